@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/shaharia-lab/agento/internal/auth"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// NewAuthCmd returns the "auth" subcommand, which turns the web server's
+// authentication on and off.
+func NewAuthCmd(cfg *config.AppConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage the web server's admin password",
+		Long: `Agento has no authentication until an admin password is set. With one set,
+the web UI asks for it, and scripts and clients need an API token, created in
+Settings > API tokens.
+
+A running "agento web" picks up a change at once.`,
+	}
+	cmd.AddCommand(newAuthSetPasswordCmd(cfg))
+	cmd.AddCommand(newAuthDisableCmd(cfg))
+	return cmd
+}
+
+func newAuthSetPasswordCmd(cfg *config.AppConfig) *cobra.Command {
+	var fromStdin bool
+	cmd := &cobra.Command{
+		Use:   "set-password",
+		Short: "Set the admin password, turning authentication on",
+		Long: `Set the admin password, turning authentication on. Every signed-in browser
+is signed out; API tokens keep working.
+
+Examples:
+  agento auth set-password
+  echo "$AGENTO_PASSWORD" | agento auth set-password --stdin`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			password, err := readNewPassword(cmd.InOrStdin(), cmd.ErrOrStderr(), fromStdin)
+			if err != nil {
+				return err
+			}
+			return withAuthManager(cmd.Context(), cfg, func(ctx context.Context, m *auth.Manager) error {
+				if err := m.SetPassword(ctx, password); err != nil {
+					return err
+				}
+				_, err := fmt.Fprintln(cmd.OutOrStdout(), "Admin password set; authentication is on.")
+				return err
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&fromStdin, "stdin", false, "Read the password from stdin instead of prompting")
+	return cmd
+}
+
+func newAuthDisableCmd(cfg *config.AppConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "disable",
+		Short: "Clear the admin password, turning authentication off",
+		Long: `Clear the admin password, turning authentication off. API tokens are kept,
+and work again when a password is next set.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withAuthManager(cmd.Context(), cfg, func(ctx context.Context, m *auth.Manager) error {
+				if err := m.Disable(ctx); err != nil {
+					return err
+				}
+				_, err := fmt.Fprintln(cmd.OutOrStdout(), "Admin password cleared; authentication is off.")
+				return err
+			})
+		},
+	}
+}
+
+// withAuthManager opens the database and runs fn with an auth.Manager on it.
+func withAuthManager(
+	ctx context.Context, cfg *config.AppConfig, fn func(context.Context, *auth.Manager) error,
+) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	db, cleanup, err := initDatabase(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return fn(ctx, auth.NewManager(storage.NewSQLiteAuthStore(db), logger))
+}
+
+// readNewPassword reads the password to set: from stdin when asked to or
+// when stdin is not a terminal, else by prompting twice without echo.
+func readNewPassword(stdin io.Reader, prompt io.Writer, fromStdin bool) (string, error) {
+	f, isFile := stdin.(*os.File)
+	if fromStdin || !isFile || !term.IsTerminal(int(f.Fd())) { //nolint:gosec // a file descriptor fits in an int
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("reading password: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	first, err := promptPassword(f, prompt, "New admin password: ")
+	if err != nil {
+		return "", err
+	}
+	second, err := promptPassword(f, prompt, "Repeat it: ")
+	if err != nil {
+		return "", err
+	}
+	if first != second {
+		return "", errors.New("the passwords do not match")
+	}
+	return first, nil
+}
+
+func promptPassword(tty *os.File, prompt io.Writer, label string) (string, error) {
+	if _, err := fmt.Fprint(prompt, label); err != nil {
+		return "", err
+	}
+	b, err := term.ReadPassword(int(tty.Fd())) //nolint:gosec // a file descriptor fits in an int
+	if _, nlErr := fmt.Fprintln(prompt); nlErr != nil {
+		return "", nlErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	return string(b), nil
+}
+
+// newAuthManager returns the web server's auth.Manager, first removing the
+// sessions that expired while it was down.
+func newAuthManager(ctx context.Context, db *sql.DB, logger *slog.Logger) *auth.Manager {
+	m := auth.NewManager(storage.NewSQLiteAuthStore(db), logger)
+	if n, err := m.PruneSessions(ctx); err != nil {
+		logger.Warn("pruning expired sign-in sessions failed", "error", err)
+	} else if n > 0 {
+		logger.Debug("pruned expired sign-in sessions", "count", n)
+	}
+	return m
+}
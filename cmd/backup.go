@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shaharia-lab/agento/internal/backup"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/secrets"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// NewBackupCmd returns the "backup" subcommand, which writes agento's state
+// to a single archive.
+func NewBackupCmd(cfg *config.AppConfig) *cobra.Command {
+	var output string
+	var excludeCredentials bool
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Write the database, agents, MCP servers and settings profiles to one archive",
+		Long: `Write agento's state to a single archive: a consistent snapshot of agento.db
+(chat history, agents, tasks, pricing edits, integrations and settings), the
+agents directory, mcps.yaml, and the Claude settings profiles. Logs, uploads
+and the credential encryption key are left out.
+
+It is safe to run while "agento web" is running. Without -o the archive is
+written to the backup directory set under Settings > Backups, or to
+~/.agento/backups.
+
+Integration credentials stay encrypted in the archive, and restoring them
+needs the same key: keep AGENTO_SECRET_KEY_FILE or AGENTO_SECRET_PASSPHRASE,
+or leave them out with --exclude-credentials and reconnect afterwards.
+
+Examples:
+  agento backup
+  agento backup -o /mnt/usb
+  agento backup -o ~/agento.tar.gz --exclude-credentials`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runBackup(cmd.Context(), cfg, cmd.OutOrStdout(), output, backup.Options{
+				IncludeCredentials: !excludeCredentials,
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Archive file, or directory to write it in")
+	cmd.Flags().BoolVar(&excludeCredentials, "exclude-credentials", false,
+		"Leave integration credentials and OAuth tokens out of the archive")
+	return cmd
+}
+
+func runBackup(ctx context.Context, cfg *config.AppConfig, out io.Writer, output string, opts backup.Options) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	db, cleanup, err := initDatabase(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	settings, err := storage.NewSQLiteSettingsStore(db).Load()
+	if err != nil {
+		return fmt.Errorf("loading settings: %w", err)
+	}
+	dest := backupDest(output, backupDir(cfg, settings), time.Now())
+	m, err := backup.Create(ctx, db, backupPaths(cfg), dest, opts)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Backup written to %s (%d files, schema version %d).\n",
+		dest, len(m.Files), m.SchemaVersion)
+	return err
+}
+
+// backupDest resolves -o: a directory, or a path ending in a separator,
+// receives a timestamped archive; anything else is the archive itself.
+func backupDest(output, defaultDir string, now time.Time) string {
+	name := backup.ArchiveName(now, false)
+	if output == "" {
+		return filepath.Join(defaultDir, name)
+	}
+	if info, err := os.Stat(output); (err == nil && info.IsDir()) || os.IsPathSeparator(output[len(output)-1]) {
+		return filepath.Join(output, name)
+	}
+	return output
+}
+
+// backupDir is where backups go: the directory set in settings, else the
+// one under the data directory.
+func backupDir(cfg *config.AppConfig, settings config.UserSettings) string {
+	if settings.BackupDir != "" {
+		return settings.BackupDir
+	}
+	return cfg.BackupsDir()
+}
+
+// backupPaths says where agento's state lives. The Claude config dir comes
+// from the stored settings, as it does for "agento ask".
+func backupPaths(cfg *config.AppConfig) backup.Paths {
+	applyStoredClaudeDirs(cfg.DatabasePath())
+	claudeDir, err := config.ClaudeSettingsDirPath()
+	if err != nil {
+		claudeDir = ""
+	}
+	return backup.Paths{DataDir: cfg.DataDir, ClaudeDir: claudeDir}
+}
+
+// NewRestoreCmd returns the "restore" subcommand, which replaces agento's
+// state with an archive written by "agento backup".
+func NewRestoreCmd(cfg *config.AppConfig) *cobra.Command {
+	var skipProfiles bool
+	cmd := &cobra.Command{
+		Use:   "restore <archive>",
+		Short: "Replace agento's state with a backup archive",
+		Long: `Replace the database, agents, MCP servers and Claude settings profiles with
+those in an archive written by "agento backup". This is also how agento moves
+to another machine: back up on the old one, copy the archive, restore on the
+new one.
+
+The archive is unpacked and checked before anything is replaced: its database
+must be intact and no newer than this build of agento understands. An older
+database is migrated. The files it replaces are moved to a pre-restore-<time>
+directory under the data directory rather than deleted.
+
+Stop "agento web" first ("agento service stop" when it runs as a service).
+
+Examples:
+  agento restore ~/.agento/backups/agento-backup-20261016T090000Z.tar.gz
+  agento restore agento.tar.gz --skip-claude-profiles`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			if serverRunning(ctx, cfg.Port) {
+				return fmt.Errorf("agento is running on port %d; stop it first "+
+					"(\"agento service stop\" when it runs as a service)", cfg.Port)
+			}
+			return runRestore(ctx, cfg, cmd.OutOrStdout(), args[0],
+				backup.RestoreOptions{SkipClaudeProfiles: skipProfiles})
+		},
+	}
+	cmd.Flags().BoolVar(&skipProfiles, "skip-claude-profiles", false,
+		"Leave this machine's Claude settings profiles as they are")
+	return cmd
+}
+
+func runRestore(
+	ctx context.Context, cfg *config.AppConfig, out io.Writer, archive string, opts backup.RestoreOptions,
+) error {
+	res, err := backup.Restore(ctx, archive, backupPaths(cfg), opts)
+	if err != nil {
+		return err
+	}
+	m := res.Manifest
+	if _, err := fmt.Fprintf(out, "Restored the backup made by agento %s on %s at %s.\n",
+		m.AgentoVersion, m.Hostname, m.CreatedAt.Local().Format(time.DateTime)); err != nil {
+		return err
+	}
+	if res.SetAside != "" {
+		if _, err := fmt.Fprintf(out, "The files it replaced are in %s.\n", res.SetAside); err != nil {
+			return err
+		}
+	}
+
+	// Opening the database migrates an older schema, and loading the key
+	// either confirms the restored credentials can be read or, when they were
+	// left out, creates a key for the ones the user adds next.
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	db, cleanup, err := initDatabase(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return checkRestoredCredentials(ctx, cfg, db, logger, out, m.IncludesCredentials)
+}
+
+// checkRestoredCredentials loads the credential encryption key against the
+// restored database. A missing key is reported rather than returned: the
+// restore itself succeeded, and the fix is to supply the key.
+func checkRestoredCredentials(
+	ctx context.Context, cfg *config.AppConfig, db *sql.DB, logger *slog.Logger, out io.Writer, included bool,
+) error {
+	_, err := newSecretsManager(cfg, db, logger).Load(ctx)
+	switch {
+	case errors.Is(err, secrets.ErrKeyUnavailable), errors.Is(err, secrets.ErrWrongKey):
+		_, werr := fmt.Fprintf(out, "warning: %v.\n"+
+			"         The restored integration credentials are encrypted with the key of the machine\n"+
+			"         the backup was made on. Set AGENTO_SECRET_KEY_FILE or AGENTO_SECRET_PASSPHRASE\n"+
+			"         to it before starting agento, or reconnect the integrations.\n", err)
+		return werr
+	case err != nil:
+		return fmt.Errorf("loading credential encryption key: %w", err)
+	}
+	if included {
+		return nil
+	}
+	_, err = fmt.Fprintln(out, "The backup holds no integration credentials; reconnect the integrations in the UI.")
+	return err
+}
+
+// serverRunning reports whether agento answers its health check on port.
+func serverRunning(ctx context.Context, port int) bool {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://127.0.0.1:%d/health", port), nil)
+	if err != nil {
+		return false
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1024)).Decode(&health); err != nil {
+		return false
+	}
+	return health.Status == "ok"
+}
+
+// startBackupScheduler starts the automatic backups, configured from
+// Settings > Backups, and returns the scheduler for the API.
+func startBackupScheduler(ctx context.Context, deps appDeps) *backup.Scheduler {
+	claudeDir, err := config.ClaudeSettingsDirPath()
+	if err != nil {
+		claudeDir = ""
+	}
+	paths := backup.Paths{DataDir: deps.appConfig.DataDir, ClaudeDir: claudeDir}
+	s := backup.NewScheduler(deps.db, paths, func() backup.Schedule {
+		us := deps.settingsMgr.Get()
+		keep := us.BackupKeep
+		if keep == 0 {
+			keep = config.DefaultBackupKeep
+		}
+		return backup.Schedule{
+			Interval:           time.Duration(us.BackupIntervalHours) * time.Hour,
+			Keep:               keep,
+			Dir:                backupDir(deps.appConfig, us),
+			IncludeCredentials: us.BackupIncludeCredentials,
+		}
+	}, deps.logger)
+	s.Start(ctx)
+	return s
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/backup"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func TestBackupDest(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	name := backup.ArchiveName(now, false)
+
+	cases := []struct {
+		output string
+		want   string
+	}{
+		{"", filepath.Join("/default", name)},
+		{dir, filepath.Join(dir, name)},
+		{"/not/yet/there/", filepath.Join("/not/yet/there", name)},
+		{filepath.Join(dir, "agento.tar.gz"), filepath.Join(dir, "agento.tar.gz")},
+	}
+	for _, tc := range cases {
+		if got := backupDest(tc.output, "/default", now); got != tc.want {
+			t.Errorf("backupDest(%q) = %q, want %q", tc.output, got, tc.want)
+		}
+	}
+}
+
+func TestRunBackupAndRestore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.ClaudeConfigDirEnvVar, "")
+	t.Cleanup(func() { config.ApplyClaudeDirs("", nil) })
+
+	src := &config.AppConfig{DataDir: t.TempDir(), SecretPassphrase: "correct horse battery staple"}
+	db, _, err := storage.NewSQLiteDB(src.DatabasePath(), slog.Default())
+	if err != nil {
+		t.Fatalf("creating db: %v", err)
+	}
+	if _, err := storage.NewSQLiteChatStore(db).CreateSession(context.Background(), "planner", "", "", ""); err != nil {
+		t.Fatalf("creating chat: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("closing db: %v", err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "agento.tar.gz")
+	var out bytes.Buffer
+	if err := runBackup(context.Background(), src, &out, archive, backup.Options{}); err != nil {
+		t.Fatalf("runBackup: %v", err)
+	}
+	if !strings.Contains(out.String(), archive) {
+		t.Errorf("output %q does not name the archive", out.String())
+	}
+
+	dst := &config.AppConfig{DataDir: t.TempDir(), SecretPassphrase: "correct horse battery staple"}
+	out.Reset()
+	if err := runRestore(context.Background(), dst, &out, archive, backup.RestoreOptions{}); err != nil {
+		t.Fatalf("runRestore: %v", err)
+	}
+	if !strings.Contains(out.String(), "reconnect the integrations") {
+		t.Errorf("output %q does not say the credentials were left out", out.String())
+	}
+
+	db, _, err = storage.NewSQLiteDB(dst.DatabasePath(), slog.Default())
+	if err != nil {
+		t.Fatalf("opening restored db: %v", err)
+	}
+	defer db.Close() //nolint:errcheck
+	sessions, err := storage.NewSQLiteChatStore(db).ListSessions(context.Background())
+	if err != nil {
+		t.Fatalf("listing chats: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Errorf("restored %d chats, want 1", len(sessions))
+	}
+	if _, err := os.Stat(filepath.Join(dst.DataDir, "agento.db")); err != nil {
+		t.Errorf("restored database: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shaharia-lab/agento/internal/api"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/export"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// exportOptions holds the flags of the export command.
+type exportOptions struct {
+	sessionFilter
+	format string
+	output string
+}
+
+// NewExportCmd returns the "export" subcommand, which writes a dataset to a
+// file or stdout.
+func NewExportCmd(cfg *config.AppConfig) *cobra.Command {
+	var opts exportOptions
+
+	names := make([]string, len(export.Datasets))
+	for i, d := range export.Datasets {
+		names[i] = string(d)
+	}
+
+	cmd := &cobra.Command{
+		Use:   "export <dataset>",
+		Short: "Export sessions, insights, job history or chat usage",
+		Long: `Export a dataset as CSV, JSON Lines or Parquet.
+
+Datasets: ` + strings.Join(names, ", ") + `
+
+The session datasets (sessions, session_models, insights) honor the same
+filters as the sessions list in the web UI, including hidden projects. The
+session index is brought up to date before exporting.
+
+Examples:
+  agento export sessions > sessions.csv
+  agento export sessions --from 2026-08-01 --to 2026-08-31 -o august.parquet
+  agento export session_models --project /home/me/app --format jsonl
+  agento export jobs -o jobs.csv`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: names,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExport(cmd.Context(), cfg, args[0], opts, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", "",
+		"csv, jsonl or parquet (default: from the --output extension, else csv)")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "File to write (default: stdout)")
+	opts.register(cmd)
+
+	return cmd
+}
+
+func runExport(
+	parent context.Context, cfg *config.AppConfig, datasetName string, opts exportOptions, stdout io.Writer,
+) error {
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	dataset, err := export.ParseDataset(datasetName)
+	if err != nil {
+		return err
+	}
+	formatName := opts.format
+	if formatName == "" && opts.output != "" {
+		formatName = strings.TrimPrefix(filepath.Ext(opts.output), ".")
+	}
+	format, err := export.ParseFormat(formatName)
+	if err != nil {
+		return err
+	}
+	q, err := opts.query()
+	if err != nil {
+		return err
+	}
+
+	// Only the session datasets read the index; jobs and chats need no scan.
+	scan := dataset == export.DatasetSessions || dataset == export.DatasetSessionModels ||
+		dataset == export.DatasetInsights
+	idx, err := openSessionIndex(ctx, cfg, scan)
+	if err != nil {
+		return err
+	}
+	defer idx.cleanup()
+
+	exporter := export.NewExporter(export.Sources{
+		Sessions: idx.cache,
+		Insights: api.NewInsightStoreAdapter(storage.NewSQLiteSessionInsightsStore(idx.db)),
+		Jobs:     storage.NewSQLiteTaskStore(idx.db),
+		Chats:    storage.NewSQLiteChatStore(idx.db),
+	})
+	table, err := exporter.Table(ctx, dataset, q)
+	if err != nil {
+		return err
+	}
+
+	if opts.output == "" {
+		return export.Write(stdout, format, table)
+	}
+	return writeExportFile(opts.output, format, table)
+}
+
+// writeExportFile writes to a temporary file beside path and renames it into
+// place, so an interrupted export never leaves a truncated file under the
+// name a later import will read.
+func writeExportFile(path string, format export.Format, table export.Table) error {
+	path = expandHome(path)
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // already renamed on success
+
+	if err := export.Write(tmp, format, table); err != nil {
+		tmp.Close() //nolint:errcheck,gosec
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing output file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d rows to %s\n", len(table.Rows), path)
+	return nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func TestRunExport_Chats(t *testing.T) {
+	cfg := &config.AppConfig{DataDir: t.TempDir()}
+	db, _, err := storage.NewSQLiteDB(cfg.DatabasePath(), slog.Default())
+	if err != nil {
+		t.Fatalf("creating db: %v", err)
+	}
+	if _, err := storage.NewSQLiteChatStore(db).CreateSession(context.Background(), "planner", "", "", ""); err != nil {
+		t.Fatalf("creating chat: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("closing db: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	if err := runExport(context.Background(), cfg, "chats", exportOptions{}, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "id,title,agent_slug") || !strings.Contains(lines[1], "planner") {
+		t.Errorf("csv = %q, want a header and the one chat", stdout.String())
+	}
+
+	// The format follows the output file's extension.
+	out := filepath.Join(t.TempDir(), "chats.parquet")
+	if err := runExport(context.Background(), cfg, "chats", exportOptions{output: out}, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Errorf("%s is not a parquet file", out)
+	}
+}
+
+func TestExportQuery_DateBounds(t *testing.T) {
+	opts := exportOptions{sessionFilter: sessionFilter{from: "2026-08-01", to: "2026-08-31", project: "/a"}}
+	q, err := opts.query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFrom := time.Date(2026, 8, 1, 0, 0, 0, 0, time.Local)
+	wantTo := time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local).Add(-time.Nanosecond)
+	if q.From == nil || q.To == nil || !q.From.Equal(wantFrom) || !q.To.Equal(wantTo) || q.Project != "/a" {
+		t.Errorf("query = %+v, want August inclusive of the 31st", q)
+	}
+
+	opts = exportOptions{sessionFilter: sessionFilter{to: "last week"}}
+	if _, err := opts.query(); err == nil {
+		t.Error("expected an error for an unparseable --to")
+	}
+}
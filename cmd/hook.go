@@ -0,0 +1,439 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shaharia-lab/agento/internal/auth"
+	"github.com/shaharia-lab/agento/internal/claudesessions"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// hookTokenName names the API token `agento hook install` creates, so a
+// reinstall replaces it rather than adding another.
+const hookTokenName = "claude-code-hooks"
+
+// hookTimeout bounds how long the hook command waits on the web server.
+// Claude Code waits on its hooks, so a server that is down must cost a session
+// as little as possible.
+const hookTimeout = 2 * time.Second
+
+// hookTimeoutSeconds is the timeout written into the hook entries, after
+// which Claude Code stops waiting on the command itself.
+const hookTimeoutSeconds = 5
+
+// maxHookInputBytes caps what the hook command reads from stdin. A tool
+// event carries the tool's whole response, which can be a large file.
+const maxHookInputBytes = 32 << 20
+
+// hookCommandMarker is the part of every command `agento hook install`
+// writes that tells it apart from the user's own hooks.
+const hookCommandMarker = " hook --url "
+
+// installedHookEvents are the events the hook command is registered for.
+// PreToolUse is left out: PostToolUse follows it, and every event costs the
+// session a process start.
+var installedHookEvents = []string{ //nolint:gochecknoglobals
+	claudesessions.HookSessionStart,
+	claudesessions.HookUserPromptSubmit,
+	claudesessions.HookPostToolUse,
+	claudesessions.HookNotification,
+	claudesessions.HookStop,
+	claudesessions.HookSubagentStop,
+	claudesessions.HookPreCompact,
+	claudesessions.HookSessionEnd,
+}
+
+// shellSafe matches what can go into a shell command unquoted.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:=@%+-]+$`) //nolint:gochecknoglobals
+
+// NewHookCmd returns the "hook" subcommand, which Claude Code runs on its
+// lifecycle events to tell the web server about them.
+func NewHookCmd(cfg *config.AppConfig) *cobra.Command {
+	var serverURL string
+	cmd := &cobra.Command{
+		Use:   "hook",
+		Short: "Forward a Claude Code hook event to the running web server",
+		Long: `Forward a Claude Code hook event, read from stdin, to the running web server,
+so the session it belongs to is updated at once and shown as running.
+
+Claude Code runs this command itself once "agento hook install" has registered
+it. It prints nothing and always exits 0: a web server that is down must not
+interrupt a Claude Code session.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			token := readHookToken(cfg.HookTokenPath())
+			forwardHookEvent(cmd.Context(), cmd.InOrStdin(), hookServerURL(serverURL, cfg), token)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&serverURL, "url", "", "Web server URL (default http://127.0.0.1:<port>)")
+	cmd.AddCommand(newHookInstallCmd(cfg))
+	cmd.AddCommand(newHookUninstallCmd(cfg))
+	return cmd
+}
+
+func newHookInstallCmd(cfg *config.AppConfig) *cobra.Command {
+	var profile, serverURL string
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Register the hook command in a Claude settings file",
+		Long: `Register "agento hook" for Claude Code's session, prompt, tool, stop and
+end events in a Claude settings file: settings.json of the Claude config dir,
+or the file of the profile named with --profile. Hooks the file already has
+are kept; an earlier install is replaced.
+
+An API token limited to delivering hook events is created and written to
+~/.agento/hook-token, so the hooks keep working when authentication is on.
+
+Examples:
+  agento hook install
+  agento hook install --profile work`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := hookSettingsPath(profile)
+			if err != nil {
+				return err
+			}
+			command, err := hookCommand(hookServerURL(serverURL, cfg))
+			if err != nil {
+				return err
+			}
+			if err := editSettingsFile(path, func(b []byte) ([]byte, error) {
+				return installHooks(b, command)
+			}); err != nil {
+				return err
+			}
+			if err := issueHookToken(cmd.Context(), cfg); err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(),
+				"Hooks installed in %s.\nClaude Code sessions started from now on report to agento.\n", path)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&profile, "profile", "", "Claude settings profile ID (default settings.json)")
+	cmd.Flags().StringVar(&serverURL, "url", "", "Web server URL (default http://127.0.0.1:<port>)")
+	return cmd
+}
+
+func newHookUninstallCmd(cfg *config.AppConfig) *cobra.Command {
+	var profile string
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the hook command from a Claude settings file",
+		Long: `Remove the entries "agento hook install" wrote from a Claude settings file,
+leaving every other hook in place, and revoke the hook command's API token.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := hookSettingsPath(profile)
+			if err != nil {
+				return err
+			}
+			if err := editSettingsFile(path, uninstallHooks); err != nil {
+				return err
+			}
+			if err := revokeHookToken(cmd.Context(), cfg); err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Hooks removed from %s.\n", path)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&profile, "profile", "", "Claude settings profile ID (default settings.json)")
+	return cmd
+}
+
+// hookServerURL returns the web server URL the hook command posts to.
+func hookServerURL(flag string, cfg *config.AppConfig) string {
+	if flag != "" {
+		return strings.TrimRight(flag, "/")
+	}
+	return fmt.Sprintf("http://127.0.0.1:%d", cfg.Port)
+}
+
+// forwardHookEvent posts the hook event on stdin to the web server. It keeps
+// only the fields the server reads: a tool event's input and response can be
+// megabytes the transcript already holds. Every failure is silent — see
+// NewHookCmd.
+func forwardHookEvent(ctx context.Context, stdin io.Reader, serverURL, token string) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var ev claudesessions.HookEvent
+	if err := json.NewDecoder(io.LimitReader(stdin, maxHookInputBytes)).Decode(&ev); err != nil {
+		return
+	}
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		serverURL+"/api/claude-sessions/hooks", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close() //nolint:errcheck,gosec // nothing to do about a failed close
+}
+
+// readHookToken returns the hook command's API token, or "" when none was
+// issued or it cannot be read — the request then goes without, which works
+// while authentication is off.
+func readHookToken(path string) string {
+	b, err := os.ReadFile(path) //nolint:gosec // path is inside the data dir
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// hookSettingsPath returns the Claude settings file to install into: the
+// named profile's file, or settings.json. Unlike a run, which falls back to
+// the default profile, an install into a profile that does not exist is an
+// error — the user named a file, and writing a different one is a surprise.
+func hookSettingsPath(profileID string) (string, error) {
+	if profileID == "" {
+		return config.ClaudeSettingsJSONPath()
+	}
+	m, err := config.LoadProfilesMetadata()
+	if err != nil {
+		return "", fmt.Errorf("loading settings profiles: %w", err)
+	}
+	for _, p := range m.Profiles {
+		if p.ID == profileID {
+			return p.FilePath, nil
+		}
+	}
+	return "", fmt.Errorf("no Claude settings profile %q", profileID)
+}
+
+// hookCommand returns the shell command Claude Code runs for each event.
+func hookCommand(serverURL string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("locating the agento binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return shellQuote(exe) + hookCommandMarker + shellQuote(serverURL), nil
+}
+
+// shellQuote quotes s for a POSIX shell, which is what Claude Code runs hook
+// commands with.
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// editSettingsFile rewrites a Claude settings file through edit, creating it
+// when it does not exist yet.
+func editSettingsFile(path string, edit func([]byte) ([]byte, error)) error {
+	b, err := os.ReadFile(path) //nolint:gosec // path is a Claude settings file the user chose
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	out, err := edit(b)
+	if err != nil {
+		return fmt.Errorf("editing %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, out, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// claudeHookGroup is one entry of an event's list in a Claude settings
+// file's "hooks" object.
+type claudeHookGroup struct {
+	Matcher string              `json:"matcher,omitempty"`
+	Hooks   []claudeHookCommand `json:"hooks"`
+}
+
+type claudeHookCommand struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+	Timeout int    `json:"timeout,omitempty"`
+}
+
+// installHooks registers command for every installed event in the settings
+// file content b, replacing what an earlier install wrote.
+func installHooks(b []byte, command string) ([]byte, error) {
+	doc, hooks, err := decodeHookSettings(b)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range installedHookEvents {
+		group := claudeHookGroup{Hooks: []claudeHookCommand{
+			{Type: "command", Command: command, Timeout: hookTimeoutSeconds},
+		}}
+		if event == claudesessions.HookPostToolUse {
+			group.Matcher = "*"
+		}
+		raw, err := json.Marshal(group)
+		if err != nil {
+			return nil, err
+		}
+		hooks[event] = append(withoutAgentoHooks(hooks[event]), raw)
+	}
+	return encodeHookSettings(doc, hooks)
+}
+
+// uninstallHooks removes what installHooks wrote from the settings file
+// content b.
+func uninstallHooks(b []byte) ([]byte, error) {
+	doc, hooks, err := decodeHookSettings(b)
+	if err != nil {
+		return nil, err
+	}
+	for event, groups := range hooks {
+		if kept := withoutAgentoHooks(groups); len(kept) > 0 {
+			hooks[event] = kept
+		} else {
+			delete(hooks, event)
+		}
+	}
+	return encodeHookSettings(doc, hooks)
+}
+
+// decodeHookSettings splits a settings file into its top-level keys and its
+// hooks, by event. Both are kept raw so settings and hooks Agento does not
+// know survive the rewrite unchanged.
+func decodeHookSettings(b []byte) (map[string]json.RawMessage, map[string][]json.RawMessage, error) {
+	doc := map[string]json.RawMessage{}
+	if len(bytes.TrimSpace(b)) > 0 {
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return nil, nil, fmt.Errorf("settings are not a JSON object: %w", err)
+		}
+	}
+	hooks := map[string][]json.RawMessage{}
+	if raw, ok := doc["hooks"]; ok {
+		if err := json.Unmarshal(raw, &hooks); err != nil {
+			return nil, nil, fmt.Errorf("settings hooks are not an object of lists: %w", err)
+		}
+	}
+	return doc, hooks, nil
+}
+
+func encodeHookSettings(doc map[string]json.RawMessage, hooks map[string][]json.RawMessage) ([]byte, error) {
+	if len(hooks) == 0 {
+		delete(doc, "hooks")
+	} else {
+		raw, err := json.Marshal(hooks)
+		if err != nil {
+			return nil, err
+		}
+		doc["hooks"] = raw
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// withoutAgentoHooks drops the groups an install wrote. A group the user
+// added a command of their own to, or one that does not parse, is kept.
+func withoutAgentoHooks(groups []json.RawMessage) []json.RawMessage {
+	kept := make([]json.RawMessage, 0, len(groups))
+	for _, raw := range groups {
+		var g claudeHookGroup
+		if json.Unmarshal(raw, &g) != nil || !allAgentoHooks(g.Hooks) {
+			kept = append(kept, raw)
+		}
+	}
+	return kept
+}
+
+func allAgentoHooks(hooks []claudeHookCommand) bool {
+	if len(hooks) == 0 {
+		return false
+	}
+	for _, h := range hooks {
+		if !strings.Contains(h.Command, hookCommandMarker) {
+			return false
+		}
+	}
+	return true
+}
+
+// issueHookToken replaces the hook command's API token with a new one and
+// writes it where the hook command reads it. The token is issued whether or
+// not authentication is on, so turning it on later does not silently stop
+// the hooks.
+func issueHookToken(ctx context.Context, cfg *config.AppConfig) error {
+	return withAuthManager(ctx, cfg, func(ctx context.Context, m *auth.Manager) error {
+		if err := revokeHookTokens(ctx, m); err != nil {
+			return err
+		}
+		_, raw, err := m.CreateToken(ctx, hookTokenName, storage.APITokenScopeIngest, 0)
+		if err != nil {
+			return fmt.Errorf("creating the hook token: %w", err)
+		}
+		if err := os.MkdirAll(cfg.DataDir, 0o750); err != nil {
+			return fmt.Errorf("creating data dir: %w", err)
+		}
+		if err := os.WriteFile(cfg.HookTokenPath(), []byte(raw+"\n"), 0o600); err != nil {
+			return fmt.Errorf("writing the hook token: %w", err)
+		}
+		return nil
+	})
+}
+
+// revokeHookToken revokes the hook command's API token and removes its file.
+func revokeHookToken(ctx context.Context, cfg *config.AppConfig) error {
+	if err := withAuthManager(ctx, cfg, revokeHookTokens); err != nil {
+		return err
+	}
+	if err := os.Remove(cfg.HookTokenPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing the hook token: %w", err)
+	}
+	return nil
+}
+
+func revokeHookTokens(ctx context.Context, m *auth.Manager) error {
+	tokens, err := m.ListTokens(ctx)
+	if err != nil {
+		return fmt.Errorf("listing API tokens: %w", err)
+	}
+	for _, t := range tokens {
+		if t.Name != hookTokenName || t.Scope != storage.APITokenScopeIngest {
+			continue
+		}
+		if err := m.RevokeToken(ctx, t.ID); err != nil {
+			return fmt.Errorf("revoking the old hook token: %w", err)
+		}
+	}
+	return nil
+}
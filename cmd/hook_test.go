@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInstallHooks_KeepsTheUsersSettingsAndHooks(t *testing.T) {
+	settings := []byte(`{
+  "model": "opus",
+  "hooks": {
+    "Stop": [{"hooks": [{"type": "command", "command": "notify-send done"}]}]
+  }
+}`)
+	command := "/usr/local/bin/agento" + hookCommandMarker + "http://127.0.0.1:8990"
+
+	out, err := installHooks(settings, command)
+	if err != nil {
+		t.Fatalf("installHooks: %v", err)
+	}
+	// Installing twice replaces the first install rather than adding to it.
+	out, err = installHooks(out, command)
+	if err != nil {
+		t.Fatalf("second installHooks: %v", err)
+	}
+
+	var doc struct {
+		Model string                       `json:"model"`
+		Hooks map[string][]claudeHookGroup `json:"hooks"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("installed settings do not parse: %v\n%s", err, out)
+	}
+	if doc.Model != "opus" {
+		t.Errorf("model = %q, want the user's setting kept", doc.Model)
+	}
+	if len(doc.Hooks) != len(installedHookEvents) {
+		t.Errorf("hooked events = %d, want %d", len(doc.Hooks), len(installedHookEvents))
+	}
+	stop := doc.Hooks["Stop"]
+	if len(stop) != 2 || stop[0].Hooks[0].Command != "notify-send done" || stop[1].Hooks[0].Command != command {
+		t.Errorf("Stop hooks = %+v, want the user's then agento's", stop)
+	}
+	if tool := doc.Hooks["PostToolUse"]; len(tool) != 1 || tool[0].Matcher != "*" {
+		t.Errorf("PostToolUse hooks = %+v, want one for every tool", tool)
+	}
+
+	out, err = uninstallHooks(out)
+	if err != nil {
+		t.Fatalf("uninstallHooks: %v", err)
+	}
+	doc.Hooks = nil
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("uninstalled settings do not parse: %v\n%s", err, out)
+	}
+	if len(doc.Hooks) != 1 || len(doc.Hooks["Stop"]) != 1 || doc.Model != "opus" {
+		t.Errorf("after uninstall = %s, want only the user's Stop hook", out)
+	}
+}
+
+func TestInstallHooks_RefusesSettingsThatAreNotAnObject(t *testing.T) {
+	if _, err := installHooks([]byte(`["not", "settings"]`), "agento hook --url x"); err == nil {
+		t.Error("installHooks accepted a JSON array")
+	}
+	out, err := installHooks(nil, "agento"+hookCommandMarker+"x")
+	if err != nil {
+		t.Fatalf("installHooks into a new file: %v", err)
+	}
+	if !strings.Contains(string(out), `"SessionEnd"`) {
+		t.Errorf("new settings = %s, want the hooks", out)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	cases := map[string]string{
+		"/usr/local/bin/agento":      "/usr/local/bin/agento",
+		"http://127.0.0.1:8990":      "http://127.0.0.1:8990",
+		"/Users/Jo Doe/bin/agento":   "'/Users/Jo Doe/bin/agento'",
+		"/home/o'brien/bin/agento":   `'/home/o'\''brien/bin/agento'`,
+		"/tmp/$(touch pwned)/agento": "'/tmp/$(touch pwned)/agento'",
+	}
+	for in, want := range cases {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestForwardHookEvent_SendsOnlyWhatTheServerReads(t *testing.T) {
+	var gotBody, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody, gotAuth = string(b), r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	stdin := strings.NewReader(`{"session_id":"s1","transcript_path":"/t/s1.jsonl",` +
+		`"hook_event_name":"PostToolUse","tool_name":"Read","tool_response":{"content":"a large file"}}`)
+	forwardHookEvent(context.Background(), stdin, srv.URL, "agento_secret")
+
+	if gotAuth != "Bearer agento_secret" {
+		t.Errorf("Authorization = %q, want the hook token", gotAuth)
+	}
+	if !strings.Contains(gotBody, `"tool_name":"Read"`) || strings.Contains(gotBody, "tool_response") {
+		t.Errorf("forwarded body = %s, want the tool name without its response", gotBody)
+	}
+
+	// A server that is not there is not an error.
+	srv.Close()
+	forwardHookEvent(context.Background(), strings.NewReader(`{"session_id":"s1"}`), srv.URL, "")
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+
+	"github.com/shaharia-lab/agento/internal/audit"
+	"github.com/shaharia-lab/agento/internal/budget"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/mcpserver"
+	"github.com/shaharia-lab/agento/internal/pricing"
+	"github.com/shaharia-lab/agento/internal/scheduler"
+	"github.com/shaharia-lab/agento/internal/service"
+	"github.com/shaharia-lab/agento/internal/storage"
+	"github.com/shaharia-lab/agento/internal/tools"
+)
+
+// NewMCPCmd returns the "mcp" subcommand, which serves Agento's agents, tasks
+// and usage analytics to an MCP client over stdio.
+func NewMCPCmd(cfg *config.AppConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "mcp",
+		Short: "Serve agents, tasks and usage analytics to MCP clients over stdio",
+		Long: `Serve Agento as an MCP server on stdin and stdout, for MCP clients such as
+Claude Code to launch. Its tools run saved agents, list and trigger scheduled
+tasks, and report Claude Code cost and usage.
+
+Agents that gate tools on approval, and agents' integration tools, need the
+web server: connect to the /mcp endpoint of "agento web" for those. A task
+triggered here runs in this process, so it ends when the client does.
+
+Examples:
+  claude mcp add agento -- agento mcp
+  claude mcp add --transport http agento http://localhost:8990/mcp`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runMCP(cmd.Context(), cfg)
+		},
+	}
+}
+
+func runMCP(parent context.Context, cfg *config.AppConfig) error {
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// Stdout carries the protocol, so logs go to stderr, which MCP clients
+	// keep for the server's diagnostics.
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	idx, err := openSessionIndex(ctx, cfg, false)
+	if err != nil {
+		return err
+	}
+	defer idx.cleanup()
+
+	server, err := buildStdioMCPServer(ctx, cfg, idx, logger)
+	if err != nil {
+		return err
+	}
+	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("serving MCP over stdio: %w", err)
+	}
+	return nil
+}
+
+// buildStdioMCPServer wires the MCP server for `agento mcp`. Task runs go
+// through a scheduler of their own that is never started: the schedules
+// belong to `agento web`, and starting them here would run every task twice.
+// Integrations are not started either, since some hold a connection only one
+// process may have.
+func buildStdioMCPServer(
+	ctx context.Context, cfg *config.AppConfig, idx *sessionIndex, logger *slog.Logger,
+) (*mcp.Server, error) {
+	mcpRegistry, err := config.LoadMCPRegistry(cfg.MCPsFile())
+	if err != nil {
+		return nil, fmt.Errorf("loading MCP registry: %w", err)
+	}
+	localToolsMCP, err := tools.StartLocalMCPServer(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("starting local tools MCP server: %w", err)
+	}
+	settingsMgr, err := config.NewSettingsManager(storage.NewSQLiteSettingsStore(idx.db), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("initializing settings: %w", err)
+	}
+
+	agentStore := storage.NewSQLiteAgentStore(idx.db)
+	taskStore := storage.NewSQLiteTaskStore(idx.db)
+	budgetEnforcer := budget.NewEnforcer(
+		storage.NewSQLiteBudgetStore(idx.db), pricing.NewStore(idx.db, logger), nil, logger,
+	)
+	toolAudit := audit.NewRecorder(storage.NewSQLiteToolCallStore(idx.db), logger)
+
+	taskScheduler, err := scheduler.New(scheduler.Config{
+		TaskStore:       taskStore,
+		ChatStore:       storage.NewSQLiteChatStore(idx.db),
+		AgentStore:      agentStore,
+		MCPRegistry:     mcpRegistry,
+		LocalMCP:        localToolsMCP,
+		SettingsManager: settingsMgr,
+		Logger:          logger,
+		Budget:          budgetEnforcer,
+		Audit:           toolAudit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating task scheduler: %w", err)
+	}
+
+	return mcpserver.New(mcpserver.Config{
+		Agents:        agentStore,
+		Tasks:         service.NewTaskService(taskStore, taskScheduler, logger),
+		Sessions:      idx.cache,
+		LocalToolsMCP: localToolsMCP,
+		MCPRegistry:   mcpRegistry,
+		Budget:        budgetEnforcer,
+		Audit:         toolAudit,
+		Logger:        logger,
+	}), nil
+}
@@ -23,13 +23,28 @@ const skipUpdateCheckEnv = "AGENTO_SKIP_UPDATE_CHECK"
 
 // updateCheckSkipCommands lists subcommand names that must never trigger the
 // auto-update check. The "update" command runs its own (uncached) check,
-// help/version are non-interactive metadata commands, and "service" manages
-// the background daemon — it must stay fast and side-effect free.
+// help/version are non-interactive metadata commands, "service" manages
+// the background daemon — it must stay fast and side-effect free — and
+// "export", "stats" and "sessions" are run from scripts with their output
+// piped. "mcp" speaks the protocol on stdin and stdout, where neither a
+// prompt nor its delay belongs, "auth" and "secrets" may read a password or
+// passphrase from stdin, "backup" and "restore" are run from cron jobs
+// and migration scripts, and "hook" is run by Claude Code on every session
+// event, where a network call would delay the session.
 var updateCheckSkipCommands = map[string]struct{}{ //nolint:gochecknoglobals
 	"update":     {},
 	"help":       {},
 	"completion": {},
 	"service":    {},
+	"export":     {},
+	"stats":      {},
+	"sessions":   {},
+	"mcp":        {},
+	"auth":       {},
+	"secrets":    {},
+	"backup":     {},
+	"restore":    {},
+	"hook":       {},
 	"__complete": {}, // cobra's hidden shell-completion command
 }
 
@@ -68,6 +83,15 @@ func Execute() {
 	root.AddCommand(NewAskCmd(cfg))
 	root.AddCommand(NewUpdateCmd(cfg))
 	root.AddCommand(NewServiceCmd(cfg))
+	root.AddCommand(NewExportCmd(cfg))
+	root.AddCommand(NewStatsCmd(cfg))
+	root.AddCommand(NewSessionsCmd(cfg))
+	root.AddCommand(NewMCPCmd(cfg))
+	root.AddCommand(NewAuthCmd(cfg))
+	root.AddCommand(NewSecretsCmd(cfg))
+	root.AddCommand(NewBackupCmd(cfg))
+	root.AddCommand(NewRestoreCmd(cfg))
+	root.AddCommand(NewHookCmd(cfg))
 
 	if err := root.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -57,7 +57,7 @@ func TestShouldRunAutoCheck_SkipCommands(t *testing.T) {
 	t.Setenv(skipUpdateCheckEnv, "")
 
 	// Skipped commands are skipped regardless of TTY.
-	for _, name := range []string{"update", "help", "completion", "__complete"} {
+	for _, name := range []string{"update", "help", "completion", "mcp", "__complete"} {
 		cmd := newTestCmd(name)
 		if shouldRunAutoCheck(cmd) {
 			t.Errorf("shouldRunAutoCheck() for %q should be false", name)
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/secrets"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// NewSecretsCmd returns the "secrets" subcommand, which manages the key that
+// integration credentials are encrypted with.
+func NewSecretsCmd(cfg *config.AppConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage the key integration credentials are encrypted with",
+		Long: `Integration credentials and OAuth tokens are encrypted in the database with a
+master key kept outside it: in the OS keyring by default, or in the file named
+by AGENTO_SECRET_KEY_FILE, or derived from AGENTO_SECRET_PASSPHRASE.`,
+	}
+	cmd.AddCommand(newSecretsRotateCmd(cfg))
+	return cmd
+}
+
+func newSecretsRotateCmd(cfg *config.AppConfig) *cobra.Command {
+	var keyFile string
+	var fromStdin bool
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Replace the master key and re-encrypt every credential under it",
+		Long: `Replace the master key. Every credential is re-encrypted under the new key in
+one transaction, and the old key is removed from the keyring. The current key
+is read as "agento web" reads it, so keep AGENTO_SECRET_KEY_FILE or
+AGENTO_SECRET_PASSPHRASE set to the old value while rotating.
+
+Without flags a new random key is generated and kept in the OS keyring. Stop
+"agento web" first, or restart it afterwards: it cannot save integrations
+with the old key.
+
+Examples:
+  agento secrets rotate
+  agento secrets rotate --key-file /etc/agento/secret.key
+  echo "$NEW_PASSPHRASE" | agento secrets rotate --passphrase-stdin`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			next := secrets.Options{KeyFile: keyFile}
+			if fromStdin {
+				passphrase, err := readNewPassword(cmd.InOrStdin(), cmd.ErrOrStderr(), true)
+				if err != nil {
+					return err
+				}
+				next.Passphrase = passphrase
+			}
+			return withSecretsManager(cmd.Context(), cfg, func(ctx context.Context, m *secrets.Manager) error {
+				c, err := m.Rotate(ctx, next)
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintf(cmd.OutOrStdout(), "Credentials re-encrypted with key %s.\n", c.KeyID())
+				return err
+			})
+		},
+	}
+	cmd.Flags().StringVar(&keyFile, "key-file", "", "Use the passphrase or key in this file as the new master key")
+	cmd.Flags().BoolVar(&fromStdin, "passphrase-stdin", false, "Read a new passphrase from stdin")
+	cmd.MarkFlagsMutuallyExclusive("key-file", "passphrase-stdin")
+	return cmd
+}
+
+// withSecretsManager opens the database and runs fn with a secrets.Manager
+// on it.
+func withSecretsManager(
+	ctx context.Context, cfg *config.AppConfig, fn func(context.Context, *secrets.Manager) error,
+) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	db, cleanup, err := initDatabase(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return fn(ctx, newSecretsManager(cfg, db, logger))
+}
+
+func newSecretsManager(cfg *config.AppConfig, db *sql.DB, logger *slog.Logger) *secrets.Manager {
+	return secrets.NewManager(storage.NewSQLiteSecretKeyStore(db), secrets.Options{
+		KeyFile:    cfg.SecretKeyFile,
+		Passphrase: cfg.SecretPassphrase,
+		KeysDir:    cfg.KeysDir(),
+	}, logger)
+}
+
+// initSecrets loads the credential encryption key, creating it and
+// encrypting any plaintext credentials on first run. Without the key the
+// integrations cannot be read, so a failure stops startup.
+func initSecrets(
+	ctx context.Context, cfg *config.AppConfig, db *sql.DB, logger *slog.Logger,
+) (*secrets.Cipher, error) {
+	c, err := newSecretsManager(cfg, db, logger).Load(ctx)
+	switch {
+	case errors.Is(err, secrets.ErrKeyUnavailable), errors.Is(err, secrets.ErrWrongKey):
+		return nil, fmt.Errorf("%w. Integration credentials in %s are encrypted and cannot be read without it; "+
+			"see \"Encryption at rest\" in docs/security.md", err, cfg.DatabasePath())
+	case err != nil:
+		return nil, fmt.Errorf("loading credential encryption key: %w", err)
+	}
+	return c, nil
+}
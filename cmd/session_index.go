@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shaharia-lab/agento/internal/claudesessions"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/pricing"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// sessionIndex is the Claude Code session index opened outside the web
+// server, for the commands that read it: export, stats and sessions.
+type sessionIndex struct {
+	db      *sql.DB
+	cache   *claudesessions.Cache
+	cleanup func()
+}
+
+// openSessionIndex opens the database with the settings the web app would
+// install at startup — hidden projects filter every figure, the Claude dirs
+// decide which transcripts the index covers, and the transcript archive keeps
+// the ones Claude Code deleted — and, when scan is set, brings the index up to
+// date first. The scan is incremental, so only transcripts changed since the
+// last one (by this command or by `agento web`) are read.
+//
+// Logging goes to stderr at warning level only: stdout is the command's
+// output, and is often piped.
+func openSessionIndex(ctx context.Context, cfg *config.AppConfig, scan bool) (*sessionIndex, error) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	db, cleanup, err := initDatabase(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := storage.NewSQLiteSettingsStore(db).Load()
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("loading settings: %w", err)
+	}
+	claudesessions.ApplyDataSettings(settings.IdleGapThresholdMinutes, settings.HiddenProjects)
+	config.ApplyClaudeDirs(settings.ClaudeConfigDir, settings.ClaudeConfigDirs)
+	claudesessions.ApplyImportedAgents(settings.ImportedAgents)
+	installTranscriptArchive(db, cfg, settings, logger)
+
+	cache := claudesessions.NewCache(db, logger).WithPricingStore(pricing.NewStore(db, logger))
+	if scan {
+		select {
+		case <-cache.EnsureScan():
+		case <-ctx.Done():
+			cleanup()
+			return nil, ctx.Err()
+		}
+	}
+	return &sessionIndex{db: db, cache: cache, cleanup: cleanup}, nil
+}
+
+// installTranscriptArchive installs the transcript archive under the data
+// directory with the user's settings. Every process that scans must install
+// it: a scan without it reads transcripts Claude Code has deleted as gone, and
+// drops their sessions from the index.
+func installTranscriptArchive(db *sql.DB, cfg *config.AppConfig, settings config.UserSettings, logger *slog.Logger) {
+	claudesessions.InstallTranscriptArchive(claudesessions.NewTranscriptArchive(db, cfg.TranscriptsDir(), logger))
+	claudesessions.ApplyArchiveSettings(settings.TranscriptArchiveDisabled,
+		settings.TranscriptArchiveRetentionDays, settings.TranscriptArchiveMaxMB)
+}
+
+// sessionFilter holds the session filter flags shared by the commands that
+// list sessions. They mean what the sessions list's filters mean in the web
+// UI.
+type sessionFilter struct {
+	project   string
+	model     string
+	search    string
+	favorites bool
+	from      string
+	to        string
+}
+
+func (f *sessionFilter) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.project, "project", "", "Only sessions in this project path")
+	cmd.Flags().StringVar(&f.model, "model", "", "Only sessions on this model")
+	cmd.Flags().StringVarP(&f.search, "search", "q", "", "Only sessions whose ID, title, preview or path contains this")
+	cmd.Flags().BoolVar(&f.favorites, "favorites", false, "Only starred sessions")
+	cmd.Flags().StringVar(&f.from, "from", "", "Start of the window, YYYY-MM-DD or RFC3339")
+	cmd.Flags().StringVar(&f.to, "to", "", "End of the window, YYYY-MM-DD (inclusive) or RFC3339")
+}
+
+// query builds the sessions-list query the flags describe.
+func (f sessionFilter) query() (claudesessions.SessionQuery, error) {
+	q := claudesessions.SessionQuery{
+		Project:       f.project,
+		Model:         f.model,
+		Search:        f.search,
+		FavoritesOnly: f.favorites,
+	}
+	if f.from != "" {
+		from, err := parseCLITime(f.from, false, time.Local)
+		if err != nil {
+			return q, fmt.Errorf("invalid --from: %w", err)
+		}
+		q.From = &from
+	}
+	if f.to != "" {
+		to, err := parseCLITime(f.to, true, time.Local)
+		if err != nil {
+			return q, fmt.Errorf("invalid --to: %w", err)
+		}
+		q.To = &to
+	}
+	return q, nil
+}
+
+// parseCLITime reads RFC 3339 or a bare date in loc. A date used as an end
+// bound means the end of that day, so --to 2026-08-31 includes the 31st.
+func parseCLITime(raw string, endOfDay bool, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", raw, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither YYYY-MM-DD nor RFC3339", raw)
+	}
+	if endOfDay {
+		return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return day, nil
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shaharia-lab/agento/internal/claudesessions"
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+// sessionsOptions holds the flags of the sessions command.
+type sessionsOptions struct {
+	sessionFilter
+	sort  string
+	limit int
+	json  bool
+}
+
+// NewSessionsCmd returns the "sessions" subcommand, which lists and searches
+// Claude Code sessions without starting the web server.
+func NewSessionsCmd(cfg *config.AppConfig) *cobra.Command {
+	var opts sessionsOptions
+
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "List and search Claude Code sessions",
+		Long: `List Claude Code sessions with the same filters and sort orders as the
+sessions list in the web UI. The session index is brought up to date first.
+
+Examples:
+  agento sessions
+  agento sessions -q "flaky test" --from 2026-08-01
+  agento sessions --sort cost --limit 5
+  agento sessions --project /home/me/app --json | jq -r '.[].session_id'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runSessions(cmd.Context(), cfg, opts, cmd.OutOrStdout())
+		},
+	}
+
+	opts.register(cmd)
+	cmd.Flags().StringVar(&opts.sort, "sort", string(claudesessions.SortRecent),
+		"recent, cost, tokens, duration or messages")
+	cmd.Flags().IntVarP(&opts.limit, "limit", "n", 20, "Sessions to print (0 for all)")
+	cmd.Flags().BoolVar(&opts.json, "json", false, "Print JSON instead of a table")
+
+	return cmd
+}
+
+func runSessions(parent context.Context, cfg *config.AppConfig, opts sessionsOptions, out io.Writer) error {
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	q, err := opts.query()
+	if err != nil {
+		return err
+	}
+	switch s := claudesessions.SessionSort(opts.sort); s {
+	case claudesessions.SortRecent, claudesessions.SortCost, claudesessions.SortTokens,
+		claudesessions.SortDuration, claudesessions.SortMessages:
+		q.Sort = s
+	default:
+		return fmt.Errorf("invalid --sort %q (want recent, cost, tokens, duration or messages)", opts.sort)
+	}
+
+	idx, err := openSessionIndex(ctx, cfg, true)
+	if err != nil {
+		return err
+	}
+	defer idx.cleanup()
+
+	sessions, err := listSessions(idx.cache, q, opts.limit)
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sessions)
+	}
+	return printSessions(out, sessions, time.Local)
+}
+
+// sessionPager is the part of the session cache listSessions reads.
+type sessionPager interface {
+	ListPage(q claudesessions.SessionQuery) (claudesessions.SessionPage, error)
+}
+
+// listSessions reads pages until it has limit sessions, or every match when
+// limit is zero.
+func listSessions(pager sessionPager, q claudesessions.SessionQuery, limit int) (
+	[]claudesessions.ClaudeSessionSummary, error,
+) {
+	out := []claudesessions.ClaudeSessionSummary{}
+	for {
+		q.Limit = claudesessions.MaxPageSize
+		if limit > 0 {
+			q.Limit = min(limit-len(out), claudesessions.MaxPageSize)
+		}
+		page, err := pager.ListPage(q)
+		if err != nil {
+			return nil, fmt.Errorf("listing sessions: %w", err)
+		}
+		out = append(out, page.Items...)
+		if !page.HasMore || (limit > 0 && len(out) >= limit) {
+			return out, nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+func printSessions(out io.Writer, sessions []claudesessions.ClaudeSessionSummary, loc *time.Location) error {
+	if len(sessions) == 0 {
+		_, err := fmt.Fprintln(out, "No sessions match.")
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SESSION\tLAST ACTIVE\tPROJECT\tMODEL\tMSGS\tACTIVE\tTOKENS\tCOST\tTITLE")
+	for _, s := range sessions {
+		u := s.TotalUsage()
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			shortID(s.SessionID), formatWhen(s.LastActivity, loc), shortenHome(s.ProjectPath), s.Model,
+			s.MessageCount, formatDurationMs(s.TotalActiveDurationMs()),
+			formatTokens(u.InputTokens+u.OutputTokens), formatUSD(s.TotalCost().TotalUSD),
+			truncate(s.DisplayTitle, 50))
+	}
+	return w.Flush()
+}
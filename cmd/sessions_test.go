@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/claudesessions"
+)
+
+// fakePager serves n sessions in pages of at most q.Limit.
+type fakePager struct {
+	n      int
+	limits []int
+}
+
+func (f *fakePager) ListPage(q claudesessions.SessionQuery) (claudesessions.SessionPage, error) {
+	f.limits = append(f.limits, q.Limit)
+	start := 0
+	if q.Cursor != "" {
+		start, _ = strconv.Atoi(q.Cursor)
+	}
+	end := min(start+q.Limit, f.n)
+	var page claudesessions.SessionPage
+	for i := start; i < end; i++ {
+		page.Items = append(page.Items, claudesessions.ClaudeSessionSummary{SessionID: strconv.Itoa(i)})
+	}
+	if end < f.n {
+		page.NextCursor, page.HasMore = strconv.Itoa(end), true
+	}
+	return page, nil
+}
+
+func TestListSessions_Limit(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      int
+		limit      int
+		want       int
+		wantLimits []int
+	}{
+		{name: "one short page", total: 50, limit: 20, want: 20, wantLimits: []int{20}},
+		{name: "across pages", total: 500, limit: 250, want: 250, wantLimits: []int{200, 50}},
+		{name: "all", total: 450, limit: 0, want: 450, wantLimits: []int{200, 200, 200}},
+		{name: "fewer than asked", total: 3, limit: 20, want: 3, wantLimits: []int{20}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pager := &fakePager{n: tc.total}
+			got, err := listSessions(pager, claudesessions.SessionQuery{}, tc.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tc.want {
+				t.Errorf("got %d sessions, want %d", len(got), tc.want)
+			}
+			if len(pager.limits) != len(tc.wantLimits) {
+				t.Fatalf("page sizes = %v, want %v", pager.limits, tc.wantLimits)
+			}
+			for i := range tc.wantLimits {
+				if pager.limits[i] != tc.wantLimits[i] {
+					t.Errorf("page sizes = %v, want %v", pager.limits, tc.wantLimits)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestPrintSessions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printSessions(&buf, nil, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "No sessions match.\n" {
+		t.Errorf("empty list printed %q", buf.String())
+	}
+
+	buf.Reset()
+	sessions := []claudesessions.ClaudeSessionSummary{{
+		SessionID: "fedcba9876543210", DisplayTitle: "Add export", ProjectPath: "/srv/app", Model: "claude-opus-4",
+		MessageCount: 14, ActiveDurationMs: 42_000, LastActivity: time.Date(2026, 8, 15, 9, 5, 0, 0, time.UTC),
+		Usage: claudesessions.TokenUsage{InputTokens: 1500, OutputTokens: 500},
+		Cost:  claudesessions.SessionCost{TotalUSD: 0.75},
+	}}
+	if err := printSessions(&buf, sessions, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "SESSION") {
+		t.Fatalf("table = %q", buf.String())
+	}
+	for _, want := range []string{"fedcba98", "2026-08-15 09:05", "/srv/app", "14", "42s", "2.0k", "$0.75", "Add export"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("row %q lacks %q", lines[1], want)
+		}
+	}
+}
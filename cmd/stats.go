@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shaharia-lab/agento/internal/claudesessions"
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+// statsOptions holds the flags of the stats command.
+type statsOptions struct {
+	from    string
+	to      string
+	project string
+	tz      string
+	top     int
+	json    bool
+}
+
+// NewStatsCmd returns the "stats" subcommand, which prints the analytics
+// dashboard's headline figures without starting the web server.
+func NewStatsCmd(cfg *config.AppConfig) *cobra.Command {
+	var opts statsOptions
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Print Claude Code cost and usage statistics",
+		Long: `Print the cost and usage summary the analytics dashboard shows: totals,
+the month's spend forecast, and breakdowns by model and by project, with the
+most expensive sessions. The session index is brought up to date first.
+
+Examples:
+  agento stats
+  agento stats --from 2026-08-01 --to 2026-08-31
+  agento stats --project /home/me/app --top 5
+  agento stats --json | jq .cost_summary.total_cost_usd`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runStats(cmd.Context(), cfg, opts, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.from, "from", "", "Start of the window, YYYY-MM-DD or RFC3339 (default: 30 days ago)")
+	cmd.Flags().StringVar(&opts.to, "to", "", "End of the window, YYYY-MM-DD (inclusive) or RFC3339 (default: now)")
+	cmd.Flags().StringVar(&opts.project, "project", "", "Only this project path")
+	cmd.Flags().StringVar(&opts.tz, "tz", "", "IANA timezone days are counted in (default: local)")
+	cmd.Flags().IntVar(&opts.top, "top", 10, "Rows per breakdown")
+	cmd.Flags().BoolVar(&opts.json, "json", false, "Print JSON instead of tables")
+
+	return cmd
+}
+
+// statsReport is what `agento stats --json` prints: the parts of the
+// analytics report the tables show, not its chart series.
+type statsReport struct {
+	From        time.Time                       `json:"from"`
+	To          time.Time                       `json:"to"`
+	Project     string                          `json:"project,omitempty"`
+	Summary     claudesessions.AnalyticsSummary `json:"summary"`
+	CostSummary claudesessions.CostSummary      `json:"cost_summary"`
+	Forecast    claudesessions.SpendForecast    `json:"forecast"`
+	ByModel     []claudesessions.ModelCostStat  `json:"by_model"`
+	ByProject   []claudesessions.ProjectStat    `json:"by_project"`
+	TopSessions []claudesessions.SessionRanking `json:"top_sessions"`
+	Anomalies   []claudesessions.Anomaly        `json:"anomalies"`
+}
+
+func runStats(parent context.Context, cfg *config.AppConfig, opts statsOptions, out io.Writer) error {
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	params, err := opts.params(time.Now())
+	if err != nil {
+		return err
+	}
+
+	idx, err := openSessionIndex(ctx, cfg, true)
+	if err != nil {
+		return err
+	}
+	defer idx.cleanup()
+
+	report := newStatsReport(idx.cache.Analytics(params), params, opts.top)
+	if opts.json {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return printStats(out, report, params.Loc)
+}
+
+// params resolves the window the way the analytics endpoint does: the last
+// 30 days by default, with bare dates read in the chosen timezone.
+func (o statsOptions) params(now time.Time) (claudesessions.AnalyticsParams, error) {
+	loc := time.Local
+	if o.tz != "" {
+		l, err := time.LoadLocation(o.tz)
+		if err != nil {
+			return claudesessions.AnalyticsParams{}, fmt.Errorf("invalid --tz: %w", err)
+		}
+		loc = l
+	}
+
+	p := claudesessions.AnalyticsParams{
+		From:    now.In(loc).AddDate(0, 0, -30),
+		To:      now.In(loc),
+		Project: o.project,
+		Loc:     loc,
+	}
+	if o.from != "" {
+		from, err := parseCLITime(o.from, false, loc)
+		if err != nil {
+			return p, fmt.Errorf("invalid --from: %w", err)
+		}
+		p.From = from
+	}
+	if o.to != "" {
+		to, err := parseCLITime(o.to, true, loc)
+		if err != nil {
+			return p, fmt.Errorf("invalid --to: %w", err)
+		}
+		p.To = to
+	}
+	if !p.From.Before(p.To) {
+		return p, fmt.Errorf("--from must be before --to")
+	}
+	return p, nil
+}
+
+func newStatsReport(r claudesessions.AnalyticsReport, p claudesessions.AnalyticsParams, top int) statsReport {
+	return statsReport{
+		From:        p.From,
+		To:          p.To,
+		Project:     p.Project,
+		Summary:     r.Summary,
+		CostSummary: r.CostSummary,
+		Forecast:    r.Forecast,
+		ByModel:     firstN(r.CostByModel, top),
+		ByProject:   firstN(r.ProjectBreakdown, top),
+		TopSessions: firstN(r.TopSessions.ByCost, top),
+		Anomalies:   r.Anomalies,
+	}
+}
+
+// firstN returns at most n leading items, never nil, so --json prints [].
+func firstN[T any](items []T, n int) []T {
+	if n >= 0 && len(items) > n {
+		items = items[:n]
+	}
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func printStats(out io.Writer, r statsReport, loc *time.Location) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	scope := "all projects"
+	if r.Project != "" {
+		scope = r.Project
+	}
+	fmt.Fprintf(w, "Claude Code usage, %s to %s (%s), %s\n\n",
+		r.From.In(loc).Format("2006-01-02"), r.To.In(loc).Format("2006-01-02"), loc, scope)
+
+	s, c := r.Summary, r.CostSummary
+	fmt.Fprintf(w, "Sessions\t%d in %d projects\n", s.TotalSessions, s.UniqueProjects)
+	fmt.Fprintf(w, "Cost\t%s\t(input %s, output %s, cache read %s, cache write %s)\n",
+		formatUSD(c.TotalCostUSD), formatUSD(c.InputCostUSD), formatUSD(c.OutputCostUSD),
+		formatUSD(c.CacheReadCostUSD), formatUSD(c.CacheWriteCostUSD))
+	fmt.Fprintf(w, "Tokens\t%s in, %s out\t(cache: %s read, %s written)\n",
+		formatTokens(s.TotalInputTokens), formatTokens(s.TotalOutputTokens),
+		formatTokens(s.TotalCacheReadTokens), formatTokens(s.TotalCacheCreationTokens))
+	if f := r.Forecast; f.DaysRemaining > 0 {
+		fmt.Fprintf(w, "Forecast\t%s projected for %s\t(%s so far, %s/day)\n",
+			formatUSD(f.ProjectedUSD), f.Month, formatUSD(f.MonthToDateUSD), formatUSD(f.DailyRateUSD))
+	}
+	if len(s.UnknownPricingModels) > 0 {
+		fmt.Fprintf(w, "Unpriced\t%s tokens on %s\n",
+			formatTokens(s.UnknownPricingTokens), strings.Join(s.UnknownPricingModels, ", "))
+	}
+	for _, a := range r.Anomalies {
+		fmt.Fprintf(w, "Anomaly\t%s\n", describeAnomaly(a))
+	}
+
+	if len(r.ByModel) > 0 {
+		fmt.Fprintln(w, "\nBY MODEL\tCOST\tSHARE\tSESSIONS")
+		for _, m := range r.ByModel {
+			fmt.Fprintf(w, "%s\t%s\t%.1f%%\t%d\n", m.Model, formatUSD(m.Cost.TotalUSD), m.Percentage, m.Sessions)
+		}
+	}
+
+	if len(r.ByProject) > 0 {
+		fmt.Fprintln(w, "\nBY PROJECT\tCOST\tSHARE\tSESSIONS\tLAST ACTIVE")
+		for _, p := range r.ByProject {
+			name := shortenHome(p.Project)
+			if p.FoldedProjects > 0 {
+				name = fmt.Sprintf("(%d other projects)", p.FoldedProjects)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%.1f%%\t%d\t%s\n", name, formatUSD(p.Cost.TotalUSD), p.Percentage,
+				p.Sessions, formatWhen(p.LastActivity, loc))
+		}
+	}
+
+	if len(r.TopSessions) > 0 {
+		fmt.Fprintln(w, "\nTOP SESSIONS\tCOST\tTOKENS\tACTIVE\tPROJECT\tTITLE")
+		for _, t := range r.TopSessions {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", shortID(t.SessionID), formatUSD(t.CostUSD),
+				formatTokens(t.Tokens), formatDurationMs(t.DurationMs), shortenHome(t.Project), truncate(t.Title, 50))
+		}
+	}
+
+	return w.Flush()
+}
+
+func describeAnomaly(a claudesessions.Anomaly) string {
+	subject := "day " + a.Date
+	if a.Scope == claudesessions.AnomalyScopeSession {
+		subject = "session " + shortID(a.SessionID) + " on " + a.Date
+	}
+	value, baseline := formatUSD(a.Value), formatUSD(a.Baseline)
+	if a.Metric == claudesessions.AnomalyMetricTokens {
+		value, baseline = formatTokens(int(a.Value))+" tokens", formatTokens(int(a.Baseline))
+	}
+	return fmt.Sprintf("%s: %s against a usual %s", subject, value, baseline)
+}
+
+func formatUSD(v float64) string {
+	return fmt.Sprintf("$%.2f", v)
+}
+
+// formatTokens abbreviates a count the way the dashboard does: 950, 12.3k,
+// 4.5M, 1.2B.
+func formatTokens(n int) string {
+	v := float64(n)
+	switch {
+	case v >= 1e9:
+		return fmt.Sprintf("%.1fB", v/1e9)
+	case v >= 1e6:
+		return fmt.Sprintf("%.1fM", v/1e6)
+	case v >= 1e3:
+		return fmt.Sprintf("%.1fk", v/1e3)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+func formatDurationMs(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func formatWhen(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.In(loc).Format("2006-01-02 15:04")
+}
+
+// shortID is the first eight characters of a session UUID — unique enough to
+// read, and a prefix `agento sessions -q` finds.
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// shortenHome writes a path under the home directory as ~/….
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if strings.HasPrefix(path, home+"/") {
+		return "~" + path[len(home):]
+	}
+	return path
+}
+
+// truncate cuts s to n runes, marking the cut, and flattens newlines so a
+// title cannot break a table row.
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/claudesessions"
+)
+
+func TestStatsOptions_Params(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.UTC)
+
+	p, err := statsOptions{tz: "Europe/Berlin"}.params(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.To.Equal(now) || !p.From.Equal(now.AddDate(0, 0, -30)) || p.Loc.String() != "Europe/Berlin" {
+		t.Errorf("default window = %v to %v in %v, want the 30 days to now in Berlin", p.From, p.To, p.Loc)
+	}
+
+	p, err = statsOptions{tz: "Europe/Berlin", from: "2026-08-01", to: "2026-08-31"}.params(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFrom := time.Date(2026, 8, 1, 0, 0, 0, 0, berlin)
+	wantTo := time.Date(2026, 9, 1, 0, 0, 0, 0, berlin).Add(-time.Nanosecond)
+	if !p.From.Equal(wantFrom) || !p.To.Equal(wantTo) {
+		t.Errorf("window = %v to %v, want August in Berlin, inclusive of the 31st", p.From, p.To)
+	}
+
+	for _, bad := range []statsOptions{
+		{tz: "Mars/Olympus"},
+		{from: "yesterday"},
+		{from: "2026-08-31", to: "2026-08-01"},
+	} {
+		if _, err := bad.params(now); err == nil {
+			t.Errorf("params(%+v): expected an error", bad)
+		}
+	}
+}
+
+func TestPrintStats(t *testing.T) {
+	at := time.Date(2026, 8, 14, 18, 30, 0, 0, time.UTC)
+	report := claudesessions.AnalyticsReport{
+		Summary: claudesessions.AnalyticsSummary{
+			TotalSessions: 12, UniqueProjects: 2, TotalInputTokens: 1_260_000, TotalOutputTokens: 98_000,
+		},
+		CostSummary: claudesessions.CostSummary{TotalCostUSD: 42.5, OutputCostUSD: 30},
+		Forecast: claudesessions.SpendForecast{
+			Month: "2026-08", MonthToDateUSD: 42.5, ProjectedUSD: 90, DailyRateUSD: 2.8, DaysRemaining: 17,
+		},
+		CostByModel: []claudesessions.ModelCostStat{
+			{Model: "claude-opus-4", Cost: claudesessions.SessionCost{TotalUSD: 40}, Percentage: 94.1, Sessions: 9},
+			{Model: "claude-haiku-4", Cost: claudesessions.SessionCost{TotalUSD: 2.5}, Percentage: 5.9, Sessions: 3},
+		},
+		ProjectBreakdown: []claudesessions.ProjectStat{
+			{Project: "/srv/app", Cost: claudesessions.SessionCost{TotalUSD: 42.5}, Percentage: 100, Sessions: 12,
+				LastActivity: at},
+		},
+		TopSessions: claudesessions.TopSessions{ByCost: []claudesessions.SessionRanking{
+			{SessionID: "0123456789abcdef", Title: "Fix the\nflaky test", CostUSD: 12, Tokens: 340_000,
+				DurationMs: 95 * 60_000, Project: "/srv/app"},
+		}},
+		Anomalies: []claudesessions.Anomaly{
+			{Scope: claudesessions.AnomalyScopeDay, Metric: claudesessions.AnomalyMetricCost, Date: "2026-08-14",
+				Value: 20, Baseline: 2},
+		},
+	}
+	p := claudesessions.AnalyticsParams{
+		From: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 8, 14, 23, 0, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	if err := printStats(&buf, newStatsReport(report, p, 1), time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"2026-08-01 to 2026-08-14 (UTC), all projects",
+		"12 in 2 projects",
+		"$42.50",
+		"1.3M in, 98.0k out",
+		"$90.00 projected for 2026-08",
+		"day 2026-08-14: $20.00 against a usual $2.00",
+		"claude-opus-4",
+		"/srv/app",
+		"2026-08-14 18:30",
+		"01234567",
+		"1h35m",
+		"Fix the flaky test",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "claude-haiku-4") {
+		t.Errorf("--top 1 should print one model:\n%s", out)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -19,6 +20,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/shaharia-lab/agento/internal/api"
+	"github.com/shaharia-lab/agento/internal/approval"
+	"github.com/shaharia-lab/agento/internal/audit"
+	"github.com/shaharia-lab/agento/internal/auth"
+	"github.com/shaharia-lab/agento/internal/budget"
 	"github.com/shaharia-lab/agento/internal/build"
 	"github.com/shaharia-lab/agento/internal/claudesessions"
 	"github.com/shaharia-lab/agento/internal/config"
@@ -32,6 +37,7 @@ import (
 	telegramintegration "github.com/shaharia-lab/agento/internal/integrations/telegram"
 	whatsappintegration "github.com/shaharia-lab/agento/internal/integrations/whatsapp"
 	"github.com/shaharia-lab/agento/internal/logger"
+	"github.com/shaharia-lab/agento/internal/mcpserver"
 	"github.com/shaharia-lab/agento/internal/notification"
 	"github.com/shaharia-lab/agento/internal/pricing"
 	"github.com/shaharia-lab/agento/internal/scheduler"
@@ -110,7 +116,13 @@ func runWeb(cfg *config.AppConfig, noBrowser bool) error {
 	}
 	defer dbCleanup()
 
-	srv, monitoringMgr, err := buildWebServer(ctx, cfg, db, sysLogger, otelCfg, otelProviders)
+	cipher, err := initSecrets(ctx, cfg, db, sysLogger)
+	if err != nil {
+		sysLogger.Error("startup failed", "error", err)
+		return err
+	}
+
+	srv, monitoringMgr, err := buildWebServer(ctx, cfg, db, cipher, sysLogger, otelCfg, otelProviders)
 	if err != nil {
 		sysLogger.Error("startup failed", "error", err)
 		return err
@@ -236,7 +248,7 @@ func initDatabase(cfg *config.AppConfig, sysLogger *slog.Logger) (*sql.DB, func(
 // the router's guards need.
 func newHTTPServer(
 	cfg *config.AppConfig, result *buildAPIServerResult, sysLogger *slog.Logger,
-	monitoringMgr *telemetry.MonitoringManager, settingsMgr *config.SettingsManager,
+	monitoringMgr *telemetry.MonitoringManager, settingsMgr *config.SettingsManager, authMgr *auth.Manager,
 ) *server.Server {
 	return server.New(result.apiSrv, WebFS, cfg.Port, sysLogger, monitoringMgr,
 		result.webhookHandler, server.Options{
@@ -245,12 +257,14 @@ func newHTTPServer(
 			// Read per request: the stored value is editable in the UI, and
 			// snapshotting it here would 403 the browser until a restart.
 			PublicURLFunc: func() string { return settingsMgr.Get().PublicURL },
+			MCP:           result.mcpHandler,
+			Auth:          authMgr,
 		})
 }
 
 func buildWebServer(
 	ctx context.Context, cfg *config.AppConfig,
-	db *sql.DB, sysLogger *slog.Logger,
+	db *sql.DB, cipher storage.CredentialCipher, sysLogger *slog.Logger,
 	otelCfg telemetry.MonitoringConfig, otelProviders *telemetry.Providers,
 ) (*server.Server, *telemetry.MonitoringManager, error) {
 	agentStore := storage.NewSQLiteAgentStore(db)
@@ -266,7 +280,7 @@ func buildWebServer(
 	}
 
 	chatStore := storage.NewSQLiteChatStore(db)
-	integrationStore := storage.NewSQLiteIntegrationStore(db)
+	integrationStore := storage.NewSQLiteIntegrationStore(db, cipher)
 	integrationRegistry := buildIntegrationRegistry(ctx, integrationStore, cfg, sysLogger)
 
 	settingsStore := storage.NewSQLiteSettingsStore(db)
@@ -285,8 +299,13 @@ func buildWebServer(
 	// service, the scanner and the agent runner all read this snapshot, and a
 	// run that started on the default would target the wrong account.
 	config.ApplyClaudeDirs(saved.ClaudeConfigDir, saved.ClaudeConfigDirs)
+	claudesessions.ApplyImportedAgents(saved.ImportedAgents)
+	claudesessions.ApplyUsageWindowSettings(saved.UsageLimitFiveHourUSD, saved.UsageLimitWeeklyUSD,
+		saved.UsageWarnPercent)
+	installTranscriptArchive(db, cfg, saved, sysLogger)
 
 	monitoringMgr := initMonitoringManager(cfg.DataDir, otelProviders, otelCfg, sysLogger)
+	authMgr := newAuthManager(ctx, db, sysLogger)
 
 	result, err := buildAPIServer(ctx, appDeps{
 		db:                  db,
@@ -300,11 +319,13 @@ func buildWebServer(
 		localToolsMCP:       localToolsMCP,
 		settingsMgr:         settingsMgr,
 		monitoringMgr:       monitoringMgr,
+		toolAudit:           audit.NewRecorder(storage.NewSQLiteToolCallStore(db), sysLogger),
+		authMgr:             authMgr,
 	})
 	if err != nil {
 		return nil, nil, err
 	}
-	srv := newHTTPServer(cfg, result, sysLogger, monitoringMgr, settingsMgr)
+	srv := newHTTPServer(cfg, result, sysLogger, monitoringMgr, settingsMgr, authMgr)
 
 	// On shutdown: clean up pairing sessions, close the event bus so no further
 	// events are enqueued, then wait for in-flight worker goroutines to finish.
@@ -365,6 +386,8 @@ type appDeps struct {
 	localToolsMCP       *tools.LocalMCPConfig
 	settingsMgr         *config.SettingsManager
 	monitoringMgr       *telemetry.MonitoringManager
+	toolAudit           *audit.Recorder
+	authMgr             *auth.Manager
 }
 
 // buildAPIServerResult holds all objects returned by buildAPIServer.
@@ -372,8 +395,9 @@ type buildAPIServerResult struct {
 	apiSrv             *api.Server
 	bus                eventbus.EventBus
 	insightWorker      *claudesessions.InsightWorker
-	webhookHandler     *api.TelegramWebhookHandler
+	webhookHandler     api.WebhookHandlers
 	whatsappPairingMgr *whatsappintegration.PairingManager
+	mcpHandler         http.Handler
 }
 
 // buildAPIServer wires all services and returns the api.Server, event bus, and webhook handler.
@@ -384,33 +408,40 @@ func buildAPIServer(
 
 	taskStore := storage.NewSQLiteTaskStore(deps.db)
 	triggerStore := storage.NewSQLiteTriggerStore(deps.db)
+	budgetStore := storage.NewSQLiteBudgetStore(deps.db)
+	pricingStore := pricing.NewStore(deps.db, deps.logger)
+	budgetEnforcer := budget.NewEnforcer(budgetStore, pricingStore, bus, deps.logger)
+	approvals := buildApprovalBroker(ctx, deps)
 
-	taskScheduler, err := initTaskScheduler(ctx, deps, taskStore, bus)
+	taskScheduler, err := initTaskScheduler(ctx, deps, taskStore, bus, budgetEnforcer, approvals)
 	if err != nil {
 		return nil, err
 	}
 
-	pricingStore := pricing.NewStore(deps.db, deps.logger)
 	sessionCache, insightStore, insightWorker := setupInsights(
 		ctx, deps.db, deps.logger, bus, pricingStore,
 	)
 
-	dispatcher := buildTriggerDispatcher(ctx, deps, triggerStore)
-	webhookHandler := api.NewTelegramWebhookHandler(triggerStore, deps.integrationStore, dispatcher, deps.logger)
+	dispatcher := buildTriggerDispatcher(ctx, deps, triggerStore, budgetEnforcer, approvals)
+	triggerSvc := service.NewTriggerService(
+		triggerStore, deps.integrationStore, deps.settingsMgr, deps.appConfig, deps.logger,
+	)
+	startTelegramPoller(ctx, deps, triggerStore, dispatcher, triggerSvc)
+	webhookHandler := buildWebhookHandlers(deps, triggerStore, dispatcher)
 
 	whatsappPairingMgr := whatsappintegration.NewPairingManager(deps.appConfig.DataDir, deps.logger)
+	taskSvc := service.NewTaskService(taskStore, taskScheduler, deps.logger)
 
 	apiSrv := api.New(api.ServerConfig{
-		AgentSvc:        service.NewAgentService(deps.agentStore, deps.logger),
-		ChatSvc:         buildChatService(deps),
-		IntegrationSvc:  service.NewIntegrationService(deps.integrationStore, deps.integrationRegistry, deps.logger),
-		NotificationSvc: service.NewNotificationService(deps.settingsMgr, notifStore),
-		TaskSvc:         service.NewTaskService(taskStore, taskScheduler, deps.logger),
-		TriggerSvc: service.NewTriggerService(
-			triggerStore, deps.integrationStore, deps.settingsMgr, deps.appConfig, deps.logger,
-		),
+		AgentSvc:           service.NewAgentService(deps.agentStore, deps.logger),
+		ChatSvc:            buildChatService(deps),
+		IntegrationSvc:     service.NewIntegrationService(deps.integrationStore, deps.integrationRegistry, deps.logger),
+		NotificationSvc:    service.NewNotificationService(deps.settingsMgr, notifStore),
+		TaskSvc:            taskSvc,
+		TriggerSvc:         triggerSvc,
 		ProfileSvc:         service.NewClaudeSettingsProfileService(deps.logger),
 		PricingSvc:         service.NewPricingService(pricingStore, sessionCache, deps.logger),
+		BudgetSvc:          service.NewBudgetService(budgetStore, deps.logger),
 		SettingsMgr:        deps.settingsMgr,
 		AppConfig:          deps.appConfig,
 		Logger:             deps.logger,
@@ -418,6 +449,10 @@ func buildAPIServer(
 		MonitoringMgr:      deps.monitoringMgr,
 		InsightStore:       insightStore,
 		WhatsAppPairingMgr: whatsappPairingMgr,
+		Approvals:          approvals,
+		ToolCalls:          deps.toolAudit,
+		Auth:               deps.authMgr,
+		Backups:            startBackupScheduler(ctx, deps),
 	})
 	return &buildAPIServerResult{
 		apiSrv:             apiSrv,
@@ -425,9 +460,42 @@ func buildAPIServer(
 		insightWorker:      insightWorker,
 		webhookHandler:     webhookHandler,
 		whatsappPairingMgr: whatsappPairingMgr,
+		mcpHandler:         buildMCPHandler(deps, taskSvc, sessionCache, budgetEnforcer, approvals),
 	}, nil
 }
 
+// buildMCPHandler returns the /mcp endpoint, which serves Agento's agents,
+// tasks and usage analytics to MCP clients.
+func buildMCPHandler(
+	deps appDeps, taskSvc service.TaskService, sessions mcpserver.SessionIndex,
+	budgetEnforcer *budget.Enforcer, approvals *approval.Broker,
+) http.Handler {
+	return mcpserver.NewHTTPHandler(mcpserver.New(mcpserver.Config{
+		Agents:              deps.agentStore,
+		Tasks:               taskSvc,
+		Sessions:            sessions,
+		LocalToolsMCP:       deps.localToolsMCP,
+		MCPRegistry:         deps.mcpRegistry,
+		IntegrationRegistry: deps.integrationRegistry,
+		Budget:              budgetEnforcer,
+		Approvals:           approvals,
+		Audit:               deps.toolAudit,
+		Logger:              deps.logger,
+	}))
+}
+
+// buildWebhookHandlers returns the inbound webhook endpoints of the trigger
+// integrations.
+func buildWebhookHandlers(
+	deps appDeps, triggerStore storage.TriggerStore, dispatcher *trigger.Dispatcher,
+) api.WebhookHandlers {
+	return api.WebhookHandlers{
+		api.NewTelegramWebhookHandler(triggerStore, deps.integrationStore, dispatcher, deps.logger),
+		api.NewGenericWebhookHandler(triggerStore, deps.integrationStore, dispatcher, deps.logger),
+		api.NewGitHubWebhookHandler(triggerStore, deps.integrationStore, dispatcher, deps.logger),
+	}
+}
+
 func buildChatService(deps appDeps) service.ChatService {
 	return service.NewChatService(
 		deps.chatStore, deps.agentStore, deps.mcpRegistry, deps.localToolsMCP,
@@ -451,7 +519,10 @@ func setupInsights(
 	return sessionCache, insightStore, insightWorker
 }
 
-func buildTriggerDispatcher(ctx context.Context, deps appDeps, triggerStore storage.TriggerStore) *trigger.Dispatcher {
+func buildTriggerDispatcher(
+	ctx context.Context, deps appDeps, triggerStore storage.TriggerStore, budgetEnforcer *budget.Enforcer,
+	approvals *approval.Broker,
+) *trigger.Dispatcher {
 	return trigger.NewDispatcher(trigger.DispatcherConfig{
 		TriggerStore:        triggerStore,
 		AgentStore:          deps.agentStore,
@@ -463,7 +534,59 @@ func buildTriggerDispatcher(ctx context.Context, deps appDeps, triggerStore stor
 		SettingsMgr:         deps.settingsMgr,
 		Logger:              deps.logger,
 		Ctx:                 ctx,
+		Budget:              budgetEnforcer,
+		Approvals:           approvals,
+		Audit:               deps.toolAudit,
+	})
+}
+
+// approvalSettings loads the approval settings from the notification
+// settings, for components that only need to know where requests go.
+func approvalSettings(settingsMgr *config.SettingsManager) notification.ApprovalSettings {
+	ns, err := loadNotificationSettingsFromJSON(settingsMgr.Get().NotificationSettings)
+	if err != nil {
+		return notification.ApprovalSettings{}
+	}
+	return ns.Approvals
+}
+
+// buildApprovalBroker creates the broker that holds gated tool calls of
+// unattended runs for approval, expiring what a previous run left pending.
+func buildApprovalBroker(ctx context.Context, deps appDeps) *approval.Broker {
+	broker := approval.NewBroker(approval.Config{
+		Store:            storage.NewSQLiteApprovalStore(deps.db),
+		IntegrationStore: deps.integrationStore,
+		Settings: func() (*notification.NotificationSettings, error) {
+			return loadNotificationSettingsFromJSON(deps.settingsMgr.Get().NotificationSettings)
+		},
+		PublicURL: func() string { return deps.settingsMgr.Get().PublicURL },
+		Logger:    deps.logger,
 	})
+	broker.ExpireStale(ctx)
+	return broker
+}
+
+// startTelegramPoller starts long polling for Telegram integrations, which
+// takes over from webhooks while no public URL is configured.
+func startTelegramPoller(
+	ctx context.Context, deps appDeps, triggerStore storage.TriggerStore,
+	dispatcher *trigger.Dispatcher, triggerSvc service.TriggerService,
+) {
+	trigger.NewTelegramPoller(trigger.TelegramPollerConfig{
+		TriggerStore:     triggerStore,
+		IntegrationStore: deps.integrationStore,
+		Dispatcher:       dispatcher,
+		Webhooks:         triggerSvc,
+		// The stored value already carries AGENTO_PUBLIC_URL when it is set.
+		PublicURL: func() string { return deps.settingsMgr.Get().PublicURL },
+		ApprovalIntegrationID: func() string {
+			if settings := approvalSettings(deps.settingsMgr); settings.Channel == notification.ApprovalChannelTelegram {
+				return settings.IntegrationID
+			}
+			return ""
+		},
+		Logger: deps.logger,
+	}).Start(ctx)
 }
 
 // setupNotifications creates the notification store, event bus, and wires the
@@ -511,7 +634,7 @@ func loadNotificationSettingsFromJSON(raw string) (*notification.NotificationSet
 
 func initTaskScheduler(
 	ctx context.Context, deps appDeps, taskStore storage.TaskStore,
-	eventPublisher scheduler.EventPublisher,
+	eventPublisher scheduler.EventPublisher, budgetEnforcer *budget.Enforcer, approvals *approval.Broker,
 ) (*scheduler.Scheduler, error) {
 	taskScheduler, err := scheduler.New(scheduler.Config{
 		TaskStore:           taskStore,
@@ -523,6 +646,9 @@ func initTaskScheduler(
 		SettingsManager:     deps.settingsMgr,
 		Logger:              deps.logger,
 		EventPublisher:      eventPublisher,
+		Budget:              budgetEnforcer,
+		Approvals:           approvals,
+		Audit:               deps.toolAudit,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("creating task scheduler: %w", err)
@@ -27,6 +27,8 @@ import TasksPage from '@/pages/TasksPage'
 import TaskCreatePage from '@/pages/TaskCreatePage'
 import TaskEditPage from '@/pages/TaskEditPage'
 import JobHistoriesPage from '@/pages/JobHistoriesPage'
+import ApprovalsPage from '@/pages/ApprovalsPage'
+import ToolCallsPage from '@/pages/ToolCallsPage'
 import OnboardingWizard from '@/components/OnboardingWizard'
 import { AppearanceProvider } from '@/contexts/ThemeContext'
 import { settingsApi } from '@/lib/api'
@@ -109,6 +111,8 @@ export default function App() {
             <Route path="tasks/new" element={<TaskCreatePage />} />
             <Route path="tasks/:id/edit" element={<TaskEditPage />} />
             <Route path="job-history" element={<JobHistoriesPage />} />
+            <Route path="approvals" element={<ApprovalsPage />} />
+            <Route path="tool-calls" element={<ToolCallsPage />} />
             <Route path="settings" element={<SettingsPage />} />
           </Route>
         </Routes>
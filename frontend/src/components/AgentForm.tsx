@@ -6,6 +6,8 @@ import { MODELS, BUILT_IN_TOOLS } from '@/types'
 import { Button } from '@/components/ui/button'
 import { Input } from '@/components/ui/input'
 import { Label } from '@/components/ui/label'
+import { Textarea } from '@/components/ui/textarea'
+import ToolPolicyEditor, { draftToPolicy, policyToDraft } from '@/components/ToolPolicyEditor'
 import {
   Select,
   SelectContent,
@@ -123,6 +125,10 @@ export default function AgentForm({ agent, isEdit = false }: AgentFormProps) {
   const [systemPrompt, setSystemPrompt] = useState(agent?.system_prompt ?? '')
   const [claudeConfigDir, setClaudeConfigDir] = useState(agent?.claude_config_dir ?? '')
   const [builtInTools, setBuiltInTools] = useState<string[]>(agent?.capabilities?.built_in ?? [])
+  const [requireApproval, setRequireApproval] = useState(
+    (agent?.capabilities?.require_approval ?? []).join('\n'),
+  )
+  const [policyDraft, setPolicyDraft] = useState(() => policyToDraft(agent?.capabilities?.policy))
 
   const [mcpTools, setMcpTools] = useState<Record<string, string[]>>(() => {
     const mcp = agent?.capabilities?.mcp ?? {}
@@ -189,6 +195,11 @@ export default function AgentForm({ agent, isEdit = false }: AgentFormProps) {
       for (const [id, tools] of Object.entries(mcpTools)) {
         if (tools.length > 0) mcp[id] = { tools }
       }
+      const gated = requireApproval
+        .split(/[\n,]/)
+        .map(t => t.trim())
+        .filter(Boolean)
+      const policy = draftToPolicy(policyDraft)
       const payload: Partial<Agent> = {
         name,
         slug,
@@ -201,6 +212,8 @@ export default function AgentForm({ agent, isEdit = false }: AgentFormProps) {
         capabilities: {
           built_in: builtInTools,
           ...(Object.keys(mcp).length > 0 ? { mcp } : {}),
+          ...(gated.length > 0 ? { require_approval: gated } : {}),
+          ...(policy ? { policy } : {}),
         },
       }
       if (isEdit && agent) {
@@ -426,6 +439,28 @@ export default function AgentForm({ agent, isEdit = false }: AgentFormProps) {
         </CollapsibleSection>
       )}
 
+      {/* Require Approval — collapsed */}
+      <CollapsibleSection title="Require Approval">
+        <Textarea
+          id="require-approval"
+          value={requireApproval}
+          onChange={e => setRequireApproval(e.target.value)}
+          placeholder={'Bash\nmcp__github__*'}
+          rows={3}
+          className="font-mono text-xs"
+        />
+        <p className="text-xs text-muted-foreground">
+          One tool per line. Scheduled and triggered runs of this agent pause before calling these
+          tools until someone approves the call on the channel set under Settings → Notifications,
+          or on the Approvals page. A trailing <code>*</code> matches every tool with that prefix.
+        </p>
+      </CollapsibleSection>
+
+      {/* Tool Policy — collapsed */}
+      <CollapsibleSection title="Tool Policy">
+        <ToolPolicyEditor draft={policyDraft} onChange={setPolicyDraft} />
+      </CollapsibleSection>
+
       {/* Actions */}
       <div className="flex items-center gap-3 pt-2">
         <Button type="submit" disabled={saving}>
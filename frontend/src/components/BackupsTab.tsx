@@ -0,0 +1,223 @@
+import { useState, useEffect, useCallback } from 'react'
+import { Button } from '@/components/ui/button'
+import { Input } from '@/components/ui/input'
+import { Label } from '@/components/ui/label'
+import { Switch } from '@/components/ui/switch'
+import { backupsApi, settingsApi } from '@/lib/api'
+import type { BackupArchive, SettingsResponse } from '@/types'
+import { DEFAULT_BACKUP_KEEP, MAX_BACKUP_KEEP, MAX_BACKUP_INTERVAL_HOURS } from '@/types'
+
+function formatSize(bytes: number) {
+  if (bytes < 1024 * 1024) return `${Math.max(1, Math.round(bytes / 1024))} KB`
+  return `${(bytes / (1024 * 1024)).toFixed(1)} MB`
+}
+
+export default function BackupsTab() {
+  const [resp, setResp] = useState<SettingsResponse | null>(null)
+  const [archives, setArchives] = useState<BackupArchive[]>([])
+  const [intervalHours, setIntervalHours] = useState(0)
+  const [keep, setKeep] = useState(DEFAULT_BACKUP_KEEP)
+  const [dir, setDir] = useState('')
+  const [includeCredentials, setIncludeCredentials] = useState(false)
+  const [loading, setLoading] = useState(true)
+  const [saving, setSaving] = useState(false)
+  const [backingUp, setBackingUp] = useState(false)
+  const [toast, setToast] = useState<string | null>(null)
+  const [error, setError] = useState<string | null>(null)
+
+  const showToast = (msg: string) => {
+    setToast(msg)
+    setTimeout(() => setToast(null), 3000)
+  }
+
+  const load = useCallback(async () => {
+    try {
+      const [data, list] = await Promise.all([settingsApi.get(), backupsApi.list()])
+      setResp(data)
+      setIntervalHours(data.settings.backup_interval_hours ?? 0)
+      setKeep(data.settings.backup_keep || DEFAULT_BACKUP_KEEP)
+      setDir(data.settings.backup_dir ?? '')
+      setIncludeCredentials(data.settings.backup_include_credentials ?? false)
+      setArchives(list)
+    } catch {
+      setError('Failed to load backup settings')
+    } finally {
+      setLoading(false)
+    }
+  }, [])
+
+  useEffect(() => {
+    load()
+  }, [load])
+
+  const handleSave = async () => {
+    setSaving(true)
+    setError(null)
+    try {
+      const updated = await settingsApi.update({
+        ...resp?.settings,
+        backup_interval_hours: intervalHours,
+        backup_keep: keep,
+        backup_dir: dir.trim(),
+        backup_include_credentials: includeCredentials,
+      })
+      setResp(updated)
+      setArchives(await backupsApi.list())
+      showToast('Backup settings saved')
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to save settings')
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  const handleBackupNow = async () => {
+    setBackingUp(true)
+    setError(null)
+    try {
+      const archive = await backupsApi.create()
+      setArchives(prev => [archive, ...prev])
+      showToast('Backup written')
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to back up')
+    } finally {
+      setBackingUp(false)
+    }
+  }
+
+  if (loading) {
+    return (
+      <div className="flex items-center justify-center py-12">
+        <div className="text-sm text-zinc-400">Loading…</div>
+      </div>
+    )
+  }
+
+  return (
+    <div className="max-w-2xl flex flex-col gap-6">
+      <h2 className="text-sm font-semibold text-zinc-900 dark:text-zinc-100">Backups</h2>
+      <p className="text-xs text-zinc-400">
+        A backup is one archive of the database, agents, MCP servers and Claude settings profiles.
+        Restore it, here or on another machine, with{' '}
+        <code className="font-mono">agento restore &lt;archive&gt;</code> while Agento is stopped.
+      </p>
+
+      {/* Schedule */}
+      <div className="flex flex-col gap-1.5">
+        <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+          Automatic backups every (hours)
+        </Label>
+        <Input
+          type="number"
+          min={0}
+          max={MAX_BACKUP_INTERVAL_HOURS}
+          value={intervalHours}
+          onChange={e =>
+            setIntervalHours(
+              Math.min(MAX_BACKUP_INTERVAL_HOURS, Math.max(0, Number(e.target.value))),
+            )
+          }
+          className="w-32 font-mono text-sm"
+        />
+        <p className="text-xs text-zinc-400">
+          0 turns automatic backups off. 24 backs up once a day while Agento runs.
+        </p>
+      </div>
+
+      <div className="flex flex-col gap-1.5">
+        <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+          Automatic backups kept
+        </Label>
+        <Input
+          type="number"
+          min={1}
+          max={MAX_BACKUP_KEEP}
+          value={keep}
+          onChange={e => setKeep(Math.min(MAX_BACKUP_KEEP, Math.max(1, Number(e.target.value))))}
+          className="w-32 font-mono text-sm"
+        />
+        <p className="text-xs text-zinc-400">
+          Older automatic backups are deleted. Backups made by hand are never deleted.
+        </p>
+      </div>
+
+      <div className="flex flex-col gap-1.5">
+        <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+          Backup directory
+        </Label>
+        <Input
+          value={dir}
+          onChange={e => setDir(e.target.value)}
+          placeholder="~/.agento/backups"
+          className="font-mono text-sm"
+        />
+        <p className="text-xs text-zinc-400">
+          An absolute path. Another disk keeps the backups safe from this one failing.
+        </p>
+      </div>
+
+      <div className="flex items-center justify-between gap-4">
+        <div className="flex flex-col gap-1">
+          <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+            Include integration credentials
+          </Label>
+          <p className="text-xs text-zinc-400">
+            They stay encrypted, and restoring them needs the same encryption key. Without them,
+            reconnect the integrations after a restore.
+          </p>
+        </div>
+        <Switch checked={includeCredentials} onCheckedChange={setIncludeCredentials} />
+      </div>
+
+      {error && (
+        <div className="rounded-md border border-red-200 bg-red-50 dark:border-red-800 dark:bg-red-900/20 px-3 py-2 text-sm text-red-700 dark:text-red-400">
+          {error}
+        </div>
+      )}
+
+      <div className="flex gap-2">
+        <Button
+          className="bg-zinc-900 hover:bg-zinc-800 text-white dark:bg-zinc-100 dark:hover:bg-zinc-200 dark:text-zinc-900 w-full sm:w-auto"
+          onClick={handleSave}
+          disabled={saving}
+        >
+          {saving ? 'Saving…' : 'Save Backup Settings'}
+        </Button>
+        <Button variant="outline" onClick={handleBackupNow} disabled={backingUp}>
+          {backingUp ? 'Backing up…' : 'Back Up Now'}
+        </Button>
+      </div>
+
+      {/* Existing backups */}
+      <div className="flex flex-col gap-1.5">
+        <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">Backups</Label>
+        {archives.length === 0 ? (
+          <p className="text-xs text-zinc-400">No backups yet.</p>
+        ) : (
+          <div className="rounded-md border border-zinc-200 dark:border-zinc-700 divide-y divide-zinc-200 dark:divide-zinc-700">
+            {archives.map(a => (
+              <div key={a.name} className="flex items-center justify-between gap-4 px-3 py-2">
+                <div className="min-w-0">
+                  <div className="text-sm text-zinc-900 dark:text-zinc-100">
+                    {new Date(a.created_at).toLocaleString()}
+                    {a.automatic && <span className="ml-2 text-xs text-zinc-400">automatic</span>}
+                  </div>
+                  <div className="truncate font-mono text-xs text-zinc-400" title={a.path}>
+                    {a.path}
+                  </div>
+                </div>
+                <span className="shrink-0 text-xs text-zinc-400">{formatSize(a.size)}</span>
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+
+      {toast && (
+        <div className="fixed bottom-4 right-4 z-50 rounded-md bg-zinc-900 dark:bg-zinc-100 text-white dark:text-zinc-900 px-4 py-2 text-sm shadow-lg">
+          {toast}
+        </div>
+      )}
+    </div>
+  )
+}
@@ -239,4 +239,47 @@ describe('DataAnalyticsTab', () => {
       claude_config_dirs: ['/mnt/other/.claude'],
     })
   })
+
+  // Off until asked for: another agent's logs are read only when the user
+  // turns that agent on, and turning one off keeps the others.
+  it('saves the coding agents whose sessions are imported', async () => {
+    const user = userEvent.setup()
+    vi.mocked(settingsApi.get).mockResolvedValue(settingsResponse({ imported_agents: ['aider'] }))
+    render(<DataAnalyticsTab />)
+
+    const codex = await screen.findByRole('switch', { name: 'Codex CLI' })
+    expect(codex).toHaveAttribute('aria-checked', 'false')
+    expect(screen.getByRole('switch', { name: 'Aider' })).toHaveAttribute('aria-checked', 'true')
+
+    await user.click(codex)
+    await user.click(screen.getByRole('switch', { name: 'Aider' }))
+    await user.click(screen.getByRole('button', { name: /save data settings/i }))
+
+    await waitFor(() => expect(settingsApi.update).toHaveBeenCalled())
+    expect(vi.mocked(settingsApi.update).mock.calls[0][0]).toMatchObject({
+      imported_agents: ['codex'],
+    })
+  })
+
+  // 0 means "learn it" for a limit and "the default" for the warning, so the
+  // form must send what the user typed and leave the meaning to the server.
+  it('saves the subscription usage limits', async () => {
+    const user = userEvent.setup()
+    render(<DataAnalyticsTab />)
+
+    const fiveHour = await screen.findByLabelText('5-hour limit (USD)')
+    await user.clear(fiveHour)
+    await user.type(fiveHour, '40')
+    const warn = screen.getByLabelText('Warn at (%)')
+    await user.clear(warn)
+    await user.type(warn, '90')
+    await user.click(screen.getByRole('button', { name: /save data settings/i }))
+
+    await waitFor(() => expect(settingsApi.update).toHaveBeenCalled())
+    expect(vi.mocked(settingsApi.update).mock.calls[0][0]).toMatchObject({
+      usage_limit_five_hour_usd: 40,
+      usage_limit_weekly_usd: 0,
+      usage_warn_percent: 90,
+    })
+  })
 })
@@ -3,9 +3,25 @@ import { Search, X } from 'lucide-react'
 import { Button } from '@/components/ui/button'
 import { Input } from '@/components/ui/input'
 import { Label } from '@/components/ui/label'
+import { Switch } from '@/components/ui/switch'
 import { settingsApi, claudeSessionsApi } from '@/lib/api'
-import type { SettingsResponse, ClaudeProject, ClaudeConfigDirsResponse } from '@/types'
-import { DEFAULT_IDLE_GAP_MINUTES, MIN_IDLE_GAP_MINUTES, MAX_IDLE_GAP_MINUTES } from '@/types'
+import type {
+  SettingsResponse,
+  ClaudeProject,
+  ClaudeConfigDirsResponse,
+  TranscriptArchiveUsage,
+} from '@/types'
+import {
+  DEFAULT_IDLE_GAP_MINUTES,
+  MIN_IDLE_GAP_MINUTES,
+  MAX_IDLE_GAP_MINUTES,
+  MAX_TRANSCRIPT_ARCHIVE_RETENTION_DAYS,
+  MAX_TRANSCRIPT_ARCHIVE_MAX_MB,
+  DEFAULT_USAGE_WARN_PERCENT,
+  MAX_USAGE_LIMIT_USD,
+  IMPORTABLE_AGENTS,
+  CODING_AGENT_LABELS,
+} from '@/types'
 
 /**
  * How many matches the picker renders at once. The list scrolls, so this is
@@ -16,9 +32,32 @@ import { DEFAULT_IDLE_GAP_MINUTES, MIN_IDLE_GAP_MINUTES, MAX_IDLE_GAP_MINUTES }
  */
 const MAX_SUGGESTIONS = 50
 
+function formatBytes(bytes: number) {
+  if (bytes < 1024 * 1024) return `${Math.round(bytes / 1024)} KB`
+  if (bytes < 1024 * 1024 * 1024) return `${(bytes / (1024 * 1024)).toFixed(1)} MB`
+  return `${(bytes / (1024 * 1024 * 1024)).toFixed(2)} GB`
+}
+
+/** Where each importable agent's sessions are read from, as the user knows it. */
+const IMPORTED_AGENT_SOURCES: Record<string, string> = {
+  codex: '~/.codex/sessions, or $CODEX_HOME',
+  gemini: '~/.gemini/tmp',
+  aider: '.aider.chat.history.md in each known project',
+}
+
+function archiveSummary(u: TranscriptArchiveUsage) {
+  const sessions = `${u.sessions} session${u.sessions === 1 ? '' : 's'}`
+  return (
+    `${sessions} archived, ${u.archived_only_sessions} of them kept only here. ` +
+    `${formatBytes(u.stored_bytes)} on disk for ${formatBytes(u.original_bytes)} of transcripts, ` +
+    `in ${u.dir}.`
+  )
+}
+
 /**
- * Data & Analytics settings: which projects Agento reports on, and what counts
- * as continuous work when it measures how long a session ran.
+ * Data & Analytics settings: which projects Agento reports on, what counts as
+ * continuous work when it measures how long a session ran, and how much of the
+ * history Claude Code deletes Agento keeps in its transcript archive.
  *
  * Both settings change what every number on the dashboard means, so each one
  * says in a line what it does — and the idle threshold additionally says that
@@ -38,6 +77,14 @@ export default function DataAnalyticsTab() {
   const [configDirs, setConfigDirs] = useState<string[]>([])
   const [dirInfo, setDirInfo] = useState<ClaudeConfigDirsResponse | null>(null)
   const [newDir, setNewDir] = useState('')
+  const [importedAgents, setImportedAgents] = useState<string[]>([])
+  const [archiveEnabled, setArchiveEnabled] = useState(true)
+  const [retentionDays, setRetentionDays] = useState(0)
+  const [archiveMaxMB, setArchiveMaxMB] = useState(0)
+  const [archiveUsage, setArchiveUsage] = useState<TranscriptArchiveUsage | null>(null)
+  const [fiveHourLimit, setFiveHourLimit] = useState(0)
+  const [weeklyLimit, setWeeklyLimit] = useState(0)
+  const [warnPercent, setWarnPercent] = useState(0)
   const [query, setQuery] = useState('')
   const [pickerOpen, setPickerOpen] = useState(false)
   const [loading, setLoading] = useState(true)
@@ -65,7 +112,19 @@ export default function DataAnalyticsTab() {
       setHidden(settings.settings.hidden_projects ?? [])
       setIdleGap(settings.settings.idle_gap_threshold_minutes || DEFAULT_IDLE_GAP_MINUTES)
       setConfigDirs(settings.settings.claude_config_dirs ?? [])
+      setImportedAgents(settings.settings.imported_agents ?? [])
       setDirInfo(dirs)
+      setArchiveEnabled(!settings.settings.transcript_archive_disabled)
+      setRetentionDays(settings.settings.transcript_archive_retention_days ?? 0)
+      setArchiveMaxMB(settings.settings.transcript_archive_max_mb ?? 0)
+      setFiveHourLimit(settings.settings.usage_limit_five_hour_usd ?? 0)
+      setWeeklyLimit(settings.settings.usage_limit_weekly_usd ?? 0)
+      setWarnPercent(settings.settings.usage_warn_percent ?? 0)
+      // Usage is informational; a failure to read it must not hide the form.
+      claudeSessionsApi
+        .archive()
+        .then(setArchiveUsage)
+        .catch(() => undefined)
     } catch {
       setError('Failed to load data settings')
     } finally {
@@ -125,6 +184,13 @@ export default function DataAnalyticsTab() {
         hidden_projects: hidden,
         idle_gap_threshold_minutes: idleGap,
         claude_config_dirs: configDirs,
+        imported_agents: importedAgents,
+        transcript_archive_disabled: !archiveEnabled,
+        transcript_archive_retention_days: retentionDays,
+        transcript_archive_max_mb: archiveMaxMB,
+        usage_limit_five_hour_usd: fiveHourLimit,
+        usage_limit_weekly_usd: weeklyLimit,
+        usage_warn_percent: warnPercent,
       })
       setResp(updated)
       // The resolved set changes with the save, so the candidate list must be
@@ -293,6 +359,43 @@ export default function DataAnalyticsTab() {
         )}
       </div>
 
+      {/* Other coding agents */}
+      <div className="flex flex-col gap-1.5">
+        <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+          Other Coding Agents
+        </Label>
+        <p className="text-xs text-zinc-400">
+          Index sessions from these agents alongside Claude Code&apos;s, so the list, the totals and
+          the cost charts cover every agent you use. Turning one off hides its sessions again.
+        </p>
+        <ul aria-label="Other coding agents" className="mt-1 flex flex-col gap-2">
+          {IMPORTABLE_AGENTS.map(agent => (
+            <li key={agent} className="flex items-center justify-between gap-4">
+              <div className="flex flex-col">
+                <Label
+                  htmlFor={`import-${agent}`}
+                  className="text-sm font-normal text-zinc-700 dark:text-zinc-300"
+                >
+                  {CODING_AGENT_LABELS[agent]}
+                </Label>
+                <span className="font-mono text-[11px] text-zinc-400">
+                  {IMPORTED_AGENT_SOURCES[agent]}
+                </span>
+              </div>
+              <Switch
+                id={`import-${agent}`}
+                checked={importedAgents.includes(agent)}
+                onCheckedChange={on =>
+                  setImportedAgents(prev =>
+                    on ? [...prev.filter(a => a !== agent), agent] : prev.filter(a => a !== agent),
+                  )
+                }
+              />
+            </li>
+          ))}
+        </ul>
+      </div>
+
       {/* Excluded projects */}
       <div className="flex flex-col gap-1.5">
         <Label
@@ -427,6 +530,157 @@ export default function DataAnalyticsTab() {
         )}
       </div>
 
+      {/* Transcript archive */}
+      <div className="flex flex-col gap-1.5">
+        <div className="flex items-center justify-between gap-4">
+          <div className="flex flex-col gap-1">
+            <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+              Transcript Archive
+            </Label>
+            <p className="text-xs text-zinc-400">
+              Claude Code deletes old transcripts on its own. Agento keeps a compressed copy of each
+              one, so a session stays in the list, the totals and its journey after the original is
+              gone.
+            </p>
+          </div>
+          <Switch checked={archiveEnabled} onCheckedChange={setArchiveEnabled} />
+        </div>
+
+        {archiveUsage && archiveUsage.transcripts > 0 && (
+          <p className="text-xs text-zinc-500 dark:text-zinc-400">{archiveSummary(archiveUsage)}</p>
+        )}
+
+        <div className="mt-2 flex flex-wrap gap-6">
+          <div className="flex flex-col gap-1.5">
+            <Label
+              htmlFor="archive-retention-days"
+              className="text-xs font-medium text-zinc-700 dark:text-zinc-300"
+            >
+              Keep deleted sessions for (days)
+            </Label>
+            <Input
+              id="archive-retention-days"
+              type="number"
+              min={0}
+              max={MAX_TRANSCRIPT_ARCHIVE_RETENTION_DAYS}
+              value={retentionDays}
+              onChange={e =>
+                setRetentionDays(
+                  Math.min(
+                    MAX_TRANSCRIPT_ARCHIVE_RETENTION_DAYS,
+                    Math.max(0, Number(e.target.value)),
+                  ),
+                )
+              }
+              className="w-32 font-mono text-sm"
+            />
+          </div>
+          <div className="flex flex-col gap-1.5">
+            <Label
+              htmlFor="archive-max-mb"
+              className="text-xs font-medium text-zinc-700 dark:text-zinc-300"
+            >
+              Size cap (MB)
+            </Label>
+            <Input
+              id="archive-max-mb"
+              type="number"
+              min={0}
+              max={MAX_TRANSCRIPT_ARCHIVE_MAX_MB}
+              value={archiveMaxMB}
+              onChange={e =>
+                setArchiveMaxMB(
+                  Math.min(MAX_TRANSCRIPT_ARCHIVE_MAX_MB, Math.max(0, Number(e.target.value))),
+                )
+              }
+              className="w-32 font-mono text-sm"
+            />
+          </div>
+        </div>
+        <p className="text-xs text-zinc-400">
+          0 keeps everything. Only sessions Claude Code has already deleted are ever removed, the
+          oldest first; the copy of a transcript still on disk is always kept.
+        </p>
+      </div>
+
+      {/* Subscription usage limits */}
+      <div className="flex flex-col gap-1.5">
+        <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+          Subscription Usage Limits
+        </Label>
+        <p className="text-xs text-zinc-400">
+          On a Pro or Max plan, Claude Code stops at the end of a 5-hour or weekly usage window, not
+          at a dollar amount. Agento estimates each window from your transcripts, weighted at API
+          prices, and warns before it runs out.
+        </p>
+
+        <div className="mt-2 flex flex-wrap gap-6">
+          <div className="flex flex-col gap-1.5">
+            <Label
+              htmlFor="usage-limit-five-hour"
+              className="text-xs font-medium text-zinc-700 dark:text-zinc-300"
+            >
+              5-hour limit (USD)
+            </Label>
+            <Input
+              id="usage-limit-five-hour"
+              type="number"
+              min={0}
+              max={MAX_USAGE_LIMIT_USD}
+              step="any"
+              value={fiveHourLimit}
+              onChange={e =>
+                setFiveHourLimit(
+                  Math.min(MAX_USAGE_LIMIT_USD, Math.max(0, Number(e.target.value))),
+                )
+              }
+              className="w-32 font-mono text-sm"
+            />
+          </div>
+          <div className="flex flex-col gap-1.5">
+            <Label
+              htmlFor="usage-limit-weekly"
+              className="text-xs font-medium text-zinc-700 dark:text-zinc-300"
+            >
+              Weekly limit (USD)
+            </Label>
+            <Input
+              id="usage-limit-weekly"
+              type="number"
+              min={0}
+              max={MAX_USAGE_LIMIT_USD}
+              step="any"
+              value={weeklyLimit}
+              onChange={e =>
+                setWeeklyLimit(Math.min(MAX_USAGE_LIMIT_USD, Math.max(0, Number(e.target.value))))
+              }
+              className="w-32 font-mono text-sm"
+            />
+          </div>
+          <div className="flex flex-col gap-1.5">
+            <Label
+              htmlFor="usage-warn-percent"
+              className="text-xs font-medium text-zinc-700 dark:text-zinc-300"
+            >
+              Warn at (%)
+            </Label>
+            <Input
+              id="usage-warn-percent"
+              type="number"
+              min={0}
+              max={100}
+              value={warnPercent}
+              onChange={e => setWarnPercent(Math.min(100, Math.max(0, Number(e.target.value))))}
+              className="w-32 font-mono text-sm"
+            />
+          </div>
+        </div>
+        <p className="text-xs text-zinc-400">
+          A limit of 0 is learned from your heaviest window of the last 8 weeks. A warning of 0 uses
+          the default of {DEFAULT_USAGE_WARN_PERCENT}%.
+        </p>
+      </div>
+
       {error && (
         <div className="rounded-md border border-red-200 bg-red-50 dark:border-red-800 dark:bg-red-900/20 px-3 py-2 text-sm text-red-700 dark:text-red-400">
           {error}
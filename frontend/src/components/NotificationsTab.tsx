@@ -9,8 +9,14 @@ import {
   SelectTrigger,
   SelectValue,
 } from '@/components/ui/select'
-import { notificationsApi } from '@/lib/api'
-import type { NotificationSettings, NotificationLogEntry } from '@/types'
+import { integrationsApi, notificationsApi } from '@/lib/api'
+import type {
+  ApprovalChannel,
+  ApprovalSettings,
+  Integration,
+  NotificationSettings,
+  NotificationLogEntry,
+} from '@/types'
 
 const defaultSettings: NotificationSettings = {
   enabled: false,
@@ -31,6 +37,9 @@ const defaultSettings: NotificationSettings = {
   },
 }
 
+// Select items cannot have an empty value, so "no channel" gets its own.
+const NO_CHANNEL = 'none'
+
 // Returns the effective value of an optional preference (nil/undefined → true).
 function prefValue(v: boolean | undefined): boolean {
   return v !== false
@@ -73,6 +82,7 @@ function PreferenceToggle({ checked, onChange, label, description }: ToggleProps
 export default function NotificationsTab() {
   const [settings, setSettings] = useState<NotificationSettings>(defaultSettings)
   const [log, setLog] = useState<NotificationLogEntry[]>([])
+  const [integrations, setIntegrations] = useState<Integration[]>([])
   const [loading, setLoading] = useState(true)
   const [saving, setSaving] = useState(false)
   const [testing, setTesting] = useState(false)
@@ -92,6 +102,9 @@ export default function NotificationsTab() {
       ])
       setSettings(ns)
       setLog(entries ?? [])
+      // Integrations only feed the approval channel picker; the rest of the
+      // tab works without them.
+      setIntegrations((await integrationsApi.list().catch(() => [])) ?? [])
     } catch {
       setError('Failed to load notification settings')
     } finally {
@@ -155,6 +168,14 @@ export default function NotificationsTab() {
     }))
   }
 
+  const updateApprovals = (patch: Partial<ApprovalSettings>) => {
+    setSettings(prev => ({ ...prev, approvals: { ...prev.approvals, ...patch } }))
+  }
+
+  const approvals = settings.approvals ?? {}
+  const approvalChannel = approvals.channel ?? ''
+  const approvalIntegrations = integrations.filter(i => i.type === approvalChannel)
+
   const scheduledTasksPrefs = settings.preferences?.scheduled_tasks
   const onFinished = prefValue(scheduledTasksPrefs?.on_finished)
   const onFailed = prefValue(scheduledTasksPrefs?.on_failed)
@@ -358,6 +379,106 @@ export default function NotificationsTab() {
               </div>
             </div>
           </fieldset>
+
+          <fieldset className="flex flex-col gap-4 rounded-md border border-zinc-200 dark:border-zinc-700 p-4">
+            <legend className="px-1 text-xs font-medium text-zinc-500 dark:text-zinc-400">
+              Approvals
+            </legend>
+            <p className="text-xs text-zinc-400 dark:text-zinc-500">
+              Where scheduled and triggered runs ask before calling a tool their agent lists under
+              “Require approval”. Requests can always be answered on the Approvals page too.
+            </p>
+
+            <div className="flex flex-col gap-1.5">
+              <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+                Channel
+              </Label>
+              <Select
+                value={approvalChannel || NO_CHANNEL}
+                onValueChange={v =>
+                  updateApprovals({
+                    channel: (v === NO_CHANNEL ? '' : v) as ApprovalChannel,
+                    integration_id: '',
+                    target: '',
+                  })
+                }
+              >
+                <SelectTrigger className="w-full">
+                  <SelectValue placeholder="Select channel" />
+                </SelectTrigger>
+                <SelectContent>
+                  <SelectItem value={NO_CHANNEL}>Approvals page only</SelectItem>
+                  <SelectItem value="telegram">Telegram</SelectItem>
+                  <SelectItem value="slack">Slack</SelectItem>
+                  <SelectItem value="email">Email</SelectItem>
+                </SelectContent>
+              </Select>
+              {approvalChannel === 'email' && (
+                <p className="text-xs text-zinc-400">
+                  Sent to the SMTP recipients with a link to the Approvals page.
+                </p>
+              )}
+            </div>
+
+            {(approvalChannel === 'telegram' || approvalChannel === 'slack') && (
+              <>
+                <div className="flex flex-col gap-1.5">
+                  <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+                    Integration
+                  </Label>
+                  <Select
+                    value={approvals.integration_id ?? ''}
+                    onValueChange={v => updateApprovals({ integration_id: v })}
+                  >
+                    <SelectTrigger className="w-full">
+                      <SelectValue placeholder="Select integration" />
+                    </SelectTrigger>
+                    <SelectContent>
+                      {approvalIntegrations.map(i => (
+                        <SelectItem key={i.id} value={i.id}>
+                          {i.name}
+                        </SelectItem>
+                      ))}
+                    </SelectContent>
+                  </Select>
+                </div>
+
+                <div className="flex flex-col gap-1.5">
+                  <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+                    {approvalChannel === 'telegram' ? 'Chat ID' : 'Channel ID'}
+                  </Label>
+                  <Input
+                    value={approvals.target ?? ''}
+                    onChange={e => updateApprovals({ target: e.target.value })}
+                    placeholder={approvalChannel === 'telegram' ? '-1001234567890' : 'C0123456789'}
+                    className="font-mono text-sm"
+                  />
+                  <p className="text-xs text-zinc-400">
+                    {approvalChannel === 'telegram'
+                      ? 'Requests come with Approve and Deny buttons.'
+                      : 'Answer by replying “approve” or “deny” in the request’s thread.'}
+                  </p>
+                </div>
+              </>
+            )}
+
+            <div className="flex flex-col gap-1.5">
+              <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+                Timeout (minutes)
+              </Label>
+              <Input
+                type="number"
+                min={1}
+                value={approvals.timeout_minutes || ''}
+                onChange={e => updateApprovals({ timeout_minutes: Number(e.target.value) })}
+                placeholder="30"
+                className="font-mono text-sm"
+              />
+              <p className="text-xs text-zinc-400">
+                Unanswered requests expire after this long and the call is denied.
+              </p>
+            </div>
+          </fieldset>
         </div>
       </div>
 
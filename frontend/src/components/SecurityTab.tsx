@@ -0,0 +1,234 @@
+import { useState, useEffect, useCallback } from 'react'
+import { Trash2 } from 'lucide-react'
+import { Button } from '@/components/ui/button'
+import { Input } from '@/components/ui/input'
+import { Label } from '@/components/ui/label'
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from '@/components/ui/select'
+import { CopyableId } from '@/components/CopyableId'
+import { authApi } from '@/lib/api'
+import type { ApiToken, ApiTokenScope, AuthStatus } from '@/types'
+
+const SCOPE_LABELS: Record<ApiTokenScope, string> = {
+  read: 'Read: analytics, sessions, chats and tasks',
+  run: 'Run: read, and run agents, chat completions and MCP',
+  admin: 'Admin: everything',
+  ingest: 'Ingest: only deliver Claude Code hook events',
+}
+
+function formatDate(value?: string) {
+  return value ? new Date(value).toLocaleString() : '—'
+}
+
+export default function SecurityTab() {
+  const [status, setStatus] = useState<AuthStatus | null>(null)
+  const [tokens, setTokens] = useState<ApiToken[]>([])
+  const [loading, setLoading] = useState(true)
+  const [name, setName] = useState('')
+  const [scope, setScope] = useState<ApiTokenScope>('read')
+  const [expiresInDays, setExpiresInDays] = useState(90)
+  const [creating, setCreating] = useState(false)
+  const [created, setCreated] = useState<string | null>(null)
+  const [error, setError] = useState<string | null>(null)
+
+  const load = useCallback(async () => {
+    try {
+      const [s, t] = await Promise.all([authApi.status(), authApi.listTokens()])
+      setStatus(s)
+      setTokens(t)
+    } catch {
+      setError('Failed to load security settings')
+    } finally {
+      setLoading(false)
+    }
+  }, [])
+
+  useEffect(() => {
+    load()
+  }, [load])
+
+  const handleCreate = async () => {
+    setCreating(true)
+    setError(null)
+    try {
+      const token = await authApi.createToken({ name, scope, expires_in_days: expiresInDays })
+      setCreated(token.token)
+      setName('')
+      await load()
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to create token')
+    } finally {
+      setCreating(false)
+    }
+  }
+
+  const handleRevoke = async (token: ApiToken) => {
+    if (!confirm(`Revoke "${token.name}"? Anything using it stops working at once.`)) return
+    setError(null)
+    try {
+      await authApi.revokeToken(token.id)
+      setTokens(prev => prev.filter(t => t.id !== token.id))
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to revoke token')
+    }
+  }
+
+  const handleSignOut = async () => {
+    try {
+      await authApi.logout()
+    } finally {
+      window.location.assign('/login')
+    }
+  }
+
+  if (loading) {
+    return (
+      <div className="flex items-center justify-center py-12">
+        <div className="text-sm text-zinc-400">Loading…</div>
+      </div>
+    )
+  }
+
+  return (
+    <div className="max-w-2xl flex flex-col gap-6">
+      <h2 className="text-sm font-semibold text-zinc-900 dark:text-zinc-100">Security</h2>
+
+      {/* Authentication state */}
+      <div className="flex flex-col gap-1.5">
+        <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+          Authentication
+        </Label>
+        {status?.enabled ? (
+          <div className="flex items-center justify-between gap-4">
+            <p className="text-xs text-zinc-400">
+              On. The web UI asks for the admin password, and scripts and clients need an API
+              token. Turn it off with <code className="font-mono">agento auth disable</code>.
+            </p>
+            {status.kind === 'session' && (
+              <Button variant="outline" size="sm" onClick={handleSignOut}>
+                Sign out
+              </Button>
+            )}
+          </div>
+        ) : (
+          <p className="text-xs text-zinc-400">
+            Off. Anyone who can reach this server can use it, which is safe only while it listens
+            on loopback. Run <code className="font-mono">agento auth set-password</code> to turn
+            it on; API tokens created here take effect then.
+          </p>
+        )}
+      </div>
+
+      {/* New token */}
+      <div className="flex flex-col gap-3 rounded-md border border-zinc-200 dark:border-zinc-700 p-4">
+        <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">
+          New API token
+        </Label>
+        <Input
+          placeholder="Name, e.g. grafana or ci"
+          value={name}
+          onChange={e => setName(e.target.value)}
+          className="text-sm"
+        />
+        <Select value={scope} onValueChange={v => setScope(v as ApiTokenScope)}>
+          <SelectTrigger className="w-full">
+            <SelectValue placeholder="Select scope" />
+          </SelectTrigger>
+          <SelectContent>
+            {(Object.keys(SCOPE_LABELS) as ApiTokenScope[]).map(s => (
+              <SelectItem key={s} value={s}>
+                {SCOPE_LABELS[s]}
+              </SelectItem>
+            ))}
+          </SelectContent>
+        </Select>
+        <div className="flex items-center gap-2">
+          <Input
+            type="number"
+            min={0}
+            max={3650}
+            value={expiresInDays}
+            onChange={e => setExpiresInDays(Math.max(0, Number(e.target.value)))}
+            className="w-24 font-mono text-sm"
+          />
+          <span className="text-xs text-zinc-400">days until it expires (0 never expires)</span>
+        </div>
+        <Button
+          className="bg-zinc-900 hover:bg-zinc-800 text-white dark:bg-zinc-100 dark:hover:bg-zinc-200 dark:text-zinc-900 w-full sm:w-auto"
+          onClick={handleCreate}
+          disabled={creating || !name.trim()}
+        >
+          {creating ? 'Creating…' : 'Create Token'}
+        </Button>
+
+        {created && (
+          <div className="rounded-md border border-amber-200 bg-amber-50 dark:border-amber-800 dark:bg-amber-900/20 px-3 py-2 text-sm text-amber-800 dark:text-amber-300">
+            <p className="mb-1">Copy this token now. It will not be shown again.</p>
+            <CopyableId
+              value={created}
+              label="Copy token"
+              className="text-amber-900 dark:text-amber-200"
+            />
+          </div>
+        )}
+      </div>
+
+      {/* Existing tokens */}
+      <div className="flex flex-col gap-2">
+        <Label className="text-sm font-medium text-zinc-700 dark:text-zinc-300">API tokens</Label>
+        {tokens.length === 0 ? (
+          <p className="text-xs text-zinc-400">No tokens yet.</p>
+        ) : (
+          <table className="w-full text-sm">
+            <thead>
+              <tr className="text-left text-xs text-zinc-400">
+                <th className="py-1 font-normal">Name</th>
+                <th className="py-1 font-normal">Scope</th>
+                <th className="py-1 font-normal">Last used</th>
+                <th className="py-1 font-normal">Expires</th>
+                <th />
+              </tr>
+            </thead>
+            <tbody>
+              {tokens.map(t => (
+                <tr key={t.id} className="border-t border-zinc-100 dark:border-zinc-800">
+                  <td className="py-2">
+                    <div className="text-zinc-900 dark:text-zinc-100">{t.name}</div>
+                    <div className="font-mono text-xs text-zinc-400">{t.prefix}…</div>
+                  </td>
+                  <td className="py-2 text-zinc-600 dark:text-zinc-400">{t.scope}</td>
+                  <td className="py-2 text-xs text-zinc-500">{formatDate(t.last_used_at)}</td>
+                  <td className="py-2 text-xs text-zinc-500">
+                    {t.expires_at ? formatDate(t.expires_at) : 'Never'}
+                  </td>
+                  <td className="py-2 text-right">
+                    <button
+                      type="button"
+                      onClick={() => handleRevoke(t)}
+                      title="Revoke"
+                      aria-label={`Revoke ${t.name}`}
+                      className="text-zinc-400 hover:text-red-600 transition-colors"
+                    >
+                      <Trash2 className="h-4 w-4" />
+                    </button>
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        )}
+      </div>
+
+      {error && (
+        <div className="rounded-md border border-red-200 bg-red-50 dark:border-red-800 dark:bg-red-900/20 px-3 py-2 text-sm text-red-700 dark:text-red-400">
+          {error}
+        </div>
+      )}
+    </div>
+  )
+}
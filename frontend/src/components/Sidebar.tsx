@@ -15,6 +15,8 @@ import {
   Lightbulb,
   CalendarClock,
   ClipboardList,
+  ShieldCheck,
+  ScrollText,
   Info,
   Star,
 } from 'lucide-react'
@@ -82,6 +84,8 @@ export default function Sidebar({ mobileOpen = false, onMobileClose }: SidebarPr
   const taskNavItems = [
     { to: '/tasks', icon: CalendarClock, label: 'Manage Tasks' },
     { to: '/job-history', icon: ClipboardList, label: 'Job History' },
+    { to: '/approvals', icon: ShieldCheck, label: 'Approvals' },
+    { to: '/tool-calls', icon: ScrollText, label: 'Tool Calls' },
   ]
 
   const claudeNavItems = [{ to: '/claude-sessions', icon: History, label: 'Claude Sessions' }]
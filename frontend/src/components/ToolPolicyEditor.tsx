@@ -0,0 +1,209 @@
+/**
+ * Editor for an agent's tool policy: ordered allow / deny / ask rules on tool
+ * arguments. Rules are edited as drafts whose list fields are plain text, so
+ * typing a separator does not reformat the field; draftToPolicy turns them
+ * back into the saved shape.
+ */
+import { Plus, Trash2 } from 'lucide-react'
+
+import { Button } from '@/components/ui/button'
+import { Input } from '@/components/ui/input'
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from '@/components/ui/select'
+import { Textarea } from '@/components/ui/textarea'
+import type { PolicyAction, ToolPolicy } from '@/types'
+
+export interface PolicyRuleDraft {
+  tool: string
+  action: PolicyAction
+  command: string
+  /** Comma-separated globs. */
+  paths: string
+  /** Comma-separated hosts. */
+  hosts: string
+  /** One `name=regex` per line. */
+  params: string
+  reason: string
+}
+
+export interface PolicyDraft {
+  dryRun: boolean
+  rules: PolicyRuleDraft[]
+}
+
+const emptyRule: PolicyRuleDraft = {
+  tool: '',
+  action: 'deny',
+  command: '',
+  paths: '',
+  hosts: '',
+  params: '',
+  reason: '',
+}
+
+const splitList = (v: string) =>
+  v
+    .split(',')
+    .map(s => s.trim())
+    .filter(Boolean)
+
+export function policyToDraft(policy?: ToolPolicy): PolicyDraft {
+  return {
+    dryRun: policy?.dry_run ?? false,
+    rules: (policy?.rules ?? []).map(r => ({
+      tool: r.tool,
+      action: r.action,
+      command: r.command ?? '',
+      paths: (r.paths ?? []).join(', '),
+      hosts: (r.hosts ?? []).join(', '),
+      params: Object.entries(r.params ?? {})
+        .map(([name, re]) => `${name}=${re}`)
+        .join('\n'),
+      reason: r.reason ?? '',
+    })),
+  }
+}
+
+/** Returns the policy to save, or undefined when there are no rules. */
+export function draftToPolicy(draft: PolicyDraft): ToolPolicy | undefined {
+  const rules = draft.rules
+    .filter(r => r.tool.trim() !== '')
+    .map(r => {
+      const params: Record<string, string> = {}
+      for (const line of r.params.split('\n')) {
+        const eq = line.indexOf('=')
+        if (eq > 0) params[line.slice(0, eq).trim()] = line.slice(eq + 1).trim()
+      }
+      const paths = splitList(r.paths)
+      const hosts = splitList(r.hosts)
+      return {
+        tool: r.tool.trim(),
+        action: r.action,
+        ...(r.command.trim() ? { command: r.command.trim() } : {}),
+        ...(paths.length > 0 ? { paths } : {}),
+        ...(hosts.length > 0 ? { hosts } : {}),
+        ...(Object.keys(params).length > 0 ? { params } : {}),
+        ...(r.reason.trim() ? { reason: r.reason.trim() } : {}),
+      }
+    })
+  if (rules.length === 0) return undefined
+  return { ...(draft.dryRun ? { dry_run: true } : {}), rules }
+}
+
+export default function ToolPolicyEditor({
+  draft,
+  onChange,
+}: Readonly<{
+  draft: PolicyDraft
+  onChange: (draft: PolicyDraft) => void
+}>) {
+  const updateRule = (i: number, patch: Partial<PolicyRuleDraft>) => {
+    onChange({ ...draft, rules: draft.rules.map((r, j) => (j === i ? { ...r, ...patch } : r)) })
+  }
+
+  return (
+    <div className="space-y-3">
+      <p className="text-xs text-muted-foreground">
+        Rules on tool arguments, checked in order before every call; the first match decides.
+        Calls no rule matches run as they would without a policy. <strong>Ask</strong> prompts in
+        chat and waits for an approval in scheduled and triggered runs.
+      </p>
+
+      {draft.rules.map((rule, i) => (
+        <div key={i} className="rounded-lg border border-border p-3 space-y-2">
+          <div className="flex items-center gap-2">
+            <span className="text-xs text-muted-foreground w-5 shrink-0">{i + 1}.</span>
+            <Input
+              value={rule.tool}
+              onChange={e => updateRule(i, { tool: e.target.value })}
+              placeholder="Bash, Read, WebFetch, mcp__github__*"
+              className="font-mono text-xs h-8"
+            />
+            <Select
+              value={rule.action}
+              onValueChange={v => updateRule(i, { action: v as PolicyAction })}
+            >
+              <SelectTrigger className="w-24 h-8 shrink-0">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectItem value="allow">Allow</SelectItem>
+                <SelectItem value="deny">Deny</SelectItem>
+                <SelectItem value="ask">Ask</SelectItem>
+              </SelectContent>
+            </Select>
+            <Button
+              type="button"
+              variant="ghost"
+              size="sm"
+              className="h-8 w-8 p-0 shrink-0"
+              onClick={() => onChange({ ...draft, rules: draft.rules.filter((_, j) => j !== i) })}
+              aria-label="Remove rule"
+            >
+              <Trash2 className="h-3.5 w-3.5" />
+            </Button>
+          </div>
+          <div className="grid grid-cols-2 gap-2 pl-7">
+            <Input
+              value={rule.command}
+              onChange={e => updateRule(i, { command: e.target.value })}
+              placeholder="Command regex, e.g. rm\s+-rf"
+              className="font-mono text-xs h-8"
+            />
+            <Input
+              value={rule.paths}
+              onChange={e => updateRule(i, { paths: e.target.value })}
+              placeholder="Path globs, e.g. ~/.ssh/*, **/.env"
+              className="font-mono text-xs h-8"
+            />
+            <Input
+              value={rule.hosts}
+              onChange={e => updateRule(i, { hosts: e.target.value })}
+              placeholder="Hosts, e.g. docs.python.org, *.github.com"
+              className="font-mono text-xs h-8"
+            />
+            <Input
+              value={rule.reason}
+              onChange={e => updateRule(i, { reason: e.target.value })}
+              placeholder="Reason shown when it applies"
+              className="text-xs h-8"
+            />
+            <Textarea
+              value={rule.params}
+              onChange={e => updateRule(i, { params: e.target.value })}
+              placeholder="Parameter rules, one per line: repo=^acme/prod-"
+              rows={2}
+              className="col-span-2 font-mono text-xs"
+            />
+          </div>
+        </div>
+      ))}
+
+      <div className="flex items-center justify-between">
+        <Button
+          type="button"
+          variant="outline"
+          size="sm"
+          onClick={() => onChange({ ...draft, rules: [...draft.rules, { ...emptyRule }] })}
+        >
+          <Plus className="h-3.5 w-3.5 mr-1" />
+          Add rule
+        </Button>
+        <label className="flex items-center gap-2 text-xs text-muted-foreground cursor-pointer">
+          <input
+            type="checkbox"
+            checked={draft.dryRun}
+            onChange={e => onChange({ ...draft, dryRun: e.target.checked })}
+            className="h-3.5 w-3.5 rounded border-gray-300"
+          />
+          Dry run: log what the rules would decide, enforce nothing
+        </label>
+      </div>
+    </div>
+  )
+}
@@ -1,6 +1,6 @@
 import { useState } from 'react'
 import { X, Loader2 } from 'lucide-react'
-import type { TriggerRule, Agent } from '@/types'
+import type { TriggerRule, TriggerConversation, Agent } from '@/types'
 
 interface Props {
   rule: TriggerRule | null
@@ -16,6 +16,8 @@ export default function TriggerRuleForm({ rule, agents, onSave, onCancel }: Prop
   const [filterPrefix, setFilterPrefix] = useState(rule?.filter_prefix ?? '')
   const [filterKeywords, setFilterKeywords] = useState(rule?.filter_keywords?.join(', ') ?? '')
   const [filterChatIds, setFilterChatIds] = useState(rule?.filter_chat_ids?.join(', ') ?? '')
+  const [conversation, setConversation] = useState<TriggerConversation | ''>(rule?.conversation ?? '')
+  const [idleMinutes, setIdleMinutes] = useState(String(rule?.conversation_idle_minutes || ''))
   const [saving, setSaving] = useState(false)
   const [error, setError] = useState<string | null>(null)
 
@@ -35,6 +37,8 @@ export default function TriggerRuleForm({ rule, agents, onSave, onCancel }: Prop
         filter_prefix: filterPrefix.trim(),
         filter_keywords: parseCommaSeparated(filterKeywords),
         filter_chat_ids: parseCommaSeparated(filterChatIds),
+        conversation: conversation || undefined,
+        conversation_idle_minutes: conversation ? Number(idleMinutes) || 0 : 0,
       })
     } catch (err) {
       setError((err as Error).message)
@@ -187,6 +191,60 @@ export default function TriggerRuleForm({ rule, agents, onSave, onCancel }: Prop
             </div>
           </div>
 
+          <div className="border-t border-zinc-100 dark:border-zinc-700 pt-4">
+            <p className="text-xs font-semibold uppercase tracking-widest text-zinc-400 mb-3">
+              Conversation
+            </p>
+
+            <div className="space-y-3">
+              <div>
+                <label
+                  htmlFor="rule-conversation"
+                  className="block text-xs font-medium text-zinc-700 dark:text-zinc-300 mb-1"
+                >
+                  Memory
+                </label>
+                <select
+                  id="rule-conversation"
+                  value={conversation}
+                  onChange={e => setConversation(e.target.value as TriggerConversation | '')}
+                  className="w-full rounded-md border border-zinc-300 dark:border-zinc-600 bg-white dark:bg-zinc-900 px-3 py-2 text-sm text-zinc-900 dark:text-zinc-100 focus:outline-none focus:ring-2 focus:ring-zinc-900 dark:focus:ring-zinc-400"
+                >
+                  <option value="">Off — every message starts fresh</option>
+                  <option value="chat">One conversation per chat</option>
+                  <option value="thread">One conversation per reply thread</option>
+                </select>
+                <p className="text-xs text-zinc-400 mt-1">
+                  The agent remembers earlier messages in the conversation. Send /new to start
+                  over.
+                </p>
+              </div>
+
+              {conversation && (
+                <div>
+                  <label
+                    htmlFor="rule-idle-minutes"
+                    className="block text-xs font-medium text-zinc-700 dark:text-zinc-300 mb-1"
+                  >
+                    Idle timeout (minutes)
+                  </label>
+                  <input
+                    id="rule-idle-minutes"
+                    type="number"
+                    min={0}
+                    value={idleMinutes}
+                    onChange={e => setIdleMinutes(e.target.value)}
+                    placeholder="60"
+                    className="w-full rounded-md border border-zinc-300 dark:border-zinc-600 bg-white dark:bg-zinc-900 px-3 py-2 text-sm text-zinc-900 dark:text-zinc-100 focus:outline-none focus:ring-2 focus:ring-zinc-900 dark:focus:ring-zinc-400"
+                  />
+                  <p className="text-xs text-zinc-400 mt-1">
+                    After this long without a message, the next one starts a new conversation.
+                  </p>
+                </div>
+              )}
+            </div>
+          </div>
+
           <div className="flex items-center justify-end gap-2 pt-2">
             <button
               type="button"
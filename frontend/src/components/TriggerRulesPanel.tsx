@@ -9,6 +9,7 @@ import {
   RefreshCw,
   Globe,
   Info,
+  Radio,
 } from 'lucide-react'
 import { triggerRulesApi, webhookApi, agentsApi } from '@/lib/api'
 import type { TriggerRule, WebhookStatus, Agent } from '@/types'
@@ -130,7 +131,8 @@ export default function TriggerRulesPanel({ integrationId }: Props) {
     )
   }
 
-  const isActive = webhookStatus?.status === 'active'
+  const isPolling = webhookStatus?.status === 'polling'
+  const isActive = webhookStatus?.status === 'active' || isPolling
   const hasRules = rules.length > 0
 
   return (
@@ -150,7 +152,8 @@ export default function TriggerRulesPanel({ integrationId }: Props) {
                 <a href="/settings" className="underline hover:no-underline">
                   Settings → General
                 </a>{' '}
-                (the externally reachable URL of this instance).
+                (the externally reachable URL of this instance). Optional: without one, Agento
+                long-polls Telegram instead.
               </li>
               <li className={isActive ? 'line-through opacity-40' : ''}>
                 Click{' '}
@@ -159,7 +162,7 @@ export default function TriggerRulesPanel({ integrationId }: Props) {
                 </span>{' '}
                 below. Agento will automatically notify Telegram of the webhook URL.
               </li>
-              <li className={!isActive ? 'opacity-40' : hasRules ? 'line-through opacity-40' : ''}>
+              <li className={hasRules ? 'line-through opacity-40' : ''}>
                 Add at least one{' '}
                 <span className="font-medium text-zinc-700 dark:text-zinc-300">Trigger Rule</span>{' '}
                 to route incoming messages to an agent.
@@ -202,6 +205,12 @@ export default function TriggerRulesPanel({ integrationId }: Props) {
                 Error
               </span>
             )}
+            {isPolling && (
+              <span className="flex items-center gap-1.5 text-sm text-green-600 dark:text-green-400 font-medium">
+                <Radio className="h-4 w-4" />
+                Long polling
+              </span>
+            )}
             {(!webhookStatus || webhookStatus.status === 'inactive') && (
               <span className="flex items-center gap-1.5 text-sm text-zinc-400 font-medium">
                 <XCircle className="h-4 w-4" />
@@ -223,6 +232,16 @@ export default function TriggerRulesPanel({ integrationId }: Props) {
             <p className="text-xs text-red-500 dark:text-red-400">{webhookStatus.error}</p>
           )}
 
+          {isPolling && (
+            <p className="text-xs text-zinc-500 dark:text-zinc-400">
+              No public URL is set, so Agento fetches new messages from Telegram itself. Set one
+              in Settings → General to switch to a webhook.
+            </p>
+          )}
+          {isPolling && webhookStatus.error && (
+            <p className="text-xs text-red-500 dark:text-red-400">{webhookStatus.error}</p>
+          )}
+
           <div className="flex items-center gap-2 pt-1">
             {(!webhookStatus || webhookStatus.status === 'inactive') && (
               <button
@@ -319,6 +338,11 @@ export default function TriggerRulesPanel({ integrationId }: Props) {
                       {rule.filter_chat_ids?.length > 0 && (
                         <span>Chat IDs: {rule.filter_chat_ids.length}</span>
                       )}
+                      {rule.conversation && (
+                        <span>
+                          Memory: {rule.conversation === 'thread' ? 'per thread' : 'per chat'}
+                        </span>
+                      )}
                     </div>
                   </div>
                   <div className="flex items-center gap-1.5 shrink-0 ml-2">
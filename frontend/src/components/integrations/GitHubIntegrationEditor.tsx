@@ -46,6 +46,7 @@ const GITHUB_SERVICES: Record<string, ServiceInfo> = {
       { name: 'create_pull', description: 'Create a new pull request' },
       { name: 'get_pull_diff', description: 'Get the diff of a pull request' },
       { name: 'list_pull_comments', description: 'List review comments on a pull request' },
+      { name: 'create_pull_comment', description: 'Comment on a pull request' },
     ],
   },
   actions: {
@@ -22,15 +22,32 @@ import type {
   ClaudeSettingsProfileDetail,
   ClaudeProject,
   ClaudeSessionStatus,
+  TranscriptArchiveUsage,
+  LiveClaudeSession,
+  UsageWindowsReport,
   ClaudeSessionPage,
   ClaudeSessionFacets,
   ClaudeSessionDetail,
   SessionJourney,
+  JourneyTailStep,
+  JourneyTailSubagent,
+  JourneyTailUsage,
+  SessionComparison,
+  TranscriptSearchPage,
   AnalyticsReport,
   Integration,
   AvailableTool,
   NotificationSettings,
   NotificationLogEntry,
+  ApprovalRequest,
+  ApprovalStatus,
+  ToolCall,
+  ToolCallFilter,
+  AuthStatus,
+  ApiToken,
+  ApiTokenInput,
+  CreatedApiToken,
+  BackupArchive,
   ScheduledTask,
   JobHistoryEntry,
   UpdateCheckResponse,
@@ -57,6 +74,15 @@ const BASE = '/api'
  */
 export const JSON_HEADERS = { 'Content-Type': 'application/json' } as const
 
+/**
+ * With authentication on, a 401 means the session expired or was signed out
+ * elsewhere. Send the browser to the sign-in form, which returns it here.
+ */
+function redirectToLogin() {
+  const next = window.location.pathname + window.location.search
+  window.location.assign(`/login?next=${encodeURIComponent(next)}`)
+}
+
 async function request<T>(path: string, options?: RequestInit): Promise<T> {
   const res = await fetch(`${BASE}${path}`, {
     ...options,
@@ -64,6 +90,7 @@ async function request<T>(path: string, options?: RequestInit): Promise<T> {
     // drop Content-Type silently, which the server now rejects outright.
     headers: { ...JSON_HEADERS, ...options?.headers },
   })
+  if (res.status === 401) redirectToLogin()
   if (!res.ok) {
     const body = await res.json().catch(() => ({ error: res.statusText }))
     throw new Error(body.error || `HTTP ${res.status}`)
@@ -228,6 +255,16 @@ export const filesystemApi = {
 
 // ── Claude Code sessions ──────────────────────────────────────────────────────
 
+/** Callbacks for claudeSessionsApi.tail, one per event the tail stream sends. */
+export interface JourneyTailHandlers {
+  onStep: (step: JourneyTailStep) => void
+  onSubagent: (subagent: JourneyTailSubagent) => void
+  onUsage: (usage: JourneyTailUsage) => void
+  /** The transcript as it stood has been replayed; everything after is live. */
+  onReady?: () => void
+  onError?: () => void
+}
+
 export const claudeSessionsApi = {
   /**
    * One page of Claude Code sessions.
@@ -281,12 +318,62 @@ export const claudeSessionsApi = {
    */
   status: () => request<ClaudeSessionStatus>('/claude-sessions/status'),
 
+  /** What the transcript archive holds, and the disk it takes. */
+  archive: () => request<TranscriptArchiveUsage>('/claude-sessions/archive'),
+
+  /** The sessions Claude Code has open, from its hooks. */
+  live: () => request<LiveClaudeSession[]>('/claude-sessions/live'),
+
+  /** Each config dir's current 5-hour and weekly subscription usage windows. */
+  usageWindows: () => request<UsageWindowsReport>('/claude-sessions/usage-windows'),
+
   /** Get the full detail of a single session including messages and todos. */
   get: (id: string) => request<ClaudeSessionDetail>(`/claude-sessions/${id}`),
 
   /** Get the structured turn-by-turn journey visualization for a session. */
   journey: (id: string) => request<SessionJourney>(`/claude-sessions/${id}/journey`),
 
+  /**
+   * Follow a session's journey as Claude Code writes it: the transcript so far,
+   * then every step, sub-agent and total as it is appended. Returns a function
+   * that closes the stream.
+   */
+  tail: (id: string, handlers: JourneyTailHandlers): (() => void) => {
+    const source = new EventSource(`${BASE}/claude-sessions/${encodeURIComponent(id)}/tail`)
+    const on = <T>(event: string, fn: (data: T) => void) =>
+      source.addEventListener(event, e => fn(JSON.parse((e as MessageEvent<string>).data) as T))
+    on('step', handlers.onStep)
+    on('subagent', handlers.onSubagent)
+    on('usage', handlers.onUsage)
+    source.addEventListener('ready', () => handlers.onReady?.())
+    // EventSource would reconnect on its own and replay the transcript from the
+    // top onto steps already shown, so a dropped stream is closed and reported.
+    source.onerror = () => {
+      source.close()
+      handlers.onError?.()
+    }
+    return () => source.close()
+  },
+
+  /** Align two sessions' journeys: where they diverged and what each turn cost. */
+  compare: (leftId: string, rightId: string) =>
+    request<SessionComparison>(
+      `/claude-sessions/compare?left=${encodeURIComponent(leftId)}&right=${encodeURIComponent(rightId)}`,
+    ),
+
+  /**
+   * Full-text search over transcript content: ranked hits, each addressing the
+   * journey step it matched. `filters` narrows the sessions searched exactly as
+   * it narrows the list; its `q` is replaced by `text`.
+   */
+  search: (text: string, params?: { filters?: URLSearchParams; limit?: number; offset?: number }) => {
+    const qs = new URLSearchParams(params?.filters)
+    qs.set('q', text)
+    if (params?.limit) qs.set('limit', String(params.limit))
+    if (params?.offset) qs.set('offset', String(params.offset))
+    return request<TranscriptSearchPage>(`/claude-sessions/search?${qs.toString()}`)
+  },
+
   /** Invalidate the server-side session cache and trigger a background rescan. */
   refresh: () =>
     fetch(`${BASE}/claude-sessions/refresh`, { method: 'POST', headers: JSON_HEADERS }).then(
@@ -728,6 +815,65 @@ export const jobHistoryApi = {
     }),
 }
 
+// ── Approvals ─────────────────────────────────────────────────────────────────
+
+export const approvalsApi = {
+  list: (status?: ApprovalStatus) => {
+    const suffix = status ? `?status=${status}` : ''
+    return request<ApprovalRequest[]>(`/approvals${suffix}`)
+  },
+
+  approve: (id: string) =>
+    request<ApprovalRequest>(`/approvals/${id}/approve`, { method: 'POST', headers: JSON_HEADERS }),
+
+  deny: (id: string) =>
+    request<ApprovalRequest>(`/approvals/${id}/deny`, { method: 'POST', headers: JSON_HEADERS }),
+}
+
+// ── Tool call audit log ───────────────────────────────────────────────────────
+
+export const toolCallsApi = {
+  list: (filter: ToolCallFilter = {}) => {
+    const params = new URLSearchParams()
+    for (const [key, value] of Object.entries(filter)) {
+      if (value !== undefined && value !== '') params.set(key, String(value))
+    }
+    // Bare since/until dates are read as the user's days.
+    params.set('tz', browserTimezone())
+    return request<ToolCall[]>(`/tool-calls?${params.toString()}`)
+  },
+}
+
+// ── Authentication ────────────────────────────────────────────────────────────
+
+export const authApi = {
+  status: () => request<AuthStatus>('/auth/status'),
+
+  /** Ends this browser's session; the server clears its cookie. */
+  logout: () =>
+    fetch(`${BASE}/auth/logout`, { method: 'POST', headers: JSON_HEADERS }).then(res => {
+      if (!res.ok) throw new Error(`HTTP ${res.status}`)
+    }),
+
+  listTokens: () => request<ApiToken[]>('/auth/tokens'),
+
+  /** The returned `token` is the secret, and is not shown again. */
+  createToken: (data: ApiTokenInput) =>
+    request<CreatedApiToken>('/auth/tokens', { method: 'POST', body: JSON.stringify(data) }),
+
+  revokeToken: (id: string) =>
+    fetch(`${BASE}/auth/tokens/${id}`, { method: 'DELETE', headers: JSON_HEADERS }).then(res => {
+      if (!res.ok) throw new Error(`HTTP ${res.status}`)
+    }),
+}
+
+export const backupsApi = {
+  list: () => request<BackupArchive[]>('/backups'),
+
+  /** Makes a backup now, outside the schedule. Retention never deletes it. */
+  create: () => request<BackupArchive>('/backups', { method: 'POST' }),
+}
+
 // ── Version / update check ────────────────────────────────────────────────────
 
 export const versionApi = {
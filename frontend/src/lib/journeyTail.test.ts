@@ -0,0 +1,83 @@
+import { describe, it, expect } from 'vitest'
+import type { JourneyStep, JourneyTailUsage } from '../types'
+import {
+  applyTailUsage,
+  applyTailStep,
+  applyTailSubagent,
+  emptyTailedJourney,
+} from './journeyTail'
+
+function step(
+  type: JourneyStep['type'],
+  at: string,
+  data: Record<string, unknown> = {},
+): JourneyStep {
+  return { type, timestamp: `2026-08-01T09:00:0${at}Z`, duration_ms: 0, data }
+}
+
+describe('applyTailStep', () => {
+  it('opens turns as their first step arrives and counts tool calls', () => {
+    let s = emptyTailedJourney('sess')
+    s = applyTailStep(s, { turn: 1, step: step('user_input', '0', { content: 'go' }) })
+    s = applyTailStep(s, { turn: 1, step: step('tool_call', '1', { tool_use_id: 't1' }) })
+    s = applyTailStep(s, { turn: 2, step: step('user_input', '2', { content: 'again' }) })
+
+    expect(s.journey.turns.map(t => t.steps.length)).toEqual([2, 1])
+    expect(s.journey.turns[0].tool_calls).toBe(1)
+    expect(s.journey.turns[0].end_time).toBe('2026-08-01T09:00:01Z')
+  })
+
+  it('ignores a sub-agent step whose agent was never announced', () => {
+    const s = emptyTailedJourney('sess')
+    const ghost = { turn: 1, agent_id: 'ghost', step: step('text_response', '0') }
+    expect(applyTailStep(s, ghost)).toBe(s)
+  })
+})
+
+describe('applyTailSubagent', () => {
+  it('nests a spawned agent under its Task call, and an unmatched one in its own step', () => {
+    let s = emptyTailedJourney('sess')
+    s = applyTailStep(s, { turn: 1, step: step('tool_call', '0', { tool_use_id: 't1' }) })
+    s = applyTailSubagent(s, { turn: 1, agent_id: 'a', agent_type: 'explore', tool_use_id: 't1' })
+    s = applyTailSubagent(s, { turn: 1, agent_id: 'b', description: 'orphan' })
+    s = applyTailStep(s, { turn: 1, agent_id: 'a', step: step('text_response', '1') })
+    s = applyTailStep(s, { turn: 1, agent_id: 'b', step: step('text_response', '2') })
+
+    const [call, orphan] = s.journey.turns[0].steps
+    expect(call.data.agent_type).toBe('explore')
+    expect(call.steps).toHaveLength(1)
+    expect(orphan.type).toBe('sub_agent')
+    expect(orphan.data.description).toBe('orphan')
+    expect(orphan.steps).toHaveLength(1)
+    // Delegated steps are not the main thread's tool calls.
+    expect(s.journey.turns[0].tool_calls).toBe(1)
+  })
+})
+
+describe('applyTailUsage', () => {
+  it('takes the header totals and the running cost from the server', () => {
+    const usage = {
+      input_tokens: 10,
+      output_tokens: 5,
+      cache_creation_tokens: 0,
+      cache_creation_5m_tokens: 0,
+      cache_creation_1h_tokens: 0,
+      cache_read_tokens: 0,
+    }
+    const p: JourneyTailUsage = {
+      model: 'claude-sonnet-4-6',
+      start_time: '2026-08-01T09:00:00Z',
+      end_time: '2026-08-01T09:01:00Z',
+      active_duration_ms: 30_000,
+      total_turns: 1,
+      usage,
+      subagent_usage: usage,
+      subagent_count: 1,
+      cost_usd: 0.25,
+    }
+    const s = applyTailUsage(emptyTailedJourney('sess'), p)
+    expect(s.costUsd).toBe(0.25)
+    expect(s.journey.total_duration_ms).toBe(60_000)
+    expect(s.journey.model).toBe('claude-sonnet-4-6')
+  })
+})
@@ -0,0 +1,164 @@
+import type {
+  ClaudeTokenUsage,
+  JourneyStep,
+  JourneyTailUsage,
+  JourneyTailStep,
+  JourneyTailSubagent,
+  JourneyTurn,
+  SessionJourney,
+} from '../types'
+
+/**
+ * A journey assembled from a live tail, so a session still being written
+ * renders with the same components as a finished one.
+ *
+ * Steps nest the way the server's journey nests them: a sub-agent's under the
+ * tool_call that spawned it, or under a sub_agent step appended to its turn
+ * when that call was not streamed first.
+ */
+export interface TailedJourney {
+  journey: SessionJourney
+  /** Every sub-agent announced so far, by agent id: where its steps go. */
+  agents: Record<string, JourneyTailSubagent>
+  /** Main thread plus sub-agents, priced per message; 0 until the first total. */
+  costUsd: number
+}
+
+const zeroUsage = (): ClaudeTokenUsage => ({
+  input_tokens: 0,
+  output_tokens: 0,
+  cache_creation_tokens: 0,
+  cache_creation_5m_tokens: 0,
+  cache_creation_1h_tokens: 0,
+  cache_read_tokens: 0,
+})
+
+export function emptyTailedJourney(sessionId: string): TailedJourney {
+  return {
+    journey: {
+      session_id: sessionId,
+      start_time: '',
+      end_time: '',
+      total_duration_ms: 0,
+      active_duration_ms: 0,
+      total_turns: 0,
+      usage: zeroUsage(),
+      subagent_usage: zeroUsage(),
+      subagent_count: 0,
+      turns: [],
+    },
+    agents: {},
+    costUsd: 0,
+  }
+}
+
+/** Returns the journey with turn `number` replaced by `update(turn)`, creating it if new. */
+function withTurn(
+  journey: SessionJourney,
+  number: number,
+  at: string,
+  update: (turn: JourneyTurn) => JourneyTurn,
+): SessionJourney {
+  const i = journey.turns.findIndex(t => t.number === number)
+  const turn: JourneyTurn =
+    i >= 0
+      ? journey.turns[i]
+      : {
+          number,
+          start_time: at,
+          end_time: at,
+          duration_ms: 0,
+          tool_calls: 0,
+          steps: [],
+          cost_usd: 0,
+        }
+  const turns = i >= 0 ? [...journey.turns] : [...journey.turns, turn]
+  turns[i >= 0 ? i : turns.length - 1] = update(turn)
+  return { ...journey, turns }
+}
+
+/** Whether `step` is the one a sub-agent's steps nest under. */
+function isContainer(step: JourneyStep, agent: JourneyTailSubagent): boolean {
+  if (agent.tool_use_id) {
+    return step.type === 'tool_call' && step.data.tool_use_id === agent.tool_use_id
+  }
+  return step.type === 'sub_agent' && step.data.agent_id === agent.agent_id
+}
+
+export function applyTailStep(state: TailedJourney, s: JourneyTailStep): TailedJourney {
+  const agent = s.agent_id ? state.agents[s.agent_id] : undefined
+  if (s.agent_id && !agent) return state
+
+  const journey = withTurn(state.journey, s.turn, s.step.timestamp, turn => {
+    const later = Date.parse(s.step.timestamp) > Date.parse(turn.end_time)
+    const endTime = later ? s.step.timestamp : turn.end_time
+    if (!agent) {
+      return {
+        ...turn,
+        end_time: endTime,
+        tool_calls: turn.tool_calls + (s.step.type === 'tool_call' ? 1 : 0),
+        steps: [...turn.steps, s.step],
+      }
+    }
+    return {
+      ...turn,
+      end_time: endTime,
+      steps: turn.steps.map(step =>
+        isContainer(step, agent) ? { ...step, steps: [...(step.steps ?? []), s.step] } : step,
+      ),
+    }
+  })
+  return { ...state, journey }
+}
+
+export function applyTailSubagent(state: TailedJourney, sub: JourneyTailSubagent): TailedJourney {
+  const agents = { ...state.agents, [sub.agent_id]: sub }
+  const journey = withTurn(state.journey, sub.turn, state.journey.end_time, turn => {
+    if (sub.tool_use_id) {
+      // Labelled the way the server labels a matched Task call.
+      return {
+        ...turn,
+        steps: turn.steps.map(step =>
+          isContainer(step, sub)
+            ? {
+                ...step,
+                data: { ...step.data, agent_type: sub.agent_type, description: sub.description },
+              }
+            : step,
+        ),
+      }
+    }
+    const step: JourneyStep = {
+      type: 'sub_agent',
+      timestamp: turn.end_time,
+      duration_ms: 0,
+      data: { agent_id: sub.agent_id, agent_type: sub.agent_type, description: sub.description },
+      steps: [],
+    }
+    return { ...turn, steps: [...turn.steps, step] }
+  })
+  return { ...state, agents, journey }
+}
+
+export function applyTailUsage(state: TailedJourney, p: JourneyTailUsage): TailedJourney {
+  const span = Date.parse(p.end_time) - Date.parse(p.start_time)
+  return {
+    ...state,
+    costUsd: p.cost_usd,
+    journey: {
+      ...state.journey,
+      model: p.model,
+      cwd: p.cwd,
+      git_branch: p.git_branch,
+      summary: p.summary,
+      start_time: p.start_time,
+      end_time: p.end_time,
+      total_duration_ms: Number.isNaN(span) ? 0 : span,
+      active_duration_ms: p.active_duration_ms,
+      total_turns: p.total_turns,
+      usage: p.usage,
+      subagent_usage: p.subagent_usage,
+      subagent_count: p.subagent_count,
+    },
+  }
+}
@@ -33,6 +33,7 @@ describe('toQueryParams', () => {
         filters({
           project: '/home/dev/repo',
           configDir: '/home/dev/.claude-personal',
+          codingAgent: 'codex',
           model: 'claude-opus-5',
           permissionMode: 'plan',
           links: 'with',
@@ -42,6 +43,7 @@ describe('toQueryParams', () => {
     ).toEqual({
       project: '/home/dev/repo',
       config_dir: '/home/dev/.claude-personal',
+      coding_agent: 'codex',
       model: 'claude-opus-5',
       permission_mode: 'plan',
       links: 'with',
@@ -113,6 +115,7 @@ describe('filterActive', () => {
   it.each([
     ['a project', { project: '/home/dev/repo' }],
     ['a config dir', { configDir: '/home/dev/.claude-personal' }],
+    ['a coding agent', { codingAgent: 'gemini' }],
     ['a search', { search: 'parser' }],
     ['favorites', { favorites: true }],
     ['a range start', { from: new Date() }],
@@ -63,6 +63,8 @@ export interface SessionFilters {
   project: string
   /** `'all'` matches every Claude config dir (i.e. every account). */
   configDir: string
+  /** `'all'` matches every coding agent. */
+  codingAgent: string
   /** Empty matches everything; matched case-insensitively as a substring. */
   search: string
   favorites: boolean
@@ -88,6 +90,7 @@ export interface SessionFilters {
 export const NO_FILTERS: SessionFilters = {
   project: 'all',
   configDir: 'all',
+  codingAgent: 'all',
   search: '',
   favorites: false,
   links: 'all',
@@ -114,6 +117,7 @@ export function toQueryParams(f: SessionFilters): URLSearchParams {
   const qs = new URLSearchParams()
   if (f.project !== 'all') qs.set('project', f.project)
   if (f.configDir !== 'all') qs.set('config_dir', f.configDir)
+  if (f.codingAgent !== 'all') qs.set('coding_agent', f.codingAgent)
   if (f.search.trim()) qs.set('q', f.search.trim())
   if (f.favorites) qs.set('favorites', 'true')
   if (f.links !== 'all') qs.set('links', f.links)
@@ -155,6 +159,7 @@ export function filterActive(f: SessionFilters): boolean {
   return (
     f.project !== 'all' ||
     f.configDir !== 'all' ||
+    f.codingAgent !== 'all' ||
     f.search.trim() !== '' ||
     f.favorites ||
     f.from !== null ||
@@ -0,0 +1,84 @@
+import { describe, it, expect } from 'vitest'
+import type { UsageWindow } from '../types'
+import { anyWindowOpen, timeUntil, usageTone, usageWindowSummary } from './usageWindows'
+
+function usageWindow(overrides: Partial<UsageWindow> = {}): UsageWindow {
+  return {
+    kind: 'five_hour',
+    active: true,
+    started_at: '2026-10-05T09:00:00Z',
+    resets_at: '2026-10-05T14:00:00Z',
+    messages: 12,
+    usage: {
+      input_tokens: 0,
+      output_tokens: 0,
+      cache_creation_tokens: 0,
+      cache_creation_5m_tokens: 0,
+      cache_creation_1h_tokens: 0,
+      cache_read_tokens: 0,
+    },
+    cost_usd: 4,
+    limit_usd: 20,
+    limit_source: 'learned',
+    completed_windows: 3,
+    used_percent: 20,
+    projected_percent: 80,
+    ...overrides,
+  }
+}
+
+describe('usageTone', () => {
+  it('colours a window against the warning threshold and the limit', () => {
+    expect(usageTone(usageWindow(), 80)).toBe('ok')
+    expect(usageTone(usageWindow({ used_percent: 85 }), 80)).toBe('warn')
+    expect(usageTone(usageWindow({ used_percent: 100 }), 80)).toBe('limit')
+  })
+
+  it('has nothing to measure without a limit or an open window', () => {
+    expect(usageTone(usageWindow({ limit_usd: 0, limit_source: '' }), 80)).toBe('unknown')
+    expect(usageTone(usageWindow({ active: false }), 80)).toBe('unknown')
+  })
+})
+
+describe('anyWindowOpen', () => {
+  it('is false when every dir is idle', () => {
+    const idle = usageWindow({ active: false })
+    expect(anyWindowOpen([{ config_dir: '/a', five_hour: idle, weekly: idle }])).toBe(false)
+    expect(anyWindowOpen([{ config_dir: '/a', five_hour: idle, weekly: usageWindow() }])).toBe(
+      true,
+    )
+  })
+})
+
+describe('timeUntil', () => {
+  const now = Date.parse('2026-10-05T11:15:00Z')
+
+  it('counts down to the minute, rounding up', () => {
+    expect(timeUntil('2026-10-05T11:59:30Z', now)).toBe('45m')
+    expect(timeUntil('2026-10-05T14:00:00Z', now)).toBe('2h 45m')
+    expect(timeUntil('2026-10-05T13:15:00Z', now)).toBe('2h')
+  })
+
+  it('switches to days for a weekly reset', () => {
+    expect(timeUntil('2026-10-08T15:15:00Z', now)).toBe('3d 4h')
+  })
+
+  it('reads now once the reset has passed', () => {
+    expect(timeUntil('2026-10-05T11:00:00Z', now)).toBe('now')
+  })
+})
+
+describe('usageWindowSummary', () => {
+  it('says a learned limit is the heaviest window, not the plan', () => {
+    expect(usageWindowSummary(usageWindow())).toBe(
+      '$4.00 at API prices, 12 messages · of $20.00, your heaviest window of the last 8 weeks · ' +
+        'on pace for 80% by the reset',
+    )
+  })
+
+  it('says when there is no limit to measure against', () => {
+    expect(usageWindowSummary(usageWindow({ limit_usd: 0, limit_source: '' }))).toBe(
+      '$4.00 at API prices, 12 messages · no limit set, and too little history to learn one',
+    )
+  })
+})
@@ -0,0 +1,57 @@
+/**
+ * Reading a Claude subscription usage window: how far it is through its limit,
+ * how long until it resets, and what the estimate behind it rests on.
+ */
+import { formatCost } from '@/lib/format'
+import type { UsageMeter, UsageWindow } from '@/types'
+
+/** How a window's meter is coloured. Unknown is a window with no limit to measure against. */
+export type UsageTone = 'ok' | 'warn' | 'limit' | 'unknown'
+
+export function usageTone(w: UsageWindow, warnPercent: number): UsageTone {
+  if (!w.active || w.limit_usd <= 0) return 'unknown'
+  if (w.used_percent >= 100) return 'limit'
+  if (w.used_percent >= warnPercent) return 'warn'
+  return 'ok'
+}
+
+/** Whether any config dir has a window open. With none, there is nothing to meter. */
+export function anyWindowOpen(meters: UsageMeter[]): boolean {
+  return meters.some(m => m.five_hour.active || m.weekly.active)
+}
+
+/** Time left until an instant, to the minute: "45m", "2h 14m", "3d 4h", or "now" once it has passed. */
+export function timeUntil(iso: string, now: number = Date.now()): string {
+  const mins = Math.ceil((new Date(iso).getTime() - now) / 60_000)
+  if (mins <= 0) return 'now'
+  if (mins < 60) return `${mins}m`
+  const h = Math.floor(mins / 60)
+  const m = mins % 60
+  if (h < 24) return m > 0 ? `${h}h ${m}m` : `${h}h`
+  const d = Math.floor(h / 24)
+  const rh = h % 24
+  return rh > 0 ? `${d}d ${rh}h` : `${d}d`
+}
+
+/**
+ * The figures behind a meter, for its tooltip. A learned limit says so: it is
+ * the heaviest window so far, not what Anthropic allows, and reads differently.
+ */
+export function usageWindowSummary(w: UsageWindow): string {
+  if (!w.active) return 'No window open. The next message starts one.'
+  const parts = [`${formatCost(w.cost_usd)} at API prices, ${w.messages} messages`]
+  if (w.limit_source === 'configured') {
+    parts.push(`of your ${formatCost(w.limit_usd)} limit`)
+  } else if (w.limit_source === 'learned') {
+    parts.push(`of ${formatCost(w.limit_usd)}, your heaviest window of the last 8 weeks`)
+  } else {
+    parts.push('no limit set, and too little history to learn one')
+  }
+  if (w.limit_at) {
+    const at = new Date(w.limit_at).toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' })
+    parts.push(`at this pace the limit is reached at ${at}`)
+  } else if (w.limit_usd > 0) {
+    parts.push(`on pace for ${Math.round(w.projected_percent)}% by the reset`)
+  }
+  return parts.join(' · ')
+}
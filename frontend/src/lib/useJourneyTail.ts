@@ -0,0 +1,78 @@
+import { useEffect, useRef, useState } from 'react'
+import { claudeSessionsApi } from '@/lib/api'
+import {
+  applyTailUsage,
+  applyTailStep,
+  applyTailSubagent,
+  emptyTailedJourney,
+  type TailedJourney,
+} from './journeyTail'
+
+/**
+ * A session's journey as Claude Code writes it, while `enabled`.
+ *
+ * Null until the stream has replayed the transcript as it stood, so the page
+ * keeps showing the journey it loaded rather than one growing from nothing.
+ * Events are folded into a ref and rendered at most once a frame: the replay
+ * of a long session is thousands of steps arriving back to back.
+ *
+ * `onStopped` is called when the stream drops. It is not resumed: the server
+ * would replay the whole transcript again onto the steps already shown.
+ */
+export function useJourneyTail(
+  sessionId: string | undefined,
+  enabled: boolean,
+  onStopped: () => void,
+): TailedJourney | null {
+  const [tailed, setTailed] = useState<TailedJourney | null>(null)
+
+  // Held in a ref so the caller's inline arrow does not reopen the stream.
+  const stopped = useRef(onStopped)
+  useEffect(() => {
+    stopped.current = onStopped
+  }, [onStopped])
+
+  useEffect(() => {
+    if (!sessionId || !enabled) {
+      setTailed(null)
+      return
+    }
+    let state = emptyTailedJourney(sessionId)
+    let ready = false
+    let frame = 0
+    const flush = () => {
+      if (ready && !frame) {
+        frame = requestAnimationFrame(() => {
+          frame = 0
+          setTailed(state)
+        })
+      }
+    }
+
+    const close = claudeSessionsApi.tail(sessionId, {
+      onStep: s => {
+        state = applyTailStep(state, s)
+        flush()
+      },
+      onSubagent: s => {
+        state = applyTailSubagent(state, s)
+        flush()
+      },
+      onUsage: p => {
+        state = applyTailUsage(state, p)
+        flush()
+      },
+      onReady: () => {
+        ready = true
+        flush()
+      },
+      onError: () => stopped.current(),
+    })
+    return () => {
+      close()
+      cancelAnimationFrame(frame)
+    }
+  }, [sessionId, enabled])
+
+  return tailed
+}
@@ -0,0 +1,196 @@
+/**
+ * Approval requests from scheduled tasks, triggered runs and MCP runs.
+ *
+ * An agent that gates tools pauses its unattended runs on each gated call
+ * until someone answers — on the configured Telegram, Slack or email channel,
+ * or here. Pending requests come first and can be answered on this page; the
+ * rest is the record of who approved what.
+ */
+import { useCallback, useEffect, useState } from 'react'
+import { ShieldCheck } from 'lucide-react'
+
+import { approvalsApi } from '@/lib/api'
+import { formatRelativeTime } from '@/lib/utils'
+import { Button } from '@/components/ui/button'
+import type { ApprovalRequest, ApprovalStatus } from '@/types'
+
+/** How often the page looks for new requests while open. */
+const REFRESH_MS = 10_000
+
+const STATUS_COLORS: Record<ApprovalStatus, string> = {
+  pending: 'bg-amber-50 text-amber-700 dark:bg-amber-900/30 dark:text-amber-400',
+  approved: 'bg-green-50 text-green-700 dark:bg-green-900/30 dark:text-green-400',
+  denied: 'bg-red-50 text-red-700 dark:bg-red-900/30 dark:text-red-400',
+  expired: 'bg-zinc-100 text-zinc-500 dark:bg-zinc-800 dark:text-zinc-400',
+}
+
+const SOURCE_LABELS: Record<ApprovalRequest['source'], string> = {
+  chat: 'API chat',
+  scheduled_task: 'scheduled task',
+  trigger: 'trigger',
+  mcp: 'MCP',
+}
+
+/** Indents a tool's JSON input for reading; anything else is shown as is. */
+function formatInput(raw: string): string {
+  try {
+    return JSON.stringify(JSON.parse(raw), null, 2)
+  } catch {
+    return raw
+  }
+}
+
+function ApprovalCard({
+  request,
+  onDecide,
+}: Readonly<{
+  request: ApprovalRequest
+  onDecide: (id: string, approve: boolean) => void
+}>) {
+  const pending = request.status === 'pending'
+  return (
+    <li className="rounded-lg border border-zinc-200 dark:border-zinc-700/60 px-4 py-3">
+      <div className="flex items-start justify-between gap-3">
+        <div className="min-w-0">
+          <p className="text-sm font-medium text-zinc-900 dark:text-zinc-100 truncate">
+            {request.title || request.agent_slug}
+          </p>
+          <p className="text-xs text-zinc-500 dark:text-zinc-400 mt-0.5">
+            <span className="font-mono">{request.tool_name}</span>
+            {request.agent_slug && <> · {request.agent_slug}</>} ·{' '}
+            {SOURCE_LABELS[request.source]} · {formatRelativeTime(request.created_at)}
+          </p>
+        </div>
+        <span
+          className={`shrink-0 inline-flex items-center rounded-full px-2 py-0.5 text-xs font-medium ${STATUS_COLORS[request.status]}`}
+        >
+          {request.status}
+        </span>
+      </div>
+
+      <pre className="mt-2 max-h-48 overflow-auto rounded-md bg-zinc-50 dark:bg-zinc-800 px-3 py-2 text-xs text-zinc-700 dark:text-zinc-300 whitespace-pre-wrap break-words">
+        {formatInput(request.tool_input)}
+      </pre>
+
+      {pending ? (
+        <div className="mt-3 flex items-center justify-between gap-2">
+          <span className="text-xs text-zinc-500 dark:text-zinc-400">
+            Expires {new Date(request.expires_at).toLocaleTimeString()}
+          </span>
+          <div className="flex gap-2">
+            <Button
+              size="sm"
+              variant="outline"
+              className="text-xs h-8"
+              onClick={() => onDecide(request.id, false)}
+            >
+              Deny
+            </Button>
+            <Button size="sm" className="text-xs h-8" onClick={() => onDecide(request.id, true)}>
+              Approve
+            </Button>
+          </div>
+        </div>
+      ) : (
+        request.decided_by && (
+          <p className="mt-2 text-xs text-zinc-500 dark:text-zinc-400">
+            {request.status === 'approved' ? 'Approved' : 'Denied'} by {request.decided_by}
+          </p>
+        )
+      )}
+    </li>
+  )
+}
+
+export default function ApprovalsPage() {
+  const [requests, setRequests] = useState<ApprovalRequest[]>([])
+  const [loading, setLoading] = useState(true)
+  const [error, setError] = useState<string | null>(null)
+
+  const load = useCallback(() => {
+    approvalsApi
+      .list()
+      .then(data => {
+        setRequests(data)
+        setError(null)
+      })
+      .catch(err => setError(err instanceof Error ? err.message : 'Failed to load approvals'))
+      .finally(() => setLoading(false))
+  }, [])
+
+  useEffect(() => {
+    load()
+    const timer = setInterval(load, REFRESH_MS)
+    return () => clearInterval(timer)
+  }, [load])
+
+  const decide = async (id: string, approve: boolean) => {
+    try {
+      const updated = await (approve ? approvalsApi.approve(id) : approvalsApi.deny(id))
+      setRequests(prev => prev.map(r => (r.id === id ? updated : r)))
+    } catch (err) {
+      // Most often answered elsewhere first; the reload shows by whom.
+      setError(err instanceof Error ? err.message : 'Failed to answer')
+      load()
+    }
+  }
+
+  const pending = requests.filter(r => r.status === 'pending')
+  const answered = requests.filter(r => r.status !== 'pending')
+
+  return (
+    <div className="flex flex-col h-full">
+      <div className="border-b border-zinc-100 dark:border-zinc-800 px-4 sm:px-6 py-4 shrink-0">
+        <h1 className="text-xl font-semibold text-zinc-900 dark:text-zinc-100">Approvals</h1>
+        <p className="text-xs text-zinc-500 dark:text-zinc-400 mt-0.5">
+          {pending.length} waiting for an answer
+        </p>
+      </div>
+
+      {error && (
+        <div className="mx-6 mt-3 rounded-md border border-red-200 bg-red-50 dark:border-red-800 dark:bg-red-900/20 px-4 py-2.5 text-sm text-red-700 dark:text-red-400">
+          {error}
+        </div>
+      )}
+
+      <div className="flex-1 overflow-y-auto px-4 sm:px-6 py-4">
+        {!loading && requests.length === 0 ? (
+          <div className="flex flex-col items-center justify-center py-20 text-center">
+            <div className="flex h-12 w-12 items-center justify-center rounded-full bg-zinc-100 dark:bg-zinc-800 mb-4">
+              <ShieldCheck className="h-5 w-5 text-zinc-400 dark:text-zinc-500" />
+            </div>
+            <h2 className="text-lg font-semibold text-zinc-900 dark:text-zinc-100 mb-1">
+              No approval requests
+            </h2>
+            <p className="text-sm text-zinc-500 dark:text-zinc-400 max-w-sm">
+              List tools under “Require approval” on an agent, and its scheduled and triggered
+              runs will ask before calling them.
+            </p>
+          </div>
+        ) : (
+          <div className="flex flex-col gap-6 max-w-3xl">
+            {pending.length > 0 && (
+              <ul className="flex flex-col gap-3">
+                {pending.map(r => (
+                  <ApprovalCard key={r.id} request={r} onDecide={decide} />
+                ))}
+              </ul>
+            )}
+            {answered.length > 0 && (
+              <section>
+                <h2 className="text-[11px] font-semibold uppercase tracking-[0.06em] text-zinc-500 dark:text-zinc-400 mb-2">
+                  Answered
+                </h2>
+                <ul className="flex flex-col gap-3">
+                  {answered.map(r => (
+                    <ApprovalCard key={r.id} request={r} onDecide={decide} />
+                  ))}
+                </ul>
+              </section>
+            )}
+          </div>
+        )}
+      </div>
+    </div>
+  )
+}
@@ -12,6 +12,8 @@ vi.mock('@/lib/api', () => ({
     facets: vi.fn(),
     projects: vi.fn(),
     status: vi.fn(),
+    live: vi.fn(),
+    usageWindows: vi.fn(),
     refresh: vi.fn(),
     toggleFavorite: vi.fn(),
   },
@@ -32,6 +34,7 @@ const facets = (overrides: Partial<ClaudeSessionFacets> = {}): ClaudeSessionFace
   models: [],
   permission_modes: [],
   config_dirs: [ACCOUNT_A, ACCOUNT_B],
+  coding_agents: ['claude-code'],
   has_favorites: false,
   has_prs: false,
   ...overrides,
@@ -94,6 +97,12 @@ describe('ClaudeSessionsPage account switcher', () => {
     vi.mocked(claudeSessionsApi.facets).mockResolvedValue(facets())
     vi.mocked(claudeSessionsApi.projects).mockResolvedValue([])
     vi.mocked(claudeSessionsApi.status).mockResolvedValue(idleStatus)
+    vi.mocked(claudeSessionsApi.live).mockResolvedValue([])
+    vi.mocked(claudeSessionsApi.usageWindows).mockResolvedValue({
+      generated_at: '2026-10-05T09:00:00Z',
+      warn_percent: 80,
+      meters: [],
+    })
   })
 
   // The regression: selecting a different account set the dropdown value but
@@ -126,4 +135,32 @@ describe('ClaudeSessionsPage account switcher', () => {
     // The list must refetch, now scoped to the chosen account.
     await waitFor(() => expect(lastRequestedConfigDir()).toBe(ACCOUNT_B))
   })
+
+  // The agent filter follows the same rule: offered only once another agent's
+  // sessions are indexed, and picking one refetches the list.
+  it('narrows the list to one coding agent', async () => {
+    const user = userEvent.setup({ pointerEventsCheck: 0 })
+    vi.mocked(claudeSessionsApi.facets).mockResolvedValue(
+      facets({ coding_agents: ['claude-code', 'codex'] }),
+    )
+
+    render(
+      <MemoryRouter>
+        <ClaudeSessionsPage />
+      </MemoryRouter>,
+    )
+
+    const agentTrigger = await waitFor(() => {
+      const el = screen.getAllByRole('combobox').find(c => c.textContent?.includes('All agents'))
+      expect(el).toBeDefined()
+      return el!
+    })
+    await user.click(agentTrigger)
+    await user.click(await screen.findByText('Codex CLI'))
+
+    await waitFor(() => {
+      const calls = vi.mocked(claudeSessionsApi.list).mock.calls
+      expect(calls[calls.length - 1][0]?.filters?.get('coding_agent')).toBe('codex')
+    })
+  })
 })
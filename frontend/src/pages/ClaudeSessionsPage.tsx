@@ -16,6 +16,7 @@ import type {
   ClaudeProject,
   ClaudeSessionStatus,
 } from '@/types'
+import { CODING_AGENT_LABELS } from '@/types'
 import { formatDateTime, formatRelativeTime } from '@/lib/utils'
 import {
   Select,
@@ -58,9 +59,12 @@ import {
   type SessionSort,
 } from '@/lib/sessionQuery'
 import { groupSessionsByDay, type SessionDayGroup } from '@/lib/sessionGroups'
+import { LiveSessionsStrip } from './LiveSessionsStrip'
+import { UsageWindowsStrip } from './UsageWindowsStrip'
 import { sessionCost, sessionDurationMs } from '@/lib/sessionMetrics'
 import { useDebounced } from '@/lib/useDebounced'
 import { useSessionPages, useDraftMatchCount } from '@/lib/useSessionPages'
+import { TranscriptMatches } from './TranscriptMatches'
 
 // How often to re-check whether the background re-cost finished. Slow enough
 // to be free, fast enough that a ~18s corpus rescan is noticed promptly.
@@ -180,6 +184,7 @@ export default function ClaudeSessionsPage() {
   const [sort, setSort] = useState<SessionSort>('recent')
   const [filterProject, setFilterProject] = useState(searchParams.get('project') ?? 'all')
   const [filterConfigDir, setFilterConfigDir] = useState('all')
+  const [filterCodingAgent, setFilterCodingAgent] = useState('all')
   const [expanded, setExpanded] = useState<ReadonlySet<string>>(() => new Set())
 
   // Keep the project filter in sync when arriving via a new drill-down URL
@@ -223,6 +228,7 @@ export default function ClaudeSessionsPage() {
       ...advanced,
       project: filterProject,
       configDir: filterConfigDir,
+      codingAgent: filterCodingAgent,
       search: debouncedSearch,
       favorites: filterFavorites,
       from,
@@ -233,6 +239,7 @@ export default function ClaudeSessionsPage() {
     advanced,
     filterProject,
     filterConfigDir,
+    filterCodingAgent,
     debouncedSearch,
     filterFavorites,
     timePreset,
@@ -382,6 +389,8 @@ export default function ClaudeSessionsPage() {
   const models = facets?.models ?? []
   // Only offered when the corpus actually spans more than one account.
   const configDirs = facets?.config_dirs?.filter(Boolean) ?? []
+  // Likewise only once another coding agent's sessions have been imported.
+  const codingAgents = facets?.coding_agents ?? []
   const activeAdvanced = countActive(advanced)
 
   const timeFilterActive = drilldownActive || timePreset !== 'all'
@@ -501,6 +510,12 @@ export default function ClaudeSessionsPage() {
         </div>
       )}
 
+      {/* Sessions Claude Code has open, when its hooks are installed. */}
+      <LiveSessionsStrip onOpen={handleOpen} onTurnEnded={() => reloadRef.current()} />
+
+      {/* How much of each account's subscription usage windows is used. */}
+      <UsageWindowsStrip />
+
       {/* Drill-down banner (from analytics charts) */}
       {drilldownActive && (
         <div className="flex items-center justify-between gap-3 px-4 sm:px-6 py-2 border-b border-indigo-100 dark:border-indigo-900/50 bg-indigo-50/60 dark:bg-indigo-950/30 shrink-0">
@@ -538,7 +553,7 @@ export default function ClaudeSessionsPage() {
               <input
                 value={search}
                 onChange={e => setSearch(e.target.value)}
-                placeholder="Search by ID or message…"
+                placeholder="Search sessions and transcripts…"
                 className="w-full h-[34px] rounded-lg border border-zinc-200 dark:border-zinc-700 bg-white dark:bg-zinc-900 text-zinc-900 dark:text-zinc-100 pl-8 pr-3 text-[13px] placeholder:text-zinc-500 dark:placeholder:text-zinc-400 focus:outline-none focus:ring-1 focus:ring-zinc-900 dark:focus:ring-zinc-400 focus:border-zinc-900 dark:focus:border-zinc-400"
               />
             </div>
@@ -573,6 +588,21 @@ export default function ClaudeSessionsPage() {
                 </SelectContent>
               </Select>
             )}
+            {codingAgents.length > 1 && (
+              <Select value={filterCodingAgent} onValueChange={setFilterCodingAgent}>
+                <SelectTrigger className="w-full sm:w-40 h-[34px] text-xs">
+                  <SelectValue placeholder="All agents" />
+                </SelectTrigger>
+                <SelectContent>
+                  <SelectItem value="all">All agents</SelectItem>
+                  {codingAgents.map(a => (
+                    <SelectItem key={a} value={a} className="text-xs">
+                      {CODING_AGENT_LABELS[a] ?? a}
+                    </SelectItem>
+                  ))}
+                </SelectContent>
+              </Select>
+            )}
             <Select
               value={timePreset}
               onValueChange={v => setTimePreset(v as TimePreset)}
@@ -667,6 +697,10 @@ export default function ClaudeSessionsPage() {
             </div>
           </div>
 
+          {filters.search.trim() && (
+            <TranscriptMatches key={toQueryParams(filters).toString()} filters={filters} />
+          )}
+
           {advancedOpen && (
             <AdvancedFilterPanel
               draft={advancedDraft}
@@ -1420,6 +1454,11 @@ const SessionRow = memo(function SessionRow({
         </div>
 
         <div className="flex items-center gap-1.5 min-w-0">
+          {session.coding_agent && session.coding_agent !== 'claude-code' && (
+            <span className="text-[11px] border border-zinc-200 dark:border-zinc-700 text-zinc-500 dark:text-zinc-400 rounded-md px-1.5 py-px whitespace-nowrap">
+              {CODING_AGENT_LABELS[session.coding_agent] ?? session.coding_agent}
+            </span>
+          )}
           <span className="font-mono text-[11.5px] bg-zinc-100 dark:bg-zinc-800 text-zinc-600 dark:text-zinc-300 rounded-md px-1.5 py-0.5 truncate">
             {shortPath(session.project_path)}
           </span>
@@ -9,7 +9,7 @@
  * Each card states one fact with a number and, where there is one, the thing to
  * do about it. The numbers come from the backend; only the phrasing lives here.
  */
-import { Coins, Layers, Bot, Flame } from 'lucide-react'
+import { Coins, Layers, Bot, Flame, TrendingUp, AlertTriangle } from 'lucide-react'
 
 import { formatCost, formatDuration, formatTokens } from '@/lib/format'
 import type { InsightCard } from '@/types'
@@ -61,6 +61,26 @@ function copyFor(card: InsightCard): CardCopy | null {
         action:
           'A few long runs dominate the total; splitting them is where a change of habit pays.',
       }
+    case 'spend_forecast':
+      return {
+        icon: TrendingUp,
+        headline: `This month is heading for about ${formatCost(card.amount_usd ?? 0)}`,
+        fact: `${formatCost(card.comparison_usd ?? 0)} so far, with ${card.count ?? 0} day${card.count === 1 ? '' : 's'} left at the last two weeks' pace${card.model ? `; ${(card.percent ?? 0).toFixed(1)}% of it on ${formatModelName(card.model)}` : ''}.`,
+      }
+    case 'day_anomaly':
+    case 'session_anomaly': {
+      const subject = card.kind === 'day_anomaly' ? `${card.date ?? ''} was` : 'One session was'
+      const figure =
+        card.metric === 'tokens'
+          ? `${formatTokens(card.tokens ?? 0)} tokens`
+          : `${formatCost(card.amount_usd ?? 0)} against a usual ${formatCost(card.comparison_usd ?? 0)}`
+      const more = (card.count ?? 0) - 1
+      return {
+        icon: AlertTriangle,
+        headline: `${subject} ${(card.ratio ?? 0).toFixed(1)}× the usual ${card.metric === 'tokens' ? 'token use' : 'spend'}`,
+        fact: `${figure}${more > 0 ? `, and ${more} more like it in this window` : ''}.`,
+      }
+    }
     default:
       return null
   }
@@ -1,7 +1,7 @@
 import { useState, useEffect, useCallback } from 'react'
 import { useNavigate } from 'react-router-dom'
 import { jobHistoryApi } from '@/lib/api'
-import type { JobHistoryEntry } from '@/types'
+import type { ApprovalRequest, JobHistoryEntry } from '@/types'
 import { Button } from '@/components/ui/button'
 import { Checkbox } from '@/components/ui/checkbox'
 import { Dialog, DialogContent, DialogHeader, DialogTitle } from '@/components/ui/dialog'
@@ -49,6 +49,20 @@ function DetailRow({ label, children }: Readonly<{ label: string; children: Reac
   )
 }
 
+/** Says how an approval request ended and who answered it. */
+function approvalOutcome(a: ApprovalRequest): string {
+  switch (a.status) {
+    case 'approved':
+      return `approved by ${a.decided_by}`
+    case 'denied':
+      return `denied by ${a.decided_by}`
+    case 'expired':
+      return 'expired unanswered'
+    default:
+      return 'waiting for an answer'
+  }
+}
+
 const PAGE_SIZE = 50
 
 export default function JobHistoriesPage() {
@@ -92,6 +106,15 @@ export default function JobHistoriesPage() {
     load(newOffset)
   }
 
+  // The list leaves out the approvals a run asked for; the detail loads them.
+  const openDetail = (entry: JobHistoryEntry) => {
+    setSelected(entry)
+    jobHistoryApi
+      .get(entry.id)
+      .then(full => setSelected(prev => (prev?.id === full.id ? full : prev)))
+      .catch(() => undefined)
+  }
+
   const toggleCheck = (id: string) => {
     setCheckedIds(prev => {
       const next = new Set(prev)
@@ -219,7 +242,7 @@ export default function JobHistoriesPage() {
                 {entries.map(entry => (
                   <tr
                     key={entry.id}
-                    onClick={() => setSelected(entry)}
+                    onClick={() => openDetail(entry)}
                     className="border-b border-zinc-50 dark:border-zinc-800/50 hover:bg-zinc-50 dark:hover:bg-zinc-800/50 transition-colors cursor-pointer"
                   >
                     <td className="px-4 py-2.5" onClick={e => e.stopPropagation()}>
@@ -365,6 +388,29 @@ export default function JobHistoriesPage() {
                   </div>
                 )}
 
+                {selected.approvals && selected.approvals.length > 0 && (
+                  <div className="mt-3">
+                    <p className="text-xs font-medium text-zinc-500 dark:text-zinc-400 mb-1">
+                      Approvals
+                    </p>
+                    <ul className="flex flex-col gap-1.5">
+                      {selected.approvals.map(a => (
+                        <li
+                          key={a.id}
+                          className="flex items-center justify-between gap-2 rounded-md bg-zinc-50 dark:bg-zinc-800 px-3 py-1.5 text-sm"
+                        >
+                          <span className="font-mono text-zinc-700 dark:text-zinc-300 truncate">
+                            {a.tool_name}
+                          </span>
+                          <span className="shrink-0 text-xs text-zinc-500 dark:text-zinc-400">
+                            {approvalOutcome(a)}
+                          </span>
+                        </li>
+                      ))}
+                    </ul>
+                  </div>
+                )}
+
                 {selected.error_message && (
                   <div className="mt-3">
                     <p className="text-xs font-medium text-red-500 dark:text-red-400 mb-1">Error</p>
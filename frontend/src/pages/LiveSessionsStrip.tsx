@@ -0,0 +1,84 @@
+/**
+ * The sessions Claude Code has open right now, as its hooks report them.
+ *
+ * Without hooks a session reaches the list only when a scan walks the disk.
+ * With `agento hook install` the server hears of every prompt, tool call and
+ * turn end as it happens, so this strip shows what is running and the list
+ * behind it reloads when a turn finishes rather than on the next scan.
+ */
+import { useEffect, useRef, useState } from 'react'
+
+import { claudeSessionsApi } from '@/lib/api'
+import { shortPath } from '@/lib/format'
+import type { LiveClaudeSession } from '@/types'
+
+/** How often to re-check. The endpoint reads process memory, so it is cheap. */
+const POLL_MS = 5_000
+
+export function LiveSessionsStrip({
+  onOpen,
+  onTurnEnded,
+}: Readonly<{ onOpen: (sessionId: string) => void; onTurnEnded?: () => void }>) {
+  const [live, setLive] = useState<LiveClaudeSession[]>([])
+  const running = useRef(new Set<string>())
+
+  // Held in a ref for the same reason as ScanStatusNotice's callback: the
+  // caller's arrow is recreated every render, and the poll must not restart.
+  const turnEnded = useRef(onTurnEnded)
+  useEffect(() => {
+    turnEnded.current = onTurnEnded
+  }, [onTurnEnded])
+
+  useEffect(() => {
+    let cancelled = false
+    let timer: ReturnType<typeof setTimeout>
+
+    const poll = async () => {
+      try {
+        const sessions = await claudeSessionsApi.live()
+        if (cancelled) return
+        const now = new Set(sessions.filter(s => s.state === 'running').map(s => s.session_id))
+        // A session that stopped running has just written its turn, which the
+        // server has already re-read: reload so the list shows it.
+        if ([...running.current].some(id => !now.has(id))) turnEnded.current?.()
+        running.current = now
+        setLive(sessions)
+      } catch {
+        // An affordance, not the feature: keep polling quietly.
+      }
+      if (!cancelled) timer = setTimeout(poll, POLL_MS)
+    }
+
+    void poll()
+    return () => {
+      cancelled = true
+      clearTimeout(timer)
+    }
+  }, [])
+
+  if (live.length === 0) return null
+
+  return (
+    <div className="flex flex-wrap items-center gap-2 px-4 sm:px-6 py-2 border-b border-zinc-100 dark:border-zinc-700/50 shrink-0">
+      <span className="text-xs font-medium text-zinc-500 dark:text-zinc-400">Open now</span>
+      {live.map(s => (
+        <button
+          key={s.session_id}
+          onClick={() => onOpen(s.session_id)}
+          title={`${s.session_id} · last event ${s.last_event}`}
+          className="flex items-center gap-1.5 rounded-full border border-zinc-200 dark:border-zinc-700 px-2.5 py-0.5 text-xs text-zinc-700 dark:text-zinc-300 hover:bg-zinc-50 dark:hover:bg-zinc-800"
+        >
+          <span
+            className={`h-2 w-2 rounded-full ${
+              s.state === 'running' ? 'bg-emerald-500 animate-pulse' : 'bg-zinc-400'
+            }`}
+          />
+          <span className="font-mono">{shortPath(s.cwd || s.project_path)}</span>
+          <span className="text-zinc-400">
+            {s.state === 'running' ? (s.last_tool ?? 'running') : 'waiting'}
+          </span>
+        </button>
+      ))}
+    </div>
+  )
+}
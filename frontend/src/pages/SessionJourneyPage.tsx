@@ -1,6 +1,7 @@
-import { useState, useEffect, useCallback } from 'react'
-import { useParams, useNavigate } from 'react-router-dom'
+import { useState, useEffect, useCallback, useRef } from 'react'
+import { useParams, useNavigate, useSearchParams } from 'react-router-dom'
 import { claudeSessionsApi } from '@/lib/api'
+import { useJourneyTail } from '@/lib/useJourneyTail'
 import type { SessionJourney, JourneyTurn, JourneyStep, ClaudeTokenUsage } from '@/types'
 import { Badge } from '@/components/ui/badge'
 import {
@@ -19,8 +20,9 @@ import {
   CheckCircle,
   Bot,
   Scissors,
+  Radio,
 } from 'lucide-react'
-import { formatTokens, shortPath, formatDuration } from '@/lib/format'
+import { formatCost, formatTokens, shortPath, formatDuration } from '@/lib/format'
 
 // ── Helpers ───────────────────────────────────────────────────────────────────
 
@@ -431,8 +433,21 @@ function StepRow({ step, depth = 0 }: Readonly<{ step: JourneyStep; depth?: numb
  */
 const AUTO_EXPAND_TURN_LIMIT = 30
 
-function TurnCard({ turn, defaultOpen }: Readonly<{ turn: JourneyTurn; defaultOpen: boolean }>) {
-  const [open, setOpen] = useState(defaultOpen)
+function TurnCard({
+  turn,
+  defaultOpen,
+  targetStep,
+}: Readonly<{ turn: JourneyTurn; defaultOpen: boolean; targetStep?: number }>) {
+  const [open, setOpen] = useState(defaultOpen || targetStep !== undefined)
+  const targetRef = useRef<HTMLDivElement>(null)
+
+  // A search hit links here with ?turn=&step=; bring that step into view once
+  // the turn has rendered it.
+  useEffect(() => {
+    if (open && targetStep !== undefined) {
+      targetRef.current?.scrollIntoView({ block: 'center' })
+    }
+  }, [open, targetStep])
 
   return (
     <div
@@ -484,9 +499,19 @@ function TurnCard({ turn, defaultOpen }: Readonly<{ turn: JourneyTurn; defaultOp
       {/* Turn steps */}
       {open && (
         <div className="px-4 pb-3 pt-1 border-t border-zinc-100 dark:border-zinc-700/50">
-          {turn.steps.map((step, idx) => (
-            <StepRow key={`${step.type}-${step.timestamp}-${idx}`} step={step} />
-          ))}
+          {turn.steps.map((step, idx) =>
+            idx === targetStep ? (
+              <div
+                key={`${step.type}-${step.timestamp}-${idx}`}
+                ref={targetRef}
+                className="rounded-md ring-2 ring-amber-300 dark:ring-amber-500/60 -mx-2 px-2 pt-2"
+              >
+                <StepRow step={step} />
+              </div>
+            ) : (
+              <StepRow key={`${step.type}-${step.timestamp}-${idx}`} step={step} />
+            ),
+          )}
         </div>
       )}
     </div>
@@ -574,15 +599,20 @@ function TokenUsageBar({ journey }: Readonly<{ journey: SessionJourney }>) {
 export default function SessionJourneyPage() {
   const { id } = useParams<{ id: string }>()
   const navigate = useNavigate()
-  const [journey, setJourney] = useState<SessionJourney | null>(null)
+  const [searchParams] = useSearchParams()
+  const targetTurn = Number(searchParams.get('turn') ?? 0)
+  const targetStep = searchParams.get('step')
+  const [loaded, setLoaded] = useState<SessionJourney | null>(null)
   const [loading, setLoading] = useState(true)
   const [error, setError] = useState<string | null>(null)
+  const [following, setFollowing] = useState(false)
+  const [tailLost, setTailLost] = useState(false)
 
   const load = useCallback(async () => {
     if (!id) return
     try {
       const j = await claudeSessionsApi.journey(id)
-      setJourney(j)
+      setLoaded(j)
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to load session journey')
     } finally {
@@ -594,6 +624,20 @@ export default function SessionJourneyPage() {
     load()
   }, [load])
 
+  // Following swaps the loaded journey for one the live tail keeps current;
+  // stopping, by hand or because the stream dropped, reloads the real thing.
+  const tailed = useJourneyTail(id, following, () => {
+    setFollowing(false)
+    setTailLost(true)
+    void load()
+  })
+  const toggleFollowing = () => {
+    if (following) void load()
+    setTailLost(false)
+    setFollowing(f => !f)
+  }
+  const journey = tailed?.journey ?? loaded
+
   if (loading) {
     return (
       <div className="flex h-full items-center justify-center">
@@ -622,6 +666,7 @@ export default function SessionJourneyPage() {
   }
 
   const totalUsage = totalJourneyUsage(journey)
+  const lastTurn = journey.turns[journey.turns.length - 1]?.number
 
   return (
     <div className="flex flex-col h-full">
@@ -704,6 +749,29 @@ export default function SessionJourneyPage() {
               )}
             </div>
           </div>
+          <div className="flex flex-col items-end gap-1 shrink-0">
+            <button
+              onClick={toggleFollowing}
+              title="Stream new steps, tokens and cost as Claude Code writes them"
+              className={`flex items-center gap-1.5 rounded-md border px-2.5 py-1 text-xs transition-colors ${
+                following
+                  ? 'border-emerald-300 dark:border-emerald-700 text-emerald-700 dark:text-emerald-400'
+                  : 'border-zinc-200 dark:border-zinc-700 text-zinc-600 dark:text-zinc-300 hover:bg-zinc-50 dark:hover:bg-zinc-800'
+              }`}
+            >
+              <Radio className={`h-3.5 w-3.5 ${following ? 'animate-pulse' : ''}`} />
+              {following ? 'Following' : 'Follow live'}
+            </button>
+            {/* The tail prices every message as it lands, sub-agents included,
+                so a long run's spend is visible while it can still be stopped. */}
+            {tailed && (
+              <span className="text-xs text-zinc-500 dark:text-zinc-400">
+                {formatCost(tailed.costUsd)} so far
+              </span>
+            )}
+            {following && !tailed && <span className="text-xs text-zinc-400">Connecting…</span>}
+            {tailLost && <span className="text-xs text-amber-600">Live view disconnected</span>}
+          </div>
         </div>
       </div>
 
@@ -726,7 +794,13 @@ export default function SessionJourneyPage() {
               <TurnCard
                 key={turn.number}
                 turn={turn}
-                defaultOpen={journey.turns.length <= AUTO_EXPAND_TURN_LIMIT && turn.number <= 3}
+                defaultOpen={
+                  (journey.turns.length <= AUTO_EXPAND_TURN_LIMIT && turn.number <= 3) ||
+                  (tailed !== null && turn.number === lastTurn)
+                }
+                targetStep={
+                  turn.number === targetTurn && targetStep !== null ? Number(targetStep) : undefined
+                }
               />
             ))}
           </div>
@@ -19,6 +19,8 @@ import AdvancedTab from '@/components/AdvancedTab'
 import MonitoringTab from '@/components/MonitoringTab'
 import ModelPricingTab from '@/components/ModelPricingTab'
 import DataAnalyticsTab from '@/components/DataAnalyticsTab'
+import SecurityTab from '@/components/SecurityTab'
+import BackupsTab from '@/components/BackupsTab'
 import { settingsApi } from '@/lib/api'
 import type { SettingsResponse } from '@/types'
 import { MODELS } from '@/types'
@@ -32,6 +34,8 @@ type Tab =
   | 'advanced'
   | 'monitoring'
   | 'pricing'
+  | 'security'
+  | 'backups'
 
 export default function SettingsPage() {
   const [activeTab, setActiveTab] = useState<Tab>('general')
@@ -196,6 +200,26 @@ export default function SettingsPage() {
           >
             Model Pricing
           </button>
+          <button
+            className={`flex w-full items-center gap-2 rounded-md px-3 py-2 text-sm transition-colors ${
+              activeTab === 'security'
+                ? 'bg-zinc-900 dark:bg-zinc-100 text-white dark:text-zinc-900'
+                : 'text-zinc-600 dark:text-zinc-400 hover:bg-zinc-100 dark:hover:bg-zinc-800 hover:text-zinc-900 dark:hover:text-zinc-100'
+            }`}
+            onClick={() => setActiveTab('security')}
+          >
+            Security
+          </button>
+          <button
+            className={`flex w-full items-center gap-2 rounded-md px-3 py-2 text-sm transition-colors ${
+              activeTab === 'backups'
+                ? 'bg-zinc-900 dark:bg-zinc-100 text-white dark:text-zinc-900'
+                : 'text-zinc-600 dark:text-zinc-400 hover:bg-zinc-100 dark:hover:bg-zinc-800 hover:text-zinc-900 dark:hover:text-zinc-100'
+            }`}
+            onClick={() => setActiveTab('backups')}
+          >
+            Backups
+          </button>
         </nav>
 
         {/* Content */}
@@ -345,6 +369,10 @@ export default function SettingsPage() {
           {activeTab === 'monitoring' && <MonitoringTab />}
 
           {activeTab === 'pricing' && <ModelPricingTab />}
+
+          {activeTab === 'security' && <SecurityTab />}
+
+          {activeTab === 'backups' && <BackupsTab />}
         </div>
       </div>
 
@@ -0,0 +1,162 @@
+/**
+ * The tool call audit log: every tool call made by chats, scheduled tasks and
+ * triggered runs, newest first, with its full input and the permission
+ * decision it got. The filters answer questions like "which agent touched
+ * prod.yaml last week".
+ */
+import { useCallback, useEffect, useState } from 'react'
+import { ScrollText } from 'lucide-react'
+
+import { toolCallsApi } from '@/lib/api'
+import { formatRelativeTime } from '@/lib/utils'
+import { Button } from '@/components/ui/button'
+import { Input } from '@/components/ui/input'
+import type { ToolCall, ToolCallFilter, ToolCallStatus } from '@/types'
+
+const STATUS_COLORS: Record<ToolCallStatus, string> = {
+  success: 'bg-green-50 text-green-700 dark:bg-green-900/30 dark:text-green-400',
+  error: 'bg-red-50 text-red-700 dark:bg-red-900/30 dark:text-red-400',
+  denied: 'bg-amber-50 text-amber-700 dark:bg-amber-900/30 dark:text-amber-400',
+  interrupted: 'bg-zinc-100 text-zinc-500 dark:bg-zinc-800 dark:text-zinc-400',
+}
+
+const SOURCE_LABELS: Record<ToolCall['source'], string> = {
+  chat: 'chat',
+  scheduled_task: 'scheduled task',
+  trigger: 'trigger',
+  mcp: 'MCP',
+}
+
+/** Indents a tool's JSON input for reading; anything else is shown as is. */
+function formatInput(raw: string): string {
+  try {
+    return JSON.stringify(JSON.parse(raw), null, 2)
+  } catch {
+    return raw
+  }
+}
+
+function ToolCallRow({ call }: Readonly<{ call: ToolCall }>) {
+  const [open, setOpen] = useState(false)
+  return (
+    <li className="rounded-lg border border-zinc-200 dark:border-zinc-700/60 px-4 py-3">
+      <button
+        type="button"
+        className="w-full flex items-start justify-between gap-3 text-left"
+        onClick={() => setOpen(o => !o)}
+      >
+        <div className="min-w-0">
+          <p className="text-sm font-mono text-zinc-900 dark:text-zinc-100 truncate">
+            {call.tool_name}
+          </p>
+          <p className="text-xs text-zinc-500 dark:text-zinc-400 mt-0.5">
+            {call.agent_slug || 'no agent'} · {SOURCE_LABELS[call.source]} · {call.decision} ·{' '}
+            {call.duration_ms} ms · {formatRelativeTime(call.started_at)}
+          </p>
+        </div>
+        <span
+          className={`shrink-0 inline-flex items-center rounded-full px-2 py-0.5 text-xs font-medium ${STATUS_COLORS[call.status]}`}
+        >
+          {call.status}
+        </span>
+      </button>
+      {call.decision_reason && (
+        <p className="mt-1 text-xs text-zinc-500 dark:text-zinc-400">{call.decision_reason}</p>
+      )}
+      {open && (
+        <pre className="mt-2 max-h-64 overflow-auto rounded-md bg-zinc-50 dark:bg-zinc-800 px-3 py-2 text-xs text-zinc-700 dark:text-zinc-300 whitespace-pre-wrap break-words">
+          {formatInput(call.input)}
+        </pre>
+      )}
+    </li>
+  )
+}
+
+export default function ToolCallsPage() {
+  const [draft, setDraft] = useState<ToolCallFilter>({})
+  const [filter, setFilter] = useState<ToolCallFilter>({})
+  const [calls, setCalls] = useState<ToolCall[]>([])
+  const [loading, setLoading] = useState(true)
+  const [error, setError] = useState<string | null>(null)
+
+  const load = useCallback(() => {
+    setLoading(true)
+    toolCallsApi
+      .list(filter)
+      .then(data => {
+        setCalls(data)
+        setError(null)
+      })
+      .catch(err => setError(err instanceof Error ? err.message : 'Failed to load tool calls'))
+      .finally(() => setLoading(false))
+  }, [filter])
+
+  useEffect(() => {
+    load()
+  }, [load])
+
+  const field = (key: keyof ToolCallFilter, placeholder: string, type = 'text') => (
+    <Input
+      type={type}
+      value={(draft[key] as string | undefined) ?? ''}
+      onChange={e => setDraft(prev => ({ ...prev, [key]: e.target.value }))}
+      placeholder={placeholder}
+      className="h-8 text-xs"
+    />
+  )
+
+  return (
+    <div className="flex flex-col h-full">
+      <div className="border-b border-zinc-100 dark:border-zinc-800 px-4 sm:px-6 py-4 shrink-0">
+        <h1 className="text-xl font-semibold text-zinc-900 dark:text-zinc-100">Tool Calls</h1>
+        <p className="text-xs text-zinc-500 dark:text-zinc-400 mt-0.5">
+          Every tool call agents made, newest first
+        </p>
+        <form
+          className="mt-3 grid grid-cols-2 sm:grid-cols-6 gap-2 max-w-4xl"
+          onSubmit={e => {
+            e.preventDefault()
+            setFilter({ ...draft })
+          }}
+        >
+          {field('agent', 'Agent slug')}
+          {field('tool', 'Tool, e.g. Edit')}
+          <div className="col-span-2">{field('q', 'Input contains, e.g. prod.yaml')}</div>
+          {field('since', 'Since', 'date')}
+          {field('until', 'Until', 'date')}
+          <Button type="submit" size="sm" className="h-8 text-xs col-span-2 sm:col-span-1">
+            Search
+          </Button>
+        </form>
+      </div>
+
+      {error && (
+        <div className="mx-6 mt-3 rounded-md border border-red-200 bg-red-50 dark:border-red-800 dark:bg-red-900/20 px-4 py-2.5 text-sm text-red-700 dark:text-red-400">
+          {error}
+        </div>
+      )}
+
+      <div className="flex-1 overflow-y-auto px-4 sm:px-6 py-4">
+        {!loading && calls.length === 0 ? (
+          <div className="flex flex-col items-center justify-center py-20 text-center">
+            <div className="flex h-12 w-12 items-center justify-center rounded-full bg-zinc-100 dark:bg-zinc-800 mb-4">
+              <ScrollText className="h-5 w-5 text-zinc-400 dark:text-zinc-500" />
+            </div>
+            <h2 className="text-lg font-semibold text-zinc-900 dark:text-zinc-100 mb-1">
+              No tool calls
+            </h2>
+            <p className="text-sm text-zinc-500 dark:text-zinc-400 max-w-sm">
+              Tool calls made in chats, scheduled tasks and triggered runs are logged here.
+            </p>
+          </div>
+        ) : (
+          <ul className="flex flex-col gap-3 max-w-4xl">
+            {calls.map(c => (
+              <ToolCallRow key={c.id} call={c} />
+            ))}
+          </ul>
+        )}
+      </div>
+    </div>
+  )
+}
@@ -0,0 +1,116 @@
+/**
+ * The transcript steps a sessions-list search matched.
+ *
+ * The list answers "which sessions"; this answers "where in them". Each hit
+ * links into the journey at the step it matched, so finding the turn where an
+ * error was first seen is one click rather than a scroll through every turn.
+ */
+import { useEffect, useState } from 'react'
+import { useNavigate } from 'react-router-dom'
+import { FileSearch } from 'lucide-react'
+
+import { claudeSessionsApi } from '@/lib/api'
+import { toQueryParams, type SessionFilters } from '@/lib/sessionQuery'
+import { formatRelativeTime } from '@/lib/utils'
+import type { TranscriptHit } from '@/types'
+
+/** Hits shown before "show more"; the panel sits above the list, not instead of it. */
+const PAGE_SIZE = 5
+
+const KIND_LABELS: Record<string, string> = {
+  user_input: 'prompt',
+  text_response: 'reply',
+  thinking: 'thinking',
+  tool_call: 'tool call',
+  tool_result: 'tool output',
+  sub_agent: 'sub-agent',
+}
+
+export function TranscriptMatches({ filters }: Readonly<{ filters: SessionFilters }>) {
+  const navigate = useNavigate()
+  const [hits, setHits] = useState<TranscriptHit[]>([])
+  const [hasMore, setHasMore] = useState(false)
+  const [offset, setOffset] = useState(0)
+
+  // The parent keys this component by its filters, so a new search mounts a
+  // fresh panel starting from the first page.
+  const text = filters.search.trim()
+
+  useEffect(() => {
+    if (!text) {
+      setHits([])
+      setHasMore(false)
+      return
+    }
+    let cancelled = false
+    claudeSessionsApi
+      .search(text, { filters: toQueryParams(filters), limit: PAGE_SIZE, offset })
+      .then(page => {
+        if (cancelled) return
+        setHits(prev => (offset === 0 ? page.hits : [...prev, ...page.hits]))
+        setHasMore(page.has_more)
+      })
+      .catch(() => {
+        // The list below still answers the search; the panel just stays empty.
+        if (!cancelled) setHits([])
+      })
+    return () => {
+      cancelled = true
+    }
+  }, [text, filters, offset])
+
+  if (hits.length === 0) return null
+
+  return (
+    <div className="border-b border-zinc-200 dark:border-zinc-700/60 px-4 py-3 shrink-0 max-h-72 overflow-y-auto">
+      <div className="flex items-center gap-1.5 mb-2 text-[11px] font-semibold uppercase tracking-[0.06em] text-zinc-500 dark:text-zinc-400">
+        <FileSearch className="h-3.5 w-3.5" />
+        In transcripts
+      </div>
+      <ul className="flex flex-col gap-1.5">
+        {hits.map((h, i) => (
+          <li key={`${h.session_id}-${h.turn}-${h.step}-${i}`}>
+            <button
+              onClick={() =>
+                navigate(`/claude-sessions/${h.session_id}/journey?turn=${h.turn}&step=${h.step}`)
+              }
+              className="w-full text-left rounded-md px-2 py-1.5 hover:bg-zinc-50 dark:hover:bg-zinc-800/60 transition-colors"
+            >
+              <div className="flex items-baseline gap-2 text-xs">
+                <span className="font-medium text-zinc-900 dark:text-zinc-100 truncate">
+                  {h.title || h.session_id}
+                </span>
+                <span className="text-zinc-400 dark:text-zinc-500 shrink-0">
+                  turn {h.turn} · {KIND_LABELS[h.kind] ?? h.kind} ·{' '}
+                  {formatRelativeTime(h.last_activity)}
+                </span>
+              </div>
+              <p className="text-xs text-zinc-600 dark:text-zinc-400 font-mono truncate">
+                {h.snippet.map((p, j) =>
+                  p.match ? (
+                    <mark
+                      key={j}
+                      className="bg-amber-100 dark:bg-amber-500/30 text-inherit rounded-sm px-0.5"
+                    >
+                      {p.text}
+                    </mark>
+                  ) : (
+                    <span key={j}>{p.text}</span>
+                  ),
+                )}
+              </p>
+            </button>
+          </li>
+        ))}
+      </ul>
+      {hasMore && (
+        <button
+          onClick={() => setOffset(hits.length)}
+          className="mt-1 px-2 text-xs text-zinc-500 hover:text-zinc-700 dark:hover:text-zinc-300"
+        >
+          Show more matches
+        </button>
+      )}
+    </div>
+  )
+}
@@ -0,0 +1,106 @@
+/**
+ * How much of each Claude account's 5-hour and weekly subscription usage
+ * windows is used, and when each resets.
+ *
+ * On a Pro or Max plan the constraint is these windows, not dollars. Anthropic
+ * publishes neither their size nor their boundaries, so the server estimates
+ * both from the transcripts; the tooltip on each meter says what the estimate
+ * rests on. The strip is hidden while no window is open.
+ */
+import { useEffect, useState } from 'react'
+
+import { claudeSessionsApi } from '@/lib/api'
+import { shortPath } from '@/lib/format'
+import { anyWindowOpen, timeUntil, usageTone, usageWindowSummary } from '@/lib/usageWindows'
+import type { UsageTone } from '@/lib/usageWindows'
+import type { UsageWindow, UsageWindowsReport } from '@/types'
+
+/** How often to re-check. A window moves by the minute at most. */
+const POLL_MS = 60_000
+
+const TONE_BAR: Record<UsageTone, string> = {
+  ok: 'bg-emerald-500',
+  warn: 'bg-amber-500',
+  limit: 'bg-red-500',
+  unknown: 'bg-zinc-400',
+}
+
+function WindowMeter({
+  label,
+  window: w,
+  warnPercent,
+}: Readonly<{ label: string; window: UsageWindow; warnPercent: number }>) {
+  const tone = usageTone(w, warnPercent)
+  return (
+    <div className="flex items-center gap-1.5 text-xs" title={usageWindowSummary(w)}>
+      <span className="text-zinc-500 dark:text-zinc-400">{label}</span>
+      {tone === 'unknown' ? (
+        <span className="text-zinc-600 dark:text-zinc-300">{w.active ? 'no limit' : 'idle'}</span>
+      ) : (
+        <>
+          <div
+            role="progressbar"
+            aria-label={`${label} usage`}
+            aria-valuenow={Math.round(w.used_percent)}
+            aria-valuemin={0}
+            aria-valuemax={100}
+            className="h-1.5 w-20 overflow-hidden rounded-full bg-zinc-100 dark:bg-zinc-800"
+          >
+            <div
+              className={`h-full ${TONE_BAR[tone]}`}
+              style={{ width: `${Math.min(100, w.used_percent)}%` }}
+            />
+          </div>
+          <span className="font-mono text-zinc-700 dark:text-zinc-300">
+            {Math.round(w.used_percent)}%
+          </span>
+        </>
+      )}
+      {w.active && <span className="text-zinc-400">resets in {timeUntil(w.resets_at)}</span>}
+    </div>
+  )
+}
+
+export function UsageWindowsStrip() {
+  const [report, setReport] = useState<UsageWindowsReport | null>(null)
+
+  useEffect(() => {
+    let cancelled = false
+    let timer: ReturnType<typeof setTimeout>
+
+    const poll = async () => {
+      try {
+        const next = await claudeSessionsApi.usageWindows()
+        if (!cancelled) setReport(next)
+      } catch {
+        // An affordance, not the feature: keep polling quietly.
+      }
+      if (!cancelled) timer = setTimeout(poll, POLL_MS)
+    }
+
+    void poll()
+    return () => {
+      cancelled = true
+      clearTimeout(timer)
+    }
+  }, [])
+
+  if (!report || !anyWindowOpen(report.meters)) return null
+
+  return (
+    <div className="flex flex-wrap items-center gap-x-6 gap-y-2 px-4 sm:px-6 py-2 border-b border-zinc-100 dark:border-zinc-700/50 shrink-0">
+      <span className="text-xs font-medium text-zinc-500 dark:text-zinc-400">Usage limits</span>
+      {report.meters.map(m => (
+        <div key={m.config_dir} className="flex flex-wrap items-center gap-4">
+          {report.meters.length > 1 && (
+            <span className="font-mono text-xs text-zinc-500 dark:text-zinc-400">
+              {shortPath(m.config_dir)}
+            </span>
+          )}
+          <WindowMeter label="5h" window={m.five_hour} warnPercent={report.warn_percent} />
+          <WindowMeter label="Week" window={m.weekly} warnPercent={report.warn_percent} />
+        </div>
+      ))}
+    </div>
+  )
+}
@@ -2,6 +2,33 @@ export interface AgentCapabilities {
   built_in?: string[]
   local?: string[]
   mcp?: Record<string, { tools: string[] }>
+  /** Tools a person must approve before each call; a trailing `*` matches a prefix. */
+  require_approval?: string[]
+  policy?: ToolPolicy
+}
+
+export type PolicyAction = 'allow' | 'deny' | 'ask'
+
+/** A rule on tool arguments. Every condition set must hold for it to match. */
+export interface PolicyRule {
+  /** Tool name; a trailing `*` matches a prefix. */
+  tool: string
+  action: PolicyAction
+  /** Regular expression on a Bash command. */
+  command?: string
+  /** Globs on the file or directory a file tool works on. */
+  paths?: string[]
+  /** WebFetch hosts; `*.example.com` matches subdomains. */
+  hosts?: string[]
+  /** Tool parameter → regular expression its value must match. */
+  params?: Record<string, string>
+  reason?: string
+}
+
+/** Ordered rules; the first match decides. A dry run only logs decisions. */
+export interface ToolPolicy {
+  dry_run?: boolean
+  rules: PolicyRule[]
 }
 
 export interface Agent {
@@ -73,6 +100,40 @@ export interface UserSettings {
    * are always indexed and need not be listed.
    */
   claude_config_dirs?: string[]
+
+  /**
+   * Other coding agents whose sessions are indexed alongside Claude Code's,
+   * from IMPORTABLE_AGENTS.
+   */
+  imported_agents?: string[]
+
+  /** Hours between automatic backups. 0 turns them off. */
+  backup_interval_hours?: number
+  /** Automatic backups kept. 0 means DEFAULT_BACKUP_KEEP. */
+  backup_keep?: number
+  /** Absolute directory backups are written to. Empty means ~/.agento/backups. */
+  backup_dir?: string
+  /** Keep integration credentials, still encrypted, in automatic backups. */
+  backup_include_credentials?: boolean
+
+  /** Stop keeping Agento's own copy of Claude Code transcripts. */
+  transcript_archive_disabled?: boolean
+  /**
+   * Days an archived session Claude Code has deleted is kept, from its last
+   * activity. 0 keeps it forever.
+   */
+  transcript_archive_retention_days?: number
+  /** Cap on the archive's size on disk, in MB. 0 means no cap. */
+  transcript_archive_max_mb?: number
+
+  /**
+   * What a Claude subscription's 5-hour and weekly usage windows allow, in
+   * API-equivalent dollars. 0 estimates each from the heaviest window on record.
+   */
+  usage_limit_five_hour_usd?: number
+  usage_limit_weekly_usd?: number
+  /** Share of a usage window used before Agento warns. 0 means DEFAULT_USAGE_WARN_PERCENT. */
+  usage_warn_percent?: number
 }
 
 /**
@@ -83,6 +144,33 @@ export const DEFAULT_IDLE_GAP_MINUTES = 10
 export const MIN_IDLE_GAP_MINUTES = 1
 export const MAX_IDLE_GAP_MINUTES = 240
 
+/**
+ * Coding agents whose sessions can be imported, mirroring the ImportedAgent
+ * constants in internal/config/settings.go.
+ */
+export const IMPORTABLE_AGENTS = ['codex', 'gemini', 'aider'] as const
+
+/** Display names for a session's coding_agent. */
+export const CODING_AGENT_LABELS: Record<string, string> = {
+  'claude-code': 'Claude Code',
+  codex: 'Codex CLI',
+  gemini: 'Gemini CLI',
+  aider: 'Aider',
+}
+
+/** Backup bounds, mirroring the Go constants in internal/config/settings.go. */
+export const DEFAULT_BACKUP_KEEP = 7
+export const MAX_BACKUP_KEEP = 365
+export const MAX_BACKUP_INTERVAL_HOURS = 720
+
+/** Transcript archive bounds, mirroring the Go constants in internal/config/settings.go. */
+export const MAX_TRANSCRIPT_ARCHIVE_RETENTION_DAYS = 3650
+export const MAX_TRANSCRIPT_ARCHIVE_MAX_MB = 1048576
+
+/** Usage window bounds, mirroring the Go constants in internal/config/settings.go. */
+export const DEFAULT_USAGE_WARN_PERCENT = 80
+export const MAX_USAGE_LIMIT_USD = 100000
+
 export interface ClaudeConfigDirsResponse {
   /** The resolved set the scanner walks, default first. */
   indexed: string[]
@@ -584,6 +672,8 @@ export interface ClaudeSessionSummary {
   project_path: string
   /** Claude config dir this session was indexed from — the account it ran under. */
   config_dir?: string
+  /** Agent the session was read from; absent means Claude Code. */
+  coding_agent?: string
   preview: string
   custom_title?: string
   /** Claude Code's own `/rename`, refreshed on every scan. */
@@ -699,6 +789,7 @@ export interface ClaudeSessionFacets {
   models: string[]
   permission_modes: string[]
   config_dirs: string[]
+  coding_agents: string[]
   has_favorites: boolean
   has_prs: boolean
 }
@@ -726,6 +817,75 @@ export interface ClaudeSessionStatus {
   last_scanned_at: string
 }
 
+/**
+ * A session Claude Code has open, as its hooks report it. Empty until
+ * `agento hook install` has registered the hooks.
+ */
+export interface LiveClaudeSession {
+  session_id: string
+  project_path: string
+  cwd?: string
+  /** Running: Claude is working on a turn. Waiting: the session waits on the user. */
+  state: 'running' | 'waiting'
+  /** The hook event last received, e.g. PostToolUse. */
+  last_event: string
+  last_tool?: string
+  started_at: string
+  updated_at: string
+}
+
+/**
+ * One config dir's current Claude subscription usage window, estimated from
+ * its transcripts. Usage is in API-equivalent dollars.
+ */
+export interface UsageWindow {
+  kind: 'five_hour' | 'weekly'
+  /** False when no window is open; the next message opens one. */
+  active: boolean
+  started_at: string
+  resets_at: string
+  messages: number
+  usage: ClaudeTokenUsage
+  cost_usd: number
+  /** 0 when no limit is set and too few windows have completed to learn one. */
+  limit_usd: number
+  limit_source: '' | 'configured' | 'learned'
+  completed_windows: number
+  used_percent: number
+  /** At the window's pace so far, how much is used by its reset. */
+  projected_percent: number
+  /** When that pace reaches the limit, if before the reset. */
+  limit_at?: string
+}
+
+export interface UsageMeter {
+  config_dir: string
+  five_hour: UsageWindow
+  weekly: UsageWindow
+}
+
+export interface UsageWindowsReport {
+  generated_at: string
+  warn_percent: number
+  meters: UsageMeter[]
+}
+
+/** What the transcript archive holds, and the disk it takes. */
+export interface TranscriptArchiveUsage {
+  /** False when copying is turned off. Copies already made are still counted. */
+  enabled: boolean
+  dir: string
+  /** Archived files, sub-agent transcripts included. */
+  transcripts: number
+  sessions: number
+  /** Sessions Claude Code has deleted that only the archive still holds. */
+  archived_only_sessions: number
+  /** The transcripts' own size, uncompressed. */
+  original_bytes: number
+  /** The archive's size on disk. */
+  stored_bytes: number
+}
+
 export interface ClaudeSessionPR {
   pr_number: number
   pr_url: string
@@ -813,6 +973,8 @@ export interface JourneyTurn {
   usage?: ClaudeTokenUsage
   tool_calls: number
   steps: JourneyStep[]
+  /** The turn's main-thread usage priced per message; 0 when unpriced. */
+  cost_usd: number
 }
 
 export interface JourneyStep {
@@ -837,6 +999,158 @@ export type JourneyStepType =
   | 'sub_agent'
   | 'compaction'
 
+// ── Live tail ────────────────────────────────────────────────────────────────
+
+/** A step appended to a session being tailed; `agent_id` marks a sub-agent's. */
+export interface JourneyTailStep {
+  turn: number
+  agent_id?: string
+  step: JourneyStep
+}
+
+/**
+ * A sub-agent transcript that appeared while tailing. With `tool_use_id` its
+ * steps nest under that tool_call; without, under a sub_agent step in `turn`.
+ */
+export interface JourneyTailSubagent {
+  turn: number
+  agent_id: string
+  agent_type?: string
+  description?: string
+  tool_use_id?: string
+}
+
+/** The tailed session's running totals; `cost_usd` includes its sub-agents. */
+export interface JourneyTailUsage {
+  model?: string
+  cwd?: string
+  git_branch?: string
+  summary?: string
+  start_time: string
+  end_time: string
+  active_duration_ms: number
+  total_turns: number
+  usage: ClaudeTokenUsage
+  subagent_usage: ClaudeTokenUsage
+  subagent_count: number
+  cost_usd: number
+}
+
+// ── Transcript search ────────────────────────────────────────────────────────
+
+export interface TranscriptSearchPage {
+  /** Best match first. */
+  hits: TranscriptHit[]
+  has_more: boolean
+}
+
+/** One matching step; `turn` and `step` address it in the session's journey. */
+export interface TranscriptHit {
+  session_id: string
+  title: string
+  project_path: string
+  last_activity: string
+  turn: number
+  /** Index into the turn's steps. A sub-agent's hit points at its Task call. */
+  step: number
+  kind: JourneyStepType
+  snippet: SnippetPart[]
+}
+
+export interface SnippetPart {
+  text: string
+  /** True for the runs that matched the query. */
+  match?: boolean
+}
+
+// ── Session comparison ───────────────────────────────────────────────────────
+
+/** Every delta is right minus left. */
+export interface SessionComparison {
+  left: ComparedSession
+  right: ComparedSession
+  /** The first step the runs disagreed on; null when they match step for step. */
+  divergence: SessionDivergence | null
+  turns: TurnComparison[]
+  tool_sequence: ToolSequenceEntry[]
+  /** Set when the middle of the sequences was too long to align call by call. */
+  tool_sequence_coarse?: boolean
+  tool_counts: ToolCountDelta[]
+  errors: {
+    left_only: ComparedToolError[]
+    right_only: ComparedToolError[]
+    shared: number
+  }
+  /** Session-insight metrics; empty until both sessions have insights. */
+  metrics: { name: string; left: number; right: number; delta: number }[]
+}
+
+export interface ComparedSession {
+  session_id: string
+  model?: string
+  cwd?: string
+  git_branch?: string
+  summary?: string
+  start_time: string
+  active_duration_ms: number
+  turns: number
+  tool_calls: number
+  tool_errors: number
+  subagent_count: number
+  usage: ClaudeTokenUsage
+  cost_usd: number
+}
+
+export interface SessionDivergence {
+  turn: number
+  /** Index into the turn's steps; -1 when that run had no such step. */
+  left_step: number
+  right_step: number
+  reason: 'prompt' | 'tool' | 'outcome' | 'compaction' | 'length'
+  left: string
+  right: string
+}
+
+export interface TurnComparison {
+  number: number
+  left: ComparedTurn | null
+  right: ComparedTurn | null
+  usage_delta: ClaudeTokenUsage
+  cost_delta_usd: number
+  duration_delta_ms: number
+  tool_calls_delta: number
+  same_tools: boolean
+}
+
+export interface ComparedTurn {
+  prompt?: string
+  usage: ClaudeTokenUsage
+  cost_usd: number
+  duration_ms: number
+  tools: string[]
+  errors: number
+}
+
+export interface ToolSequenceEntry {
+  op: 'same' | 'left_only' | 'right_only'
+  tool: string
+  left_turn?: number
+  right_turn?: number
+}
+
+export interface ToolCountDelta {
+  tool: string
+  left: number
+  right: number
+  delta: number
+}
+
+export interface ComparedToolError {
+  turn: number
+  tool: string
+  message: string
+}
+
 // ── Notifications ─────────────────────────────────────────────────────────────
 
 export interface SMTPConfig {
@@ -858,10 +1172,23 @@ export interface NotificationPreferences {
   scheduled_tasks?: ScheduledTasksPreferences
 }
 
+export type ApprovalChannel = '' | 'telegram' | 'slack' | 'email'
+
+/** Where approval requests from scheduled and triggered runs are sent. */
+export interface ApprovalSettings {
+  channel?: ApprovalChannel
+  integration_id?: string
+  /** Telegram chat ID or Slack channel ID. Email goes to the SMTP recipients. */
+  target?: string
+  /** Minutes a run waits for an answer; 0 or unset means 30. */
+  timeout_minutes?: number
+}
+
 export interface NotificationSettings {
   enabled: boolean
   provider: SMTPConfig
   preferences?: NotificationPreferences
+  approvals?: ApprovalSettings
 }
 
 export interface NotificationLogEntry {
@@ -931,6 +1258,121 @@ export interface JobHistoryEntry {
   total_cache_creation_tokens: number
   total_cache_read_tokens: number
   response_text: string
+  /** Approval requests the run made; only set on a single job fetched by ID. */
+  approvals?: ApprovalRequest[]
+}
+
+// ── Approvals ─────────────────────────────────────────────────────────────────
+
+export type ApprovalStatus = 'pending' | 'approved' | 'denied' | 'expired'
+
+/** A gated tool call of an unattended run, and the answer it got. */
+export interface ApprovalRequest {
+  id: string
+  source: 'chat' | 'scheduled_task' | 'trigger' | 'mcp'
+  agent_slug: string
+  task_id?: string
+  job_id?: string
+  trigger_rule_id?: string
+  chat_session_id?: string
+  title: string
+  tool_name: string
+  /** The tool's input, as JSON. */
+  tool_input: string
+  channel: ApprovalChannel
+  status: ApprovalStatus
+  /** Who answered and where, e.g. `telegram:@alice`, `slack:U123` or `web`. */
+  decided_by?: string
+  created_at: string
+  expires_at: string
+  decided_at?: string
+}
+
+// ── Tool call audit log ───────────────────────────────────────────────────────
+
+export type ToolCallStatus = 'success' | 'error' | 'denied' | 'interrupted'
+
+/** One entry of the tool call audit log. */
+export interface ToolCall {
+  id: number
+  tool_use_id: string
+  source: 'chat' | 'scheduled_task' | 'trigger' | 'mcp'
+  agent_slug: string
+  task_id?: string
+  job_id?: string
+  trigger_rule_id?: string
+  chat_session_id?: string
+  tool_name: string
+  /** The call's full input, as JSON. */
+  input: string
+  status: ToolCallStatus
+  /** `auto` when claude ran the call without asking. */
+  decision: 'auto' | 'allow' | 'deny'
+  decision_reason?: string
+  started_at: string
+  duration_ms: number
+}
+
+/** Filters of the tool call list; empty fields do not filter. */
+export interface ToolCallFilter {
+  agent?: string
+  source?: string
+  tool?: string
+  status?: ToolCallStatus
+  /** Text the call's input contains, e.g. a file name. */
+  q?: string
+  /** YYYY-MM-DD or RFC3339. */
+  since?: string
+  until?: string
+  limit?: number
+}
+
+// ── Authentication ────────────────────────────────────────────────────────────
+
+export type ApiTokenScope = 'read' | 'run' | 'admin' | 'ingest'
+
+/** Whether authentication is on and how this browser signed in. */
+export interface AuthStatus {
+  /** True once an admin password is set with `agento auth set-password`. */
+  enabled: boolean
+  kind?: 'session' | 'token'
+  scope?: ApiTokenScope
+  token_name?: string
+}
+
+/** A personal API token. Its secret is only ever returned by `create`. */
+export interface ApiToken {
+  id: string
+  name: string
+  scope: ApiTokenScope
+  /** The start of the secret, enough to tell tokens apart. */
+  prefix: string
+  created_at: string
+  last_used_at?: string
+  expires_at?: string
+}
+
+export interface ApiTokenInput {
+  name: string
+  scope: ApiTokenScope
+  /** 0 never expires. */
+  expires_in_days: number
+}
+
+export interface CreatedApiToken extends ApiToken {
+  token: string
+}
+
+// ── Backups ───────────────────────────────────────────────────────────────────
+
+/** A backup archive in the backup directory. */
+export interface BackupArchive {
+  name: string
+  path: string
+  size: number
+  created_at: string
+  /** Made by the schedule; only these are deleted by retention. */
+  automatic: boolean
 }
 
 // ── Version / update check ────────────────────────────────────────────────────
@@ -1212,7 +1654,14 @@ export interface ModelCostStat {
  * relevant to a `kind` are absent.
  */
 export interface InsightCard {
-  kind: 'cache_savings' | 'model_low_cache' | 'delegation_mix' | 'expensive_sessions'
+  kind:
+    | 'cache_savings'
+    | 'model_low_cache'
+    | 'delegation_mix'
+    | 'expensive_sessions'
+    | 'spend_forecast'
+    | 'day_anomaly'
+    | 'session_anomaly'
   amount_usd?: number
   /** A share, 0–100. */
   percent?: number
@@ -1224,6 +1673,53 @@ export interface InsightCard {
   comparison_usd?: number
   /** Derived from list rates rather than a stored total — say "about". */
   estimated?: boolean
+  /** What an anomaly measured: cost (amount_usd vs comparison_usd) or tokens. */
+  metric?: 'cost' | 'tokens'
+  /** An anomaly's figure as a multiple of its baseline. */
+  ratio?: number
+  /** The day (YYYY-MM-DD) a card is about, or the forecast month (YYYY-MM). */
+  date?: string
+  session_id?: string
+}
+
+/** One model's or project's share of a spend forecast. */
+export interface ForecastLine {
+  /** The model id or project path. */
+  name: string
+  month_to_date_usd: number
+  daily_rate_usd: number
+  projected_usd: number
+}
+
+/**
+ * Month-end spend for the month the window ends in: month to date plus a
+ * trailing 14-day run rate over the days left.
+ */
+export interface SpendForecast {
+  /** YYYY-MM. */
+  month: string
+  month_to_date_usd: number
+  daily_rate_usd: number
+  projected_usd: number
+  /** Zero for a window ending in a past month. */
+  days_remaining: number
+  by_model: ForecastLine[]
+  by_project: ForecastLine[]
+}
+
+/** A day or session whose cost or tokens sat far above its rolling baseline. */
+export interface UsageAnomaly {
+  scope: 'day' | 'session'
+  metric: 'cost' | 'tokens'
+  date: string
+  session_id?: string
+  project?: string
+  at: string
+  value: number
+  /** The baseline median, in the metric's unit. */
+  baseline: number
+  /** Robust standard deviations above the baseline. */
+  score: number
 }
 
 /** One project's activity over the window. */
@@ -1346,6 +1842,8 @@ export interface AnalyticsReport {
   cost_over_time: CostPoint[]
   cost_summary: CostSummary
   projects: string[]
+  forecast: SpendForecast
+  anomalies: UsageAnomaly[]
 }
 
 // ── Inbound Triggers ──────────────────────────────────────────────────────────
@@ -1359,12 +1857,22 @@ export interface TriggerRule {
   filter_prefix: string
   filter_keywords: string[]
   filter_chat_ids: string[]
+  /**
+   * Telegram rules only. 'chat' resumes one agent session per chat, 'thread'
+   * one per reply thread or forum topic; absent runs every message afresh.
+   */
+  conversation?: TriggerConversation
+  /** Minutes without a message before a conversation ends; 0 means 60. */
+  conversation_idle_minutes?: number
   created_at: string
   updated_at: string
 }
 
+export type TriggerConversation = 'chat' | 'thread'
+
 export interface WebhookStatus {
-  status: 'active' | 'inactive' | 'error'
+  /** 'polling' while Agento fetches updates itself because no public URL is set. */
+  status: 'active' | 'inactive' | 'error' | 'polling'
   url: string
   has_secret: boolean
   error: string
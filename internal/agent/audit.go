@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
+)
+
+// Tool call status constants: how a call ended.
+const (
+	ToolCallSucceeded = "success"
+	ToolCallFailed    = "error"
+	// ToolCallInterrupted means the run ended before the call returned.
+	ToolCallInterrupted = "interrupted"
+)
+
+// ToolAuditor records the tool calls of a single run in the audit log. The
+// runner reports the permission decision for every call claude asks about
+// and, once a call has returned or the run has ended, the call itself.
+//
+// Like BudgetGuard, the interface lives here so the runner can feed the log
+// without importing storage.
+type ToolAuditor interface {
+	// Decide reports the permission decision for a call. Calls claude runs
+	// without asking are never decided.
+	Decide(d PermissionDecision)
+
+	// Record reports a finished call.
+	Record(ctx context.Context, call ToolCall)
+}
+
+// PermissionDecision is the answer the permission handler gave a call.
+type PermissionDecision struct {
+	// ToolUseID identifies the call. Older CLIs leave it empty, in which case
+	// the call is told apart by its tool name and input.
+	ToolUseID string
+	ToolName  string
+	Input     json.RawMessage
+	// Behavior is "allow" or "deny".
+	Behavior string
+	// Message is why a call was denied.
+	Message string
+}
+
+// ToolCall is one tool call as the run's stream reported it.
+type ToolCall struct {
+	ToolUseID string
+	ToolName  string
+	Input     json.RawMessage
+	// Status is ToolCallSucceeded, ToolCallFailed or ToolCallInterrupted.
+	Status    string
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// auditPermissions wraps handler so that every decision it makes is reported
+// to auditor.
+func auditPermissions(handler claude.PermissionHandler, auditor ToolAuditor) claude.PermissionHandler {
+	return func(toolName string, input json.RawMessage, ctx claude.PermissionContext) claude.PermissionResult {
+		res := handler(toolName, input, ctx)
+		auditor.Decide(PermissionDecision{
+			ToolUseID: ctx.ToolUseID,
+			ToolName:  toolName,
+			Input:     input,
+			Behavior:  res.Behavior,
+			Message:   res.Message,
+		})
+		return res
+	}
+}
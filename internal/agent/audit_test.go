@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+type recordingAuditor struct {
+	decisions []PermissionDecision
+	calls     []ToolCall
+}
+
+func (a *recordingAuditor) Decide(d PermissionDecision) { a.decisions = append(a.decisions, d) }
+
+func (a *recordingAuditor) Record(_ context.Context, call ToolCall) { a.calls = append(a.calls, call) }
+
+func TestToolSpans_Audit(t *testing.T) {
+	ctx := context.Background()
+	runSpan := trace.SpanFromContext(ctx)
+	spans := make(map[string]ToolSpanEntry)
+	auditor := &recordingAuditor{}
+
+	OpenToolSpans(ctx, runSpan, json.RawMessage(`{"type":"assistant","message":{"content":[
+		{"type":"tool_use","id":"tu-1","name":"Read","input":{"file_path":"/srv/prod.yaml"}},
+		{"type":"tool_use","id":"tu-2","name":"Bash","input":{"command":"false"}},
+		{"type":"tool_use","id":"tu-3","name":"Bash","input":{"command":"sleep 600"}}
+	]}}`), spans)
+	CloseToolSpans(ctx, json.RawMessage(`{"type":"user","message":{"content":[
+		{"type":"tool_result","tool_use_id":"tu-1","content":"apiVersion: v1"},
+		{"type":"tool_result","tool_use_id":"tu-2","content":"exit 1","is_error":true}
+	]}}`), spans, auditor)
+	FlushToolSpans(ctx, spans, auditor)
+
+	if len(auditor.calls) != 3 {
+		t.Fatalf("expected 3 recorded calls, got %d", len(auditor.calls))
+	}
+	want := map[string]string{"tu-1": ToolCallSucceeded, "tu-2": ToolCallFailed, "tu-3": ToolCallInterrupted}
+	for _, call := range auditor.calls {
+		if call.Status != want[call.ToolUseID] {
+			t.Errorf("%s: expected status %q, got %q", call.ToolUseID, want[call.ToolUseID], call.Status)
+		}
+		if call.StartedAt.IsZero() {
+			t.Errorf("%s: start time not recorded", call.ToolUseID)
+		}
+	}
+	if got := string(auditor.calls[0].Input); got != `{"file_path":"/srv/prod.yaml"}` {
+		t.Errorf("expected the full input, got %s", got)
+	}
+	if len(spans) != 0 {
+		t.Errorf("expected every span to be closed, %d left", len(spans))
+	}
+
+	// Without an auditor the spans are closed all the same.
+	OpenToolSpans(ctx, runSpan, json.RawMessage(`{"message":{"content":[{"type":"tool_use","id":"tu-4","name":"Glob"}]}}`), spans)
+	FlushToolSpans(ctx, spans, nil)
+	if len(spans) != 0 {
+		t.Error("expected the span to be closed without an auditor")
+	}
+}
+
+func TestBuildSDKOptions_AuditsPermissionDecisions(t *testing.T) {
+	auditor := &recordingAuditor{}
+	cfg := &config.AgentConfig{Capabilities: config.AgentCapabilities{BuiltIn: []string{"Read"}}}
+	inner := func(string, json.RawMessage, claude.PermissionContext) claude.PermissionResult {
+		return claude.PermissionResult{Behavior: "allow"}
+	}
+
+	o := applyOpts(buildSDKOptions(context.Background(), cfg, RunOptions{PermissionHandler: inner, Audit: auditor}, ""))
+	if o.PermissionHandler == nil {
+		t.Fatal("expected a permission handler")
+	}
+	o.PermissionHandler("Bash", json.RawMessage(`{"command":"ls"}`), claude.PermissionContext{ToolUseID: "tu-9"})
+
+	if len(auditor.decisions) != 1 {
+		t.Fatalf("expected 1 decision, got %d", len(auditor.decisions))
+	}
+	d := auditor.decisions[0]
+	if d.ToolUseID != "tu-9" || d.ToolName != "Bash" || d.Behavior != "deny" || d.Message == "" {
+		t.Errorf("expected the denial of tu-9 to be reported, got %+v", d)
+	}
+}
@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
+
+	"github.com/shaharia-lab/agento/internal/pricing"
+)
+
+// BudgetGuard enforces spending caps around a single agent run. The runner
+// calls Admit before the subprocess starts, Observe for every assistant
+// message while the run streams, and Settle once with the final cost.
+//
+// The interface lives here rather than in the package that implements it so
+// the runner can refuse and abort runs without importing storage.
+type BudgetGuard interface {
+	// Admit returns a *BudgetExceededError when a cap this run falls under is
+	// already spent. Any other error also refuses the run.
+	Admit(ctx context.Context) error
+
+	// Observe reports the usage of one assistant message. The CLI re-emits a
+	// message as its content blocks arrive, so the same messageID may be seen
+	// more than once and the latest usage replaces the earlier one. A non-nil
+	// return aborts the run.
+	Observe(messageID, model string, usage pricing.Usage) error
+
+	// Settle charges the run against the ledger. reportedCostUSD is the CLI's
+	// own figure; it is zero when the run produced no result, in which case
+	// the guard charges its running estimate instead.
+	Settle(ctx context.Context, reportedCostUSD float64)
+}
+
+// BudgetExceededError is returned when a run is refused or aborted because a
+// budget cap would be exceeded.
+type BudgetExceededError struct {
+	PolicyID   string
+	PolicyName string
+	Scope      string
+	ScopeID    string
+	Period     string
+	LimitUSD   float64
+	SpentUSD   float64
+}
+
+func (e *BudgetExceededError) Error() string {
+	name := e.PolicyName
+	if name == "" {
+		name = e.Scope
+		if e.ScopeID != "" {
+			name += " " + e.ScopeID
+		}
+	}
+	return fmt.Sprintf("budget exceeded: %s %s limit $%.2f, spent $%.2f",
+		name, e.Period, e.LimitUSD, e.SpentUSD)
+}
+
+// ObserveBudget forwards the usage carried by an assistant event to guard.
+// Events of any other type, and assistant events without usage, are ignored.
+// RunAgent calls it for every event; callers of StreamAgent that set
+// RunOptions.Budget call it from their own event loop, since they own the
+// stream.
+func ObserveBudget(guard BudgetGuard, event claude.Event) error {
+	if guard == nil || event.Type != claude.TypeAssistant {
+		return nil
+	}
+	id, model, usage, ok := parseAssistantUsage(event.Raw)
+	if !ok {
+		return nil
+	}
+	return guard.Observe(id, model, usage)
+}
+
+// parseAssistantUsage extracts the message ID, model and token usage from a
+// raw assistant event. The SDK's typed message does not carry the cache
+// creation split by TTL, which prices differently, so the raw JSON is read.
+func parseAssistantUsage(raw json.RawMessage) (id, model string, usage pricing.Usage, ok bool) {
+	var msg struct {
+		Message struct {
+			ID    string `json:"id"`
+			Model string `json:"model"`
+			Usage *struct {
+				InputTokens              int `json:"input_tokens"`
+				OutputTokens             int `json:"output_tokens"`
+				CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+				CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+				CacheCreation            *struct {
+					Ephemeral5m int `json:"ephemeral_5m_input_tokens"`
+					Ephemeral1h int `json:"ephemeral_1h_input_tokens"`
+				} `json:"cache_creation"`
+			} `json:"usage"`
+		} `json:"message"`
+	}
+	if json.Unmarshal(raw, &msg) != nil || msg.Message.Usage == nil || msg.Message.ID == "" {
+		return "", "", pricing.Usage{}, false
+	}
+	u := msg.Message.Usage
+	usage = pricing.Usage{
+		InputTokens:     u.InputTokens,
+		OutputTokens:    u.OutputTokens,
+		CacheReadTokens: u.CacheReadInputTokens,
+	}
+	if u.CacheCreation != nil {
+		usage.CacheCreation5mTokens = u.CacheCreation.Ephemeral5m
+		usage.CacheCreation1hTokens = u.CacheCreation.Ephemeral1h
+	} else {
+		// Older CLIs report only the total; bill it at the 5-minute rate,
+		// which is the default TTL.
+		usage.CacheCreation5mTokens = u.CacheCreationInputTokens
+	}
+	return msg.Message.ID, msg.Message.Model, usage, true
+}
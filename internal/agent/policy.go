@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/policy"
+)
+
+// policyUnanswerable is the message a call the tool policy asks about is
+// denied with when there is nobody to ask.
+const policyUnanswerable = "this agent's tool policy requires approval for this call, but nobody can give it here"
+
+// agentHasPolicy reports whether agentCfg declares tool policy rules.
+func agentHasPolicy(agentCfg *config.AgentConfig) bool {
+	return agentCfg != nil && agentCfg.Capabilities.HasPolicy()
+}
+
+// policyNamesTool reports whether any of the agent's policy rules applies to
+// toolName.
+func policyNamesTool(agentCfg *config.AgentConfig, toolName string) bool {
+	if !agentHasPolicy(agentCfg) {
+		return false
+	}
+	for _, r := range agentCfg.Capabilities.Policy.Rules {
+		if config.MatchToolName(r.Tool, toolName) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyEnforcer returns the function that evaluates a call against the
+// agent's tool policy and logs the decision. It returns the decision to
+// enforce, which is empty when no rule matched or the policy is a dry run.
+// A policy that does not compile denies every call: running the agent
+// without the restrictions it declares would be worse.
+func policyEnforcer(agentCfg *config.AgentConfig) func(toolName string, input json.RawMessage) policy.Decision {
+	none := policy.Decision{Rule: -1}
+	if !agentHasPolicy(agentCfg) {
+		return func(string, json.RawMessage) policy.Decision { return none }
+	}
+
+	slug := agentCfg.Slug
+	pol, err := policy.Compile(agentCfg.Capabilities.Policy)
+	if err != nil {
+		slog.Error("invalid tool policy, denying every tool call", "agent", slug, "error", err)
+		invalid := policy.Decision{
+			Action: config.PolicyDeny,
+			Rule:   -1,
+			Reason: fmt.Sprintf("the agent's tool policy is invalid (%v)", err),
+		}
+		return func(string, json.RawMessage) policy.Decision { return invalid }
+	}
+
+	return func(toolName string, input json.RawMessage) policy.Decision {
+		d := pol.Evaluate(toolName, input)
+		if !d.Matched() {
+			slog.Debug("tool policy: no rule matched", "agent", slug, "tool", toolName)
+			return none
+		}
+		attrs := []any{"agent", slug, "tool", toolName, "rule", d.Rule + 1, "reason", d.Reason}
+		if pol.DryRun() {
+			slog.Info("tool policy dry run: not enforced", append(attrs, "would", d.Action)...)
+			return none
+		}
+		slog.Info("tool policy decision", append(attrs, "action", d.Action)...)
+		return d
+	}
+}
+
+// policyDenial is the message a call denied by the tool policy is refused
+// with.
+func policyDenial(toolName string, d policy.Decision) string {
+	msg := fmt.Sprintf("this agent's tool policy denies this call to %s", toolName)
+	if d.Reason != "" {
+		msg += ": " + d.Reason
+	}
+	return msg
+}
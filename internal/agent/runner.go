@@ -70,6 +70,16 @@ type RunOptions struct {
 	// discovers project-level skills from .claude/skills/ and loads
 	// project CLAUDE.md files.
 	WorkingDir string
+
+	// Budget, when set, enforces spending caps: the run is refused before it
+	// starts when a cap is already spent, and aborted once its running cost
+	// would take a cap over. Nil means the run is not budgeted.
+	Budget BudgetGuard
+
+	// Audit, when set, records every tool call of the run in the audit log.
+	// Callers of StreamAgent and StartSession, which own the stream, report
+	// the calls themselves through CloseToolSpans and FlushToolSpans.
+	Audit ToolAuditor
 }
 
 // AgentResult is the final result of an agent invocation.
@@ -169,8 +179,11 @@ func buildSDKOptions(
 	allowedTools, mcpServers := resolveToolsAndMCP(ctx, agentCfg, opts)
 	sdkOpts = appendToolOpts(sdkOpts, agentCfg, allowedTools, mcpServers)
 
-	if opts.PermissionHandler != nil {
-		handler := wrapPermissionHandler(opts.PermissionHandler, allowedTools)
+	if opts.PermissionHandler != nil || agentHasPolicy(agentCfg) {
+		handler := wrapPermissionHandler(opts.PermissionHandler, allowedTools, agentCfg)
+		if opts.Audit != nil {
+			handler = auditPermissions(handler, opts.Audit)
+		}
 		sdkOpts = append(sdkOpts, claude.WithPermissionHandler(handler))
 	}
 
@@ -217,8 +230,9 @@ func appendPermissionOpts(sdkOpts []claude.Option, opts RunOptions, agentCfg *co
 	// approve/deny prompts), we use WithDefaultPermissions so the handler
 	// receives each tool call. This takes precedence over the agent's
 	// configured permission_mode, which means "plan" and "dontAsk" agents
-	// will still behave as "default" when used through the chat UI.
-	if opts.PermissionHandler != nil {
+	// will still behave as "default" when used through the chat UI. A tool
+	// policy needs the same, since it is enforced by the handler.
+	if opts.PermissionHandler != nil || agentHasPolicy(agentCfg) {
 		return append(sdkOpts, claude.WithDefaultPermissions())
 	}
 
@@ -352,8 +366,8 @@ func appendToolOpts(
 	sdkOpts []claude.Option, agentCfg *config.AgentConfig,
 	allowedTools []string, mcpServers map[string]any,
 ) []claude.Option {
-	if len(allowedTools) > 0 {
-		sdkOpts = append(sdkOpts, claude.WithAllowedTools(allowedTools...))
+	if preApproved := withoutGatedTools(allowedTools, agentCfg); len(preApproved) > 0 {
+		sdkOpts = append(sdkOpts, claude.WithAllowedTools(preApproved...))
 	}
 
 	sdkOpts = appendDisallowedTools(sdkOpts, agentCfg)
@@ -366,6 +380,23 @@ func appendToolOpts(
 	return sdkOpts
 }
 
+// withoutGatedTools drops the tools that need approval, or that a tool
+// policy rule names, from allowedTools. claude runs a tool on its allowed
+// list without asking, so a gated tool must stay off it for its calls to
+// reach the permission handler.
+func withoutGatedTools(allowedTools []string, agentCfg *config.AgentConfig) []string {
+	if agentCfg == nil || (len(agentCfg.Capabilities.RequireApproval) == 0 && !agentHasPolicy(agentCfg)) {
+		return allowedTools
+	}
+	preApproved := make([]string, 0, len(allowedTools))
+	for _, t := range allowedTools {
+		if !agentCfg.Capabilities.RequiresApproval(t) && !policyNamesTool(agentCfg, t) {
+			preApproved = append(preApproved, t)
+		}
+	}
+	return preApproved
+}
+
 // appendDisallowedTools computes and appends the disallowed built-in tools.
 func appendDisallowedTools(sdkOpts []claude.Option, agentCfg *config.AgentConfig) []claude.Option {
 	if agentCfg == nil || len(agentCfg.Capabilities.BuiltIn) == 0 {
@@ -388,35 +419,75 @@ func appendDisallowedTools(sdkOpts []claude.Option, agentCfg *config.AgentConfig
 }
 
 // wrapPermissionHandler returns a PermissionHandler that enforces the allowed
-// tools list before delegating to inner. AskUserQuestion is always allowed
-// (it is a special interactive tool, not an external capability).
-// When allowedTools is empty (no-agent direct chat) the inner handler is
-// returned unwrapped so that all tools are reachable.
-func wrapPermissionHandler(inner claude.PermissionHandler, allowedTools []string) claude.PermissionHandler {
-	if len(allowedTools) == 0 {
+// tools list and the agent's tool policy before delegating to inner.
+// AskUserQuestion is always delegated (it is a special interactive tool, not
+// an external capability), as is a call the policy asks about. Calls the
+// policy does not decide behave as they would without one: tools claude runs
+// unprompted are allowed and the rest are delegated. A nil inner allows what
+// would be delegated, except asks, which it denies since nobody can answer.
+// When allowedTools is empty (no-agent direct chat) and there is no policy
+// the inner handler is returned unwrapped so that all tools are reachable.
+func wrapPermissionHandler(
+	inner claude.PermissionHandler, allowedTools []string, agentCfg *config.AgentConfig,
+) claude.PermissionHandler {
+	if len(allowedTools) == 0 && !agentHasPolicy(agentCfg) {
 		return inner
 	}
 	set := make(map[string]struct{}, len(allowedTools))
 	for _, t := range allowedTools {
 		set[t] = struct{}{}
 	}
+	// Only pre-approved tools are unprompted; gated ones were kept off the
+	// list claude is given.
+	unprompted := func(toolName string) bool {
+		_, ok := set[toolName]
+		return ok && !agentCfg.Capabilities.RequiresApproval(toolName)
+	}
+	enforce := policyEnforcer(agentCfg)
+
 	return func(toolName string, input json.RawMessage, ctx claude.PermissionContext) claude.PermissionResult {
 		// AskUserQuestion is always permitted — it drives the multi-turn Q&A flow.
 		if toolName == "AskUserQuestion" {
-			return inner(toolName, input, ctx)
+			return delegatePermission(inner, toolName, input, ctx)
 		}
-		if _, ok := set[toolName]; !ok {
+		if _, ok := set[toolName]; !ok && len(set) > 0 {
 			return claude.PermissionResult{
 				Behavior: "deny",
 				Message:  fmt.Sprintf("tool %q is not in this agent's allowed capabilities", toolName),
 			}
 		}
-		return inner(toolName, input, ctx)
+		switch d := enforce(toolName, input); d.Action {
+		case config.PolicyAllow:
+			return claude.PermissionResult{Behavior: "allow"}
+		case config.PolicyDeny:
+			return claude.PermissionResult{Behavior: "deny", Message: policyDenial(toolName, d)}
+		case config.PolicyAsk:
+			if inner == nil {
+				return claude.PermissionResult{Behavior: "deny", Message: policyUnanswerable}
+			}
+			return inner(toolName, input, ctx)
+		}
+		if unprompted(toolName) {
+			return claude.PermissionResult{Behavior: "allow"}
+		}
+		return delegatePermission(inner, toolName, input, ctx)
 	}
 }
 
+// delegatePermission passes a call to inner, or allows it when there is none.
+func delegatePermission(
+	inner claude.PermissionHandler, toolName string, input json.RawMessage, ctx claude.PermissionContext,
+) claude.PermissionResult {
+	if inner == nil {
+		return claude.PermissionResult{Behavior: "allow"}
+	}
+	return inner(toolName, input, ctx)
+}
+
 // StreamAgent starts a streaming agent invocation and returns the *claude.Stream.
-// The caller is responsible for consuming events from stream.Events().
+// The caller is responsible for consuming events from stream.Events(). When
+// opts.Budget is set the run is admitted here, and the caller passes each
+// event to ObserveBudget and calls Settle when the stream ends.
 func StreamAgent(
 	ctx context.Context, agentCfg *config.AgentConfig, question string, opts RunOptions,
 ) (*claude.Stream, error) {
@@ -425,6 +496,12 @@ func StreamAgent(
 		return nil, err
 	}
 
+	if opts.Budget != nil {
+		if err := opts.Budget.Admit(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	sdkOpts := buildSDKOptions(ctx, agentCfg, opts, systemPrompt)
 	return claude.Query(ctx, question, sdkOpts...)
 }
@@ -489,16 +566,37 @@ func RunAgent(
 		return nil, err
 	}
 
-	sdkOpts := buildSDKOptions(ctx, agentCfg, opts, systemPrompt)
+	if opts.Budget != nil {
+		if err := opts.Budget.Admit(ctx); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+	}
 
-	stream, err := claude.Query(ctx, question, sdkOpts...)
+	// Cancelling runCtx is how a budget abort stops the subprocess; the
+	// caller's ctx stays live so the span and the ledger write still land.
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sdkOpts := buildSDKOptions(runCtx, agentCfg, opts, systemPrompt)
+
+	stream, err := claude.Query(runCtx, question, sdkOpts...)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("starting agent: %w", err)
 	}
 
-	result, err := collectRunResult(ctx, stream, span)
+	result, err := collectRunResult(ctx, stream, span, opts, cancel)
+	if opts.Budget != nil {
+		reported := 0.0
+		if result != nil {
+			reported = result.CostUSD
+		}
+		// WithoutCancel: a run stopped by its timeout still spent money.
+		opts.Budget.Settle(context.WithoutCancel(ctx), reported)
+	}
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -565,17 +663,33 @@ func resolveSystemPrompt(agentCfg *config.AgentConfig, opts RunOptions) (string,
 	return Interpolate(agentCfg.SystemPrompt, opts.Variables)
 }
 
-func collectRunResult(ctx context.Context, stream *claude.Stream, runSpan trace.Span) (*AgentResult, error) {
+// collectRunResult drains stream into an AgentResult, reporting its tool calls
+// to opts.Audit. When opts.Budget rejects an observed message, abort is called
+// to stop the subprocess and the budget error is returned in place of whatever
+// the aborted run produced.
+func collectRunResult(
+	ctx context.Context, stream *claude.Stream, runSpan trace.Span,
+	opts RunOptions, abort context.CancelFunc,
+) (*AgentResult, error) {
 	var finalThinking string
 	var result *AgentResult
-	var resultErr error
+	var resultErr, budgetErr error
 	toolSpans := make(map[string]ToolSpanEntry)
 
 	for event := range stream.Events() {
-		processRunEvent(ctx, event, &finalThinking, &result, &resultErr, runSpan, toolSpans)
+		if budgetErr == nil {
+			if err := ObserveBudget(opts.Budget, event); err != nil {
+				budgetErr = err
+				abort()
+			}
+		}
+		processRunEvent(ctx, event, &finalThinking, &result, &resultErr, runSpan, toolSpans, opts.Audit)
 	}
-	FlushToolSpans(toolSpans)
+	FlushToolSpans(ctx, toolSpans, opts.Audit)
 
+	if budgetErr != nil {
+		return result, budgetErr
+	}
 	if resultErr != nil {
 		return nil, resultErr
 	}
@@ -587,13 +701,13 @@ func collectRunResult(ctx context.Context, stream *claude.Stream, runSpan trace.
 
 // processRunEvent handles a single event during result collection.
 // It updates the thinking, result, and error pointers in place and records
-// OTel spans for tool calls and result metadata.
+// OTel spans for tool calls and result metadata; finished calls go to auditor.
 // We do NOT return early on TypeResult — the remaining events must be drained
 // so the subprocess has time to finish writing the session to disk.
 func processRunEvent(
 	ctx context.Context, event claude.Event,
 	thinking *string, result **AgentResult, resultErr *error,
-	runSpan trace.Span, toolSpans map[string]ToolSpanEntry,
+	runSpan trace.Span, toolSpans map[string]ToolSpanEntry, auditor ToolAuditor,
 ) {
 	switch event.Type {
 	case claude.TypeAssistant:
@@ -608,7 +722,7 @@ func processRunEvent(
 	case claude.TypeToolProgress:
 		RecordToolProgress(event.ToolProgress, toolSpans)
 	case MessageTypeUser:
-		CloseToolSpans(event.Raw, toolSpans)
+		CloseToolSpans(ctx, event.Raw, toolSpans, auditor)
 	case claude.TypeResult:
 		if event.Result == nil {
 			return
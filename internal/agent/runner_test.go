@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -336,3 +337,115 @@ func assertEqual(t *testing.T, got, want string) {
 		t.Errorf("got %q, want %q", got, want)
 	}
 }
+
+func TestWithoutGatedTools(t *testing.T) {
+	allowed := []string{"Read", "Bash", "mcp__github__create_issue", "mcp__github__list_issues"}
+
+	if got := withoutGatedTools(allowed, nil); len(got) != len(allowed) {
+		t.Errorf("without an agent nothing is gated, got %v", got)
+	}
+
+	agentCfg := &config.AgentConfig{Capabilities: config.AgentCapabilities{
+		RequireApproval: []string{"Bash", "mcp__github__create_*"},
+	}}
+	got := withoutGatedTools(allowed, agentCfg)
+	want := []string{"Read", "mcp__github__list_issues"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		assertEqual(t, got[i], want[i])
+	}
+}
+
+func TestWithoutGatedTools_Policy(t *testing.T) {
+	allowed := []string{"Read", "Bash", "WebFetch"}
+	agentCfg := &config.AgentConfig{Capabilities: config.AgentCapabilities{
+		Policy: &config.ToolPolicy{Rules: []config.PolicyRule{{Tool: "Bash", Action: config.PolicyDeny}}},
+	}}
+
+	got := withoutGatedTools(allowed, agentCfg)
+	want := []string{"Read", "WebFetch"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		assertEqual(t, got[i], want[i])
+	}
+}
+
+func TestWrapPermissionHandler_Policy(t *testing.T) {
+	var delegated []string
+	inner := func(toolName string, _ json.RawMessage, _ claude.PermissionContext) claude.PermissionResult {
+		delegated = append(delegated, toolName)
+		return claude.PermissionResult{Behavior: "allow"}
+	}
+	agentCfg := &config.AgentConfig{Slug: "ops", Capabilities: config.AgentCapabilities{
+		RequireApproval: []string{"Write"},
+		Policy: &config.ToolPolicy{Rules: []config.PolicyRule{
+			{Tool: "Bash", Action: config.PolicyDeny, Command: `rm\s+-rf`, Reason: "no recursive deletes"},
+			{Tool: "Bash", Action: config.PolicyAsk, Command: `^git push`},
+			{Tool: "WebFetch", Action: config.PolicyAllow, Hosts: []string{"*.github.com"}},
+			{Tool: "WebFetch", Action: config.PolicyDeny},
+		}},
+	}}
+	allowed := []string{"Read", "Write", "Bash", "WebFetch"}
+	call := func(handler claude.PermissionHandler, toolName, input string) claude.PermissionResult {
+		return handler(toolName, json.RawMessage(input), claude.PermissionContext{})
+	}
+
+	t.Run("enforced", func(t *testing.T) {
+		delegated = nil
+		handler := wrapPermissionHandler(inner, allowed, agentCfg)
+
+		denied := call(handler, "Bash", `{"command":"rm -rf /tmp/x"}`)
+		assertEqual(t, denied.Behavior, "deny")
+		assertEqual(t, denied.Message, "this agent's tool policy denies this call to Bash: no recursive deletes")
+		assertEqual(t, call(handler, "Bash", `{"command":"git push origin main"}`).Behavior, "allow")
+		assertEqual(t, call(handler, "Bash", `{"command":"ls"}`).Behavior, "allow")
+		assertEqual(t, call(handler, "WebFetch", `{"url":"https://api.github.com/repos"}`).Behavior, "allow")
+		assertEqual(t, call(handler, "WebFetch", `{"url":"https://example.com"}`).Behavior, "deny")
+		assertEqual(t, call(handler, "Write", `{"file_path":"/tmp/a"}`).Behavior, "allow")
+
+		// Only the ask and the gated Write reach the inner handler; calls no
+		// rule decides are allowed as claude would have.
+		if len(delegated) != 2 || delegated[0] != "Bash" || delegated[1] != "Write" {
+			t.Errorf("delegated %v, want [Bash Write]", delegated)
+		}
+	})
+
+	t.Run("without an inner handler asks are denied", func(t *testing.T) {
+		handler := wrapPermissionHandler(nil, allowed, agentCfg)
+		assertEqual(t, call(handler, "Bash", `{"command":"git push"}`).Behavior, "deny")
+		assertEqual(t, call(handler, "Write", `{"file_path":"/tmp/a"}`).Behavior, "allow")
+	})
+
+	t.Run("dry run only logs", func(t *testing.T) {
+		dryRun := *agentCfg
+		dryRun.Capabilities.Policy = &config.ToolPolicy{DryRun: true, Rules: agentCfg.Capabilities.Policy.Rules}
+		handler := wrapPermissionHandler(nil, allowed, &dryRun)
+		assertEqual(t, call(handler, "Bash", `{"command":"rm -rf /"}`).Behavior, "allow")
+		assertEqual(t, call(handler, "WebFetch", `{"url":"https://example.com"}`).Behavior, "allow")
+	})
+
+	t.Run("an invalid policy denies everything", func(t *testing.T) {
+		invalid := *agentCfg
+		invalid.Capabilities.Policy = &config.ToolPolicy{Rules: []config.PolicyRule{{Tool: "Bash", Action: "maybe"}}}
+		handler := wrapPermissionHandler(inner, allowed, &invalid)
+		assertEqual(t, call(handler, "Read", `{"file_path":"/tmp/a"}`).Behavior, "deny")
+	})
+}
+
+func TestBuildSDKOptions_PolicyInstallsHandler(t *testing.T) {
+	agentCfg := &config.AgentConfig{Capabilities: config.AgentCapabilities{
+		BuiltIn: []string{"Bash"},
+		Policy:  &config.ToolPolicy{Rules: []config.PolicyRule{{Tool: "Bash", Action: config.PolicyDeny}}},
+	}}
+	o := applyOpts(buildSDKOptions(context.Background(), agentCfg, RunOptions{}, ""))
+	if o.PermissionHandler == nil {
+		t.Fatal("a policy must be enforced even without a permission handler")
+	}
+	if o.PermissionMode == claude.PermissionModeBypassPermissions {
+		t.Error("bypass mode would skip the permission handler")
+	}
+}
@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"time"
 	"unicode/utf8"
 
 	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
@@ -15,9 +16,13 @@ import (
 // is returned. It is not a named constant in the SDK.
 const MessageTypeUser claude.MessageType = "user"
 
-// ToolSpanEntry tracks an in-flight tool_use span keyed by tool_use_id.
+// ToolSpanEntry tracks an in-flight tool_use span keyed by tool_use_id. The
+// call itself is kept alongside the span for the audit log.
 type ToolSpanEntry struct {
-	Span trace.Span
+	Span    trace.Span
+	Name    string
+	Input   json.RawMessage
+	Started time.Time
 }
 
 // OpenToolSpans starts a child span for every tool_use block found in an
@@ -52,12 +57,15 @@ func OpenToolSpans(ctx context.Context, runSpan trace.Span, raw json.RawMessage,
 			attribute.String("tool.name", blk.Name),
 			attribute.String("tool.input", TruncateAttr(string(blk.Input), 512)),
 		)
-		toolSpans[blk.ID] = ToolSpanEntry{Span: span}
+		toolSpans[blk.ID] = ToolSpanEntry{Span: span, Name: blk.Name, Input: blk.Input, Started: time.Now()}
 	}
 }
 
-// CloseToolSpans ends spans for completed tool_result items in a "user" event.
-func CloseToolSpans(raw json.RawMessage, toolSpans map[string]ToolSpanEntry) {
+// CloseToolSpans ends spans for completed tool_result items in a "user" event
+// and reports the calls to auditor, which may be nil.
+func CloseToolSpans(
+	ctx context.Context, raw json.RawMessage, toolSpans map[string]ToolSpanEntry, auditor ToolAuditor,
+) {
 	var msg struct {
 		Type    string `json:"type"`
 		Message struct {
@@ -65,6 +73,7 @@ func CloseToolSpans(raw json.RawMessage, toolSpans map[string]ToolSpanEntry) {
 				Type      string          `json:"type"`
 				ToolUseID string          `json:"tool_use_id,omitempty"`
 				Content   json.RawMessage `json:"content,omitempty"`
+				IsError   bool            `json:"is_error,omitempty"`
 			} `json:"content"`
 		} `json:"message"`
 	}
@@ -81,19 +90,42 @@ func CloseToolSpans(raw json.RawMessage, toolSpans map[string]ToolSpanEntry) {
 		}
 		entry.Span.SetAttributes(
 			attribute.String("tool.result", TruncateAttr(string(c.Content), 512)),
+			attribute.Bool("tool.is_error", c.IsError),
 		)
 		entry.Span.End()
 		delete(toolSpans, c.ToolUseID)
+
+		status := ToolCallSucceeded
+		if c.IsError {
+			status = ToolCallFailed
+		}
+		auditToolCall(ctx, auditor, c.ToolUseID, entry, status)
 	}
 }
 
 // FlushToolSpans ends all in-flight tool spans. Called when the event loop
-// exits to prevent spans from being left open on cancellation or error.
-func FlushToolSpans(toolSpans map[string]ToolSpanEntry) {
+// exits to prevent spans from being left open on cancellation or error. The
+// calls are reported to auditor, which may be nil, as interrupted.
+func FlushToolSpans(ctx context.Context, toolSpans map[string]ToolSpanEntry, auditor ToolAuditor) {
 	for id, entry := range toolSpans {
 		entry.Span.End()
 		delete(toolSpans, id)
+		auditToolCall(ctx, auditor, id, entry, ToolCallInterrupted)
+	}
+}
+
+func auditToolCall(ctx context.Context, auditor ToolAuditor, id string, entry ToolSpanEntry, status string) {
+	if auditor == nil {
+		return
 	}
+	auditor.Record(ctx, ToolCall{
+		ToolUseID: id,
+		ToolName:  entry.Name,
+		Input:     entry.Input,
+		Status:    status,
+		StartedAt: entry.Started,
+		Duration:  time.Since(entry.Started),
+	})
 }
 
 // RecordToolProgress adds a progress event to the matching in-flight tool span.
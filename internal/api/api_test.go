@@ -60,6 +60,7 @@ func newHarness(t *testing.T) *testHarness {
 
 	r := chi.NewRouter()
 	srv.Mount(r)
+	r.Route("/v1", srv.MountOpenAI)
 
 	return &testHarness{
 		agentSvc:        agentSvc,
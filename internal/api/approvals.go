@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
+
+	"github.com/shaharia-lab/agento/internal/approval"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// ApprovalBroker lists the approval requests of unattended runs and answers
+// them from the web UI, and asks for the gated calls of chats run through
+// the OpenAI-compatible API. *approval.Broker satisfies it.
+type ApprovalBroker interface {
+	List(ctx context.Context, filter storage.ApprovalFilter) ([]*storage.ApprovalRequest, error)
+	Decide(ctx context.Context, id string, approve bool, decidedBy string) (*storage.ApprovalRequest, error)
+	PermissionHandler(ctx context.Context, agentCfg *config.AgentConfig, run approval.Run) claude.PermissionHandler
+}
+
+// decidedByWeb records an answer given on the Approvals page.
+const decidedByWeb = "web"
+
+// mountApprovalRoutes registers the approval request routes.
+func (s *Server) mountApprovalRoutes(r chi.Router) {
+	r.Get("/approvals", s.handleListApprovals)
+	r.Post("/approvals/{id}/approve", s.handleDecideApproval(true))
+	r.Post("/approvals/{id}/deny", s.handleDecideApproval(false))
+}
+
+// handleListApprovals returns approval requests, newest first. Accepts an
+// optional ?status= filter and ?limit=N (default 100).
+func (s *Server) handleListApprovals(w http.ResponseWriter, r *http.Request) {
+	filter := storage.ApprovalFilter{Limit: 100}
+	switch status := storage.ApprovalStatus(r.URL.Query().Get("status")); status {
+	case "", storage.ApprovalPending, storage.ApprovalApproved, storage.ApprovalDenied, storage.ApprovalExpired:
+		filter.Status = status
+	default:
+		s.writeError(w, http.StatusBadRequest, "invalid status")
+		return
+	}
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			s.writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		filter.Limit = n
+	}
+
+	if s.approvals == nil {
+		s.writeJSON(w, http.StatusOK, []*storage.ApprovalRequest{})
+		return
+	}
+	requests, err := s.approvals.List(r.Context(), filter)
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, requests)
+}
+
+// handleDecideApproval answers a pending approval request, waking the run
+// waiting on it.
+func (s *Server) handleDecideApproval(approve bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.approvals == nil {
+			s.writeError(w, http.StatusNotFound, approval.ErrNotFound.Error())
+			return
+		}
+		req, err := s.approvals.Decide(r.Context(), chi.URLParam(r, "id"), approve, decidedByWeb)
+		switch {
+		case errors.Is(err, approval.ErrNotFound):
+			s.writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, approval.ErrAlreadyDecided):
+			s.writeError(w, http.StatusConflict, err.Error())
+		case err != nil:
+			s.httpErr(w, err)
+		default:
+			s.writeJSON(w, http.StatusOK, req)
+		}
+	}
+}
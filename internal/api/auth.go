@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/shaharia-lab/agento/internal/auth"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// AuthManager reports whether authentication is on, signs browsers out and
+// manages API tokens. *auth.Manager satisfies it.
+type AuthManager interface {
+	Enabled(ctx context.Context) (bool, error)
+	Logout(ctx context.Context, sessionID string) error
+	CreateToken(
+		ctx context.Context, name string, scope storage.APITokenScope, ttl time.Duration,
+	) (*storage.APIToken, string, error)
+	ListTokens(ctx context.Context) ([]*storage.APIToken, error)
+	RevokeToken(ctx context.Context, id string) error
+}
+
+// maxTokenExpiryDays caps expires_in_days on a new API token.
+const maxTokenExpiryDays = 3650
+
+// AuthStatus is the response of GET /api/auth/status.
+type AuthStatus struct {
+	// Enabled reports whether an admin password is set.
+	Enabled bool `json:"enabled"`
+	// Kind is "session" or "token": how this request signed in. Empty while
+	// authentication is off.
+	Kind      string                `json:"kind,omitempty"`
+	Scope     storage.APITokenScope `json:"scope,omitempty"`
+	TokenName string                `json:"token_name,omitempty"`
+}
+
+// CreateAPITokenRequest is the body of POST /api/auth/tokens.
+type CreateAPITokenRequest struct {
+	Name  string                `json:"name"`
+	Scope storage.APITokenScope `json:"scope"`
+	// ExpiresInDays is how long the token lasts. Zero never expires.
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+// CreatedAPIToken is the response of POST /api/auth/tokens. Token is the
+// secret, returned this once only.
+type CreatedAPIToken struct {
+	*storage.APIToken
+	Token string `json:"token"`
+}
+
+// mountAuthRoutes registers the authentication routes. Which of them a
+// request may reach is decided by the router's auth middleware: the token
+// routes need an admin session or token.
+func (s *Server) mountAuthRoutes(r chi.Router) {
+	r.Get("/auth/status", s.handleAuthStatus)
+	r.Post("/auth/logout", s.handleLogout)
+	r.Get("/auth/tokens", s.handleListAPITokens)
+	r.Post("/auth/tokens", s.handleCreateAPIToken)
+	r.Delete("/auth/tokens/{id}", s.handleRevokeAPIToken)
+}
+
+// handleAuthStatus reports whether authentication is on and how the request
+// signed in.
+func (s *Server) handleAuthStatus(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		s.writeJSON(w, http.StatusOK, AuthStatus{})
+		return
+	}
+	enabled, err := s.auth.Enabled(r.Context())
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	status := AuthStatus{Enabled: enabled}
+	if p := auth.PrincipalFrom(r.Context()); p != nil {
+		status.Kind = p.Kind
+		status.Scope = p.Scope
+		status.TokenName = p.TokenName
+	}
+	s.writeJSON(w, http.StatusOK, status)
+}
+
+// handleLogout ends the browser's session and clears its cookie.
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if c, err := r.Cookie(auth.SessionCookie); err == nil && s.auth != nil {
+		if err := s.auth.Logout(r.Context(), c.Value); err != nil {
+			s.httpErr(w, err)
+			return
+		}
+	}
+	http.SetCookie(w, auth.ClearedSessionCookie(r))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListAPITokens returns every API token, newest first. Secrets are
+// never included.
+func (s *Server) handleListAPITokens(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		s.writeJSON(w, http.StatusOK, []*storage.APIToken{})
+		return
+	}
+	tokens, err := s.auth.ListTokens(r.Context())
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, tokens)
+}
+
+// handleCreateAPIToken issues an API token and returns its secret, which
+// cannot be shown again.
+func (s *Server) handleCreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPITokenRequest
+	if json.NewDecoder(r.Body).Decode(&req) != nil {
+		s.writeError(w, http.StatusBadRequest, errInvalidJSONBody)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	switch {
+	case req.Name == "":
+		s.writeError(w, http.StatusBadRequest, "name is required")
+		return
+	case !auth.ValidScope(req.Scope):
+		s.writeError(w, http.StatusBadRequest, "scope must be read, run or admin")
+		return
+	case req.ExpiresInDays < 0 || req.ExpiresInDays > maxTokenExpiryDays:
+		s.writeError(w, http.StatusBadRequest, "expires_in_days must be between 0 and 3650")
+		return
+	}
+	if s.auth == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "authentication is not available")
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	token, secret, err := s.auth.CreateToken(r.Context(), req.Name, req.Scope, ttl)
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, CreatedAPIToken{APIToken: token, Token: secret})
+}
+
+// handleRevokeAPIToken deletes an API token. Requests carrying it are
+// refused from then on.
+func (s *Server) handleRevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		s.writeError(w, http.StatusNotFound, auth.ErrTokenNotFound.Error())
+		return
+	}
+	err := s.auth.RevokeToken(r.Context(), chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, auth.ErrTokenNotFound):
+		s.writeError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		s.httpErr(w, err)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
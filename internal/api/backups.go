@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/shaharia-lab/agento/internal/backup"
+)
+
+// BackupManager lists the backups in the backup directory and makes one on
+// demand. *backup.Scheduler satisfies it.
+type BackupManager interface {
+	List() ([]backup.Archive, error)
+	BackupNow(ctx context.Context) (*backup.Archive, error)
+}
+
+// mountBackupRoutes registers the backup routes. The schedule itself is part
+// of the settings.
+func (s *Server) mountBackupRoutes(r chi.Router) {
+	r.Get("/backups", s.handleListBackups)
+	r.Post("/backups", s.handleCreateBackup)
+}
+
+// handleListBackups returns the backups in the backup directory, newest first.
+func (s *Server) handleListBackups(w http.ResponseWriter, _ *http.Request) {
+	if s.backups == nil {
+		s.writeJSON(w, http.StatusOK, []backup.Archive{})
+		return
+	}
+	archives, err := s.backups.List()
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, archives)
+}
+
+// handleCreateBackup makes a backup now. Retention does not delete it.
+func (s *Server) handleCreateBackup(w http.ResponseWriter, r *http.Request) {
+	if s.backups == nil {
+		s.writeError(w, http.StatusServiceUnavailable, "backups are not available")
+		return
+	}
+	archive, err := s.backups.BackupNow(r.Context())
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, archive)
+}
@@ -0,0 +1,53 @@
+package api_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/api"
+	"github.com/shaharia-lab/agento/internal/backup"
+)
+
+type stubBackups struct {
+	archives []backup.Archive
+}
+
+func (b *stubBackups) List() ([]backup.Archive, error) { return b.archives, nil }
+
+func (b *stubBackups) BackupNow(context.Context) (*backup.Archive, error) {
+	a := backup.Archive{Name: "agento-backup-20261016T090000Z.tar.gz"}
+	b.archives = append([]backup.Archive{a}, b.archives...)
+	return &a, nil
+}
+
+func TestBackups(t *testing.T) {
+	serve := func(cfg api.ServerConfig, method string) *httptest.ResponseRecorder {
+		r := chi.NewRouter()
+		cfg.Logger = slog.Default()
+		api.New(cfg).Mount(r)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, "/backups", nil))
+		return w
+	}
+
+	w := serve(api.ServerConfig{}, http.MethodGet)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+	assert.Equal(t, http.StatusServiceUnavailable, serve(api.ServerConfig{}, http.MethodPost).Code)
+
+	b := &stubBackups{}
+	w = serve(api.ServerConfig{Backups: b}, http.MethodPost)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"agento-backup-20261016T090000Z.tar.gz"`)
+
+	w = serve(api.ServerConfig{Backups: b}, http.MethodGet)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"automatic":false`)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// handleListBudgets returns all budget policies.
+func (s *Server) handleListBudgets(w http.ResponseWriter, r *http.Request) {
+	policies, err := s.budgetSvc.ListPolicies(r.Context())
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, policies)
+}
+
+// handleGetBudgetStatus returns every budget policy with its current-window spend.
+func (s *Server) handleGetBudgetStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.budgetSvc.Status(r.Context())
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, status)
+}
+
+// handleGetBudget returns a single budget policy.
+func (s *Server) handleGetBudget(w http.ResponseWriter, r *http.Request) {
+	p, err := s.budgetSvc.GetPolicy(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, p)
+}
+
+// handleCreateBudget creates a new budget policy.
+func (s *Server) handleCreateBudget(w http.ResponseWriter, r *http.Request) {
+	var req BudgetPolicyRequest
+	if json.NewDecoder(r.Body).Decode(&req) != nil {
+		s.writeError(w, http.StatusBadRequest, errInvalidJSONBody)
+		return
+	}
+
+	created, err := s.budgetSvc.CreatePolicy(r.Context(), req.toPolicy())
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, created)
+}
+
+// handleUpdateBudget updates an existing budget policy.
+func (s *Server) handleUpdateBudget(w http.ResponseWriter, r *http.Request) {
+	var req BudgetPolicyRequest
+	if json.NewDecoder(r.Body).Decode(&req) != nil {
+		s.writeError(w, http.StatusBadRequest, errInvalidJSONBody)
+		return
+	}
+
+	updated, err := s.budgetSvc.UpdatePolicy(r.Context(), chi.URLParam(r, "id"), req.toPolicy())
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, updated)
+}
+
+// handleDeleteBudget removes a budget policy.
+func (s *Server) handleDeleteBudget(w http.ResponseWriter, r *http.Request) {
+	if err := s.budgetSvc.DeletePolicy(r.Context(), chi.URLParam(r, "id")); err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (req BudgetPolicyRequest) toPolicy() *storage.BudgetPolicy {
+	return &storage.BudgetPolicy{
+		Name:     req.Name,
+		Scope:    storage.BudgetScope(req.Scope),
+		ScopeID:  req.ScopeID,
+		Period:   storage.BudgetPeriod(req.Period),
+		LimitUSD: req.LimitUSD,
+		Enabled:  req.Enabled,
+	}
+}
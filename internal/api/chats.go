@@ -264,6 +264,7 @@ type eventProcessor struct {
 	w            http.ResponseWriter
 	chs          sendMessageChannels
 	execSpan     trace.Span // parent span covering the full streaming window
+	auditor      agent.ToolAuditor
 }
 
 func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request) {
@@ -294,9 +295,11 @@ func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request) {
 	chs := newSendMessageChannels()
 	permHandler := s.buildPermissionHandler(r, chs)
 
+	auditor := s.chatToolAuditor(r.Context(), id)
+
 	agentSession, chatSession, err := s.chatSvc.BeginMessage(
 		r.Context(), id, req.Content,
-		agent.RunOptions{PermissionHandler: permHandler},
+		agent.RunOptions{PermissionHandler: permHandler, Audit: auditor},
 	)
 	if err != nil {
 		s.handleBeginMessageError(w, id, err)
@@ -312,7 +315,7 @@ func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request) {
 	)
 
 	isFirstMessage := chatSession.Title == "New Chat"
-	state := s.streamAgentSession(w, r, id, agentSession, chs, execSpan)
+	state := s.streamAgentSession(w, r, id, agentSession, chs, execSpan, auditor)
 	state.userContent = req.Content
 
 	if isFirstMessage {
@@ -322,12 +325,13 @@ func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request) {
 }
 
 // streamAgentSession sets up the SSE response, registers the live session,
-// runs the event loop, and returns the accumulated stream state.
+// runs the event loop, and returns the accumulated stream state. Finished tool
+// calls are reported to auditor, which may be nil.
 // execSpan is ended by the caller (commitMessage).
 func (s *Server) streamAgentSession(
 	w http.ResponseWriter, r *http.Request, id string,
 	agentSession *claude.Session, chs sendMessageChannels,
-	execSpan trace.Span,
+	execSpan trace.Span, auditor agent.ToolAuditor,
 ) streamState {
 	flusher, ok := s.prepareSSEResponse(w, agentSession)
 	if !ok {
@@ -357,9 +361,11 @@ func (s *Server) streamAgentSession(
 		w:            w,
 		chs:          chs,
 		execSpan:     execSpan,
+		auditor:      auditor,
 	}
 	state := ep.consumeAgentEvents()
-	agent.FlushToolSpans(state.toolSpans) // close any spans not ended by a tool_result
+	// Close any spans not ended by a tool_result.
+	agent.FlushToolSpans(r.Context(), state.toolSpans, auditor)
 	return state
 }
 
@@ -522,7 +528,7 @@ func (ep *eventProcessor) processAgentEvent(event claude.Event, state *streamSta
 		agent.RecordToolProgress(event.ToolProgress, state.toolSpans)
 
 	case agent.MessageTypeUser:
-		agent.CloseToolSpans(event.Raw, state.toolSpans)
+		agent.CloseToolSpans(ep.r.Context(), event.Raw, state.toolSpans, ep.auditor)
 
 	case claude.TypeResult:
 		if event.Result == nil {
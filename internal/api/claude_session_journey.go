@@ -24,3 +24,80 @@ func (s *Server) handleGetClaudeSessionJourney(w http.ResponseWriter, r *http.Re
 	}
 	s.writeJSON(w, http.StatusOK, journey)
 }
+
+// handleTailClaudeSession streams a session's journey as Claude Code writes
+// it: the transcript so far, a "ready" event, then every new step, sub-agent
+// and usage total until the client goes away.
+//
+//	GET /api/claude-sessions/{id}/tail
+func (s *Server) handleTailClaudeSession(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	tail := claudesessions.OpenSessionTail(id, s.logger)
+	if tail == nil {
+		s.writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	err := tail.Run(r.Context(), func(ev claudesessions.TailEvent) {
+		s.sendSSEEvent(w, flusher, ev.Type, ev.Data)
+	})
+	if err != nil {
+		s.logger.Error("tail claude session failed", "session_id", id, "error", err)
+		s.sendSSEEvent(w, flusher, "error", map[string]string{"error": "failed to read session transcript"})
+	}
+}
+
+// handleCompareClaudeSessions aligns the journeys of two sessions — typically
+// two runs of the same task — and returns where they diverged and what each
+// turn cost.
+//
+//	GET /api/claude-sessions/compare?left={id}&right={id}
+func (s *Server) handleCompareClaudeSessions(w http.ResponseWriter, r *http.Request) {
+	leftID, rightID := r.URL.Query().Get("left"), r.URL.Query().Get("right")
+	if leftID == "" || rightID == "" {
+		s.writeError(w, http.StatusBadRequest, "left and right session IDs are required")
+		return
+	}
+
+	journeys := make([]*claudesessions.SessionJourney, 2)
+	insights := make([]*claudesessions.SessionInsight, 2)
+	for i, id := range []string{leftID, rightID} {
+		journey, err := claudesessions.GetSessionJourney(id, s.logger)
+		if err != nil {
+			s.logger.Error("get claude session journey failed", "session_id", id, "error", err)
+			s.writeError(w, http.StatusInternalServerError, "failed to get session journey")
+			return
+		}
+		if journey == nil {
+			s.writeError(w, http.StatusNotFound, "session not found: "+id)
+			return
+		}
+		journeys[i] = journey
+
+		// Insights are a bonus: a session the pipeline has not reached yet is
+		// still compared, just without the metric deltas.
+		if s.insightStore == nil {
+			continue
+		}
+		insight, err := s.insightStore.Get(r.Context(), id)
+		if err != nil {
+			s.logger.Warn("get session insight for comparison failed", "session_id", id, "error", err)
+			continue
+		}
+		insights[i] = insight
+	}
+
+	s.writeJSON(w, http.StatusOK, claudesessions.CompareJourneys(journeys[0], journeys[1], insights[0], insights[1]))
+}
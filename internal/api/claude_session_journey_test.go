@@ -0,0 +1,23 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareClaudeSessions_Validation(t *testing.T) {
+	h := newHarness(t)
+
+	w := h.do(httptest.NewRequest(http.MethodGet, "/claude-sessions/compare?left=a1b2", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The route must win over /claude-sessions/{id}, and an unknown session is
+	// a 404 naming it.
+	w = h.do(httptest.NewRequest(http.MethodGet,
+		"/claude-sessions/compare?left=no-such-session-1&right=no-such-session-2", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "no-such-session-1")
+}
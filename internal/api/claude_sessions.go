@@ -67,6 +67,40 @@ func (s *Server) handleGetClaudeSessionFacets(w http.ResponseWriter, r *http.Req
 	s.writeJSON(w, http.StatusOK, facets)
 }
 
+// handleSearchClaudeSessions runs a full-text search over transcript content
+// and returns ranked hits, each addressing the journey step it matched.
+//
+// Query params: q (required) is the search text; limit and offset page the
+// hits; every filter sessionQueryFromRequest reads narrows the sessions
+// searched. Its sort and cursor do not apply — hits are ranked by relevance.
+func (s *Server) handleSearchClaudeSessions(w http.ResponseWriter, r *http.Request) {
+	q, err := sessionQueryFromRequest(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strings.TrimSpace(q.Search) == "" {
+		s.writeError(w, http.StatusBadRequest, "q is required")
+		return
+	}
+	search := claudesessions.TranscriptSearchQuery{Text: q.Search, Filter: q, Limit: q.Limit}
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid offset %q", raw))
+			return
+		}
+		search.Offset = n
+	}
+	page, err := s.claudeSessionCache.SearchTranscripts(search)
+	if err != nil {
+		s.logger.Error("search claude sessions failed", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to search sessions")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, page)
+}
+
 // sessionQueryFromRequest reads the sessions list's filter, sort and page
 // parameters. Both the list and the facets endpoint parse through it, so the
 // counter in the toolbar and the rows below it are always describing the same
@@ -75,11 +109,13 @@ func (s *Server) handleGetClaudeSessionFacets(w http.ResponseWriter, r *http.Req
 // Query params:
 //
 //	project           decoded project path, exact match
-//	q                 case-insensitive substring over ID, titles, preview, path
+//	q                 case-insensitive substring over ID, titles, preview, path,
+//	                  or a word of the transcript content
 //	favorites         "true" to keep only starred sessions
 //	links             "with" | "without"
 //	permission_mode   exact match
 //	model             exact match
+//	coding_agent      exact match: claude-code, codex, gemini or aider
 //	messages_min/max  inclusive bounds on conversational turns
 //	duration_min/max  inclusive bounds on active duration, in minutes
 //	tokens_in_min/max, tokens_out_min/max, cost_min/max
@@ -97,6 +133,7 @@ func sessionQueryFromRequest(r *http.Request) (claudesessions.SessionQuery, erro
 	q := claudesessions.SessionQuery{
 		Project:         v.Get("project"),
 		ConfigDir:       v.Get("config_dir"),
+		CodingAgent:     v.Get("coding_agent"),
 		Search:          v.Get("q"),
 		FavoritesOnly:   v.Get("favorites") == "true",
 		Links:           claudesessions.LinkFilter(v.Get("links")),
@@ -328,6 +365,60 @@ func (s *Server) handleGetClaudeSessionStatus(w http.ResponseWriter, _ *http.Req
 	})
 }
 
+// handleGetClaudeSessionArchive reports what the transcript archive holds and
+// how much disk it takes, for the Data & Analytics settings tab.
+func (s *Server) handleGetClaudeSessionArchive(w http.ResponseWriter, r *http.Request) {
+	usage, err := claudesessions.TranscriptArchiveUsage(r.Context())
+	if err != nil {
+		s.logger.Error("transcript archive usage failed", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to read the transcript archive")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, usage)
+}
+
+// maxHookBodyBytes caps a hook event's body. `agento hook` forwards only the
+// fields Agento reads, so a real one is a few hundred bytes.
+const maxHookBodyBytes = 64 << 10
+
+// handleClaudeSessionHook receives a Claude Code hook event from `agento hook`
+// and brings the session it names up to date without a scan.
+func (s *Server) handleClaudeSessionHook(w http.ResponseWriter, r *http.Request) {
+	var ev claudesessions.HookEvent
+	if json.NewDecoder(http.MaxBytesReader(w, r.Body, maxHookBodyBytes)).Decode(&ev) != nil {
+		s.writeError(w, http.StatusBadRequest, errInvalidJSONBody)
+		return
+	}
+	if err := s.claudeSessionCache.HandleHookEvent(ev); err != nil {
+		if errors.Is(err, claudesessions.ErrInvalidHookEvent) {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.logger.Error("claude session hook failed", "session_id", ev.SessionID, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to record the hook event")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListLiveClaudeSessions returns the sessions Claude Code's hooks
+// report open. The list is empty until the hooks are installed.
+func (s *Server) handleListLiveClaudeSessions(w http.ResponseWriter, _ *http.Request) {
+	s.writeJSON(w, http.StatusOK, s.claudeSessionCache.LiveSessions())
+}
+
+// handleGetClaudeUsageWindows reports each Claude config dir's current 5-hour
+// and weekly subscription usage windows.
+func (s *Server) handleGetClaudeUsageWindows(w http.ResponseWriter, r *http.Request) {
+	report, err := s.claudeSessionCache.UsageWindows(r.Context())
+	if err != nil {
+		s.logger.Error("claude usage windows failed", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to estimate the usage windows")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, report)
+}
+
 // handleUpdateClaudeSession updates mutable fields of a cached Claude Code session.
 // Supports custom_title and is_favorite — all JSONL-derived fields are read-only.
 func (s *Server) handleUpdateClaudeSession(w http.ResponseWriter, r *http.Request) {
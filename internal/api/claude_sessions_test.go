@@ -0,0 +1,65 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchClaudeSessions_Validation(t *testing.T) {
+	h := newHarness(t)
+
+	// The route must win over /claude-sessions/{id}; without text there is
+	// nothing to search for.
+	for _, target := range []string{
+		"/claude-sessions/search",
+		"/claude-sessions/search?q=%20%20",
+		"/claude-sessions/search?q=flaky&offset=-1",
+		"/claude-sessions/search?q=flaky&limit=ten",
+	} {
+		w := h.do(httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+}
+
+func TestGetClaudeSessionArchive_NoArchiveInstalled(t *testing.T) {
+	h := newHarness(t)
+
+	// The route must win over /claude-sessions/{id}. A process with no archive
+	// reports an empty, disabled one rather than an error.
+	w := h.do(httptest.NewRequest(http.MethodGet, "/claude-sessions/archive", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"enabled":false`)
+	assert.Contains(t, w.Body.String(), `"transcripts":0`)
+}
+
+func TestClaudeSessionHook_RejectsTranscriptsOutsideIndexedDirs(t *testing.T) {
+	h := newHarness(t)
+
+	// The body names a file the server will read, so anything but a session
+	// transcript in an indexed config dir is refused before it is opened.
+	for _, body := range []string{
+		`not json`,
+		`{"session_id":"abc","transcript_path":"/etc/passwd","hook_event_name":"Stop"}`,
+		`{"session_id":"../abc","transcript_path":"/tmp/projects/p/../abc.jsonl","hook_event_name":"Stop"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/claude-sessions/hooks", strings.NewReader(body))
+		w := h.do(req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestTailClaudeSession_UnknownSessionIsNotFound(t *testing.T) {
+	h := newHarness(t)
+	t.Setenv("HOME", t.TempDir())
+
+	for _, id := range []string{"no-such-session", "..%2F..%2Fetc"} {
+		req := httptest.NewRequest(http.MethodGet, "/claude-sessions/"+id+"/tail", nil)
+		w := h.do(req)
+		assert.Equal(t, http.StatusNotFound, w.Code, id)
+		assert.NotEqual(t, "text/event-stream", w.Header().Get("Content-Type"), id)
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/shaharia-lab/agento/internal/export"
+)
+
+// newExporter wires the export datasets to whichever stores the server was
+// given. Each is checked before it is boxed in an interface, so a server
+// built without one reports that dataset unavailable instead of calling a
+// nil pointer.
+func newExporter(cfg ServerConfig) *export.Exporter {
+	var src export.Sources
+	if cfg.SessionCache != nil {
+		src.Sessions = cfg.SessionCache
+	}
+	if cfg.InsightStore != nil {
+		src.Insights = cfg.InsightStore
+	}
+	if cfg.TaskSvc != nil {
+		src.Jobs = cfg.TaskSvc
+	}
+	if cfg.ChatSvc != nil {
+		src.Chats = cfg.ChatSvc
+	}
+	return export.NewExporter(src)
+}
+
+// handleExport downloads a dataset as a file.
+//
+// Path param:
+//
+//	dataset   sessions | session_models | insights | jobs | chats
+//
+// Query params:
+//
+//	format    csv (default) | jsonl | parquet
+//
+// plus every sessions-list filter (see sessionQueryFromRequest), so exporting
+// with a filtered list's parameters downloads that whole list rather than
+// the page on screen.
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	dataset, err := export.ParseDataset(chi.URLParam(r, "dataset"))
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	q, err := sessionQueryFromRequest(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	table, err := s.exporter.Table(r.Context(), dataset, q)
+	if err != nil {
+		s.logger.Error("export failed", "dataset", dataset, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to export "+string(dataset))
+		return
+	}
+
+	w.Header().Set(headerContentType, format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+format.FileName(dataset)+`"`)
+	if err := export.Write(w, format, table); err != nil {
+		// The status line is already sent; all that is left is to log it.
+		s.logger.Error("writing export failed", "dataset", dataset, "format", format, "error", err)
+	}
+}
@@ -0,0 +1,47 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func TestExport_Chats(t *testing.T) {
+	h := newHarness(t)
+	at := time.Date(2026, 8, 15, 9, 0, 0, 0, time.UTC)
+	h.chatSvc.On("ListSessions", mock.Anything).Return([]*storage.ChatSession{
+		{ID: "c1", Title: "Plan", AgentSlug: "planner", CreatedAt: at, UpdatedAt: at, TotalInputTokens: 12},
+	}, nil)
+
+	w := h.do(httptest.NewRequest(http.MethodGet, "/export/chats?format=jsonl", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), `filename="agento-chats.jsonl"`)
+	assert.Contains(t, w.Body.String(), `"id":"c1","title":"Plan","agent_slug":"planner"`)
+	assert.Contains(t, w.Body.String(), `"input_tokens":12`)
+}
+
+func TestExport_RejectsUnknownDatasetAndFormat(t *testing.T) {
+	h := newHarness(t)
+
+	w := h.do(httptest.NewRequest(http.MethodGet, "/export/everything", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = h.do(httptest.NewRequest(http.MethodGet, "/export/chats?format=xlsx", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestExport_UnavailableDataset: the harness wires no session cache, and the
+// sessions export must say so rather than dereference it.
+func TestExport_UnavailableDataset(t *testing.T) {
+	h := newHarness(t)
+	w := h.do(httptest.NewRequest(http.MethodGet, "/export/sessions?format=csv", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
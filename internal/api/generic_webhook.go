@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+	"github.com/shaharia-lab/agento/internal/trigger"
+)
+
+// maxWebhookBodyBytes caps the request body a generic webhook will read.
+const maxWebhookBodyBytes = 1 << 20
+
+// GenericWebhookHandler handles inbound requests to generic webhook trigger
+// rules. Each rule has its own URL and secret. It is mounted outside the /api
+// prefix on the main router.
+type GenericWebhookHandler struct {
+	triggerStore     storage.TriggerStore
+	integrationStore storage.IntegrationStore
+	dispatcher       *trigger.Dispatcher
+	logger           *slog.Logger
+}
+
+// NewGenericWebhookHandler creates a handler for inbound generic webhooks.
+func NewGenericWebhookHandler(
+	triggerStore storage.TriggerStore,
+	integrationStore storage.IntegrationStore,
+	dispatcher *trigger.Dispatcher,
+	logger *slog.Logger,
+) *GenericWebhookHandler {
+	return &GenericWebhookHandler{
+		triggerStore:     triggerStore,
+		integrationStore: integrationStore,
+		dispatcher:       dispatcher,
+		logger:           logger,
+	}
+}
+
+// Mount registers the webhook route on the given router.
+func (h *GenericWebhookHandler) Mount(r chi.Router) {
+	r.Post("/webhooks/generic/{id}", h.handleInbound)
+}
+
+func (h *GenericWebhookHandler) handleInbound(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "id")
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		writeWebhookError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+
+	// Unknown, disabled and unsigned requests all get the same 404 so the
+	// endpoint does not reveal which rule IDs exist.
+	rule, err := h.triggerStore.GetRule(r.Context(), ruleID)
+	if err != nil || rule == nil || !rule.Enabled || rule.WebhookSecret == "" {
+		writeWebhookError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !trigger.VerifyWebhookSignature(rule.WebhookSecret, body, r.Header.Get(trigger.WebhookSignatureHeader)) {
+		writeWebhookError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+
+	integration, err := h.integrationStore.Get(r.Context(), rule.IntegrationID)
+	if err != nil || integration == nil || !integration.Enabled || integration.Type != "webhook" {
+		writeWebhookError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	prompt, err := trigger.BuildWebhookPrompt(rule, body)
+	if err != nil {
+		writeWebhookError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	h.logger.Info("webhook trigger received",
+		"rule_id", rule.ID, "rule_name", rule.Name,
+		"agent_slug", rule.AgentSlug, "response_mode", rule.ResponseMode)
+
+	if rule.ResponseMode == config.WebhookResponseCallback {
+		h.dispatcher.DispatchWebhook(rule, prompt)
+		writeWebhookJSON(w, http.StatusAccepted, map[string]string{"rule_id": rule.ID, "status": "accepted"})
+		return
+	}
+
+	res, err := h.dispatcher.RunWebhook(r.Context(), rule, prompt)
+	var budgetErr *agent.BudgetExceededError
+	switch {
+	case errors.Is(err, trigger.ErrDispatcherBusy):
+		writeWebhookError(w, http.StatusServiceUnavailable, err.Error())
+	case errors.As(err, &budgetErr):
+		res.Error = err.Error()
+		writeWebhookJSON(w, http.StatusTooManyRequests, res)
+	case err != nil:
+		res.Error = "agent execution failed"
+		writeWebhookJSON(w, http.StatusBadGateway, res)
+	default:
+		writeWebhookJSON(w, http.StatusOK, res)
+	}
+}
+
+// writeWebhookJSON writes v as JSON. The webhook handlers sit outside the
+// api.Server, so they cannot use its helpers.
+func writeWebhookJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v) //nolint:errcheck
+}
+
+func writeWebhookError(w http.ResponseWriter, status int, msg string) {
+	writeWebhookJSON(w, status, map[string]string{"error": msg})
+}
+
+// WebhookHandlers mounts several webhook handlers on one router.
+type WebhookHandlers []interface{ Mount(r chi.Router) }
+
+// Mount registers every handler's routes.
+func (hs WebhookHandlers) Mount(r chi.Router) {
+	for _, h := range hs {
+		h.Mount(r)
+	}
+}
@@ -0,0 +1,105 @@
+package api_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/shaharia-lab/agento/internal/api"
+	"github.com/shaharia-lab/agento/internal/config"
+	storagemocks "github.com/shaharia-lab/agento/internal/storage/mocks"
+	"github.com/shaharia-lab/agento/internal/trigger"
+)
+
+// These cases are all rejected before the dispatcher is reached, so the
+// handler is built without one.
+func TestGenericWebhook_Rejections(t *testing.T) {
+	const body = `{"status":"firing"}`
+	webhookRule := &config.TriggerRule{
+		ID: "r1", IntegrationID: "i1", Enabled: true,
+		WebhookSecret: "s3cret", PromptTemplate: "{{status}}",
+	}
+
+	tests := []struct {
+		name        string
+		rule        *config.TriggerRule
+		integration *config.IntegrationConfig
+		signature   string
+		wantStatus  int
+	}{
+		{
+			name:       "unknown rule",
+			signature:  trigger.SignWebhookPayload("s3cret", []byte(body)),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "rule without a secret",
+			rule:       &config.TriggerRule{ID: "r1", IntegrationID: "i1", Enabled: true},
+			signature:  trigger.SignWebhookPayload("", []byte(body)),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "disabled rule",
+			rule:       &config.TriggerRule{ID: "r1", IntegrationID: "i1", WebhookSecret: "s3cret"},
+			signature:  trigger.SignWebhookPayload("s3cret", []byte(body)),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "missing signature",
+			rule:       webhookRule,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong secret",
+			rule:       webhookRule,
+			signature:  trigger.SignWebhookPayload("guess", []byte(body)),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:        "disabled integration",
+			rule:        webhookRule,
+			integration: &config.IntegrationConfig{ID: "i1", Type: "webhook"},
+			signature:   trigger.SignWebhookPayload("s3cret", []byte(body)),
+			wantStatus:  http.StatusNotFound,
+		},
+		{
+			name: "template variable missing",
+			rule: &config.TriggerRule{
+				ID: "r1", IntegrationID: "i1", Enabled: true,
+				WebhookSecret: "s3cret", PromptTemplate: "{{nope}}",
+			},
+			integration: &config.IntegrationConfig{ID: "i1", Type: "webhook", Enabled: true},
+			signature:   trigger.SignWebhookPayload("s3cret", []byte(body)),
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			triggerStore := new(storagemocks.MockTriggerStore)
+			integrationStore := new(storagemocks.MockIntegrationStore)
+			triggerStore.On("GetRule", mock.Anything, "r1").Return(tc.rule, nil)
+			if tc.integration != nil {
+				integrationStore.On("Get", mock.Anything, "i1").Return(tc.integration, nil)
+			}
+
+			r := chi.NewRouter()
+			api.NewGenericWebhookHandler(triggerStore, integrationStore, nil, slog.Default()).Mount(r)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/generic/r1", strings.NewReader(body))
+			if tc.signature != "" {
+				req.Header.Set(trigger.WebhookSignatureHeader, tc.signature)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+	"github.com/shaharia-lab/agento/internal/trigger"
+)
+
+// maxGitHubBodyBytes matches the 25 MB cap GitHub puts on webhook payloads.
+const maxGitHubBodyBytes = 25 << 20
+
+// GitHubWebhookHandler handles inbound GitHub webhook deliveries.
+// It is mounted outside the /api prefix on the main router.
+type GitHubWebhookHandler struct {
+	triggerStore     storage.TriggerStore
+	integrationStore storage.IntegrationStore
+	dispatcher       *trigger.Dispatcher
+	logger           *slog.Logger
+}
+
+// NewGitHubWebhookHandler creates a handler for inbound GitHub webhooks.
+func NewGitHubWebhookHandler(
+	triggerStore storage.TriggerStore,
+	integrationStore storage.IntegrationStore,
+	dispatcher *trigger.Dispatcher,
+	logger *slog.Logger,
+) *GitHubWebhookHandler {
+	return &GitHubWebhookHandler{
+		triggerStore:     triggerStore,
+		integrationStore: integrationStore,
+		dispatcher:       dispatcher,
+		logger:           logger,
+	}
+}
+
+// Mount registers the webhook route on the given router.
+func (h *GitHubWebhookHandler) Mount(r chi.Router) {
+	r.Post("/webhooks/github/{id}", h.handleInbound)
+}
+
+func (h *GitHubWebhookHandler) handleInbound(w http.ResponseWriter, r *http.Request) {
+	integrationID := chi.URLParam(r, "id")
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxGitHubBodyBytes))
+	if err != nil {
+		writeWebhookError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+
+	storedSecret, status, _, err := h.triggerStore.GetWebhookInfo(r.Context(), integrationID)
+	if err != nil || status != "active" || storedSecret == "" {
+		writeWebhookError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !trigger.VerifyWebhookSignature(storedSecret, body, r.Header.Get(trigger.GitHubSignatureHeader)) {
+		writeWebhookError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+
+	integration, err := h.integrationStore.Get(r.Context(), integrationID)
+	if err != nil || integration == nil || !integration.Enabled || integration.Type != "github" {
+		writeWebhookError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	eventName := r.Header.Get("X-GitHub-Event")
+	if eventName == "ping" {
+		// Sent once when the webhook is created on GitHub.
+		writeWebhookJSON(w, http.StatusOK, map[string]string{"status": "pong"})
+		return
+	}
+
+	event, err := trigger.ParseGitHubEvent(eventName, body)
+	if err != nil {
+		writeWebhookError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	h.logger.Debug("github webhook received",
+		"integration_id", integrationID, "event", event.FullName(),
+		"repository", event.Repo, "delivery", r.Header.Get("X-GitHub-Delivery"))
+
+	// GitHub times out deliveries after ten seconds, so runs happen in the
+	// background.
+	writeWebhookJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+	h.dispatcher.HandleGitHubEvent(integrationID, event)
+}
@@ -0,0 +1,91 @@
+package api_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/shaharia-lab/agento/internal/api"
+	"github.com/shaharia-lab/agento/internal/config"
+	storagemocks "github.com/shaharia-lab/agento/internal/storage/mocks"
+	"github.com/shaharia-lab/agento/internal/trigger"
+)
+
+// None of these deliveries reach the dispatcher, so the handler is built
+// without one.
+func TestGitHubWebhook_Deliveries(t *testing.T) {
+	const body = `{"zen":"Keep it logically awesome."}`
+	github := &config.IntegrationConfig{ID: "gh", Type: "github", Enabled: true}
+
+	tests := []struct {
+		name        string
+		status      string
+		integration *config.IntegrationConfig
+		signature   string
+		event       string
+		wantStatus  int
+	}{
+		{
+			name:       "webhook not enabled",
+			status:     "inactive",
+			signature:  trigger.SignWebhookPayload("s3cret", []byte(body)),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "missing signature",
+			status:     "active",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong secret",
+			status:     "active",
+			signature:  trigger.SignWebhookPayload("guess", []byte(body)),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:        "not a github integration",
+			status:      "active",
+			integration: &config.IntegrationConfig{ID: "gh", Type: "telegram", Enabled: true},
+			signature:   trigger.SignWebhookPayload("s3cret", []byte(body)),
+			wantStatus:  http.StatusNotFound,
+		},
+		{
+			name:        "ping",
+			status:      "active",
+			integration: github,
+			signature:   trigger.SignWebhookPayload("s3cret", []byte(body)),
+			event:       "ping",
+			wantStatus:  http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			triggerStore := new(storagemocks.MockTriggerStore)
+			integrationStore := new(storagemocks.MockIntegrationStore)
+			triggerStore.On("GetWebhookInfo", mock.Anything, "gh").Return("s3cret", tc.status, "", nil)
+			if tc.integration != nil {
+				integrationStore.On("Get", mock.Anything, "gh").Return(tc.integration, nil)
+			}
+
+			r := chi.NewRouter()
+			api.NewGitHubWebhookHandler(triggerStore, integrationStore, nil, slog.Default()).Mount(r)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/github/gh", strings.NewReader(body))
+			if tc.signature != "" {
+				req.Header.Set(trigger.GitHubSignatureHeader, tc.signature)
+			}
+			req.Header.Set("X-GitHub-Event", tc.event)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
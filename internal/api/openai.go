@@ -0,0 +1,624 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/approval"
+	"github.com/shaharia-lab/agento/internal/audit"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// HeaderChatID names the chat session an OpenAI-compatible completion ran in.
+// It is set on every response, and a request that sends it continues that chat
+// whatever its message history says.
+const HeaderChatID = "X-Agento-Chat-Id"
+
+// maxIndexedConversations bounds the conversation index. A conversation that
+// has dropped out of it starts a new chat that is given the history to read.
+const maxIndexedConversations = 1000
+
+// OpenAI error types, as clients expect them in error.type.
+const (
+	openAIInvalidRequest = "invalid_request_error"
+	openAIServerError    = "server_error"
+)
+
+// openAIMessage is one message of a chat completion request.
+type openAIMessage struct {
+	Role    string        `json:"role"`
+	Content openAIContent `json:"content"`
+}
+
+// openAIContent is the text of a message. Clients send either a string or an
+// array of content parts; only the text parts are kept.
+type openAIContent string
+
+// UnmarshalJSON accepts a string, null, or an array of content parts.
+func (c *openAIContent) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*c = openAIContent(s)
+		return nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("content must be a string or an array of parts: %w", err)
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Type == "text" {
+			texts = append(texts, p.Text)
+		}
+	}
+	*c = openAIContent(strings.Join(texts, "\n"))
+	return nil
+}
+
+type chatCompletionRequest struct {
+	Model         string          `json:"model"`
+	Messages      []openAIMessage `json:"messages"`
+	Stream        bool            `json:"stream"`
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options"`
+}
+
+// chatCompletion is both a chat.completion and a chat.completion.chunk; the
+// chunks carry Delta in their choices where the completion carries Message.
+type chatCompletion struct {
+	ID      string             `json:"id"`
+	Object  string             `json:"object"`
+	Created int64              `json:"created"`
+	Model   string             `json:"model"`
+	Choices []completionChoice `json:"choices"`
+	Usage   *completionUsage   `json:"usage,omitempty"`
+}
+
+type completionChoice struct {
+	Index        int                `json:"index"`
+	Message      *completionMessage `json:"message,omitempty"`
+	Delta        *completionMessage `json:"delta,omitempty"`
+	FinishReason *string            `json:"finish_reason"`
+}
+
+type completionMessage struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+}
+
+type completionUsage struct {
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	TotalTokens         int `json:"total_tokens"`
+	PromptTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+}
+
+// newCompletionUsage reports an agent's usage the way OpenAI does: cached and
+// cache-writing input count towards the prompt tokens.
+func newCompletionUsage(u agent.UsageStats) *completionUsage {
+	usage := &completionUsage{
+		PromptTokens:     u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens,
+		CompletionTokens: u.OutputTokens,
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	usage.PromptTokensDetails.CachedTokens = u.CacheReadInputTokens
+	return usage
+}
+
+// openAIModel is an agent as /v1/models lists it. Agents keep no creation
+// time, so Created is zero.
+type openAIModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// MountOpenAI registers the OpenAI-compatible routes, which clients expect
+// under /v1: the models are the saved agents, and a chat completion is a turn
+// of a chat session with one.
+func (s *Server) MountOpenAI(r chi.Router) {
+	r.Get("/models", s.handleListModels)
+	r.Post("/chat/completions", s.handleChatCompletion)
+}
+
+func (s *Server) writeOpenAIError(w http.ResponseWriter, status int, errType, code, msg string) {
+	body := map[string]string{"message": msg, "type": errType}
+	if code != "" {
+		body["code"] = code
+	}
+	s.writeJSON(w, status, map[string]any{"error": body})
+}
+
+func (s *Server) handleListModels(w http.ResponseWriter, r *http.Request) {
+	agents, err := s.agentSvc.List(r.Context())
+	if err != nil {
+		s.logger.Error("list models failed", "error", err)
+		s.writeOpenAIError(w, http.StatusInternalServerError, openAIServerError, "", "failed to list agents")
+		return
+	}
+	models := make([]openAIModel, 0, len(agents))
+	for _, a := range agents {
+		models = append(models, openAIModel{ID: a.Slug, Object: "model", OwnedBy: "agento"})
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"object": "list", "data": models})
+}
+
+// completionTurn is a chat completion request on its way to a chat session.
+type completionTurn struct {
+	req      chatCompletionRequest
+	agentCfg *config.AgentConfig
+	chat     *storage.ChatSession
+	// prompt is what the chat session is sent: the last user message, or the
+	// whole conversation when a new chat has to pick it up.
+	prompt string
+}
+
+func (s *Server) handleChatCompletion(w http.ResponseWriter, r *http.Request) {
+	turn, status, err := s.prepareCompletion(r)
+	if err != nil {
+		if status == http.StatusInternalServerError {
+			s.logger.Error("chat completion failed", "error", err)
+			s.writeOpenAIError(w, status, openAIServerError, "", "failed to start the chat")
+			return
+		}
+		code := ""
+		if status == http.StatusNotFound {
+			code = "model_not_found"
+		}
+		s.writeOpenAIError(w, status, openAIInvalidRequest, code, err.Error())
+		return
+	}
+
+	unlock := s.liveSessions.tryLock(turn.chat.ID)
+	if unlock == nil {
+		s.writeOpenAIError(w, http.StatusConflict, openAIInvalidRequest, "",
+			"the chat is busy, wait for the current message to complete")
+		return
+	}
+	defer unlock()
+
+	auditor := s.chatToolAuditor(r.Context(), turn.chat.ID)
+	agentSession, chatSession, err := s.chatSvc.BeginMessage(r.Context(), turn.chat.ID, turn.prompt, agent.RunOptions{
+		PermissionHandler: s.completionPermissionHandler(r.Context(), turn.agentCfg, turn.chat.ID),
+		Audit:             auditor,
+	})
+	if err != nil {
+		s.logger.Error("begin message failed", "session_id", turn.chat.ID, "error", err)
+		s.writeOpenAIError(w, http.StatusInternalServerError, openAIServerError, "", "failed to start the agent")
+		return
+	}
+
+	_, execSpan := otel.Tracer("agento").Start(r.Context(), "chat.agent_execution")
+	execSpan.SetAttributes(
+		attribute.String("chat.session_id", turn.chat.ID),
+		attribute.String("chat.agent_slug", chatSession.AgentSlug),
+	)
+	w.Header().Set(HeaderChatID, turn.chat.ID)
+
+	state := s.runCompletion(w, r, turn, agentSession, execSpan, auditor)
+	last := string(turn.req.Messages[len(turn.req.Messages)-1].Content)
+	isFirstMessage := chatSession.Title == "New Chat"
+	if isFirstMessage {
+		chatSession.Title = truncateTitle(last, 60)
+	}
+	state.userContent = turn.prompt
+	s.commitMessage(execSpan, chatSession, state, isFirstMessage, turn.chat.ID)
+}
+
+// prepareCompletion validates a chat completion request and finds the chat it
+// continues. On error it returns the HTTP status to answer with.
+func (s *Server) prepareCompletion(r *http.Request) (*completionTurn, int, error) {
+	var req chatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err)
+	}
+	if req.Model == "" {
+		return nil, http.StatusBadRequest, errors.New("model is required: use an agent slug from /v1/models")
+	}
+	n := len(req.Messages)
+	if n == 0 || req.Messages[n-1].Role != "user" || strings.TrimSpace(string(req.Messages[n-1].Content)) == "" {
+		return nil, http.StatusBadRequest, errors.New("messages must end with a non-empty user message")
+	}
+
+	agentCfg, err := s.agentSvc.Get(r.Context(), req.Model)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("loading agent: %w", err)
+	}
+	if agentCfg == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("the model %q does not exist: models are agent slugs", req.Model)
+	}
+	if agentCfg.Capabilities.MayRequireApproval() && s.approvals == nil {
+		return nil, http.StatusForbidden, fmt.Errorf("agent %q gates tools on approval, "+
+			"and nobody can be asked here", req.Model)
+	}
+
+	turn := &completionTurn{req: req, agentCfg: agentCfg}
+	status, err := s.resolveCompletionChat(r, turn)
+	if err != nil {
+		return nil, status, err
+	}
+	return turn, http.StatusOK, nil
+}
+
+// resolveCompletionChat sets turn's chat and prompt. A chat is continued when
+// the request names it, or when its history is one this server answered;
+// otherwise a new chat is started and given the history to read.
+func (s *Server) resolveCompletionChat(r *http.Request, turn *completionTurn) (int, error) {
+	ctx := r.Context()
+	messages := turn.req.Messages
+	last := string(messages[len(messages)-1].Content)
+
+	chatID := r.Header.Get(HeaderChatID)
+	if chatID == "" && len(messages) > 1 {
+		chatID, _ = s.conversations.get(conversationKey(turn.agentCfg.Slug, messages[:len(messages)-1]))
+	}
+	if chatID != "" {
+		chat, err := s.chatSvc.GetSession(ctx, chatID)
+		if err != nil {
+			return http.StatusInternalServerError, fmt.Errorf("loading chat: %w", err)
+		}
+		if chat != nil && chat.AgentSlug != turn.agentCfg.Slug {
+			return http.StatusBadRequest, fmt.Errorf("chat %q is with agent %q, not %q",
+				chatID, chat.AgentSlug, turn.agentCfg.Slug)
+		}
+		if chat != nil {
+			turn.chat, turn.prompt = chat, last
+			return http.StatusOK, nil
+		}
+		if r.Header.Get(HeaderChatID) != "" {
+			return http.StatusNotFound, fmt.Errorf("chat %q not found", chatID)
+		}
+	}
+
+	chat, err := s.chatSvc.CreateSession(ctx, turn.agentCfg.Slug, "", "", "")
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("creating chat: %w", err)
+	}
+	turn.chat, turn.prompt = chat, foldConversation(messages)
+	return http.StatusOK, nil
+}
+
+// completionPermissionHandler returns the approval broker's handler for an
+// agent that gates tools, and nil for one that does not: nobody is watching
+// an API client's run to answer.
+func (s *Server) completionPermissionHandler(
+	ctx context.Context, agentCfg *config.AgentConfig, chatID string,
+) claude.PermissionHandler {
+	if s.approvals == nil {
+		return nil
+	}
+	return s.approvals.PermissionHandler(ctx, agentCfg, approval.Run{
+		Source:        audit.SourceChat,
+		AgentSlug:     agentCfg.Slug,
+		ChatSessionID: chatID,
+		Title:         "API chat with " + agentCfg.Name,
+	})
+}
+
+// runCompletion answers the request from the agent session, as SSE chunks when
+// it asked to stream, and returns what the turn produced for committing.
+func (s *Server) runCompletion(
+	w http.ResponseWriter, r *http.Request, turn *completionTurn,
+	agentSession *claude.Session, execSpan trace.Span, auditor agent.ToolAuditor,
+) streamState {
+	s.liveSessions.put(turn.chat.ID, &liveSession{session: agentSession})
+	defer func() {
+		s.liveSessions.delete(turn.chat.ID)
+		if cerr := agentSession.Close(); cerr != nil {
+			s.logger.Error("close agent session", "id", turn.chat.ID, "error", cerr)
+		}
+	}()
+
+	c := &completionCollector{
+		ctx:          r.Context(),
+		agentSession: agentSession,
+		execSpan:     execSpan,
+		auditor:      auditor,
+	}
+	var answer string
+	var err error
+	if turn.req.Stream {
+		answer, err = s.streamCompletion(w, turn, c)
+	} else {
+		c.run()
+		answer, err = c.state.assistantText, c.err
+		s.writeCompletion(w, turn, c, err)
+	}
+	if err == nil && answer != "" {
+		history := append(slices.Clone(turn.req.Messages), openAIMessage{Role: "assistant", Content: openAIContent(answer)})
+		s.conversations.put(conversationKey(turn.agentCfg.Slug, history), turn.chat.ID)
+	}
+	return c.state
+}
+
+// newCompletion returns a chat.completion or chunk for turn with no choices.
+func newCompletion(turn *completionTurn, id, object string) chatCompletion {
+	return chatCompletion{
+		ID:      id,
+		Object:  object,
+		Created: time.Now().Unix(),
+		Model:   turn.agentCfg.Slug,
+		Choices: []completionChoice{},
+	}
+}
+
+func (s *Server) writeCompletion(w http.ResponseWriter, turn *completionTurn, c *completionCollector, err error) {
+	if err != nil {
+		s.logger.Error("chat completion failed", "session_id", turn.chat.ID, "error", err)
+		s.writeOpenAIError(w, http.StatusBadGateway, openAIServerError, "", err.Error())
+		return
+	}
+	stop := "stop"
+	resp := newCompletion(turn, "chatcmpl-"+uuid.NewString(), "chat.completion")
+	resp.Choices = append(resp.Choices, completionChoice{
+		Message:      &completionMessage{Role: "assistant", Content: c.state.assistantText},
+		FinishReason: &stop,
+	})
+	resp.Usage = newCompletionUsage(c.state.tokens.toUsageStats())
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// streamCompletion sends the agent's text as it is written, in
+// chat.completion.chunk events ending with [DONE], and returns the text sent.
+// The text includes what the agent says between tool calls, which the
+// non-streaming answer, being the agent's final message, leaves out.
+func (s *Server) streamCompletion(
+	w http.ResponseWriter, turn *completionTurn, c *completionCollector,
+) (string, error) {
+	w.Header().Set(headerContentType, "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	id := "chatcmpl-" + uuid.NewString()
+	chunk := func(delta *completionMessage, finish *string) chatCompletion {
+		out := newCompletion(turn, id, "chat.completion.chunk")
+		out.Choices = append(out.Choices, completionChoice{Delta: delta, FinishReason: finish})
+		return out
+	}
+
+	var sent strings.Builder
+	c.onText = func(text string) {
+		sent.WriteString(text)
+		s.sendSSEData(w, flusher, chunk(&completionMessage{Content: text}, nil))
+	}
+	s.sendSSEData(w, flusher, chunk(&completionMessage{Role: "assistant"}, nil))
+	c.run()
+	if c.err != nil {
+		s.logger.Error("chat completion failed", "session_id", turn.chat.ID, "error", c.err)
+		s.sendSSEData(w, flusher, map[string]any{"error": map[string]string{
+			"message": c.err.Error(), "type": openAIServerError,
+		}})
+		return sent.String(), c.err
+	}
+	// Without partial messages nothing was streamed; send the answer whole.
+	if sent.Len() == 0 && c.state.assistantText != "" {
+		c.onText(c.state.assistantText)
+	}
+
+	stop := "stop"
+	s.sendSSEData(w, flusher, chunk(&completionMessage{}, &stop))
+	if turn.req.StreamOptions != nil && turn.req.StreamOptions.IncludeUsage {
+		usage := newCompletion(turn, id, "chat.completion.chunk")
+		usage.Usage = newCompletionUsage(c.state.tokens.toUsageStats())
+		s.sendSSEData(w, flusher, usage)
+	}
+	writeSSEDone(w, flusher)
+	return sent.String(), nil
+}
+
+// sendSSEData writes data as an unnamed SSE event, the form OpenAI clients read.
+func (s *Server) sendSSEData(w http.ResponseWriter, flusher http.Flusher, data any) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		s.logger.Error("sendSSEData: failed to marshal data", "error", err)
+		return
+	}
+	if _, err := fmt.Fprintf(w, "data: %s\n\n", b); err != nil {
+		return
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+func writeSSEDone(w http.ResponseWriter, flusher http.Flusher) {
+	if _, err := w.Write([]byte("data: [DONE]\n\n")); err != nil {
+		return
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// completionCollector consumes one turn of an agent session for a chat
+// completion. Unlike the chat UI's eventProcessor it has nobody to ask: a
+// question from the agent is answered by the permission handler, if at all.
+type completionCollector struct {
+	ctx          context.Context
+	agentSession *claude.Session
+	execSpan     trace.Span
+	auditor      agent.ToolAuditor
+	// onText, when set, receives the agent's top-level text as it streams.
+	onText func(string)
+
+	state      streamState
+	err        error
+	wroteText  bool
+	newMessage bool
+}
+
+// run consumes events until the turn's result, or until the session or the
+// request ends, leaving what it collected in c.state and c.err.
+func (c *completionCollector) run() {
+	c.state = streamState{toolSpans: make(map[string]agent.ToolSpanEntry)}
+	defer agent.FlushToolSpans(c.ctx, c.state.toolSpans, c.auditor)
+
+	events := c.agentSession.Events()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				c.err = errors.New("the agent stopped without answering")
+				return
+			}
+			if c.handle(event) {
+				return
+			}
+		case <-c.ctx.Done():
+			c.err = c.ctx.Err()
+			return
+		}
+	}
+}
+
+// handle processes one event and reports whether it ended the turn.
+func (c *completionCollector) handle(event claude.Event) bool {
+	switch event.Type {
+	case claude.TypeStreamEvent:
+		c.handleStreamEvent(event.StreamEvent)
+	case claude.TypeAssistant:
+		c.state.blocks = appendAssistantBlocks(c.state.blocks, event.Raw)
+		agent.OpenToolSpans(c.ctx, c.execSpan, event.Raw, c.state.toolSpans)
+		c.newMessage = c.wroteText
+	case claude.TypeSystem:
+		agent.AddSystemInitEvent(c.execSpan, event.System)
+	case claude.TypeToolProgress:
+		agent.RecordToolProgress(event.ToolProgress, c.state.toolSpans)
+	case agent.MessageTypeUser:
+		agent.CloseToolSpans(c.ctx, event.Raw, c.state.toolSpans, c.auditor)
+	case claude.TypeResult:
+		return c.handleResult(event)
+	}
+	return false
+}
+
+// handleStreamEvent passes on the text deltas of the agent's own messages,
+// not a subagent's, separating one message from the next.
+func (c *completionCollector) handleStreamEvent(se *claude.StreamEventMessage) {
+	if c.onText == nil || se == nil || se.ParentToolUseID != nil {
+		return
+	}
+	if se.Event.Delta == nil || se.Event.Delta.Type != "text_delta" || se.Event.Delta.Text == "" {
+		return
+	}
+	if c.newMessage {
+		c.onText("\n\n")
+		c.newMessage = false
+	}
+	c.onText(se.Event.Delta.Text)
+	c.wroteText = true
+}
+
+func (c *completionCollector) handleResult(event claude.Event) bool {
+	if event.Result == nil {
+		return false
+	}
+	agent.EnrichSpanFromResult(c.execSpan, event.Result, event.Raw)
+	c.state.tokens.add(event.Result)
+	if event.Result.SessionID != "" {
+		c.state.sdkSessionID = event.Result.SessionID
+	}
+	if event.Result.IsError {
+		msg := event.Result.Result
+		if msg == "" {
+			msg = strings.Join(event.Result.Errors, "; ")
+		}
+		c.err = fmt.Errorf("agent error: %s", msg)
+		return true
+	}
+	c.state.assistantText = event.Result.Result
+	return true
+}
+
+// foldConversation turns a conversation a new chat did not see into one
+// message, so the agent answers the last message knowing what came before.
+func foldConversation(messages []openAIMessage) string {
+	last := string(messages[len(messages)-1].Content)
+	if len(messages) == 1 {
+		return last
+	}
+	var b strings.Builder
+	b.WriteString("The conversation so far:\n\n")
+	for _, m := range messages[:len(messages)-1] {
+		fmt.Fprintf(&b, "%s: %s\n\n", m.Role, m.Content)
+	}
+	b.WriteString("Reply to this message:\n\n")
+	b.WriteString(last)
+	return b.String()
+}
+
+// conversationKey identifies a message history. Whitespace at the ends of a
+// message is ignored, since clients trim what they display and send back.
+func conversationKey(agentSlug string, messages []openAIMessage) string {
+	h := sha256.New()
+	h.Write([]byte(agentSlug))
+	for _, m := range messages {
+		h.Write([]byte{0})
+		h.Write([]byte(m.Role))
+		h.Write([]byte{0})
+		h.Write([]byte(strings.TrimSpace(string(m.Content))))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// conversationIndex maps the message history of each answered completion to
+// its chat, so a client that sends the history back with a new message
+// continues the chat instead of starting another. OpenAI clients keep no
+// conversation ID, so the history is all there is to go by. The index is in
+// memory and bounded; losing an entry costs a new chat, not the conversation.
+type conversationIndex struct {
+	mu    sync.Mutex
+	chats map[string]string
+	order []string
+}
+
+func newConversationIndex() *conversationIndex {
+	return &conversationIndex{chats: make(map[string]string)}
+}
+
+func (c *conversationIndex) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, ok := c.chats[key]
+	return id, ok
+}
+
+func (c *conversationIndex) put(key, chatID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.chats[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.chats[key] = chatID
+	for len(c.order) > maxIndexedConversations {
+		delete(c.chats, c.order[0])
+		c.order = c.order[1:]
+	}
+}
@@ -0,0 +1,111 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/api"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func TestListModels(t *testing.T) {
+	h := newHarness(t)
+	h.agentSvc.On("List", mock.Anything).Return([]*config.AgentConfig{{Slug: "reviewer", Name: "Reviewer"}}, nil)
+
+	w := h.do(httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Object string `json:"object"`
+		Data   []struct {
+			ID     string `json:"id"`
+			Object string `json:"object"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "list", body.Object)
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, "reviewer", body.Data[0].ID)
+	assert.Equal(t, "model", body.Data[0].Object)
+}
+
+func TestChatCompletion_Refusals(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		chatID     string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "no messages",
+			body:       `{"model":"reviewer","messages":[]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "last message is not the user's",
+			body:       `{"model":"reviewer","messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown model",
+			body:       `{"model":"missing","messages":[{"role":"user","content":"hi"}]}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "model_not_found",
+		},
+		{
+			name:       "gated agent without an approval broker",
+			body:       `{"model":"deployer","messages":[{"role":"user","content":"ship it"}]}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "chat with another agent",
+			body:       `{"model":"reviewer","messages":[{"role":"user","content":"hi"}]}`,
+			chatID:     "chat-1",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newHarness(t)
+			h.agentSvc.On("Get", mock.Anything, "reviewer").Return(&config.AgentConfig{Slug: "reviewer"}, nil)
+			h.agentSvc.On("Get", mock.Anything, "missing").Return(nil, nil)
+			h.agentSvc.On("Get", mock.Anything, "deployer").Return(&config.AgentConfig{
+				Slug:         "deployer",
+				Capabilities: config.AgentCapabilities{RequireApproval: []string{"Bash"}},
+			}, nil)
+			h.chatSvc.On("GetSession", mock.Anything, "chat-1").Return(
+				&storage.ChatSession{ID: "chat-1", AgentSlug: "writer"}, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.chatID != "" {
+				req.Header.Set(api.HeaderChatID, tc.chatID)
+			}
+			w := h.do(req)
+			assert.Equal(t, tc.wantStatus, w.Code)
+
+			var body struct {
+				Error struct {
+					Message string `json:"message"`
+					Type    string `json:"type"`
+					Code    string `json:"code"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, "invalid_request_error", body.Error.Type)
+			assert.NotEmpty(t, body.Error.Message)
+			assert.Equal(t, tc.wantCode, body.Error.Code)
+			h.chatSvc.AssertNotCalled(t, "CreateSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
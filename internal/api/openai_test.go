@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+)
+
+func TestOpenAIContent_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "string", raw: `"hello"`, want: "hello"},
+		{name: "null", raw: `null`, want: ""},
+		{
+			name: "parts keep only text",
+			raw:  `[{"type":"text","text":"one"},{"type":"image_url","image_url":{"url":"x"}},{"type":"text","text":"two"}]`,
+			want: "one\ntwo",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var c openAIContent
+			require.NoError(t, json.Unmarshal([]byte(tc.raw), &c))
+			assert.Equal(t, tc.want, string(c))
+		})
+	}
+
+	var c openAIContent
+	assert.Error(t, json.Unmarshal([]byte(`42`), &c))
+}
+
+func TestFoldConversation(t *testing.T) {
+	assert.Equal(t, "hi", foldConversation([]openAIMessage{{Role: "user", Content: "hi"}}))
+
+	folded := foldConversation([]openAIMessage{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "What is 2+2?"},
+		{Role: "assistant", Content: "4"},
+		{Role: "user", Content: "And times 3?"},
+	})
+	assert.Equal(t, "The conversation so far:\n\n"+
+		"system: Be brief.\n\nuser: What is 2+2?\n\nassistant: 4\n\n"+
+		"Reply to this message:\n\nAnd times 3?", folded)
+}
+
+func TestConversationKey(t *testing.T) {
+	history := []openAIMessage{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello\n"}}
+	trimmed := []openAIMessage{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}}
+
+	assert.Equal(t, conversationKey("a", history), conversationKey("a", trimmed),
+		"clients trim what they send back")
+	assert.NotEqual(t, conversationKey("a", history), conversationKey("b", history))
+	assert.NotEqual(t, conversationKey("a", history), conversationKey("a", history[:1]))
+	assert.NotEqual(t,
+		conversationKey("a", []openAIMessage{{Role: "user", Content: "ab"}}),
+		conversationKey("a", []openAIMessage{{Role: "user", Content: "a"}, {Role: "user", Content: "b"}}))
+}
+
+func TestConversationIndex_EvictsOldest(t *testing.T) {
+	idx := newConversationIndex()
+	for i := 0; i <= maxIndexedConversations; i++ {
+		idx.put(fmt.Sprint(i), fmt.Sprint("chat-", i))
+	}
+	_, ok := idx.get("0")
+	assert.False(t, ok)
+	id, ok := idx.get(fmt.Sprint(maxIndexedConversations))
+	assert.True(t, ok)
+	assert.Equal(t, fmt.Sprint("chat-", maxIndexedConversations), id)
+
+	idx.put("1", "chat-x")
+	assert.Len(t, idx.order, maxIndexedConversations, "updating an entry does not grow the index")
+}
+
+func TestNewCompletionUsage(t *testing.T) {
+	u := newCompletionUsage(agent.UsageStats{
+		InputTokens: 10, CacheCreationInputTokens: 20, CacheReadInputTokens: 300, OutputTokens: 40,
+	})
+	assert.Equal(t, 330, u.PromptTokens)
+	assert.Equal(t, 40, u.CompletionTokens)
+	assert.Equal(t, 370, u.TotalTokens)
+	assert.Equal(t, 300, u.PromptTokensDetails.CachedTokens)
+}
+
+func textDelta(text string, parent *string) claude.Event {
+	return claude.Event{
+		Type: claude.TypeStreamEvent,
+		StreamEvent: &claude.StreamEventMessage{
+			Event:           claude.StreamEvent{Delta: &claude.StreamEventDelta{Type: "text_delta", Text: text}},
+			ParentToolUseID: parent,
+		},
+	}
+}
+
+func TestCompletionCollector_Handle(t *testing.T) {
+	var streamed string
+	ctx := context.Background()
+	span := trace.SpanFromContext(ctx)
+	c := &completionCollector{ctx: ctx, execSpan: span, onText: func(s string) { streamed += s }}
+	c.state.toolSpans = make(map[string]agent.ToolSpanEntry)
+	subagent := "toolu_1"
+
+	assert.False(t, c.handle(textDelta("Let me look.", nil)))
+	assert.False(t, c.handle(claude.Event{
+		Type: claude.TypeAssistant,
+		Raw:  json.RawMessage(`{"message":{"content":[{"type":"text","text":"Let me look."}]}}`),
+	}))
+	assert.False(t, c.handle(textDelta("ignored", &subagent)))
+	assert.False(t, c.handle(textDelta("Found it.", nil)))
+	assert.Equal(t, "Let me look.\n\nFound it.", streamed,
+		"messages are separated and a subagent's text is left out")
+
+	assert.True(t, c.handle(claude.Event{Type: claude.TypeResult, Result: &claude.Result{
+		Result: "Found it.", SessionID: "sdk-1", Usage: claude.Usage{InputTokens: 5, OutputTokens: 7},
+	}}))
+	require.NoError(t, c.err)
+	assert.Equal(t, "Found it.", c.state.assistantText)
+	assert.Equal(t, "sdk-1", c.state.sdkSessionID)
+	assert.Equal(t, 7, c.state.tokens.OutputTokens)
+	assert.Len(t, c.state.blocks, 1)
+
+	c = &completionCollector{ctx: ctx, execSpan: span}
+	assert.True(t, c.handle(claude.Event{Type: claude.TypeResult, Result: &claude.Result{
+		IsError: true, Errors: []string{"overloaded"},
+	}}))
+	assert.EqualError(t, c.err, "agent error: overloaded")
+}
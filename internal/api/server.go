@@ -10,6 +10,7 @@ import (
 
 	"github.com/shaharia-lab/agento/internal/claudesessions"
 	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/export"
 	whatsappintegration "github.com/shaharia-lab/agento/internal/integrations/whatsapp"
 	"github.com/shaharia-lab/agento/internal/service"
 	"github.com/shaharia-lab/agento/internal/telemetry"
@@ -32,6 +33,7 @@ const (
 	routeJobHistoryBase  = "/job-history"
 	routeJobHistoryByID  = routeJobHistoryBase + "/{id}"
 	routePricingRates    = "/pricing/rates"
+	routeBudgetByID      = "/budgets/{id}"
 )
 
 // ServerConfig bundles all dependencies needed to construct an API Server.
@@ -44,6 +46,7 @@ type ServerConfig struct {
 	TriggerSvc         service.TriggerService
 	ProfileSvc         service.ClaudeSettingsProfileService
 	PricingSvc         service.PricingService
+	BudgetSvc          service.BudgetService
 	SettingsMgr        *config.SettingsManager
 	AppConfig          *config.AppConfig
 	Logger             *slog.Logger
@@ -51,6 +54,18 @@ type ServerConfig struct {
 	MonitoringMgr      *telemetry.MonitoringManager
 	InsightStore       claudesessions.InsightStorer
 	WhatsAppPairingMgr *whatsappintegration.PairingManager
+	// Approvals is optional. Without it the approval list is empty, and
+	// agents that gate tools cannot be run through /v1/chat/completions.
+	Approvals ApprovalBroker
+	// ToolCalls is optional. Without it chat turns are not audited and the
+	// tool call list is empty.
+	ToolCalls ToolCallLog
+	// Auth is optional. Without it authentication reports as off and no API
+	// tokens can be created.
+	Auth AuthManager
+	// Backups is optional. Without it the backup list is empty and no backup
+	// can be made from the UI.
+	Backups BackupManager
 }
 
 // Server holds all dependencies for the REST API handlers.
@@ -63,6 +78,7 @@ type Server struct {
 	triggerSvc         service.TriggerService
 	profileSvc         service.ClaudeSettingsProfileService
 	pricingSvc         service.PricingService
+	budgetSvc          service.BudgetService
 	settingsMgr        *config.SettingsManager
 	appConfig          *config.AppConfig
 	logger             *slog.Logger
@@ -72,6 +88,12 @@ type Server struct {
 	monitoringMgr      *telemetry.MonitoringManager
 	insightStore       claudesessions.InsightStorer
 	whatsappPairingMgr *whatsappintegration.PairingManager
+	exporter           *export.Exporter
+	approvals          ApprovalBroker
+	toolCalls          ToolCallLog
+	auth               AuthManager
+	backups            BackupManager
+	conversations      *conversationIndex
 }
 
 // New creates a new API Server backed by the provided services.
@@ -88,6 +110,7 @@ func New(cfg ServerConfig) *Server {
 		triggerSvc:         cfg.TriggerSvc,
 		profileSvc:         cfg.ProfileSvc,
 		pricingSvc:         cfg.PricingSvc,
+		budgetSvc:          cfg.BudgetSvc,
 		settingsMgr:        cfg.SettingsMgr,
 		appConfig:          cfg.AppConfig,
 		logger:             cfg.Logger,
@@ -96,6 +119,12 @@ func New(cfg ServerConfig) *Server {
 		monitoringMgr:      cfg.MonitoringMgr,
 		insightStore:       cfg.InsightStore,
 		whatsappPairingMgr: cfg.WhatsAppPairingMgr,
+		exporter:           newExporter(cfg),
+		approvals:          cfg.Approvals,
+		toolCalls:          cfg.ToolCalls,
+		auth:               cfg.Auth,
+		backups:            cfg.Backups,
+		conversations:      newConversationIndex(),
 	}
 }
 
@@ -175,6 +204,20 @@ func (s *Server) mountExtensionRoutes(r chi.Router) {
 	// Scheduled tasks and job history
 	s.mountTaskRoutes(r)
 
+	// Budget guardrails
+	s.mountBudgetRoutes(r)
+
+	// Approvals of gated tool calls in unattended runs
+	s.mountApprovalRoutes(r)
+
+	// Tool call audit log
+	s.mountToolCallRoutes(r)
+
+	// Sign-out and API tokens
+	s.mountAuthRoutes(r)
+
+	// Backups
+	s.mountBackupRoutes(r)
 }
 
 // mountClaudeSessionRoutes registers Claude Code session and analytics routes.
@@ -184,14 +227,22 @@ func (s *Server) mountClaudeSessionRoutes(r chi.Router) {
 	r.Get("/claude-sessions/projects", s.handleListClaudeProjects)
 	r.Post("/claude-sessions/refresh", s.handleRefreshClaudeSessionCache)
 	r.Get("/claude-sessions/status", s.handleGetClaudeSessionStatus)
-	// Insights summary must come before /{id} to avoid chi routing conflicts.
+	r.Get("/claude-sessions/archive", s.handleGetClaudeSessionArchive)
+	r.Get("/claude-sessions/live", s.handleListLiveClaudeSessions)
+	r.Get("/claude-sessions/usage-windows", s.handleGetClaudeUsageWindows)
+	r.Post("/claude-sessions/hooks", s.handleClaudeSessionHook)
+	// Insights summary, compare and search must come before /{id} to avoid chi routing conflicts.
 	r.Get("/claude-sessions/insights/summary", s.handleGetClaudeSessionInsightsSummary)
+	r.Get("/claude-sessions/compare", s.handleCompareClaudeSessions)
+	r.Get("/claude-sessions/search", s.handleSearchClaudeSessions)
 	r.Get("/claude-sessions/{id}", s.handleGetClaudeSession)
 	r.Patch("/claude-sessions/{id}", s.handleUpdateClaudeSession)
 	r.Post("/claude-sessions/{id}/continue", s.handleContinueClaudeSession)
 	r.Get("/claude-sessions/{id}/insights", s.handleGetClaudeSessionInsights)
 	r.Get("/claude-sessions/{id}/journey", s.handleGetClaudeSessionJourney)
+	r.Get("/claude-sessions/{id}/tail", s.handleTailClaudeSession)
 	r.Get("/claude-analytics", s.handleGetClaudeAnalytics)
+	r.Get("/export/{dataset}", s.handleExport)
 }
 
 // mountIntegrationRoutes registers integration-related routes.
@@ -217,6 +268,7 @@ func (s *Server) mountIntegrationRoutes(r chi.Router) {
 	r.Post(routeIntegrationByID+"/triggers", s.handleCreateTriggerRule)
 	r.Put(routeIntegrationByID+"/triggers/{rid}", s.handleUpdateTriggerRule)
 	r.Delete(routeIntegrationByID+"/triggers/{rid}", s.handleDeleteTriggerRule)
+	r.Post(routeIntegrationByID+"/triggers/{rid}/regenerate-secret", s.handleRegenerateTriggerRuleSecret)
 
 	// Webhook management
 	r.Post(routeIntegrationByID+"/webhook/register", s.handleRegisterWebhook)
@@ -249,6 +301,17 @@ func (s *Server) mountTaskRoutes(r chi.Router) {
 	r.Delete(routeJobHistoryByID, s.handleDeleteJobHistory)
 }
 
+// mountBudgetRoutes registers budget policy routes. /budgets/status must come
+// before /budgets/{id} to avoid chi routing conflicts.
+func (s *Server) mountBudgetRoutes(r chi.Router) {
+	r.Get("/budgets", s.handleListBudgets)
+	r.Post("/budgets", s.handleCreateBudget)
+	r.Get("/budgets/status", s.handleGetBudgetStatus)
+	r.Get(routeBudgetByID, s.handleGetBudget)
+	r.Put(routeBudgetByID, s.handleUpdateBudget)
+	r.Delete(routeBudgetByID, s.handleDeleteBudget)
+}
+
 // mountPricingRoutes registers the model pricing catalog endpoints.
 //
 // Adding and correcting a rate are deliberately separate endpoints rather than
@@ -38,10 +38,21 @@ func (s *Server) handleGetSettings(w http.ResponseWriter, _ *http.Request) {
 // Adding a config dir is the same class as changing the threshold rather than
 // as hiding a project: there are no cached rows to filter, because that dir has
 // never been walked. Removing one is the filter case and needs no scan.
-func (s *Server) applyDataSettings(previousIdleGap int, previousDirs []string) {
+// Importing another coding agent's sessions is the same: an agent newly
+// imported has never been walked, and one no longer imported is filtered.
+//
+// The transcript archive settings wait for the next scan, which is when the
+// archive copies and prunes anyway. The usage window limits are read whenever
+// the windows are, and need nothing.
+func (s *Server) applyDataSettings(previousIdleGap int, previousDirs, previousAgents []string) {
 	current := s.settingsMgr.Get()
 	claudesessions.ApplyDataSettings(current.IdleGapThresholdMinutes, current.HiddenProjects)
 	config.ApplyClaudeDirs(current.ClaudeConfigDir, current.ClaudeConfigDirs)
+	claudesessions.ApplyImportedAgents(current.ImportedAgents)
+	claudesessions.ApplyArchiveSettings(current.TranscriptArchiveDisabled,
+		current.TranscriptArchiveRetentionDays, current.TranscriptArchiveMaxMB)
+	claudesessions.ApplyUsageWindowSettings(current.UsageLimitFiveHourUSD,
+		current.UsageLimitWeeklyUSD, current.UsageWarnPercent)
 
 	if s.claudeSessionCache == nil {
 		return
@@ -62,6 +73,12 @@ func (s *Server) applyDataSettings(previousIdleGap int, previousDirs []string) {
 		s.logger.Info("claude sessions: config dirs changed; indexing",
 			"from", previousDirs, "to", claudesessions.ClaudeHomes())
 		s.claudeSessionCache.EnsureScan()
+		return
+	}
+	if !slices.Equal(previousAgents, claudesessions.ImportedAgents()) {
+		s.logger.Info("claude sessions: imported agents changed; indexing",
+			"from", previousAgents, "to", claudesessions.ImportedAgents())
+		s.claudeSessionCache.EnsureScan()
 	}
 }
 
@@ -74,13 +91,14 @@ func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 
 	previousIdleGap := s.settingsMgr.Get().IdleGapThresholdMinutes
 	previousDirs := claudesessions.ClaudeHomes()
+	previousAgents := claudesessions.ImportedAgents()
 
 	if err := s.settingsMgr.Update(incoming); err != nil {
 		s.writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	s.applyDataSettings(previousIdleGap, previousDirs)
+	s.applyDataSettings(previousIdleGap, previousDirs, previousAgents)
 
 	s.writeJSON(w, http.StatusOK, settingsResponse{
 		Settings:     s.settingsMgr.Get(),
@@ -38,6 +38,8 @@ func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 		Status:         req.Status,
 		TimeoutMinutes: req.TimeoutMinutes,
 		SaveOutput:     req.SaveOutput,
+		Steps:          req.Steps,
+		Retry:          req.Retry,
 	}
 
 	created, err := s.taskSvc.CreateTask(r.Context(), task)
@@ -79,6 +81,8 @@ func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 		Status:         req.Status,
 		TimeoutMinutes: req.TimeoutMinutes,
 		SaveOutput:     req.SaveOutput,
+		Steps:          req.Steps,
+		Retry:          req.Retry,
 	}
 
 	updated, err := s.taskSvc.UpdateTask(r.Context(), id, task)
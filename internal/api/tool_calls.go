@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/audit"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// ToolCallLog is the tool call audit log: it hands out the auditor for each
+// chat turn and lists what was logged. *audit.Recorder satisfies it.
+type ToolCallLog interface {
+	ForRun(run audit.Run) agent.ToolAuditor
+	List(ctx context.Context, filter storage.ToolCallFilter) ([]*storage.ToolCall, error)
+}
+
+// maxToolCallLimit caps ?limit= on the tool call list.
+const maxToolCallLimit = 1000
+
+// mountToolCallRoutes registers the tool call audit log routes.
+func (s *Server) mountToolCallRoutes(r chi.Router) {
+	r.Get("/tool-calls", s.handleListToolCalls)
+}
+
+// handleListToolCalls returns logged tool calls, newest first.
+//
+// Query params (all optional):
+//
+//	agent    agent slug
+//	source   chat, scheduled_task, trigger or mcp
+//	tool     tool name, e.g. Edit or mcp__github__merge_pr
+//	status   success, error, denied or interrupted
+//	job_id   job history ID of a task run
+//	chat_id  chat session ID
+//	q        text the call's input contains, e.g. a file name
+//	since    YYYY-MM-DD or RFC3339, inclusive
+//	until    YYYY-MM-DD or RFC3339, inclusive of a whole day when a date
+//	tz       IANA timezone bare dates are read in (default: UTC)
+//	limit    at most this many calls (default 100, max 1000)
+func (s *Server) handleListToolCalls(w http.ResponseWriter, r *http.Request) {
+	filter, msg := parseToolCallFilter(r)
+	if msg != "" {
+		s.writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if s.toolCalls == nil {
+		s.writeJSON(w, http.StatusOK, []*storage.ToolCall{})
+		return
+	}
+	calls, err := s.toolCalls.List(r.Context(), filter)
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, calls)
+}
+
+// parseToolCallFilter reads the list filter from the query string. It returns
+// a message for the client when a parameter is invalid.
+func parseToolCallFilter(r *http.Request) (storage.ToolCallFilter, string) {
+	q := r.URL.Query()
+	filter := storage.ToolCallFilter{
+		AgentSlug:     q.Get("agent"),
+		Source:        q.Get("source"),
+		ToolName:      q.Get("tool"),
+		JobID:         q.Get("job_id"),
+		ChatSessionID: q.Get("chat_id"),
+		Status:        storage.ToolCallStatus(q.Get("status")),
+		Query:         q.Get("q"),
+		Limit:         100,
+	}
+	loc := parseTimezone(q.Get("tz"))
+
+	if raw := q.Get("since"); raw != "" {
+		t, err := parseAnalyticsDate(raw, loc)
+		if err != nil {
+			return filter, "invalid since"
+		}
+		filter.Since = t
+	}
+	if raw := q.Get("until"); raw != "" {
+		t, err := parseAnalyticsDate(raw, loc)
+		if err != nil {
+			return filter, "invalid until"
+		}
+		if _, rfcErr := time.Parse(time.RFC3339, raw); rfcErr != nil {
+			// A bare date names the whole day; the filter's end is exclusive.
+			t = t.AddDate(0, 0, 1)
+		}
+		filter.Until = t
+	}
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			return filter, "invalid limit"
+		}
+		filter.Limit = min(n, maxToolCallLimit)
+	}
+	return filter, ""
+}
+
+// chatToolAuditor returns the auditor for a turn of chat session id, or nil
+// when there is no audit log. A session that cannot be loaded gets none
+// either; BeginMessage reports why.
+func (s *Server) chatToolAuditor(ctx context.Context, id string) agent.ToolAuditor {
+	if s.toolCalls == nil {
+		return nil
+	}
+	session, err := s.chatSvc.GetSession(ctx, id)
+	if err != nil || session == nil {
+		return nil
+	}
+	return s.toolCalls.ForRun(audit.Run{
+		Source:        audit.SourceChat,
+		AgentSlug:     session.AgentSlug,
+		ChatSessionID: id,
+	})
+}
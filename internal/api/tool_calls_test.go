@@ -0,0 +1,68 @@
+package api_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/api"
+	"github.com/shaharia-lab/agento/internal/audit"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+type stubToolCallLog struct {
+	filter storage.ToolCallFilter
+}
+
+func (l *stubToolCallLog) ForRun(audit.Run) agent.ToolAuditor { return nil }
+
+func (l *stubToolCallLog) List(_ context.Context, filter storage.ToolCallFilter) ([]*storage.ToolCall, error) {
+	l.filter = filter
+	return []*storage.ToolCall{{ID: 1, ToolName: "Edit"}}, nil
+}
+
+func TestListToolCalls(t *testing.T) {
+	log := &stubToolCallLog{}
+	r := chi.NewRouter()
+	api.New(api.ServerConfig{Logger: slog.Default(), ToolCalls: log}).Mount(r)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tool-calls?"+query, nil))
+		return w
+	}
+
+	w := get("agent=ops&tool=Edit&q=prod.yaml&since=2026-05-04&until=2026-05-10&limit=5000")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"tool_name":"Edit"`)
+	assert.Equal(t, "ops", log.filter.AgentSlug)
+	assert.Equal(t, "Edit", log.filter.ToolName)
+	assert.Equal(t, "prod.yaml", log.filter.Query)
+	assert.Equal(t, time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC), log.filter.Since)
+	assert.Equal(t, time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC), log.filter.Until, "a bare until date covers that day")
+	assert.Equal(t, 1000, log.filter.Limit)
+
+	w = get("until=2026-05-10T12:00:00Z")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC), log.filter.Until.UTC())
+	assert.Equal(t, 100, log.filter.Limit)
+
+	for _, query := range []string{"since=last-week", "until=x", "limit=0"} {
+		assert.Equal(t, http.StatusBadRequest, get(query).Code, query)
+	}
+}
+
+func TestListToolCalls_NoLog(t *testing.T) {
+	h := newHarness(t)
+	w := h.do(httptest.NewRequest(http.MethodGet, "/tool-calls", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+}
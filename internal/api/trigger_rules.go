@@ -38,6 +38,18 @@ func (s *Server) handleCreateTriggerRule(w http.ResponseWriter, r *http.Request)
 		FilterPrefix:   req.FilterPrefix,
 		FilterKeywords: req.FilterKeywords,
 		FilterChatIDs:  req.FilterChatIDs,
+		PromptTemplate: req.PromptTemplate,
+		Variables:      req.Variables,
+		ResponseMode:   req.ResponseMode,
+		CallbackURL:    req.CallbackURL,
+		FilterEvents:   req.FilterEvents,
+		FilterRepos:    req.FilterRepos,
+		FilterLabels:   req.FilterLabels,
+		FilterAuthors:  req.FilterAuthors,
+		FilterBranches: req.FilterBranches,
+
+		Conversation:            req.Conversation,
+		ConversationIdleMinutes: req.ConversationIdleMinutes,
 	}
 
 	created, err := s.triggerSvc.CreateRule(r.Context(), rule)
@@ -77,6 +89,18 @@ func (s *Server) handleUpdateTriggerRule(w http.ResponseWriter, r *http.Request)
 		FilterPrefix:   req.FilterPrefix,
 		FilterKeywords: req.FilterKeywords,
 		FilterChatIDs:  req.FilterChatIDs,
+		PromptTemplate: req.PromptTemplate,
+		Variables:      req.Variables,
+		ResponseMode:   req.ResponseMode,
+		CallbackURL:    req.CallbackURL,
+		FilterEvents:   req.FilterEvents,
+		FilterRepos:    req.FilterRepos,
+		FilterLabels:   req.FilterLabels,
+		FilterAuthors:  req.FilterAuthors,
+		FilterBranches: req.FilterBranches,
+
+		Conversation:            req.Conversation,
+		ConversationIdleMinutes: req.ConversationIdleMinutes,
 	}
 
 	updated, err := s.triggerSvc.UpdateRule(r.Context(), ruleID, rule)
@@ -110,7 +134,33 @@ func (s *Server) handleDeleteTriggerRule(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// handleRegisterWebhook registers a Telegram webhook for the integration.
+// handleRegenerateTriggerRuleSecret replaces the signing secret of a generic
+// webhook rule.
+func (s *Server) handleRegenerateTriggerRuleSecret(w http.ResponseWriter, r *http.Request) {
+	integrationID := chi.URLParam(r, "id")
+	ruleID := chi.URLParam(r, "rid")
+
+	// Verify the rule belongs to this integration.
+	existing, err := s.triggerSvc.GetRule(r.Context(), ruleID)
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	if existing.IntegrationID != integrationID {
+		s.writeError(w, http.StatusForbidden, "rule does not belong to this integration")
+		return
+	}
+
+	rule, err := s.triggerSvc.RegenerateRuleSecret(r.Context(), ruleID)
+	if err != nil {
+		s.httpErr(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, rule)
+}
+
+// handleRegisterWebhook registers a Telegram webhook, or enables inbound GitHub
+// deliveries, for the integration.
 func (s *Server) handleRegisterWebhook(w http.ResponseWriter, r *http.Request) {
 	integrationID := chi.URLParam(r, "id")
 	if err := s.triggerSvc.RegisterWebhook(r.Context(), integrationID); err != nil {
@@ -120,7 +170,7 @@ func (s *Server) handleRegisterWebhook(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, http.StatusOK, map[string]string{"status": "registered"})
 }
 
-// handleDeleteWebhook removes the Telegram webhook for the integration.
+// handleDeleteWebhook removes the webhook for the integration.
 func (s *Server) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
 	integrationID := chi.URLParam(r, "id")
 	if err := s.triggerSvc.DeleteWebhook(r.Context(), integrationID); err != nil {
@@ -39,6 +39,8 @@ type CreateTaskRequest struct {
 	Status         storage.TaskStatus     `json:"status"`
 	TimeoutMinutes int                    `json:"timeout_minutes"`
 	SaveOutput     bool                   `json:"save_output"`
+	Steps          []storage.PipelineStep `json:"steps"`
+	Retry          storage.RetryPolicy    `json:"retry"`
 }
 
 // UpdateTaskRequest is the request body for updating an existing scheduled task.
@@ -54,6 +56,8 @@ type UpdateTaskRequest struct {
 	Status         storage.TaskStatus     `json:"status"`
 	TimeoutMinutes int                    `json:"timeout_minutes"`
 	SaveOutput     bool                   `json:"save_output"`
+	Steps          []storage.PipelineStep `json:"steps"`
+	Retry          storage.RetryPolicy    `json:"retry"`
 }
 
 // ─── Integration request types ────────────────────────────────────────────────
@@ -94,22 +98,58 @@ type BulkDeleteRequest struct {
 
 // CreateTriggerRuleRequest is the request body for creating a new trigger rule.
 type CreateTriggerRuleRequest struct {
-	Name           string   `json:"name"`
-	AgentSlug      string   `json:"agent_slug"`
-	Enabled        bool     `json:"enabled"`
-	FilterPrefix   string   `json:"filter_prefix"`
-	FilterKeywords []string `json:"filter_keywords"`
-	FilterChatIDs  []string `json:"filter_chat_ids"`
+	Name           string            `json:"name"`
+	AgentSlug      string            `json:"agent_slug"`
+	Enabled        bool              `json:"enabled"`
+	FilterPrefix   string            `json:"filter_prefix"`
+	FilterKeywords []string          `json:"filter_keywords"`
+	FilterChatIDs  []string          `json:"filter_chat_ids"`
+	PromptTemplate string            `json:"prompt_template"`
+	Variables      map[string]string `json:"variables"`
+	ResponseMode   string            `json:"response_mode"`
+	CallbackURL    string            `json:"callback_url"`
+	FilterEvents   []string          `json:"filter_events"`
+	FilterRepos    []string          `json:"filter_repos"`
+	FilterLabels   []string          `json:"filter_labels"`
+	FilterAuthors  []string          `json:"filter_authors"`
+	FilterBranches []string          `json:"filter_branches"`
+	// Conversation and ConversationIdleMinutes apply to Telegram rules.
+	Conversation            string `json:"conversation"`
+	ConversationIdleMinutes int    `json:"conversation_idle_minutes"`
 }
 
 // UpdateTriggerRuleRequest is the request body for updating a trigger rule.
 type UpdateTriggerRuleRequest struct {
-	Name           string   `json:"name"`
-	AgentSlug      string   `json:"agent_slug"`
-	Enabled        bool     `json:"enabled"`
-	FilterPrefix   string   `json:"filter_prefix"`
-	FilterKeywords []string `json:"filter_keywords"`
-	FilterChatIDs  []string `json:"filter_chat_ids"`
+	Name           string            `json:"name"`
+	AgentSlug      string            `json:"agent_slug"`
+	Enabled        bool              `json:"enabled"`
+	FilterPrefix   string            `json:"filter_prefix"`
+	FilterKeywords []string          `json:"filter_keywords"`
+	FilterChatIDs  []string          `json:"filter_chat_ids"`
+	PromptTemplate string            `json:"prompt_template"`
+	Variables      map[string]string `json:"variables"`
+	ResponseMode   string            `json:"response_mode"`
+	CallbackURL    string            `json:"callback_url"`
+	FilterEvents   []string          `json:"filter_events"`
+	FilterRepos    []string          `json:"filter_repos"`
+	FilterLabels   []string          `json:"filter_labels"`
+	FilterAuthors  []string          `json:"filter_authors"`
+	FilterBranches []string          `json:"filter_branches"`
+	// Conversation and ConversationIdleMinutes apply to Telegram rules.
+	Conversation            string `json:"conversation"`
+	ConversationIdleMinutes int    `json:"conversation_idle_minutes"`
+}
+
+// ─── Budget request types ─────────────────────────────────────────────────────
+
+// BudgetPolicyRequest is the request body for creating or updating a budget policy.
+type BudgetPolicyRequest struct {
+	Name     string  `json:"name"`
+	Scope    string  `json:"scope"`
+	ScopeID  string  `json:"scope_id"`
+	Period   string  `json:"period"`
+	LimitUSD float64 `json:"limit_usd"`
+	Enabled  bool    `json:"enabled"`
 }
 
 // UpdateProfileRequest is the request body for updating a Claude settings profile.
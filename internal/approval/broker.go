@@ -0,0 +1,361 @@
+// Package approval pauses unattended agent runs on gated tool calls until a
+// person approves or denies them.
+//
+// Scheduled tasks and Telegram triggers have no browser attached to answer a
+// permission prompt, so they used to run with every tool allowed. An agent
+// can now list tools under capabilities.require_approval; when one of its
+// unattended runs calls such a tool, the Broker records an approval request,
+// sends it over the channel configured in the notification settings
+// (Telegram, Slack or email), and holds the call until someone answers, the
+// request times out, or the run ends. Anything but an approval denies the
+// call. Every request and its answer stays in storage.ApprovalStore, which is
+// how job history shows who approved what.
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/notification"
+	"github.com/shaharia-lab/agento/internal/policy"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+var (
+	// ErrNotFound is returned by Decide for a request that does not exist.
+	ErrNotFound = errors.New("approval request not found")
+	// ErrAlreadyDecided is returned by Decide for a request that is no longer
+	// pending: it was answered elsewhere first, or it expired.
+	ErrAlreadyDecided = errors.New("approval request is no longer pending")
+)
+
+// Run describes the unattended run a permission handler serves.
+type Run struct {
+	// Source is "chat", "scheduled_task", "trigger" or "mcp". Chats ask here
+	// only when run through the OpenAI-compatible API.
+	Source        string
+	AgentSlug     string
+	TaskID        string
+	JobID         string
+	TriggerRuleID string
+	ChatSessionID string
+	// Title names the run for the approver.
+	Title string
+}
+
+// Config holds all dependencies for the Broker.
+type Config struct {
+	Store            storage.ApprovalStore
+	IntegrationStore storage.IntegrationStore
+	// Settings loads the notification settings, which hold the approval
+	// channel. It is called for every request, so changes apply right away.
+	Settings notification.SettingsLoader
+	// PublicURL returns the configured public URL, used to link to the
+	// Approvals page from email.
+	PublicURL func() string
+	Logger    *slog.Logger
+}
+
+// Broker sends approval requests and wakes the runs waiting on them.
+type Broker struct {
+	store            storage.ApprovalStore
+	integrationStore storage.IntegrationStore
+	settings         notification.SettingsLoader
+	publicURL        func() string
+	logger           *slog.Logger
+
+	mu sync.Mutex
+	// waiting holds the channel each pending request's run waits on, by
+	// request ID.
+	waiting map[string]chan storage.ApprovalStatus
+}
+
+// NewBroker creates a new Broker.
+func NewBroker(cfg Config) *Broker {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	publicURL := cfg.PublicURL
+	if publicURL == nil {
+		publicURL = func() string { return "" }
+	}
+	return &Broker{
+		store:            cfg.Store,
+		integrationStore: cfg.IntegrationStore,
+		settings:         cfg.Settings,
+		publicURL:        publicURL,
+		logger:           logger,
+		waiting:          make(map[string]chan storage.ApprovalStatus),
+	}
+}
+
+// ExpireStale expires the requests left pending by a previous process. Their
+// runs died with it, so nothing waits on an answer any more.
+func (b *Broker) ExpireStale(ctx context.Context) {
+	n, err := b.store.ExpirePendingApprovals(ctx, time.Now().UTC())
+	if err != nil {
+		b.logger.Warn("approval: failed to expire stale requests", "error", err)
+		return
+	}
+	if n > 0 {
+		b.logger.Info("approval: expired requests left pending by the last run", "count", n)
+	}
+}
+
+// List returns the approval requests matching filter, newest first.
+func (b *Broker) List(ctx context.Context, filter storage.ApprovalFilter) ([]*storage.ApprovalRequest, error) {
+	return b.store.ListApprovals(ctx, filter)
+}
+
+// Timeout returns how long a request made now would wait for an answer.
+func (b *Broker) Timeout() time.Duration {
+	return b.loadSettings().Timeout()
+}
+
+// PermissionHandler returns the permission handler for one unattended run of
+// agentCfg, or nil when the agent gates no tools, which keeps the run's
+// calls unprompted. A call is gated when the tool is listed under
+// require_approval or the agent's tool policy asks about it. Gated calls wait
+// on an approval until ctx ends.
+func (b *Broker) PermissionHandler(
+	ctx context.Context, agentCfg *config.AgentConfig, run Run,
+) claude.PermissionHandler {
+	if agentCfg == nil || !agentCfg.Capabilities.MayRequireApproval() {
+		return nil
+	}
+	caps := agentCfg.Capabilities
+	pol, err := policy.Compile(caps.Policy)
+	if err != nil {
+		// The runner denies every call of such an agent; nothing reaches
+		// this handler to ask about.
+		pol = nil
+	}
+	return func(toolName string, input json.RawMessage, _ claude.PermissionContext) claude.PermissionResult {
+		if toolName == "AskUserQuestion" {
+			return claude.PermissionResult{
+				Behavior: "deny",
+				Message:  "Nobody is watching this run to answer questions. Decide on your own and carry on.",
+			}
+		}
+		if !caps.RequiresApproval(toolName) && !pol.Asks(toolName, input) {
+			return claude.PermissionResult{Behavior: "allow"}
+		}
+		return b.ask(ctx, run, toolName, input)
+	}
+}
+
+// ask records an approval request for one tool call, sends it, and waits for
+// the answer.
+func (b *Broker) ask(ctx context.Context, run Run, toolName string, input json.RawMessage) claude.PermissionResult {
+	settings := b.loadSettings()
+	now := time.Now().UTC()
+	req := &storage.ApprovalRequest{
+		Source:        run.Source,
+		AgentSlug:     run.AgentSlug,
+		TaskID:        run.TaskID,
+		JobID:         run.JobID,
+		TriggerRuleID: run.TriggerRuleID,
+		ChatSessionID: run.ChatSessionID,
+		Title:         run.Title,
+		ToolName:      toolName,
+		ToolInput:     string(input),
+		Channel:       settings.Channel,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(settings.Timeout()),
+	}
+	if err := b.store.CreateApproval(ctx, req); err != nil {
+		b.logger.Error("approval: failed to record request", "tool", toolName, "error", err)
+		return claude.PermissionResult{Behavior: "deny", Message: "The approval request could not be recorded."}
+	}
+
+	decided := b.register(req.ID)
+	defer b.unregister(req.ID)
+
+	notifyCtx, stopNotify := context.WithCancel(ctx)
+	defer stopNotify()
+	b.notify(notifyCtx, settings, req)
+
+	switch b.wait(ctx, req, decided) {
+	case storage.ApprovalApproved:
+		return claude.PermissionResult{Behavior: "allow"}
+	case storage.ApprovalDenied:
+		return claude.PermissionResult{
+			Behavior: "deny",
+			Message:  fmt.Sprintf("A person denied this call to %s. Do not retry it.", toolName),
+		}
+	default:
+		return claude.PermissionResult{
+			Behavior: "deny",
+			Message:  fmt.Sprintf("Nobody approved this call to %s in time. Do not retry it.", toolName),
+		}
+	}
+}
+
+// wait blocks until req is answered, expires, or ctx ends, and returns its
+// final status.
+func (b *Broker) wait(
+	ctx context.Context, req *storage.ApprovalRequest, decided <-chan storage.ApprovalStatus,
+) storage.ApprovalStatus {
+	timer := time.NewTimer(time.Until(req.ExpiresAt))
+	defer timer.Stop()
+
+	select {
+	case status := <-decided:
+		return status
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return b.expire(req)
+}
+
+// expire moves req to expired. An answer that landed in the meantime wins,
+// and its status is returned instead.
+func (b *Broker) expire(req *storage.ApprovalRequest) storage.ApprovalStatus {
+	// The run's context may be what ended; the bookkeeping must still happen.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	ok, err := b.store.DecideApproval(ctx, req.ID, storage.ApprovalExpired, "", now)
+	if err != nil {
+		b.logger.Error("approval: failed to expire request", "approval_id", req.ID, "error", err)
+		return storage.ApprovalExpired
+	}
+	if !ok {
+		current, getErr := b.store.GetApproval(ctx, req.ID)
+		if getErr != nil || current == nil {
+			return storage.ApprovalExpired
+		}
+		return current.Status
+	}
+
+	req.Status = storage.ApprovalExpired
+	req.DecidedAt = &now
+	b.announce(ctx, req)
+	return storage.ApprovalExpired
+}
+
+// Decide answers a pending request, approving it or denying it on behalf of
+// decidedBy, and wakes the run waiting on it. It returns ErrAlreadyDecided
+// when the request was answered first elsewhere or has expired, and
+// ErrNotFound when there is no such request.
+func (b *Broker) Decide(
+	ctx context.Context, id string, approve bool, decidedBy string,
+) (*storage.ApprovalRequest, error) {
+	status := storage.ApprovalDenied
+	if approve {
+		status = storage.ApprovalApproved
+	}
+	ok, err := b.store.DecideApproval(ctx, id, status, decidedBy, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("deciding approval request: %w", err)
+	}
+	if !ok {
+		if req, getErr := b.store.GetApproval(ctx, id); getErr == nil && req == nil {
+			return nil, ErrNotFound
+		}
+		return nil, ErrAlreadyDecided
+	}
+
+	b.mu.Lock()
+	if ch, found := b.waiting[id]; found {
+		ch <- status
+	}
+	b.mu.Unlock()
+
+	req, err := b.store.GetApproval(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("loading approval request: %w", err)
+	}
+	if req == nil {
+		return nil, fmt.Errorf("approval request %q vanished", id)
+	}
+	b.announce(ctx, req)
+	return req, nil
+}
+
+// register creates the channel the run waiting on request id is woken
+// through. It is buffered so Decide never blocks on a run that has stopped
+// listening.
+func (b *Broker) register(id string) <-chan storage.ApprovalStatus {
+	ch := make(chan storage.ApprovalStatus, 1)
+	b.mu.Lock()
+	b.waiting[id] = ch
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *Broker) unregister(id string) {
+	b.mu.Lock()
+	delete(b.waiting, id)
+	b.mu.Unlock()
+}
+
+// loadSettings returns the approval settings, or the defaults when the
+// notification settings cannot be read.
+func (b *Broker) loadSettings() notification.ApprovalSettings {
+	if b.settings == nil {
+		return notification.ApprovalSettings{}
+	}
+	settings, err := b.settings()
+	if err != nil {
+		b.logger.Warn("approval: failed to load notification settings", "error", err)
+		return notification.ApprovalSettings{}
+	}
+	return settings.Approvals
+}
+
+// requestText is the body of an approval request message.
+func requestText(req *storage.ApprovalRequest) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Approval needed: %s\n", req.Title)
+	if req.AgentSlug != "" {
+		fmt.Fprintf(&sb, "Agent: %s\n", req.AgentSlug)
+	}
+	fmt.Fprintf(&sb, "Tool: %s\n", req.ToolName)
+	if input := formatInput(req.ToolInput); input != "" {
+		fmt.Fprintf(&sb, "Input:\n%s\n", input)
+	}
+	fmt.Fprintf(&sb, "Expires: %s", req.ExpiresAt.Local().Format("Jan 2 15:04 MST"))
+	return sb.String()
+}
+
+// maxInputLen caps how much of a tool's input a request message shows.
+const maxInputLen = 1500
+
+// formatInput indents a tool's JSON input for reading and cuts it to
+// maxInputLen.
+func formatInput(raw string) string {
+	var v any
+	if err := json.Unmarshal([]byte(raw), &v); err == nil {
+		if pretty, err := json.MarshalIndent(v, "", "  "); err == nil {
+			raw = string(pretty)
+		}
+	}
+	if len(raw) > maxInputLen {
+		raw = strings.ToValidUTF8(raw[:maxInputLen], "") + "\n…"
+	}
+	return raw
+}
+
+// outcomeText describes how req ended, for the message it was sent as.
+func outcomeText(req *storage.ApprovalRequest) string {
+	switch req.Status {
+	case storage.ApprovalApproved:
+		return "Approved by " + req.DecidedBy
+	case storage.ApprovalDenied:
+		return "Denied by " + req.DecidedBy
+	default:
+		return "Expired without an answer; the call was denied"
+	}
+}
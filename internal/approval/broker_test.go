@@ -0,0 +1,197 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/integrations/slack"
+	"github.com/shaharia-lab/agento/internal/notification"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// newTestBroker returns a broker that only lists requests, with no channel
+// to send them over.
+func newTestBroker(t *testing.T) (*Broker, storage.ApprovalStore) {
+	t.Helper()
+	db, _, err := storage.NewSQLiteDB(":memory:", slog.Default())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	store := storage.NewSQLiteApprovalStore(db)
+	broker := NewBroker(Config{
+		Store: store,
+		Settings: func() (*notification.NotificationSettings, error) {
+			return &notification.NotificationSettings{}, nil
+		},
+	})
+	return broker, store
+}
+
+var gatedAgent = &config.AgentConfig{
+	Slug:         "deployer",
+	Capabilities: config.AgentCapabilities{RequireApproval: []string{"Bash", "mcp__github__*"}},
+}
+
+var testRun = Run{Source: "scheduled_task", AgentSlug: "deployer", TaskID: "t1", JobID: "j1", Title: "Nightly deploy"}
+
+// callAsync runs handler on a tool call in the background.
+func callAsync(handler claude.PermissionHandler, toolName string) <-chan claude.PermissionResult {
+	done := make(chan claude.PermissionResult, 1)
+	go func() {
+		done <- handler(toolName, json.RawMessage(`{"command":"make deploy"}`), claude.PermissionContext{})
+	}()
+	return done
+}
+
+// pendingRequest waits for the broker to record a request and returns it.
+func pendingRequest(t *testing.T, store storage.ApprovalStore) *storage.ApprovalRequest {
+	t.Helper()
+	var req *storage.ApprovalRequest
+	require.Eventually(t, func() bool {
+		pending, err := store.ListApprovals(context.Background(), storage.ApprovalFilter{Status: storage.ApprovalPending})
+		if err != nil || len(pending) == 0 {
+			return false
+		}
+		req = pending[0]
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+	return req
+}
+
+func TestPermissionHandler(t *testing.T) {
+	broker, _ := newTestBroker(t)
+
+	t.Run("agents that gate nothing keep running unprompted", func(t *testing.T) {
+		assert.Nil(t, broker.PermissionHandler(context.Background(), &config.AgentConfig{}, testRun))
+		assert.Nil(t, broker.PermissionHandler(context.Background(), nil, testRun))
+	})
+
+	t.Run("ungated tools are allowed and questions refused", func(t *testing.T) {
+		handler := broker.PermissionHandler(context.Background(), gatedAgent, testRun)
+		require.NotNil(t, handler)
+		assert.Equal(t, "allow", handler("Read", nil, claude.PermissionContext{}).Behavior)
+		assert.Equal(t, "deny", handler("AskUserQuestion", nil, claude.PermissionContext{}).Behavior)
+	})
+
+	t.Run("calls a policy asks about wait for an answer", func(t *testing.T) {
+		broker, store := newTestBroker(t)
+		agentCfg := &config.AgentConfig{Slug: "deployer", Capabilities: config.AgentCapabilities{
+			Policy: &config.ToolPolicy{Rules: []config.PolicyRule{
+				{Tool: "Bash", Action: config.PolicyAsk, Command: `^make deploy`},
+			}},
+		}}
+		handler := broker.PermissionHandler(context.Background(), agentCfg, testRun)
+		require.NotNil(t, handler)
+		ls := handler("Bash", json.RawMessage(`{"command":"ls"}`), claude.PermissionContext{})
+		assert.Equal(t, "allow", ls.Behavior)
+
+		done := callAsync(handler, "Bash")
+		req := pendingRequest(t, store)
+		_, err := broker.Decide(context.Background(), req.ID, true, "web")
+		require.NoError(t, err)
+		assert.Equal(t, "allow", (<-done).Behavior)
+	})
+}
+
+func TestGatedCall(t *testing.T) {
+	t.Run("an approval lets the call through", func(t *testing.T) {
+		broker, store := newTestBroker(t)
+		done := callAsync(broker.PermissionHandler(context.Background(), gatedAgent, testRun), "Bash")
+
+		req := pendingRequest(t, store)
+		assert.Equal(t, "Bash", req.ToolName)
+		assert.Equal(t, "j1", req.JobID)
+		assert.Equal(t, "Nightly deploy", req.Title)
+
+		decided, err := broker.Decide(context.Background(), req.ID, true, "web")
+		require.NoError(t, err)
+		assert.Equal(t, storage.ApprovalApproved, decided.Status)
+		assert.Equal(t, "allow", (<-done).Behavior)
+
+		_, err = broker.Decide(context.Background(), req.ID, false, "web")
+		assert.ErrorIs(t, err, ErrAlreadyDecided, "the first answer wins")
+	})
+
+	t.Run("a denial refuses the call", func(t *testing.T) {
+		broker, store := newTestBroker(t)
+		done := callAsync(broker.PermissionHandler(context.Background(), gatedAgent, testRun), "mcp__github__merge_pr")
+
+		req := pendingRequest(t, store)
+		_, err := broker.Decide(context.Background(), req.ID, false, "telegram:@alice")
+		require.NoError(t, err)
+		assert.Equal(t, "deny", (<-done).Behavior)
+
+		stored, err := store.GetApproval(context.Background(), req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "telegram:@alice", stored.DecidedBy)
+	})
+
+	t.Run("a run that ends stops waiting and the request expires", func(t *testing.T) {
+		broker, store := newTestBroker(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := callAsync(broker.PermissionHandler(ctx, gatedAgent, testRun), "Bash")
+
+		req := pendingRequest(t, store)
+		cancel()
+		assert.Equal(t, "deny", (<-done).Behavior)
+
+		stored, err := store.GetApproval(context.Background(), req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, storage.ApprovalExpired, stored.Status)
+
+		_, err = broker.Decide(context.Background(), req.ID, true, "web")
+		assert.ErrorIs(t, err, ErrAlreadyDecided)
+	})
+
+	t.Run("unknown requests", func(t *testing.T) {
+		broker, _ := newTestBroker(t)
+		_, err := broker.Decide(context.Background(), "missing", true, "web")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestHandleTelegramCallback(t *testing.T) {
+	broker, store := newTestBroker(t)
+	done := callAsync(broker.PermissionHandler(context.Background(), gatedAgent, testRun), "Bash")
+	req := pendingRequest(t, store)
+	require.NoError(t, store.SetApprovalChannelRef(context.Background(), req.ID, "tg1/-100/42"))
+
+	assert.Equal(t, "Unknown action.", broker.HandleTelegramCallback(context.Background(), "tg1", "poll:1", "@bob"))
+	assert.Equal(t, "This approval request does not exist.",
+		broker.HandleTelegramCallback(context.Background(), "tg2", "approve:"+req.ID, "@bob"),
+		"buttons only answer requests sent through the same bot")
+
+	assert.Equal(t, "Approved by telegram:@bob",
+		broker.HandleTelegramCallback(context.Background(), "tg1", "approve:"+req.ID, "@bob"))
+	assert.Equal(t, "allow", (<-done).Behavior)
+	assert.Equal(t, "This request was already answered or has expired.",
+		broker.HandleTelegramCallback(context.Background(), "tg1", "deny:"+req.ID, "@carol"))
+}
+
+func TestParseSlackAnswer(t *testing.T) {
+	tests := []struct {
+		reply       slack.Message
+		wantApprove bool
+		wantOK      bool
+	}{
+		{reply: slack.Message{User: "U1", Text: "approve"}, wantApprove: true, wantOK: true},
+		{reply: slack.Message{User: "U1", Text: "  Yes, go ahead"}, wantApprove: true, wantOK: true},
+		{reply: slack.Message{User: "U1", Text: "Deny."}, wantOK: true},
+		{reply: slack.Message{User: "U1", Text: "no"}, wantOK: true},
+		{reply: slack.Message{User: "U1", Text: "what does it do?"}},
+		{reply: slack.Message{User: "U1", BotID: "B1", Text: "approve"}},
+	}
+	for _, tc := range tests {
+		approve, ok := parseSlackAnswer(tc.reply)
+		assert.Equal(t, tc.wantOK, ok, tc.reply.Text)
+		assert.Equal(t, tc.wantApprove, approve, tc.reply.Text)
+	}
+}
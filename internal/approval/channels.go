@@ -0,0 +1,300 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	slackintegration "github.com/shaharia-lab/agento/internal/integrations/slack"
+	telegramintegration "github.com/shaharia-lab/agento/internal/integrations/telegram"
+	"github.com/shaharia-lab/agento/internal/notification"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// Approval channels.
+//
+// On Telegram a request is a message with Approve and Deny buttons; pressing
+// one reaches HandleTelegramCallback through the integration's webhook or
+// long polling. On Slack it is a message whose thread is watched for an
+// "approve" or "deny" reply. Email has no way back in, so it links to the
+// Approvals page. Once a request is answered or expires, the Telegram message
+// is edited and the Slack thread replied to with the outcome.
+
+// slackWatchInterval is how often a Slack request's thread is checked for an
+// answer.
+const slackWatchInterval = 15 * time.Second
+
+// Telegram callback data prefixes. Callback data is capped at 64 bytes,
+// which a prefix and a UUID fit in.
+const (
+	callbackApprove = "approve:"
+	callbackDeny    = "deny:"
+)
+
+// notify sends req over the configured channel. A request that cannot be
+// sent still waits: it can be answered on the Approvals page.
+func (b *Broker) notify(
+	ctx context.Context, settings notification.ApprovalSettings, req *storage.ApprovalRequest,
+) {
+	var err error
+	switch settings.Channel {
+	case "":
+		return
+	case notification.ApprovalChannelTelegram:
+		err = b.sendTelegram(ctx, settings, req)
+	case notification.ApprovalChannelSlack:
+		err = b.sendSlack(ctx, settings, req)
+	case notification.ApprovalChannelEmail:
+		err = b.sendEmail(ctx, req)
+	default:
+		err = fmt.Errorf("unknown approval channel %q", settings.Channel)
+	}
+	if err != nil {
+		b.logger.Error("approval: failed to send request",
+			"approval_id", req.ID, "channel", settings.Channel, "error", err)
+	}
+}
+
+func (b *Broker) sendTelegram(
+	ctx context.Context, settings notification.ApprovalSettings, req *storage.ApprovalRequest,
+) error {
+	botToken, err := b.telegramToken(ctx, settings.IntegrationID)
+	if err != nil {
+		return err
+	}
+	chatID, err := strconv.ParseInt(settings.Target, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid telegram chat ID %q", settings.Target)
+	}
+
+	buttons := []telegramintegration.InlineButton{
+		{Text: "Approve", Data: callbackApprove + req.ID},
+		{Text: "Deny", Data: callbackDeny + req.ID},
+	}
+	messageID, err := telegramintegration.SendWithButtons(ctx, botToken, chatID, requestText(req), buttons)
+	if err != nil {
+		return err
+	}
+	b.setChannelRef(ctx, req, fmt.Sprintf("%s/%d/%d", settings.IntegrationID, chatID, messageID))
+	return nil
+}
+
+func (b *Broker) sendSlack(
+	ctx context.Context, settings notification.ApprovalSettings, req *storage.ApprovalRequest,
+) error {
+	integration, err := b.integration(ctx, settings.IntegrationID, "slack")
+	if err != nil {
+		return err
+	}
+	text := requestText(req) + "\nReply in this thread with approve or deny."
+	ts, err := slackintegration.PostMessage(ctx, integration, settings.Target, text, "")
+	if err != nil {
+		return err
+	}
+	b.setChannelRef(ctx, req, settings.IntegrationID+"/"+settings.Target+"/"+ts)
+	go b.watchSlackThread(ctx, integration, settings.Target, ts, req.ID)
+	return nil
+}
+
+func (b *Broker) sendEmail(ctx context.Context, req *storage.ApprovalRequest) error {
+	settings, err := b.settings()
+	if err != nil {
+		return fmt.Errorf("loading notification settings: %w", err)
+	}
+	answer := "Answer it on the Approvals page in Agento."
+	if base := strings.TrimRight(b.publicURL(), "/"); base != "" {
+		answer = "Answer it at " + base + "/approvals"
+	}
+	provider := notification.NewSMTPProvider(settings.Provider)
+	return provider.Send(ctx, notification.Message{
+		Subject: notification.SubjectPrefix + "Approval Needed: " + req.ToolName,
+		Body:    requestText(req) + "\n\n" + answer,
+	})
+}
+
+func (b *Broker) setChannelRef(ctx context.Context, req *storage.ApprovalRequest, ref string) {
+	req.ChannelRef = ref
+	if err := b.store.SetApprovalChannelRef(ctx, req.ID, ref); err != nil {
+		b.logger.Warn("approval: failed to save channel reference", "approval_id", req.ID, "error", err)
+	}
+}
+
+// watchSlackThread checks the thread of a Slack request for an answer until
+// one arrives or ctx ends. The first reply reading approve or deny, from a
+// person rather than a bot, decides the request.
+func (b *Broker) watchSlackThread(
+	ctx context.Context, integration *config.IntegrationConfig, channel, ts, approvalID string,
+) {
+	ticker := time.NewTicker(slackWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		replies, err := slackintegration.ThreadReplies(ctx, integration, channel, ts)
+		if err != nil {
+			b.logger.Warn("approval: failed to read slack thread", "approval_id", approvalID, "error", err)
+			continue
+		}
+		for _, reply := range replies {
+			approve, ok := parseSlackAnswer(reply)
+			if !ok {
+				continue
+			}
+			// The waiting run stops this watch as soon as it wakes; the
+			// outcome must still be reported back to the thread.
+			_, err := b.Decide(context.WithoutCancel(ctx), approvalID, approve, "slack:"+reply.User)
+			if err != nil && !errors.Is(err, ErrAlreadyDecided) {
+				b.logger.Warn("approval: failed to record slack answer", "approval_id", approvalID, "error", err)
+			}
+			return
+		}
+	}
+}
+
+// parseSlackAnswer reads a thread reply as an answer: approve, yes, deny or
+// no, in any case and with any trailing punctuation, as its first word.
+func parseSlackAnswer(reply slackintegration.Message) (approve, ok bool) {
+	if reply.User == "" || reply.BotID != "" {
+		return false, false
+	}
+	word, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(reply.Text)), " ")
+	switch strings.TrimRightFunc(word, unicode.IsPunct) {
+	case "approve", "approved", "yes":
+		return true, true
+	case "deny", "denied", "no":
+		return false, true
+	}
+	return false, false
+}
+
+// HandleTelegramCallback answers the request an Approve or Deny button press
+// on integrationID's bot is for, and returns the text to show the person who
+// pressed it. from names them. Presses on buttons of other integrations, or
+// with data that is not an approval's, are refused.
+func (b *Broker) HandleTelegramCallback(ctx context.Context, integrationID, data, from string) string {
+	var approve bool
+	var id string
+	switch {
+	case strings.HasPrefix(data, callbackApprove):
+		approve, id = true, strings.TrimPrefix(data, callbackApprove)
+	case strings.HasPrefix(data, callbackDeny):
+		id = strings.TrimPrefix(data, callbackDeny)
+	default:
+		return "Unknown action."
+	}
+
+	req, err := b.store.GetApproval(ctx, id)
+	if err != nil {
+		b.logger.Error("approval: failed to load request", "approval_id", id, "error", err)
+		return "Something went wrong."
+	}
+	if req == nil || !strings.HasPrefix(req.ChannelRef, integrationID+"/") {
+		return "This approval request does not exist."
+	}
+
+	decided, err := b.Decide(ctx, id, approve, "telegram:"+from)
+	if errors.Is(err, ErrAlreadyDecided) {
+		return "This request was already answered or has expired."
+	}
+	if err != nil {
+		b.logger.Error("approval: failed to record telegram answer", "approval_id", id, "error", err)
+		return "Something went wrong."
+	}
+	return outcomeText(decided)
+}
+
+// announce reports how req ended on the message it was sent as.
+func (b *Broker) announce(ctx context.Context, req *storage.ApprovalRequest) {
+	integrationID, chat, message, ok := splitChannelRef(req.ChannelRef)
+	if !ok {
+		return
+	}
+
+	var err error
+	switch req.Channel {
+	case notification.ApprovalChannelTelegram:
+		err = b.announceTelegram(ctx, integrationID, chat, message, req)
+	case notification.ApprovalChannelSlack:
+		err = b.announceSlack(ctx, integrationID, chat, message, req)
+	}
+	if err != nil {
+		b.logger.Warn("approval: failed to report outcome", "approval_id", req.ID, "error", err)
+	}
+}
+
+func (b *Broker) announceTelegram(
+	ctx context.Context, integrationID, chat, message string, req *storage.ApprovalRequest,
+) error {
+	botToken, err := b.telegramToken(ctx, integrationID)
+	if err != nil {
+		return err
+	}
+	chatID, err := strconv.ParseInt(chat, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid telegram chat ID %q", chat)
+	}
+	messageID, err := strconv.Atoi(message)
+	if err != nil {
+		return fmt.Errorf("invalid telegram message ID %q", message)
+	}
+	// Editing the message also removes its buttons.
+	text := requestText(req) + "\n\n" + outcomeText(req) + "."
+	return telegramintegration.EditMessageText(ctx, botToken, chatID, messageID, text)
+}
+
+func (b *Broker) announceSlack(
+	ctx context.Context, integrationID, channel, ts string, req *storage.ApprovalRequest,
+) error {
+	integration, err := b.integration(ctx, integrationID, "slack")
+	if err != nil {
+		return err
+	}
+	_, err = slackintegration.PostMessage(ctx, integration, channel, outcomeText(req)+".", ts)
+	return err
+}
+
+// splitChannelRef splits a channel reference into the integration ID, the
+// chat or channel, and the message.
+func splitChannelRef(ref string) (integrationID, chat, message string, ok bool) {
+	parts := strings.SplitN(ref, "/", 3)
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+func (b *Broker) telegramToken(ctx context.Context, integrationID string) (string, error) {
+	integration, err := b.integration(ctx, integrationID, "telegram")
+	if err != nil {
+		return "", err
+	}
+	var creds config.TelegramCredentials
+	if err := integration.ParseCredentials(&creds); err != nil {
+		return "", fmt.Errorf("parsing telegram credentials: %w", err)
+	}
+	return creds.BotToken, nil
+}
+
+// integration loads an enabled integration of the given type.
+func (b *Broker) integration(ctx context.Context, id, typ string) (*config.IntegrationConfig, error) {
+	integration, err := b.integrationStore.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("loading integration %q: %w", id, err)
+	}
+	if integration == nil || integration.Type != typ {
+		return nil, fmt.Errorf("no %s integration %q", typ, id)
+	}
+	if !integration.Enabled {
+		return nil, fmt.Errorf("integration %q is disabled", id)
+	}
+	return integration, nil
+}
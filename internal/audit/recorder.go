@@ -0,0 +1,163 @@
+// Package audit keeps the tool call audit log: every tool call of a chat,
+// scheduled task, triggered run or MCP run, with its input, how it ended and
+// the permission decision it got. The log lives in storage.ToolCallStore;
+// this package turns it into the agent.ToolAuditor the runner reports to.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// Run source constants for Run.Source.
+const (
+	SourceChat          = "chat"
+	SourceScheduledTask = "scheduled_task"
+	SourceTrigger       = "trigger"
+	SourceMCP           = "mcp"
+)
+
+// Run identifies the run a set of tool calls belongs to.
+type Run struct {
+	// Source is SourceChat, SourceScheduledTask, SourceTrigger or SourceMCP.
+	Source        string
+	AgentSlug     string
+	TaskID        string
+	JobID         string
+	TriggerRuleID string
+	ChatSessionID string
+}
+
+// Recorder hands out a ToolAuditor per run.
+type Recorder struct {
+	store  storage.ToolCallStore
+	logger *slog.Logger
+}
+
+// NewRecorder returns a Recorder writing to store.
+func NewRecorder(store storage.ToolCallStore, logger *slog.Logger) *Recorder {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Recorder{store: store, logger: logger}
+}
+
+// List returns the logged calls matching filter, newest first.
+func (r *Recorder) List(ctx context.Context, filter storage.ToolCallFilter) ([]*storage.ToolCall, error) {
+	calls, err := r.store.ListToolCalls(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("listing tool calls: %w", err)
+	}
+	return calls, nil
+}
+
+// ForRun returns the auditor for one run. A nil Recorder returns nil, which
+// the runner takes as auditing switched off.
+func (r *Recorder) ForRun(run Run) agent.ToolAuditor {
+	if r == nil {
+		return nil
+	}
+	return &runAuditor{
+		r:         r,
+		run:       run,
+		byID:      make(map[string]agent.PermissionDecision),
+		byContent: make(map[string][]agent.PermissionDecision),
+	}
+}
+
+// runAuditor implements agent.ToolAuditor for a single run. Decisions arrive
+// from the permission handler before the call runs and are held until the
+// call is recorded.
+type runAuditor struct {
+	r   *Recorder
+	run Run
+
+	mu   sync.Mutex
+	byID map[string]agent.PermissionDecision
+	// byContent holds, in arrival order, the decisions that came without a
+	// tool_use ID, keyed by tool name and input.
+	byContent map[string][]agent.PermissionDecision
+}
+
+// Decide holds d until its call is recorded.
+func (a *runAuditor) Decide(d agent.PermissionDecision) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if d.ToolUseID != "" {
+		a.byID[d.ToolUseID] = d
+		return
+	}
+	key := contentKey(d.ToolName, d.Input)
+	a.byContent[key] = append(a.byContent[key], d)
+}
+
+// Record writes call to the log with the decision it got. Writing outlives
+// ctx, so that the calls a cancelled run was making are still logged.
+func (a *runAuditor) Record(ctx context.Context, call agent.ToolCall) {
+	entry := &storage.ToolCall{
+		ToolUseID:     call.ToolUseID,
+		Source:        a.run.Source,
+		AgentSlug:     a.run.AgentSlug,
+		TaskID:        a.run.TaskID,
+		JobID:         a.run.JobID,
+		TriggerRuleID: a.run.TriggerRuleID,
+		ChatSessionID: a.run.ChatSessionID,
+		ToolName:      call.ToolName,
+		Input:         string(call.Input),
+		Status:        storage.ToolCallStatus(call.Status),
+		Decision:      storage.ToolDecisionAuto,
+		StartedAt:     call.StartedAt.UTC(),
+		DurationMS:    call.Duration.Milliseconds(),
+	}
+	if d, ok := a.takeDecision(call); ok {
+		entry.Decision = d.Behavior
+		if d.Behavior == storage.ToolDecisionDeny {
+			entry.Status = storage.ToolCallDenied
+			entry.DecisionReason = d.Message
+		}
+	}
+
+	if err := a.r.store.RecordToolCall(context.WithoutCancel(ctx), entry); err != nil {
+		a.r.logger.Error("failed to record tool call",
+			"agent", a.run.AgentSlug, "tool", call.ToolName, "tool_use_id", call.ToolUseID, "error", err)
+	}
+}
+
+// takeDecision removes and returns the decision made for call, if any.
+func (a *runAuditor) takeDecision(call agent.ToolCall) (agent.PermissionDecision, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if d, ok := a.byID[call.ToolUseID]; ok {
+		delete(a.byID, call.ToolUseID)
+		return d, true
+	}
+	key := contentKey(call.ToolName, call.Input)
+	queue := a.byContent[key]
+	if len(queue) == 0 {
+		return agent.PermissionDecision{}, false
+	}
+	if len(queue) == 1 {
+		delete(a.byContent, key)
+	} else {
+		a.byContent[key] = queue[1:]
+	}
+	return queue[0], true
+}
+
+// contentKey identifies a call by its tool name and input. The input is
+// compacted since the permission request and the tool_use block may format
+// the same JSON differently.
+func contentKey(toolName string, input json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, input); err != nil {
+		return toolName + "\x00" + string(input)
+	}
+	return toolName + "\x00" + buf.String()
+}
@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func newTestRecorder(t *testing.T) (*Recorder, storage.ToolCallStore) {
+	t.Helper()
+	db, _, err := storage.NewSQLiteDB(":memory:", slog.Default())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	store := storage.NewSQLiteToolCallStore(db)
+	return NewRecorder(store, slog.Default()), store
+}
+
+func TestRecorder(t *testing.T) {
+	r, store := newTestRecorder(t)
+	auditor := r.ForRun(Run{Source: SourceScheduledTask, AgentSlug: "ops", TaskID: "task-1", JobID: "job-1"})
+	started := time.Now().Add(-time.Second)
+
+	call := func(id, tool, input, status string) agent.ToolCall {
+		return agent.ToolCall{
+			ToolUseID: id, ToolName: tool, Input: json.RawMessage(input),
+			Status: status, StartedAt: started, Duration: 250 * time.Millisecond,
+		}
+	}
+
+	auditor.Decide(agent.PermissionDecision{ToolUseID: "tu-2", ToolName: "Bash", Behavior: "deny", Message: "no sudo"})
+	// Without an ID the decision is matched on tool name and input, however
+	// the JSON is spaced.
+	auditor.Decide(agent.PermissionDecision{
+		ToolName: "Write", Input: json.RawMessage(`{ "file_path": "/srv/prod.yaml" }`), Behavior: "allow",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	auditor.Record(ctx, call("tu-1", "Read", `{"file_path":"/srv/prod.yaml"}`, agent.ToolCallSucceeded))
+	auditor.Record(ctx, call("tu-2", "Bash", `{"command":"sudo ls"}`, agent.ToolCallFailed))
+	auditor.Record(ctx, call("tu-3", "Write", `{"file_path":"/srv/prod.yaml"}`, agent.ToolCallSucceeded))
+	cancel()
+	auditor.Record(ctx, call("tu-4", "Bash", `{"command":"make"}`, agent.ToolCallInterrupted))
+
+	calls, err := store.ListToolCalls(context.Background(), storage.ToolCallFilter{})
+	require.NoError(t, err)
+	require.Len(t, calls, 4, "a cancelled run's calls are still recorded")
+
+	byID := make(map[string]*storage.ToolCall, len(calls))
+	for _, c := range calls {
+		byID[c.ToolUseID] = c
+		assert.Equal(t, "ops", c.AgentSlug)
+		assert.Equal(t, "job-1", c.JobID)
+		assert.Equal(t, SourceScheduledTask, c.Source)
+		assert.Equal(t, int64(250), c.DurationMS)
+	}
+
+	assert.Equal(t, storage.ToolDecisionAuto, byID["tu-1"].Decision)
+	assert.Equal(t, storage.ToolCallSucceeded, byID["tu-1"].Status)
+
+	assert.Equal(t, storage.ToolDecisionDeny, byID["tu-2"].Decision)
+	assert.Equal(t, storage.ToolCallDenied, byID["tu-2"].Status)
+	assert.Equal(t, "no sudo", byID["tu-2"].DecisionReason)
+
+	assert.Equal(t, storage.ToolDecisionAllow, byID["tu-3"].Decision)
+	assert.Equal(t, storage.ToolCallInterrupted, byID["tu-4"].Status)
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var r *Recorder
+	assert.Nil(t, r.ForRun(Run{Source: SourceChat}), "a nil recorder must yield a nil interface")
+}
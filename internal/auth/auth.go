@@ -0,0 +1,359 @@
+// Package auth is Agento's optional authentication: a local admin password
+// that signs the UI in with a session cookie, and personal API tokens, scoped
+// to what they may do, for scripts and clients.
+//
+// Agento ships without authentication and listens on loopback only. Running
+// it on a shared machine, with AGENTO_BIND set to a reachable address, needs
+// more than that, so setting an admin password (agento auth set-password)
+// turns authentication on for every route but /health, /login and the
+// webhooks, which carry their own secrets. Clearing it turns it off again.
+// The state lives in storage.AuthStore and is read per request, so the CLI
+// can change it while agento web runs.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+const (
+	// SessionCookie is the name of the cookie holding a browser session.
+	SessionCookie = "agento_session"
+	// TokenPrefix starts every API token, so a leaked one is recognisable.
+	TokenPrefix = "agento_"
+	// SessionTTL is how long a browser stays signed in.
+	SessionTTL = 30 * 24 * time.Hour
+	// MinPasswordLength is the shortest admin password accepted.
+	MinPasswordLength = 8
+	// maxPasswordLength is bcrypt's limit; it ignores anything past it.
+	maxPasswordLength = 72
+	// shownPrefixLength is how much of a token is kept in the clear to tell
+	// tokens apart in a list.
+	shownPrefixLength = len(TokenPrefix) + 6
+	// touchInterval throttles recording a token's last use, so a busy script
+	// does not write on every request.
+	touchInterval = time.Minute
+)
+
+var (
+	// ErrDisabled is returned by Login while no admin password is set.
+	ErrDisabled = errors.New("authentication is not enabled")
+	// ErrInvalidCredentials is returned by Login for a wrong password.
+	ErrInvalidCredentials = errors.New("invalid password")
+	// ErrTokenNotFound is returned by RevokeToken for a token that does not
+	// exist.
+	ErrTokenNotFound = errors.New("API token not found")
+)
+
+// Principal kinds.
+const (
+	KindSession = "session"
+	KindToken   = "token"
+)
+
+// Principal is who a request authenticated as.
+type Principal struct {
+	// Kind is KindSession for the signed-in admin or KindToken for an API
+	// token.
+	Kind string
+	// Scope is what the principal may do. A session is always admin.
+	Scope     storage.APITokenScope
+	TokenID   string
+	TokenName string
+}
+
+// Manager checks and issues credentials.
+type Manager struct {
+	store  storage.AuthStore
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewManager returns a Manager backed by store.
+func NewManager(store storage.AuthStore, logger *slog.Logger) *Manager {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Manager{store: store, logger: logger, now: time.Now}
+}
+
+// Enabled reports whether an admin password is set, which is what turns
+// authentication on.
+func (m *Manager) Enabled(ctx context.Context) (bool, error) {
+	hash, err := m.store.GetPasswordHash(ctx)
+	if err != nil {
+		return false, fmt.Errorf("checking whether authentication is enabled: %w", err)
+	}
+	return hash != "", nil
+}
+
+// SetPassword sets the admin password, turning authentication on, and signs
+// every browser out.
+func (m *Manager) SetPassword(ctx context.Context, password string) error {
+	if len(password) < MinPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("password must be at most %d bytes", maxPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("hashing password: %w", err)
+	}
+	if err := m.store.SetPasswordHash(ctx, string(hash)); err != nil {
+		return err
+	}
+	return m.store.DeleteAuthSessions(ctx)
+}
+
+// Disable clears the admin password, turning authentication off, and signs
+// every browser out. API tokens are kept for when it is turned on again.
+func (m *Manager) Disable(ctx context.Context) error {
+	if err := m.store.ClearPassword(ctx); err != nil {
+		return err
+	}
+	return m.store.DeleteAuthSessions(ctx)
+}
+
+// Login checks the admin password and starts a browser session. It returns
+// the value for the session cookie and when it expires.
+func (m *Manager) Login(ctx context.Context, password string) (string, time.Time, error) {
+	hash, err := m.store.GetPasswordHash(ctx)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("loading admin password: %w", err)
+	}
+	if hash == "" {
+		return "", time.Time{}, ErrDisabled
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return "", time.Time{}, ErrInvalidCredentials
+	}
+
+	id, err := randomSecret()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	now := m.now().UTC()
+	sess := &storage.AuthSession{IDHash: hashSecret(id), CreatedAt: now, ExpiresAt: now.Add(SessionTTL)}
+	if err := m.store.CreateAuthSession(ctx, sess); err != nil {
+		return "", time.Time{}, err
+	}
+	return id, sess.ExpiresAt, nil
+}
+
+// Logout ends the browser session with the given cookie value.
+func (m *Manager) Logout(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	return m.store.DeleteAuthSession(ctx, hashSecret(sessionID))
+}
+
+// PruneSessions removes expired browser sessions.
+func (m *Manager) PruneSessions(ctx context.Context) (int, error) {
+	return m.store.DeleteExpiredAuthSessions(ctx, m.now().UTC())
+}
+
+// Authenticate returns who r is signed in as: the bearer token in its
+// Authorization header, else its session cookie. It returns nil when r
+// carries neither, or one that is unknown or expired.
+func (m *Manager) Authenticate(r *http.Request) (*Principal, error) {
+	if raw, ok := bearerToken(r); ok {
+		return m.authenticateToken(r.Context(), raw)
+	}
+	c, err := r.Cookie(SessionCookie)
+	if err != nil || c.Value == "" {
+		return nil, nil
+	}
+	return m.authenticateSession(r.Context(), c.Value)
+}
+
+func (m *Manager) authenticateSession(ctx context.Context, id string) (*Principal, error) {
+	sess, err := m.store.GetAuthSession(ctx, hashSecret(id))
+	if err != nil {
+		return nil, fmt.Errorf("loading session: %w", err)
+	}
+	if sess == nil || !m.now().Before(sess.ExpiresAt) {
+		return nil, nil
+	}
+	return &Principal{Kind: KindSession, Scope: storage.APITokenScopeAdmin}, nil
+}
+
+func (m *Manager) authenticateToken(ctx context.Context, raw string) (*Principal, error) {
+	if !strings.HasPrefix(raw, TokenPrefix) {
+		return nil, nil
+	}
+	t, err := m.store.GetAPITokenByHash(ctx, hashSecret(raw))
+	if err != nil {
+		return nil, fmt.Errorf("loading API token: %w", err)
+	}
+	now := m.now()
+	if t == nil || (t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)) {
+		return nil, nil
+	}
+	if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) >= touchInterval {
+		// Failing to record the use is no reason to refuse the request.
+		if err := m.store.TouchAPIToken(ctx, t.ID, now.UTC()); err != nil {
+			m.logger.Warn("recording API token use failed", "token_id", t.ID, "error", err)
+		}
+	}
+	return &Principal{Kind: KindToken, Scope: t.Scope, TokenID: t.ID, TokenName: t.Name}, nil
+}
+
+// CreateToken issues an API token. It returns the token's secret, which is
+// not stored and cannot be shown again. A zero ttl never expires.
+func (m *Manager) CreateToken(
+	ctx context.Context, name string, scope storage.APITokenScope, ttl time.Duration,
+) (*storage.APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.New("name is required")
+	}
+	if !ValidScope(scope) {
+		return nil, "", fmt.Errorf("scope must be %q, %q, %q or %q",
+			storage.APITokenScopeRead, storage.APITokenScopeRun, storage.APITokenScopeAdmin,
+			storage.APITokenScopeIngest)
+	}
+	if ttl < 0 {
+		return nil, "", errors.New("expiry must not be negative")
+	}
+
+	secret, err := randomSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	raw := TokenPrefix + secret
+	now := m.now().UTC()
+	t := &storage.APIToken{
+		Name:      name,
+		Scope:     scope,
+		Prefix:    raw[:shownPrefixLength],
+		TokenHash: hashSecret(raw),
+		CreatedAt: now,
+	}
+	if ttl > 0 {
+		expires := now.Add(ttl)
+		t.ExpiresAt = &expires
+	}
+	if err := m.store.CreateAPIToken(ctx, t); err != nil {
+		return nil, "", err
+	}
+	return t, raw, nil
+}
+
+// ListTokens returns every API token, newest first.
+func (m *Manager) ListTokens(ctx context.Context) ([]*storage.APIToken, error) {
+	return m.store.ListAPITokens(ctx)
+}
+
+// RevokeToken deletes an API token.
+func (m *Manager) RevokeToken(ctx context.Context, id string) error {
+	found, err := m.store.DeleteAPIToken(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+// ValidScope reports whether scope is one a token may have.
+func ValidScope(scope storage.APITokenScope) bool {
+	switch scope {
+	case storage.APITokenScopeRead, storage.APITokenScopeRun, storage.APITokenScopeAdmin,
+		storage.APITokenScopeIngest:
+		return true
+	default:
+		return false
+	}
+}
+
+// bearerToken returns the token in r's Authorization header.
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(h, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// randomSecret returns 32 random bytes, base64url-encoded.
+func randomSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating secret: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashSecret is how sessions and tokens are looked up. They are random and
+// long, so a fast hash is enough; a slow one would only slow every request.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal stored in ctx, or nil when the request
+// was not authenticated, as when authentication is off.
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	if !ok {
+		return nil
+	}
+	return p
+}
+
+// SessionCookieFor returns the cookie that keeps r's browser signed in with
+// the given session.
+func SessionCookieFor(r *http.Request, sessionID string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     SessionCookie,
+		Value:    sessionID,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// ClearedSessionCookie returns the cookie that signs r's browser out.
+func ClearedSessionCookie(r *http.Request) *http.Cookie {
+	return &http.Cookie{
+		Name:     SessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// isHTTPS reports whether the browser reached r over HTTPS, directly or
+// through a reverse proxy that terminates it.
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
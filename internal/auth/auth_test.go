@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	db, _, err := storage.NewSQLiteDB(":memory:", slog.Default())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewManager(storage.NewSQLiteAuthStore(db), slog.Default())
+}
+
+func requestWith(header, value string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/api/agents", nil)
+	r.Header.Set(header, value)
+	return r
+}
+
+func TestManager_PasswordAndSessions(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	enabled, err := m.Enabled(ctx)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+	_, _, err = m.Login(ctx, "anything")
+	assert.ErrorIs(t, err, ErrDisabled)
+
+	assert.Error(t, m.SetPassword(ctx, "short"), "a password under 8 characters is refused")
+	require.NoError(t, m.SetPassword(ctx, "correct horse"))
+	enabled, err = m.Enabled(ctx)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	_, _, err = m.Login(ctx, "wrong horse")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	sessionID, expires, err := m.Login(ctx, "correct horse")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(SessionTTL), expires, time.Minute)
+
+	r := requestWith("Cookie", SessionCookie+"="+sessionID)
+	p, err := m.Authenticate(r)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, KindSession, p.Kind)
+	assert.Equal(t, storage.APITokenScopeAdmin, p.Scope)
+
+	// Changing the password signs every browser out.
+	require.NoError(t, m.SetPassword(ctx, "battery staple"))
+	p, err = m.Authenticate(r)
+	require.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+func TestManager_Logout(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+	require.NoError(t, m.SetPassword(ctx, "correct horse"))
+	sessionID, _, err := m.Login(ctx, "correct horse")
+	require.NoError(t, err)
+
+	require.NoError(t, m.Logout(ctx, sessionID))
+	p, err := m.Authenticate(requestWith("Cookie", SessionCookie+"="+sessionID))
+	require.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+func TestManager_Tokens(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	tok, secret, err := m.CreateToken(ctx, "grafana", storage.APITokenScopeRead, 0)
+	require.NoError(t, err)
+	assert.Contains(t, secret, TokenPrefix)
+	assert.Equal(t, secret[:len(tok.Prefix)], tok.Prefix)
+	assert.Nil(t, tok.ExpiresAt)
+
+	p, err := m.Authenticate(requestWith("Authorization", "Bearer "+secret))
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, KindToken, p.Kind)
+	assert.Equal(t, storage.APITokenScopeRead, p.Scope)
+	assert.Equal(t, "grafana", p.TokenName)
+
+	listed, err := m.ListTokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.NotNil(t, listed[0].LastUsedAt, "using a token records when")
+
+	p, err = m.Authenticate(requestWith("Authorization", "Bearer agento_not-a-token"))
+	require.NoError(t, err)
+	assert.Nil(t, p)
+
+	require.NoError(t, m.RevokeToken(ctx, tok.ID))
+	assert.ErrorIs(t, m.RevokeToken(ctx, tok.ID), ErrTokenNotFound)
+	p, err = m.Authenticate(requestWith("Authorization", "Bearer "+secret))
+	require.NoError(t, err)
+	assert.Nil(t, p, "a revoked token no longer authenticates")
+}
+
+func TestManager_ExpiredToken(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	_, secret, err := m.CreateToken(ctx, "ci", storage.APITokenScopeRun, time.Hour)
+	require.NoError(t, err)
+	m.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	p, err := m.Authenticate(requestWith("Authorization", "Bearer "+secret))
+	require.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+func TestManager_CreateTokenValidates(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	_, _, err := m.CreateToken(ctx, " ", storage.APITokenScopeRead, 0)
+	assert.Error(t, err)
+	_, _, err = m.CreateToken(ctx, "x", "owner", 0)
+	assert.Error(t, err)
+}
+
+func TestPrincipal_Allows(t *testing.T) {
+	read := &Principal{Kind: KindToken, Scope: storage.APITokenScopeRead}
+	run := &Principal{Kind: KindToken, Scope: storage.APITokenScopeRun}
+	admin := &Principal{Kind: KindToken, Scope: storage.APITokenScopeAdmin}
+	ingest := &Principal{Kind: KindToken, Scope: storage.APITokenScopeIngest}
+
+	tests := []struct {
+		method, path string
+		read, run    bool
+	}{
+		{http.MethodGet, "/api/claude-analytics", true, true},
+		{http.MethodGet, "/api/claude-sessions/abc/journey", true, true},
+		{http.MethodGet, "/api/export/sessions", true, true},
+		{http.MethodGet, "/metrics", true, true},
+		{http.MethodGet, "/v1/models", true, true},
+		{http.MethodGet, "/api/settings", false, false},
+		{http.MethodGet, "/api/integrations", false, false},
+		{http.MethodGet, "/api/auth/tokens", false, false},
+		{http.MethodGet, "/api/agentsx", false, false},
+		{http.MethodPost, "/api/chats", false, true},
+		{http.MethodPost, "/api/chats/c1/messages", false, true},
+		{http.MethodPost, "/api/chats/c1/stop", false, true},
+		{http.MethodPost, "/api/claude-sessions/s1/continue", false, true},
+		{http.MethodPost, "/v1/chat/completions", false, true},
+		{http.MethodDelete, "/mcp", false, true},
+		{http.MethodDelete, "/api/chats/c1", false, false},
+		{http.MethodPost, "/api/agents", false, false},
+		{http.MethodPost, "/api/auth/tokens", false, false},
+		{http.MethodPut, "/api/settings", false, false},
+		{http.MethodPost, "/api/claude-sessions/hooks", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.read, read.Allows(tt.method, tt.path), "read")
+			assert.Equal(t, tt.run, run.Allows(tt.method, tt.path), "run")
+			assert.True(t, admin.Allows(tt.method, tt.path), "admin")
+			want := tt.method == http.MethodPost && tt.path == "/api/claude-sessions/hooks"
+			assert.Equal(t, want, ingest.Allows(tt.method, tt.path), "ingest")
+		})
+	}
+}
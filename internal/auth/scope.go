@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// readPaths are what a read token may GET: analytics, sessions, chats, tasks,
+// and the records around them. Settings and integrations are left out, since
+// they hold credentials.
+var readPaths = []string{ //nolint:gochecknoglobals
+	"/api/claude-sessions",
+	"/api/claude-analytics",
+	"/api/export",
+	"/api/job-history",
+	"/api/tasks",
+	"/api/agents",
+	"/api/chats",
+	"/api/tool-calls",
+	"/api/pricing",
+	"/api/budgets",
+	"/api/approvals",
+	"/api/version",
+	"/api/auth/status",
+	"/metrics",
+	"/v1/models",
+}
+
+// runChatActions are the chat sub-resources a run token may post to: sending
+// a message, answering a question or permission prompt, and stopping a turn.
+var runChatActions = []string{"messages", "input", "permission", "stop"} //nolint:gochecknoglobals
+
+// hookIngestPath is where Claude Code hook events are delivered.
+const hookIngestPath = "/api/claude-sessions/hooks"
+
+// Allows reports whether p may make a request with this method to this path.
+// An admin may do anything; a run token may do what a read token may, and
+// also run agents. An ingest token may only deliver hook events: it sits in a
+// file every Claude Code session can read, so it is good for nothing else.
+func (p *Principal) Allows(method, path string) bool {
+	switch p.Scope {
+	case storage.APITokenScopeAdmin:
+		return true
+	case storage.APITokenScopeRun:
+		return readAllows(method, path) || runAllows(method, path)
+	case storage.APITokenScopeRead:
+		return readAllows(method, path)
+	case storage.APITokenScopeIngest:
+		return method == http.MethodPost && path == hookIngestPath
+	default:
+		return false
+	}
+}
+
+func readAllows(method, path string) bool {
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+	for _, p := range readPaths {
+		if underPath(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func runAllows(method, path string) bool {
+	// MCP is served over GET, POST and DELETE, and every tool it has either
+	// reads or runs an agent.
+	if path == "/mcp" {
+		return true
+	}
+	if method != http.MethodPost {
+		return false
+	}
+	switch path {
+	case "/api/chats", "/api/uploads", "/v1/chat/completions":
+		return true
+	}
+	if rest, ok := strings.CutPrefix(path, "/api/chats/"); ok {
+		id, action, found := strings.Cut(rest, "/")
+		return found && id != "" && slices.Contains(runChatActions, action)
+	}
+	if rest, ok := strings.CutPrefix(path, "/api/claude-sessions/"); ok {
+		id, action, found := strings.Cut(rest, "/")
+		return found && id != "" && action == "continue"
+	}
+	return false
+}
+
+// underPath reports whether path is prefix or below it.
+func underPath(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
@@ -0,0 +1,268 @@
+// Package backup writes agento's state to a single versioned archive and
+// restores it, on this machine or another one.
+//
+// An archive is a gzipped tar whose first entry is manifest.json. It holds a
+// consistent snapshot of agento.db, the configuration files beside it, and
+// the Claude settings profiles. Logs, uploads and the credential encryption
+// key are left out: the key must never travel with the data it protects.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/build"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// FormatVersion is the archive layout this build writes and reads.
+const FormatVersion = 1
+
+// Names of entries in an archive.
+const (
+	manifestName = "manifest.json"
+	databaseName = "agento.db"
+	claudePrefix = "claude/"
+	profilesName = "settings_profiles.json"
+)
+
+// dataFiles are the files and directories under the data directory that an
+// archive carries besides the database. Missing ones are skipped.
+var dataFiles = []string{"mcps.yaml", "monitoring.json", "agents"} //nolint:gochecknoglobals
+
+// Manifest describes an archive.
+type Manifest struct {
+	FormatVersion int       `json:"format_version"`
+	AgentoVersion string    `json:"agento_version"`
+	SchemaVersion int       `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Hostname      string    `json:"hostname"`
+	// IncludesCredentials reports whether integration credentials were kept.
+	// They are encrypted, and restoring them needs the same key.
+	IncludesCredentials bool     `json:"includes_credentials"`
+	Files               []string `json:"files"`
+}
+
+// Paths says where agento's state lives.
+type Paths struct {
+	// DataDir is the data directory, which holds agento.db.
+	DataDir string
+	// ClaudeDir is the Claude config dir that holds the settings profiles.
+	// Empty leaves the profiles out.
+	ClaudeDir string
+}
+
+// Options controls what Create puts in an archive.
+type Options struct {
+	// IncludeCredentials keeps integration credentials and OAuth tokens.
+	IncludeCredentials bool
+}
+
+// entry is a file to add to an archive.
+type entry struct {
+	name string // slash-separated name in the archive
+	path string // file on disk
+}
+
+// Create writes an archive of db and the files under p to dest. The archive
+// appears at dest only once it is complete.
+func Create(ctx context.Context, db *sql.DB, p Paths, dest string, opts Options) (*Manifest, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return nil, fmt.Errorf("creating backup directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(dest), ".agento-backup-")
+	if err != nil {
+		return nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging) //nolint:errcheck
+
+	snapshot := filepath.Join(staging, databaseName)
+	schema, err := storage.SnapshotSQLiteDB(ctx, db, snapshot, opts.IncludeCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := collect(p, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	// The hostname is informational; an archive without one is still whole.
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = ""
+	}
+	m := &Manifest{
+		FormatVersion:       FormatVersion,
+		AgentoVersion:       build.Version,
+		SchemaVersion:       schema,
+		CreatedAt:           time.Now().UTC(),
+		Hostname:            hostname,
+		IncludesCredentials: opts.IncludeCredentials,
+	}
+	for _, e := range entries {
+		m.Files = append(m.Files, e.name)
+	}
+
+	partial := filepath.Join(staging, filepath.Base(dest))
+	if err := writeArchive(partial, m, entries); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(partial, dest); err != nil {
+		return nil, fmt.Errorf("moving backup into place: %w", err)
+	}
+	return m, nil
+}
+
+// collect lists the files an archive of p carries.
+func collect(p Paths, snapshot string) ([]entry, error) {
+	entries := []entry{{name: databaseName, path: snapshot}}
+	for _, name := range dataFiles {
+		found, err := walk(filepath.Join(p.DataDir, name), name)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, found...)
+	}
+	if p.ClaudeDir == "" {
+		return entries, nil
+	}
+	profiles, err := profileEntries(p.ClaudeDir)
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, profiles...), nil
+}
+
+// walk lists the regular files at root, a file or a directory, naming them
+// under name. A missing root yields nothing.
+func walk(root, name string) ([]entry, error) {
+	var found []entry
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == root {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		found = append(found, entry{name: path.Join(name, filepath.ToSlash(rel)), path: p})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", root, err)
+	}
+	return found, nil
+}
+
+// profileEntries lists the settings profiles index in dir and the profile
+// files it names.
+func profileEntries(dir string) ([]entry, error) {
+	index := filepath.Join(dir, profilesName)
+	meta, err := readProfiles(index)
+	if err != nil || meta == nil {
+		return nil, err
+	}
+	entries := []entry{{name: claudePrefix + profilesName, path: index}}
+	for _, prof := range meta.Profiles {
+		if _, err := os.Stat(prof.FilePath); err != nil {
+			continue
+		}
+		entries = append(entries, entry{name: claudePrefix + filepath.Base(prof.FilePath), path: prof.FilePath})
+	}
+	return entries, nil
+}
+
+// readProfiles reads a settings profiles index, or returns nil when there is
+// none.
+func readProfiles(index string) (*config.ProfilesMetadata, error) {
+	data, err := os.ReadFile(index) //nolint:gosec // the path is built from the Claude config dir
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading settings profiles: %w", err)
+	}
+	var meta config.ProfilesMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("parsing settings profiles: %w", err)
+	}
+	return &meta, nil
+}
+
+// writeArchive writes the manifest and then every entry to a new archive at
+// dest.
+func writeArchive(dest string, m *Manifest, entries []entry) (err error) {
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600) //nolint:gosec // dest is in our staging dir
+	if err != nil {
+		return fmt.Errorf("creating backup: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("writing backup: %w", cerr)
+		}
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	if err := writeEntry(tw, manifestName, int64(len(manifest)), m.CreatedAt, bytes.NewReader(manifest)); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := addFile(tw, e); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("writing backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("writing backup: %w", err)
+	}
+	return nil
+}
+
+func addFile(tw *tar.Writer, e entry) error {
+	f, err := os.Open(e.path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", e.path, err)
+	}
+	defer f.Close() //nolint:errcheck
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", e.path, err)
+	}
+	return writeEntry(tw, e.name, info.Size(), info.ModTime(), f)
+}
+
+func writeEntry(tw *tar.Writer, name string, size int64, modTime time.Time, r io.Reader) error {
+	hdr := &tar.Header{Name: name, Mode: 0o600, Size: size, ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	if _, err := io.CopyN(tw, r, size); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+type fixture struct {
+	db    *sql.DB
+	paths Paths
+}
+
+// newFixture builds a data directory with a database holding one
+// integration, an MCP registry, a legacy agent file and a settings profile.
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	root := t.TempDir()
+	p := Paths{DataDir: filepath.Join(root, "data"), ClaudeDir: filepath.Join(root, "claude")}
+	db, _, err := storage.NewSQLiteDB(filepath.Join(p.DataDir, databaseName), slog.Default())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	now := time.Now().UTC()
+	require.NoError(t, storage.NewSQLiteIntegrationStore(db, nil).Save(context.Background(), &config.IntegrationConfig{
+		ID: "github-1", Name: "GitHub", Type: "github",
+		Credentials: json.RawMessage(`{"token":"ghp_secret"}`),
+		CreatedAt:   now, UpdatedAt: now,
+	}))
+
+	writeTestFile(t, filepath.Join(p.DataDir, "mcps.yaml"), "servers: {}\n")
+	writeTestFile(t, filepath.Join(p.DataDir, "agents", "helper.yaml"), "name: helper\n")
+	writeTestFile(t, filepath.Join(p.DataDir, "logs", "system.log"), "not backed up\n")
+	writeTestFile(t, filepath.Join(p.ClaudeDir, "settings_work.json"), `{"model":"opus"}`)
+	meta, err := json.Marshal(config.ProfilesMetadata{Profiles: []config.ClaudeSettingsProfile{
+		{ID: "work", Name: "Work", FilePath: filepath.Join(p.ClaudeDir, "settings_work.json")},
+	}})
+	require.NoError(t, err)
+	writeTestFile(t, filepath.Join(p.ClaudeDir, profilesName), string(meta))
+	return &fixture{db: db, paths: p}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func credentialsIn(t *testing.T, dbPath string) string {
+	t.Helper()
+	db, err := sql.Open("sqlite", dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+	var credentials string
+	require.NoError(t, db.QueryRow(`SELECT credentials FROM integrations WHERE id = 'github-1'`).Scan(&credentials))
+	return credentials
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	archive := filepath.Join(t.TempDir(), ArchiveName(time.Now(), false))
+
+	m, err := Create(ctx, f.db, f.paths, archive, Options{IncludeCredentials: true})
+	require.NoError(t, err)
+	assert.Equal(t, FormatVersion, m.FormatVersion)
+	assert.Equal(t, storage.LatestSchemaVersion(), m.SchemaVersion)
+	assert.ElementsMatch(t, []string{
+		"agento.db", "mcps.yaml", "agents/helper.yaml", "claude/settings_profiles.json", "claude/settings_work.json",
+	}, m.Files, "logs are not backed up")
+
+	read, err := ReadManifest(archive)
+	require.NoError(t, err)
+	assert.Equal(t, m.Files, read.Files)
+
+	// Restore onto another machine, whose Claude dir is elsewhere.
+	root := t.TempDir()
+	target := Paths{DataDir: filepath.Join(root, "data"), ClaudeDir: filepath.Join(root, "claude")}
+	writeTestFile(t, filepath.Join(target.DataDir, "mcps.yaml"), "old\n")
+
+	res, err := Restore(ctx, archive, target, RestoreOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.SetAside)
+	old, err := os.ReadFile(filepath.Join(res.SetAside, "mcps.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "old\n", string(old), "a replaced file is moved aside")
+
+	assert.Equal(t, `{"token":"ghp_secret"}`, credentialsIn(t, filepath.Join(target.DataDir, databaseName)))
+	agent, err := os.ReadFile(filepath.Join(target.DataDir, "agents", "helper.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "name: helper\n", string(agent))
+
+	meta, err := readProfiles(filepath.Join(target.ClaudeDir, profilesName))
+	require.NoError(t, err)
+	require.Len(t, meta.Profiles, 1)
+	assert.Equal(t, filepath.Join(target.ClaudeDir, "settings_work.json"), meta.Profiles[0].FilePath,
+		"a profile points at its file on the new machine")
+	assert.FileExists(t, meta.Profiles[0].FilePath)
+}
+
+func TestCreate_ExcludesCredentials(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	archive := filepath.Join(t.TempDir(), ArchiveName(time.Now(), false))
+
+	m, err := Create(ctx, f.db, f.paths, archive, Options{})
+	require.NoError(t, err)
+	assert.False(t, m.IncludesCredentials)
+
+	target := Paths{DataDir: t.TempDir()}
+	_, err = Restore(ctx, archive, target, RestoreOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "{}", credentialsIn(t, filepath.Join(target.DataDir, databaseName)))
+	assert.Equal(t, `{"token":"ghp_secret"}`, credentialsIn(t, filepath.Join(f.paths.DataDir, databaseName)),
+		"the live database keeps its credentials")
+}
+
+// writeRawArchive writes an archive with the given manifest and entries,
+// for the cases Create never produces.
+func writeRawArchive(t *testing.T, m Manifest, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "crafted.tar.gz")
+	out, err := os.Create(path)
+	require.NoError(t, err)
+	defer out.Close()
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	manifest, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: manifestName, Mode: 0o600, Size: int64(len(manifest)), Typeflag: tar.TypeReg}))
+	_, err = tw.Write(manifest)
+	require.NoError(t, err)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err = tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return path
+}
+
+func TestRestore_RefusesNewerSchema(t *testing.T) {
+	archive := writeRawArchive(t, Manifest{
+		FormatVersion: FormatVersion, SchemaVersion: storage.LatestSchemaVersion() + 1, Files: []string{},
+	}, nil)
+	_, err := Restore(context.Background(), archive, Paths{DataDir: t.TempDir()}, RestoreOptions{})
+	assert.ErrorIs(t, err, ErrNewerSchema)
+}
+
+func TestRestore_RefusesUnexpectedEntries(t *testing.T) {
+	dataDir := t.TempDir()
+	for _, name := range []string{"../escape", "logs/system.log", "claude/nested/file", "unlisted.yaml"} {
+		files := []string{name}
+		if name == "unlisted.yaml" {
+			files = nil
+		}
+		archive := writeRawArchive(t, Manifest{FormatVersion: FormatVersion, Files: files},
+			map[string]string{name: "x"})
+		_, err := Restore(context.Background(), archive, Paths{DataDir: dataDir}, RestoreOptions{})
+		assert.Error(t, err, name)
+	}
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(dataDir), "escape"))
+}
+
+func TestRestore_RefusesDamagedDatabase(t *testing.T) {
+	archive := writeRawArchive(t, Manifest{FormatVersion: FormatVersion, Files: []string{databaseName}},
+		map[string]string{databaseName: "not a database"})
+	dataDir := t.TempDir()
+	writeTestFile(t, filepath.Join(dataDir, databaseName), "current")
+
+	_, err := Restore(context.Background(), archive, Paths{DataDir: dataDir}, RestoreOptions{})
+	require.Error(t, err)
+	current, err := os.ReadFile(filepath.Join(dataDir, databaseName))
+	require.NoError(t, err)
+	assert.Equal(t, "current", string(current), "nothing is replaced when the archive fails its checks")
+}
@@ -0,0 +1,312 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// ErrNewerSchema is returned when an archive was made by a newer agento,
+// whose database this build cannot migrate.
+var ErrNewerSchema = errors.New("backup was made by a newer version of agento")
+
+// RestoreOptions controls what Restore puts back.
+type RestoreOptions struct {
+	// SkipClaudeProfiles leaves the Claude settings profiles as they are.
+	SkipClaudeProfiles bool
+}
+
+// RestoreResult describes a finished restore.
+type RestoreResult struct {
+	Manifest *Manifest
+	// SetAside is the directory the replaced files were moved to, or empty
+	// when nothing was replaced.
+	SetAside string
+}
+
+// Restore replaces the state under p with the archive at archivePath. The
+// archive is unpacked and checked in full before anything is replaced, and
+// the files it replaces are moved aside rather than deleted. agento web must
+// not be running.
+func Restore(ctx context.Context, archivePath string, p Paths, opts RestoreOptions) (*RestoreResult, error) {
+	if err := os.MkdirAll(p.DataDir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating data directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(p.DataDir, ".agento-restore-")
+	if err != nil {
+		return nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging) //nolint:errcheck
+
+	m, err := extract(archivePath, staging)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(ctx, m, staging); err != nil {
+		return nil, err
+	}
+
+	setAside := filepath.Join(p.DataDir, "pre-restore-"+time.Now().UTC().Format(stampLayout))
+	moved, err := replaceData(staging, p.DataDir, setAside)
+	if err != nil {
+		return nil, err
+	}
+	if p.ClaudeDir != "" && !opts.SkipClaudeProfiles {
+		n, err := replaceProfiles(staging, p.ClaudeDir, filepath.Join(setAside, "claude"))
+		if err != nil {
+			return nil, err
+		}
+		moved += n
+	}
+
+	res := &RestoreResult{Manifest: m}
+	if moved > 0 {
+		res.SetAside = setAside
+	}
+	return res, nil
+}
+
+// ReadManifest returns the manifest of the archive at archivePath.
+func ReadManifest(archivePath string) (*Manifest, error) {
+	f, err := os.Open(archivePath) //nolint:gosec // the path is the user's own archive
+	if err != nil {
+		return nil, fmt.Errorf("opening backup: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading backup: %w", err)
+	}
+	return readManifest(tar.NewReader(gz))
+}
+
+// readManifest reads the manifest, which must be the first entry.
+func readManifest(tr *tar.Reader) (*Manifest, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("reading backup: %w", err)
+	}
+	if hdr.Name != manifestName {
+		return nil, fmt.Errorf("not an agento backup: it does not start with %s", manifestName)
+	}
+	var m Manifest
+	if err := json.NewDecoder(io.LimitReader(tr, 1<<20)).Decode(&m); err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	if m.FormatVersion < 1 || m.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("%w: archive format %d, this build reads up to %d",
+			ErrNewerSchema, m.FormatVersion, FormatVersion)
+	}
+	return &m, nil
+}
+
+// extract unpacks the archive into dir, accepting only the entries its
+// manifest lists.
+func extract(archivePath, dir string) (*Manifest, error) {
+	f, err := os.Open(archivePath) //nolint:gosec // the path is the user's own archive
+	if err != nil {
+		return nil, fmt.Errorf("opening backup: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading backup: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	m, err := readManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return m, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading backup: %w", err)
+		}
+		if err := validName(hdr, m); err != nil {
+			return nil, err
+		}
+		if err := writeFile(filepath.Join(dir, filepath.FromSlash(hdr.Name)), tr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// validName rejects an entry that is not a regular file the manifest lists
+// under a name agento writes.
+func validName(hdr *tar.Header, m *Manifest) error {
+	name := hdr.Name
+	switch {
+	case hdr.Typeflag != tar.TypeReg,
+		name != path.Clean(name), path.IsAbs(name), strings.HasPrefix(name, "../"),
+		!slices.Contains(m.Files, name):
+		return fmt.Errorf("backup holds an unexpected entry %q", name)
+	}
+	top, _, _ := strings.Cut(name, "/")
+	if name == databaseName || slices.Contains(dataFiles, top) ||
+		(top+"/" == claudePrefix && strings.Count(name, "/") == 1) {
+		return nil
+	}
+	return fmt.Errorf("backup holds an unexpected entry %q", name)
+}
+
+func writeFile(dest string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return fmt.Errorf("unpacking backup: %w", err)
+	}
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600) //nolint:gosec // dest is checked by validName
+	if err != nil {
+		return fmt.Errorf("unpacking backup: %w", err)
+	}
+	if _, err := io.Copy(f, r); err != nil { //nolint:gosec // the archive is the user's own
+		f.Close() //nolint:errcheck,gosec
+		return fmt.Errorf("unpacking backup: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unpacking backup: %w", err)
+	}
+	return nil
+}
+
+// check confirms the unpacked database is whole and one this build can
+// migrate.
+func check(ctx context.Context, m *Manifest, staging string) error {
+	if m.SchemaVersion > storage.LatestSchemaVersion() {
+		return fmt.Errorf("%w: its database is at schema version %d, this build knows up to %d; update agento first",
+			ErrNewerSchema, m.SchemaVersion, storage.LatestSchemaVersion())
+	}
+	version, err := storage.CheckSQLiteDB(ctx, filepath.Join(staging, databaseName))
+	if err != nil {
+		return fmt.Errorf("checking the backed-up database: %w", err)
+	}
+	if version != m.SchemaVersion {
+		return fmt.Errorf("the backed-up database is at schema version %d, but the manifest says %d",
+			version, m.SchemaVersion)
+	}
+	return nil
+}
+
+// replaceData moves the database and data files from staging into dataDir,
+// moving whatever they replace into setAside. It returns how many were
+// moved aside.
+func replaceData(staging, dataDir, setAside string) (int, error) {
+	// The write-ahead log and shared memory belong to the database they sit
+	// beside, so they are moved aside with it.
+	names := append([]string{databaseName, databaseName + "-wal", databaseName + "-shm"}, dataFiles...)
+	moved := 0
+	for _, name := range names {
+		ok, err := moveAside(filepath.Join(dataDir, name), filepath.Join(setAside, name))
+		if err != nil {
+			return moved, err
+		}
+		if ok {
+			moved++
+		}
+		src := filepath.Join(staging, name)
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := os.Rename(src, filepath.Join(dataDir, name)); err != nil {
+			return moved, fmt.Errorf("restoring %s: %w", name, err)
+		}
+	}
+	return moved, nil
+}
+
+// replaceProfiles writes the backed-up settings profiles into claudeDir,
+// pointing each at its file there, and moves the files they replace into
+// setAside.
+func replaceProfiles(staging, claudeDir, setAside string) (int, error) {
+	meta, err := readProfiles(filepath.Join(staging, "claude", profilesName))
+	if err != nil || meta == nil {
+		return 0, err
+	}
+	moved := 0
+	for i, prof := range meta.Profiles {
+		base := filepath.Base(prof.FilePath)
+		meta.Profiles[i].FilePath = filepath.Join(claudeDir, base)
+		n, err := restoreFile(filepath.Join(staging, "claude", base), meta.Profiles[i].FilePath, setAside)
+		if err != nil {
+			return moved, err
+		}
+		moved += n
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return moved, fmt.Errorf("encoding settings profiles: %w", err)
+	}
+	index := filepath.Join(staging, "claude", profilesName)
+	if err := os.WriteFile(index, data, 0o600); err != nil {
+		return moved, fmt.Errorf("writing settings profiles: %w", err)
+	}
+	n, err := restoreFile(index, filepath.Join(claudeDir, profilesName), setAside)
+	return moved + n, err
+}
+
+// restoreFile copies src to dest, moving an existing dest into setAside
+// first. A missing src is skipped. It returns 1 when a file was moved aside.
+func restoreFile(src, dest, setAside string) (int, error) {
+	data, err := os.ReadFile(src) //nolint:gosec // src is in our staging dir
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("restoring %s: %w", dest, err)
+	}
+	moved, err := moveAside(dest, filepath.Join(setAside, filepath.Base(dest)))
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return 0, fmt.Errorf("restoring %s: %w", dest, err)
+	}
+	if err := os.WriteFile(dest, data, 0o600); err != nil {
+		return 0, fmt.Errorf("restoring %s: %w", dest, err)
+	}
+	if moved {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// moveAside moves src to dest if it exists. Profiles may live on another
+// filesystem than the data directory, so a failed rename falls back to a
+// copy.
+func moveAside(src, dest string) (bool, error) {
+	if _, err := os.Lstat(src); os.IsNotExist(err) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return false, fmt.Errorf("setting %s aside: %w", src, err)
+	}
+	if err := os.Rename(src, dest); err == nil {
+		return true, nil
+	}
+	data, err := os.ReadFile(src) //nolint:gosec // src is one of agento's own files
+	if err != nil {
+		return false, fmt.Errorf("setting %s aside: %w", src, err)
+	}
+	if err := os.WriteFile(dest, data, 0o600); err != nil {
+		return false, fmt.Errorf("setting %s aside: %w", src, err)
+	}
+	if err := os.Remove(src); err != nil {
+		return false, fmt.Errorf("setting %s aside: %w", src, err)
+	}
+	return true, nil
+}
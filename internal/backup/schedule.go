@@ -0,0 +1,232 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Archive file names read agento-backup-<UTC time>[-auto].tar.gz. The time
+// orders them, and -auto marks the ones retention may delete.
+const (
+	stampLayout   = "20060102T150405Z"
+	archivePrefix = "agento-backup-"
+	autoSuffix    = "-auto"
+	archiveExt    = ".tar.gz"
+)
+
+// checkInterval is how often the scheduler looks for a due backup. Settings
+// are read on every check, so a change applies within it.
+const checkInterval = 10 * time.Minute
+
+// ArchiveName returns the file name of an archive made at t.
+func ArchiveName(t time.Time, automatic bool) string {
+	name := archivePrefix + t.UTC().Format(stampLayout)
+	if automatic {
+		name += autoSuffix
+	}
+	return name + archiveExt
+}
+
+// Archive is a backup in a backup directory.
+type Archive struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	// Automatic reports whether the scheduler made it. Only automatic
+	// backups are deleted by retention.
+	Automatic bool `json:"automatic"`
+}
+
+// List returns the archives in dir, newest first. A missing dir has none.
+func List(dir string) ([]Archive, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []Archive{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing backups: %w", err)
+	}
+	archives := make([]Archive, 0, len(entries))
+	for _, e := range entries {
+		a, ok := parseArchiveName(e.Name())
+		if !ok || !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		a.Path = filepath.Join(dir, a.Name)
+		a.Size = info.Size()
+		archives = append(archives, a)
+	}
+	sort.Slice(archives, func(i, j int) bool { return archives[i].CreatedAt.After(archives[j].CreatedAt) })
+	return archives, nil
+}
+
+func parseArchiveName(name string) (Archive, bool) {
+	stamp, ok := strings.CutPrefix(name, archivePrefix)
+	if !ok {
+		return Archive{}, false
+	}
+	if stamp, ok = strings.CutSuffix(stamp, archiveExt); !ok {
+		return Archive{}, false
+	}
+	stamp, automatic := strings.CutSuffix(stamp, autoSuffix)
+	t, err := time.Parse(stampLayout, stamp)
+	if err != nil {
+		return Archive{}, false
+	}
+	return Archive{Name: name, CreatedAt: t, Automatic: automatic}, true
+}
+
+// Prune deletes all but the newest keep automatic archives in dir, and
+// returns how many it deleted. Backups made by hand are never deleted.
+func Prune(dir string, keep int) (int, error) {
+	archives, err := List(dir)
+	if err != nil {
+		return 0, err
+	}
+	deleted, kept := 0, 0
+	for _, a := range archives {
+		if !a.Automatic {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+		if err := os.Remove(a.Path); err != nil {
+			return deleted, fmt.Errorf("deleting old backup: %w", err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// Schedule is how automatic backups are made.
+type Schedule struct {
+	// Interval is the time between automatic backups. Zero turns them off.
+	Interval time.Duration
+	// Keep is how many automatic backups are kept.
+	Keep int
+	// Dir is where backups are written.
+	Dir string
+	// IncludeCredentials keeps integration credentials in every backup.
+	IncludeCredentials bool
+}
+
+// Scheduler makes automatic backups while agento web runs, and backups on
+// demand from the API.
+type Scheduler struct {
+	db       *sql.DB
+	paths    Paths
+	schedule func() Schedule
+	logger   *slog.Logger
+	now      func() time.Time
+	// mu keeps a scheduled backup and one made on demand from overlapping.
+	mu sync.Mutex
+}
+
+// NewScheduler returns a Scheduler that backs up db and the files under
+// paths. schedule is called on every check, so it may read settings the user
+// changes while the server runs.
+func NewScheduler(db *sql.DB, paths Paths, schedule func() Schedule, logger *slog.Logger) *Scheduler {
+	return &Scheduler{db: db, paths: paths, schedule: schedule, logger: logger, now: time.Now}
+}
+
+// Start launches the scheduler and returns immediately. Cancel ctx to stop
+// it. The time of the newest automatic backup is read from the backup
+// directory, so a restart neither skips a backup nor makes an extra one.
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.RunDue(ctx); err != nil {
+				s.logger.Error("automatic backup failed", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunDue makes an automatic backup when the interval has passed since the
+// last one, then deletes the ones past retention. It returns nil when no
+// backup was due.
+func (s *Scheduler) RunDue(ctx context.Context) (*Archive, error) {
+	sched := s.schedule()
+	if sched.Interval <= 0 {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	archives, err := List(sched.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range archives {
+		if a.Automatic {
+			if s.now().Sub(a.CreatedAt) < sched.Interval {
+				return nil, nil
+			}
+			break
+		}
+	}
+
+	a, err := s.create(ctx, sched, true)
+	if err != nil {
+		return nil, err
+	}
+	// The backup just made is always kept.
+	n, err := Prune(sched.Dir, max(sched.Keep, 1))
+	if err != nil {
+		return a, err
+	}
+	s.logger.Info("automatic backup written", "path", a.Path, "size", a.Size, "pruned", n)
+	return a, nil
+}
+
+// BackupNow makes a backup outside the schedule, in the same directory.
+// Retention does not apply to it.
+func (s *Scheduler) BackupNow(ctx context.Context) (*Archive, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.create(ctx, s.schedule(), false)
+}
+
+// List returns the backups in the backup directory, newest first.
+func (s *Scheduler) List() ([]Archive, error) {
+	return List(s.schedule().Dir)
+}
+
+func (s *Scheduler) create(ctx context.Context, sched Schedule, automatic bool) (*Archive, error) {
+	now := s.now()
+	name := ArchiveName(now, automatic)
+	dest := filepath.Join(sched.Dir, name)
+	if _, err := Create(ctx, s.db, s.paths, dest, Options{IncludeCredentials: sched.IncludeCredentials}); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		return nil, fmt.Errorf("reading backup: %w", err)
+	}
+	return &Archive{
+		Name: name, Path: dest, Size: info.Size(),
+		CreatedAt: now.UTC().Truncate(time.Second), Automatic: automatic,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package backup
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrune_KeepsNewestAutomaticAndEveryManual(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		writeTestFile(t, filepath.Join(dir, ArchiveName(start.Add(time.Duration(i)*24*time.Hour), true)), "x")
+	}
+	manual := ArchiveName(start.Add(-time.Hour), false)
+	writeTestFile(t, filepath.Join(dir, manual), "x")
+	writeTestFile(t, filepath.Join(dir, "notes.txt"), "x")
+
+	n, err := Prune(dir, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	archives, err := List(dir)
+	require.NoError(t, err)
+	require.Len(t, archives, 3)
+	assert.Equal(t, ArchiveName(start.Add(4*24*time.Hour), true), archives[0].Name, "newest first")
+	assert.Equal(t, ArchiveName(start.Add(3*24*time.Hour), true), archives[1].Name)
+	assert.Equal(t, manual, archives[2].Name)
+	assert.False(t, archives[2].Automatic)
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	sched := Schedule{Dir: dir, Keep: 2}
+	s := NewScheduler(f.db, f.paths, func() Schedule { return sched }, slog.Default())
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	a, err := s.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, a, "no interval means automatic backups are off")
+
+	sched.Interval = 24 * time.Hour
+	a, err = s.RunDue(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, a)
+	assert.True(t, a.Automatic)
+
+	now = now.Add(time.Hour)
+	a, err = s.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, a, "the last backup is recent")
+
+	for range 3 {
+		now = now.Add(25 * time.Hour)
+		a, err = s.RunDue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, a)
+	}
+	_, err = s.BackupNow(ctx)
+	require.NoError(t, err)
+
+	archives, err := List(dir)
+	require.NoError(t, err)
+	assert.Len(t, archives, 3, "two automatic backups are kept, plus the one made on demand")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "no staging directory is left behind")
+}
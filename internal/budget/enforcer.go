@@ -0,0 +1,256 @@
+// Package budget enforces USD spending caps on agent runs. Policies and the
+// spend ledger live in storage.BudgetStore; this package turns them into the
+// agent.BudgetGuard the runner consults.
+package budget
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/pricing"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// EventBudgetExceeded is published when a run is refused or aborted by a cap.
+const EventBudgetExceeded = "budget.limit.exceeded"
+
+// EventPublisher allows the enforcer to emit events without depending on a
+// concrete event bus implementation.
+type EventPublisher interface {
+	Publish(eventType string, payload map[string]string)
+}
+
+// RateSource supplies the pricing catalog used to estimate a run's cost while
+// it streams. *pricing.Store satisfies it.
+type RateSource interface {
+	Snapshot(ctx context.Context) ([]pricing.Rate, error)
+}
+
+// Enforcer hands out a BudgetGuard per run.
+type Enforcer struct {
+	store     storage.BudgetStore
+	rates     RateSource
+	publisher EventPublisher
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewEnforcer returns an Enforcer. rates and publisher may be nil: without
+// rates a run is only checked before it starts, and without a publisher no
+// event is emitted when a cap trips.
+func NewEnforcer(
+	store storage.BudgetStore, rates RateSource, publisher EventPublisher, logger *slog.Logger,
+) *Enforcer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Enforcer{
+		store:     store,
+		rates:     rates,
+		publisher: publisher,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// ForRun returns a guard for one run of agentSlug. taskID is empty for runs
+// that are not scheduled tasks. source names the caller on the ledger, e.g.
+// "scheduled_task" or "trigger".
+func (e *Enforcer) ForRun(agentSlug, taskID, source string) agent.BudgetGuard {
+	return &runGuard{
+		e:         e,
+		agentSlug: agentSlug,
+		taskID:    taskID,
+		source:    source,
+		messages:  make(map[string]float64),
+	}
+}
+
+// headroom is one applicable policy and what was already spent against it
+// when the run was admitted.
+type headroom struct {
+	policy *storage.BudgetPolicy
+	spent  float64
+}
+
+// runGuard implements agent.BudgetGuard for a single run.
+type runGuard struct {
+	e         *Enforcer
+	agentSlug string
+	taskID    string
+	source    string
+
+	mu       sync.Mutex
+	policies []headroom
+	resolver *pricing.Resolver
+	messages map[string]float64 // message ID → estimated cost
+	estimate float64
+	tripped  bool
+}
+
+// Admit loads every enabled policy that applies to this run and refuses it
+// when any of them is already spent.
+func (g *runGuard) Admit(ctx context.Context) error {
+	all, err := g.e.store.ListPolicies(ctx)
+	if err != nil {
+		return fmt.Errorf("loading budget policies: %w", err)
+	}
+
+	now := g.e.now()
+	var policies []headroom
+	for _, p := range all {
+		if !g.applies(p) {
+			continue
+		}
+		spent, sumErr := g.e.store.SumSpend(ctx, p.Scope, p.ScopeID, p.Period.WindowStart(now))
+		if sumErr != nil {
+			return fmt.Errorf("summing spend for budget %q: %w", p.ID, sumErr)
+		}
+		if spent >= p.LimitUSD {
+			return g.trip(p, spent, "refused")
+		}
+		policies = append(policies, headroom{policy: p, spent: spent})
+	}
+
+	g.mu.Lock()
+	g.policies = policies
+	g.mu.Unlock()
+
+	if len(policies) > 0 && g.e.rates != nil {
+		rates, snapErr := g.e.rates.Snapshot(ctx)
+		if snapErr != nil {
+			// Not fatal: the run was admitted and will still be settled from
+			// the CLI's own figure, it just cannot be stopped part-way.
+			g.e.logger.Warn("budget: pricing snapshot failed; in-flight checks disabled", "error", snapErr)
+		} else {
+			g.mu.Lock()
+			g.resolver = pricing.NewResolver(rates)
+			g.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// Observe re-estimates the run's cost and aborts it once any applicable cap
+// would be exceeded.
+func (g *runGuard) Observe(messageID, model string, usage pricing.Usage) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.resolver == nil || g.tripped {
+		return nil
+	}
+	resolved, ok := g.resolver.Resolve(model, g.e.now())
+	if !ok {
+		return nil
+	}
+	cost := resolved.Rate.Price(usage).TotalCostUSD
+	g.estimate += cost - g.messages[messageID]
+	g.messages[messageID] = cost
+
+	for _, h := range g.policies {
+		if total := h.spent + g.estimate; total > h.policy.LimitUSD {
+			g.tripped = true
+			return g.trip(h.policy, total, "aborted")
+		}
+	}
+	return nil
+}
+
+// Settle records the run on the ledger. A zero cost writes nothing.
+func (g *runGuard) Settle(ctx context.Context, reportedCostUSD float64) {
+	g.mu.Lock()
+	cost := reportedCostUSD
+	if cost <= 0 {
+		cost = g.estimate
+	}
+	g.mu.Unlock()
+	if cost <= 0 {
+		return
+	}
+
+	err := g.e.store.RecordSpend(ctx, &storage.BudgetSpend{
+		AgentSlug: g.agentSlug,
+		TaskID:    g.taskID,
+		Source:    g.source,
+		CostUSD:   cost,
+		SpentAt:   g.e.now(),
+	})
+	if err != nil {
+		g.e.logger.Error("budget: failed to record spend",
+			"agent_slug", g.agentSlug, "task_id", g.taskID, "cost_usd", cost, "error", err)
+	}
+}
+
+// applies reports whether p caps this run.
+func (g *runGuard) applies(p *storage.BudgetPolicy) bool {
+	if !p.Enabled {
+		return false
+	}
+	switch p.Scope {
+	case storage.BudgetScopeGlobal:
+		return true
+	case storage.BudgetScopeAgent:
+		return p.ScopeID != "" && p.ScopeID == g.agentSlug
+	case storage.BudgetScopeTask:
+		return p.ScopeID != "" && p.ScopeID == g.taskID
+	}
+	return false
+}
+
+// trip publishes the exceeded event and returns the error the runner surfaces.
+// action is "refused" for a run stopped before it started and "aborted" for
+// one stopped part-way.
+func (g *runGuard) trip(p *storage.BudgetPolicy, spent float64, action string) error {
+	g.e.logger.Warn("budget exceeded",
+		"policy_id", p.ID, "scope", p.Scope, "scope_id", p.ScopeID,
+		"period", p.Period, "limit_usd", p.LimitUSD, "spent_usd", spent,
+		"agent_slug", g.agentSlug, "task_id", g.taskID, "action", action)
+
+	if g.e.publisher != nil {
+		g.e.publisher.Publish(EventBudgetExceeded, map[string]string{
+			"Budget":    policyLabel(p),
+			"Scope":     string(p.Scope),
+			"Period":    string(p.Period),
+			"Limit":     fmt.Sprintf("$%.2f", p.LimitUSD),
+			"Spent":     fmt.Sprintf("$%.2f", spent),
+			"Agent":     g.agentSlug,
+			"Task ID":   g.taskID,
+			"Source":    g.source,
+			"Run":       capitalize(action),
+			"Policy ID": p.ID,
+		})
+	}
+
+	return &agent.BudgetExceededError{
+		PolicyID:   p.ID,
+		PolicyName: p.Name,
+		Scope:      string(p.Scope),
+		ScopeID:    p.ScopeID,
+		Period:     string(p.Period),
+		LimitUSD:   p.LimitUSD,
+		SpentUSD:   spent,
+	}
+}
+
+func policyLabel(p *storage.BudgetPolicy) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	if p.ScopeID != "" {
+		return string(p.Scope) + " " + p.ScopeID
+	}
+	return string(p.Scope)
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,168 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/pricing"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+type stubPublisher struct {
+	mu     sync.Mutex
+	events []map[string]string
+}
+
+func (p *stubPublisher) Publish(eventType string, payload map[string]string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if eventType == EventBudgetExceeded {
+		p.events = append(p.events, payload)
+	}
+}
+
+type stubRates []pricing.Rate
+
+func (r stubRates) Snapshot(_ context.Context) ([]pricing.Rate, error) { return r, nil }
+
+// testRates prices "test-model" at $1 per million input and $10 per million
+// output tokens, which keeps the arithmetic in the tests readable.
+var testRates = stubRates{{
+	ModelPattern:  "test-model",
+	MatchType:     pricing.MatchExact,
+	InputPerMTok:  1,
+	OutputPerMTok: 10,
+	Billable:      true,
+}}
+
+func newTestEnforcer(t *testing.T) (*Enforcer, storage.BudgetStore, *stubPublisher) {
+	t.Helper()
+	db, _, err := storage.NewSQLiteDB(":memory:", slog.Default())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	store := storage.NewSQLiteBudgetStore(db)
+	pub := &stubPublisher{}
+	e := NewEnforcer(store, testRates, pub, slog.Default())
+	e.now = func() time.Time { return time.Date(2026, 5, 14, 12, 0, 0, 0, time.UTC) }
+	return e, store, pub
+}
+
+func createPolicy(t *testing.T, store storage.BudgetStore, p storage.BudgetPolicy) {
+	t.Helper()
+	p.Enabled = true
+	require.NoError(t, store.CreatePolicy(context.Background(), &p))
+}
+
+func TestAdmit_RefusesWhenSpent(t *testing.T) {
+	e, store, pub := newTestEnforcer(t)
+	ctx := context.Background()
+	createPolicy(t, store, storage.BudgetPolicy{
+		Scope: storage.BudgetScopeAgent, ScopeID: "writer",
+		Period: storage.BudgetPeriodDaily, LimitUSD: 5,
+	})
+	require.NoError(t, store.RecordSpend(ctx, &storage.BudgetSpend{
+		AgentSlug: "writer", CostUSD: 5, SpentAt: e.now(),
+	}))
+
+	err := e.ForRun("writer", "", "trigger").Admit(ctx)
+
+	var be *agent.BudgetExceededError
+	require.True(t, errors.As(err, &be), "expected BudgetExceededError, got %v", err)
+	assert.InDelta(t, 5.0, be.SpentUSD, 1e-9)
+	require.Len(t, pub.events, 1)
+	assert.Equal(t, "Refused", pub.events[0]["Run"])
+	assert.Equal(t, "writer", pub.events[0]["Agent"])
+}
+
+func TestAdmit_IgnoresOtherScopesAndDisabledPolicies(t *testing.T) {
+	e, store, _ := newTestEnforcer(t)
+	ctx := context.Background()
+	createPolicy(t, store, storage.BudgetPolicy{
+		Scope: storage.BudgetScopeAgent, ScopeID: "other",
+		Period: storage.BudgetPeriodDaily, LimitUSD: 1,
+	})
+	disabled := storage.BudgetPolicy{
+		Scope: storage.BudgetScopeGlobal, Period: storage.BudgetPeriodDaily, LimitUSD: 1,
+	}
+	require.NoError(t, store.CreatePolicy(ctx, &disabled))
+	require.NoError(t, store.RecordSpend(ctx, &storage.BudgetSpend{
+		AgentSlug: "other", CostUSD: 3, SpentAt: e.now(),
+	}))
+
+	assert.NoError(t, e.ForRun("writer", "", "trigger").Admit(ctx))
+}
+
+func TestAdmit_SpendBeforeWindowDoesNotCount(t *testing.T) {
+	e, store, _ := newTestEnforcer(t)
+	ctx := context.Background()
+	createPolicy(t, store, storage.BudgetPolicy{
+		Scope: storage.BudgetScopeTask, ScopeID: "task-1",
+		Period: storage.BudgetPeriodDaily, LimitUSD: 2,
+	})
+	require.NoError(t, store.RecordSpend(ctx, &storage.BudgetSpend{
+		TaskID: "task-1", CostUSD: 10, SpentAt: e.now().AddDate(0, 0, -1),
+	}))
+
+	assert.NoError(t, e.ForRun("", "task-1", "scheduled_task").Admit(ctx))
+}
+
+func TestObserve_AbortsOnceRunningCostExceedsLimit(t *testing.T) {
+	e, store, pub := newTestEnforcer(t)
+	ctx := context.Background()
+	createPolicy(t, store, storage.BudgetPolicy{
+		Scope: storage.BudgetScopeGlobal, Period: storage.BudgetPeriodMonthly, LimitUSD: 1,
+	})
+	require.NoError(t, store.RecordSpend(ctx, &storage.BudgetSpend{CostUSD: 0.5, SpentAt: e.now()}))
+
+	g := e.ForRun("writer", "", "trigger")
+	require.NoError(t, g.Admit(ctx))
+
+	// $0.30 of output: 0.5 + 0.3 is still under the $1 cap.
+	require.NoError(t, g.Observe("msg-1", "test-model", pricing.Usage{OutputTokens: 30_000}))
+	// The same message re-emitted with the same usage must not double-count.
+	require.NoError(t, g.Observe("msg-1", "test-model", pricing.Usage{OutputTokens: 30_000}))
+	// A second message of $0.30 takes the total to $1.10.
+	err := g.Observe("msg-2", "test-model", pricing.Usage{OutputTokens: 30_000})
+
+	var be *agent.BudgetExceededError
+	require.True(t, errors.As(err, &be), "expected BudgetExceededError, got %v", err)
+	assert.InDelta(t, 1.1, be.SpentUSD, 1e-9)
+	require.Len(t, pub.events, 1)
+	assert.Equal(t, "Aborted", pub.events[0]["Run"])
+}
+
+func TestSettle_RecordsReportedCostOrEstimate(t *testing.T) {
+	e, store, _ := newTestEnforcer(t)
+	ctx := context.Background()
+	createPolicy(t, store, storage.BudgetPolicy{
+		Scope: storage.BudgetScopeGlobal, Period: storage.BudgetPeriodDaily, LimitUSD: 100,
+	})
+	since := storage.BudgetPeriodDaily.WindowStart(e.now())
+
+	g := e.ForRun("writer", "task-1", "scheduled_task")
+	require.NoError(t, g.Admit(ctx))
+	require.NoError(t, g.Observe("m", "test-model", pricing.Usage{InputTokens: 1_000_000}))
+	g.Settle(ctx, 0.25)
+
+	total, err := store.SumSpend(ctx, storage.BudgetScopeTask, "task-1", since)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.25, total, 1e-9, "the CLI's reported cost wins over the estimate")
+
+	g = e.ForRun("writer", "task-1", "scheduled_task")
+	require.NoError(t, g.Admit(ctx))
+	require.NoError(t, g.Observe("m", "test-model", pricing.Usage{InputTokens: 1_000_000}))
+	g.Settle(ctx, 0)
+
+	total, err = store.SumSpend(ctx, storage.BudgetScopeTask, "task-1", since)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.25, total, 1e-9, "a run without a result is charged its estimate")
+}
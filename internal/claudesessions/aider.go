@@ -0,0 +1,320 @@
+package claudesessions
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// aiderHistoryFile is the chat log Aider appends to in the directory it runs
+// in, every session it runs there one after another.
+const aiderHistoryFile = ".aider.chat.history.md"
+
+// aiderSessionHeader opens each session in the chat log.
+const aiderSessionHeader = "# aider chat started at "
+
+// aiderSource reads Aider's chat logs.
+//
+// Aider keeps no log of its own outside the project, so only the projects the
+// scan already knows of are looked in. Each session in a log is addressed as
+// the log's path with its index appended, "#3" being the fourth session.
+//
+// The log is Markdown written for reading, not a record: a session has one
+// timestamp, when it started, and token counts are rounded to Aider's "2.5k".
+// Every event of a session is dated at its start, so a session has no duration.
+type aiderSource struct{}
+
+// NewAiderSource reads the chat log of each project the scan knows of.
+func NewAiderSource() TranscriptSource {
+	return &aiderSource{}
+}
+
+func (s *aiderSource) Agent() string { return CodingAgentAider }
+
+func (s *aiderSource) Owns(path string) bool {
+	return strings.Contains(path, string(filepath.Separator)+aiderHistoryFile+"#")
+}
+
+// Walk lists the sessions of each project's chat log. A project dir without a
+// log walks clean; a missing dir, or a log that cannot be read, walks nothing.
+func (s *aiderSource) Walk(projects []string, logger *slog.Logger) SourceWalk {
+	var w SourceWalk
+	for _, p := range projects {
+		if !filepath.IsAbs(p) {
+			continue
+		}
+		log := filepath.Join(p, aiderHistoryFile)
+		info, err := os.Stat(log)
+		switch {
+		case os.IsNotExist(err):
+			if dirExists(p) {
+				w.Roots = append(w.Roots, p)
+			}
+			continue
+		case err != nil:
+			logger.Warn("claude sessions: skipping unreadable aider log", "file", log, "error", err)
+			continue
+		}
+		starts, err := aiderSessionStarts(log)
+		if err != nil {
+			logger.Warn("claude sessions: skipping unreadable aider log", "file", log, "error", err)
+			continue
+		}
+		w.Roots = append(w.Roots, p)
+		w.Transcripts = append(w.Transcripts, aiderTranscripts(p, log, info.ModTime(), starts)...)
+	}
+	return w
+}
+
+// aiderTranscripts describes the sessions of one log that sent a prompt. A
+// session other than the last is finished, and is dated by when the next
+// began, so appending to the log rereads only the session being written.
+func aiderTranscripts(project, log string, modTime time.Time, starts []aiderStart) []SourceTranscript {
+	var out []SourceTranscript
+	for i, st := range starts {
+		if !st.prompted {
+			continue
+		}
+		mtime := modTime
+		if i+1 < len(starts) {
+			mtime = starts[i+1].at
+		}
+		path := fmt.Sprintf("%s#%d", log, i)
+		out = append(out, SourceTranscript{
+			SessionID:   aiderSessionID(path),
+			ProjectPath: project,
+			Path:        path,
+			ModTime:     mtime,
+			Root:        project,
+		})
+	}
+	return out
+}
+
+// aiderStart is one session of a log: when it started, and whether it sent a
+// prompt. Aider opens a session on every launch, including the ones quit
+// before anything was asked.
+type aiderStart struct {
+	at       time.Time
+	prompted bool
+}
+
+func aiderSessionStarts(log string) ([]aiderStart, error) {
+	f, err := os.Open(log) //nolint:gosec // path built from a known project dir
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+	var starts []aiderStart
+	sc := newAiderScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if at, ok := aiderSessionStart(line); ok {
+			starts = append(starts, aiderStart{at: at})
+		} else if len(starts) > 0 && strings.HasPrefix(line, "####") {
+			starts[len(starts)-1].prompted = true
+		}
+	}
+	return starts, sc.Err()
+}
+
+// aiderSessionID derives a session id from the session's address, which is
+// stable for as long as the log is only appended to.
+func aiderSessionID(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return "aider-" + hex.EncodeToString(sum[:])[:16]
+}
+
+// aiderSessionStart parses a session header. Aider writes it in local time.
+func aiderSessionStart(line string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(line, aiderSessionHeader)
+	if !ok {
+		return time.Time{}, false
+	}
+	at, err := time.ParseInLocation(time.DateTime, strings.TrimSpace(rest), time.Local)
+	return at, err == nil
+}
+
+func newAiderScanner(r io.Reader) *bufio.Scanner {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return sc
+}
+
+func (s *aiderSource) Open(path string) (io.ReadCloser, error) {
+	i := strings.LastIndex(path, "#")
+	n, err := strconv.Atoi(path[i+1:])
+	if i < 0 || err != nil {
+		return nil, fmt.Errorf("parse aider session address %q", path)
+	}
+	f, err := os.Open(path[:i]) //nolint:gosec // path found under a known project dir
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	ar := aiderReader{out: claudeTranscript{
+		sessionID: aiderSessionID(path),
+		cwd:       filepath.Dir(path[:i]),
+	}}
+	index := -1
+	sc := newAiderScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if at, ok := aiderSessionStart(line); ok {
+			index++
+			ar.at = at
+			continue
+		}
+		if index == n {
+			ar.line(line)
+		} else if index > n {
+			break
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	ar.flushPrompt()
+	ar.flushReply(nil)
+	return ar.out.reader(), nil
+}
+
+// aiderReader turns one session of a chat log into Claude Code events. Lines
+// starting "####" are the user's; lines quoted with ">" are Aider's own
+// notes, of which the model in use and each reply's token counts are kept;
+// everything else is the model's reply.
+type aiderReader struct {
+	out    claudeTranscript
+	at     time.Time
+	model  string
+	prompt []string
+	reply  []string
+}
+
+func (ar *aiderReader) line(line string) {
+	switch {
+	case strings.HasPrefix(line, "####"):
+		ar.flushReply(nil)
+		ar.prompt = append(ar.prompt, strings.TrimSpace(strings.TrimPrefix(line, "####")))
+	case strings.HasPrefix(line, ">"):
+		ar.flushPrompt()
+		ar.note(strings.TrimSpace(strings.TrimPrefix(line, ">")))
+	case len(ar.prompt) > 0 && strings.TrimSpace(line) == "":
+		// The blank line between a prompt and its reply.
+	default:
+		ar.flushPrompt()
+		ar.reply = append(ar.reply, line)
+	}
+}
+
+func (ar *aiderReader) note(note string) {
+	if model, ok := aiderModel(note); ok {
+		ar.model = model
+		return
+	}
+	if usage, ok := aiderUsage(note); ok {
+		ar.flushReply(usage)
+	}
+}
+
+func (ar *aiderReader) flushPrompt() {
+	if len(ar.prompt) == 0 {
+		return
+	}
+	if text := strings.TrimSpace(strings.Join(ar.prompt, "\n")); text != "" {
+		ar.out.prompt(ar.at, text)
+	}
+	ar.prompt = nil
+}
+
+// flushReply writes the reply read so far. A reply Aider reported tokens for
+// is written even when it printed nothing, so no tokens go uncounted.
+func (ar *aiderReader) flushReply(usage *rawUsage) {
+	text := strings.TrimSpace(strings.Join(ar.reply, "\n"))
+	ar.reply = nil
+	if text == "" && usage == nil {
+		return
+	}
+	var blocks []rawContentBlock
+	if text != "" {
+		blocks = append(blocks, rawContentBlock{Type: "text", Text: text})
+	}
+	ar.out.reply(ar.at, ar.model, blocks, usage)
+}
+
+// aiderModel parses the note naming the model a session runs, "Model: x with
+// diff edit format" or "Main model: x ...". A provider prefix such as
+// "anthropic/" is dropped, so the model prices through the catalog.
+func aiderModel(note string) (string, bool) {
+	rest, ok := strings.CutPrefix(note, "Main model:")
+	if !ok {
+		rest, ok = strings.CutPrefix(note, "Model:")
+	}
+	fields := strings.Fields(rest)
+	if !ok || len(fields) == 0 {
+		return "", false
+	}
+	model := fields[0]
+	return model[strings.LastIndex(model, "/")+1:], true
+}
+
+// aiderUsage parses a reply's token note: "Tokens: 2.5k sent, 1.2k cache
+// write, 800 cache hit, 120 received. Cost: ...". Aider's sent count includes
+// the cached tokens.
+func aiderUsage(note string) (*rawUsage, bool) {
+	rest, ok := strings.CutPrefix(note, "Tokens:")
+	if !ok {
+		return nil, false
+	}
+	if i := strings.Index(rest, " received"); i >= 0 {
+		rest = rest[:i+len(" received")]
+	}
+	var sent, write, hit, received int
+	for _, part := range strings.Split(rest, ",") {
+		count, label, ok := strings.Cut(strings.TrimSpace(part), " ")
+		if !ok {
+			continue
+		}
+		switch n := parseAiderCount(count); label {
+		case "sent":
+			sent = n
+		case "cache write":
+			write = n
+		case "cache hit":
+			hit = n
+		case "received":
+			received = n
+		}
+	}
+	return &rawUsage{
+		InputTokens:              max(sent-write-hit, 0),
+		CacheCreationInputTokens: write,
+		CacheReadInputTokens:     hit,
+		OutputTokens:             received,
+	}, true
+}
+
+// parseAiderCount parses a count as Aider prints it: 950, 2.5k or 1.2M.
+func parseAiderCount(s string) int {
+	scale := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"):
+		scale, s = 1e3, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "M"):
+		scale, s = 1e6, strings.TrimSuffix(s, "M")
+	}
+	v, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	return int(v*scale + 0.5)
+}
@@ -0,0 +1,114 @@
+package claudesessions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// aiderLog is a chat log of three launches: one that asked something, one quit
+// before a prompt, and one still running.
+const aiderLog = `
+# aider chat started at 2026-10-01 09:00:00
+
+> aider --model sonnet
+> Main model: anthropic/claude-sonnet-4-20250514 with diff edit format, infinite output
+> Git repo: .git with 12 files
+
+#### add a hello function
+#### in hello.go
+
+Here is the function.
+
+hello.go
+` + "```go" + `
+func Hello() string { return "hello" }
+` + "```" + `
+
+> Tokens: 2.5k sent, 1.0k cache write, 500 cache hit, 120 received. Cost: $0.01 message, $0.01 session.
+> Applied edit to hello.go
+
+# aider chat started at 2026-10-01 10:00:00
+
+> aider
+
+# aider chat started at 2026-10-02 08:00:00
+
+#### and a test
+`
+
+func writeAiderLog(t *testing.T) string {
+	t.Helper()
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, aiderHistoryFile), []byte(aiderLog), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return project
+}
+
+func TestAiderSource_WalksSessionsThatAsked(t *testing.T) {
+	project := writeAiderLog(t)
+	missing := filepath.Join(t.TempDir(), "gone")
+	bare := t.TempDir()
+
+	w := NewAiderSource().Walk([]string{project, missing, bare, "-home-dev-undecoded"}, testLogger)
+	if len(w.Transcripts) != 2 {
+		t.Fatalf("walk found %d sessions, want the two that sent a prompt: %+v", len(w.Transcripts), w.Transcripts)
+	}
+	// Addressed by position in the log, which the unprompted launch still
+	// holds.
+	log := filepath.Join(project, aiderHistoryFile)
+	if w.Transcripts[0].Path != log+"#0" || w.Transcripts[1].Path != log+"#2" {
+		t.Errorf("paths = %q, %q", w.Transcripts[0].Path, w.Transcripts[1].Path)
+	}
+	// A finished session is dated by the next launch, so appending to the log
+	// does not make it look changed.
+	if got := w.Transcripts[0].ModTime; !got.Equal(time.Date(2026, 10, 1, 10, 0, 0, 0, time.Local)) {
+		t.Errorf("finished session mtime = %v, want the next launch", got)
+	}
+	if w.Transcripts[0].ProjectPath != project || w.Transcripts[0].SessionID == w.Transcripts[1].SessionID {
+		t.Errorf("transcripts = %+v", w.Transcripts)
+	}
+	// A project without a log walks clean; a missing one is not walked.
+	if len(w.Roots) != 2 || w.Roots[0] != project || w.Roots[1] != bare {
+		t.Errorf("roots = %v, want the project with a log and the one without", w.Roots)
+	}
+}
+
+func TestAiderSource_NormalizesSession(t *testing.T) {
+	project := writeAiderLog(t)
+	path := filepath.Join(project, aiderHistoryFile) + "#0"
+
+	InstallTranscriptSources(NewAiderSource())
+	t.Cleanup(func() { InstallTranscriptSources() })
+
+	s, _, err := readSessionSummary(aiderSessionID(path), project, path, testLogger)
+	if err != nil {
+		t.Fatalf("read summary: %v", err)
+	}
+	if s.Preview != "add a hello function\nin hello.go" {
+		t.Errorf("preview = %q, want the two prompt lines", s.Preview)
+	}
+	if s.Model != "claude-sonnet-4-20250514" {
+		t.Errorf("model = %q, want the provider prefix dropped", s.Model)
+	}
+	if s.Usage.InputTokens != 1000 || s.Usage.CacheCreationTokens != 1000 ||
+		s.Usage.CacheReadTokens != 500 || s.Usage.OutputTokens != 120 {
+		t.Errorf("usage = %+v", s.Usage)
+	}
+	if s.MessageCount != 2 {
+		t.Errorf("message count = %d, want the prompt and the reply", s.MessageCount)
+	}
+	if s.Cost.TotalUSD <= 0 {
+		t.Errorf("cost = %+v, want the model priced through the catalog", s.Cost)
+	}
+}
+
+func TestParseAiderCount(t *testing.T) {
+	for in, want := range map[string]int{"950": 950, "2.5k": 2500, "1.2M": 1_200_000, "12,345": 12345, "?": 0} {
+		if got := parseAiderCount(in); got != want {
+			t.Errorf("parseAiderCount(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
@@ -114,6 +114,14 @@ type AnalyticsReport struct {
 	CostOverTime        []CostPoint            `json:"cost_over_time"`
 	CostSummary         CostSummary            `json:"cost_summary"`
 	Projects            []string               `json:"projects"`
+	// Forecast projects month-end spend for the month the window ends in.
+	// Unlike every other figure here it reads outside the window — the month
+	// from its first day, and a trailing baseline — so it is populated even
+	// for a window with no sessions.
+	Forecast SpendForecast `json:"forecast"`
+	// Anomalies are the window's days and sessions whose cost or token usage
+	// deviated sharply from their rolling baseline, strongest first.
+	Anomalies []Anomaly `json:"anomalies"`
 	// Granularity is the bucket width every series in this report was built at
 	// — "hourly", "daily", "weekly" or "monthly". It travels with the report
 	// because a bucket key alone no longer says how wide its bucket is: a
@@ -401,14 +409,19 @@ func AggregateAnalytics(sessions []ClaudeSessionSummary, p AnalyticsParams) Anal
 	filtered := FilterSessions(sessions, p)
 
 	granularity := p.Granularity()
+	forecast := buildForecast(sessions, p)
 	if len(filtered) == 0 {
-		return emptyReport(projects, loc, granularity)
+		report := emptyReport(projects, loc, granularity)
+		report.Forecast = forecast
+		report.InsightCards = buildTrendCards(forecast, nil)
+		return report
 	}
 
 	summary, costSummary := buildSummary(filtered)
 	projectBreakdown := buildProjectBreakdown(filtered)
 	costByModel := buildCostByModel(filtered)
 	timeSeries := buildTimeSeries(filtered, p.From, p.To, granularity, loc)
+	anomalies := DetectAnomalies(sessions, p)
 
 	return AnalyticsReport{
 		Summary:             summary,
@@ -416,7 +429,7 @@ func AggregateAnalytics(sessions []ClaudeSessionSummary, p AnalyticsParams) Anal
 		CacheEfficiency:     buildCacheEfficiency(timeSeries),
 		ModelBreakdown:      buildModelBreakdown(filtered),
 		CostByModel:         costByModel,
-		InsightCards:        buildInsightCards(filtered, costByModel),
+		InsightCards:        append(buildInsightCards(filtered, costByModel), buildTrendCards(forecast, anomalies)...),
 		CostOverTimeByModel: buildCostOverTimeByModel(filtered, p.From, p.To, granularity, loc),
 		ProjectBreakdown:    projectBreakdown,
 		ProjectActivity:     buildProjectActivity(filtered, projectBreakdown, granularity, loc),
@@ -428,6 +441,8 @@ func AggregateAnalytics(sessions []ClaudeSessionSummary, p AnalyticsParams) Anal
 		CostOverTime:        buildCostOverTime(filtered, p.From, p.To, granularity, loc),
 		CostSummary:         costSummary,
 		Projects:            projects,
+		Forecast:            forecast,
+		Anomalies:           anomalies,
 		Granularity:         granularity,
 	}
 }
@@ -458,6 +473,11 @@ func emptyReport(projects []string, loc *time.Location, granularity string) Anal
 			ByDuration: []SessionRanking{},
 			ByTokens:   []SessionRanking{},
 		},
+		Forecast: SpendForecast{
+			ByModel:   []ForecastLine{},
+			ByProject: []ForecastLine{},
+		},
+		Anomalies:   []Anomaly{},
 		Projects:    projects,
 		Granularity: granularity,
 	}
@@ -91,9 +91,10 @@ func (m *analyticsMemo) put(key string, report AnalyticsReport) {
 // stale report after a rate edit, too fine makes the memo never hit. The four
 // invalidation inputs are the same ones the scan already tracks — a rate edit
 // moves pricingRev and forces a re-read, a threshold change moves
-// idleThresholdMs and does the same, and any re-read moves lastScanned — plus
-// the hidden-project set and the indexed config-dir set, which are process
-// state rather than cached state and therefore have to be fingerprinted here.
+// idleThresholdMs and does the same, and any re-read moves lastScanned — or,
+// for one driven by a hook, ingests — plus the hidden-project set and the
+// indexed config-dir set, which are process state rather than cached state and
+// therefore have to be fingerprinted here.
 // Removing a config dir deliberately triggers no rescan — its rows are filtered
 // out, not deleted — so lastScanned does not move and this is the only thing
 // standing between the user and a report that still counts the account they
@@ -103,6 +104,7 @@ type analyticsCacheKey struct {
 	project         string
 	tz              string
 	lastScanned     time.Time
+	ingests         int64
 	pricingRev      int64
 	idleThresholdMs int64
 	hidden          string
@@ -110,9 +112,9 @@ type analyticsCacheKey struct {
 }
 
 func (k analyticsCacheKey) String() string {
-	return fmt.Sprintf("%d|%d|%s|%s|%d|%d|%d|%s|%s",
+	return fmt.Sprintf("%d|%d|%s|%s|%d|%d|%d|%d|%s|%s",
 		k.from.UnixNano(), k.to.UnixNano(), k.project, k.tz,
-		k.lastScanned.UnixNano(), k.pricingRev, k.idleThresholdMs, k.hidden,
+		k.lastScanned.UnixNano(), k.ingests, k.pricingRev, k.idleThresholdMs, k.hidden,
 		k.configDirs)
 }
 
@@ -136,6 +138,7 @@ func (c *Cache) Analytics(p AnalyticsParams) AnalyticsReport {
 		project:         p.Project,
 		tz:              p.location().String(),
 		lastScanned:     c.LastScannedAt(),
+		ingests:         c.ingests.Load(),
 		pricingRev:      currentPricingRevision(),
 		idleThresholdMs: IdleGapThreshold().Milliseconds(),
 		hidden:          strings.Join(HiddenProjects(), "\x00"),
@@ -0,0 +1,264 @@
+package claudesessions
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Anomaly detection: the day or the session that cost far more than usual.
+//
+// "Usual" is a rolling baseline — the active days, or the sessions, just
+// before the one being judged — summarized by its median and spread by its
+// median absolute deviation. Both are robust: one runaway session in the
+// baseline does not raise the bar enough to hide the next one, which a mean
+// and standard deviation would. Idle days are left out of the day baseline,
+// because a weekend of zeros would make every working day look unusual.
+//
+// A value is flagged only when it is all three of far out (a modified z-score
+// of anomalyScore), a real multiple of the baseline (anomalyMinRatio) and
+// material (a cost or token floor). Any one alone flags noise: a flat baseline
+// makes tiny deviations score high, and a cheap baseline makes a cheap day a
+// multiple of it.
+
+// AnomalyScope says whether an anomaly is a whole day or one session.
+type AnomalyScope string
+
+// The scopes an anomaly can have.
+const (
+	AnomalyScopeDay     AnomalyScope = "day"
+	AnomalyScopeSession AnomalyScope = "session"
+)
+
+// AnomalyMetric says which figure deviated.
+type AnomalyMetric string
+
+const (
+	// AnomalyMetricCost is spend in USD.
+	AnomalyMetricCost AnomalyMetric = "cost"
+	// AnomalyMetricTokens is conversation tokens, input plus output. Cache
+	// traffic is left out for the same reason the token headline leaves it
+	// out: it dwarfs everything else, and its cost already shows in the cost
+	// metric.
+	AnomalyMetricTokens AnomalyMetric = "tokens"
+)
+
+// Anomaly is one day or session whose cost or token usage deviated sharply
+// from its rolling baseline. When both metrics deviate, the one that deviated
+// further is reported.
+type Anomaly struct {
+	Scope  AnomalyScope  `json:"scope"`
+	Metric AnomalyMetric `json:"metric"`
+	// Date is the local day, YYYY-MM-DD; for a session, the day of its last
+	// activity.
+	Date      string `json:"date"`
+	SessionID string `json:"session_id,omitempty"`
+	Project   string `json:"project,omitempty"`
+	// At is when the day began or the session was last active.
+	At time.Time `json:"at"`
+	// Value is the day's or the session's figure, Baseline the median it was
+	// judged against, both in Metric's unit.
+	Value    float64 `json:"value"`
+	Baseline float64 `json:"baseline"`
+	// Score is how far above the baseline Value sits, in robust standard
+	// deviations.
+	Score float64 `json:"score"`
+}
+
+// anomalyDayBaseline is how many days before a day make up its baseline.
+const anomalyDayBaseline = 14
+
+// anomalySessionBaseline is how many sessions before a session make up its
+// baseline.
+const anomalySessionBaseline = 30
+
+// anomalyMinBaseline is the fewest active days or sessions a baseline needs
+// before anything is judged against it. A new user's second day is not an
+// anomaly because their first was short.
+const anomalyMinBaseline = 5
+
+// anomalyScore is the modified z-score above which a value is an outlier —
+// the conventional 3.5 for a median/MAD test.
+const anomalyScore = 3.5
+
+// anomalyMinRatio is how many times the baseline a value must also be.
+const anomalyMinRatio = 2.0
+
+// anomalyMinTokens is the token floor, the counterpart of minCardCostUSD.
+const anomalyMinTokens = 50_000
+
+// maxAnomalies caps the report's list. The strongest are kept; a window with
+// more than this many is one where the baseline itself has moved, and the
+// forecast says that better than a long list would.
+const maxAnomalies = 20
+
+// DetectAnomalies returns the days and sessions in p's window whose cost or
+// token usage deviated sharply from their rolling baseline, strongest first.
+//
+// Baselines reach back before the window, so the first days of a window are
+// judged as fairly as the last. It is exported for alerting, which runs it
+// over the latest day outside any report.
+func DetectAnomalies(sessions []ClaudeSessionSummary, p AnalyticsParams) []Anomaly {
+	out := append(dayAnomalies(sessions, p), sessionAnomalies(sessions, p)...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	if len(out) > maxAnomalies {
+		out = out[:maxAnomalies]
+	}
+	return out
+}
+
+// dayAnomalies judges each local day in the window against the active days
+// before it.
+func dayAnomalies(sessions []ClaudeSessionSummary, p AnalyticsParams) []Anomaly {
+	loc := p.location()
+	from := bucketStart(p.From, GranularityDaily, loc).AddDate(0, 0, -anomalyDayBaseline)
+	scoped := FilterSessions(sessions, AnalyticsParams{From: from, To: p.To, Project: p.Project})
+	if len(scoped) == 0 {
+		return []Anomaly{}
+	}
+
+	costs := buildCostOverTime(scoped, from, p.To, GranularityDaily, loc)
+	tokens := buildTimeSeries(scoped, from, p.To, GranularityDaily, loc)
+	windowKey := bucketKey(p.From, GranularityDaily, loc)
+
+	out := []Anomaly{}
+	for i, pt := range costs {
+		if pt.Date < windowKey {
+			continue
+		}
+		lo := max(i-anomalyDayBaseline, 0)
+		costBase := make([]float64, 0, anomalyDayBaseline)
+		tokenBase := make([]float64, 0, anomalyDayBaseline)
+		for j := lo; j < i; j++ {
+			if costs[j].EstimatedCostUSD > 0 {
+				costBase = append(costBase, costs[j].EstimatedCostUSD)
+			}
+			if tokens[j].TotalTokens > 0 {
+				tokenBase = append(tokenBase, float64(tokens[j].TotalTokens))
+			}
+		}
+
+		a, ok := strongest(pt.EstimatedCostUSD, costBase, float64(tokens[i].TotalTokens), tokenBase)
+		if !ok {
+			continue
+		}
+		a.Scope = AnomalyScopeDay
+		a.Date = pt.Date
+		if day, err := time.ParseInLocation("2006-01-02", pt.Date, loc); err == nil {
+			a.At = day
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// sessionAnomalies judges each session in the window against the sessions
+// that finished before it.
+func sessionAnomalies(sessions []ClaudeSessionSummary, p AnalyticsParams) []Anomaly {
+	ordered := make([]ClaudeSessionSummary, 0, len(sessions))
+	for _, s := range sessions {
+		if s.LastActivity.After(p.To) || (p.Project != "" && s.ProjectPath != p.Project) {
+			continue
+		}
+		ordered = append(ordered, s)
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].LastActivity.Before(ordered[j].LastActivity)
+	})
+
+	loc := p.location()
+	out := []Anomaly{}
+	var costs, tokens []float64
+	for _, s := range ordered {
+		c := s.TotalCost().TotalUSD
+		u := s.TotalUsage()
+		t := float64(u.InputTokens + u.OutputTokens)
+
+		if !s.LastActivity.Before(p.From) {
+			a, ok := strongest(c, tail(costs, anomalySessionBaseline), t, tail(tokens, anomalySessionBaseline))
+			if ok {
+				a.Scope = AnomalyScopeSession
+				a.SessionID = s.SessionID
+				a.Project = s.ProjectPath
+				a.Date = bucketKey(s.LastActivity, GranularityDaily, loc)
+				a.At = s.LastActivity
+				out = append(out, a)
+			}
+		}
+
+		if c > 0 {
+			costs = append(costs, c)
+		}
+		if t > 0 {
+			tokens = append(tokens, t)
+		}
+	}
+	return out
+}
+
+// strongest judges a cost and a token figure against their baselines and
+// returns whichever deviated further, with Metric, Value, Baseline and Score
+// set.
+func strongest(costUSD float64, costBase []float64, tokens float64, tokenBase []float64) (Anomaly, bool) {
+	var best Anomaly
+	found := false
+	if median, score, ok := outlier(costUSD, costBase, minCardCostUSD); ok {
+		best = Anomaly{Metric: AnomalyMetricCost, Value: costUSD, Baseline: median, Score: score}
+		found = true
+	}
+	if median, score, ok := outlier(tokens, tokenBase, anomalyMinTokens); ok && (!found || score > best.Score) {
+		best = Anomaly{Metric: AnomalyMetricTokens, Value: tokens, Baseline: median, Score: score}
+		found = true
+	}
+	return best, found
+}
+
+// outlier reports whether value is an outlier against baseline, returning
+// the baseline's median and value's modified z-score.
+func outlier(value float64, baseline []float64, floor float64) (median, score float64, ok bool) {
+	if len(baseline) < anomalyMinBaseline || value < floor {
+		return 0, 0, false
+	}
+	median = medianOf(baseline)
+	if value < median*anomalyMinRatio {
+		return 0, 0, false
+	}
+
+	devs := make([]float64, len(baseline))
+	for i, b := range baseline {
+		devs[i] = math.Abs(b - median)
+	}
+	// 1.4826 scales the MAD to a standard deviation for normal data. The floor
+	// keeps a perfectly even baseline from giving every deviation an infinite
+	// score; the ratio test above is what judges those.
+	spread := math.Max(medianOf(devs)*1.4826, median*0.1)
+	if spread == 0 {
+		return 0, 0, false
+	}
+	score = (value - median) / spread
+	if score < anomalyScore {
+		return 0, 0, false
+	}
+	return median, math.Round(score*10) / 10, true
+}
+
+// medianOf returns the median of values, which must not be empty. It sorts a
+// copy.
+func medianOf(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// tail returns the last n values.
+func tail(values []float64, n int) []float64 {
+	if len(values) <= n {
+		return values
+	}
+	return values[len(values)-n:]
+}
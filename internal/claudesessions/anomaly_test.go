@@ -0,0 +1,140 @@
+package claudesessions
+
+import (
+	"testing"
+	"time"
+)
+
+// spikeCorpus is twenty quiet $2 days ending Aug 14 and one $20 session on
+// Aug 15.
+func spikeCorpus() []ClaudeSessionSummary {
+	sessions := dailySessions(
+		time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC), time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC),
+		"opus", "/a", 2)
+	spike := costSession("spike", "opus", time.Date(2026, 8, 15, 9, 0, 0, 0, time.UTC),
+		map[string]SessionCost{"opus": cost(20, 0, 0, 0)}, nil)
+	spike.ProjectPath = "/a"
+	return append(sessions, spike)
+}
+
+func TestDetectAnomalies_CostSpike(t *testing.T) {
+	p := AnalyticsParams{
+		From: time.Date(2026, 8, 10, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 8, 15, 23, 59, 59, 0, time.UTC),
+	}
+	anomalies := DetectAnomalies(spikeCorpus(), p)
+	if len(anomalies) != 2 {
+		t.Fatalf("anomalies = %+v, want the spike as a day and as a session", anomalies)
+	}
+
+	for _, a := range anomalies {
+		if a.Metric != AnomalyMetricCost || a.Value != 20 || a.Baseline != 2 {
+			t.Errorf("anomaly = %+v, want $20 against a $2 baseline", a)
+		}
+		if a.Date != "2026-08-15" {
+			t.Errorf("date = %q, want 2026-08-15", a.Date)
+		}
+		if a.Score < anomalyScore {
+			t.Errorf("score = %v, want at least %v", a.Score, anomalyScore)
+		}
+	}
+
+	day, ok := cardOf(buildTrendCards(SpendForecast{}, anomalies), CardDayAnomaly)
+	if !ok || day.Ratio != 10 || day.AmountUSD != 20 || day.ComparisonUSD != 2 {
+		t.Errorf("day card = %+v (emitted %v), want $20 at 10× a $2 baseline", day, ok)
+	}
+	session, ok := cardOf(buildTrendCards(SpendForecast{}, anomalies), CardSessionAnomaly)
+	if !ok || session.SessionID != "spike" {
+		t.Errorf("session card = %+v (emitted %v), want it to name the spike", session, ok)
+	}
+}
+
+// TestDetectAnomalies_TokenSpike reports a day whose cost is ordinary but whose
+// token usage is not, under the tokens metric.
+func TestDetectAnomalies_TokenSpike(t *testing.T) {
+	sessions := dailySessions(
+		time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC), time.Date(2026, 8, 16, 0, 0, 0, 0, time.UTC),
+		"opus", "/a", 2)
+	for i := range sessions {
+		sessions[i].Usage = TokenUsage{InputTokens: 10_000, OutputTokens: 10_000}
+	}
+	sessions[len(sessions)-1].Usage = TokenUsage{InputTokens: 200_000, OutputTokens: 200_000}
+
+	anomalies := DetectAnomalies(sessions, AnalyticsParams{
+		From: time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 8, 15, 23, 59, 59, 0, time.UTC),
+	})
+	if len(anomalies) == 0 {
+		t.Fatal("expected the token spike to be flagged")
+	}
+	if a := anomalies[0]; a.Metric != AnomalyMetricTokens || a.Value != 400_000 || a.Baseline != 20_000 {
+		t.Errorf("anomaly = %+v, want 400k tokens against a 20k baseline", a)
+	}
+}
+
+// TestDetectAnomalies_Quiet covers the three guards: a value that is not a
+// real multiple of its baseline, one that is not material, and a baseline too
+// short to judge against.
+func TestDetectAnomalies_Quiet(t *testing.T) {
+	window := AnalyticsParams{
+		From: time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 8, 15, 23, 59, 59, 0, time.UTC),
+	}
+	quietDays := func(usd float64) []ClaudeSessionSummary {
+		return dailySessions(
+			time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC), time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC),
+			"opus", "/a", usd)
+	}
+	at := time.Date(2026, 8, 15, 9, 0, 0, 0, time.UTC)
+	session := func(usd float64) ClaudeSessionSummary {
+		return costSession("today", "opus", at, map[string]SessionCost{"opus": cost(usd, 0, 0, 0)}, nil)
+	}
+
+	tests := []struct {
+		name     string
+		sessions []ClaudeSessionSummary
+	}{
+		{name: "under twice the baseline", sessions: append(quietDays(2), session(3.5))},
+		{name: "below the cost floor", sessions: append(quietDays(0.05), session(0.9))},
+		{
+			name: "baseline too short",
+			sessions: append(dailySessions(
+				time.Date(2026, 8, 12, 0, 0, 0, 0, time.UTC), time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC),
+				"opus", "/a", 2), session(20)),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DetectAnomalies(tc.sessions, window); len(got) != 0 {
+				t.Errorf("anomalies = %+v, want none", got)
+			}
+		})
+	}
+}
+
+func TestAggregateAnalytics_ForecastAndAnomalies(t *testing.T) {
+	report := AggregateAnalytics(spikeCorpus(), AnalyticsParams{
+		From: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 8, 15, 23, 59, 59, 0, time.UTC),
+	})
+	if report.Forecast.Month != "2026-08" || report.Forecast.ProjectedUSD <= report.Forecast.MonthToDateUSD {
+		t.Errorf("forecast = %+v, want August projected past its month to date", report.Forecast)
+	}
+	if len(report.Anomalies) != 2 {
+		t.Errorf("anomalies = %+v, want the spike as a day and a session", report.Anomalies)
+	}
+	for _, kind := range []InsightCardKind{CardSpendForecast, CardDayAnomaly, CardSessionAnomaly} {
+		if _, ok := cardOf(report.InsightCards, kind); !ok {
+			t.Errorf("expected a %s card in %+v", kind, report.InsightCards)
+		}
+	}
+
+	empty := AggregateAnalytics(spikeCorpus(), AnalyticsParams{
+		From: time.Date(2026, 8, 20, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 8, 21, 0, 0, 0, 0, time.UTC),
+	})
+	if empty.Forecast.MonthToDateUSD != 48 || empty.Anomalies == nil {
+		t.Errorf("empty window: forecast = %+v, anomalies = %v; want August's $48 and []",
+			empty.Forecast, empty.Anomalies)
+	}
+}
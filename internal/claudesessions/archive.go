@@ -0,0 +1,666 @@
+package claudesessions
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TranscriptArchive keeps Agento's own compressed copy of every Claude Code
+// transcript, sub-agent transcripts included.
+//
+// Claude Code deletes old session files on its own schedule, and before the
+// archive a deleted file took its session out of every list, total and
+// journey on the next scan. With an archive installed, the scanner copies each
+// transcript as it changes, and every reader — the scan, the detail page, the
+// journey builder and the insight processors — opens the copy when the
+// original is gone.
+//
+// Copies are content-addressed: each one is stored once, gzipped, as
+// objects/<sha[:2]>/<sha>.jsonl.gz under dir, and claude_transcript_archive
+// maps the original path to it. Two paths with identical content share one
+// object, and an object no row refers to any more is deleted.
+type TranscriptArchive struct {
+	db     *sql.DB
+	dir    string
+	logger *slog.Logger
+}
+
+// NewTranscriptArchive returns an archive that stores its copies under dir.
+// It does nothing until installed with InstallTranscriptArchive.
+func NewTranscriptArchive(db *sql.DB, dir string, logger *slog.Logger) *TranscriptArchive {
+	return &TranscriptArchive{db: db, dir: dir, logger: logger}
+}
+
+// archivePolicy is the user's Settings > Data & Analytics choice of what the
+// archive copies and how long it keeps it.
+type archivePolicy struct {
+	disabled      bool
+	retentionDays int
+	maxBytes      int64
+}
+
+// archiveState holds the installed archive and its policy. Process-wide for
+// the same reason dataSettings is: the readers that fall back to the archive
+// are the same package-level functions that read dataSettings, none of which
+// are handed a database.
+var archiveState = struct {
+	sync.RWMutex
+	archive *TranscriptArchive
+	policy  archivePolicy
+}{}
+
+// InstallTranscriptArchive sets the archive every scan copies into and every
+// reader falls back to. Passing nil uninstalls it.
+func InstallTranscriptArchive(a *TranscriptArchive) {
+	archiveState.Lock()
+	defer archiveState.Unlock()
+	archiveState.archive = a
+}
+
+// ApplyArchiveSettings installs the user's transcript archive preferences.
+//
+// disabled stops new copies being made; copies already made are still read
+// and still pruned. retentionDays and maxMB bound how much is kept of the
+// sessions whose originals Claude Code has deleted, and zero means no bound.
+// The copy of a transcript still on disk is never pruned: it is the one the
+// archive exists to protect.
+func ApplyArchiveSettings(disabled bool, retentionDays, maxMB int) {
+	archiveState.Lock()
+	defer archiveState.Unlock()
+	archiveState.policy = archivePolicy{
+		disabled:      disabled,
+		retentionDays: max(retentionDays, 0),
+		maxBytes:      int64(max(maxMB, 0)) << 20,
+	}
+}
+
+// installedArchive returns the installed archive and its policy. The archive
+// is nil when none is installed.
+func installedArchive() (*TranscriptArchive, archivePolicy) {
+	archiveState.RLock()
+	defer archiveState.RUnlock()
+	return archiveState.archive, archiveState.policy
+}
+
+// ── Reading ─────────────────────────────────────────────────────────────────
+
+// openTranscript opens a transcript for reading, falling back to its archived
+// copy when the original no longer exists. Another agent's log is read through
+// its TranscriptSource, as Claude Code JSONL. Any other error — a permission
+// problem, say — is returned as is: the original is still there, and a stale
+// copy would hide that.
+func openTranscript(filePath string) (io.ReadCloser, error) {
+	if src := sourceFor(filePath); src != nil {
+		return src.Open(filePath)
+	}
+	f, err := os.Open(filePath) //nolint:gosec // path derived from a scanned transcript
+	if err == nil {
+		return f, nil
+	}
+	a, _ := installedArchive()
+	if a == nil || !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	rc, aerr := a.open(filePath)
+	if aerr != nil {
+		return nil, err
+	}
+	return rc, nil
+}
+
+// gzipFile closes both the decompressor and the object file beneath it.
+type gzipFile struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (g gzipFile) Close() error {
+	zerr := g.Reader.Close()
+	if err := g.f.Close(); err != nil {
+		return err
+	}
+	return zerr
+}
+
+// open returns the decompressed archived copy of filePath.
+func (a *TranscriptArchive) open(filePath string) (io.ReadCloser, error) {
+	var sum string
+	err := a.db.QueryRowContext(context.Background(),
+		`SELECT sha256 FROM claude_transcript_archive WHERE file_path = ?`, filePath).Scan(&sum)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(a.objectPath(sum))
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close() //nolint:errcheck,gosec
+		return nil, fmt.Errorf("reading archived transcript %s: %w", filePath, err)
+	}
+	return gzipFile{Reader: zr, f: f}, nil
+}
+
+// archivedMeta returns the sub-agent sidecar archived with filePath, or nil.
+func archivedMeta(filePath string) []byte {
+	a, _ := installedArchive()
+	if a == nil {
+		return nil
+	}
+	var meta string
+	if err := a.db.QueryRowContext(context.Background(),
+		`SELECT meta FROM claude_transcript_archive WHERE file_path = ?`, filePath).Scan(&meta); err != nil {
+		return nil
+	}
+	if meta == "" {
+		return nil
+	}
+	return []byte(meta)
+}
+
+// archivedSessionFile finds a session's own transcript in the archive, for
+// when findSessionFile found no original. Empty strings when it has none.
+func archivedSessionFile(sessionID string) (configDir, projectPath, filePath string) {
+	a, _ := installedArchive()
+	if a == nil {
+		return "", "", ""
+	}
+	err := a.db.QueryRowContext(context.Background(), `
+		SELECT config_dir, project_path, file_path FROM claude_transcript_archive
+		WHERE session_id = ? AND agent_id = ''
+		ORDER BY file_mtime DESC LIMIT 1`, sessionID).Scan(&configDir, &projectPath, &filePath)
+	if err != nil {
+		return "", "", ""
+	}
+	return configDir, projectPath, filePath
+}
+
+// archivedSubagentFiles returns the archived sub-agent transcripts under
+// subagentsDir, whether or not their originals still exist.
+func archivedSubagentFiles(sessionID, subagentsDir string) []string {
+	a, _ := installedArchive()
+	if a == nil {
+		return nil
+	}
+	rows, err := a.db.QueryContext(context.Background(), `
+		SELECT file_path FROM claude_transcript_archive
+		WHERE session_id = ? AND agent_id <> ''`, sessionID)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var paths []string
+	for rows.Next() {
+		var fp string
+		if rows.Scan(&fp) == nil && filepath.Dir(fp) == subagentsDir {
+			paths = append(paths, fp)
+		}
+	}
+	return paths
+}
+
+// ── Scan integration ────────────────────────────────────────────────────────
+
+// archiveRow is one claude_transcript_archive row.
+type archiveRow struct {
+	filePath    string
+	sessionID   string
+	projectPath string
+	configDir   string
+	agentID     string
+	sha256      string
+	size        int64
+	storedSize  int64
+	mtime       time.Time
+	missing     bool
+}
+
+func (a *TranscriptArchive) loadRows(ctx context.Context) ([]archiveRow, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT file_path, session_id, project_path, config_dir, agent_id,
+		       sha256, size, stored_size, file_mtime, original_missing_since IS NOT NULL
+		FROM claude_transcript_archive`)
+	if err != nil {
+		return nil, fmt.Errorf("listing archived transcripts: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []archiveRow
+	for rows.Next() {
+		var r archiveRow
+		if err := rows.Scan(&r.filePath, &r.sessionID, &r.projectPath, &r.configDir, &r.agentID,
+			&r.sha256, &r.size, &r.storedSize, &r.mtime, &r.missing); err != nil {
+			return nil, fmt.Errorf("listing archived transcripts: %w", err)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// restoreMissing adds every archived transcript whose original is gone to the
+// walk, as though it were still on disk, so the diff keeps its cache row
+// instead of deleting it. The files are read from the archive from then on.
+//
+// A transcript whose row now belongs to another file on disk — a session that
+// moved between config dirs — is left out: the file on disk is the session.
+// Transcripts gone from a dir that was walked in full are stamped as missing,
+// which is what makes them eligible for pruning.
+func (a *TranscriptArchive) restoreMissing(ctx context.Context, walk diskWalk) {
+	rows, err := a.loadRows(ctx)
+	if err != nil {
+		a.logger.Warn("claude sessions: transcript archive unavailable", "error", err)
+		return
+	}
+	present := make(map[string]struct{}, len(walk.files))
+	for _, df := range walk.files {
+		present[df.key()] = struct{}{}
+	}
+
+	var gone []string
+	for _, r := range rows {
+		if _, onDisk := walk.files[r.filePath]; onDisk {
+			continue
+		}
+		df := r.diskFile()
+		if _, owned := present[df.key()]; owned {
+			continue
+		}
+		walk.files[r.filePath] = df
+		ce := cachedEntry{filePath: r.filePath, configDir: r.configDir}
+		if !r.missing && rowReconcilable(ce, walk) {
+			gone = append(gone, r.filePath)
+		}
+	}
+	a.markMissing(ctx, gone)
+}
+
+// diskFile rebuilds the walk entry the archived transcript was copied from.
+func (r archiveRow) diskFile() diskFile {
+	df := diskFile{
+		sessionID:   r.sessionID,
+		projectPath: r.projectPath,
+		filePath:    r.filePath,
+		mtime:       r.mtime,
+		configDir:   r.configDir,
+		archived:    true,
+	}
+	if r.agentID != "" {
+		// <project>/<session>/subagents/<agent>.jsonl → <project>/<session>.jsonl
+		projectDir := filepath.Dir(filepath.Dir(filepath.Dir(r.filePath)))
+		df.isSubagent = true
+		df.agentID = r.agentID
+		df.parentFilePath = filepath.Join(projectDir, r.sessionID+jsonlExt)
+	}
+	return df
+}
+
+func (a *TranscriptArchive) markMissing(ctx context.Context, paths []string) {
+	now := time.Now().UTC()
+	for _, fp := range paths {
+		if _, err := a.db.ExecContext(ctx, `
+			UPDATE claude_transcript_archive SET original_missing_since = ?
+			WHERE file_path = ? AND original_missing_since IS NULL`, now, fp); err != nil {
+			a.logger.Warn("claude sessions: failed to mark archived transcript missing",
+				"file", fp, "error", err)
+		}
+	}
+	if len(paths) > 0 {
+		a.logger.Info("claude sessions: transcripts deleted by Claude Code kept from the archive",
+			"count", len(paths))
+	}
+}
+
+// sync brings the archive up to date with what the walk found on disk: a
+// transcript with no copy, or a copy older than the file, is copied again.
+// Copying is skipped entirely when the archive is disabled. Pruning runs
+// either way.
+func (a *TranscriptArchive) sync(ctx context.Context, walk diskWalk, policy archivePolicy) {
+	if !policy.disabled {
+		a.copyChanged(ctx, walk)
+	}
+	if err := a.prune(ctx, policy, time.Now()); err != nil {
+		a.logger.Warn("claude sessions: failed to prune the transcript archive", "error", err)
+	}
+}
+
+func (a *TranscriptArchive) copyChanged(ctx context.Context, walk diskWalk) {
+	rows, err := a.loadRows(ctx)
+	if err != nil {
+		a.logger.Warn("claude sessions: transcript archive unavailable", "error", err)
+		return
+	}
+	archived := make(map[string]archiveRow, len(rows))
+	for _, r := range rows {
+		archived[r.filePath] = r
+	}
+
+	copied := 0
+	for fp, df := range walk.files {
+		// Another agent's log is not Claude Code's to clean up, and may not
+		// even be a file of its own.
+		if df.archived || df.codingAgent != "" {
+			continue
+		}
+		if r, ok := archived[fp]; ok && r.mtime.Equal(df.mtime) && !r.missing {
+			continue
+		}
+		if err := a.store(ctx, df); err != nil {
+			a.logger.Warn("claude sessions: failed to archive transcript", "file", fp, "error", err)
+			continue
+		}
+		copied++
+	}
+	if copied > 0 {
+		a.logger.Info("claude sessions: archived transcripts", "count", copied)
+	}
+}
+
+// store copies one transcript into the archive and records it, replacing the
+// row's previous copy.
+func (a *TranscriptArchive) store(ctx context.Context, df diskFile) error {
+	sum, size, stored, err := a.writeObject(df.filePath)
+	if err != nil {
+		return err
+	}
+	var meta []byte
+	if df.isSubagent {
+		// A missing sidecar is normal for older transcripts; see readSubagentMeta.
+		meta, err = os.ReadFile(strings.TrimSuffix(df.filePath, jsonlExt) + ".meta.json") //nolint:gosec
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("reading sub-agent meta: %w", err)
+		}
+	}
+
+	var previous string
+	err = a.db.QueryRowContext(ctx,
+		`SELECT sha256 FROM claude_transcript_archive WHERE file_path = ?`, df.filePath).Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("looking up archived transcript: %w", err)
+	}
+	if _, err := a.db.ExecContext(ctx, `
+		INSERT INTO claude_transcript_archive (
+			file_path, session_id, project_path, config_dir, agent_id,
+			sha256, size, stored_size, file_mtime, meta, archived_at, original_missing_since
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULL)
+		ON CONFLICT(file_path) DO UPDATE SET
+			session_id = excluded.session_id,
+			project_path = excluded.project_path,
+			config_dir = excluded.config_dir,
+			agent_id = excluded.agent_id,
+			sha256 = excluded.sha256,
+			size = excluded.size,
+			stored_size = excluded.stored_size,
+			file_mtime = excluded.file_mtime,
+			meta = excluded.meta,
+			archived_at = excluded.archived_at,
+			original_missing_since = NULL`,
+		df.filePath, df.sessionID, df.projectPath, df.configDir, df.agentID,
+		sum, size, stored, df.mtime, string(meta), time.Now().UTC(),
+	); err != nil {
+		return fmt.Errorf("recording archived transcript: %w", err)
+	}
+	if previous != "" && previous != sum {
+		a.removeUnreferenced(ctx, []string{previous})
+	}
+	return nil
+}
+
+// writeObject compresses src into the object store, reading it once for both
+// the hash and the copy. Content already stored is not written twice.
+func (a *TranscriptArchive) writeObject(src string) (sum string, size, stored int64, err error) {
+	in, err := os.Open(src) //nolint:gosec // path derived from a scanned transcript
+	if err != nil {
+		return "", 0, 0, err
+	}
+	defer in.Close() //nolint:errcheck
+
+	objects := filepath.Join(a.dir, "objects")
+	if err := os.MkdirAll(objects, 0o700); err != nil {
+		return "", 0, 0, fmt.Errorf("creating transcript archive: %w", err)
+	}
+	tmp, err := os.CreateTemp(objects, "incoming-*")
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("creating archive object: %w", err)
+	}
+	// After the rename this finds nothing to remove.
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	h := sha256.New()
+	zw := gzip.NewWriter(tmp)
+	size, err = io.Copy(zw, io.TeeReader(in, h))
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("compressing transcript: %w", err)
+	}
+
+	sum = hex.EncodeToString(h.Sum(nil))
+	dest := a.objectPath(sum)
+	if info, err := os.Stat(dest); err == nil {
+		return sum, size, info.Size(), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return "", 0, 0, fmt.Errorf("creating transcript archive: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return "", 0, 0, fmt.Errorf("storing archive object: %w", err)
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return sum, size, info.Size(), nil
+}
+
+func (a *TranscriptArchive) objectPath(sum string) string {
+	return filepath.Join(a.dir, "objects", sum[:2], sum+".jsonl.gz")
+}
+
+// removeUnreferenced deletes the objects no row refers to any more.
+func (a *TranscriptArchive) removeUnreferenced(ctx context.Context, sums []string) {
+	for _, sum := range sums {
+		var refs int
+		if err := a.db.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM claude_transcript_archive WHERE sha256 = ?`, sum).Scan(&refs); err != nil || refs > 0 {
+			continue
+		}
+		if err := os.Remove(a.objectPath(sum)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			a.logger.Warn("claude sessions: failed to remove archive object", "sha256", sum, "error", err)
+		}
+	}
+}
+
+// ── Retention ───────────────────────────────────────────────────────────────
+
+// archivedSession groups the rows of one session for pruning.
+type archivedSession struct {
+	id           string
+	last         time.Time
+	originalGone bool
+	sums         []string
+}
+
+// prune drops archived sessions past the retention period, then the oldest
+// ones until the archive fits its size cap. Only sessions whose every
+// transcript is gone from disk are candidates; once dropped, the next scan
+// removes them from Agento as it would have without an archive.
+func (a *TranscriptArchive) prune(ctx context.Context, policy archivePolicy, now time.Time) error {
+	if policy.retentionDays == 0 && policy.maxBytes == 0 {
+		return nil
+	}
+	rows, err := a.loadRows(ctx)
+	if err != nil {
+		return err
+	}
+	sessions, objects := groupArchivedSessions(rows)
+
+	total := objects.total()
+	cutoff := now.AddDate(0, 0, -policy.retentionDays)
+	var drop []archivedSession
+	var freed []string
+	for _, s := range sessions {
+		if !s.originalGone {
+			continue
+		}
+		expired := policy.retentionDays > 0 && s.last.Before(cutoff)
+		if !expired && (policy.maxBytes == 0 || total <= policy.maxBytes) {
+			continue
+		}
+		drop = append(drop, s)
+		sums, size := objects.release(s.sums)
+		freed = append(freed, sums...)
+		total -= size
+	}
+	if len(drop) == 0 {
+		return nil
+	}
+	if err := a.deleteSessions(ctx, drop); err != nil {
+		return err
+	}
+	a.removeUnreferenced(ctx, freed)
+	a.logger.Info("claude sessions: pruned the transcript archive", "sessions", len(drop))
+	return nil
+}
+
+// archiveObjects counts each stored object's references and size.
+type archiveObjects struct {
+	refs   map[string]int
+	stored map[string]int64
+}
+
+// total is the archive's size on disk, each object counted once.
+func (o archiveObjects) total() int64 {
+	var n int64
+	for _, size := range o.stored {
+		n += size
+	}
+	return n
+}
+
+// release drops one reference to each of sums, returning the objects no
+// longer referenced and the bytes they hold.
+func (o archiveObjects) release(sums []string) (freed []string, size int64) {
+	for _, sum := range sums {
+		o.refs[sum]--
+		if o.refs[sum] == 0 {
+			freed = append(freed, sum)
+			size += o.stored[sum]
+		}
+	}
+	return freed, size
+}
+
+// groupArchivedSessions groups rows by session, oldest last activity first,
+// and counts the objects they refer to.
+func groupArchivedSessions(rows []archiveRow) ([]archivedSession, archiveObjects) {
+	bySession := map[string]*archivedSession{}
+	objects := archiveObjects{refs: map[string]int{}, stored: map[string]int64{}}
+	for _, r := range rows {
+		s := bySession[r.sessionID]
+		if s == nil {
+			s = &archivedSession{id: r.sessionID, originalGone: true}
+			bySession[r.sessionID] = s
+		}
+		if r.mtime.After(s.last) {
+			s.last = r.mtime
+		}
+		s.originalGone = s.originalGone && r.missing
+		s.sums = append(s.sums, r.sha256)
+		objects.refs[r.sha256]++
+		objects.stored[r.sha256] = r.storedSize
+	}
+	sessions := make([]archivedSession, 0, len(bySession))
+	for _, s := range bySession {
+		sessions = append(sessions, *s)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].last.Before(sessions[j].last) })
+	return sessions, objects
+}
+
+func (a *TranscriptArchive) deleteSessions(ctx context.Context, sessions []archivedSession) error {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("pruning transcript archive: %w", err)
+	}
+	for _, s := range sessions {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM claude_transcript_archive WHERE session_id = ?`, s.id); err != nil {
+			tx.Rollback() //nolint:errcheck,gosec
+			return fmt.Errorf("pruning transcript archive: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pruning transcript archive: %w", err)
+	}
+	return nil
+}
+
+// ── Usage ───────────────────────────────────────────────────────────────────
+
+// ArchiveUsage reports what the transcript archive holds.
+type ArchiveUsage struct {
+	// Enabled is false when no archive is installed or the user turned
+	// copying off. Copies already made are still counted.
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir"`
+	// Transcripts counts archived files, sub-agent transcripts included.
+	Transcripts int `json:"transcripts"`
+	Sessions    int `json:"sessions"`
+	// ArchivedOnlySessions are the sessions Claude Code has deleted and only
+	// the archive still holds.
+	ArchivedOnlySessions int `json:"archived_only_sessions"`
+	// OriginalBytes is the transcripts' uncompressed size; StoredBytes is what
+	// the archive takes on disk, each distinct content counted once.
+	OriginalBytes int64 `json:"original_bytes"`
+	StoredBytes   int64 `json:"stored_bytes"`
+}
+
+// TranscriptArchiveUsage reports the installed archive's contents. A process
+// with no archive installed reports an empty, disabled one.
+func TranscriptArchiveUsage(ctx context.Context) (ArchiveUsage, error) {
+	a, policy := installedArchive()
+	if a == nil {
+		return ArchiveUsage{}, nil
+	}
+	rows, err := a.loadRows(ctx)
+	if err != nil {
+		return ArchiveUsage{}, err
+	}
+	sessions, objects := groupArchivedSessions(rows)
+	u := ArchiveUsage{
+		Enabled:     !policy.disabled,
+		Dir:         a.dir,
+		Transcripts: len(rows),
+		Sessions:    len(sessions),
+		StoredBytes: objects.total(),
+	}
+	for _, s := range sessions {
+		if s.originalGone {
+			u.ArchivedOnlySessions++
+		}
+	}
+	for _, r := range rows {
+		u.OriginalBytes += r.size
+	}
+	return u, nil
+}
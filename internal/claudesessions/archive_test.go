@@ -0,0 +1,174 @@
+package claudesessions
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useArchive installs a transcript archive over db for the test, and
+// uninstalls it afterwards.
+func useArchive(t *testing.T, db *sql.DB) *TranscriptArchive {
+	t.Helper()
+	a := NewTranscriptArchive(db, filepath.Join(t.TempDir(), "transcripts"), testLogger)
+	InstallTranscriptArchive(a)
+	ApplyArchiveSettings(false, 0, 0)
+	t.Cleanup(func() {
+		InstallTranscriptArchive(nil)
+		ApplyArchiveSettings(false, 0, 0)
+	})
+	return a
+}
+
+// archiveObjectCount counts the objects in the archive's store.
+func archiveObjectCount(t *testing.T, a *TranscriptArchive) int {
+	t.Helper()
+	objects, err := filepath.Glob(filepath.Join(a.dir, "objects", "*", "*.jsonl.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(objects)
+}
+
+func TestTranscriptArchive_ScanKeepsSessionsClaudeDeleted(t *testing.T) {
+	home := t.TempDir()
+	useConfigDirs(t, home)
+	at := time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC)
+	fp := writeSessionIn(t, filepath.Join(home, ".claude"), "-home-dev-work", "sess-kept", at)
+	subagentsDir := filepath.Join(filepath.Dir(fp), "sess-kept", "subagents")
+	subagent := writeJSONL(t, subagentsDir, "agent-a1", at.Add(time.Second))
+	meta := `{"agentType":"Explore","description":"look around","toolUseId":"toolu_1"}`
+	if err := os.WriteFile(filepath.Join(subagentsDir, "agent-a1.meta.json"), []byte(meta), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newScanCache(t)
+	useArchive(t, c.db)
+	if _, err := IncrementalScan(c.db, testLogger); err != nil {
+		t.Fatalf("first scan: %v", err)
+	}
+
+	// Claude Code's cleanup removes the transcript and its sub-agents.
+	if err := os.RemoveAll(filepath.Dir(fp)); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := IncrementalScan(c.db, testLogger)
+	if err != nil {
+		t.Fatalf("second scan: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].SessionID != "sess-kept" {
+		t.Fatalf("sessions after the original was deleted = %+v, want sess-kept", sessions)
+	}
+	subagents, err := ListSubagents(c.db, testLogger, "sess-kept")
+	if err != nil {
+		t.Fatalf("listing sub-agents: %v", err)
+	}
+	if len(subagents) != 1 || subagents[0].AgentType != "Explore" {
+		t.Errorf("sub-agents = %+v, want the archived Explore agent", subagents)
+	}
+
+	journey, err := GetSessionJourney("sess-kept", testLogger)
+	if err != nil {
+		t.Fatalf("journey: %v", err)
+	}
+	if journey == nil || len(journey.Turns) == 0 {
+		t.Fatalf("journey = %+v, want one read from the archive", journey)
+	}
+	if got := SubagentFiles("sess-kept", fp); len(got) != 1 || got[0] != subagent {
+		t.Errorf("SubagentFiles = %v, want [%s]", got, subagent)
+	}
+
+	usage, err := TranscriptArchiveUsage(context.Background())
+	if err != nil {
+		t.Fatalf("usage: %v", err)
+	}
+	if usage.Transcripts != 2 || usage.Sessions != 1 || usage.ArchivedOnlySessions != 1 {
+		t.Errorf("usage = %+v, want 2 transcripts of 1 archive-only session", usage)
+	}
+	if usage.StoredBytes == 0 || usage.OriginalBytes == 0 {
+		t.Errorf("usage = %+v, want the sizes of both copies", usage)
+	}
+}
+
+func TestTranscriptArchive_StoreSharesAndReleasesObjects(t *testing.T) {
+	home := t.TempDir()
+	at := time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC)
+	c := newScanCache(t)
+	a := useArchive(t, c.db)
+	ctx := context.Background()
+
+	// The same content under two config dirs is stored once.
+	first := writeSessionIn(t, filepath.Join(home, ".claude"), "-p", "sess-same", at)
+	second := writeSessionIn(t, filepath.Join(home, ".claude-two"), "-p", "sess-same", at)
+	for _, fp := range []string{first, second} {
+		if err := a.store(ctx, diskFile{sessionID: "sess-same", filePath: fp, mtime: at}); err != nil {
+			t.Fatalf("store %s: %v", fp, err)
+		}
+	}
+	if n := archiveObjectCount(t, a); n != 1 {
+		t.Fatalf("objects after storing identical content twice = %d, want 1", n)
+	}
+
+	// Rewriting both releases the object they shared.
+	for _, fp := range []string{first, second} {
+		writeJSONL(t, filepath.Dir(fp), "sess-same", at.Add(time.Hour))
+		if err := a.store(ctx, diskFile{sessionID: "sess-same", filePath: fp, mtime: at.Add(time.Hour)}); err != nil {
+			t.Fatalf("store %s: %v", fp, err)
+		}
+	}
+	if n := archiveObjectCount(t, a); n != 1 {
+		t.Errorf("objects after both copies changed = %d, want only the new one", n)
+	}
+
+	// The copy reads back as the file did.
+	if err := os.Remove(first); err != nil {
+		t.Fatal(err)
+	}
+	summary, _, err := readSessionSummary("sess-same", "/p", first, testLogger)
+	if err != nil || summary == nil {
+		t.Fatalf("reading the archived copy: %v", err)
+	}
+	if !summary.StartTime.Equal(at.Add(time.Hour)) {
+		t.Errorf("archived copy starts at %v, want %v", summary.StartTime, at.Add(time.Hour))
+	}
+}
+
+func TestTranscriptArchive_PruneOnlyDropsSessionsClaudeDeleted(t *testing.T) {
+	home := t.TempDir()
+	old := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	c := newScanCache(t)
+	a := useArchive(t, c.db)
+	ctx := context.Background()
+
+	for _, id := range []string{"sess-gone", "sess-here"} {
+		fp := writeSessionIn(t, filepath.Join(home, ".claude"), "-p", id, old)
+		if err := a.store(ctx, diskFile{sessionID: id, filePath: fp, mtime: old}); err != nil {
+			t.Fatalf("store %s: %v", id, err)
+		}
+	}
+	if _, err := c.db.ExecContext(ctx, `UPDATE claude_transcript_archive
+		SET original_missing_since = ? WHERE session_id = 'sess-gone'`, old); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC)
+	for _, policy := range []archivePolicy{{retentionDays: 30}, {maxBytes: 1}} {
+		if err := a.prune(ctx, policy, now); err != nil {
+			t.Fatalf("prune %+v: %v", policy, err)
+		}
+	}
+
+	rows, err := a.loadRows(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].sessionID != "sess-here" {
+		t.Errorf("archive after pruning = %+v, want only sess-here", rows)
+	}
+	if n := archiveObjectCount(t, a); n != 1 {
+		t.Errorf("objects after pruning = %d, want 1", n)
+	}
+}
@@ -3,6 +3,7 @@ package claudesessions
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log/slog"
 	"sort"
 	"strings"
@@ -24,6 +25,11 @@ const CacheTTL = 1 * time.Hour
 // Every warm read returns immediately, however stale.
 const coldStartScanWait = 30 * time.Second
 
+// anomalyAlertWindow is how far back a finished scan looks for anomalies to
+// publish. A day reaches back to yesterday's sessions, so one that ran
+// overnight is still alerted on when the next scan happens in the morning.
+const anomalyAlertWindow = 24 * time.Hour
+
 // pricingRevUnknown marks a resolver snapshot whose revision could not be
 // read; it never matches a stored revision, so a degraded pricing store
 // behaves as "cost data may be stale" rather than asserting it is fresh.
@@ -105,6 +111,17 @@ type Cache struct {
 	// should ever wait on the other.
 	filesDone  atomic.Int64
 	filesTotal atomic.Int64
+
+	// alerted holds the anomalies and usage window warnings already
+	// published, guarded by mu, so a day that stays unusual is alerted on once
+	// rather than after every scan.
+	alerted map[string]struct{}
+
+	// live tracks the sessions Claude Code's hooks report open. See live.go.
+	live *liveSessions
+	// ingests counts the hook-driven re-reads that wrote rows. They change
+	// the corpus without a scan, so the analytics memo keys on it too.
+	ingests atomic.Int64
 }
 
 // NewCache creates a new Cache backed by the given SQLite database.
@@ -113,11 +130,15 @@ func NewCache(db *sql.DB, logger *slog.Logger) *Cache {
 		db:        db,
 		logger:    logger,
 		analytics: newAnalyticsMemo(),
+		alerted:   map[string]struct{}{},
+		live:      newLiveSessions(),
 	}
 }
 
 // WithEventBus attaches an event bus to the cache so that newly discovered or
-// updated sessions trigger EventSessionDiscovered / EventSessionUpdated events.
+// updated sessions trigger EventSessionDiscovered / EventSessionUpdated events,
+// usage anomalies EventUsageAnomalyDetected, and subscription usage windows
+// nearing their limit EventUsageLimitApproaching.
 func (c *Cache) WithEventBus(bus eventbus.EventBus) *Cache {
 	c.bus = bus
 	return c
@@ -204,6 +225,57 @@ func (c *Cache) notify(sessionID, filePath string, isNew bool) {
 	})
 }
 
+// alertAnomalies publishes EventUsageAnomalyDetected for each anomaly of the
+// last anomalyAlertWindow not already published by this process.
+//
+// Days are taken in the server's local timezone: Agento runs on the machine
+// whose sessions it reads, so that is the user's day. The published set is not
+// persisted, so a restart can repeat an alert for an anomaly still in the
+// window — a repeat is a smaller failure than a missed alert.
+func (c *Cache) alertAnomalies() {
+	if c.bus == nil {
+		return
+	}
+	now := time.Now()
+	anomalies := DetectAnomalies(c.loadOrEmpty(), AnalyticsParams{
+		From: now.Add(-anomalyAlertWindow),
+		To:   now,
+		Loc:  time.Local,
+	})
+	for _, a := range anomalies {
+		key := string(a.Scope) + ":" + a.Date + ":" + a.SessionID
+		c.mu.Lock()
+		_, seen := c.alerted[key]
+		c.alerted[key] = struct{}{}
+		c.mu.Unlock()
+		if seen {
+			continue
+		}
+		c.bus.Publish(eventbus.EventUsageAnomalyDetected, anomalyPayload(a))
+	}
+}
+
+// anomalyPayload renders an anomaly as an event payload.
+func anomalyPayload(a Anomaly) map[string]string {
+	format := "%.2f"
+	if a.Metric == AnomalyMetricTokens {
+		format = "%.0f"
+	}
+	payload := map[string]string{
+		eventbus.PayloadKeyScope:    string(a.Scope),
+		eventbus.PayloadKeyMetric:   string(a.Metric),
+		eventbus.PayloadKeyDate:     a.Date,
+		eventbus.PayloadKeyValue:    fmt.Sprintf(format, a.Value),
+		eventbus.PayloadKeyBaseline: fmt.Sprintf(format, a.Baseline),
+		eventbus.PayloadKeyScore:    fmt.Sprintf("%.1f", a.Score),
+	}
+	if a.Scope == AnomalyScopeSession {
+		payload[eventbus.PayloadKeySessionID] = a.SessionID
+		payload[eventbus.PayloadKeyProjectPath] = a.Project
+	}
+	return payload
+}
+
 // StartBackgroundScan runs an incremental scan in a background goroutine so
 // the server starts immediately while the cache is being populated.
 func (c *Cache) StartBackgroundScan() {
@@ -250,6 +322,8 @@ func (c *Cache) EnsureScan() <-chan struct{} {
 			return
 		}
 		c.logger.Info("claude sessions: background scan complete")
+		c.alertAnomalies()
+		c.alertUsageWindows()
 	}()
 	return done
 }
@@ -0,0 +1,365 @@
+package claudesessions
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// codexSource reads OpenAI Codex CLI sessions: one JSONL "rollout" per session,
+// filed by date under $CODEX_HOME/sessions/YYYY/MM/DD/.
+//
+// A rollout records the model's response items — messages, reasoning
+// summaries, tool calls and their outputs — with a token_count event after
+// each response. Those become the assistant messages, tool_use blocks and
+// tool_result carriers of a Claude Code transcript, each response carrying the
+// usage its token_count reported.
+type codexSource struct {
+	home string
+}
+
+// NewCodexSource reads the sessions under home, a Codex CLI home directory.
+func NewCodexSource(home string) TranscriptSource {
+	return &codexSource{home: home}
+}
+
+// DefaultCodexHome returns where Codex CLI keeps its state: $CODEX_HOME, the
+// variable Codex itself reads, or ~/.codex.
+func DefaultCodexHome() string {
+	if v := strings.TrimSpace(os.Getenv("CODEX_HOME")); v != "" {
+		return filepath.Clean(v)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join("/root", ".codex")
+	}
+	return filepath.Join(home, ".codex")
+}
+
+func (s *codexSource) Agent() string { return CodingAgentCodex }
+
+func (s *codexSource) root() string { return filepath.Join(s.home, "sessions") }
+
+func (s *codexSource) Owns(path string) bool {
+	return strings.HasPrefix(path, s.root()+string(filepath.Separator)) && strings.HasSuffix(path, jsonlExt)
+}
+
+// Walk lists every rollout under sessions/. A home without one has never run
+// a session and walks clean; a missing home walks nothing, so its rows are
+// kept, as a missing Claude config dir's are.
+func (s *codexSource) Walk(_ []string, logger *slog.Logger) SourceWalk {
+	root := s.root()
+	if !dirExists(root) {
+		if dirExists(s.home) {
+			return SourceWalk{Roots: []string{root}}
+		}
+		return SourceWalk{}
+	}
+
+	w := SourceWalk{Roots: []string{root}}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() && path != root {
+				logger.Warn("claude sessions: skipping unreadable codex dir", "dir", path, "error", err)
+				w.Protected = append(w.Protected, path)
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasPrefix(d.Name(), "rollout-") || !strings.HasSuffix(d.Name(), jsonlExt) {
+			return nil
+		}
+		if t, ok := codexTranscript(root, path, d); ok {
+			w.Transcripts = append(w.Transcripts, t)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Warn("claude sessions: skipping unreadable codex sessions dir", "dir", root, "error", err)
+		return SourceWalk{}
+	}
+	return w
+}
+
+// codexTranscript describes one rollout from its session_meta line, which
+// carries the session id and the directory Codex ran in. A rollout without
+// one — being created, or written by a release too old to record it — is
+// skipped until it has one.
+func codexTranscript(root, path string, d fs.DirEntry) (SourceTranscript, bool) {
+	info, err := d.Info()
+	if err != nil {
+		return SourceTranscript{}, false
+	}
+	f, err := os.Open(path) //nolint:gosec // path found under the Codex sessions dir
+	if err != nil {
+		return SourceTranscript{}, false
+	}
+	defer f.Close() //nolint:errcheck
+	first, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return SourceTranscript{}, false
+	}
+	var line codexLine
+	var meta codexPayload
+	if json.Unmarshal(first, &line) != nil || line.Type != "session_meta" ||
+		json.Unmarshal(line.Payload, &meta) != nil || !validSessionID.MatchString(meta.ID) {
+		return SourceTranscript{}, false
+	}
+	return SourceTranscript{
+		SessionID:   meta.ID,
+		ProjectPath: meta.CWD,
+		Path:        path,
+		ModTime:     info.ModTime(),
+		Root:        root,
+	}, true
+}
+
+func (s *codexSource) Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path) //nolint:gosec // path found under the Codex sessions dir
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+	out, err := normalizeCodex(f)
+	if err != nil {
+		return nil, err
+	}
+	return out.reader(), nil
+}
+
+// codexLine is one rollout line: a typed envelope around its payload.
+type codexLine struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// codexPayload is the union of the payload fields the reader uses. Which are
+// set depends on the envelope's type and, for a response item or an event,
+// the payload's own.
+type codexPayload struct {
+	Type string `json:"type"`
+
+	// session_meta and turn_context.
+	ID    string `json:"id"`
+	CWD   string `json:"cwd"`
+	Model string `json:"model"`
+	Git   *struct {
+		Branch string `json:"branch"`
+	} `json:"git"`
+
+	// message and reasoning.
+	Role    string         `json:"role"`
+	Content []codexContent `json:"content"`
+	Summary []codexContent `json:"summary"`
+
+	// function_call, custom_tool_call and their outputs.
+	CallID    string          `json:"call_id"`
+	Name      string          `json:"name"`
+	Arguments string          `json:"arguments"`
+	Input     string          `json:"input"`
+	Output    json.RawMessage `json:"output"`
+
+	// token_count.
+	Info *struct {
+		Total *codexTokens `json:"total_token_usage"`
+		Last  *codexTokens `json:"last_token_usage"`
+	} `json:"info"`
+}
+
+type codexContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// codexTokens is OpenAI's usage: input_tokens includes the cached ones, and
+// output_tokens includes reasoning.
+type codexTokens struct {
+	InputTokens       int `json:"input_tokens"`
+	CachedInputTokens int `json:"cached_input_tokens"`
+	OutputTokens      int `json:"output_tokens"`
+	TotalTokens       int `json:"total_tokens"`
+}
+
+// codexReader turns a rollout into Claude Code events. A response's items are
+// held until its token_count arrives, so the usage lands on the message that
+// spent it; anything that can only follow a finished response — a tool's
+// output, the next prompt — writes what is held first.
+type codexReader struct {
+	out       claudeTranscript
+	model     string
+	pending   []rawContentBlock
+	pendingAt time.Time
+	lastTotal int
+}
+
+func normalizeCodex(r io.Reader) (*claudeTranscript, error) {
+	var cr codexReader
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 4*1024*1024), 16*1024*1024)
+	for sc.Scan() {
+		var line codexLine
+		var p codexPayload
+		if json.Unmarshal(sc.Bytes(), &line) != nil || !decodeLenient(line.Payload, &p) {
+			continue
+		}
+		cr.process(line, p)
+	}
+	cr.flush(time.Time{}, nil)
+	return &cr.out, sc.Err()
+}
+
+func (cr *codexReader) process(line codexLine, p codexPayload) {
+	switch line.Type {
+	case "session_meta":
+		cr.out.sessionID = p.ID
+		cr.out.cwd = p.CWD
+		if p.Git != nil {
+			cr.out.gitBranch = p.Git.Branch
+		}
+	case "turn_context":
+		if p.Model != "" {
+			cr.model = p.Model
+		}
+		if p.CWD != "" {
+			cr.out.cwd = p.CWD
+		}
+	case "response_item":
+		cr.responseItem(line.Timestamp, p)
+	case "event_msg":
+		if p.Type == "token_count" {
+			cr.tokenCount(line.Timestamp, p)
+		}
+	}
+}
+
+func (cr *codexReader) responseItem(ts time.Time, p codexPayload) {
+	switch p.Type {
+	case "message":
+		text := codexText(p.Content)
+		switch {
+		case p.Role == "assistant" && text != "":
+			cr.hold(ts, rawContentBlock{Type: "text", Text: text})
+		case p.Role == "user" && text != "" && !isCodexContext(text):
+			cr.flush(ts, nil)
+			cr.out.prompt(ts, text)
+		}
+	case "reasoning":
+		if text := codexText(p.Summary); text != "" {
+			cr.hold(ts, rawContentBlock{Type: "thinking", Thinking: text})
+		}
+	case "function_call":
+		cr.hold(ts, rawContentBlock{Type: "tool_use", ID: p.CallID, Name: p.Name, Input: toolInput(p.Arguments)})
+	case "custom_tool_call":
+		cr.hold(ts, rawContentBlock{Type: "tool_use", ID: p.CallID, Name: p.Name, Input: toolInput(p.Input)})
+	case "function_call_output", "custom_tool_call_output":
+		cr.flush(ts, nil)
+		output, isError := codexToolOutput(p.Output)
+		cr.out.toolResult(ts, p.CallID, output, isError)
+	}
+}
+
+// tokenCount closes the response in progress with its usage. Codex repeats a
+// token_count when only its rate-limit figures changed, so one whose running
+// total has not moved is not a new response.
+func (cr *codexReader) tokenCount(ts time.Time, p codexPayload) {
+	if p.Info == nil || p.Info.Last == nil {
+		return
+	}
+	if p.Info.Total != nil {
+		if p.Info.Total.TotalTokens == cr.lastTotal {
+			return
+		}
+		cr.lastTotal = p.Info.Total.TotalTokens
+	}
+	last := p.Info.Last
+	cr.flush(ts, promptUsage(last.InputTokens, last.CachedInputTokens, last.OutputTokens))
+}
+
+func (cr *codexReader) hold(ts time.Time, b rawContentBlock) {
+	if len(cr.pending) == 0 {
+		cr.pendingAt = ts
+	}
+	cr.pending = append(cr.pending, b)
+}
+
+// flush writes the held response. With usage and nothing held — the
+// token_count of a response whose tool call was already answered — it writes
+// a message carrying only the usage, so no tokens go uncounted.
+func (cr *codexReader) flush(ts time.Time, usage *rawUsage) {
+	if len(cr.pending) == 0 && usage == nil {
+		return
+	}
+	at := ts
+	if len(cr.pending) > 0 {
+		at = cr.pendingAt
+	}
+	cr.out.reply(at, cr.model, cr.pending, usage)
+	cr.pending = nil
+}
+
+// decodeLenient decodes what it can of raw into v. Agents add fields and
+// change their shapes between releases, and a field of an unexpected shape is
+// left zero rather than losing the whole line.
+func decodeLenient(raw []byte, v any) bool {
+	err := json.Unmarshal(raw, v)
+	var typeErr *json.UnmarshalTypeError
+	return err == nil || errors.As(err, &typeErr)
+}
+
+// codexText joins a message's text parts.
+func codexText(parts []codexContent) string {
+	texts := make([]string, 0, len(parts))
+	for _, c := range parts {
+		if c.Text != "" {
+			texts = append(texts, c.Text)
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n"))
+}
+
+// isCodexContext reports whether a user-role message is context Codex sends
+// on the user's behalf — the environment and the project's AGENTS.md — rather
+// than a prompt.
+func isCodexContext(text string) bool {
+	for _, prefix := range []string{"<environment_context>", "<user_instructions>", "# AGENTS.md instructions"} {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// codexToolOutput returns a tool's output and whether it failed. A shell call's
+// output is itself JSON, carrying the command's exit code; newer releases
+// record some outputs as an object with a success flag instead.
+func codexToolOutput(raw json.RawMessage) (string, bool) {
+	var output string
+	if json.Unmarshal(raw, &output) != nil {
+		var structured struct {
+			Content string `json:"content"`
+			Success *bool  `json:"success"`
+		}
+		if json.Unmarshal(raw, &structured) != nil {
+			return string(raw), false
+		}
+		return structured.Content, structured.Success != nil && !*structured.Success
+	}
+	var shell struct {
+		Output   *string `json:"output"`
+		Metadata struct {
+			ExitCode int `json:"exit_code"`
+		} `json:"metadata"`
+	}
+	if json.Unmarshal([]byte(output), &shell) == nil && shell.Output != nil {
+		return *shell.Output, shell.Metadata.ExitCode != 0
+	}
+	return output, false
+}
@@ -0,0 +1,156 @@
+package claudesessions
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// codexLineJSON renders one rollout line.
+func codexLineJSON(at time.Time, lineType string, payload map[string]any) string {
+	b, _ := json.Marshal(map[string]any{
+		"timestamp": at.Format(time.RFC3339Nano),
+		"type":      lineType,
+		"payload":   payload,
+	})
+	return string(b)
+}
+
+func codexTokenCount(at time.Time, total, input, cached, output int) string {
+	return codexLineJSON(at, "event_msg", map[string]any{
+		"type": "token_count",
+		"info": map[string]any{
+			"total_token_usage": map[string]any{"total_tokens": total},
+			"last_token_usage": map[string]any{
+				"input_tokens": input, "cached_input_tokens": cached,
+				"output_tokens": output, "total_tokens": input + output,
+			},
+		},
+	})
+}
+
+// writeCodexRollout writes a rollout for one prompt that ran a failing shell
+// command and then answered, the way Codex CLI records it.
+func writeCodexRollout(t *testing.T, home, sessionID, cwd string, at time.Time) string {
+	t.Helper()
+	dir := filepath.Join(home, "sessions", at.Format("2006"), at.Format("01"), at.Format("02"))
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "rollout-"+at.Format("2006-01-02T15-04-05")+"-"+sessionID+jsonlExt)
+	args, _ := json.Marshal(map[string]any{"command": []string{"go", "test", "./..."}})
+	output, _ := json.Marshal(map[string]any{"output": "FAIL", "metadata": map[string]any{"exit_code": 1}})
+	lines := []string{
+		codexLineJSON(at, "session_meta", map[string]any{
+			"id": sessionID, "cwd": cwd, "git": map[string]any{"branch": "main"},
+		}),
+		codexLineJSON(at, "turn_context", map[string]any{"model": "gpt-5-codex", "cwd": cwd}),
+		codexLineJSON(at, "response_item", map[string]any{
+			"type": "message", "role": "user",
+			"content": []map[string]any{{"type": "input_text", "text": "<environment_context>\n  <cwd>" + cwd}},
+		}),
+		codexLineJSON(at, "response_item", map[string]any{
+			"type": "message", "role": "user",
+			"content": []map[string]any{{"type": "input_text", "text": "Fix the failing test"}},
+		}),
+		codexLineJSON(at.Add(time.Second), "response_item", map[string]any{
+			"type": "reasoning", "summary": []map[string]any{{"type": "summary_text", "text": "Running the tests"}},
+		}),
+		codexLineJSON(at.Add(2*time.Second), "response_item", map[string]any{
+			"type": "function_call", "name": "shell", "arguments": string(args), "call_id": "call_1",
+		}),
+		codexTokenCount(at.Add(2*time.Second), 1500, 1200, 1000, 300),
+		codexLineJSON(at.Add(5*time.Second), "response_item", map[string]any{
+			"type": "function_call_output", "call_id": "call_1", "output": string(output),
+		}),
+		codexLineJSON(at.Add(8*time.Second), "response_item", map[string]any{
+			"type": "message", "role": "assistant",
+			"content": []map[string]any{{"type": "output_text", "text": "Fixed it."}},
+		}),
+		codexTokenCount(at.Add(8*time.Second), 2400, 800, 600, 100),
+		// Repeated for a rate-limit update: the same response, not a new one.
+		codexTokenCount(at.Add(9*time.Second), 2400, 800, 600, 100),
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCodexSource_WalksAndNormalizesRollouts(t *testing.T) {
+	home := t.TempDir()
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	path := writeCodexRollout(t, home, "0199a1b2-c3d4-7e5f", "/home/dev/api", at)
+
+	src := NewCodexSource(home)
+	InstallTranscriptSources(src)
+	t.Cleanup(func() { InstallTranscriptSources() })
+
+	w := src.Walk(nil, testLogger)
+	if len(w.Transcripts) != 1 {
+		t.Fatalf("walk found %d transcripts, want 1", len(w.Transcripts))
+	}
+	tr := w.Transcripts[0]
+	if tr.SessionID != "0199a1b2-c3d4-7e5f" || tr.ProjectPath != "/home/dev/api" || tr.Path != path {
+		t.Errorf("transcript = %+v", tr)
+	}
+
+	s, _, err := readSessionSummary(tr.SessionID, tr.ProjectPath, tr.Path, testLogger)
+	if err != nil {
+		t.Fatalf("read summary: %v", err)
+	}
+	if s.Preview != "Fix the failing test" {
+		t.Errorf("preview = %q, want the prompt rather than the injected context", s.Preview)
+	}
+	if s.Model != "gpt-5-codex" || s.GitBranch != "main" {
+		t.Errorf("model, branch = %q, %q", s.Model, s.GitBranch)
+	}
+	// Cached input is split out of OpenAI's input total, and the repeated
+	// token_count is not counted twice.
+	want := TokenUsage{InputTokens: 400, CacheReadTokens: 1600, OutputTokens: 400}
+	if s.Usage != want {
+		t.Errorf("usage = %+v, want %+v", s.Usage, want)
+	}
+	if s.MessageCount != 2 {
+		t.Errorf("message count = %d, want the prompt and the answer", s.MessageCount)
+	}
+	if s.Cost.TotalUSD <= 0 {
+		t.Errorf("cost = %+v, want gpt-5-codex priced through the catalog", s.Cost)
+	}
+}
+
+func TestCodexSource_ShellExitCodeMarksToolError(t *testing.T) {
+	home := t.TempDir()
+	path := writeCodexRollout(t, home, "sess-exit", "/home/dev/api", time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC))
+
+	rc, err := NewCodexSource(home).Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer rc.Close()
+	out, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), `"tool_use_id":"call_1","content":"FAIL","is_error":true`) {
+		t.Errorf("no failed tool_result for call_1 in:\n%s", out)
+	}
+}
+
+func TestCodexSource_MissingHomeWalksNothing(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "gone")
+	if w := NewCodexSource(missing).Walk(nil, testLogger); len(w.Roots) != 0 {
+		t.Errorf("a missing home reported roots %v, which would drop its cached rows", w.Roots)
+	}
+
+	// A home that has never run a session is listed, and lists nothing.
+	home := t.TempDir()
+	w := NewCodexSource(home).Walk(nil, testLogger)
+	if len(w.Roots) != 1 || len(w.Transcripts) != 0 {
+		t.Errorf("empty home walk = %+v", w)
+	}
+}
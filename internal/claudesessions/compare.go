@@ -0,0 +1,632 @@
+package claudesessions
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Session comparison: two runs of the same task, side by side.
+//
+// Both journeys are reduced to what a run *did* — the prompts it was given, the
+// tools it called in order, and whether each call failed — because that is
+// what differs between a run that worked and one that did not. Assistant prose
+// and thinking are left out: no two runs word them alike, so comparing them
+// would put the divergence at the first response every time.
+//
+// Only the main thread is compared. A sub-agent's work is nested under the
+// Task call that spawned it, and that call is compared like any other.
+
+// DiffOp says which run a tool call in an aligned sequence belongs to.
+type DiffOp string
+
+// The operations of an aligned tool sequence.
+const (
+	DiffSame      DiffOp = "same"
+	DiffLeftOnly  DiffOp = "left_only"
+	DiffRightOnly DiffOp = "right_only"
+)
+
+// DivergenceReason says what kind of step the runs first disagreed on.
+type DivergenceReason string
+
+const (
+	// DivergencePrompt is a turn that opened with a different prompt.
+	DivergencePrompt DivergenceReason = "prompt"
+	// DivergenceTool is a different tool called, or a tool called by one run
+	// where the other called none.
+	DivergenceTool DivergenceReason = "tool"
+	// DivergenceOutcome is the same tool call succeeding in one run and
+	// failing in the other.
+	DivergenceOutcome DivergenceReason = "outcome"
+	// DivergenceCompaction is one run compacting its context where the other
+	// did not.
+	DivergenceCompaction DivergenceReason = "compaction"
+	// DivergenceLength is one run taking more turns than the other after
+	// agreeing on every turn they share.
+	DivergenceLength DivergenceReason = "length"
+)
+
+// SessionComparison is the response for the comparison endpoint. Every delta
+// is right minus left, so a positive cost delta means the right run spent more.
+type SessionComparison struct {
+	Left  ComparedSession `json:"left"`
+	Right ComparedSession `json:"right"`
+	// Divergence is the first step at which the runs stopped doing the same
+	// thing; nil when they match step for step.
+	Divergence *Divergence `json:"divergence"`
+	// Turns pairs the runs turn by turn, by turn number.
+	Turns []TurnComparison `json:"turns"`
+	// ToolSequence is the two runs' tool calls aligned by a longest common
+	// subsequence: calls both made in the same order are "same", the rest are
+	// the calls only one run made.
+	ToolSequence []ToolSequenceEntry `json:"tool_sequence"`
+	// ToolSequenceCoarse is set when the sequences were too long to align
+	// call by call. The shared head and tail are still aligned; the middle of
+	// each run is listed whole, left then right.
+	ToolSequenceCoarse bool             `json:"tool_sequence_coarse,omitempty"`
+	ToolCounts         []ToolCountDelta `json:"tool_counts"`
+	Errors             ErrorComparison  `json:"errors"`
+	// Metrics compares the two runs' session insights. Empty until both have
+	// been through the insight pipeline.
+	Metrics []MetricDelta `json:"metrics"`
+}
+
+// ComparedSession is one run's headline figures. Usage and CostUSD are the
+// main thread's, matching the per-turn figures they sum.
+type ComparedSession struct {
+	SessionID        string     `json:"session_id"`
+	Model            string     `json:"model,omitempty"`
+	CWD              string     `json:"cwd,omitempty"`
+	GitBranch        string     `json:"git_branch,omitempty"`
+	Summary          string     `json:"summary,omitempty"`
+	StartTime        time.Time  `json:"start_time"`
+	ActiveDurationMs int64      `json:"active_duration_ms"`
+	Turns            int        `json:"turns"`
+	ToolCalls        int        `json:"tool_calls"`
+	ToolErrors       int        `json:"tool_errors"`
+	SubagentCount    int        `json:"subagent_count"`
+	Usage            TokenUsage `json:"usage"`
+	CostUSD          float64    `json:"cost_usd"`
+}
+
+// TurnComparison is one turn of both runs. A side is nil when only the other
+// run reached this turn; its figures then count as zero in the deltas.
+type TurnComparison struct {
+	Number          int          `json:"number"`
+	Left            *TurnSummary `json:"left"`
+	Right           *TurnSummary `json:"right"`
+	UsageDelta      TokenUsage   `json:"usage_delta"`
+	CostDeltaUSD    float64      `json:"cost_delta_usd"`
+	DurationDeltaMs int64        `json:"duration_delta_ms"`
+	ToolCallsDelta  int          `json:"tool_calls_delta"`
+	// SameTools reports whether both runs called the same tools in the same
+	// order during this turn.
+	SameTools bool `json:"same_tools"`
+}
+
+// TurnSummary is what one run did in one turn.
+type TurnSummary struct {
+	Prompt     string     `json:"prompt,omitempty"`
+	Usage      TokenUsage `json:"usage"`
+	CostUSD    float64    `json:"cost_usd"`
+	DurationMs int64      `json:"duration_ms"`
+	Tools      []string   `json:"tools"`
+	Errors     int        `json:"errors"`
+}
+
+// ToolSequenceEntry is one tool call of the aligned sequence. LeftTurn and
+// RightTurn are the turns the call was made in, zero on the side that did not
+// make it.
+type ToolSequenceEntry struct {
+	Op        DiffOp `json:"op"`
+	Tool      string `json:"tool"`
+	LeftTurn  int    `json:"left_turn,omitempty"`
+	RightTurn int    `json:"right_turn,omitempty"`
+}
+
+// ToolCountDelta is how often each run called one tool.
+type ToolCountDelta struct {
+	Tool  string `json:"tool"`
+	Left  int    `json:"left"`
+	Right int    `json:"right"`
+	Delta int    `json:"delta"`
+}
+
+// ErrorComparison lists the tool errors only one run hit. Errors are matched
+// on tool and first line of the message, so the same failure in both runs —
+// a flaky test both retried — counts as Shared rather than as a difference.
+type ErrorComparison struct {
+	LeftOnly  []ToolError `json:"left_only"`
+	RightOnly []ToolError `json:"right_only"`
+	Shared    int         `json:"shared"`
+}
+
+// ToolError is one failed tool call.
+type ToolError struct {
+	Turn    int    `json:"turn"`
+	Tool    string `json:"tool"`
+	Message string `json:"message"`
+}
+
+// MetricDelta is one session-insight metric of both runs.
+type MetricDelta struct {
+	Name  string  `json:"name"`
+	Left  float64 `json:"left"`
+	Right float64 `json:"right"`
+	Delta float64 `json:"delta"`
+}
+
+// Divergence is the first step at which the runs disagreed. LeftStep and
+// RightStep index the turn's Steps in each journey; -1 on a side that has no
+// such step (its turn, or its run, had already ended).
+type Divergence struct {
+	Turn      int              `json:"turn"`
+	LeftStep  int              `json:"left_step"`
+	RightStep int              `json:"right_step"`
+	Reason    DivergenceReason `json:"reason"`
+	Left      string           `json:"left"`
+	Right     string           `json:"right"`
+}
+
+// maxToolDiffCells bounds the longest-common-subsequence table. Beyond it
+// (two runs of 2,000 calls each with nothing in common) the middle of the
+// sequences is listed whole instead of aligned. A variable so tests can
+// lower it.
+var maxToolDiffCells = 4_000_000
+
+// errorMessageRunes caps a tool error message in the comparison.
+const errorMessageRunes = 200
+
+// CompareJourneys aligns two journeys. The insights are optional; either one
+// nil leaves Metrics empty.
+func CompareJourneys(left, right *SessionJourney, leftInsight, rightInsight *SessionInsight) SessionComparison {
+	l, r := digestJourney(left), digestJourney(right)
+
+	cmp := SessionComparison{
+		Left:       l.compared(left),
+		Right:      r.compared(right),
+		Divergence: findDivergence(l, r),
+		Turns:      compareTurns(l, r),
+		ToolCounts: compareToolCounts(l, r),
+		Errors:     compareErrors(l, r),
+		Metrics:    compareInsights(leftInsight, rightInsight),
+	}
+	cmp.ToolSequence, cmp.ToolSequenceCoarse = diffToolSequences(l.calls, r.calls)
+	return cmp
+}
+
+// ── Digest ──────────────────────────────────────────────────────────────────
+
+// journeyDigest is a journey reduced to the steps a comparison reads.
+type journeyDigest struct {
+	turns  []turnDigest
+	calls  []toolCallRef
+	errors []ToolError
+}
+
+type turnDigest struct {
+	turn   *JourneyTurn
+	prompt string
+	events []stepEvent
+	tools  []string
+	errors int
+}
+
+// stepEvent is one comparable step: key is what must match for two runs to
+// count as doing the same thing, desc how the step reads in a Divergence.
+type stepEvent struct {
+	index int
+	kind  string
+	key   string
+	desc  string
+}
+
+type toolCallRef struct {
+	name string
+	turn int
+}
+
+func digestJourney(j *SessionJourney) journeyDigest {
+	var d journeyDigest
+	for i := range j.Turns {
+		t := &j.Turns[i]
+		td := turnDigest{turn: t, tools: []string{}}
+		// Results name the call they answer only by id; the call's tool name
+		// is what a reader needs.
+		toolNames := map[string]string{}
+		for si, step := range t.Steps {
+			switch step.Type {
+			case "user_input":
+				var data UserInputData
+				_ = json.Unmarshal(step.Data, &data)
+				if td.prompt == "" {
+					td.prompt = truncateRunes(data.Content, 200)
+				}
+				td.events = append(td.events, stepEvent{
+					index: si, kind: step.Type, key: data.Content, desc: truncateRunes(data.Content, 120),
+				})
+			case "tool_call":
+				var data ToolCallData
+				_ = json.Unmarshal(step.Data, &data)
+				toolNames[data.ToolUseID] = data.ToolName
+				td.tools = append(td.tools, data.ToolName)
+				d.calls = append(d.calls, toolCallRef{name: data.ToolName, turn: t.Number})
+				td.events = append(td.events, stepEvent{
+					index: si, kind: step.Type, key: data.ToolName, desc: data.ToolName,
+				})
+			case "tool_result":
+				var data ToolResultData
+				_ = json.Unmarshal(step.Data, &data)
+				tool := toolNames[data.ToolUseID]
+				ev := stepEvent{index: si, kind: step.Type, key: tool + " ok", desc: tool + " succeeded"}
+				if data.IsError {
+					msg := firstLine(data.Content)
+					td.errors++
+					d.errors = append(d.errors, ToolError{Turn: t.Number, Tool: tool, Message: msg})
+					ev.key, ev.desc = tool+" error", tool+" failed: "+msg
+				}
+				td.events = append(td.events, ev)
+			case "compaction":
+				td.events = append(td.events, stepEvent{index: si, kind: step.Type, key: step.Type, desc: "compaction"})
+			}
+		}
+		d.turns = append(d.turns, td)
+	}
+	return d
+}
+
+func (d journeyDigest) compared(j *SessionJourney) ComparedSession {
+	c := ComparedSession{
+		SessionID:        j.SessionID,
+		Model:            j.Model,
+		CWD:              j.CWD,
+		GitBranch:        j.GitBranch,
+		Summary:          j.Summary,
+		StartTime:        j.StartTime,
+		ActiveDurationMs: j.ActiveDuration,
+		Turns:            j.TotalTurns,
+		ToolCalls:        len(d.calls),
+		ToolErrors:       len(d.errors),
+		SubagentCount:    j.SubagentCount,
+		Usage:            j.Usage,
+	}
+	for _, t := range j.Turns {
+		c.CostUSD += t.CostUSD
+	}
+	return c
+}
+
+// firstLine is the first non-blank line of a tool error, capped — enough to
+// tell two failures apart without shipping a stack trace.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return truncateRunes(line, errorMessageRunes)
+		}
+	}
+	return ""
+}
+
+// ── Divergence ──────────────────────────────────────────────────────────────
+
+func findDivergence(l, r journeyDigest) *Divergence {
+	shared := min(len(l.turns), len(r.turns))
+	for i := 0; i < shared; i++ {
+		le, re := l.turns[i].events, r.turns[i].events
+		for k := 0; k < max(len(le), len(re)); k++ {
+			var a, b *stepEvent
+			if k < len(le) {
+				a = &le[k]
+			}
+			if k < len(re) {
+				b = &re[k]
+			}
+			if a != nil && b != nil && a.kind == b.kind && a.key == b.key {
+				continue
+			}
+			return newDivergence(l.turns[i].turn.Number, a, b)
+		}
+	}
+	if len(l.turns) == len(r.turns) {
+		return nil
+	}
+	div := &Divergence{Turn: shared + 1, LeftStep: -1, RightStep: -1, Reason: DivergenceLength}
+	div.Left, div.Right = "ended", "ended"
+	if len(l.turns) > shared {
+		div.Left = "continued: " + turnOpening(l.turns[shared])
+	} else {
+		div.Right = "continued: " + turnOpening(r.turns[shared])
+	}
+	return div
+}
+
+func newDivergence(turn int, a, b *stepEvent) *Divergence {
+	div := &Divergence{Turn: turn, LeftStep: -1, RightStep: -1, Left: "end of turn", Right: "end of turn"}
+	if a != nil {
+		div.LeftStep, div.Left = a.index, a.desc
+	}
+	if b != nil {
+		div.RightStep, div.Right = b.index, b.desc
+	}
+	div.Reason = divergenceReason(a, b)
+	return div
+}
+
+// divergenceReason names a disagreement by the most telling step involved:
+// a tool call outranks a prompt, which outranks a compaction, and two
+// results of the same call differ only in outcome.
+func divergenceReason(a, b *stepEvent) DivergenceReason {
+	kinds := map[string]bool{}
+	for _, ev := range []*stepEvent{a, b} {
+		if ev != nil {
+			kinds[ev.kind] = true
+		}
+	}
+	switch {
+	case kinds["tool_call"]:
+		return DivergenceTool
+	case kinds["user_input"]:
+		return DivergencePrompt
+	case kinds["compaction"]:
+		return DivergenceCompaction
+	}
+	return DivergenceOutcome
+}
+
+func turnOpening(t turnDigest) string {
+	if len(t.events) == 0 {
+		return "an empty turn"
+	}
+	return t.events[0].desc
+}
+
+// ── Per-turn and aggregate deltas ───────────────────────────────────────────
+
+func compareTurns(l, r journeyDigest) []TurnComparison {
+	n := max(len(l.turns), len(r.turns))
+	out := make([]TurnComparison, 0, n)
+	for i := 0; i < n; i++ {
+		var tc TurnComparison
+		if i < len(l.turns) {
+			tc.Number = l.turns[i].turn.Number
+			tc.Left = l.turns[i].summary()
+		} else {
+			tc.Number = r.turns[i].turn.Number
+		}
+		if i < len(r.turns) {
+			tc.Right = r.turns[i].summary()
+		}
+
+		var left, right TurnSummary
+		if tc.Left != nil {
+			left = *tc.Left
+		}
+		if tc.Right != nil {
+			right = *tc.Right
+		}
+		tc.UsageDelta = usageDelta(left.Usage, right.Usage)
+		tc.CostDeltaUSD = right.CostUSD - left.CostUSD
+		tc.DurationDeltaMs = right.DurationMs - left.DurationMs
+		tc.ToolCallsDelta = len(right.Tools) - len(left.Tools)
+		tc.SameTools = tc.Left != nil && tc.Right != nil && slices.Equal(left.Tools, right.Tools)
+		out = append(out, tc)
+	}
+	return out
+}
+
+func (t turnDigest) summary() *TurnSummary {
+	s := &TurnSummary{
+		Prompt:     t.prompt,
+		CostUSD:    t.turn.CostUSD,
+		DurationMs: t.turn.DurationMs,
+		Tools:      t.tools,
+		Errors:     t.errors,
+	}
+	if t.turn.Usage != nil {
+		s.Usage = *t.turn.Usage
+	}
+	return s
+}
+
+// usageDelta is b minus a, field by field.
+func usageDelta(a, b TokenUsage) TokenUsage {
+	return TokenUsage{
+		InputTokens:           b.InputTokens - a.InputTokens,
+		OutputTokens:          b.OutputTokens - a.OutputTokens,
+		CacheCreationTokens:   b.CacheCreationTokens - a.CacheCreationTokens,
+		CacheCreation5mTokens: b.CacheCreation5mTokens - a.CacheCreation5mTokens,
+		CacheCreation1hTokens: b.CacheCreation1hTokens - a.CacheCreation1hTokens,
+		CacheReadTokens:       b.CacheReadTokens - a.CacheReadTokens,
+	}
+}
+
+// compareToolCounts lists every tool either run called, the largest
+// difference first.
+func compareToolCounts(l, r journeyDigest) []ToolCountDelta {
+	counts := map[string]*ToolCountDelta{}
+	entry := func(name string) *ToolCountDelta {
+		e, ok := counts[name]
+		if !ok {
+			e = &ToolCountDelta{Tool: name}
+			counts[name] = e
+		}
+		return e
+	}
+	for _, c := range l.calls {
+		entry(c.name).Left++
+	}
+	for _, c := range r.calls {
+		entry(c.name).Right++
+	}
+
+	out := make([]ToolCountDelta, 0, len(counts))
+	for _, e := range counts {
+		e.Delta = e.Right - e.Left
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		di, dj := abs(out[i].Delta), abs(out[j].Delta)
+		if di != dj {
+			return di > dj
+		}
+		return out[i].Tool < out[j].Tool
+	})
+	return out
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// compareErrors matches the runs' tool errors as multisets, so a failure both
+// runs hit twice is shared twice and a third occurrence is a difference.
+func compareErrors(l, r journeyDigest) ErrorComparison {
+	key := func(e ToolError) string { return e.Tool + "\x00" + e.Message }
+	unmatched := map[string]int{}
+	for _, e := range r.errors {
+		unmatched[key(e)]++
+	}
+
+	out := ErrorComparison{LeftOnly: []ToolError{}, RightOnly: []ToolError{}}
+	matched := map[string]int{}
+	for _, e := range l.errors {
+		k := key(e)
+		if unmatched[k] > 0 {
+			unmatched[k]--
+			matched[k]++
+			out.Shared++
+			continue
+		}
+		out.LeftOnly = append(out.LeftOnly, e)
+	}
+	for _, e := range r.errors {
+		k := key(e)
+		if matched[k] > 0 {
+			matched[k]--
+			continue
+		}
+		out.RightOnly = append(out.RightOnly, e)
+	}
+	return out
+}
+
+// compareInsights pairs the scalar insight metrics a reader compares runs by.
+func compareInsights(l, r *SessionInsight) []MetricDelta {
+	if l == nil || r == nil {
+		return []MetricDelta{}
+	}
+	metrics := []struct {
+		name string
+		get  func(*SessionInsight) float64
+	}{
+		{"turn_count", func(i *SessionInsight) float64 { return float64(i.TurnCount) }},
+		{"tool_calls_total", func(i *SessionInsight) float64 { return float64(i.ToolCallsTotal) }},
+		{"tool_error_count", func(i *SessionInsight) float64 { return float64(i.ToolErrorCount) }},
+		{"tool_error_rate", func(i *SessionInsight) float64 { return i.ToolErrorRate }},
+		{"autonomy_score", func(i *SessionInsight) float64 { return i.AutonomyScore }},
+		{"steps_per_turn_avg", func(i *SessionInsight) float64 { return i.StepsPerTurnAvg }},
+		{"longest_autonomous_chain", func(i *SessionInsight) float64 { return float64(i.LongestAutonomousChain) }},
+		{"cache_hit_rate", func(i *SessionInsight) float64 { return i.CacheHitRate }},
+		{"tokens_per_turn_avg", func(i *SessionInsight) float64 { return i.TokensPerTurnAvg }},
+		{"cost_estimate_usd", func(i *SessionInsight) float64 { return i.CostEstimateUSD }},
+		{"active_duration_ms", func(i *SessionInsight) float64 { return float64(i.ActiveDurationMs) }},
+		{"claude_working_time_ms", func(i *SessionInsight) float64 { return float64(i.ClaudeWorkingTimeMs) }},
+	}
+	out := make([]MetricDelta, 0, len(metrics))
+	for _, m := range metrics {
+		lv, rv := m.get(l), m.get(r)
+		out = append(out, MetricDelta{Name: m.name, Left: lv, Right: rv, Delta: rv - lv})
+	}
+	return out
+}
+
+// ── Tool sequence alignment ─────────────────────────────────────────────────
+
+// diffToolSequences aligns two call sequences. The shared prefix and suffix
+// are matched directly — two runs of one task usually agree at both ends —
+// and only the middle goes through the quadratic LCS table, which is what
+// keeps long sessions within maxToolDiffCells. It reports true when the
+// middle was too large and was listed whole instead.
+func diffToolSequences(a, b []toolCallRef) ([]ToolSequenceEntry, bool) {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre].name == b[pre].name {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf].name == b[len(b)-1-suf].name {
+		suf++
+	}
+
+	out := make([]ToolSequenceEntry, 0, len(a)+len(b))
+	for i := 0; i < pre; i++ {
+		out = append(out, sameCall(a[i], b[i]))
+	}
+	midA, midB := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	coarse := len(midA)*len(midB) > maxToolDiffCells
+	if coarse {
+		for _, c := range midA {
+			out = append(out, ToolSequenceEntry{Op: DiffLeftOnly, Tool: c.name, LeftTurn: c.turn})
+		}
+		for _, c := range midB {
+			out = append(out, ToolSequenceEntry{Op: DiffRightOnly, Tool: c.name, RightTurn: c.turn})
+		}
+	} else {
+		out = append(out, lcsDiff(midA, midB)...)
+	}
+	for i := suf; i > 0; i-- {
+		out = append(out, sameCall(a[len(a)-i], b[len(b)-i]))
+	}
+	return out, coarse
+}
+
+func sameCall(a, b toolCallRef) ToolSequenceEntry {
+	return ToolSequenceEntry{Op: DiffSame, Tool: a.name, LeftTurn: a.turn, RightTurn: b.turn}
+}
+
+// lcsDiff is the textbook longest-common-subsequence diff. lcs[i*w+j] holds
+// the LCS length of a[i:] and b[j:], so the walk from the front can pick, at
+// each mismatch, the side whose skip keeps the longer common tail.
+func lcsDiff(a, b []toolCallRef) []ToolSequenceEntry {
+	n, m := len(a), len(b)
+	w := m + 1
+	lcs := make([]int32, (n+1)*w)
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i].name == b[j].name {
+				lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+			} else {
+				lcs[i*w+j] = max(lcs[(i+1)*w+j], lcs[i*w+j+1])
+			}
+		}
+	}
+
+	out := make([]ToolSequenceEntry, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i].name == b[j].name:
+			out = append(out, sameCall(a[i], b[j]))
+			i++
+			j++
+		case lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+			out = append(out, ToolSequenceEntry{Op: DiffLeftOnly, Tool: a[i].name, LeftTurn: a[i].turn})
+			i++
+		default:
+			out = append(out, ToolSequenceEntry{Op: DiffRightOnly, Tool: b[j].name, RightTurn: b[j].turn})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = append(out, ToolSequenceEntry{Op: DiffLeftOnly, Tool: a[i].name, LeftTurn: a[i].turn})
+	}
+	for ; j < m; j++ {
+		out = append(out, ToolSequenceEntry{Op: DiffRightOnly, Tool: b[j].name, RightTurn: b[j].turn})
+	}
+	return out
+}
@@ -0,0 +1,208 @@
+package claudesessions
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// compareStep builds a journey step with its data marshalled.
+func compareStep(typ string, data any) JourneyStep {
+	raw, _ := json.Marshal(data) //nolint:errcheck
+	return JourneyStep{Type: typ, Data: raw}
+}
+
+func promptStep(content string) JourneyStep {
+	return compareStep("user_input", UserInputData{Content: content})
+}
+
+func callStep(id, tool string) JourneyStep {
+	return compareStep("tool_call", ToolCallData{ToolUseID: id, ToolName: tool})
+}
+
+func resultStep(id, content string, isError bool) JourneyStep {
+	return compareStep("tool_result", ToolResultData{ToolUseID: id, Content: content, IsError: isError})
+}
+
+// journeyOf numbers the turns and fills the totals CompareJourneys reads.
+func journeyOf(id string, turns ...JourneyTurn) *SessionJourney {
+	j := &SessionJourney{SessionID: id, TotalTurns: len(turns), Turns: turns}
+	for i := range j.Turns {
+		j.Turns[i].Number = i + 1
+		if u := j.Turns[i].Usage; u != nil {
+			j.Usage.InputTokens += u.InputTokens
+			j.Usage.OutputTokens += u.OutputTokens
+		}
+	}
+	return j
+}
+
+func TestCompareJourneys_Identical(t *testing.T) {
+	turn := func() JourneyTurn {
+		return JourneyTurn{Steps: []JourneyStep{
+			promptStep("fix the flaky test"),
+			callStep("a", "Read"), resultStep("a", "ok", false),
+			callStep("b", "Bash"), resultStep("b", "PASS", false),
+		}}
+	}
+	cmp := CompareJourneys(journeyOf("l", turn()), journeyOf("r", turn()), nil, nil)
+
+	if cmp.Divergence != nil {
+		t.Errorf("divergence = %+v, want none", cmp.Divergence)
+	}
+	if len(cmp.Turns) != 1 || !cmp.Turns[0].SameTools {
+		t.Errorf("turns = %+v, want one turn with the same tools", cmp.Turns)
+	}
+	for _, e := range cmp.ToolSequence {
+		if e.Op != DiffSame {
+			t.Errorf("tool sequence = %+v, want every call shared", cmp.ToolSequence)
+			break
+		}
+	}
+	if len(cmp.Metrics) != 0 {
+		t.Errorf("metrics = %+v, want none without insights", cmp.Metrics)
+	}
+}
+
+func TestCompareJourneys_ToolDivergence(t *testing.T) {
+	left := journeyOf("l",
+		JourneyTurn{Steps: []JourneyStep{
+			promptStep("fix the flaky test"),
+			callStep("a", "Read"), resultStep("a", "ok", false),
+			callStep("b", "Edit"), resultStep("b", "ok", false),
+			callStep("c", "Bash"), resultStep("c", "PASS", false),
+		}, Usage: &TokenUsage{InputTokens: 100, OutputTokens: 10}, CostUSD: 0.5},
+	)
+	right := journeyOf("r",
+		JourneyTurn{Steps: []JourneyStep{
+			promptStep("fix the flaky test"),
+			callStep("a", "Read"), resultStep("a", "ok", false),
+			callStep("b", "Grep"), resultStep("b", "ok", false),
+			callStep("c", "Edit"), resultStep("c", "ok", false),
+			callStep("d", "Bash"), resultStep("d", "FAIL\nstack", true),
+		}, Usage: &TokenUsage{InputTokens: 150, OutputTokens: 30}, CostUSD: 0.75},
+		JourneyTurn{Steps: []JourneyStep{promptStep("try again")}},
+	)
+
+	cmp := CompareJourneys(left, right, nil, nil)
+
+	d := cmp.Divergence
+	if d == nil || d.Turn != 1 || d.Reason != DivergenceTool || d.Left != "Edit" || d.Right != "Grep" ||
+		d.LeftStep != 3 || d.RightStep != 3 {
+		t.Errorf("divergence = %+v, want Edit vs Grep at step 3 of turn 1", d)
+	}
+
+	var ops []string
+	for _, e := range cmp.ToolSequence {
+		ops = append(ops, string(e.Op)+":"+e.Tool)
+	}
+	want := []string{"same:Read", "right_only:Grep", "same:Edit", "same:Bash"}
+	if len(ops) != len(want) {
+		t.Fatalf("tool sequence = %v, want %v", ops, want)
+	}
+	for i := range want {
+		if ops[i] != want[i] {
+			t.Fatalf("tool sequence = %v, want %v", ops, want)
+		}
+	}
+
+	if len(cmp.Turns) != 2 {
+		t.Fatalf("turns = %d, want 2", len(cmp.Turns))
+	}
+	first := cmp.Turns[0]
+	if first.UsageDelta.InputTokens != 50 || first.UsageDelta.OutputTokens != 20 || first.CostDeltaUSD != 0.25 ||
+		first.ToolCallsDelta != 1 || first.SameTools {
+		t.Errorf("turn 1 = %+v, want +50 in, +20 out, +$0.25, one more call", first)
+	}
+	if second := cmp.Turns[1]; second.Left != nil || second.Right == nil || second.Right.Prompt != "try again" {
+		t.Errorf("turn 2 = %+v, want the right run's alone", second)
+	}
+
+	if len(cmp.Errors.RightOnly) != 1 || cmp.Errors.RightOnly[0] != (ToolError{Turn: 1, Tool: "Bash", Message: "FAIL"}) {
+		t.Errorf("right-only errors = %+v, want the Bash failure", cmp.Errors.RightOnly)
+	}
+	if cmp.Left.CostUSD != 0.5 || cmp.Right.CostUSD != 0.75 || cmp.Right.ToolErrors != 1 {
+		t.Errorf("headline = %+v / %+v", cmp.Left, cmp.Right)
+	}
+	if top := cmp.ToolCounts[0]; top.Tool != "Grep" || top.Delta != 1 {
+		t.Errorf("tool counts = %+v, want Grep first", cmp.ToolCounts)
+	}
+}
+
+func TestCompareJourneys_OutcomeAndLength(t *testing.T) {
+	turn := func(fail bool) JourneyTurn {
+		return JourneyTurn{Steps: []JourneyStep{
+			promptStep("run the tests"),
+			callStep("a", "Bash"), resultStep("a", "exit status 1", fail),
+		}}
+	}
+
+	cmp := CompareJourneys(journeyOf("l", turn(false)), journeyOf("r", turn(true)), nil, nil)
+	if d := cmp.Divergence; d == nil || d.Reason != DivergenceOutcome || d.Right != "Bash failed: exit status 1" {
+		t.Errorf("divergence = %+v, want the Bash outcome", d)
+	}
+
+	cmp = CompareJourneys(journeyOf("l", turn(false), turn(false)), journeyOf("r", turn(false)), nil, nil)
+	if d := cmp.Divergence; d == nil || d.Reason != DivergenceLength || d.Turn != 2 || d.Right != "ended" {
+		t.Errorf("divergence = %+v, want the left run continuing into turn 2", d)
+	}
+}
+
+func TestCompareErrors_Multiset(t *testing.T) {
+	fail := func(id string) []JourneyStep {
+		return []JourneyStep{callStep(id, "Bash"), resultStep(id, "flaky", true)}
+	}
+	left := journeyOf("l", JourneyTurn{Steps: append(fail("a"), fail("b")...)})
+	right := journeyOf("r", JourneyTurn{Steps: fail("a")})
+
+	errs := CompareJourneys(left, right, nil, nil).Errors
+	if errs.Shared != 1 || len(errs.LeftOnly) != 1 || len(errs.RightOnly) != 0 {
+		t.Errorf("errors = %+v, want one shared and one left-only", errs)
+	}
+}
+
+func TestCompareInsights(t *testing.T) {
+	l := &SessionInsight{TurnCount: 3, ToolErrorRate: 0.1, CostEstimateUSD: 1}
+	r := &SessionInsight{TurnCount: 5, ToolErrorRate: 0.3, CostEstimateUSD: 2.5}
+	got := map[string]MetricDelta{}
+	for _, m := range CompareJourneys(journeyOf("l"), journeyOf("r"), l, r).Metrics {
+		got[m.Name] = m
+	}
+	if m := got["turn_count"]; m.Left != 3 || m.Right != 5 || m.Delta != 2 {
+		t.Errorf("turn_count = %+v", m)
+	}
+	if m := got["cost_estimate_usd"]; m.Delta != 1.5 {
+		t.Errorf("cost_estimate_usd = %+v", m)
+	}
+}
+
+func TestDiffToolSequences_CoarseBeyondLimit(t *testing.T) {
+	calls := func(names ...string) []toolCallRef {
+		out := make([]toolCallRef, len(names))
+		for i, n := range names {
+			out[i] = toolCallRef{name: n, turn: 1}
+		}
+		return out
+	}
+	a := calls("Read", "Edit", "Bash", "Write", "Bash")
+	b := calls("Read", "Grep", "Bash", "Glob", "Bash")
+
+	old := maxToolDiffCells
+	maxToolDiffCells = 4
+	defer func() { maxToolDiffCells = old }()
+
+	seq, coarse := diffToolSequences(a, b)
+	if !coarse {
+		t.Fatal("expected the middle to be listed whole")
+	}
+	// Read and the trailing Bash are aligned; the 3x3 middle is not.
+	if len(seq) != 8 || seq[0].Op != DiffSame || seq[7].Op != DiffSame ||
+		seq[1].Op != DiffLeftOnly || seq[4].Op != DiffRightOnly {
+		t.Errorf("sequence = %+v", seq)
+	}
+
+	maxToolDiffCells = old
+	seq, coarse = diffToolSequences(a, b)
+	if coarse || len(seq) != 7 {
+		t.Errorf("sequence = %+v (coarse %v), want Read, Bash, Bash shared and two calls each side", seq, coarse)
+	}
+}
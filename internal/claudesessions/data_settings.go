@@ -116,7 +116,7 @@ func VisibleSessions(sessions []ClaudeSessionSummary) []ClaudeSessionSummary {
 		if IsProjectHidden(s.ProjectPath) {
 			continue
 		}
-		if !config.IsIndexedClaudeDir(s.ConfigDir) {
+		if !isSessionScopeVisible(s.CodingAgent, s.ConfigDir) {
 			continue
 		}
 		visible = append(visible, s)
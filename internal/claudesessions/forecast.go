@@ -0,0 +1,200 @@
+package claudesessions
+
+import (
+	"sort"
+	"time"
+)
+
+// Spend forecast: where the month is heading, not only where it has been.
+//
+// The projection is deliberately simple — month-to-date spend plus a trailing
+// daily run rate carried over the days left — because a figure a reader can
+// check in their head is one they will trust. A fitted trend would react to
+// every busy afternoon and could not say why it moved; a run rate moves when
+// the rate does, and says so.
+//
+// It is computed from the same daily cost series the cost-over-time charts are
+// built from (buildCostOverTime and buildCostOverTimeByModel), so the
+// month-to-date figure and the bars on the chart are the same money.
+
+// forecastBaselineDays is the trailing span the run rate is taken over. Two
+// weeks always holds a full working week and a weekend, so a forecast made on
+// a Monday morning is not read off a quiet Sunday, and is still short enough
+// to follow a change of habit within days.
+const forecastBaselineDays = 14
+
+// SpendForecast projects month-end spend for the month containing the end of
+// the report's window.
+type SpendForecast struct {
+	// Month is the forecast month as YYYY-MM, in the report's timezone.
+	Month string `json:"month"`
+	// MonthToDateUSD is what the month has cost up to the window's end.
+	MonthToDateUSD float64 `json:"month_to_date_usd"`
+	// DailyRateUSD is the trailing run rate the rest of the month is
+	// projected at.
+	DailyRateUSD float64 `json:"daily_rate_usd"`
+	// ProjectedUSD is MonthToDateUSD plus DailyRateUSD over DaysRemaining.
+	ProjectedUSD float64 `json:"projected_usd"`
+	// DaysRemaining is what is left of the month after the window's end. It
+	// is zero for a window ending in a past month, whose projection is then
+	// simply what it cost.
+	DaysRemaining float64 `json:"days_remaining"`
+	// ByModel and ByProject split the same projection, ordered by projected
+	// spend. Lines with no spend in either span are omitted.
+	ByModel   []ForecastLine `json:"by_model"`
+	ByProject []ForecastLine `json:"by_project"`
+}
+
+// ForecastLine is one model's or project's share of a SpendForecast.
+type ForecastLine struct {
+	// Name is the model id or the project path.
+	Name           string  `json:"name"`
+	MonthToDateUSD float64 `json:"month_to_date_usd"`
+	DailyRateUSD   float64 `json:"daily_rate_usd"`
+	ProjectedUSD   float64 `json:"projected_usd"`
+}
+
+// forecaster holds the spans one forecast is taken over, as daily bucket keys.
+type forecaster struct {
+	monthKey      string
+	baselineKey   string
+	baselineDays  float64
+	daysRemaining float64
+}
+
+// forecastSums is one line's spend in the month so far and in the baseline.
+type forecastSums struct {
+	monthToDate float64
+	baseline    float64
+}
+
+func (f forecaster) add(sums *forecastSums, date string, usd float64) {
+	// Keys are YYYY-MM-DD, so they order as strings.
+	if date >= f.monthKey {
+		sums.monthToDate += usd
+	}
+	if date >= f.baselineKey {
+		sums.baseline += usd
+	}
+}
+
+func (f forecaster) line(name string, sums forecastSums) ForecastLine {
+	rate := 0.0
+	if f.baselineDays > 0 {
+		rate = sums.baseline / f.baselineDays
+	}
+	return ForecastLine{
+		Name:           name,
+		MonthToDateUSD: sums.monthToDate,
+		DailyRateUSD:   rate,
+		ProjectedUSD:   sums.monthToDate + rate*f.daysRemaining,
+	}
+}
+
+// buildForecast projects the month containing p.To.
+//
+// It reads every session rather than only the window's: a forecast needs the
+// month from its first day and a full baseline, neither of which a short
+// window contains. The project filter still applies, so a filtered report
+// forecasts that project.
+func buildForecast(sessions []ClaudeSessionSummary, p AnalyticsParams) SpendForecast {
+	loc := p.location()
+	end := p.To.In(loc)
+	monthStart := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, loc)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	// Today counts as the baseline's last, partial day, so the span is
+	// measured to the instant rather than in whole days.
+	baselineStart := bucketStart(end, GranularityDaily, loc).AddDate(0, 0, -(forecastBaselineDays - 1))
+	// A corpus younger than the baseline is rated over the days it has, or a
+	// first week of use would forecast half its real pace.
+	if first, ok := firstActivity(sessions, p.Project); ok && first.After(baselineStart) {
+		baselineStart = bucketStart(first, GranularityDaily, loc)
+	}
+
+	from := monthStart
+	if baselineStart.Before(from) {
+		from = baselineStart
+	}
+	scoped := FilterSessions(sessions, AnalyticsParams{From: from, To: p.To, Project: p.Project})
+
+	f := forecaster{
+		monthKey:      bucketKey(monthStart, GranularityDaily, loc),
+		baselineKey:   bucketKey(baselineStart, GranularityDaily, loc),
+		baselineDays:  end.Sub(baselineStart).Hours() / 24,
+		daysRemaining: max(monthEnd.Sub(end).Hours()/24, 0),
+	}
+
+	var total forecastSums
+	for _, pt := range buildCostOverTime(scoped, from, p.To, GranularityDaily, loc) {
+		f.add(&total, pt.Date, pt.EstimatedCostUSD)
+	}
+
+	byModel := map[string]*forecastSums{}
+	for _, pt := range buildCostOverTimeByModel(scoped, from, p.To, GranularityDaily, loc) {
+		for model, usd := range pt.CostByModel {
+			if byModel[model] == nil {
+				byModel[model] = &forecastSums{}
+			}
+			f.add(byModel[model], pt.Date, usd)
+		}
+	}
+
+	byProjectSessions := map[string][]ClaudeSessionSummary{}
+	for _, s := range scoped {
+		byProjectSessions[s.ProjectPath] = append(byProjectSessions[s.ProjectPath], s)
+	}
+	byProject := make(map[string]*forecastSums, len(byProjectSessions))
+	for project, ss := range byProjectSessions {
+		sums := &forecastSums{}
+		for _, pt := range buildCostOverTime(ss, from, p.To, GranularityDaily, loc) {
+			f.add(sums, pt.Date, pt.EstimatedCostUSD)
+		}
+		byProject[project] = sums
+	}
+
+	overall := f.line("", total)
+	return SpendForecast{
+		Month:          monthStart.Format("2006-01"),
+		MonthToDateUSD: overall.MonthToDateUSD,
+		DailyRateUSD:   overall.DailyRateUSD,
+		ProjectedUSD:   overall.ProjectedUSD,
+		DaysRemaining:  f.daysRemaining,
+		ByModel:        forecastLines(f, byModel),
+		ByProject:      forecastLines(f, byProject),
+	}
+}
+
+// forecastLines turns per-key sums into lines ordered by projected spend.
+func forecastLines(f forecaster, sums map[string]*forecastSums) []ForecastLine {
+	out := make([]ForecastLine, 0, len(sums))
+	for name, s := range sums {
+		if s.monthToDate == 0 && s.baseline == 0 {
+			continue
+		}
+		out = append(out, f.line(name, *s))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ProjectedUSD != out[j].ProjectedUSD {
+			return out[i].ProjectedUSD > out[j].ProjectedUSD
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// firstActivity returns the earliest last-activity among sessions matching
+// project ("" for all).
+func firstActivity(sessions []ClaudeSessionSummary, project string) (time.Time, bool) {
+	var first time.Time
+	found := false
+	for _, s := range sessions {
+		if project != "" && s.ProjectPath != project {
+			continue
+		}
+		if !found || s.LastActivity.Before(first) {
+			first, found = s.LastActivity, true
+		}
+	}
+	return first, found
+}
@@ -0,0 +1,104 @@
+package claudesessions
+
+import (
+	"testing"
+	"time"
+)
+
+// dailySessions builds one session a day at midnight UTC from `from` up to and
+// excluding `to`, each costing usd on model in project.
+func dailySessions(from, to time.Time, model, project string, usd float64) []ClaudeSessionSummary {
+	var out []ClaudeSessionSummary
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		s := costSession(model+d.Format("0102"), model, d, map[string]SessionCost{model: cost(usd, 0, 0, 0)}, nil)
+		s.ProjectPath = project
+		out = append(out, s)
+	}
+	return out
+}
+
+// TestBuildForecast projects month-to-date spend forward at the trailing run
+// rate, and splits the projection by model and by project.
+func TestBuildForecast(t *testing.T) {
+	to := time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC)
+	sessions := dailySessions(time.Date(2026, 7, 20, 0, 0, 0, 0, time.UTC), to, "opus", "/a", 2)
+	burst := costSession("burst", "haiku", time.Date(2026, 8, 10, 0, 0, 0, 0, time.UTC),
+		map[string]SessionCost{"haiku": cost(13, 0, 0, 0)}, nil)
+	burst.ProjectPath = "/b"
+	sessions = append(sessions, burst)
+
+	f := buildForecast(sessions, AnalyticsParams{From: to.AddDate(0, 0, -7), To: to})
+
+	// The baseline is the 13 days Aug 2–14 plus a zero-length Aug 15: $39 over
+	// 13 days is $3/day, carried over the 17 days left in August.
+	if f.Month != "2026-08" {
+		t.Errorf("month = %q, want 2026-08", f.Month)
+	}
+	if f.MonthToDateUSD != 41 {
+		t.Errorf("month to date = %v, want 41 (14 days at $2 plus $13)", f.MonthToDateUSD)
+	}
+	if f.DailyRateUSD != 3 {
+		t.Errorf("daily rate = %v, want 3", f.DailyRateUSD)
+	}
+	if f.DaysRemaining != 17 {
+		t.Errorf("days remaining = %v, want 17", f.DaysRemaining)
+	}
+	if f.ProjectedUSD != 92 {
+		t.Errorf("projected = %v, want 92", f.ProjectedUSD)
+	}
+
+	wantModels := []ForecastLine{
+		{Name: "opus", MonthToDateUSD: 28, DailyRateUSD: 2, ProjectedUSD: 62},
+		{Name: "haiku", MonthToDateUSD: 13, DailyRateUSD: 1, ProjectedUSD: 30},
+	}
+	if len(f.ByModel) != len(wantModels) {
+		t.Fatalf("by model = %+v, want %+v", f.ByModel, wantModels)
+	}
+	for i, want := range wantModels {
+		if f.ByModel[i] != want {
+			t.Errorf("by model[%d] = %+v, want %+v", i, f.ByModel[i], want)
+		}
+	}
+	if len(f.ByProject) != 2 || f.ByProject[0].Name != "/a" || f.ByProject[0].ProjectedUSD != 62 {
+		t.Errorf("by project = %+v, want /a at 62 first", f.ByProject)
+	}
+}
+
+// TestBuildForecast_YoungCorpus rates a corpus younger than the baseline over
+// the days it has, rather than dividing three days of spend by fourteen.
+func TestBuildForecast_YoungCorpus(t *testing.T) {
+	to := time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC)
+	sessions := dailySessions(time.Date(2026, 8, 12, 0, 0, 0, 0, time.UTC), to, "opus", "/a", 3)
+
+	f := buildForecast(sessions, AnalyticsParams{From: to.AddDate(0, 0, -7), To: to})
+	if f.DailyRateUSD != 3 {
+		t.Errorf("daily rate = %v, want 3", f.DailyRateUSD)
+	}
+	if f.ProjectedUSD != 60 {
+		t.Errorf("projected = %v, want 60 ($9 so far plus 17 days at $3)", f.ProjectedUSD)
+	}
+}
+
+// TestForecastCard_OnlyForAMonthInProgress: a finished month's projection is
+// its bill, which the cost charts already show.
+func TestForecastCard_OnlyForAMonthInProgress(t *testing.T) {
+	f := SpendForecast{
+		Month: "2026-08", MonthToDateUSD: 41, ProjectedUSD: 92, DaysRemaining: 17,
+		ByModel: []ForecastLine{{Name: "opus", ProjectedUSD: 62}},
+	}
+	card, ok := cardOf(buildTrendCards(f, nil), CardSpendForecast)
+	if !ok {
+		t.Fatal("expected a forecast card")
+	}
+	if card.AmountUSD != 92 || card.ComparisonUSD != 41 || card.Count != 17 || card.Model != "opus" {
+		t.Errorf("card = %+v", card)
+	}
+	if card.Percent != 67.4 {
+		t.Errorf("top model share = %v%%, want 67.4", card.Percent)
+	}
+
+	f.DaysRemaining = 0
+	if _, ok := cardOf(buildTrendCards(f, nil), CardSpendForecast); ok {
+		t.Error("a finished month should not get a forecast card")
+	}
+}
@@ -0,0 +1,280 @@
+package claudesessions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// geminiSource reads Google Gemini CLI sessions: one JSON document per
+// session, under $HOME/.gemini/tmp/<project hash>/chats/.
+//
+// Gemini CLI names a project's directory after the SHA-256 of its path and
+// does not record the path itself, so a session's project is found by hashing
+// the project paths the scan already knows of. One no hint matches is filed
+// under its hash directory until a Claude Code session in the same project
+// names it.
+type geminiSource struct {
+	home string
+}
+
+// NewGeminiSource reads the sessions under home, a Gemini CLI home directory.
+func NewGeminiSource(home string) TranscriptSource {
+	return &geminiSource{home: home}
+}
+
+// DefaultGeminiHome returns where Gemini CLI keeps its state, ~/.gemini.
+func DefaultGeminiHome() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join("/root", ".gemini")
+	}
+	return filepath.Join(home, ".gemini")
+}
+
+func (s *geminiSource) Agent() string { return CodingAgentGemini }
+
+func (s *geminiSource) root() string { return filepath.Join(s.home, "tmp") }
+
+func (s *geminiSource) Owns(path string) bool {
+	return strings.HasPrefix(path, s.root()+string(filepath.Separator)) && strings.HasSuffix(path, ".json")
+}
+
+// Walk lists every saved chat under tmp/. A home without one has never saved a
+// session and walks clean; a missing home walks nothing.
+func (s *geminiSource) Walk(projects []string, logger *slog.Logger) SourceWalk {
+	root := s.root()
+	if !dirExists(root) {
+		if dirExists(s.home) {
+			return SourceWalk{Roots: []string{root}}
+		}
+		return SourceWalk{}
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		logger.Warn("claude sessions: skipping unreadable gemini dir", "dir", root, "error", err)
+		return SourceWalk{}
+	}
+
+	byHash := make(map[string]string, len(projects))
+	for _, p := range projects {
+		byHash[geminiProjectHash(p)] = p
+	}
+	w := SourceWalk{Roots: []string{root}}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		chats := filepath.Join(root, e.Name(), "chats")
+		project, ok := byHash[e.Name()]
+		if !ok {
+			project = filepath.Join(root, e.Name())
+		}
+		transcripts, err := geminiChats(root, chats, project)
+		if err != nil {
+			logger.Warn("claude sessions: skipping unreadable gemini dir", "dir", chats, "error", err)
+			w.Protected = append(w.Protected, filepath.Join(root, e.Name()))
+			continue
+		}
+		w.Transcripts = append(w.Transcripts, transcripts...)
+	}
+	return w
+}
+
+// geminiChats lists one project's saved chats. A project that never saved one
+// has no chats dir.
+func geminiChats(root, chats, project string) ([]SourceTranscript, error) {
+	entries, err := os.ReadDir(chats)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []SourceTranscript
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, "session-") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		id := strings.TrimSuffix(name, ".json")
+		info, err := e.Info()
+		if err != nil || !validSessionID.MatchString(id) {
+			continue
+		}
+		out = append(out, SourceTranscript{
+			SessionID:   id,
+			ProjectPath: project,
+			Path:        filepath.Join(chats, name),
+			ModTime:     info.ModTime(),
+			Root:        root,
+		})
+	}
+	return out, nil
+}
+
+// geminiProjectHash is the directory name Gemini CLI files a project under.
+func geminiProjectHash(projectPath string) string {
+	sum := sha256.Sum256([]byte(projectPath))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *geminiSource) Open(path string) (io.ReadCloser, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path found under the Gemini tmp dir
+	if err != nil {
+		return nil, err
+	}
+	var chat geminiChat
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return nil, err
+	}
+	out := claudeTranscript{sessionID: strings.TrimSuffix(filepath.Base(path), ".json")}
+	// The project path the walk resolved, when it resolved one.
+	if t, ok := lookupSourceTranscript(out.sessionID); ok {
+		out.cwd = t.ProjectPath
+	}
+	for _, m := range chat.Messages {
+		normalizeGeminiMessage(&out, m)
+	}
+	return out.reader(), nil
+}
+
+// geminiChat is a saved Gemini CLI session.
+type geminiChat struct {
+	SessionID string          `json:"sessionId"`
+	Messages  []geminiMessage `json:"messages"`
+}
+
+type geminiMessage struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Type      string          `json:"type"` // user, gemini, info or error
+	Content   json.RawMessage `json:"content"`
+	Model     string          `json:"model"`
+	Thoughts  []struct {
+		Subject     string `json:"subject"`
+		Description string `json:"description"`
+	} `json:"thoughts"`
+	Tokens    *geminiTokens    `json:"tokens"`
+	ToolCalls []geminiToolCall `json:"toolCalls"`
+}
+
+// geminiTokens is Gemini's usage: input counts the cached tokens, and thoughts
+// and tool-use prompt tokens are reported apart from output and input.
+type geminiTokens struct {
+	Input    int `json:"input"`
+	Output   int `json:"output"`
+	Cached   int `json:"cached"`
+	Thoughts int `json:"thoughts"`
+	Tool     int `json:"tool"`
+}
+
+type geminiToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Args      json.RawMessage `json:"args"`
+	Status    string          `json:"status"`
+	Timestamp time.Time       `json:"timestamp"`
+	Result    []struct {
+		FunctionResponse struct {
+			Response struct {
+				Output string `json:"output"`
+				Error  string `json:"error"`
+			} `json:"response"`
+		} `json:"functionResponse"`
+	} `json:"result"`
+}
+
+// normalizeGeminiMessage writes one message: a prompt, or a response followed
+// by the outputs of the tools it called. Info and error messages are the CLI's
+// own and are dropped.
+func normalizeGeminiMessage(out *claudeTranscript, m geminiMessage) {
+	switch m.Type {
+	case "user":
+		if text := geminiText(m.Content); text != "" {
+			out.prompt(m.Timestamp, text)
+		}
+	case "gemini":
+		var usage *rawUsage
+		if t := m.Tokens; t != nil {
+			usage = promptUsage(t.Input+t.Tool, t.Cached, t.Output+t.Thoughts)
+		}
+		out.reply(m.Timestamp, m.Model, geminiBlocks(m), usage)
+		for _, tc := range m.ToolCalls {
+			at := tc.Timestamp
+			if at.IsZero() {
+				at = m.Timestamp
+			}
+			out.toolResult(at, tc.ID, geminiToolOutput(tc), tc.Status == "error")
+		}
+	}
+}
+
+// geminiBlocks returns a response's thoughts, text and tool calls as content
+// blocks, in the order the model produced them.
+func geminiBlocks(m geminiMessage) []rawContentBlock {
+	var blocks []rawContentBlock
+	for _, th := range m.Thoughts {
+		blocks = append(blocks, rawContentBlock{Type: "thinking", Thinking: geminiThought(th.Subject, th.Description)})
+	}
+	if text := geminiText(m.Content); text != "" {
+		blocks = append(blocks, rawContentBlock{Type: "text", Text: text})
+	}
+	for _, tc := range m.ToolCalls {
+		blocks = append(blocks, rawContentBlock{Type: "tool_use", ID: tc.ID, Name: tc.Name, Input: geminiArgs(tc.Args)})
+	}
+	return blocks
+}
+
+// geminiText reads a message's content: a string, or a list of parts.
+func geminiText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return strings.TrimSpace(s)
+	}
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(raw, &parts) != nil {
+		return ""
+	}
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n"))
+}
+
+func geminiThought(subject, description string) string {
+	if subject == "" {
+		return description
+	}
+	return subject + "\n" + description
+}
+
+func geminiArgs(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("{}")
+	}
+	return toolInput(string(raw))
+}
+
+func geminiToolOutput(tc geminiToolCall) string {
+	texts := make([]string, 0, len(tc.Result))
+	for _, r := range tc.Result {
+		resp := r.FunctionResponse.Response
+		if resp.Error != "" {
+			texts = append(texts, resp.Error)
+		} else if resp.Output != "" {
+			texts = append(texts, resp.Output)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
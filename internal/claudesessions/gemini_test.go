@@ -0,0 +1,103 @@
+package claudesessions
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeGeminiChat saves a Gemini CLI session for project: one prompt, and a
+// reply that thought, read a file and answered.
+func writeGeminiChat(t *testing.T, home, project, name string, at time.Time) string {
+	t.Helper()
+	dir := filepath.Join(home, "tmp", geminiProjectHash(project), "chats")
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	chat := map[string]any{
+		"sessionId": "5f0c2a9e-1111-2222-3333-444455556666",
+		"messages": []map[string]any{
+			{"timestamp": at, "type": "user", "content": "Summarize main.go"},
+			{"timestamp": at, "type": "info", "content": "Switched to fallback model"},
+			{
+				"timestamp": at.Add(4 * time.Second),
+				"type":      "gemini",
+				"model":     "gemini-2.5-pro",
+				"content":   "It starts the server.",
+				"thoughts":  []map[string]any{{"subject": "Reading", "description": "Open main.go first."}},
+				"tokens":    map[string]any{"input": 5000, "output": 200, "cached": 4000, "thoughts": 50, "tool": 0},
+				"toolCalls": []map[string]any{{
+					"id": "read_file-1", "name": "read_file", "status": "error",
+					"args": map[string]any{"absolute_path": project + "/main.go"},
+					"result": []map[string]any{{
+						"functionResponse": map[string]any{"response": map[string]any{"error": "file not found"}},
+					}},
+				}},
+			},
+		},
+	}
+	b, err := json.Marshal(chat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name+".json")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGeminiSource_ResolvesProjectsByHash(t *testing.T) {
+	home := t.TempDir()
+	at := time.Date(2026, 10, 2, 14, 0, 0, 0, time.UTC)
+	path := writeGeminiChat(t, home, "/home/dev/web", "session-2026-10-02T14-00-5f0c2a9e", at)
+	src := NewGeminiSource(home)
+
+	w := src.Walk([]string{"/home/dev/api", "/home/dev/web"}, testLogger)
+	if len(w.Transcripts) != 1 {
+		t.Fatalf("walk found %d transcripts, want 1", len(w.Transcripts))
+	}
+	if tr := w.Transcripts[0]; tr.ProjectPath != "/home/dev/web" || tr.Path != path ||
+		tr.SessionID != "session-2026-10-02T14-00-5f0c2a9e" {
+		t.Errorf("transcript = %+v", tr)
+	}
+
+	// No known project hashes to the dir: the session is still listed, under
+	// the dir itself.
+	w = src.Walk(nil, testLogger)
+	want := filepath.Join(home, "tmp", geminiProjectHash("/home/dev/web"))
+	if len(w.Transcripts) != 1 || w.Transcripts[0].ProjectPath != want {
+		t.Errorf("unresolved walk = %+v, want the session under %s", w.Transcripts, want)
+	}
+}
+
+func TestGeminiSource_NormalizesChat(t *testing.T) {
+	home := t.TempDir()
+	at := time.Date(2026, 10, 2, 14, 0, 0, 0, time.UTC)
+	path := writeGeminiChat(t, home, "/home/dev/web", "session-2026-10-02T14-00-5f0c2a9e", at)
+
+	InstallTranscriptSources(NewGeminiSource(home))
+	t.Cleanup(func() { InstallTranscriptSources() })
+
+	s, _, err := readSessionSummary("session-2026-10-02T14-00-5f0c2a9e", "/home/dev/web", path, testLogger)
+	if err != nil {
+		t.Fatalf("read summary: %v", err)
+	}
+	if s.Preview != "Summarize main.go" || s.Model != "gemini-2.5-pro" {
+		t.Errorf("preview, model = %q, %q", s.Preview, s.Model)
+	}
+	// Cached tokens come out of the input count, and thinking is billed as
+	// output.
+	want := TokenUsage{InputTokens: 1000, CacheReadTokens: 4000, OutputTokens: 250}
+	if s.Usage != want {
+		t.Errorf("usage = %+v, want %+v", s.Usage, want)
+	}
+	if s.MessageCount != 2 {
+		t.Errorf("message count = %d, want the prompt and the reply; info messages are the CLI's", s.MessageCount)
+	}
+	if s.Cost.TotalUSD <= 0 {
+		t.Errorf("cost = %+v, want gemini-2.5-pro priced through the catalog", s.Cost)
+	}
+}
@@ -36,6 +36,15 @@ const (
 	// CardExpensiveSessions is what the priciest handful of sessions cost
 	// together, and how long they ran.
 	CardExpensiveSessions InsightCardKind = "expensive_sessions"
+	// CardSpendForecast is where the month's spend is heading at the current
+	// run rate, and which model most of it is going to.
+	CardSpendForecast InsightCardKind = "spend_forecast"
+	// CardDayAnomaly names the most unusual day in the window: one whose cost
+	// or token usage was far above the days before it.
+	CardDayAnomaly InsightCardKind = "day_anomaly"
+	// CardSessionAnomaly names the most unusual session in the window, judged
+	// against the sessions before it.
+	CardSessionAnomaly InsightCardKind = "session_anomaly"
 )
 
 // InsightCard is one fact. Fields not relevant to a Kind are left zero; the
@@ -62,6 +71,16 @@ type InsightCard struct {
 	// Estimated marks a figure derived from list rates rather than read from a
 	// stored total, so the UI can say "about" and mean it.
 	Estimated bool `json:"estimated,omitempty"`
+	// Metric is what an anomaly card measured: "cost", read from AmountUSD
+	// against ComparisonUSD, or "tokens", read from Tokens.
+	Metric AnomalyMetric `json:"metric,omitempty"`
+	// Ratio is an anomaly's figure as a multiple of its baseline.
+	Ratio float64 `json:"ratio,omitempty"`
+	// Date is the local day a card is about, YYYY-MM-DD — or, for the
+	// forecast, the month, YYYY-MM.
+	Date string `json:"date,omitempty"`
+	// SessionID names the session a card is about, so the UI can link to it.
+	SessionID string `json:"session_id,omitempty"`
 }
 
 // lowCacheShare is the read share below which a model is worth a card.
@@ -280,3 +299,80 @@ func expensiveSessionsCard(sessions []ClaudeSessionSummary) (InsightCard, bool)
 		AvgDurationMs: durationMs / expensiveSessionSample,
 	}, true
 }
+
+// buildTrendCards derives the cards that look past the window's own sessions:
+// the month's forecast and the strongest anomaly of each scope. Count on an
+// anomaly card is how many anomalies of that scope the window holds.
+func buildTrendCards(forecast SpendForecast, anomalies []Anomaly) []InsightCard {
+	cards := make([]InsightCard, 0, 3)
+	if card, ok := forecastCard(forecast); ok {
+		cards = append(cards, card)
+	}
+	for _, scope := range []AnomalyScope{AnomalyScopeDay, AnomalyScopeSession} {
+		if card, ok := anomalyCard(anomalies, scope); ok {
+			cards = append(cards, card)
+		}
+	}
+	return cards
+}
+
+// forecastCard states the month-end projection. Only a month still in
+// progress gets one: a finished month's "projection" is its bill, which the
+// cost charts already show.
+func forecastCard(f SpendForecast) (InsightCard, bool) {
+	if f.DaysRemaining <= 0 || f.ProjectedUSD < minCardCostUSD {
+		return InsightCard{}, false
+	}
+	card := InsightCard{
+		Kind:          CardSpendForecast,
+		AmountUSD:     f.ProjectedUSD,
+		ComparisonUSD: f.MonthToDateUSD,
+		Count:         int(math.Ceil(f.DaysRemaining)),
+		Date:          f.Month,
+		Estimated:     true,
+	}
+	if len(f.ByModel) > 0 && f.ProjectedUSD > 0 {
+		card.Model = f.ByModel[0].Name
+		card.Percent = math.Round(f.ByModel[0].ProjectedUSD/f.ProjectedUSD*1000) / 10
+	}
+	return card, true
+}
+
+// anomalyCard names the strongest anomaly of one scope. anomalies arrive
+// strongest first, so the first match is the one.
+func anomalyCard(anomalies []Anomaly, scope AnomalyScope) (InsightCard, bool) {
+	var top *Anomaly
+	count := 0
+	for i := range anomalies {
+		if anomalies[i].Scope != scope {
+			continue
+		}
+		if top == nil {
+			top = &anomalies[i]
+		}
+		count++
+	}
+	if top == nil || top.Baseline <= 0 {
+		return InsightCard{}, false
+	}
+
+	kind := CardDayAnomaly
+	if scope == AnomalyScopeSession {
+		kind = CardSessionAnomaly
+	}
+	card := InsightCard{
+		Kind:      kind,
+		Metric:    top.Metric,
+		Ratio:     math.Round(top.Value/top.Baseline*10) / 10,
+		Count:     count,
+		Date:      top.Date,
+		SessionID: top.SessionID,
+	}
+	if top.Metric == AnomalyMetricCost {
+		card.AmountUSD = top.Value
+		card.ComparisonUSD = top.Baseline
+	} else {
+		card.Tokens = int(top.Value)
+	}
+	return card, true
+}
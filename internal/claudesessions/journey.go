@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -68,6 +67,10 @@ type JourneyTurn struct {
 	Usage      *TokenUsage   `json:"usage,omitempty"`
 	ToolCalls  int           `json:"tool_calls"`
 	Steps      []JourneyStep `json:"steps"`
+	// CostUSD prices Usage message by message, at each message's own model and
+	// timestamp, the way the session's stored cost is. Zero when pricing is not
+	// wired or the model has no known rate.
+	CostUSD float64 `json:"cost_usd"`
 }
 
 // JourneyStep is one discrete event within a turn.
@@ -195,7 +198,7 @@ func GetSessionJourney(sessionID string, logger *slog.Logger) (*SessionJourney,
 }
 
 func buildJourney(sessionID, filePath string, logger *slog.Logger) (*SessionJourney, error) {
-	f, err := os.Open(filePath) //nolint:gosec
+	f, err := openTranscript(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -214,17 +217,7 @@ func buildJourney(sessionID, filePath string, logger *slog.Logger) (*SessionJour
 	builder.loadSubagents(sessionID, filePath)
 
 	for sc.Scan() {
-		var ev rawJourneyEvent
-		if json.Unmarshal(sc.Bytes(), &ev) != nil {
-			continue
-		}
-		// Skip file-history-snapshot events — they can be very large (full file contents)
-		// and carry no useful journey information. All other unrecognized types are
-		// safely ignored by the switch in processEvent.
-		if ev.Type == "file-history-snapshot" {
-			continue
-		}
-		builder.processEvent(ev, journey)
+		builder.processLine(sc.Bytes(), journey)
 	}
 
 	builder.finalize(journey)
@@ -246,6 +239,7 @@ type journeyBuilder struct {
 	turns         []JourneyTurn
 	turnNumber    int
 	turnUsage     TokenUsage
+	turnCost      *costAccumulator
 	turnToolCalls int
 
 	// subagents indexes the session's delegated sub-agents by the tool_use id
@@ -275,6 +269,23 @@ type subagentEntry struct {
 	matched     bool
 }
 
+// processLine decodes one transcript line and feeds it to the builder. It is
+// the one decoding path for every journey reader: the whole-file pass here and
+// the live tail's line-at-a-time one, which must build the same steps.
+func (b *journeyBuilder) processLine(line []byte, j *SessionJourney) {
+	var ev rawJourneyEvent
+	if json.Unmarshal(line, &ev) != nil {
+		return
+	}
+	// Skip file-history-snapshot events — they can be very large (full file contents)
+	// and carry no useful journey information. All other unrecognized types are
+	// safely ignored by the switch in processEvent.
+	if ev.Type == "file-history-snapshot" {
+		return
+	}
+	b.processEvent(ev, j)
+}
+
 func (b *journeyBuilder) processEvent(ev rawJourneyEvent, j *SessionJourney) {
 	b.tr.update(ev.Timestamp)
 	b.active.observe(ev.Timestamp, ev.Type == "assistant")
@@ -305,6 +316,7 @@ func (b *journeyBuilder) startNewTurn(ts time.Time) {
 		StartTime: ts,
 	}
 	b.turnUsage = TokenUsage{}
+	b.turnCost = newCostAccumulator(defaultPricingResolver())
 	b.turnToolCalls = 0
 }
 
@@ -327,6 +339,7 @@ func (b *journeyBuilder) closeTurn() {
 		u := b.turnUsage
 		b.currentTurn.Usage = &u
 	}
+	b.currentTurn.CostUSD = sessionCostFromPricing(b.turnCost).TotalUSD
 	b.turns = append(b.turns, *b.currentTurn)
 	b.currentTurn = nil
 }
@@ -454,7 +467,7 @@ func (b *journeyBuilder) processAssistantEvent(ev rawJourneyEvent, j *SessionJou
 		j.Model = ev.Message.Model
 	}
 
-	b.accumulateUsage(ev.Message.Usage, j)
+	b.accumulateUsage(ev.Message, ev.Timestamp, j)
 
 	// Parse content blocks
 	var blocks []rawContentBlock
@@ -467,19 +480,27 @@ func (b *journeyBuilder) processAssistantEvent(ev rawJourneyEvent, j *SessionJou
 	}
 }
 
-// accumulateUsage adds message usage to both turn-level and session-level totals.
-func (b *journeyBuilder) accumulateUsage(usage *rawUsage, j *SessionJourney) {
-	if usage == nil {
+// accumulateUsage adds message usage to both turn-level and session-level
+// totals, and prices it into the turn's cost.
+func (b *journeyBuilder) accumulateUsage(msg *rawMessage, ts time.Time, j *SessionJourney) {
+	if msg.Usage == nil {
 		return
 	}
-	b.turnUsage.InputTokens += usage.InputTokens
-	b.turnUsage.OutputTokens += usage.OutputTokens
-	b.turnUsage.CacheCreationTokens += usage.CacheCreationInputTokens
-	b.turnUsage.CacheReadTokens += usage.CacheReadInputTokens
-	j.Usage.InputTokens += usage.InputTokens
-	j.Usage.OutputTokens += usage.OutputTokens
-	j.Usage.CacheCreationTokens += usage.CacheCreationInputTokens
-	j.Usage.CacheReadTokens += usage.CacheReadInputTokens
+	var u TokenUsage
+	addAssistantUsage(&u, msg)
+	addUsage(&b.turnUsage, u)
+	addUsage(&j.Usage, u)
+	b.turnCost.addAssistantMessage(msg.Model, u, ts)
+}
+
+// addUsage adds every field of o into u.
+func addUsage(u *TokenUsage, o TokenUsage) {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CacheCreationTokens += o.CacheCreationTokens
+	u.CacheCreation5mTokens += o.CacheCreation5mTokens
+	u.CacheCreation1hTokens += o.CacheCreation1hTokens
+	u.CacheReadTokens += o.CacheReadTokens
 }
 
 // processContentBlock converts a single assistant content block into a journey step.
@@ -679,7 +700,7 @@ func (b *journeyBuilder) loadSubagents(sessionID, filePath string) {
 // returns its steps (flattened across turns) and total usage. It does not
 // recurse into the sub-agent's own subagents/ — deeper delegation is flattened.
 func (b *journeyBuilder) buildSubagentSteps(e *subagentEntry) ([]JourneyStep, TokenUsage) {
-	f, err := os.Open(e.filePath) //nolint:gosec
+	f, err := openTranscript(e.filePath)
 	if err != nil {
 		return nil, TokenUsage{}
 	}
@@ -698,14 +719,7 @@ func (b *journeyBuilder) buildSubagentSteps(e *subagentEntry) ([]JourneyStep, To
 	sc := bufio.NewScanner(f)
 	sc.Buffer(make([]byte, 4*1024*1024), 4*1024*1024)
 	for sc.Scan() {
-		var ev rawJourneyEvent
-		if json.Unmarshal(sc.Bytes(), &ev) != nil {
-			continue
-		}
-		if ev.Type == "file-history-snapshot" {
-			continue
-		}
-		sb.processEvent(ev, sub)
+		sb.processLine(sc.Bytes(), sub)
 	}
 	sb.finalize(sub)
 
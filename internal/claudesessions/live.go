@@ -0,0 +1,352 @@
+package claudesessions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Real-time ingestion from Claude Code hooks.
+//
+// Without hooks a session reaches the cache only when a scan walks the disk:
+// on the TTL, on a refresh, or when a reader finds the cache stale. Claude
+// Code can instead run a command on each of its lifecycle events, and `agento
+// hook` forwards those to the web server, which re-reads the one transcript
+// the event names and records the session as running or waiting. The scan is
+// still the reconciler — hooks can be missed, uninstalled, or never installed —
+// but with them a session's row is seconds old rather than up to a TTL old.
+
+// Hook event names, as Claude Code sends them in hook_event_name.
+const (
+	HookSessionStart     = "SessionStart"
+	HookUserPromptSubmit = "UserPromptSubmit"
+	HookPreToolUse       = "PreToolUse"
+	HookPostToolUse      = "PostToolUse"
+	HookNotification     = "Notification"
+	HookStop             = "Stop"
+	HookSubagentStop     = "SubagentStop"
+	HookPreCompact       = "PreCompact"
+	HookSessionEnd       = "SessionEnd"
+)
+
+// HookEvent is the part of a Claude Code hook's input Agento uses. Claude Code
+// sends more — a tool's input and response, for one — which `agento hook`
+// drops before forwarding, since the transcript records it all anyway.
+type HookEvent struct {
+	SessionID      string `json:"session_id"`
+	TranscriptPath string `json:"transcript_path"`
+	Cwd            string `json:"cwd,omitempty"`
+	HookEventName  string `json:"hook_event_name"`
+	ToolName       string `json:"tool_name,omitempty"`
+	// Source is why a session started: startup, resume, clear or compact.
+	Source string `json:"source,omitempty"`
+	// Reason is why a session ended: clear, logout, prompt_input_exit or other.
+	Reason string `json:"reason,omitempty"`
+}
+
+// LiveState is what a session Claude Code has open is doing.
+type LiveState string
+
+const (
+	// LiveStateRunning means Claude is working on a turn.
+	LiveStateRunning LiveState = "running"
+	// LiveStateWaiting means the turn is over, or a permission prompt is
+	// open, and the session waits on the user.
+	LiveStateWaiting LiveState = "waiting"
+)
+
+// LiveSession is a session Claude Code has open, as its hooks report it.
+type LiveSession struct {
+	SessionID   string    `json:"session_id"`
+	ProjectPath string    `json:"project_path"`
+	Cwd         string    `json:"cwd,omitempty"`
+	State       LiveState `json:"state"`
+	LastEvent   string    `json:"last_event"`
+	LastTool    string    `json:"last_tool,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// liveSessionTTL is how long a session stays live without an event. SessionEnd
+// does not fire when Claude Code is killed or the machine sleeps, so a session
+// that has gone quiet this long is taken to be closed. An hour is longer than
+// any one tool call runs, and a waiting session the user comes back to after
+// it reappears on the next prompt.
+const liveSessionTTL = time.Hour
+
+// hookIngestDelay is how long a session's events are gathered before its
+// transcript is re-read. A busy turn fires several tool events a second, and
+// re-reading a long transcript for each would cost more than the freshness is
+// worth. The delay is not restarted by later events, so a turn that never
+// pauses is still re-read once a second.
+const hookIngestDelay = time.Second
+
+// ErrInvalidHookEvent is returned for a hook event that names no valid
+// session, or a transcript outside every indexed Claude config dir.
+var ErrInvalidHookEvent = errors.New("invalid hook event")
+
+// hookSessionIDPattern matches the ids Claude Code gives sessions. The id
+// becomes part of a file name, so nothing that could step out of a directory
+// gets through.
+var hookSessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,127}$`)
+
+// liveSessions tracks the sessions hooks report open, and the transcript
+// re-reads their events have scheduled.
+type liveSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*LiveSession
+	// scheduled holds the sessions with a re-read pending, so a burst of
+	// events schedules one.
+	scheduled map[string]struct{}
+	// unannounced holds the sessions whose rows changed since the last
+	// session event was published for them, valued by whether the first of
+	// those changes inserted the row.
+	unannounced map[string]bool
+	// ingest serializes re-reads, so two sessions' batches never interleave
+	// and one session's are applied in order.
+	ingest sync.Mutex
+	// delay is hookIngestDelay, a field so tests need not wait on it.
+	delay time.Duration
+}
+
+func newLiveSessions() *liveSessions {
+	return &liveSessions{
+		sessions:    map[string]*LiveSession{},
+		scheduled:   map[string]struct{}{},
+		unannounced: map[string]bool{},
+		delay:       hookIngestDelay,
+	}
+}
+
+// HandleHookEvent records a Claude Code hook event and schedules a re-read of
+// the transcript it names. It returns ErrInvalidHookEvent, wrapped, for an
+// event that does not name a transcript in an indexed config dir.
+//
+// An event name Claude Code adds later is still a sign the transcript moved,
+// so it schedules the re-read without changing the session's state.
+func (c *Cache) HandleHookEvent(ev HookEvent) error {
+	configDir, filePath, err := hookTranscript(ev)
+	if err != nil {
+		return err
+	}
+	projectPath := DecodeProjectPath(filepath.Base(filepath.Dir(filePath)))
+	c.live.record(ev, projectPath, time.Now().UTC())
+	c.scheduleIngest(ev, configDir, filePath)
+	return nil
+}
+
+// LiveSessions returns the sessions Claude Code has open, most recently
+// active first. Sessions in hidden projects are left out, as everywhere else.
+func (c *Cache) LiveSessions() []LiveSession {
+	return c.live.list(time.Now().UTC())
+}
+
+// hookTranscript checks that ev names a transcript Agento indexes, and returns
+// that transcript's config dir and cleaned path.
+//
+// The path comes from a request body, and the web server reads the file it
+// names, so it must be exactly <config dir>/projects/<project>/<session>.jsonl
+// for a config dir Agento already reads.
+func hookTranscript(ev HookEvent) (configDir, filePath string, err error) {
+	if !hookSessionIDPattern.MatchString(ev.SessionID) {
+		return "", "", fmt.Errorf("%w: session_id %q is not a Claude Code session id",
+			ErrInvalidHookEvent, ev.SessionID)
+	}
+	filePath = filepath.Clean(ev.TranscriptPath)
+	if !filepath.IsAbs(filePath) || filepath.Base(filePath) != ev.SessionID+jsonlExt {
+		return "", "", fmt.Errorf("%w: transcript_path is not the session's transcript", ErrInvalidHookEvent)
+	}
+	projectsDir := filepath.Dir(filepath.Dir(filePath))
+	if filepath.Base(projectsDir) != "projects" {
+		return "", "", fmt.Errorf("%w: transcript_path is not in a projects directory",
+			ErrInvalidHookEvent)
+	}
+	dir := filepath.Dir(projectsDir)
+	for _, home := range ClaudeHomes() {
+		if filepath.Clean(home) == dir {
+			return home, filePath, nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: transcript_path is not under an indexed Claude config dir",
+		ErrInvalidHookEvent)
+}
+
+// record applies one event to the session's live state.
+func (l *liveSessions) record(ev HookEvent, projectPath string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if ev.HookEventName == HookSessionEnd {
+		delete(l.sessions, ev.SessionID)
+		return
+	}
+	state, known := hookEventState(ev.HookEventName)
+	if !known {
+		return
+	}
+	s, ok := l.sessions[ev.SessionID]
+	if !ok {
+		s = &LiveSession{SessionID: ev.SessionID, StartedAt: now}
+		l.sessions[ev.SessionID] = s
+	}
+	s.ProjectPath = projectPath
+	if ev.Cwd != "" {
+		s.Cwd = ev.Cwd
+	}
+	s.State = state
+	s.LastEvent = ev.HookEventName
+	if ev.ToolName != "" {
+		s.LastTool = ev.ToolName
+	}
+	s.UpdatedAt = now
+}
+
+// hookEventState returns the state an event leaves its session in. A session
+// that has started but not been prompted waits on the user like one whose turn
+// is over; a sub-agent stopping leaves its parent still at work.
+func hookEventState(event string) (LiveState, bool) {
+	switch event {
+	case HookUserPromptSubmit, HookPreToolUse, HookPostToolUse, HookSubagentStop, HookPreCompact:
+		return LiveStateRunning, true
+	case HookSessionStart, HookNotification, HookStop:
+		return LiveStateWaiting, true
+	default:
+		return "", false
+	}
+}
+
+// list returns the live sessions, dropping the ones gone quiet for longer than
+// liveSessionTTL.
+func (l *liveSessions) list(now time.Time) []LiveSession {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make([]LiveSession, 0, len(l.sessions))
+	for id, s := range l.sessions {
+		if now.Sub(s.UpdatedAt) > liveSessionTTL {
+			delete(l.sessions, id)
+			continue
+		}
+		if IsProjectHidden(s.ProjectPath) {
+			continue
+		}
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UpdatedAt.After(out[j].UpdatedAt) })
+	return out
+}
+
+// turnEnded reports whether an event closes a unit of work worth publishing a
+// session event for. Insight processing and the event bus's subscribers run
+// once per turn rather than once per tool call.
+func turnEnded(event string) bool {
+	return event == HookStop || event == HookSessionEnd
+}
+
+// scheduleIngest arranges for the event's transcript to be re-read after
+// hookIngestDelay, unless a re-read is already pending. When the event ends a
+// turn, the session event is published after the re-read whether or not that
+// re-read found anything new: the changes may have been picked up by an
+// earlier one, mid-turn.
+func (c *Cache) scheduleIngest(ev HookEvent, configDir, filePath string) {
+	announce := turnEnded(ev.HookEventName)
+
+	c.live.mu.Lock()
+	_, pending := c.live.scheduled[ev.SessionID]
+	c.live.scheduled[ev.SessionID] = struct{}{}
+	c.live.mu.Unlock()
+	if pending && !announce {
+		return
+	}
+
+	time.AfterFunc(c.live.delay, func() {
+		c.live.mu.Lock()
+		delete(c.live.scheduled, ev.SessionID)
+		c.live.mu.Unlock()
+		c.ingestSession(ev.SessionID, configDir, filePath, announce)
+	})
+}
+
+// ingestSession re-reads one session's transcripts and, when announce is set,
+// publishes the session event its changes are owed.
+func (c *Cache) ingestSession(sessionID, configDir, filePath string, announce bool) {
+	c.live.ingest.Lock()
+	defer c.live.ingest.Unlock()
+
+	changed, err := ingestTranscript(c, configDir, filePath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// SessionStart fires before Claude Code writes the transcript.
+	case err != nil:
+		c.logger.Warn("claude sessions: reading hook-reported transcript failed",
+			"session_id", sessionID, "file_path", filePath, "error", err)
+	case changed:
+		c.ingests.Add(1)
+		// Hooks report usage as it happens, so a window nearing its limit is
+		// warned about mid-session rather than at the next scan.
+		c.alertUsageWindows()
+	}
+
+	if !announce {
+		return
+	}
+	c.live.mu.Lock()
+	isNew := c.live.unannounced[sessionID]
+	delete(c.live.unannounced, sessionID)
+	c.live.mu.Unlock()
+	if err == nil {
+		c.notify(sessionID, filePath, isNew)
+	}
+}
+
+// ingestTranscript brings one session's rows up to date from its transcript
+// and its sub-agents' transcripts, without walking the rest of the corpus. It
+// reports whether any row was written.
+//
+// Nothing is deleted: a walk of one session says nothing about the others, so
+// the diff is given an empty walk, which no cached row is reconcilable
+// against. The changes it finds are held for the session's next turn-end
+// event rather than published as they are written.
+func ingestTranscript(c *Cache, configDir, filePath string) (bool, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return false, err
+	}
+	projectDir := filepath.Dir(filePath)
+	sessionID := strings.TrimSuffix(filepath.Base(filePath), jsonlExt)
+	projectPath := DecodeProjectPath(filepath.Base(projectDir))
+	onDisk := map[string]diskFile{filePath: {
+		sessionID:   sessionID,
+		projectPath: projectPath,
+		filePath:    filePath,
+		mtime:       info.ModTime().UTC(),
+		configDir:   configDir,
+	}}
+	collectSubagentDiskFiles(configDir, projectDir, sessionID, projectPath, onDisk)
+
+	cached, err := loadCachedEntries(c.db, c.logger)
+	if err != nil {
+		return false, fmt.Errorf("loading cached entries: %w", err)
+	}
+	diff := diffDiskAndCache(onDisk, cached, diskWalk{})
+	if len(diff.toInsert)+len(diff.toUpdate) == 0 {
+		return false, nil
+	}
+	applyChangesWithNotify(c.db, c.logger, onDisk, diff, c.live.holdNotify, nil)
+	return true, nil
+}
+
+// holdNotify records a session's change for its next turn-end event. A
+// session inserted by one re-read and updated by the next is still announced
+// as discovered.
+func (l *liveSessions) holdNotify(sessionID, _ string, isNew bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.unannounced[sessionID] = l.unannounced[sessionID] || isNew
+}
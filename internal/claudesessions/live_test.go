@@ -0,0 +1,142 @@
+package claudesessions
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/eventbus"
+)
+
+// recordingBus keeps the events published to it.
+type recordingBus struct {
+	mu     sync.Mutex
+	events []eventbus.Event
+}
+
+func (b *recordingBus) Publish(eventType string, payload map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, eventbus.Event{Type: eventType, Payload: payload})
+}
+
+func (b *recordingBus) Subscribe(eventbus.Listener) {}
+
+func (b *recordingBus) Close() {}
+
+func (b *recordingBus) published() []eventbus.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]eventbus.Event(nil), b.events...)
+}
+
+func TestHookTranscript_OnlyAcceptsIndexedTranscripts(t *testing.T) {
+	home := t.TempDir()
+	useConfigDirs(t, home)
+	projects := filepath.Join(home, ".claude", "projects")
+
+	cases := []struct {
+		name string
+		ev   HookEvent
+		ok   bool
+	}{
+		{"indexed", HookEvent{SessionID: "abc-1", TranscriptPath: filepath.Join(projects, "-p", "abc-1.jsonl")}, true},
+		{"other session", HookEvent{SessionID: "abc-1", TranscriptPath: filepath.Join(projects, "-p", "abc-2.jsonl")}, false},
+		{"bad id", HookEvent{SessionID: "../abc", TranscriptPath: filepath.Join(projects, "-p", "../abc.jsonl")}, false},
+		{"relative", HookEvent{SessionID: "abc-1", TranscriptPath: "projects/-p/abc-1.jsonl"}, false},
+		{"escapes", HookEvent{SessionID: "abc-1", TranscriptPath: filepath.Join(projects, "..", "..", "x", "abc-1.jsonl")}, false},
+		{"not indexed", HookEvent{SessionID: "abc-1", TranscriptPath: "/elsewhere/projects/-p/abc-1.jsonl"}, false},
+	}
+	for _, tc := range cases {
+		_, _, err := hookTranscript(tc.ev)
+		if tc.ok && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrInvalidHookEvent) {
+			t.Errorf("%s: error = %v, want ErrInvalidHookEvent", tc.name, err)
+		}
+	}
+}
+
+func TestLiveSessions_FollowHookEvents(t *testing.T) {
+	ApplyDataSettings(0, nil)
+	l := newLiveSessions()
+	start := time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		ev   HookEvent
+		want LiveState
+	}{
+		{HookEvent{HookEventName: HookSessionStart, Cwd: "/work"}, LiveStateWaiting},
+		{HookEvent{HookEventName: HookUserPromptSubmit}, LiveStateRunning},
+		{HookEvent{HookEventName: HookPostToolUse, ToolName: "Bash"}, LiveStateRunning},
+		{HookEvent{HookEventName: "SomethingNew"}, LiveStateRunning},
+		{HookEvent{HookEventName: HookStop}, LiveStateWaiting},
+	}
+	for i, step := range steps {
+		step.ev.SessionID = "sess-live"
+		l.record(step.ev, "/work", start.Add(time.Duration(i)*time.Second))
+		live := l.list(start.Add(time.Duration(i) * time.Second))
+		if len(live) != 1 || live[0].State != step.want {
+			t.Fatalf("after %s: live = %+v, want one %s session", step.ev.HookEventName, live, step.want)
+		}
+	}
+	live := l.list(start.Add(5 * time.Second))
+	if live[0].LastTool != "Bash" || live[0].Cwd != "/work" || !live[0].StartedAt.Equal(start) {
+		t.Errorf("live session = %+v, want the Bash call in /work, started at %v", live[0], start)
+	}
+
+	if got := l.list(start.Add(liveSessionTTL + 5*time.Second)); len(got) != 0 {
+		t.Errorf("live after an hour of silence = %+v, want none", got)
+	}
+
+	l.record(HookEvent{SessionID: "sess-live", HookEventName: HookUserPromptSubmit}, "/work", start)
+	l.record(HookEvent{SessionID: "sess-live", HookEventName: HookSessionEnd}, "/work", start)
+	if got := l.list(start); len(got) != 0 {
+		t.Errorf("live after SessionEnd = %+v, want none", got)
+	}
+}
+
+func TestCache_HookIngestWritesTheSessionWithoutAScan(t *testing.T) {
+	home := t.TempDir()
+	useConfigDirs(t, home)
+	at := time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC)
+	fp := writeSessionIn(t, filepath.Join(home, ".claude"), "-home-dev-work", "sess-hooked", at)
+
+	bus := &recordingBus{}
+	c := newScanCache(t).WithEventBus(bus)
+	// The re-reads are driven by hand below; the one HandleHookEvent
+	// schedules must not race them.
+	c.live.delay = time.Hour
+
+	// Mid-turn events write the row but hold the session event.
+	c.ingestSession("sess-hooked", filepath.Join(home, ".claude"), fp, false)
+	if c.GetSummary("sess-hooked") == nil {
+		t.Fatal("the hook-reported session has no row")
+	}
+	if got := bus.published(); len(got) != 0 {
+		t.Fatalf("published mid-turn = %+v, want nothing", got)
+	}
+
+	// The turn's end announces it, as discovered, though nothing changed since.
+	c.ingestSession("sess-hooked", filepath.Join(home, ".claude"), fp, true)
+	got := bus.published()
+	if len(got) != 1 || got[0].Type != eventbus.EventSessionDiscovered {
+		t.Fatalf("published at turn end = %+v, want one discovery", got)
+	}
+	if c.ingests.Load() != 1 {
+		t.Errorf("ingests = %d, want 1: only the first re-read wrote", c.ingests.Load())
+	}
+
+	if err := c.HandleHookEvent(HookEvent{
+		SessionID: "sess-hooked", TranscriptPath: fp, HookEventName: HookStop,
+	}); err != nil {
+		t.Fatalf("HandleHookEvent: %v", err)
+	}
+	live := c.LiveSessions()
+	if len(live) != 1 || live[0].ProjectPath != DecodeProjectPath("-home-dev-work") {
+		t.Errorf("live = %+v, want sess-hooked in its project", live)
+	}
+}
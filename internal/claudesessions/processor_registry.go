@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"os"
 	"time"
 )
 
@@ -109,7 +108,7 @@ func (r *ProcessorRegistry) newProcessors() []SessionProcessor {
 
 // feedProcessors opens filePath and feeds each decoded event to all processors.
 func (r *ProcessorRegistry) feedProcessors(filePath string, processors []SessionProcessor) error {
-	f, err := os.Open(filePath) //nolint:gosec
+	f, err := openTranscript(filePath)
 	if err != nil {
 		return fmt.Errorf("opening session file %q: %w", filePath, err)
 	}
@@ -60,7 +60,9 @@ func projectsFromDiskFiles(onDisk map[string]diskFile) []ClaudeProject {
 	}
 	byEncoded := map[string]*entry{}
 	for _, df := range onDisk {
-		if df.isSubagent {
+		// Projects are Claude Code's projects/ directories; another agent's
+		// sessions are not filed under one.
+		if df.isSubagent || df.codingAgent != "" {
 			continue
 		}
 		encoded := filepath.Base(filepath.Dir(df.filePath))
@@ -65,7 +65,9 @@ type scanUnit struct {
 type scanResult struct {
 	unit    scanUnit
 	summary *ClaudeSessionSummary
-	meta    subagentMeta // sub-agents only
+	meta    subagentMeta          // sub-agents only
+	entries []transcriptEntry     // sessions only; see transcript_index.go
+	usage   map[int64]usageBucket // see usage_meter.go
 }
 
 // execer is the subset of *sql.DB and *sql.Tx the row writers need, so one
@@ -114,6 +116,41 @@ func applyChangesWithNotify(
 	}
 
 	deleteCachedFiles(db, logger, diff.toDelete)
+	reindexTranscripts(db, logger, staleTranscripts(onDisk, units, pending, diff.toDelete))
+}
+
+// staleTranscripts returns the sessions whose transcript index this scan left
+// stale: a sub-agent transcript changed or was removed, but the parent's own
+// transcript — which the sub-agent's steps are indexed under — was not
+// re-read. Keyed by session id, valued by the parent transcript's path.
+func staleTranscripts(
+	onDisk map[string]diskFile, units []scanUnit, pending map[string]pendingNotify, gone []cachedEntry,
+) map[string]string {
+	reread := map[string]bool{}
+	parents := map[string]string{}
+	for _, df := range onDisk {
+		if !df.isSubagent {
+			parents[df.sessionID] = df.filePath
+		}
+	}
+	for _, u := range units {
+		if !u.df.isSubagent {
+			reread[u.df.sessionID] = true
+		}
+	}
+
+	stale := map[string]string{}
+	for sessionID, p := range pending {
+		if !reread[sessionID] {
+			stale[sessionID] = p.filePath
+		}
+	}
+	for _, ce := range gone {
+		if fp, ok := parents[ce.sessionID]; ok && ce.isSubagent && !reread[ce.sessionID] {
+			stale[ce.sessionID] = fp
+		}
+	}
+	return stale
 }
 
 // readAndWrite runs the reader pool and the single batching writer, returning
@@ -165,14 +202,21 @@ func readAndWrite(
 // it safe to run in parallel.
 func readUnit(u scanUnit, logger *slog.Logger) scanResult {
 	res := scanResult{unit: u}
+	var costs *costAccumulator
 	var err error
 	if u.df.isSubagent {
-		res.summary, _, err = readSubagentSummary(u.df.sessionID, u.df.projectPath, u.df.filePath, logger)
+		res.summary, costs, err = readSubagentSummary(u.df.sessionID, u.df.projectPath, u.df.filePath, logger)
 		if err == nil && res.summary != nil {
 			res.meta = readSubagentMeta(u.df.filePath, logger)
 		}
 	} else {
-		res.summary, _, err = readSessionSummary(u.df.sessionID, u.df.projectPath, u.df.filePath, logger)
+		res.summary, costs, err = readSessionSummary(u.df.sessionID, u.df.projectPath, u.df.filePath, logger)
+		if err == nil && res.summary != nil {
+			res.entries = readTranscriptEntries(u.df.sessionID, u.df.filePath, logger)
+		}
+	}
+	if costs != nil {
+		res.usage = costs.byMinute
 	}
 	if err != nil {
 		// Not fatal: a transcript being appended to right now, or one the user
@@ -264,16 +308,25 @@ func runBatchTx(db *sql.DB, batch []scanResult) (err error) {
 // writeResult persists one decoded transcript inside the batch's transaction.
 func writeResult(ctx context.Context, tx *sql.Tx, res scanResult) error {
 	if res.unit.df.isSubagent {
-		return upsertSubagentRow(ctx, tx, res.unit.df, res.summary, res.meta)
+		if err := upsertSubagentRow(ctx, tx, res.unit.df, res.summary, res.meta); err != nil {
+			return err
+		}
+		return replaceUsageBuckets(ctx, tx, res.unit.df, res.usage)
 	}
-	// The session row and its linked pull requests go together: the row carries
-	// the file's mtime, so a PR write failing after the row committed would
-	// leave the file looking unchanged to the next diff and the PR rows would
-	// never be rebuilt.
+	// The session row, its linked pull requests, its indexed transcript and
+	// its usage buckets go together: the row carries the file's mtime, so a
+	// write failing after the row committed would leave the file looking
+	// unchanged to the next diff and those rows would never be rebuilt.
 	if err := insertCacheRow(ctx, tx, res.unit.df, res.summary); err != nil {
 		return err
 	}
-	return replacePRRows(ctx, tx, res.summary.SessionID, res.summary.PRs)
+	if err := replacePRRows(ctx, tx, res.summary.SessionID, res.summary.PRs); err != nil {
+		return err
+	}
+	if err := replaceTranscriptRows(ctx, tx, res.summary.SessionID, res.entries); err != nil {
+		return err
+	}
+	return replaceUsageBuckets(ctx, tx, res.unit.df, res.usage)
 }
 
 // pendingNotify is one session's queued insight notification for this scan.
@@ -329,19 +382,27 @@ func deleteCachedFiles(db *sql.DB, logger *slog.Logger, gone []cachedEntry) {
 // deleteCachedFileTx removes every cache row belonging to one removed
 // transcript.
 func deleteCachedFileTx(ctx context.Context, tx *sql.Tx, ce cachedEntry) error {
+	// Usage buckets are keyed by the transcript itself, session or sub-agent.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM claude_usage_bucket WHERE file_path = ?`, ce.filePath); err != nil {
+		return err
+	}
 	table := "claude_session_cache"
 	if ce.isSubagent {
 		table = "claude_subagent_cache"
 	} else {
-		// Linked PRs hang off the session row with no foreign key, so they must
-		// be cleared here or they outlive the session forever — and the list's
-		// PR attach reads them back. This runs before the session row is
-		// deleted, because it resolves the session through it.
-		if _, err := tx.ExecContext(ctx,
-			`DELETE FROM claude_session_pr WHERE session_id IN (
+		// Linked PRs and indexed transcript steps hang off the session row with
+		// no foreign key, so they must be cleared here or they outlive the
+		// session forever — and the list's PR attach and the search read them
+		// back. This runs before the session row is deleted, because it
+		// resolves the session through it.
+		for _, child := range []string{"claude_session_pr", "claude_transcript_step"} {
+			// #nosec G202 -- child is a package-internal constant, never user input.
+			if _, err := tx.ExecContext(ctx,
+				`DELETE FROM `+child+` WHERE session_id IN (
 				SELECT session_id FROM claude_session_cache WHERE file_path = ?)`,
-			ce.filePath); err != nil {
-			return err
+				ce.filePath); err != nil {
+				return err
+			}
 		}
 	}
 	// #nosec G202 -- table is a package-internal constant, never user input.
@@ -82,7 +82,15 @@ const (
 	// mtime changes, so without this bump an existing corpus would keep an
 	// empty config_dir indefinitely and filtering the sessions list by account
 	// would match none of it.
-	CurrentScannerVersion = 13
+	// v14: every transcript's prompts, replies, thinking and tool traffic are
+	// indexed into claude_transcript_step for full-text search. Rows written
+	// before v14 have nothing indexed, and unchanged files would never be
+	// re-read to fill it.
+	// v15: every Claude Code transcript's usage is bucketed by minute into
+	// claude_usage_bucket, from which the subscription usage windows are
+	// reconstructed. Like cost_by_model it needs each message's timestamp, so
+	// rows written before v15 have no buckets and must be re-read.
+	CurrentScannerVersion = 15
 )
 
 // rawEvent is the raw JSON structure of a single line in a Claude Code session JSONL file.
@@ -366,6 +374,22 @@ type diskFile struct {
 	// the row so a session can be attributed and filtered by account, and so a
 	// dir that fails to walk can have its rows protected from the delete pass.
 	configDir string
+
+	// archived marks a transcript Claude Code has deleted, put back into the
+	// walk from the transcript archive. It is read from the archived copy.
+	archived bool
+
+	// codingAgent names the TranscriptSource the file was found by. Blank for
+	// Claude Code's own transcripts.
+	codingAgent string
+}
+
+// agent returns the coding agent stored on the file's row.
+func (df diskFile) agent() string {
+	if df.codingAgent == "" {
+		return CodingAgentClaudeCode
+	}
+	return df.codingAgent
 }
 
 // cachedEntry holds a cached file's path and modification time. isSubagent
@@ -457,28 +481,41 @@ func IncrementalScanWith(
 	walk := walkAllDiskFiles(dirs, logger)
 	onDisk := walk.files
 
-	if len(walk.walked) == 0 {
+	if len(walk.walked) == 0 && len(installedSources()) == 0 {
 		// Not one configured dir could be listed. Previously this was the
 		// "wipe the cache" path, on the reasoning that a missing ~/.claude
 		// means the user deleted their sessions. With several dirs that
 		// inference no longer holds — an unplugged drive looks identical —
-		// so leave every row alone and let the next scan reconcile.
+		// so leave every row alone and let the next scan reconcile. With
+		// another agent imported the scan goes on: rowReconcilable already
+		// keeps the rows of every dir that was not walked.
 		logger.Warn("claude sessions: no readable claude config dir, keeping cached rows",
 			"dirs", dirs)
 		updateLastScanned(db, logger)
 		return loadAllSessions(db, logger)
 	}
 
+	// Transcripts Claude Code has deleted are put back from the archive
+	// before the diff, which would otherwise delete their rows.
+	archive, policy := installedArchive()
+	if archive != nil {
+		archive.restoreMissing(context.Background(), walk)
+	}
+
 	cached, err := loadCachedEntries(db, logger)
 	if err != nil {
 		return nil, err
 	}
+	walkSources(&walk, cached, logger)
 
 	stale := detectStaleness(db)
 	stale.invalidate(db, cached, logger)
 
 	diff := diffDiskAndCache(onDisk, cached, walk)
 	applyChangesWithNotify(db, logger, onDisk, diff, opts.Notify, opts.Progress)
+	if archive != nil {
+		archive.sync(context.Background(), walk, policy)
+	}
 
 	// The scan has just walked every project directory, so the project list it
 	// implies is free here and costs 500 ReadDir round trips per request
@@ -1053,9 +1090,9 @@ func insertCacheRow(ctx context.Context, tx *sql.Tx, df diskFile, s *ClaudeSessi
 			compaction_count, dropped_tokens,
 			input_cost_usd, output_cost_usd, cache_read_cost_usd,
 			cache_write_cost_usd, total_cost_usd, unpriced_models, unpriced_tokens,
-			cost_by_model, active_duration_ms, config_dir
+			cost_by_model, active_duration_ms, config_dir, coding_agent
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
-		          ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		          ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(session_id, project_path) DO UPDATE SET
 			file_path = excluded.file_path,
 			file_mtime = excluded.file_mtime,
@@ -1093,7 +1130,8 @@ func insertCacheRow(ctx context.Context, tx *sql.Tx, df diskFile, s *ClaudeSessi
 			unpriced_tokens = excluded.unpriced_tokens,
 			cost_by_model = excluded.cost_by_model,
 			active_duration_ms = excluded.active_duration_ms,
-			config_dir = excluded.config_dir`,
+			config_dir = excluded.config_dir,
+			coding_agent = excluded.coding_agent`,
 		cacheRowArgs(df, s)...,
 	)
 	return err
@@ -1125,7 +1163,7 @@ func cacheRowArgs(df diskFile, s *ClaudeSessionSummary) []any {
 		s.Cost.InputUSD, s.Cost.OutputUSD, s.Cost.CacheReadUSD,
 		s.Cost.CacheWriteUSD, s.Cost.TotalUSD,
 		encodeUnpricedModels(s.UnpricedModels), s.UnpricedTokens,
-		encodeCostByModel(s.CostByModel), s.ActiveDurationMs, df.configDir,
+		encodeCostByModel(s.CostByModel), s.ActiveDurationMs, df.configDir, df.agent(),
 	}
 }
 
@@ -1228,12 +1266,18 @@ type subagentMeta struct {
 	ToolUseID   string `json:"toolUseId"`
 }
 
-// readSubagentMeta reads the sidecar next to a sub-agent transcript. A missing
-// or unreadable sidecar yields a zero value rather than an error — the token
+// readSubagentMeta reads the sidecar next to a sub-agent transcript, or the
+// copy archived with it once Claude Code has deleted both. A missing or
+// unreadable sidecar yields a zero value rather than an error — the token
 // usage in the transcript is the part that matters.
 func readSubagentMeta(filePath string, logger *slog.Logger) subagentMeta {
 	metaPath := strings.TrimSuffix(filePath, jsonlExt) + ".meta.json"
 	data, err := os.ReadFile(metaPath) //nolint:gosec // path derived from a scanned transcript
+	if os.IsNotExist(err) {
+		if archived := archivedMeta(filePath); archived != nil {
+			data, err = archived, nil
+		}
+	}
 	if err != nil {
 		if !os.IsNotExist(err) {
 			logger.Debug("claude sessions: failed to read sub-agent meta", "file", metaPath, "error", err)
@@ -1347,20 +1391,34 @@ func ListSubagents(db *sql.DB, logger *slog.Logger, sessionID string) ([]ClaudeS
 }
 
 // SubagentFiles returns the sub-agent transcript paths belonging to the session
-// whose own transcript is at sessionFilePath. Returns nil when the session
-// delegated nothing.
+// whose own transcript is at sessionFilePath, including those only the
+// transcript archive still holds. Returns nil when the session delegated
+// nothing.
 func SubagentFiles(sessionID, sessionFilePath string) []string {
-	subagentsDir := filepath.Join(filepath.Dir(sessionFilePath), sessionID, "subagents")
-	entries, err := os.ReadDir(subagentsDir)
-	if err != nil {
+	if sourceFor(sessionFilePath) != nil {
+		// Only Claude Code writes sub-agent transcripts of its own.
 		return nil
 	}
-	paths := make([]string, 0, len(entries))
-	for _, e := range entries {
-		if e.IsDir() || !strings.HasSuffix(e.Name(), jsonlExt) {
-			continue
+	subagentsDir := filepath.Join(filepath.Dir(sessionFilePath), sessionID, "subagents")
+	seen := map[string]struct{}{}
+	var paths []string
+	add := func(fp string) {
+		if _, dup := seen[fp]; !dup {
+			seen[fp] = struct{}{}
+			paths = append(paths, fp)
 		}
-		paths = append(paths, filepath.Join(subagentsDir, e.Name()))
+	}
+	// A missing directory is the common case, not an error: the session
+	// delegated nothing, or Claude Code has deleted it and the archive answers.
+	if entries, err := os.ReadDir(subagentsDir); err == nil {
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), jsonlExt) {
+				add(filepath.Join(subagentsDir, e.Name()))
+			}
+		}
+	}
+	for _, fp := range archivedSubagentFiles(sessionID, subagentsDir) {
+		add(fp)
 	}
 	if len(paths) == 0 {
 		return nil
@@ -1394,7 +1452,7 @@ const sessionSummaryColumns = `
 	       c.compaction_count, c.dropped_tokens,
 	       c.input_cost_usd, c.output_cost_usd, c.cache_read_cost_usd,
 	       c.cache_write_cost_usd, c.total_cost_usd, c.unpriced_models, c.unpriced_tokens,
-	       c.cost_by_model, c.active_duration_ms, c.config_dir,
+	       c.cost_by_model, c.active_duration_ms, c.config_dir, c.coding_agent,
 	       COALESCE(sa.n, 0), COALESCE(sa.it, 0), COALESCE(sa.ot, 0),
 	       COALESCE(sa.cct, 0), COALESCE(sa.crt, 0),
 	       COALESCE(sa.c5m, 0), COALESCE(sa.c1h, 0),
@@ -1589,7 +1647,7 @@ func scanSessionSummary(rows *sql.Rows) (ClaudeSessionSummary, error) {
 		&s.CompactionCount, &s.DroppedTokens,
 		&s.Cost.InputUSD, &s.Cost.OutputUSD, &s.Cost.CacheReadUSD,
 		&s.Cost.CacheWriteUSD, &s.Cost.TotalUSD, &unpriced, &s.UnpricedTokens,
-		&costByModel, &s.ActiveDurationMs, &s.ConfigDir,
+		&costByModel, &s.ActiveDurationMs, &s.ConfigDir, &s.CodingAgent,
 		&s.SubagentCount, &s.SubagentUsage.InputTokens, &s.SubagentUsage.OutputTokens,
 		&s.SubagentUsage.CacheCreationTokens, &s.SubagentUsage.CacheReadTokens,
 		&s.SubagentUsage.CacheCreation5mTokens, &s.SubagentUsage.CacheCreation1hTokens,
@@ -1732,7 +1790,7 @@ func readSubagentSummary(
 func readSummaryFile(
 	sessionID, projectPath, filePath string, countSidechainUsers bool, logger *slog.Logger,
 ) (*ClaudeSessionSummary, *costAccumulator, error) {
-	f, err := os.Open(filePath) //nolint:gosec
+	f, err := openTranscript(filePath)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -2048,8 +2106,9 @@ func GetSessionDetail(sessionID string, logger *slog.Logger) (*ClaudeSessionDeta
 }
 
 // findSessionFile locates a session transcript across every configured config
-// dir, returning the dir it was found in, the decoded project path and the
-// file path. Empty strings when the session is not found anywhere.
+// dir, then in the transcript archive, returning the dir it was found in, the
+// decoded project path and the file path. Empty strings when the session is
+// not found anywhere.
 //
 // The config dir is returned rather than discarded because the session's todo
 // list lives beside its transcript, under that same dir's todos/ — resolving it
@@ -2078,7 +2137,11 @@ func findSessionFile(sessionID string) (configDir, projectPath, filePath string)
 			}
 		}
 	}
-	return "", "", ""
+	if configDir, projectPath, filePath = sourceSessionFile(sessionID); filePath != "" {
+		return configDir, projectPath, filePath
+	}
+	// Claude Code may have deleted it; the archive may still hold a copy.
+	return archivedSessionFile(sessionID)
 }
 
 // readSessionDetail reads a session JSONL file and builds the full message
@@ -2087,7 +2150,7 @@ func findSessionFile(sessionID string) (configDir, projectPath, filePath string)
 func readSessionDetail(
 	configDir, sessionID, projectPath, filePath string, logger *slog.Logger,
 ) (*ClaudeSessionDetail, error) {
-	f, err := os.Open(filePath) //nolint:gosec
+	f, err := openTranscript(filePath)
 	if err != nil {
 		return nil, err
 	}
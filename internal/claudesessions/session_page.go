@@ -55,6 +55,9 @@ type SessionFacets struct {
 	// ConfigDirs are the Claude config dirs present in the corpus — the
 	// accounts sessions were run under. Same basis as the dropdowns above.
 	ConfigDirs []string `json:"config_dirs"`
+	// CodingAgents are the coding agents present in the corpus: Claude Code,
+	// and whichever imported agents have sessions.
+	CodingAgents []string `json:"coding_agents"`
 	// HasFavorites and HasPRs gate the toggles that would otherwise filter
 	// nothing. Same basis as the dropdowns, and for the same reason.
 	HasFavorites bool `json:"has_favorites"`
@@ -288,48 +291,51 @@ func loadFacetOptions(db *sql.DB, logger *slog.Logger, f *SessionFacets) error {
 		visible.add("c.project_path != ?", p)
 	}
 	addConfigDirScope(visible)
-	where := visible.where()
 
-	configDirs, err := distinctStrings(db, logger,
-		"SELECT DISTINCT c.config_dir FROM claude_session_cache c"+where+
-			" ORDER BY c.config_dir", visible.args)
-	if err != nil {
-		return err
-	}
-	f.ConfigDirs = configDirs
-
-	models, err := distinctStrings(db, logger,
-		"SELECT DISTINCT c.model FROM claude_session_cache c"+where+" ORDER BY c.model", visible.args)
-	if err != nil {
-		return err
-	}
-	f.Models = models
-
-	modes, err := distinctStrings(db, logger,
-		"SELECT DISTINCT c.permission_mode FROM claude_session_cache c"+where+
-			" ORDER BY c.permission_mode", visible.args)
-	if err != nil {
+	if err := loadFacetDropdowns(db, logger, visible, f); err != nil {
 		return err
 	}
-	f.PermissionModes = modes
 
-	favClause := &clause{}
-	favClause.sql = append(favClause.sql, visible.sql...)
-	favClause.args = append(favClause.args, visible.args...)
+	favClause := visible.clone()
 	favClause.add("c.is_favorite = 1")
 	if err := existsRow(db, "SELECT 1 FROM claude_session_cache c"+favClause.where()+" LIMIT 1",
 		favClause.args, &f.HasFavorites); err != nil {
 		return err
 	}
 
-	prClause := &clause{}
-	prClause.sql = append(prClause.sql, visible.sql...)
-	prClause.args = append(prClause.args, visible.args...)
+	prClause := visible.clone()
 	addLinks(prClause, LinksWith)
 	return existsRow(db, "SELECT 1 FROM claude_session_cache c"+prClause.where()+" LIMIT 1",
 		prClause.args, &f.HasPRs)
 }
 
+// loadFacetDropdowns fills the dropdown options. Config dirs are Claude Code's
+// accounts, so only its sessions offer one.
+func loadFacetDropdowns(db *sql.DB, logger *slog.Logger, visible *clause, f *SessionFacets) error {
+	claudeOnly := visible.clone()
+	claudeOnly.add("c.coding_agent = ?", CodingAgentClaudeCode)
+	for _, opt := range []struct {
+		column string
+		scope  *clause
+		dst    *[]string
+	}{
+		{"c.config_dir", claudeOnly, &f.ConfigDirs},
+		{"c.coding_agent", visible, &f.CodingAgents},
+		{"c.model", visible, &f.Models},
+		{"c.permission_mode", visible, &f.PermissionModes},
+	} {
+		// #nosec G202 -- column is one of the literals above, never input.
+		values, err := distinctStrings(db, logger,
+			"SELECT DISTINCT "+opt.column+" FROM claude_session_cache c"+opt.scope.where()+
+				" ORDER BY "+opt.column, opt.scope.args)
+		if err != nil {
+			return err
+		}
+		*opt.dst = values
+	}
+	return nil
+}
+
 func distinctStrings(db *sql.DB, logger *slog.Logger, query string, args []any) ([]string, error) {
 	rows, err := db.QueryContext(context.Background(), query, args...)
 	if err != nil {
@@ -2,6 +2,7 @@ package claudesessions
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
@@ -134,8 +135,12 @@ type SessionQuery struct {
 	// ConfigDir narrows to one Claude config dir — the account a session was
 	// run under. Empty = every indexed dir.
 	ConfigDir string
+	// CodingAgent narrows to the sessions one coding agent wrote, by its
+	// CodingAgent name. Empty = all.
+	CodingAgent string
 	// Search matches the session ID, the resolved titles, the preview or the
-	// project path, case-insensitively, as a substring.
+	// project path, case-insensitively, as a substring — or any word of the
+	// transcript content, through the full-text index.
 	Search string
 	// FavoritesOnly keeps only starred sessions.
 	FavoritesOnly bool
@@ -211,6 +216,11 @@ func (c *clause) add(sql string, args ...any) {
 	c.args = append(c.args, args...)
 }
 
+// clone returns a copy to narrow further without touching the original.
+func (c *clause) clone() *clause {
+	return &clause{sql: slices.Clone(c.sql), args: slices.Clone(c.args)}
+}
+
 // where renders the accumulated predicate, or an empty string when nothing was
 // added. Always AND: every filter narrows.
 func (c *clause) where() string {
@@ -240,6 +250,9 @@ func buildFilter(q SessionQuery) (*clause, error) {
 	if q.ConfigDir != "" {
 		c.add("c.config_dir = ?", q.ConfigDir)
 	}
+	if q.CodingAgent != "" {
+		c.add("c.coding_agent = ?", q.CodingAgent)
+	}
 	if q.FavoritesOnly {
 		c.add("c.is_favorite = 1")
 	}
@@ -272,28 +285,42 @@ func buildFilter(q SessionQuery) (*clause, error) {
 // cached and correct, so re-adding the dir is immediate and costs no re-read.
 // The empty string is always admitted, since rows written before the column
 // existed carry it and belong to the default dir.
+//
+// Config dirs are Claude Code's. Another agent's sessions are scoped by the
+// agent instead, and hidden the same way once it is no longer imported.
 func addConfigDirScope(c *clause) {
 	dirs := ClaudeHomes()
 	if len(dirs) == 0 {
 		return
 	}
-	placeholders := make([]string, 0, len(dirs))
-	args := make([]any, 0, len(dirs))
+	args := make([]any, 0, len(dirs)+1)
+	args = append(args, CodingAgentClaudeCode)
 	for _, d := range dirs {
-		placeholders = append(placeholders, "?")
 		args = append(args, d)
 	}
-	c.add("(c.config_dir = '' OR c.config_dir IN ("+strings.Join(placeholders, ", ")+"))", args...)
+	scope := "(c.coding_agent = ? AND (c.config_dir = '' OR c.config_dir IN (" + placeholders(len(dirs)) + ")))"
+	if agents := ImportedAgents(); len(agents) > 0 {
+		scope += " OR c.coding_agent IN (" + placeholders(len(agents)) + ")"
+		for _, a := range agents {
+			args = append(args, a)
+		}
+	}
+	c.add("("+scope+")", args...)
+}
+
+// placeholders returns n comma-separated bind markers.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
 }
 
-// addSearch matches the same four fields the client-side predicate did.
+// addSearch matches the session ID, titles, preview and project path as a
+// substring, or the transcript content through the FTS index.
 //
-// A plain indexed-free LIKE rather than an FTS5 index: the corpus this serves
-// is thousands of rows, not millions, and scanning five short columns over
-// 5,000 rows is well under a millisecond. FTS5 would buy that back at the cost
-// of a shadow table to keep in step with every upsert and delete in the
-// scanner — a synchronization burden with a real failure mode (a stale index
-// silently hides rows) for a saving no user could perceive.
+// The fields keep their substring LIKE: a half-typed UUID or path fragment is
+// not a word the tokenizer would match, and scanning six short columns over a
+// few thousand rows is well under a millisecond. The transcript is the part
+// too large to scan that way, so it goes through claude_transcript_fts, which
+// the scanner keeps in step with the cache rows (see transcript_index.go).
 //
 // LOWER on both sides rather than COLLATE NOCASE, because NOCASE is ASCII-only
 // in SQLite and project paths and titles are not.
@@ -305,13 +332,22 @@ func addSearch(c *clause, search string) {
 	// The pattern is bound, not interpolated, so % and _ typed by the user are
 	// matched literally via ESCAPE rather than acting as wildcards.
 	pattern := "%" + escapeLike(q) + "%"
-	c.add(`(LOWER(c.session_id) LIKE ? ESCAPE '\'
+	fields := `LOWER(c.session_id) LIKE ? ESCAPE '\'
     OR LOWER(c.preview) LIKE ? ESCAPE '\'
     OR LOWER(c.custom_title) LIKE ? ESCAPE '\'
     OR LOWER(c.native_title) LIKE ? ESCAPE '\'
     OR LOWER(c.ai_title) LIKE ? ESCAPE '\'
-    OR LOWER(c.project_path) LIKE ? ESCAPE '\')`,
-		pattern, pattern, pattern, pattern, pattern, pattern)
+    OR LOWER(c.project_path) LIKE ? ESCAPE '\'`
+	args := []any{pattern, pattern, pattern, pattern, pattern, pattern}
+	if match := ftsQuery(search); match != "" {
+		fields += `
+    OR c.session_id IN (
+        SELECT t.session_id FROM claude_transcript_step t
+        JOIN claude_transcript_fts f ON f.rowid = t.id
+        WHERE claude_transcript_fts MATCH ?)`
+		args = append(args, match)
+	}
+	c.add("("+fields+")", args...)
 }
 
 // escapeLike neutralizes LIKE's wildcards so a search for "100%" does not match
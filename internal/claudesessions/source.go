@@ -0,0 +1,336 @@
+package claudesessions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+// The coding agents a cached session can have been read from. Every row
+// written before other agents were indexed is Claude Code's.
+const (
+	CodingAgentClaudeCode = "claude-code"
+	CodingAgentCodex      = config.ImportedAgentCodex
+	CodingAgentGemini     = config.ImportedAgentGemini
+	CodingAgentAider      = config.ImportedAgentAider
+)
+
+// TranscriptSource is a coding agent other than Claude Code whose session logs
+// the scanner indexes alongside Claude Code's.
+//
+// A source does two things: it finds its sessions, and it reads one back as
+// Claude Code JSONL — the one shape the summary, journey, insight, detail and
+// search readers decode. It is plugged in at openTranscript, the single point
+// every one of those readers opens a transcript through, so another agent's
+// sessions reach every list and total without a second copy of any reader,
+// and are priced per message through the same catalog.
+type TranscriptSource interface {
+	// Agent is the name stored on the source's rows, one of the CodingAgent
+	// constants.
+	Agent() string
+	// Walk lists the source's sessions. projects are the project paths the
+	// scan knows of, for an agent that keeps its logs inside the project or
+	// names them after it.
+	Walk(projects []string, logger *slog.Logger) SourceWalk
+	// Owns reports whether path is one of this source's transcripts. It must
+	// not touch the disk: it is asked on every transcript open.
+	Owns(path string) bool
+	// Open returns the transcript at path as Claude Code JSONL.
+	Open(path string) (io.ReadCloser, error)
+}
+
+// SourceWalk is what a TranscriptSource found.
+type SourceWalk struct {
+	Transcripts []SourceTranscript
+	// Roots are the directories listed end to end. A cached session under any
+	// other root keeps its row when its transcript is not found, as a Claude
+	// config dir's sessions do when the dir cannot be read.
+	Roots []string
+	// Protected are directories under a root that could not be listed.
+	Protected []string
+}
+
+// SourceTranscript is one session a TranscriptSource found.
+type SourceTranscript struct {
+	SessionID   string
+	ProjectPath string
+	// Path is what Open is given. It need not name a file: an agent that keeps
+	// several sessions in one file addresses each with a suffix.
+	Path    string
+	ModTime time.Time
+	// Root is the directory the transcript was listed under, stored as the
+	// row's config dir.
+	Root string
+}
+
+// transcriptSources holds the installed sources, and where the last walk
+// found each of their sessions. Process-wide for the same reason dataSettings
+// is.
+var transcriptSources = struct {
+	sync.RWMutex
+	sources []TranscriptSource
+	// found maps a session id to its transcript. A Claude Code transcript is
+	// named after its session; another agent's are named by date or hash, so
+	// findSessionFile asks the last walk instead.
+	found  map[string]SourceTranscript
+	walked bool
+}{}
+
+// ApplyImportedAgents installs a source for each of the user's imported
+// agents, read from where that agent keeps its logs by default. Unknown names
+// are skipped; SettingsManager.Update rejects them before they get here.
+func ApplyImportedAgents(agents []string) {
+	var sources []TranscriptSource
+	for _, a := range agents {
+		switch a {
+		case CodingAgentCodex:
+			sources = append(sources, NewCodexSource(DefaultCodexHome()))
+		case CodingAgentGemini:
+			sources = append(sources, NewGeminiSource(DefaultGeminiHome()))
+		case CodingAgentAider:
+			sources = append(sources, NewAiderSource())
+		}
+	}
+	InstallTranscriptSources(sources...)
+}
+
+// InstallTranscriptSources replaces the installed sources. The next scan
+// indexes what they find; sessions of an agent no longer installed keep their
+// rows but are hidden, as a removed config dir's are.
+func InstallTranscriptSources(sources ...TranscriptSource) {
+	transcriptSources.Lock()
+	defer transcriptSources.Unlock()
+	transcriptSources.sources = sources
+	transcriptSources.found = nil
+	transcriptSources.walked = false
+}
+
+func installedSources() []TranscriptSource {
+	transcriptSources.RLock()
+	defer transcriptSources.RUnlock()
+	return transcriptSources.sources
+}
+
+// ImportedAgents returns the agents whose sources are installed.
+func ImportedAgents() []string {
+	sources := installedSources()
+	agents := make([]string, 0, len(sources))
+	for _, s := range sources {
+		agents = append(agents, s.Agent())
+	}
+	return agents
+}
+
+// isSessionScopeVisible reports whether a session read from the given agent and
+// config dir is within the indexed set: Claude Code's when its dir is indexed,
+// another agent's while that agent is imported.
+func isSessionScopeVisible(agent, configDir string) bool {
+	if agent == "" || agent == CodingAgentClaudeCode {
+		return config.IsIndexedClaudeDir(configDir)
+	}
+	for _, s := range installedSources() {
+		if s.Agent() == agent {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceFor returns the installed source that owns path, or nil for a Claude
+// Code transcript.
+func sourceFor(path string) TranscriptSource {
+	for _, s := range installedSources() {
+		if s.Owns(path) {
+			return s
+		}
+	}
+	return nil
+}
+
+// walkSources adds every installed source's sessions to the walk.
+//
+// A session keeps the project it was first indexed under. A source that names
+// a project from the scan's hints can resolve it differently once a new hint
+// appears, and the cache row is keyed on the project path: a moved key would
+// leave the old row behind under the same transcript.
+func walkSources(walk *diskWalk, cached map[string]cachedEntry, logger *slog.Logger) {
+	sources := installedSources()
+	if len(sources) == 0 {
+		return
+	}
+	projects := knownProjects(walk.files, cached)
+	found := make(map[string]SourceTranscript)
+	for _, src := range sources {
+		sw := src.Walk(projects, logger)
+		for _, t := range sw.Transcripts {
+			if ce, ok := cached[t.Path]; ok && ce.projectPath != "" {
+				t.ProjectPath = ce.projectPath
+			}
+			walk.files[t.Path] = diskFile{
+				sessionID:   t.SessionID,
+				projectPath: t.ProjectPath,
+				filePath:    t.Path,
+				mtime:       t.ModTime.UTC(),
+				configDir:   t.Root,
+				codingAgent: src.Agent(),
+			}
+			found[t.SessionID] = t
+		}
+		for _, root := range sw.Roots {
+			walk.walked[root] = struct{}{}
+		}
+		walk.protected = append(walk.protected, sw.Protected...)
+	}
+
+	transcriptSources.Lock()
+	defer transcriptSources.Unlock()
+	transcriptSources.found = found
+	transcriptSources.walked = true
+}
+
+// knownProjects returns the distinct project paths of the walk and the cache.
+func knownProjects(onDisk map[string]diskFile, cached map[string]cachedEntry) []string {
+	seen := map[string]struct{}{}
+	for _, df := range onDisk {
+		seen[df.projectPath] = struct{}{}
+	}
+	for _, ce := range cached {
+		if !ce.isSubagent {
+			seen[ce.projectPath] = struct{}{}
+		}
+	}
+	delete(seen, "")
+	projects := make([]string, 0, len(seen))
+	for p := range seen {
+		projects = append(projects, p)
+	}
+	sort.Strings(projects)
+	return projects
+}
+
+// sourceSessionFile finds another agent's session where the last walk did.
+// Before the process has walked at all it walks once itself, so a session
+// page opened on a cold start does not 404 until the first scan finishes.
+func sourceSessionFile(sessionID string) (configDir, projectPath, filePath string) {
+	transcriptSources.RLock()
+	walked := transcriptSources.walked
+	transcriptSources.RUnlock()
+
+	t, ok := lookupSourceTranscript(sessionID)
+	if !ok && !walked && len(installedSources()) > 0 {
+		w := diskWalk{files: map[string]diskFile{}, walked: map[string]struct{}{}}
+		walkSources(&w, nil, slog.New(slog.DiscardHandler))
+		t, ok = lookupSourceTranscript(sessionID)
+	}
+	if !ok {
+		return "", "", ""
+	}
+	return t.Root, t.ProjectPath, t.Path
+}
+
+// lookupSourceTranscript returns where the last walk found a session.
+func lookupSourceTranscript(sessionID string) (SourceTranscript, bool) {
+	transcriptSources.RLock()
+	defer transcriptSources.RUnlock()
+	t, ok := transcriptSources.found[sessionID]
+	return t, ok
+}
+
+// claudeTranscript assembles Claude Code JSONL from another agent's log, one
+// event at a time, in exactly the shape the readers decode.
+type claudeTranscript struct {
+	buf       bytes.Buffer
+	sessionID string
+	cwd       string
+	gitBranch string
+	seq       int
+}
+
+// claudeLine is one written event: the subset of rawEvent every reader needs.
+type claudeLine struct {
+	Type      string      `json:"type"`
+	UUID      string      `json:"uuid"`
+	SessionID string      `json:"sessionId"`
+	Timestamp time.Time   `json:"timestamp"`
+	CWD       string      `json:"cwd,omitempty"`
+	GitBranch string      `json:"gitBranch,omitempty"`
+	Message   *rawMessage `json:"message"`
+}
+
+func (t *claudeTranscript) write(eventType string, ts time.Time, msg *rawMessage) {
+	t.seq++
+	line, err := json.Marshal(claudeLine{
+		Type:      eventType,
+		UUID:      fmt.Sprintf("%s-%d", t.sessionID, t.seq),
+		SessionID: t.sessionID,
+		Timestamp: ts,
+		CWD:       t.cwd,
+		GitBranch: t.gitBranch,
+		Message:   msg,
+	})
+	if err != nil {
+		return
+	}
+	t.buf.Write(line)
+	t.buf.WriteByte('\n')
+}
+
+// prompt writes a message the user typed.
+func (t *claudeTranscript) prompt(ts time.Time, text string) {
+	content, _ := json.Marshal(text) //nolint:errcheck
+	t.write("user", ts, &rawMessage{Role: "user", Content: content})
+}
+
+// toolResult writes a tool's output, in the user-role carrier Claude Code
+// returns it to the model in.
+func (t *claudeTranscript) toolResult(ts time.Time, toolUseID, output string, isError bool) {
+	content, _ := json.Marshal([]rawToolResultBlock{{ //nolint:errcheck
+		Type: "tool_result", ToolUseID: toolUseID, Content: output, IsError: isError,
+	}})
+	t.write("user", ts, &rawMessage{Role: "user", Content: content})
+}
+
+// reply writes one model response. usage may be nil when the agent did not
+// report it for this response.
+func (t *claudeTranscript) reply(ts time.Time, model string, blocks []rawContentBlock, usage *rawUsage) {
+	if blocks == nil {
+		blocks = []rawContentBlock{}
+	}
+	content, _ := json.Marshal(blocks) //nolint:errcheck
+	t.write("assistant", ts, &rawMessage{Role: "assistant", Model: model, Content: content, Usage: usage})
+}
+
+// reader returns what has been written.
+func (t *claudeTranscript) reader() io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(t.buf.Bytes()))
+}
+
+// promptUsage builds a message's usage from a provider that counts cached
+// prompt tokens inside its input total, as OpenAI and Gemini both do. Claude
+// Code's input_tokens excludes them, and the cost accumulator prices the two
+// at different rates.
+func promptUsage(input, cached, output int) *rawUsage {
+	return &rawUsage{
+		InputTokens:          max(input-cached, 0),
+		CacheReadInputTokens: cached,
+		OutputTokens:         output,
+	}
+}
+
+// toolInput returns a tool call's arguments as the tool_use block's input. An
+// agent that passes them as a JSON string has them decoded in place; anything
+// else is wrapped so the block still carries valid JSON.
+func toolInput(args string) json.RawMessage {
+	if json.Valid([]byte(args)) && len(args) > 0 && args[0] == '{' {
+		return json.RawMessage(args)
+	}
+	wrapped, _ := json.Marshal(map[string]string{"input": args}) //nolint:errcheck
+	return wrapped
+}
@@ -0,0 +1,72 @@
+package claudesessions
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIncrementalScan_IndexesImportedAgents(t *testing.T) {
+	home := t.TempDir()
+	codexHome := t.TempDir()
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	writeSessionIn(t, filepath.Join(home, ".claude"), "-home-dev-work", "work-1", at)
+	writeCodexRollout(t, codexHome, "0199a1b2-c3d4-7e5f", "/home/dev/api", at.Add(time.Hour))
+	useConfigDirs(t, home)
+
+	InstallTranscriptSources(NewCodexSource(codexHome))
+	t.Cleanup(func() { InstallTranscriptSources() })
+
+	c := newScanCache(t)
+	if _, err := IncrementalScan(c.db, testLogger); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+
+	page, err := listSessionPage(c.db, testLogger, SessionQuery{CodingAgent: CodingAgentCodex})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page.Items) != 1 {
+		t.Fatalf("codex filter listed %v, want the one rollout", ids(page))
+	}
+	s := page.Items[0]
+	if s.SessionID != "0199a1b2-c3d4-7e5f" || s.CodingAgent != CodingAgentCodex || s.ProjectPath != "/home/dev/api" {
+		t.Errorf("codex row = %+v", s)
+	}
+	if s.Cost.TotalUSD <= 0 {
+		t.Errorf("codex row cost = %+v, want it priced", s.Cost)
+	}
+
+	facets, err := sessionFacets(c.db, testLogger, SessionQuery{})
+	if err != nil {
+		t.Fatalf("facets: %v", err)
+	}
+	if facets.Total != 2 || !slices.Equal(facets.CodingAgents, []string{CodingAgentClaudeCode, CodingAgentCodex}) {
+		t.Errorf("facets total %d, agents %v; want both agents' sessions", facets.Total, facets.CodingAgents)
+	}
+
+	// The session page finds the rollout by id, though it is not named after it.
+	if _, _, fp := findSessionFile("0199a1b2-c3d4-7e5f"); fp == "" {
+		t.Error("findSessionFile did not find the codex session")
+	}
+
+	// No longer imported: hidden, as a removed config dir's sessions are, and
+	// kept for when it is imported again.
+	InstallTranscriptSources()
+	if _, err := IncrementalScan(c.db, testLogger); err != nil {
+		t.Fatalf("rescan: %v", err)
+	}
+	page, err = listSessionPage(c.db, testLogger, SessionQuery{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].SessionID != "work-1" {
+		t.Errorf("after removing codex the list is %v, want only work-1", ids(page))
+	}
+	var rows int
+	if err := c.db.QueryRow(`SELECT COUNT(*) FROM claude_session_cache WHERE coding_agent = 'codex'`).
+		Scan(&rows); err != nil || rows != 1 {
+		t.Errorf("codex rows = %d (%v), want the row kept", rows, err)
+	}
+}
@@ -0,0 +1,438 @@
+package claudesessions
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ── Live tail ───────────────────────────────────────────────────────────────
+//
+// A journey is built from a finished transcript in one pass. A tail follows a
+// transcript that is still being written: it feeds each line through the same
+// journeyBuilder as it is appended, and reports what the line added — new
+// steps, the session's usage and running cost — so a long autonomous run can
+// be watched as it happens rather than reloaded.
+
+// Tail event types, also the SSE event names the tail endpoint emits.
+const (
+	// TailEventStep carries a TailStep.
+	TailEventStep = "step"
+	// TailEventSubagent carries a TailSubagent.
+	TailEventSubagent = "subagent"
+	// TailEventUsage carries a TailUsage.
+	TailEventUsage = "usage"
+	// TailEventReady is sent once, when the transcript as it stood when the
+	// tail opened has been replayed. Everything after it is live.
+	TailEventReady = "ready"
+)
+
+// tailPollInterval is how often a tail without inotify re-reads its files.
+const tailPollInterval = time.Second
+
+// TailEvent is one update from a SessionTail.
+type TailEvent struct {
+	Type string
+	Data any
+}
+
+// TailStep is a journey step appended since the last update. AgentID is set
+// for a step of a sub-agent's transcript; it nests under the step the agent's
+// TailSubagent named, exactly as the journey nests sub-agent steps.
+type TailStep struct {
+	Turn    int         `json:"turn"`
+	AgentID string      `json:"agent_id,omitempty"`
+	Step    JourneyStep `json:"step"`
+}
+
+// TailSubagent announces a sub-agent transcript as it appears. ToolUseID is
+// set when the tool_call that spawned it has already been streamed, and the
+// agent's steps nest under that step. Otherwise they nest under a sub_agent
+// step appended to Turn — the journey's rule for an unmatched sub-agent.
+type TailSubagent struct {
+	Turn        int    `json:"turn"`
+	AgentID     string `json:"agent_id"`
+	AgentType   string `json:"agent_type,omitempty"`
+	Description string `json:"description,omitempty"`
+	ToolUseID   string `json:"tool_use_id,omitempty"`
+}
+
+// TailUsage is the session's running totals after an update, in the
+// journey's own terms: Usage is the main thread's, SubagentUsage every
+// sub-agent's. CostUSD is both, priced message by message.
+type TailUsage struct {
+	Model          string     `json:"model,omitempty"`
+	CWD            string     `json:"cwd,omitempty"`
+	GitBranch      string     `json:"git_branch,omitempty"`
+	Summary        string     `json:"summary,omitempty"`
+	StartTime      time.Time  `json:"start_time"`
+	EndTime        time.Time  `json:"end_time"`
+	ActiveDuration int64      `json:"active_duration_ms"`
+	TotalTurns     int        `json:"total_turns"`
+	Usage          TokenUsage `json:"usage"`
+	SubagentUsage  TokenUsage `json:"subagent_usage"`
+	SubagentCount  int        `json:"subagent_count"`
+	CostUSD        float64    `json:"cost_usd"`
+}
+
+// SessionTail follows one session's transcript and its sub-agents' as Claude
+// Code appends to them. It is not safe for concurrent use: one tail serves one
+// stream.
+type SessionTail struct {
+	sessionID    string
+	subagentsDir string
+	logger       *slog.Logger
+
+	main *tailedTranscript
+	// subagents holds every sub-agent transcript seen so far, by file path.
+	subagents map[string]*tailedTranscript
+	// toolTurns maps each streamed tool_call's tool_use id to its turn, so a
+	// sub-agent appearing later can be nested under the call that spawned it.
+	toolTurns map[string]int
+}
+
+// tailedTranscript is one transcript being followed: how far it has been
+// read, the builder its lines have fed, and how many of the builder's steps
+// have been reported.
+type tailedTranscript struct {
+	path    string
+	offset  int64
+	builder journeyBuilder
+	journey SessionJourney
+
+	// agentID and turn are set for a sub-agent transcript: the turn its steps
+	// are reported under.
+	agentID string
+	turn    int
+
+	// sentTurn and sentSteps are the index of the last turn reported and how
+	// many of its steps have been.
+	sentTurn  int
+	sentSteps int
+}
+
+// OpenSessionTail returns a tail of the session's transcript, or nil when the
+// session has no transcript on disk. An archived copy is not tailed: nothing
+// will ever be appended to it, and the journey already shows all of it. Nor is
+// another agent's log, which is not Claude Code JSONL until it is read whole.
+func OpenSessionTail(sessionID string, logger *slog.Logger) *SessionTail {
+	_, _, filePath := findSessionFile(sessionID)
+	if filePath == "" || sourceFor(filePath) != nil {
+		return nil
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		return nil
+	}
+	return &SessionTail{
+		sessionID:    sessionID,
+		subagentsDir: filepath.Join(filepath.Dir(filePath), sessionID, "subagents"),
+		logger:       logger,
+		main:         newTailedTranscript(filePath, logger, false),
+		subagents:    map[string]*tailedTranscript{},
+		toolTurns:    map[string]int{},
+	}
+}
+
+func newTailedTranscript(path string, logger *slog.Logger, subagent bool) *tailedTranscript {
+	t := &tailedTranscript{path: path}
+	t.builder.logger = logger
+	t.builder.subagentMode = subagent
+	// Sub-agents are tailed as transcripts of their own rather than read
+	// whole when their Task tool_use goes by: when it does, the sub-agent has
+	// not started writing yet.
+	t.builder.subagents = map[string]*subagentEntry{}
+	return t
+}
+
+// Run replays the transcript as it stands, sends TailEventReady, then reports
+// every change until ctx is done. emit is called from Run's goroutine only.
+func (t *SessionTail) Run(ctx context.Context, emit func(TailEvent)) error {
+	w := newDirWatcher(t.logger)
+	defer w.close()
+	// Watched before the first read, so nothing appended between the two is
+	// missed.
+	t.watch(w)
+	if err := t.update(emit); err != nil {
+		return err
+	}
+	emit(TailEvent{Type: TailEventReady, Data: struct{}{}})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-w.events():
+			if !ok {
+				return errors.New("transcript watcher stopped")
+			}
+			t.watch(w)
+			if err := t.update(emit); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watch (re)watches the session's directories. The session's own directory
+// and its subagents/ appear only once the session delegates work, so they are
+// watched again on every wake: their parent's create event is what woke it.
+// Watching the project directory also wakes the tail for the other sessions
+// in it; a wake that finds nothing new costs a few stats.
+func (t *SessionTail) watch(w dirWatcher) {
+	w.watch(filepath.Dir(t.main.path))
+	w.watch(filepath.Dir(t.subagentsDir))
+	w.watch(t.subagentsDir)
+}
+
+// update reads what was appended since the last update and reports it: the
+// main thread's steps first, so a sub-agent's spawning tool_call is known
+// before the sub-agent is, then each sub-agent's, then the new totals.
+func (t *SessionTail) update(emit func(TailEvent)) error {
+	grew, err := t.main.readNew()
+	if err != nil {
+		return err
+	}
+	for _, s := range t.main.newSteps() {
+		t.noteToolCall(s)
+		emit(TailEvent{Type: TailEventStep, Data: s})
+	}
+
+	for _, sub := range t.discoverSubagents() {
+		emit(TailEvent{Type: TailEventSubagent, Data: sub})
+		grew = true
+	}
+	for _, path := range t.subagentPaths() {
+		sub := t.subagents[path]
+		subGrew, err := sub.readNew()
+		if err != nil {
+			return err
+		}
+		grew = grew || subGrew
+		for _, s := range sub.newSteps() {
+			emit(TailEvent{Type: TailEventStep, Data: s})
+		}
+	}
+
+	if grew {
+		emit(TailEvent{Type: TailEventUsage, Data: t.usage()})
+	}
+	return nil
+}
+
+// noteToolCall remembers a streamed main-thread tool_call's turn.
+func (t *SessionTail) noteToolCall(s TailStep) {
+	if s.Step.Type != "tool_call" {
+		return
+	}
+	var d ToolCallData
+	if json.Unmarshal(s.Step.Data, &d) == nil && d.ToolUseID != "" {
+		t.toolTurns[d.ToolUseID] = s.Turn
+	}
+}
+
+// discoverSubagents starts tailing every sub-agent transcript that has
+// appeared since the last update. One that appears before the main thread has
+// a turn waits for it: there is nowhere to nest it yet.
+func (t *SessionTail) discoverSubagents() []TailSubagent {
+	entries, err := os.ReadDir(t.subagentsDir)
+	if err != nil || t.main.builder.turnNumber == 0 {
+		return nil
+	}
+	var found []TailSubagent
+	for _, e := range entries {
+		path := filepath.Join(t.subagentsDir, e.Name())
+		if e.IsDir() || !strings.HasSuffix(e.Name(), jsonlExt) || t.subagents[path] != nil {
+			continue
+		}
+		meta := readSubagentMeta(path, t.logger)
+		sub := TailSubagent{
+			Turn:        t.main.builder.turnNumber,
+			AgentID:     strings.TrimSuffix(e.Name(), jsonlExt),
+			AgentType:   meta.AgentType,
+			Description: meta.Description,
+		}
+		if turn, ok := t.toolTurns[meta.ToolUseID]; ok && meta.ToolUseID != "" {
+			sub.Turn, sub.ToolUseID = turn, meta.ToolUseID
+		}
+		tt := newTailedTranscript(path, t.logger, true)
+		tt.agentID, tt.turn = sub.AgentID, sub.Turn
+		t.subagents[path] = tt
+		found = append(found, sub)
+	}
+	return found
+}
+
+// subagentPaths returns the tailed sub-agent transcripts in a stable order.
+func (t *SessionTail) subagentPaths() []string {
+	paths := make([]string, 0, len(t.subagents))
+	for p := range t.subagents {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// usage totals the session so far. Active time merges every transcript's
+// timestamps, as the journey does, so delegated work fills the main thread's
+// Task wait gaps rather than counting twice.
+func (t *SessionTail) usage() TailUsage {
+	j := &t.main.journey
+	b := &t.main.builder
+	p := TailUsage{
+		Model:      j.Model,
+		CWD:        j.CWD,
+		GitBranch:  j.GitBranch,
+		Summary:    j.Summary,
+		StartTime:  b.tr.start,
+		EndTime:    b.tr.last,
+		TotalTurns: b.turnNumber,
+		Usage:      j.Usage,
+		CostUSD:    b.costSoFar(),
+	}
+	active := activeTimeTracker{stamps: append([]activeStamp(nil), b.active.stamps...)}
+	for _, sub := range t.subagents {
+		addUsage(&p.SubagentUsage, sub.journey.Usage)
+		p.CostUSD += sub.builder.costSoFar()
+		active.stamps = append(active.stamps, sub.builder.active.stamps...)
+		if !sub.builder.tr.last.IsZero() && sub.builder.tr.last.After(p.EndTime) {
+			p.EndTime = sub.builder.tr.last
+		}
+	}
+	p.SubagentCount = len(t.subagents)
+	p.ActiveDuration, _ = active.durations()
+	return p
+}
+
+// costSoFar is the priced cost of every turn the builder has seen, including
+// the one still open.
+func (b *journeyBuilder) costSoFar() float64 {
+	var total float64
+	for _, turn := range b.turns {
+		total += turn.CostUSD
+	}
+	if b.currentTurn != nil {
+		total += sessionCostFromPricing(b.turnCost).TotalUSD
+	}
+	return total
+}
+
+// readNew feeds every complete line appended since the last read to the
+// builder, and reports whether there were any. A trailing line without its
+// newline is still being written; it is left for the next read. A transcript
+// that does not exist yet has simply not been written.
+func (t *tailedTranscript) readNew() (bool, error) {
+	f, err := os.Open(t.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return false, err
+	}
+	r := bufio.NewReaderSize(f, 64*1024)
+	grew := false
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return grew, nil
+			}
+			return grew, err
+		}
+		t.offset += int64(len(line))
+		t.builder.processLine(line, &t.journey)
+		grew = true
+	}
+}
+
+// newSteps returns the steps the builder has added since the last call. Turns
+// only ever grow at the end, and a closed turn keeps the steps it had while
+// open, so a turn index and a step count are all the bookkeeping needed.
+func (t *tailedTranscript) newSteps() []TailStep {
+	b := &t.builder
+	turns := b.turns[:len(b.turns):len(b.turns)]
+	if b.currentTurn != nil {
+		turns = append(turns, *b.currentTurn)
+	}
+	var out []TailStep
+	for i := t.sentTurn; i < len(turns); i++ {
+		from := 0
+		if i == t.sentTurn {
+			from = t.sentSteps
+		}
+		for _, step := range turns[i].Steps[from:] {
+			s := TailStep{Turn: turns[i].Number, AgentID: t.agentID, Step: step}
+			if t.agentID != "" {
+				s.Turn = t.turn
+			}
+			out = append(out, s)
+		}
+	}
+	if len(turns) > 0 {
+		t.sentTurn, t.sentSteps = len(turns)-1, len(turns[len(turns)-1].Steps)
+	}
+	return out
+}
+
+// dirWatcher wakes a tail when something in a watched directory changes. The
+// change itself is not reported: the tail re-reads whatever grew. Wakes are
+// coalesced, so a burst of appends costs one read.
+type dirWatcher interface {
+	// watch adds a directory. One that does not exist yet is ignored; the
+	// tail watches it again once its parent reports it created.
+	watch(dir string)
+	// events is closed if the watcher fails.
+	events() <-chan struct{}
+	close()
+}
+
+// pollWatcher wakes its tail every tailPollInterval, watched directories or
+// not. It stands in where inotify is unavailable.
+type pollWatcher struct {
+	ch   chan struct{}
+	done chan struct{}
+}
+
+func newPollWatcher() *pollWatcher {
+	w := &pollWatcher{ch: make(chan struct{}, 1), done: make(chan struct{})}
+	go func() {
+		ticker := time.NewTicker(tailPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-ticker.C:
+				wake(w.ch)
+			}
+		}
+	}()
+	return w
+}
+
+func (w *pollWatcher) watch(string) {}
+
+func (w *pollWatcher) events() <-chan struct{} { return w.ch }
+
+func (w *pollWatcher) close() { close(w.done) }
+
+// wake signals ch without blocking: a wake already pending covers this one.
+func wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,60 @@
+//go:build linux
+
+package claudesessions
+
+import (
+	"log/slog"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyMask is what wakes a tail: a transcript appended to, and a transcript
+// or sub-agent directory appearing.
+const inotifyMask = unix.IN_MODIFY | unix.IN_CREATE | unix.IN_MOVED_TO
+
+// inotifyWatcher wakes a tail as soon as a watched directory changes. The
+// event records are not decoded; any of them means "read again".
+type inotifyWatcher struct {
+	fd   int
+	file *os.File
+	ch   chan struct{}
+}
+
+// newDirWatcher returns an inotify watcher, or a polling one when inotify is
+// unavailable — the per-user instance limit is low on some distributions.
+func newDirWatcher(logger *slog.Logger) dirWatcher {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		logger.Warn("inotify unavailable, polling session transcripts instead", "error", err)
+		return newPollWatcher()
+	}
+	// A non-blocking descriptor handed to os.NewFile is read through the
+	// runtime poller, so close() unblocks the reader below.
+	w := &inotifyWatcher{fd: fd, file: os.NewFile(uintptr(fd), "inotify"), ch: make(chan struct{}, 1)}
+	go w.read()
+	return w
+}
+
+func (w *inotifyWatcher) watch(dir string) {
+	// Re-adding a watched directory is a no-op, and a missing one fails
+	// harmlessly; either way there is nothing to report.
+	unix.InotifyAddWatch(w.fd, dir, inotifyMask) //nolint:errcheck,gosec
+}
+
+func (w *inotifyWatcher) read() {
+	buf := make([]byte, 64*1024)
+	for {
+		if _, err := w.file.Read(buf); err != nil {
+			close(w.ch)
+			return
+		}
+		wake(w.ch)
+	}
+}
+
+func (w *inotifyWatcher) events() <-chan struct{} { return w.ch }
+
+func (w *inotifyWatcher) close() {
+	w.file.Close() //nolint:errcheck,gosec
+}
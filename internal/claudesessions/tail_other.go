@@ -0,0 +1,12 @@
+//go:build !linux
+
+package claudesessions
+
+import "log/slog"
+
+// newDirWatcher polls. inotify is Linux's own, and the macOS and Windows
+// equivalents would need cgo or another dependency for a view that a
+// once-a-second re-read serves just as well.
+func newDirWatcher(_ *slog.Logger) dirWatcher {
+	return newPollWatcher()
+}
@@ -0,0 +1,136 @@
+package claudesessions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// appendLines appends transcript lines to path, creating it if need be.
+func appendLines(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for _, l := range lines {
+		if _, err := f.WriteString(l + "\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// nextTail returns the next event of the given type, failing the test if none
+// arrives in time.
+func nextTail(t *testing.T, events <-chan TailEvent, eventType string) TailEvent {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type == eventType {
+				return ev
+			}
+		case <-timeout:
+			t.Fatalf("no %s event", eventType)
+		}
+	}
+}
+
+func TestSessionTail_StreamsAppendedStepsAndSubagents(t *testing.T) {
+	home := t.TempDir()
+	useConfigDirs(t, home)
+	projectDir := filepath.Join(home, ".claude", "projects", "-home-dev-work")
+	if err := os.MkdirAll(projectDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	fp := filepath.Join(projectDir, "sess-tail"+jsonlExt)
+	appendLines(t, fp, userInputEvent("u1", ts(t0), "Explore the repo"))
+
+	if OpenSessionTail("sess-missing", testLogger) != nil {
+		t.Error("a session with no transcript opened a tail")
+	}
+	tail := OpenSessionTail("sess-tail", testLogger)
+	if tail == nil {
+		t.Fatal("OpenSessionTail found no transcript")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan TailEvent, 64)
+	done := make(chan error, 1)
+	go func() { done <- tail.Run(ctx, func(ev TailEvent) { events <- ev }) }()
+
+	// The transcript as it stood is replayed first.
+	if s := nextTail(t, events, TailEventStep).Data.(TailStep); s.Step.Type != "user_input" || s.Turn != 1 {
+		t.Errorf("replayed step = %+v, want turn 1's user_input", s)
+	}
+	nextTail(t, events, TailEventReady)
+
+	// A line still being written is not read until its newline lands.
+	task := assistantEvent("a1", "u1", ts(t1), []map[string]any{
+		{"type": "tool_use", "id": "toolu_1", "name": "Task", "input": map[string]any{"description": "explore"}},
+	})
+	half := len(task) / 2
+	appendRaw(t, fp, task[:half])
+	appendRaw(t, fp, task[half:]+"\n")
+	if s := nextTail(t, events, TailEventStep).Data.(TailStep); s.Step.Type != "tool_call" {
+		t.Errorf("appended step = %+v, want the Task tool_call", s)
+	}
+	if p := nextTail(t, events, TailEventUsage).Data.(TailUsage); p.Usage.OutputTokens != 50 {
+		t.Errorf("usage = %+v, want the assistant message's", p.Usage)
+	}
+
+	// The sub-agent it spawned is nested under the call as it appears.
+	subagentsDir := filepath.Join(projectDir, "sess-tail", "subagents")
+	if err := os.MkdirAll(subagentsDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	meta := subagentMetaJSON("toolu_1", "general-purpose", "explore the repo")
+	if err := os.WriteFile(filepath.Join(subagentsDir, "agent-x.meta.json"), []byte(meta), 0600); err != nil {
+		t.Fatal(err)
+	}
+	appendLines(t, filepath.Join(subagentsDir, "agent-x"+jsonlExt),
+		subagentSidechainEvent("user", "s1", ts(t1), nil),
+		subagentSidechainEvent("assistant", "s2", ts(t2), []map[string]any{{"type": "text", "text": "found it"}}),
+	)
+	sub := nextTail(t, events, TailEventSubagent).Data.(TailSubagent)
+	if sub.AgentID != "agent-x" || sub.ToolUseID != "toolu_1" || sub.Turn != 1 {
+		t.Errorf("sub-agent = %+v, want agent-x under toolu_1 in turn 1", sub)
+	}
+	var agentSteps []string
+	for len(agentSteps) < 2 {
+		s := nextTail(t, events, TailEventStep).Data.(TailStep)
+		if s.AgentID != "agent-x" || s.Turn != 1 {
+			t.Fatalf("sub-agent step = %+v, want agent-x's in turn 1", s)
+		}
+		agentSteps = append(agentSteps, s.Step.Type)
+	}
+	if !equalStrings(agentSteps, []string{"user_input", "text_response"}) {
+		t.Errorf("sub-agent steps = %v, want its prompt and reply", agentSteps)
+	}
+	p := nextTail(t, events, TailEventUsage).Data.(TailUsage)
+	if p.SubagentCount != 1 || p.SubagentUsage.OutputTokens != 20 || p.TotalTurns != 1 {
+		t.Errorf("usage = %+v, want one sub-agent's 20 output tokens in one turn", p)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run: %v", err)
+	}
+}
+
+// appendRaw appends s to path without adding a newline.
+func appendRaw(t *testing.T, path, s string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(s); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,445 @@
+package claudesessions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Full-text search over transcript content.
+//
+// The sessions list search matches titles and previews; this matches what was
+// actually said and done — every prompt, reply, thinking block, tool input and
+// tool output. Each is stored as one row of claude_transcript_step, addressed
+// by the turn number and step index the journey view renders, and indexed by
+// the claude_transcript_fts FTS5 table (migration 33).
+//
+// The rows are derived by running the journey builder over the transcript
+// rather than by a second parser, so a hit's turn and step are by construction
+// the ones the journey page shows. A sub-agent's steps are indexed under the
+// Task call that spawned them, which is where the journey nests them.
+//
+// The scanner keeps the index in step: a re-read transcript has its rows
+// replaced in the same transaction as its cache row, and a removed one has them
+// deleted with it. Because the rows carry no mtime of their own, a write that
+// failed leaves the cache row stale too, and the next scan re-reads both.
+
+// transcriptIndexMaxRunes caps one indexed step. A Write call's input or a
+// thinking block can run to tens of kilobytes; the head of it is what anyone
+// searches for, and the cap keeps the index a fraction of the corpus rather
+// than a second copy of it. Tool output is already capped tighter by the
+// journey builder.
+const transcriptIndexMaxRunes = 4000
+
+// transcriptEntry is one indexed step.
+type transcriptEntry struct {
+	turn int
+	step int
+	kind string
+	body string
+}
+
+// readTranscriptEntries builds the journey of one session transcript and
+// returns its indexable steps. A transcript the builder cannot read yields no
+// entries, which clears the session's rows: stale rows would link to steps
+// that no longer exist.
+func readTranscriptEntries(sessionID, filePath string, logger *slog.Logger) []transcriptEntry {
+	j, err := buildJourney(sessionID, filePath, logger)
+	if err != nil {
+		logger.Warn("claude sessions: failed to index transcript", "file", filePath, "error", err)
+		return nil
+	}
+	if j == nil {
+		return nil
+	}
+	return transcriptEntries(j)
+}
+
+// transcriptEntries flattens a journey into indexable steps.
+func transcriptEntries(j *SessionJourney) []transcriptEntry {
+	var out []transcriptEntry
+	for _, turn := range j.Turns {
+		for i, step := range turn.Steps {
+			out = appendStepEntries(out, turn.Number, i, step)
+		}
+	}
+	return out
+}
+
+// appendStepEntries adds a step and, addressed to the same step, everything
+// nested under it — a hit inside a sub-agent links to the call that spawned it.
+func appendStepEntries(out []transcriptEntry, turn, index int, step JourneyStep) []transcriptEntry {
+	if body := stepText(step); strings.TrimSpace(body) != "" {
+		out = append(out, transcriptEntry{
+			turn: turn,
+			step: index,
+			kind: step.Type,
+			body: truncateRunes(body, transcriptIndexMaxRunes),
+		})
+	}
+	for _, nested := range step.Steps {
+		out = appendStepEntries(out, turn, index, nested)
+	}
+	return out
+}
+
+// stepText is the searchable text of one journey step. Steps that carry no
+// words of their own (durations, compaction markers) have none.
+func stepText(step JourneyStep) string {
+	switch step.Type {
+	case "user_input":
+		var d UserInputData
+		if json.Unmarshal(step.Data, &d) == nil {
+			return d.Content
+		}
+	case "text_response":
+		var d TextResponseData
+		if json.Unmarshal(step.Data, &d) == nil {
+			return d.Content
+		}
+	case "thinking":
+		var d ThinkingData
+		if json.Unmarshal(step.Data, &d) == nil {
+			return d.Full
+		}
+	case "tool_call":
+		var d ToolCallData
+		if json.Unmarshal(step.Data, &d) == nil {
+			return strings.Join(nonEmpty(d.ToolName, d.Description, jsonText(d.Input)), "\n")
+		}
+	case "tool_result":
+		var d ToolResultData
+		if json.Unmarshal(step.Data, &d) == nil {
+			return d.Content
+		}
+	case "sub_agent":
+		var d SubAgentData
+		if json.Unmarshal(step.Data, &d) == nil {
+			return strings.Join(nonEmpty(d.AgentType, d.Description), "\n")
+		}
+	}
+	return ""
+}
+
+// jsonText is the string values of a tool input, one per line. Indexing the
+// raw JSON would glue escape sequences to words — "\nfunc" tokenizes as
+// "nfunc" — and make every key a match for its own name.
+func jsonText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var v any
+	if json.Unmarshal(raw, &v) != nil {
+		return string(raw)
+	}
+	var parts []string
+	var walk func(any)
+	walk = func(v any) {
+		switch x := v.(type) {
+		case string:
+			parts = append(parts, x)
+		case []any:
+			for _, e := range x {
+				walk(e)
+			}
+		case map[string]any:
+			// Sorted so a re-read writes the same body.
+			keys := make([]string, 0, len(x))
+			for k := range x {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(x[k])
+			}
+		}
+	}
+	walk(v)
+	return strings.Join(parts, "\n")
+}
+
+func nonEmpty(values ...string) []string {
+	out := values[:0]
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// replaceTranscriptRows rewrites a session's indexed steps. Like the linked
+// PRs, the transcript is re-read whole, so the rows are replaced rather than
+// merged; the triggers on claude_transcript_step carry both halves into the
+// FTS index.
+func replaceTranscriptRows(ctx context.Context, ex execer, sessionID string, entries []transcriptEntry) error {
+	if _, err := ex.ExecContext(ctx,
+		`DELETE FROM claude_transcript_step WHERE session_id = ?`, sessionID); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if _, err := ex.ExecContext(ctx, `
+			INSERT INTO claude_transcript_step (session_id, turn, step, kind, body)
+			VALUES (?, ?, ?, ?, ?)`,
+			sessionID, e.turn, e.step, e.kind, e.body,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reindexTranscripts rebuilds the rows of sessions whose own transcript was
+// not re-read this scan but whose index is stale anyway: a sub-agent
+// transcript changed or disappeared, and its steps are indexed under the
+// parent. sessions maps a session id to its parent transcript's path.
+func reindexTranscripts(db *sql.DB, logger *slog.Logger, sessions map[string]string) {
+	ctx := context.Background()
+	for sessionID, filePath := range sessions {
+		entries := readTranscriptEntries(sessionID, filePath, logger)
+		if err := runInTx(ctx, db, func(tx *sql.Tx) error {
+			return replaceTranscriptRows(ctx, tx, sessionID, entries)
+		}); err != nil {
+			logger.Warn("claude sessions: failed to reindex transcript",
+				"session_id", sessionID, "error", err)
+		}
+	}
+}
+
+// runInTx runs fn in a transaction, rolling back when it fails.
+func runInTx(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+				err = errors.Join(err, rbErr)
+			}
+		}
+	}()
+	if err = fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ── Search ──────────────────────────────────────────────────────────────────
+
+// Search page bounds. Hits are ranked, not ordered by a column, so they page
+// by offset: the keyset paging the list uses needs a stable sort key, and a
+// relevance score is only stable until the next scan.
+const (
+	DefaultSearchLimit = 25
+	MaxSearchLimit     = 100
+)
+
+// Snippet delimiters. Control characters rather than markup so no transcript
+// text can forge a highlight, and so the client never has to render HTML.
+const (
+	snippetOpen  = "\x02"
+	snippetClose = "\x03"
+)
+
+// TranscriptSearchQuery is a full-text search.
+type TranscriptSearchQuery struct {
+	// Text is what to look for. Words must all appear in a step, in any
+	// order; "double quotes" match a phrase; the last word also matches as a
+	// prefix, so results keep up with typing.
+	Text string
+	// Filter narrows the sessions searched with the sessions list's filters.
+	// Its Search, Sort, Limit and Cursor are ignored.
+	Filter SessionQuery
+	// Limit is the page size: zero means DefaultSearchLimit, and values above
+	// MaxSearchLimit are clamped.
+	Limit  int
+	Offset int
+}
+
+// TranscriptSearchPage is one page of ranked hits, best first.
+type TranscriptSearchPage struct {
+	Hits    []TranscriptHit `json:"hits"`
+	HasMore bool            `json:"has_more"`
+}
+
+// TranscriptHit is one matching step. Turn and Step address it in the
+// session's journey: Turn is the journey turn number, Step the index into
+// that turn's steps.
+type TranscriptHit struct {
+	SessionID    string    `json:"session_id"`
+	Title        string    `json:"title"`
+	ProjectPath  string    `json:"project_path"`
+	LastActivity time.Time `json:"last_activity"`
+	Turn         int       `json:"turn"`
+	Step         int       `json:"step"`
+	// Kind is the journey step type: user_input, text_response, thinking,
+	// tool_call, tool_result or sub_agent.
+	Kind    string        `json:"kind"`
+	Snippet []SnippetPart `json:"snippet"`
+}
+
+// SnippetPart is a run of snippet text; Match marks the runs that matched.
+type SnippetPart struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
+
+// SearchTranscripts runs a full-text search over the indexed transcripts.
+// Hidden projects and unindexed config dirs are excluded exactly as they are
+// from the sessions list.
+func (c *Cache) SearchTranscripts(q TranscriptSearchQuery) (TranscriptSearchPage, error) {
+	return searchTranscripts(c.db, c.logger, q)
+}
+
+func searchTranscripts(db *sql.DB, logger *slog.Logger, q TranscriptSearchQuery) (TranscriptSearchPage, error) {
+	page := TranscriptSearchPage{Hits: []TranscriptHit{}}
+	match := ftsQuery(q.Text)
+	if match == "" {
+		return page, nil
+	}
+
+	f := q.Filter
+	f.Search = ""
+	filter, err := buildFilter(f)
+	if err != nil {
+		return page, err
+	}
+	filter.add("claude_transcript_fts MATCH ?", match)
+
+	limit := q.Limit
+	switch {
+	case limit <= 0:
+		limit = DefaultSearchLimit
+	case limit > MaxSearchLimit:
+		limit = MaxSearchLimit
+	}
+	offset := max(q.Offset, 0)
+
+	// The hidden-project and filter predicates are written against the list's
+	// `c` and `sa` aliases, so the search joins onto the same source rather
+	// than restating them. One extra row answers HasMore.
+	query := `SELECT t.session_id, t.turn, t.step, t.kind,
+	       snippet(claude_transcript_fts, 0, char(2), char(3), '…', 24),
+	       c.custom_title, c.native_title, c.ai_title, c.preview, c.project_path, c.last_activity` +
+		sessionSummarySource + `
+	JOIN claude_transcript_step t ON t.session_id = c.session_id
+	JOIN claude_transcript_fts ON claude_transcript_fts.rowid = t.id` +
+		filter.where() +
+		fmt.Sprintf("\nORDER BY bm25(claude_transcript_fts), c.last_activity DESC, t.id\nLIMIT %d OFFSET %d",
+			limit+1, offset)
+
+	rows, err := db.QueryContext(context.Background(), query, filter.args...)
+	if err != nil {
+		return page, fmt.Errorf("claudesessions: searching transcripts: %w", err)
+	}
+	defer closeRows(rows, logger)
+
+	for rows.Next() {
+		var (
+			h       TranscriptHit
+			snippet string
+			s       ClaudeSessionSummary
+		)
+		if err := rows.Scan(&h.SessionID, &h.Turn, &h.Step, &h.Kind, &snippet,
+			&s.CustomTitle, &s.NativeTitle, &s.AITitle, &s.Preview, &h.ProjectPath, &h.LastActivity); err != nil {
+			return page, fmt.Errorf("claudesessions: scanning search hit: %w", err)
+		}
+		h.Title = s.ResolveDisplayTitle()
+		h.Snippet = splitSnippet(snippet)
+		page.Hits = append(page.Hits, h)
+	}
+	if err := rows.Err(); err != nil {
+		return page, fmt.Errorf("claudesessions: reading search hits: %w", err)
+	}
+	if len(page.Hits) > limit {
+		page.Hits = page.Hits[:limit]
+		page.HasMore = true
+	}
+	return page, nil
+}
+
+// splitSnippet turns FTS5's delimited snippet into runs.
+func splitSnippet(s string) []SnippetPart {
+	parts := []SnippetPart{}
+	for s != "" {
+		open := strings.Index(s, snippetOpen)
+		if open < 0 {
+			parts = append(parts, SnippetPart{Text: s})
+			break
+		}
+		if open > 0 {
+			parts = append(parts, SnippetPart{Text: s[:open]})
+		}
+		s = s[open+len(snippetOpen):]
+		end := strings.Index(s, snippetClose)
+		if end < 0 {
+			end = len(s)
+		}
+		parts = append(parts, SnippetPart{Text: s[:end], Match: true})
+		s = strings.TrimPrefix(s[end:], snippetClose)
+	}
+	return parts
+}
+
+// ftsQuery turns what a user typed into an FTS5 query that cannot be a syntax
+// error. Every word is quoted, so FTS5 operators and punctuation ("AND",
+// "foo-bar", "a:b") are searched for rather than parsed; quoted phrases stay
+// phrases; and the last bare word is a prefix. Words with no letter or digit
+// are dropped — the tokenizer would reduce them to nothing. Empty when
+// nothing searchable remains.
+func ftsQuery(input string) string {
+	type term struct {
+		text   string
+		phrase bool
+	}
+	var terms []term
+	rest := input
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			break
+		}
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				terms = append(terms, term{text: rest[1:], phrase: true})
+				break
+			}
+			terms = append(terms, term{text: rest[1 : end+1], phrase: true})
+			rest = rest[end+2:]
+			continue
+		}
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+		terms = append(terms, term{text: rest[:end]})
+		rest = rest[end:]
+	}
+
+	quoted := make([]string, 0, len(terms))
+	lastBare := -1
+	for _, t := range terms {
+		if !strings.ContainsFunc(t.text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+			continue
+		}
+		quoted = append(quoted, `"`+strings.ReplaceAll(t.text, `"`, `""`)+`"`)
+		if !t.phrase {
+			lastBare = len(quoted) - 1
+		} else {
+			lastBare = -1
+		}
+	}
+	if lastBare >= 0 {
+		quoted[lastBare] += "*"
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,168 @@
+package claudesessions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFTSQuery(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"", ""},
+		{"   ", ""},
+		{"flaky test", `"flaky" "test"*`},
+		{`"exit status" 1`, `"exit status" "1"*`},
+		{`retry "exit status"`, `"retry" "exit status"`},
+		{"foo-bar AND baz", `"foo-bar" "AND" "baz"*`},
+		{`say "hi`, `"say" "hi"`},
+		{`a"b`, `"a""b"*`},
+		{"-- %% ***", ""},
+	}
+	for _, tc := range cases {
+		if got := ftsQuery(tc.in); got != tc.want {
+			t.Errorf("ftsQuery(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSplitSnippet(t *testing.T) {
+	got := splitSnippet("…the " + snippetOpen + "flaky" + snippetClose + " test " + snippetOpen + "again")
+	want := []SnippetPart{
+		{Text: "…the "}, {Text: "flaky", Match: true}, {Text: " test "}, {Text: "again", Match: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parts = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("parts = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestTranscriptEntries_AddressesNestedStepsToTheirParent(t *testing.T) {
+	task := compareStep("sub_agent", SubAgentData{AgentType: "Explore", Description: "map the repo"})
+	task.Steps = []JourneyStep{compareStep("text_response", TextResponseData{Content: "found the scheduler"})}
+	j := journeyOf("s",
+		JourneyTurn{Steps: []JourneyStep{
+			promptStep("where is the cron parser"),
+			compareStep("thinking_duration", map[string]int{"duration_ms": 5}),
+			compareStep("tool_call", ToolCallData{
+				ToolName: "Grep", Input: []byte(`{"pattern":"cron","path":"internal"}`),
+			}),
+			task,
+		}},
+	)
+
+	got := transcriptEntries(j)
+	want := []transcriptEntry{
+		{turn: 1, step: 0, kind: "user_input", body: "where is the cron parser"},
+		{turn: 1, step: 2, kind: "tool_call", body: "Grep\ninternal\ncron"},
+		{turn: 1, step: 3, kind: "sub_agent", body: "Explore\nmap the repo"},
+		{turn: 1, step: 3, kind: "text_response", body: "found the scheduler"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("entries = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// writeTranscript writes a one-turn session: a prompt, a tool call and its
+// output, and a reply.
+func writeTranscript(t *testing.T, dir, sessionID, prompt, output string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	lines := []string{
+		`{"type":"user","sessionId":"` + sessionID + `","timestamp":"2026-08-01T10:00:00Z","cwd":"/srv/app",` +
+			`"message":{"role":"user","content":"` + prompt + `"}}`,
+		`{"type":"assistant","sessionId":"` + sessionID + `","timestamp":"2026-08-01T10:00:05Z",` +
+			`"message":{"role":"assistant","model":"claude-sonnet-4","content":[` +
+			`{"type":"tool_use","id":"tu1","name":"Bash","input":{"command":"go test ./..."}}]}}`,
+		`{"type":"user","sessionId":"` + sessionID + `","timestamp":"2026-08-01T10:00:09Z",` +
+			`"message":{"role":"user","content":[` +
+			`{"type":"tool_result","tool_use_id":"tu1","content":"` + output + `"}]}}`,
+		`{"type":"assistant","sessionId":"` + sessionID + `","timestamp":"2026-08-01T10:00:12Z",` +
+			`"message":{"role":"assistant","model":"claude-sonnet-4","content":[` +
+			`{"type":"text","text":"The suite passes."}]}}`,
+	}
+	data := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, sessionID+".jsonl"), []byte(data), 0600); err != nil {
+		t.Fatalf("write jsonl: %v", err)
+	}
+}
+
+func TestSearchTranscripts_FollowsTheScanner(t *testing.T) {
+	c := newScanCache(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	projectDir := filepath.Join(home, ".claude", "projects", "-srv-app")
+
+	writeTranscript(t, projectDir, "sess-a", "run the tests", "FAIL TestScheduler_Retry")
+	writeTranscript(t, projectDir, "sess-b", "check the style", "golangci-lint: 0 issues")
+	if _, err := IncrementalScan(c.db, testLogger); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+
+	page, err := c.SearchTranscripts(TranscriptSearchQuery{Text: "testscheduler_retry"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(page.Hits) != 1 {
+		t.Fatalf("hits = %+v, want the tool output of sess-a", page.Hits)
+	}
+	h := page.Hits[0]
+	if h.SessionID != "sess-a" || h.Turn != 1 || h.Kind != "tool_result" || h.Title != "run the tests" {
+		t.Errorf("hit = %+v", h)
+	}
+	var matched bool
+	for _, p := range h.Snippet {
+		matched = matched || (p.Match && p.Text == "TestScheduler_Retry")
+	}
+	if !matched {
+		t.Errorf("snippet = %+v, want the match highlighted", h.Snippet)
+	}
+
+	// The list search reaches transcript content too, prefix-matching as typed.
+	list, err := c.ListPage(SessionQuery{Search: "golang"})
+	if err != nil {
+		t.Fatalf("list search: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].SessionID != "sess-b" {
+		t.Errorf("list search = %v, want sess-b", ids(list))
+	}
+
+	// A rewritten transcript replaces its rows; a deleted one takes them along.
+	writeTranscript(t, projectDir, "sess-a", "run the tests", "PASS")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(projectDir, "sess-a.jsonl"), later, later); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if err := os.Remove(filepath.Join(projectDir, "sess-b.jsonl")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := IncrementalScan(c.db, testLogger); err != nil {
+		t.Fatalf("rescan: %v", err)
+	}
+	for _, q := range []string{"testscheduler_retry", "golangci"} {
+		page, err := c.SearchTranscripts(TranscriptSearchQuery{Text: q})
+		if err != nil {
+			t.Fatalf("search %q: %v", q, err)
+		}
+		if len(page.Hits) != 0 {
+			t.Errorf("search %q after rescan = %+v, want nothing", q, page.Hits)
+		}
+	}
+	var rows int
+	if err := c.db.QueryRow(`SELECT COUNT(*) FROM claude_transcript_step WHERE session_id = 'sess-b'`).
+		Scan(&rows); err != nil || rows != 0 {
+		t.Errorf("sess-b rows = %d (%v), want none", rows, err)
+	}
+}
@@ -51,6 +51,10 @@ type costAccumulator struct {
 	// per-message timing nor per-message model, so no later pass could
 	// reconstruct this without re-reading the transcript.
 	byModel map[string]SessionCost
+	// byMinute buckets the same messages by the minute they were sent, for
+	// the usage windows (see usage_meter.go). Like byModel, it needs each
+	// message's own timestamp, which nothing after this point keeps.
+	byMinute map[int64]usageBucket
 }
 
 func newCostAccumulator(resolver *pricing.Resolver) *costAccumulator {
@@ -74,11 +78,13 @@ func (a *costAccumulator) addAssistantMessage(model string, u TokenUsage, at tim
 			}
 			a.unknownModels[model] += u.InputTokens + u.OutputTokens
 		}
+		a.addMinute(at, u, 0)
 		return
 	}
 	priced := res.Rate.Price(u.eventUsage())
 	a.cost.Add(priced)
 	a.pricedMessages++
+	a.addMinute(at, u, priced.TotalCostUSD)
 
 	if a.byModel == nil {
 		a.byModel = map[string]SessionCost{}
@@ -193,7 +199,11 @@ type ClaudeSessionSummary struct {
 	// account it ran under. Empty on rows written before the column existed,
 	// which belong to the default dir.
 	ConfigDir string `json:"config_dir,omitempty"`
-	Preview   string `json:"preview"` // first user message text, truncated
+	// CodingAgent is the agent that wrote the session: CodingAgentClaudeCode,
+	// or the imported agent whose TranscriptSource found it. Blank on a summary
+	// read straight from a Claude Code transcript rather than the cache.
+	CodingAgent string `json:"coding_agent,omitempty"`
+	Preview     string `json:"preview"` // first user message text, truncated
 	// previewIsFallback marks a Preview taken from an injected wrapper because
 	// no genuine prompt had been seen yet. Scan-local only (never stored or
 	// serialized): it lets a later real prompt replace the placeholder.
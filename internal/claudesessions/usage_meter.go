@@ -0,0 +1,408 @@
+package claudesessions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/eventbus"
+)
+
+// Claude's Pro and Max subscriptions meter usage in two rolling windows: one
+// that opens with the first message after the last one closed and lasts five
+// hours, and a weekly one. Anthropic publishes neither the windows' boundaries
+// nor their size, so both are estimated here from the per-minute usage the
+// scan records in claude_usage_bucket.
+//
+// Usage is measured in API-equivalent dollars — each message priced through
+// the catalog at its own model and time — rather than in tokens. The limits
+// weigh models and token kinds roughly as the API prices them: an Opus message
+// uses a window up several times faster than the same Sonnet one, and a cache
+// read counts for a fraction of fresh input. A token count would treat them
+// all alike.
+const (
+	fiveHourWindow = 5 * time.Hour
+	weeklyWindow   = 7 * 24 * time.Hour
+
+	// usageWindowHistory is how far back windows are reconstructed. A window
+	// the user has set no limit for is measured against the heaviest window
+	// completed within it.
+	usageWindowHistory = 8 * weeklyWindow
+
+	// minLearnedWindows is how many completed windows a learned limit needs.
+	// Fewer say too little about how far the account goes to warn against.
+	minLearnedWindows = 3
+)
+
+// UsageWindowKind names a subscription usage window.
+type UsageWindowKind string
+
+// The subscription usage windows.
+const (
+	UsageWindowFiveHour UsageWindowKind = "five_hour"
+	UsageWindowWeekly   UsageWindowKind = "weekly"
+)
+
+// Where a usage window's limit came from.
+const (
+	UsageLimitConfigured = "configured"
+	UsageLimitLearned    = "learned"
+)
+
+// Levels of EventUsageLimitApproaching.
+const (
+	usageLevelWarning = "warning"
+	usageLevelLimit   = "limit"
+)
+
+// UsageWindow is one config dir's current window of one kind. Active is false
+// when no window is open — the next message opens one — and every figure but
+// the limit is zero then.
+type UsageWindow struct {
+	Kind      UsageWindowKind `json:"kind"`
+	Active    bool            `json:"active"`
+	StartedAt time.Time       `json:"started_at"`
+	ResetsAt  time.Time       `json:"resets_at"`
+	Messages  int             `json:"messages"`
+	Usage     TokenUsage      `json:"usage"`
+	CostUSD   float64         `json:"cost_usd"`
+	// LimitUSD is what the window is measured against, and LimitSource where
+	// it came from: the user's setting, or the heaviest of the windows
+	// completed in the last eight weeks. Zero and empty when neither is known.
+	LimitUSD         float64 `json:"limit_usd"`
+	LimitSource      string  `json:"limit_source"`
+	CompletedWindows int     `json:"completed_windows"`
+	// UsedPercent is CostUSD as a share of LimitUSD. ProjectedPercent carries
+	// the window's pace so far on to its reset, and LimitAt is when that pace
+	// reaches the limit, if it does before the reset.
+	UsedPercent      float64    `json:"used_percent"`
+	ProjectedPercent float64    `json:"projected_percent"`
+	LimitAt          *time.Time `json:"limit_at,omitempty"`
+}
+
+// UsageMeter is one Claude config dir's usage windows. Each dir signs in as
+// its own account, with limits of its own.
+type UsageMeter struct {
+	ConfigDir string      `json:"config_dir"`
+	FiveHour  UsageWindow `json:"five_hour"`
+	Weekly    UsageWindow `json:"weekly"`
+}
+
+// UsageWindowsReport is the meter of every indexed config dir.
+type UsageWindowsReport struct {
+	GeneratedAt time.Time    `json:"generated_at"`
+	WarnPercent int          `json:"warn_percent"`
+	Meters      []UsageMeter `json:"meters"`
+}
+
+// usageWindowSettings is the process-wide snapshot of the user's usage window
+// preferences, written by ApplyUsageWindowSettings. See dataSettings for why a
+// snapshot.
+var usageWindowSettings = struct {
+	sync.RWMutex
+	fiveHourUSD float64
+	weeklyUSD   float64
+	warnPercent int
+}{warnPercent: config.DefaultUsageWarnPercent}
+
+// ApplyUsageWindowSettings installs the user's usage window limits, in
+// API-equivalent dollars, and the share of a window used before Agento warns.
+// A zero limit is learned from history, and a warnPercent out of range means
+// the default.
+func ApplyUsageWindowSettings(fiveHourUSD, weeklyUSD float64, warnPercent int) {
+	if warnPercent <= 0 || warnPercent > 100 {
+		warnPercent = config.DefaultUsageWarnPercent
+	}
+	usageWindowSettings.Lock()
+	defer usageWindowSettings.Unlock()
+	usageWindowSettings.fiveHourUSD = max(fiveHourUSD, 0)
+	usageWindowSettings.weeklyUSD = max(weeklyUSD, 0)
+	usageWindowSettings.warnPercent = warnPercent
+}
+
+// usageWindowPolicy returns the configured limit for a kind of window and the
+// warning threshold.
+func usageWindowPolicy(kind UsageWindowKind) (limitUSD float64, warnPercent int) {
+	usageWindowSettings.RLock()
+	defer usageWindowSettings.RUnlock()
+	if kind == UsageWindowWeekly {
+		return usageWindowSettings.weeklyUSD, usageWindowSettings.warnPercent
+	}
+	return usageWindowSettings.fiveHourUSD, usageWindowSettings.warnPercent
+}
+
+// ── Recording ───────────────────────────────────────────────────────────────
+
+// usageBucket is the Claude usage of one transcript, or one config dir, in
+// one minute.
+type usageBucket struct {
+	messages int
+	usage    TokenUsage
+	costUSD  float64
+}
+
+func (b *usageBucket) add(o usageBucket) {
+	b.messages += o.messages
+	b.usage.InputTokens += o.usage.InputTokens
+	b.usage.OutputTokens += o.usage.OutputTokens
+	b.usage.CacheCreationTokens += o.usage.CacheCreationTokens
+	b.usage.CacheCreation5mTokens += o.usage.CacheCreation5mTokens
+	b.usage.CacheCreation1hTokens += o.usage.CacheCreation1hTokens
+	b.usage.CacheReadTokens += o.usage.CacheReadTokens
+	b.costUSD += o.costUSD
+}
+
+// addMinute buckets one assistant message by the minute it was sent. A
+// message on a model with no rate still used the window, so its tokens count
+// at no cost.
+func (a *costAccumulator) addMinute(at time.Time, u TokenUsage, costUSD float64) {
+	if at.IsZero() {
+		return
+	}
+	if a.byMinute == nil {
+		a.byMinute = map[int64]usageBucket{}
+	}
+	minute := at.Truncate(time.Minute).Unix()
+	b := a.byMinute[minute]
+	b.add(usageBucket{messages: 1, usage: u, costUSD: costUSD})
+	a.byMinute[minute] = b
+}
+
+// replaceUsageBuckets rewrites one transcript's usage buckets. Only Claude
+// Code's usage counts against a Claude subscription, so another agent's
+// transcript writes none.
+func replaceUsageBuckets(ctx context.Context, tx execer, df diskFile, buckets map[int64]usageBucket) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM claude_usage_bucket WHERE file_path = ?`, df.filePath); err != nil {
+		return err
+	}
+	if df.agent() != CodingAgentClaudeCode {
+		return nil
+	}
+	for minute, b := range buckets {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO claude_usage_bucket
+				(file_path, config_dir, minute, messages, input_tokens, output_tokens,
+				 cache_creation_tokens, cache_creation_5m_tokens, cache_creation_1h_tokens,
+				 cache_read_tokens, cost_usd)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			df.filePath, df.configDir, minute, b.messages, b.usage.InputTokens, b.usage.OutputTokens,
+			b.usage.CacheCreationTokens, b.usage.CacheCreation5mTokens, b.usage.CacheCreation1hTokens,
+			b.usage.CacheReadTokens, b.costUSD); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ── Reconstruction ──────────────────────────────────────────────────────────
+
+// usageMinute is one minute of a config dir's usage, summed over its
+// transcripts.
+type usageMinute struct {
+	at time.Time
+	usageBucket
+}
+
+// loadUsageMinutes reads every config dir's usage since a time, by minute in
+// ascending order. Hidden projects are included: the subscription counts them.
+func loadUsageMinutes(ctx context.Context, db *sql.DB, since time.Time) (map[string][]usageMinute, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT config_dir, minute, SUM(messages), SUM(input_tokens), SUM(output_tokens),
+		       SUM(cache_creation_tokens), SUM(cache_creation_5m_tokens), SUM(cache_creation_1h_tokens),
+		       SUM(cache_read_tokens), SUM(cost_usd)
+		FROM claude_usage_bucket
+		WHERE minute >= ?
+		GROUP BY config_dir, minute
+		ORDER BY config_dir, minute`, since.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("reading usage buckets: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	out := map[string][]usageMinute{}
+	for rows.Next() {
+		var dir string
+		var minute int64
+		var m usageMinute
+		if err := rows.Scan(&dir, &minute, &m.messages, &m.usage.InputTokens, &m.usage.OutputTokens,
+			&m.usage.CacheCreationTokens, &m.usage.CacheCreation5mTokens, &m.usage.CacheCreation1hTokens,
+			&m.usage.CacheReadTokens, &m.costUSD); err != nil {
+			return nil, fmt.Errorf("reading usage buckets: %w", err)
+		}
+		// A blank dir is the default one; see config.IsIndexedClaudeDir.
+		if dir == "" {
+			dir = config.DefaultClaudeConfigDir()
+		}
+		m.at = time.Unix(minute, 0)
+		out[dir] = append(out[dir], m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading usage buckets: %w", err)
+	}
+	return out, nil
+}
+
+// usageSpan is one reconstructed window.
+type usageSpan struct {
+	start, end time.Time
+	usageBucket
+}
+
+// usageSpans splits a dir's usage into back-to-back windows of a length. A
+// window opens with the first message after the last one closed, on the hour
+// that message was sent in, as Claude's do, and closes length later.
+func usageSpans(minutes []usageMinute, length time.Duration) []usageSpan {
+	var spans []usageSpan
+	for _, m := range minutes {
+		if n := len(spans); n == 0 || !m.at.Before(spans[n-1].end) {
+			start := m.at.Truncate(time.Hour)
+			spans = append(spans, usageSpan{start: start, end: start.Add(length)})
+		}
+		spans[len(spans)-1].add(m.usageBucket)
+	}
+	return spans
+}
+
+// usageWindow reports a dir's current window of a kind as of now.
+func usageWindow(kind UsageWindowKind, minutes []usageMinute, now time.Time) UsageWindow {
+	length := fiveHourWindow
+	if kind == UsageWindowWeekly {
+		length = weeklyWindow
+	}
+	w := UsageWindow{Kind: kind}
+	var peak float64
+	for _, s := range usageSpans(minutes, length) {
+		if s.end.After(now) {
+			w.Active = true
+			w.StartedAt, w.ResetsAt = s.start, s.end
+			w.Messages, w.Usage, w.CostUSD = s.messages, s.usage, s.costUSD
+			continue
+		}
+		w.CompletedWindows++
+		peak = max(peak, s.costUSD)
+	}
+
+	configured, _ := usageWindowPolicy(kind)
+	switch {
+	case configured > 0:
+		w.LimitUSD, w.LimitSource = configured, UsageLimitConfigured
+	case w.CompletedWindows >= minLearnedWindows && peak > 0:
+		w.LimitUSD, w.LimitSource = peak, UsageLimitLearned
+	}
+	w.project(now)
+	return w
+}
+
+// project fills in the share of the limit used, and — at the window's pace so
+// far — the share used by its reset and when the limit is reached. The pace is
+// a straight line from the window's start: a run rate the user can check in
+// their head, not a fitted trend.
+func (w *UsageWindow) project(now time.Time) {
+	if !w.Active || w.LimitUSD <= 0 {
+		return
+	}
+	w.UsedPercent = w.CostUSD / w.LimitUSD * 100
+	w.ProjectedPercent = w.UsedPercent
+	elapsed := now.Sub(w.StartedAt).Seconds()
+	if elapsed <= 0 || w.CostUSD <= 0 {
+		return
+	}
+	rate := w.CostUSD / elapsed
+	projected := w.CostUSD + rate*w.ResetsAt.Sub(now).Seconds()
+	w.ProjectedPercent = projected / w.LimitUSD * 100
+	if w.CostUSD < w.LimitUSD && projected >= w.LimitUSD {
+		at := now.Add(time.Duration((w.LimitUSD - w.CostUSD) / rate * float64(time.Second))).Truncate(time.Minute)
+		w.LimitAt = &at
+	}
+}
+
+// usageWindows reports the usage windows of every indexed config dir as of
+// now, in ClaudeConfigDirs order. A dir with no recent usage has a meter with
+// no window open.
+func usageWindows(ctx context.Context, db *sql.DB, now time.Time) (UsageWindowsReport, error) {
+	byDir, err := loadUsageMinutes(ctx, db, now.Add(-usageWindowHistory))
+	if err != nil {
+		return UsageWindowsReport{}, err
+	}
+	_, warn := usageWindowPolicy(UsageWindowFiveHour)
+	report := UsageWindowsReport{GeneratedAt: now, WarnPercent: warn, Meters: []UsageMeter{}}
+	for _, dir := range config.ClaudeConfigDirs() {
+		report.Meters = append(report.Meters, UsageMeter{
+			ConfigDir: dir,
+			FiveHour:  usageWindow(UsageWindowFiveHour, byDir[dir], now),
+			Weekly:    usageWindow(UsageWindowWeekly, byDir[dir], now),
+		})
+	}
+	return report, nil
+}
+
+// UsageWindows reports each indexed config dir's current 5-hour and weekly
+// subscription usage windows, estimated from the cached usage.
+func (c *Cache) UsageWindows(ctx context.Context) (UsageWindowsReport, error) {
+	return usageWindows(ctx, c.db, time.Now())
+}
+
+// ── Warning ─────────────────────────────────────────────────────────────────
+
+// alertUsageWindows publishes EventUsageLimitApproaching when a config dir's
+// current window passes the warning threshold, and again when it reaches its
+// limit, once each per window. As with anomalies, the published set is not
+// persisted, so a restart can repeat a warning for a window still open.
+func (c *Cache) alertUsageWindows() {
+	if c.bus == nil {
+		return
+	}
+	report, err := usageWindows(context.Background(), c.db, time.Now())
+	if err != nil {
+		c.logger.Warn("claude sessions: usage windows unavailable", "error", err)
+		return
+	}
+	for _, m := range report.Meters {
+		for _, w := range []UsageWindow{m.FiveHour, m.Weekly} {
+			level := usageAlertLevel(w, report.WarnPercent)
+			if level == "" {
+				continue
+			}
+			key := "window:" + m.ConfigDir + ":" + string(w.Kind) + ":" + w.StartedAt.Format(time.RFC3339) + ":" + level
+			c.mu.Lock()
+			_, seen := c.alerted[key]
+			c.alerted[key] = struct{}{}
+			c.mu.Unlock()
+			if seen {
+				continue
+			}
+			c.bus.Publish(eventbus.EventUsageLimitApproaching, usageWindowPayload(m.ConfigDir, w, level))
+		}
+	}
+}
+
+// usageAlertLevel returns the level a window has reached, or "" when it has
+// not reached the warning threshold or its limit is not known.
+func usageAlertLevel(w UsageWindow, warnPercent int) string {
+	switch {
+	case !w.Active || w.LimitUSD <= 0:
+		return ""
+	case w.UsedPercent >= 100:
+		return usageLevelLimit
+	case w.UsedPercent >= float64(warnPercent):
+		return usageLevelWarning
+	}
+	return ""
+}
+
+// usageWindowPayload renders a window as an event payload.
+func usageWindowPayload(configDir string, w UsageWindow, level string) map[string]string {
+	return map[string]string{
+		eventbus.PayloadKeyConfigDir:   configDir,
+		eventbus.PayloadKeyWindow:      string(w.Kind),
+		eventbus.PayloadKeyLevel:       level,
+		eventbus.PayloadKeyUsedPercent: fmt.Sprintf("%.0f", w.UsedPercent),
+		eventbus.PayloadKeyValue:       fmt.Sprintf("%.2f", w.CostUSD),
+		eventbus.PayloadKeyLimit:       fmt.Sprintf("%.2f", w.LimitUSD),
+		eventbus.PayloadKeyLimitSource: w.LimitSource,
+		eventbus.PayloadKeyResetsAt:    w.ResetsAt.Local().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,160 @@
+package claudesessions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/eventbus"
+)
+
+func usageAt(at time.Time, costUSD float64) usageMinute {
+	return usageMinute{at: at, usageBucket: usageBucket{
+		messages: 1, usage: TokenUsage{OutputTokens: 100}, costUSD: costUSD,
+	}}
+}
+
+func useUsageWindowSettings(t *testing.T, fiveHourUSD, weeklyUSD float64, warnPercent int) {
+	t.Helper()
+	ApplyUsageWindowSettings(fiveHourUSD, weeklyUSD, warnPercent)
+	t.Cleanup(func() { ApplyUsageWindowSettings(0, 0, 0) })
+}
+
+func TestUsageWindow_ReconstructsWindowsAndLearnsTheLimit(t *testing.T) {
+	day := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	minutes := []usageMinute{
+		usageAt(day.Add(9*time.Hour+10*time.Minute), 10),
+		usageAt(day.Add(13*time.Hour+50*time.Minute), 5),
+		// The first message after 14:00 opens the next window.
+		usageAt(day.Add(14*time.Hour+5*time.Minute), 2),
+		usageAt(day.Add(32*time.Hour+30*time.Minute), 20),
+		usageAt(day.Add(58*time.Hour+15*time.Minute), 4),
+	}
+	now := day.Add(59*time.Hour + 15*time.Minute)
+
+	w := usageWindow(UsageWindowFiveHour, minutes, now)
+	if !w.Active || !w.StartedAt.Equal(day.Add(58*time.Hour)) || !w.ResetsAt.Equal(day.Add(63*time.Hour)) {
+		t.Fatalf("window = %+v, want the one opened on the hour of the last message", w)
+	}
+	// Three windows completed, the heaviest at $20.
+	if w.CompletedWindows != 3 || w.LimitUSD != 20 || w.LimitSource != UsageLimitLearned {
+		t.Errorf("limit = $%v (%q) from %d windows, want the $20 peak of 3", w.LimitUSD, w.LimitSource, w.CompletedWindows)
+	}
+	if w.UsedPercent != 20 || w.CostUSD != 4 || w.Messages != 1 {
+		t.Errorf("used %v%% ($%v, %d messages), want 20%% ($4, 1)", w.UsedPercent, w.CostUSD, w.Messages)
+	}
+	// $4 in 75 minutes carried over the 225 left is $16, under the limit.
+	if w.ProjectedPercent != 80 || w.LimitAt != nil {
+		t.Errorf("projected %v%%, limit at %v; want 80%% and never", w.ProjectedPercent, w.LimitAt)
+	}
+
+	// A configured limit wins, and the same pace now reaches it early.
+	useUsageWindowSettings(t, 10, 0, 0)
+	w = usageWindow(UsageWindowFiveHour, minutes, now)
+	if w.LimitSource != UsageLimitConfigured || w.UsedPercent != 40 {
+		t.Errorf("configured window = %+v", w)
+	}
+	if w.LimitAt == nil || !w.LimitAt.Equal(day.Add(61*time.Hour+7*time.Minute)) {
+		t.Errorf("limit at %v, want 13:07 on the third day", w.LimitAt)
+	}
+
+	// The week holds every message so far, and no week has completed to
+	// learn from.
+	weekly := usageWindow(UsageWindowWeekly, minutes, now)
+	if !weekly.Active || weekly.CostUSD != 41 || weekly.LimitSource != "" || weekly.UsedPercent != 0 {
+		t.Errorf("weekly window = %+v", weekly)
+	}
+}
+
+func TestUsageWindow_NoWindowOpen(t *testing.T) {
+	at := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+	w := usageWindow(UsageWindowFiveHour, []usageMinute{usageAt(at, 3)}, at.Add(6*time.Hour))
+	if w.Active || w.CostUSD != 0 || w.CompletedWindows != 1 || w.LimitSource != "" {
+		t.Errorf("window = %+v, want none open and too little history for a limit", w)
+	}
+}
+
+func TestIncrementalScan_RecordsClaudeUsageByMinute(t *testing.T) {
+	home := t.TempDir()
+	codexHome := t.TempDir()
+	at := time.Date(2026, 10, 5, 9, 20, 0, 0, time.UTC)
+	fp := writeSessionIn(t, filepath.Join(home, ".claude"), "-home-dev-work", "work-1", at)
+	writeCodexRollout(t, codexHome, "0199a1b2-c3d4-7e5f", "/home/dev/api", at)
+	useConfigDirs(t, home)
+	InstallTranscriptSources(NewCodexSource(codexHome))
+	t.Cleanup(func() { InstallTranscriptSources() })
+
+	c := newScanCache(t)
+	if _, err := IncrementalScan(c.db, testLogger); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+
+	report, err := usageWindows(context.Background(), c.db, at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("usage windows: %v", err)
+	}
+	if len(report.Meters) != 1 || report.Meters[0].ConfigDir != filepath.Join(home, ".claude") {
+		t.Fatalf("meters = %+v, want the one config dir", report.Meters)
+	}
+	// Codex's usage is not Claude's, and is not counted against it.
+	w := report.Meters[0].FiveHour
+	if !w.Active || w.Messages != 1 || w.Usage.InputTokens != 10 || w.Usage.OutputTokens != 20 || w.CostUSD <= 0 {
+		t.Errorf("five-hour window = %+v, want the session's one priced reply", w)
+	}
+	if report.WarnPercent != 80 {
+		t.Errorf("warn percent = %d, want the default", report.WarnPercent)
+	}
+
+	// A deleted transcript takes its usage with it.
+	if err := os.Remove(fp); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := IncrementalScan(c.db, testLogger); err != nil {
+		t.Fatalf("rescan: %v", err)
+	}
+	var rows int
+	if err := c.db.QueryRow(`SELECT COUNT(*) FROM claude_usage_bucket`).Scan(&rows); err != nil || rows != 0 {
+		t.Errorf("usage buckets after delete = %d (%v), want none", rows, err)
+	}
+}
+
+func TestCache_AlertUsageWindowsOncePerLevel(t *testing.T) {
+	home := t.TempDir()
+	useConfigDirs(t, home)
+	useUsageWindowSettings(t, 10, 0, 0)
+
+	bus := &recordingBus{}
+	c := newScanCache(t).WithEventBus(bus)
+	df := diskFile{filePath: "/sessions/a.jsonl", configDir: filepath.Join(home, ".claude")}
+	minute := time.Now().Add(-30 * time.Minute).Truncate(time.Minute).Unix()
+	write := func(costUSD float64) {
+		t.Helper()
+		buckets := map[int64]usageBucket{minute: {messages: 1, costUSD: costUSD}}
+		if err := replaceUsageBuckets(context.Background(), c.db, df, buckets); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(9)
+	c.alertUsageWindows()
+	c.alertUsageWindows()
+	write(12)
+	c.alertUsageWindows()
+
+	got := bus.published()
+	if len(got) != 2 {
+		t.Fatalf("published %d events, want a warning and a limit: %+v", len(got), got)
+	}
+	for i, level := range []string{usageLevelWarning, usageLevelLimit} {
+		p := got[i].Payload
+		if got[i].Type != eventbus.EventUsageLimitApproaching || p[eventbus.PayloadKeyLevel] != level ||
+			p[eventbus.PayloadKeyWindow] != string(UsageWindowFiveHour) || p[eventbus.PayloadKeyLimit] != "10.00" {
+			t.Errorf("event %d = %+v, want the five-hour %s", i, got[i], level)
+		}
+	}
+	if got[0].Payload[eventbus.PayloadKeyUsedPercent] != "90" {
+		t.Errorf("warning used_percent = %q, want 90", got[0].Payload[eventbus.PayloadKeyUsedPercent])
+	}
+}
@@ -32,6 +32,98 @@ type AgentCapabilities struct {
 	BuiltIn []string          `yaml:"built_in" json:"built_in"`
 	Local   []string          `yaml:"local"    json:"local"`
 	MCP     map[string]MCPCap `yaml:"mcp"      json:"mcp"`
+	// RequireApproval lists the tools a person must approve before each call.
+	// An entry ending in "*" matches every tool name with that prefix, e.g.
+	// "mcp__github__*". Unattended runs ask over the configured approval
+	// channel; chat sessions ask in the browser.
+	RequireApproval []string `yaml:"require_approval" json:"require_approval,omitempty"`
+	// Policy holds rules on the arguments of tool calls, evaluated before
+	// each call. See ToolPolicy.
+	Policy *ToolPolicy `yaml:"policy" json:"policy,omitempty"`
+}
+
+// RequiresApproval reports whether calls to toolName need a person's approval.
+func (c AgentCapabilities) RequiresApproval(toolName string) bool {
+	for _, pattern := range c.RequireApproval {
+		if MatchToolName(pattern, toolName) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPolicy reports whether the agent declares any tool policy rules.
+func (c AgentCapabilities) HasPolicy() bool {
+	return c.Policy != nil && len(c.Policy.Rules) > 0
+}
+
+// MayRequireApproval reports whether any call may need a person's approval:
+// a tool is listed under require_approval, or an enforced policy rule asks.
+func (c AgentCapabilities) MayRequireApproval() bool {
+	if len(c.RequireApproval) > 0 {
+		return true
+	}
+	if c.Policy == nil || c.Policy.DryRun {
+		return false
+	}
+	for _, r := range c.Policy.Rules {
+		if r.Action == PolicyAsk {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchToolName reports whether toolName matches pattern: exactly, or by
+// prefix when pattern ends in "*".
+func MatchToolName(pattern, toolName string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(toolName, prefix)
+	}
+	return pattern == toolName
+}
+
+// Tool policy actions.
+const (
+	PolicyAllow = "allow"
+	PolicyDeny  = "deny"
+	PolicyAsk   = "ask"
+)
+
+// ToolPolicy is an ordered list of rules on tool arguments. The first rule
+// matching a call decides it; a call no rule matches is treated as if the
+// agent had no policy. In dry-run mode decisions are only logged, which is
+// how a new policy is tuned before it is enforced.
+type ToolPolicy struct {
+	DryRun bool         `yaml:"dry_run" json:"dry_run,omitempty"`
+	Rules  []PolicyRule `yaml:"rules"   json:"rules"`
+}
+
+// PolicyRule matches calls to Tool whose arguments meet every condition set
+// on the rule, and applies Action to them. A rule with no conditions matches
+// every call to the tool.
+type PolicyRule struct {
+	// Tool is a tool name; a trailing "*" matches by prefix.
+	Tool string `yaml:"tool" json:"tool"`
+	// Action is "allow", "deny" or "ask". Asking routes the call to a person:
+	// the browser in chats, the approval channel in unattended runs.
+	Action string `yaml:"action" json:"action"`
+	// Command is a regular expression matched against a Bash command.
+	Command string `yaml:"command,omitempty" json:"command,omitempty"`
+	// Paths are globs matched against the file or directory a file tool
+	// works on. "**" spans directories and a leading "~/" is the home
+	// directory.
+	Paths []string `yaml:"paths,omitempty" json:"paths,omitempty"`
+	// Hosts are matched against the host of a WebFetch URL; "*.example.com"
+	// matches any subdomain of example.com.
+	Hosts []string `yaml:"hosts,omitempty" json:"hosts,omitempty"`
+	// Params maps a tool parameter, typically of an MCP tool, to a regular
+	// expression its value must match. Values that are not strings are
+	// matched as JSON.
+	Params map[string]string `yaml:"params,omitempty" json:"params,omitempty"`
+	// Reason is shown to the agent when the rule denies a call, and to the
+	// approver when it asks.
+	Reason string `yaml:"reason,omitempty" json:"reason,omitempty"`
 }
 
 // MCPCap specifies which tools from an MCP server an agent may use.
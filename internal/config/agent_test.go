@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentCapabilities_RequiresApproval(t *testing.T) {
+	caps := AgentCapabilities{RequireApproval: []string{"Bash", "mcp__github__*"}}
+
+	assert.True(t, caps.RequiresApproval("Bash"))
+	assert.True(t, caps.RequiresApproval("mcp__github__create_issue"))
+	assert.False(t, caps.RequiresApproval("BashOutput"), "names without a * match exactly")
+	assert.False(t, caps.RequiresApproval("mcp__gitlab__create_issue"))
+	assert.False(t, AgentCapabilities{}.RequiresApproval("Bash"))
+}
+
+func TestAgentCapabilities_MayRequireApproval(t *testing.T) {
+	askBash := &ToolPolicy{Rules: []PolicyRule{{Tool: "Bash", Action: PolicyAsk}}}
+
+	assert.False(t, AgentCapabilities{}.MayRequireApproval())
+	assert.True(t, AgentCapabilities{RequireApproval: []string{"Bash"}}.MayRequireApproval())
+	assert.True(t, AgentCapabilities{Policy: askBash}.MayRequireApproval())
+	assert.False(t, AgentCapabilities{Policy: &ToolPolicy{DryRun: true, Rules: askBash.Rules}}.MayRequireApproval(),
+		"a dry run never asks")
+	assert.False(t, AgentCapabilities{Policy: &ToolPolicy{
+		Rules: []PolicyRule{{Tool: "Bash", Action: PolicyDeny}},
+	}}.MayRequireApproval())
+}
@@ -50,6 +50,14 @@ type AppConfig struct {
 	// Required for webhook registration (e.g. Telegram inbound triggers).
 	// When set via env var, it takes precedence over the settings-stored value.
 	PublicURL string `envconfig:"AGENTO_PUBLIC_URL"`
+
+	// SecretKeyFile is a file holding the passphrase or key that integration
+	// credentials are encrypted with. It takes precedence over the OS keyring.
+	SecretKeyFile string `envconfig:"AGENTO_SECRET_KEY_FILE"`
+
+	// SecretPassphrase is the passphrase integration credentials are
+	// encrypted with, for hosts without an OS keyring.
+	SecretPassphrase string `envconfig:"AGENTO_SECRET_PASSPHRASE"`
 }
 
 // Load reads AppConfig from environment variables using envconfig.
@@ -125,6 +133,29 @@ func (c *AppConfig) DatabasePath() string {
 	return filepath.Join(c.DataDir, "agento.db")
 }
 
+// KeysDir returns the path to the directory that holds the credential
+// encryption key when no OS keyring is available.
+func (c *AppConfig) KeysDir() string {
+	return filepath.Join(c.DataDir, "keys")
+}
+
+// BackupsDir returns the default directory for backups.
+func (c *AppConfig) BackupsDir() string {
+	return filepath.Join(c.DataDir, "backups")
+}
+
+// TranscriptsDir returns the directory that holds the archived copies of
+// Claude Code transcripts.
+func (c *AppConfig) TranscriptsDir() string {
+	return filepath.Join(c.DataDir, "transcripts")
+}
+
+// HookTokenPath returns the file holding the API token the Claude Code hook
+// command authenticates with.
+func (c *AppConfig) HookTokenPath() string {
+	return filepath.Join(c.DataDir, "hook-token")
+}
+
 // TmpUploadsDir returns the path to the temporary uploads directory.
 // Files here are cleaned up at startup (files older than 24 hours are removed).
 func (c *AppConfig) TmpUploadsDir() string {
@@ -45,6 +45,10 @@ func TestAppConfig_DirectoryPaths(t *testing.T) {
 		{"MCPsFile", c.MCPsFile, "/data/mcps.yaml"},
 		{"IntegrationsDir", c.IntegrationsDir, "/data/integrations"},
 		{"DatabasePath", c.DatabasePath, "/data/agento.db"},
+		{"KeysDir", c.KeysDir, "/data/keys"},
+		{"BackupsDir", c.BackupsDir, "/data/backups"},
+		{"TranscriptsDir", c.TranscriptsDir, "/data/transcripts"},
+		{"HookTokenPath", c.HookTokenPath, "/data/hook-token"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -53,8 +53,85 @@ type UserSettings struct {
 	// authenticates as exactly one account by definition. The default dir and
 	// ClaudeConfigDir are always indexed and need not be listed here.
 	ClaudeConfigDirs []string `json:"claude_config_dirs"`
+
+	// BackupIntervalHours is how often `agento web` writes an automatic
+	// backup. Zero turns automatic backups off.
+	BackupIntervalHours int `json:"backup_interval_hours"`
+	// BackupKeep is how many automatic backups are kept; older ones are
+	// deleted. Zero means DefaultBackupKeep.
+	BackupKeep int `json:"backup_keep"`
+	// BackupDir is where backups are written. Empty means the backups
+	// directory under the data directory.
+	BackupDir string `json:"backup_dir"`
+	// BackupIncludeCredentials keeps integration credentials in automatic
+	// backups. They stay encrypted, and restoring them needs the same key.
+	BackupIncludeCredentials bool `json:"backup_include_credentials"`
+
+	// TranscriptArchiveDisabled stops Agento keeping its own copy of Claude
+	// Code transcripts. Stored negated so the archive is on for a settings row
+	// that predates it.
+	TranscriptArchiveDisabled bool `json:"transcript_archive_disabled"`
+	// TranscriptArchiveRetentionDays is how long an archived session whose
+	// original Claude Code has deleted is kept, counted from its last activity.
+	// Zero keeps it forever.
+	TranscriptArchiveRetentionDays int `json:"transcript_archive_retention_days"`
+	// TranscriptArchiveMaxMB caps the archive's size on disk. Past it, the
+	// oldest sessions whose originals are gone are dropped first. Zero means
+	// no cap.
+	TranscriptArchiveMaxMB int `json:"transcript_archive_max_mb"`
+
+	// ImportedAgents are the coding agents besides Claude Code whose session
+	// logs are indexed too, by their ImportedAgent* name. Empty indexes Claude
+	// Code alone. Removing one hides its sessions rather than deleting them,
+	// as removing a config dir does.
+	ImportedAgents []string `json:"imported_agents"`
+
+	// UsageLimitFiveHourUSD and UsageLimitWeeklyUSD are how much a Claude
+	// subscription's 5-hour and weekly usage windows allow, in API-equivalent
+	// dollars. Anthropic does not publish either, so zero estimates each from
+	// the heaviest window on record.
+	UsageLimitFiveHourUSD float64 `json:"usage_limit_five_hour_usd"`
+	UsageLimitWeeklyUSD   float64 `json:"usage_limit_weekly_usd"`
+	// UsageWarnPercent is how much of a usage window may be used before Agento
+	// warns. Zero means DefaultUsageWarnPercent.
+	UsageWarnPercent int `json:"usage_warn_percent"`
+}
+
+// Bounds for the automatic backup settings.
+const (
+	DefaultBackupKeep      = 7
+	MaxBackupKeep          = 365
+	MaxBackupIntervalHours = 24 * 30
+)
+
+// The coding agents UserSettings.ImportedAgents can name.
+const (
+	ImportedAgentCodex  = "codex"
+	ImportedAgentGemini = "gemini"
+	ImportedAgentAider  = "aider"
+)
+
+// IsImportableAgent reports whether name is a coding agent Agento can index.
+func IsImportableAgent(name string) bool {
+	switch name {
+	case ImportedAgentCodex, ImportedAgentGemini, ImportedAgentAider:
+		return true
+	}
+	return false
 }
 
+// Bounds for the transcript archive settings.
+const (
+	MaxTranscriptArchiveRetentionDays = 3650
+	MaxTranscriptArchiveMaxMB         = 1 << 20
+)
+
+// Bounds for the subscription usage window settings.
+const (
+	DefaultUsageWarnPercent = 80
+	MaxUsageLimitUSD        = 100_000
+)
+
 // Bounds for UserSettings.IdleGapThresholdMinutes, defined here because this
 // is the package every layer may import; claudesessions.IdleGapThreshold
 // documents what the value means and is the only place it is interpreted.
@@ -205,6 +282,67 @@ func validateIdleGapThreshold(minutes int) error {
 	return nil
 }
 
+// validateBackupSettings rejects an out-of-range schedule or a relative
+// backup directory. Zero values mean off and the default respectively.
+func validateBackupSettings(s UserSettings) error {
+	if s.BackupIntervalHours < 0 || s.BackupIntervalHours > MaxBackupIntervalHours {
+		return fmt.Errorf("backup_interval_hours must be between 0 and %d, got %d",
+			MaxBackupIntervalHours, s.BackupIntervalHours)
+	}
+	if s.BackupKeep < 0 || s.BackupKeep > MaxBackupKeep {
+		return fmt.Errorf("backup_keep must be between 0 and %d, got %d", MaxBackupKeep, s.BackupKeep)
+	}
+	if s.BackupDir != "" && !filepath.IsAbs(s.BackupDir) {
+		return fmt.Errorf("backup_dir must be an absolute path, got %q", s.BackupDir)
+	}
+	return nil
+}
+
+// validateTranscriptArchiveSettings rejects an out-of-range retention or size
+// cap. Zero means keep forever and no cap respectively.
+func validateTranscriptArchiveSettings(s UserSettings) error {
+	if s.TranscriptArchiveRetentionDays < 0 || s.TranscriptArchiveRetentionDays > MaxTranscriptArchiveRetentionDays {
+		return fmt.Errorf("transcript_archive_retention_days must be between 0 and %d, got %d",
+			MaxTranscriptArchiveRetentionDays, s.TranscriptArchiveRetentionDays)
+	}
+	if s.TranscriptArchiveMaxMB < 0 || s.TranscriptArchiveMaxMB > MaxTranscriptArchiveMaxMB {
+		return fmt.Errorf("transcript_archive_max_mb must be between 0 and %d, got %d",
+			MaxTranscriptArchiveMaxMB, s.TranscriptArchiveMaxMB)
+	}
+	return nil
+}
+
+// validateUsageWindowSettings rejects a negative or implausible window limit
+// and a warning threshold that is not a percentage. Zero means estimate and
+// the default respectively.
+func validateUsageWindowSettings(s UserSettings) error {
+	for _, l := range []struct {
+		name  string
+		value float64
+	}{
+		{"usage_limit_five_hour_usd", s.UsageLimitFiveHourUSD},
+		{"usage_limit_weekly_usd", s.UsageLimitWeeklyUSD},
+	} {
+		if l.value < 0 || l.value > MaxUsageLimitUSD {
+			return fmt.Errorf("%s must be between 0 and %d, got %g", l.name, MaxUsageLimitUSD, l.value)
+		}
+	}
+	if s.UsageWarnPercent < 0 || s.UsageWarnPercent > 100 {
+		return fmt.Errorf("usage_warn_percent must be between 0 and 100, got %d", s.UsageWarnPercent)
+	}
+	return nil
+}
+
+// validateImportedAgents rejects an agent Agento has no reader for.
+func validateImportedAgents(agents []string) error {
+	for _, a := range agents {
+		if !IsImportableAgent(a) {
+			return fmt.Errorf("imported_agents: unknown coding agent %q", a)
+		}
+	}
+	return nil
+}
+
 // validateClaudeConfigDirs rejects a run dir or an indexed dir that cannot be
 // one. A blank run dir is allowed and means "use the default"; blank entries in
 // the list are dropped rather than rejected, so a half-filled row in the UI is
@@ -282,6 +420,18 @@ func (m *SettingsManager) Update(incoming UserSettings) error {
 	if err := validateClaudeConfigDirs(incoming, m.settings); err != nil {
 		return err
 	}
+	if err := validateBackupSettings(incoming); err != nil {
+		return err
+	}
+	if err := validateTranscriptArchiveSettings(incoming); err != nil {
+		return err
+	}
+	if err := validateImportedAgents(incoming.ImportedAgents); err != nil {
+		return err
+	}
+	if err := validateUsageWindowSettings(incoming); err != nil {
+		return err
+	}
 
 	incoming.ClaudeConfigDir = NormalizeClaudeConfigDir(incoming.ClaudeConfigDir)
 	incoming.ClaudeConfigDirs = normalizeClaudeConfigDirs(incoming.ClaudeConfigDirs)
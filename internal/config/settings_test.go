@@ -317,6 +317,77 @@ func TestSettingsManager_Update(t *testing.T) {
 			incoming:      config.UserSettings{IdleGapThresholdMinutes: 241},
 			wantErr:       "idle_gap_threshold_minutes must be between 1 and 240 minutes",
 		},
+		{
+			name:          "backup settings round-trip",
+			storeSettings: config.UserSettings{},
+			cfg:           &config.AppConfig{},
+			incoming: config.UserSettings{
+				BackupIntervalHours: 24, BackupKeep: 14, BackupDir: "/mnt/backups",
+			},
+			wantSaved: &config.UserSettings{
+				BackupIntervalHours: 24, BackupKeep: 14, BackupDir: "/mnt/backups",
+			},
+		},
+		{
+			name:          "backup interval above the bound is rejected",
+			storeSettings: config.UserSettings{},
+			cfg:           &config.AppConfig{},
+			incoming:      config.UserSettings{BackupIntervalHours: 721},
+			wantErr:       "backup_interval_hours must be between 0 and 720",
+		},
+		{
+			name:          "relative backup directory is rejected",
+			storeSettings: config.UserSettings{},
+			cfg:           &config.AppConfig{},
+			incoming:      config.UserSettings{BackupDir: "backups"},
+			wantErr:       "backup_dir must be an absolute path",
+		},
+		{
+			name:          "transcript archive settings round-trip",
+			storeSettings: config.UserSettings{},
+			cfg:           &config.AppConfig{},
+			incoming: config.UserSettings{
+				TranscriptArchiveDisabled: true, TranscriptArchiveRetentionDays: 365, TranscriptArchiveMaxMB: 2048,
+			},
+			wantSaved: &config.UserSettings{
+				TranscriptArchiveDisabled: true, TranscriptArchiveRetentionDays: 365, TranscriptArchiveMaxMB: 2048,
+			},
+		},
+		{
+			name:          "negative transcript archive retention is rejected",
+			storeSettings: config.UserSettings{},
+			cfg:           &config.AppConfig{},
+			incoming:      config.UserSettings{TranscriptArchiveRetentionDays: -1},
+			wantErr:       "transcript_archive_retention_days must be between 0 and 3650",
+		},
+		{
+			name:          "imported agents round-trip",
+			storeSettings: config.UserSettings{},
+			cfg:           &config.AppConfig{},
+			incoming:      config.UserSettings{ImportedAgents: []string{"codex", "aider"}},
+			wantSaved:     &config.UserSettings{ImportedAgents: []string{"codex", "aider"}},
+		},
+		{
+			name:          "unknown imported agent is rejected",
+			storeSettings: config.UserSettings{},
+			cfg:           &config.AppConfig{},
+			incoming:      config.UserSettings{ImportedAgents: []string{"cursor"}},
+			wantErr:       `imported_agents: unknown coding agent "cursor"`,
+		},
+		{
+			name:          "usage window settings round-trip",
+			storeSettings: config.UserSettings{},
+			cfg:           &config.AppConfig{},
+			incoming:      config.UserSettings{UsageLimitFiveHourUSD: 40, UsageWarnPercent: 90},
+			wantSaved:     &config.UserSettings{UsageLimitFiveHourUSD: 40, UsageWarnPercent: 90},
+		},
+		{
+			name:          "usage warning past 100 percent is rejected",
+			storeSettings: config.UserSettings{},
+			cfg:           &config.AppConfig{},
+			incoming:      config.UserSettings{UsageWarnPercent: 120},
+			wantErr:       "usage_warn_percent must be between 0 and 100",
+		},
 		{
 			name:          "save error is wrapped and returned",
 			storeSettings: config.UserSettings{},
@@ -2,6 +2,29 @@ package config
 
 import "time"
 
+// Response modes for generic webhook trigger rules.
+const (
+	// WebhookResponseSync returns the agent's answer as the HTTP response.
+	WebhookResponseSync = "sync"
+	// WebhookResponseCallback acknowledges with 202 and POSTs the answer to
+	// the rule's CallbackURL when the run finishes.
+	WebhookResponseCallback = "callback"
+)
+
+// Conversation modes for Telegram trigger rules.
+const (
+	// TriggerConversationChat gives each Telegram chat one ongoing agent
+	// session.
+	TriggerConversationChat = "chat"
+	// TriggerConversationThread gives each reply thread or forum topic its
+	// own session; messages outside a thread share the chat's.
+	TriggerConversationThread = "thread"
+)
+
+// DefaultConversationIdleMinutes is how long a Telegram conversation waits
+// for its next message before that message starts a fresh one.
+const DefaultConversationIdleMinutes = 60
+
 // TriggerRule defines a rule that matches incoming messages to an agent.
 type TriggerRule struct {
 	ID             string    `json:"id"`
@@ -14,4 +37,51 @@ type TriggerRule struct {
 	FilterChatIDs  []string  `json:"filter_chat_ids"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+
+	// The fields below apply to rules on a Telegram integration.
+
+	// Conversation is TriggerConversationChat or TriggerConversationThread to
+	// resume one agent session across messages. Empty runs every message in
+	// a fresh session.
+	Conversation string `json:"conversation,omitempty"`
+	// ConversationIdleMinutes ends a conversation after this long without a
+	// message. Zero means DefaultConversationIdleMinutes.
+	ConversationIdleMinutes int `json:"conversation_idle_minutes,omitempty"`
+
+	// PromptTemplate and Variables apply to generic webhook and GitHub rules.
+
+	// PromptTemplate is interpolated with Variables and {{body}}. Empty sends
+	// the raw request body (webhook) or an event summary (GitHub).
+	PromptTemplate string `json:"prompt_template,omitempty"`
+	// Variables maps a template variable name to a JSONPath into the request
+	// body, e.g. "status": "$.alerts[0].status".
+	Variables map[string]string `json:"variables,omitempty"`
+
+	// The fields below apply to rules on a generic webhook integration.
+
+	// WebhookSecret signs requests to the rule's URL and its callbacks.
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+	// ResponseMode is WebhookResponseSync or WebhookResponseCallback.
+	ResponseMode string `json:"response_mode,omitempty"`
+	CallbackURL  string `json:"callback_url,omitempty"`
+	// WebhookURL is derived from the public URL when the rule is read; it is
+	// not stored.
+	WebhookURL string `json:"webhook_url,omitempty"`
+
+	// The fields below filter rules on a GitHub integration. Each matches
+	// everything when empty and otherwise passes if any entry matches.
+
+	// FilterEvents lists event names ("pull_request") or event.action pairs
+	// ("pull_request.opened").
+	FilterEvents []string `json:"filter_events,omitempty"`
+	// FilterRepos lists "owner/name" repositories.
+	FilterRepos []string `json:"filter_repos,omitempty"`
+	// FilterLabels lists labels, at least one of which the issue or pull
+	// request must carry.
+	FilterLabels []string `json:"filter_labels,omitempty"`
+	// FilterAuthors lists GitHub logins of the issue, pull request or push author.
+	FilterAuthors []string `json:"filter_authors,omitempty"`
+	// FilterBranches lists branch globs ("main", "release/*"). A pull request
+	// matches on its base branch.
+	FilterBranches []string `json:"filter_branches,omitempty"`
 }
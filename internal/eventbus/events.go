@@ -13,3 +13,37 @@ const (
 	PayloadKeyFilePath    = "file_path"
 	PayloadKeyProjectPath = "project_path"
 )
+
+// EventUsageAnomalyDetected is published when a day or a session of Claude
+// Code usage costs or consumes far more than its recent baseline. Each
+// anomaly is published once per process.
+const EventUsageAnomalyDetected = "claude.usage.anomaly_detected"
+
+// Payload keys used in usage anomaly events, alongside PayloadKeySessionID
+// and PayloadKeyProjectPath for a session anomaly.
+const (
+	PayloadKeyScope    = "scope"
+	PayloadKeyMetric   = "metric"
+	PayloadKeyDate     = "date"
+	PayloadKeyValue    = "value"
+	PayloadKeyBaseline = "baseline"
+	PayloadKeyScore    = "score"
+)
+
+// EventUsageLimitApproaching is published when a Claude config dir's current
+// 5-hour or weekly subscription usage window passes the warning threshold,
+// and again when it reaches its limit. Each is published once per window per
+// process.
+const EventUsageLimitApproaching = "claude.usage.limit_approaching"
+
+// Payload keys used in usage limit events, alongside PayloadKeyValue for the
+// window's API-equivalent cost so far.
+const (
+	PayloadKeyConfigDir   = "config_dir"
+	PayloadKeyWindow      = "window"
+	PayloadKeyLevel       = "level"
+	PayloadKeyUsedPercent = "used_percent"
+	PayloadKeyLimit       = "limit"
+	PayloadKeyLimitSource = "limit_source"
+	PayloadKeyResetsAt    = "resets_at"
+)
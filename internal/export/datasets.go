@@ -0,0 +1,305 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/claudesessions"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// Dataset names a table that can be exported.
+type Dataset string
+
+// The exportable datasets.
+const (
+	// DatasetSessions is one row per Claude Code session, with token and cost
+	// totals that include delegated sub-agent work.
+	DatasetSessions Dataset = "sessions"
+	// DatasetSessionModels is one row per session and model, splitting each
+	// session's cost by the model that spent it. It is the long form of the
+	// sessions table's cost columns, which is the shape a pivot wants.
+	DatasetSessionModels Dataset = "session_models"
+	// DatasetInsights is one row per session with processed insight metrics.
+	DatasetInsights Dataset = "insights"
+	// DatasetJobs is one row per scheduled task run.
+	DatasetJobs Dataset = "jobs"
+	// DatasetChats is one row per Agento chat, with its cumulative usage.
+	DatasetChats Dataset = "chats"
+)
+
+// Datasets lists every dataset, in the order help text shows them.
+var Datasets = []Dataset{DatasetSessions, DatasetSessionModels, DatasetInsights, DatasetJobs, DatasetChats}
+
+// ParseDataset validates a dataset name.
+func ParseDataset(s string) (Dataset, error) {
+	for _, d := range Datasets {
+		if string(d) == s {
+			return d, nil
+		}
+	}
+	names := make([]string, len(Datasets))
+	for i, d := range Datasets {
+		names[i] = string(d)
+	}
+	return "", fmt.Errorf("unknown dataset %q (want one of %s)", s, strings.Join(names, ", "))
+}
+
+var sessionColumns = []Column{
+	{Name: "session_id", Type: TypeString},
+	{Name: "project_path", Type: TypeString},
+	{Name: "config_dir", Type: TypeString},
+	{Name: "title", Type: TypeString},
+	{Name: "start_time", Type: TypeTime},
+	{Name: "last_activity", Type: TypeTime},
+	{Name: "active_duration_ms", Type: TypeInt},
+	{Name: "message_count", Type: TypeInt},
+	{Name: "event_count", Type: TypeInt},
+	{Name: "model", Type: TypeString},
+	{Name: "git_branch", Type: TypeString},
+	{Name: "permission_mode", Type: TypeString},
+	{Name: "subagent_count", Type: TypeInt},
+	{Name: "compaction_count", Type: TypeInt},
+	{Name: "input_tokens", Type: TypeInt},
+	{Name: "output_tokens", Type: TypeInt},
+	{Name: "cache_creation_tokens", Type: TypeInt},
+	{Name: "cache_read_tokens", Type: TypeInt},
+	{Name: "input_cost_usd", Type: TypeFloat},
+	{Name: "output_cost_usd", Type: TypeFloat},
+	{Name: "cache_read_cost_usd", Type: TypeFloat},
+	{Name: "cache_write_cost_usd", Type: TypeFloat},
+	{Name: "cost_usd", Type: TypeFloat},
+	{Name: "is_favorite", Type: TypeBool},
+	{Name: "pr_urls", Type: TypeString},
+}
+
+// SessionsTable builds the sessions dataset. Tokens, cost and active time
+// are the session's totals including sub-agents — the figures analytics
+// reports — rather than the main-thread-only fields the list shows.
+func SessionsTable(sessions []claudesessions.ClaudeSessionSummary) Table {
+	t := Table{Columns: sessionColumns, Rows: make([][]any, 0, len(sessions))}
+	for _, s := range sessions {
+		u := s.TotalUsage()
+		c := s.TotalCost()
+		prs := make([]string, len(s.PRs))
+		for i, pr := range s.PRs {
+			prs[i] = pr.PRURL
+		}
+		t.Rows = append(t.Rows, []any{
+			s.SessionID, s.ProjectPath, s.ConfigDir, s.DisplayTitle,
+			s.StartTime, s.LastActivity, s.TotalActiveDurationMs(),
+			int64(s.MessageCount), int64(s.EventCount),
+			s.Model, s.GitBranch, s.PermissionMode,
+			int64(s.SubagentCount), int64(s.CompactionCount),
+			int64(u.InputTokens), int64(u.OutputTokens), int64(u.CacheCreationTokens), int64(u.CacheReadTokens),
+			c.InputUSD, c.OutputUSD, c.CacheReadUSD, c.CacheWriteUSD, c.TotalUSD,
+			s.IsFavorite, strings.Join(prs, " "),
+		})
+	}
+	return t
+}
+
+var sessionModelColumns = []Column{
+	{Name: "session_id", Type: TypeString},
+	{Name: "project_path", Type: TypeString},
+	{Name: "last_activity", Type: TypeTime},
+	{Name: "model", Type: TypeString},
+	{Name: "input_cost_usd", Type: TypeFloat},
+	{Name: "output_cost_usd", Type: TypeFloat},
+	{Name: "cache_read_cost_usd", Type: TypeFloat},
+	{Name: "cache_write_cost_usd", Type: TypeFloat},
+	{Name: "cost_usd", Type: TypeFloat},
+}
+
+// SessionModelsTable builds the session_models dataset from each session's
+// TotalCostByModel, so a session's rows sum to its cost_usd in the sessions
+// table.
+func SessionModelsTable(sessions []claudesessions.ClaudeSessionSummary) Table {
+	t := Table{Columns: sessionModelColumns, Rows: [][]any{}}
+	for _, s := range sessions {
+		byModel := s.TotalCostByModel()
+		models := make([]string, 0, len(byModel))
+		for m := range byModel {
+			models = append(models, m)
+		}
+		sort.Strings(models)
+		for _, m := range models {
+			c := byModel[m]
+			t.Rows = append(t.Rows, []any{
+				s.SessionID, s.ProjectPath, s.LastActivity, m,
+				c.InputUSD, c.OutputUSD, c.CacheReadUSD, c.CacheWriteUSD, c.TotalUSD,
+			})
+		}
+	}
+	return t
+}
+
+var insightColumns = []Column{
+	{Name: "session_id", Type: TypeString},
+	{Name: "project_path", Type: TypeString},
+	{Name: "last_activity", Type: TypeTime},
+	{Name: "processor_version", Type: TypeInt},
+	{Name: "scanned_at", Type: TypeTime},
+	{Name: "turn_count", Type: TypeInt},
+	{Name: "steps_per_turn_avg", Type: TypeFloat},
+	{Name: "autonomy_score", Type: TypeFloat},
+	{Name: "tool_calls_total", Type: TypeInt},
+	{Name: "tool_error_count", Type: TypeInt},
+	{Name: "tool_error_rate", Type: TypeFloat},
+	{Name: "has_errors", Type: TypeBool},
+	{Name: "unattributed_calls", Type: TypeInt},
+	{Name: "total_duration_ms", Type: TypeInt},
+	{Name: "active_duration_ms", Type: TypeInt},
+	{Name: "claude_working_time_ms", Type: TypeInt},
+	{Name: "cache_hit_rate", Type: TypeFloat},
+	{Name: "tokens_per_turn_avg", Type: TypeFloat},
+	{Name: "cost_estimate_usd", Type: TypeFloat},
+	{Name: "max_consecutive_tool_calls", Type: TypeInt},
+	{Name: "longest_autonomous_chain", Type: TypeInt},
+	{Name: "avg_user_response_time_ms", Type: TypeFloat},
+	{Name: "avg_claude_response_time_ms", Type: TypeFloat},
+	{Name: "session_type", Type: TypeString},
+	{Name: "tool_breakdown", Type: TypeString},
+	{Name: "skill_breakdown", Type: TypeString},
+	{Name: "plugin_breakdown", Type: TypeString},
+	{Name: "mcp_server_breakdown", Type: TypeString},
+	{Name: "mcp_tool_breakdown", Type: TypeString},
+	{Name: "effort_breakdown", Type: TypeString},
+	{Name: "agent_breakdown", Type: TypeString},
+}
+
+// InsightsTable builds the insights dataset for sessions, in their order.
+// Sessions without a processed insight are left out. The breakdown maps are
+// JSON objects in a string column: flattening them would give every export a
+// different set of columns, depending on which tools happened to be used.
+func InsightsTable(sessions []claudesessions.ClaudeSessionSummary, insights []*claudesessions.SessionInsight) Table {
+	byID := make(map[string]*claudesessions.SessionInsight, len(insights))
+	for _, in := range insights {
+		byID[in.SessionID] = in
+	}
+
+	t := Table{Columns: insightColumns, Rows: [][]any{}}
+	for _, s := range sessions {
+		in, ok := byID[s.SessionID]
+		if !ok {
+			continue
+		}
+		t.Rows = append(t.Rows, []any{
+			s.SessionID, s.ProjectPath, s.LastActivity,
+			int64(in.ProcessorVersion), in.ScannedAt,
+			int64(in.TurnCount), in.StepsPerTurnAvg, in.AutonomyScore,
+			int64(in.ToolCallsTotal), int64(in.ToolErrorCount), in.ToolErrorRate, in.HasErrors,
+			int64(in.UnattributedCalls),
+			in.TotalDurationMs, in.ActiveDurationMs, in.ClaudeWorkingTimeMs,
+			in.CacheHitRate, in.TokensPerTurnAvg, in.CostEstimateUSD,
+			int64(in.MaxConsecutiveToolCalls), int64(in.LongestAutonomousChain),
+			in.AvgUserResponseTimeMs, in.AvgClaudeResponseTimeMs,
+			in.SessionType,
+			breakdownJSON(in.ToolBreakdown), breakdownJSON(in.SkillBreakdown),
+			breakdownJSON(in.PluginBreakdown), breakdownJSON(in.McpServerBreakdown),
+			breakdownJSON(in.McpToolBreakdown), breakdownJSON(in.EffortBreakdown),
+			breakdownJSON(in.AgentBreakdown),
+		})
+	}
+	return t
+}
+
+// breakdownJSON encodes a count map as a JSON object, "{}" when empty.
+func breakdownJSON(m map[string]int) string {
+	if len(m) == 0 {
+		return "{}"
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+var jobColumns = []Column{
+	{Name: "id", Type: TypeString},
+	{Name: "task_id", Type: TypeString},
+	{Name: "task_name", Type: TypeString},
+	{Name: "agent_slug", Type: TypeString},
+	{Name: "status", Type: TypeString},
+	{Name: "started_at", Type: TypeTime},
+	{Name: "finished_at", Type: TypeTime, Nullable: true},
+	{Name: "duration_ms", Type: TypeInt},
+	{Name: "attempt", Type: TypeInt},
+	{Name: "retry_of", Type: TypeString},
+	{Name: "chat_session_id", Type: TypeString},
+	{Name: "model", Type: TypeString},
+	{Name: "input_tokens", Type: TypeInt},
+	{Name: "output_tokens", Type: TypeInt},
+	{Name: "cache_creation_tokens", Type: TypeInt},
+	{Name: "cache_read_tokens", Type: TypeInt},
+	{Name: "error_message", Type: TypeString},
+}
+
+// JobsTable builds the jobs dataset. Prompts and responses are left out:
+// they are content rather than usage, and can be large.
+func JobsTable(jobs []*storage.JobHistory) Table {
+	t := Table{Columns: jobColumns, Rows: make([][]any, 0, len(jobs))}
+	for _, j := range jobs {
+		var finished any
+		if j.FinishedAt != nil {
+			finished = *j.FinishedAt
+		}
+		t.Rows = append(t.Rows, []any{
+			j.ID, j.TaskID, j.TaskName, j.AgentSlug, string(j.Status),
+			j.StartedAt, finished, j.DurationMS, int64(j.Attempt), j.RetryOf,
+			j.ChatSessionID, j.Model,
+			int64(j.TotalInputTokens), int64(j.TotalOutputTokens),
+			int64(j.TotalCacheCreationTokens), int64(j.TotalCacheReadTokens),
+			j.ErrorMessage,
+		})
+	}
+	return t
+}
+
+var chatColumns = []Column{
+	{Name: "id", Type: TypeString},
+	{Name: "title", Type: TypeString},
+	{Name: "agent_slug", Type: TypeString},
+	{Name: "model", Type: TypeString},
+	{Name: "working_directory", Type: TypeString},
+	{Name: "sdk_session_id", Type: TypeString},
+	{Name: "created_at", Type: TypeTime},
+	{Name: "updated_at", Type: TypeTime},
+	{Name: "input_tokens", Type: TypeInt},
+	{Name: "output_tokens", Type: TypeInt},
+	{Name: "cache_creation_tokens", Type: TypeInt},
+	{Name: "cache_read_tokens", Type: TypeInt},
+	{Name: "is_favorite", Type: TypeBool},
+}
+
+// ChatsTable builds the chats dataset.
+func ChatsTable(chats []*storage.ChatSession) Table {
+	t := Table{Columns: chatColumns, Rows: make([][]any, 0, len(chats))}
+	for _, c := range chats {
+		t.Rows = append(t.Rows, []any{
+			c.ID, c.Title, c.AgentSlug, c.Model, c.WorkingDir, c.SDKSession,
+			c.CreatedAt, c.UpdatedAt,
+			int64(c.TotalInputTokens), int64(c.TotalOutputTokens),
+			int64(c.TotalCacheCreationTokens), int64(c.TotalCacheReadTokens),
+			c.IsFavorite,
+		})
+	}
+	return t
+}
+
+// overlaps reports whether [start, end] overlaps the query's From/To window,
+// the same definition SessionQuery uses for sessions. Windows are not
+// applied: they are a drill-down from a sessions chart.
+func overlaps(q claudesessions.SessionQuery, start, end time.Time) bool {
+	if q.From != nil && end.Before(*q.From) {
+		return false
+	}
+	if q.To != nil && start.After(*q.To) {
+		return false
+	}
+	return true
+}
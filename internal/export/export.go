@@ -0,0 +1,144 @@
+// Package export writes Agento's analytics and session data as flat tables in
+// CSV, JSON Lines and Parquet, for analysis in a spreadsheet, a notebook or a
+// warehouse.
+//
+// Every dataset is first built as a Table — named, typed columns and rows of
+// plain values — and only then encoded, so the three formats always carry the
+// same columns in the same order and a figure cannot differ between a CSV and
+// the Parquet file exported beside it.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format is an output encoding.
+type Format string
+
+// The supported formats.
+const (
+	FormatCSV     Format = "csv"
+	FormatJSONL   Format = "jsonl"
+	FormatParquet Format = "parquet"
+)
+
+// ParseFormat validates a format name. "json" and "ndjson" are accepted as
+// JSON Lines, since that is what anyone asking for them from a row exporter
+// means.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", string(FormatCSV):
+		return FormatCSV, nil
+	case string(FormatJSONL), "json", "ndjson":
+		return FormatJSONL, nil
+	case string(FormatParquet):
+		return FormatParquet, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want csv, jsonl or parquet)", s)
+}
+
+// ContentType is the MIME type an HTTP response in f should declare.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// FileName is the conventional name for dataset d exported in f.
+func (f Format) FileName(d Dataset) string {
+	return "agento-" + string(d) + "." + string(f)
+}
+
+// ColumnType is the type of every value in a column.
+type ColumnType int
+
+// The column types. Each maps to one Go type in a row: string, int64,
+// float64, bool and time.Time respectively.
+const (
+	TypeString ColumnType = iota
+	TypeInt
+	TypeFloat
+	TypeBool
+	TypeTime
+)
+
+// Column describes one column of a Table.
+type Column struct {
+	Name string
+	Type ColumnType
+	// Nullable columns may hold nil, written as an empty CSV field, a JSON
+	// null or a Parquet null. A nil in any other column is an error.
+	Nullable bool
+}
+
+// Table is a dataset ready to encode. Each row holds one value per column,
+// in column order.
+type Table struct {
+	Columns []Column
+	Rows    [][]any
+}
+
+// Write encodes t to w in format f.
+//
+// The table is validated first, so a builder that put the wrong type in a
+// column fails before any byte is written rather than leaving a truncated
+// file behind.
+func Write(w io.Writer, f Format, t Table) error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+	switch f {
+	case FormatCSV:
+		return writeCSV(w, t)
+	case FormatJSONL:
+		return writeJSONL(w, t)
+	case FormatParquet:
+		return writeParquet(w, t)
+	}
+	return fmt.Errorf("unknown format %q", f)
+}
+
+func (t Table) validate() error {
+	for i, row := range t.Rows {
+		if len(row) != len(t.Columns) {
+			return fmt.Errorf("row %d has %d values for %d columns", i, len(row), len(t.Columns))
+		}
+		for j, v := range row {
+			c := t.Columns[j]
+			if v == nil {
+				if !c.Nullable {
+					return fmt.Errorf("row %d: column %q is not nullable", i, c.Name)
+				}
+				continue
+			}
+			if !c.Type.accepts(v) {
+				return fmt.Errorf("row %d: column %q holds %T", i, c.Name, v)
+			}
+		}
+	}
+	return nil
+}
+
+func (ct ColumnType) accepts(v any) bool {
+	switch v.(type) {
+	case string:
+		return ct == TypeString
+	case int64:
+		return ct == TypeInt
+	case float64:
+		return ct == TypeFloat
+	case bool:
+		return ct == TypeBool
+	case time.Time:
+		return ct == TypeTime
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleTable() Table {
+	at := time.Date(2026, 8, 15, 9, 30, 0, 0, time.FixedZone("CEST", 2*3600))
+	return Table{
+		Columns: []Column{
+			{Name: "id", Type: TypeString},
+			{Name: "tokens", Type: TypeInt},
+			{Name: "cost_usd", Type: TypeFloat},
+			{Name: "favorite", Type: TypeBool},
+			{Name: "finished_at", Type: TypeTime, Nullable: true},
+		},
+		Rows: [][]any{
+			{"a", int64(1200), 0.25, true, at},
+			{`b, "quoted"`, int64(0), 0.0, false, nil},
+		},
+	}
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, sampleTable()); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,tokens,cost_usd,favorite,finished_at\n" +
+		"a,1200,0.25,true,2026-08-15T07:30:00Z\n" +
+		`"b, ""quoted""",0,0,false,` + "\n"
+	if buf.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWrite_JSONL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSONL, sampleTable()); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":"a","tokens":1200,"cost_usd":0.25,"favorite":true,"finished_at":"2026-08-15T07:30:00Z"}` + "\n" +
+		`{"id":"b, \"quoted\"","tokens":0,"cost_usd":0,"favorite":false,"finished_at":null}` + "\n"
+	if buf.String() != want {
+		t.Errorf("jsonl =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+// TestWrite_RejectsMistypedRows checks a table is validated before anything
+// is written, so a builder bug fails loudly instead of leaving half a file.
+func TestWrite_RejectsMistypedRows(t *testing.T) {
+	tests := []struct {
+		name string
+		row  []any
+		want string
+	}{
+		{name: "wrong type", row: []any{"a", 1200, 0.25, true, nil}, want: `"tokens" holds int`},
+		{name: "null in a required column", row: []any{nil, int64(1), 0.0, true, nil}, want: `"id" is not nullable`},
+		{name: "short row", row: []any{"a"}, want: "1 values for 5 columns"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			table := sampleTable()
+			table.Rows = append(table.Rows, tc.row)
+			var buf bytes.Buffer
+			err := Write(&buf, FormatCSV, table)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want it to mention %s", err, tc.want)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("wrote %d bytes before failing", buf.Len())
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{
+		"": FormatCSV, "CSV": FormatCSV, "jsonl": FormatJSONL, "ndjson": FormatJSONL, "parquet": FormatParquet,
+	} {
+		got, err := ParseFormat(in)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xlsx"); err == nil {
+		t.Error("expected an error for xlsx")
+	}
+}
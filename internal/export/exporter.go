@@ -0,0 +1,176 @@
+package export
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shaharia-lab/agento/internal/claudesessions"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// SessionLister pages through the indexed Claude Code sessions.
+// *claudesessions.Cache implements it.
+type SessionLister interface {
+	ListPage(q claudesessions.SessionQuery) (claudesessions.SessionPage, error)
+}
+
+// InsightGetter loads processed insights by session ID.
+type InsightGetter interface {
+	GetMany(ctx context.Context, sessionIDs []string) ([]*claudesessions.SessionInsight, error)
+}
+
+// JobHistoryLister pages through scheduled task runs, newest first.
+type JobHistoryLister interface {
+	ListAllJobHistory(ctx context.Context, limit, offset int) ([]*storage.JobHistory, error)
+}
+
+// ChatLister lists Agento chats.
+type ChatLister interface {
+	ListSessions(ctx context.Context) ([]*storage.ChatSession, error)
+}
+
+// Sources are the stores an Exporter reads. A nil source makes the datasets
+// that need it unavailable rather than empty.
+type Sources struct {
+	Sessions SessionLister
+	Insights InsightGetter
+	Jobs     JobHistoryLister
+	Chats    ChatLister
+}
+
+// Exporter builds dataset tables from the stores. The web server and the
+// `agento export` command share it, so a file is the same whichever produced
+// it.
+type Exporter struct {
+	src Sources
+}
+
+// NewExporter returns an Exporter reading from src.
+func NewExporter(src Sources) *Exporter {
+	return &Exporter{src: src}
+}
+
+// jobPageSize is how many job history rows are read per query.
+const jobPageSize = 500
+
+// Table builds dataset d.
+//
+// The session datasets honor every filter in q, exactly as the sessions list
+// does — including hidden projects, which the list's query applies itself —
+// so an export of a filtered list is that list. q's sort, limit and cursor are
+// ignored: an export is every matching row. The job and chat datasets are not
+// sessions and honor only q's From/To; chats in a hidden project are left out
+// too, by their working directory.
+func (e *Exporter) Table(ctx context.Context, d Dataset, q claudesessions.SessionQuery) (Table, error) {
+	switch d {
+	case DatasetSessions, DatasetSessionModels, DatasetInsights:
+		sessions, err := e.sessions(q)
+		if err != nil {
+			return Table{}, err
+		}
+		switch d {
+		case DatasetSessions:
+			return SessionsTable(sessions), nil
+		case DatasetSessionModels:
+			return SessionModelsTable(sessions), nil
+		}
+		return e.insights(ctx, sessions)
+	case DatasetJobs:
+		return e.jobs(ctx, q)
+	case DatasetChats:
+		return e.chats(ctx, q)
+	}
+	return Table{}, fmt.Errorf("unknown dataset %q", d)
+}
+
+// sessions reads every session matching q, a page at a time.
+func (e *Exporter) sessions(q claudesessions.SessionQuery) ([]claudesessions.ClaudeSessionSummary, error) {
+	if e.src.Sessions == nil {
+		return nil, fmt.Errorf("session index not available")
+	}
+	q.Limit = claudesessions.MaxPageSize
+	q.Cursor = ""
+	var out []claudesessions.ClaudeSessionSummary
+	for {
+		page, err := e.src.Sessions.ListPage(q)
+		if err != nil {
+			return nil, fmt.Errorf("listing sessions: %w", err)
+		}
+		out = append(out, page.Items...)
+		if !page.HasMore {
+			return out, nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+func (e *Exporter) insights(ctx context.Context, sessions []claudesessions.ClaudeSessionSummary) (Table, error) {
+	if e.src.Insights == nil {
+		return Table{}, fmt.Errorf("session insights not available")
+	}
+	var insights []*claudesessions.SessionInsight
+	if len(sessions) > 0 {
+		ids := make([]string, len(sessions))
+		for i, s := range sessions {
+			ids[i] = s.SessionID
+		}
+		var err error
+		insights, err = e.src.Insights.GetMany(ctx, ids)
+		if err != nil {
+			return Table{}, fmt.Errorf("loading insights: %w", err)
+		}
+	}
+	return InsightsTable(sessions, insights), nil
+}
+
+func (e *Exporter) jobs(ctx context.Context, q claudesessions.SessionQuery) (Table, error) {
+	if e.src.Jobs == nil {
+		return Table{}, fmt.Errorf("job history not available")
+	}
+	var jobs []*storage.JobHistory
+	// A run that starts while this pages would shift the offsets by one and
+	// repeat a row, so rows are deduplicated by ID.
+	seen := map[string]struct{}{}
+	for offset := 0; ; offset += jobPageSize {
+		page, err := e.src.Jobs.ListAllJobHistory(ctx, jobPageSize, offset)
+		if err != nil {
+			return Table{}, fmt.Errorf("listing job history: %w", err)
+		}
+		for _, j := range page {
+			if _, dup := seen[j.ID]; dup {
+				continue
+			}
+			seen[j.ID] = struct{}{}
+			end := j.StartedAt
+			if j.FinishedAt != nil {
+				end = *j.FinishedAt
+			}
+			if overlaps(q, j.StartedAt, end) {
+				jobs = append(jobs, j)
+			}
+		}
+		if len(page) < jobPageSize {
+			return JobsTable(jobs), nil
+		}
+	}
+}
+
+func (e *Exporter) chats(ctx context.Context, q claudesessions.SessionQuery) (Table, error) {
+	if e.src.Chats == nil {
+		return Table{}, fmt.Errorf("chats not available")
+	}
+	all, err := e.src.Chats.ListSessions(ctx)
+	if err != nil {
+		return Table{}, fmt.Errorf("listing chats: %w", err)
+	}
+	chats := make([]*storage.ChatSession, 0, len(all))
+	for _, c := range all {
+		if c.WorkingDir != "" && claudesessions.IsProjectHidden(c.WorkingDir) {
+			continue
+		}
+		if overlaps(q, c.CreatedAt, c.UpdatedAt) {
+			chats = append(chats, c)
+		}
+	}
+	return ChatsTable(chats), nil
+}
@@ -0,0 +1,137 @@
+package export
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/claudesessions"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// pagedSessions serves items in pages of two, recording the queries it saw.
+type pagedSessions struct {
+	items   []claudesessions.ClaudeSessionSummary
+	queries []claudesessions.SessionQuery
+}
+
+func (p *pagedSessions) ListPage(q claudesessions.SessionQuery) (claudesessions.SessionPage, error) {
+	p.queries = append(p.queries, q)
+	start := 0
+	if q.Cursor != "" {
+		start, _ = strconv.Atoi(q.Cursor)
+	}
+	end := min(start+2, len(p.items))
+	page := claudesessions.SessionPage{Items: p.items[start:end]}
+	if end < len(p.items) {
+		page.NextCursor, page.HasMore = strconv.Itoa(end), true
+	}
+	return page, nil
+}
+
+type fakeInsights []*claudesessions.SessionInsight
+
+func (f fakeInsights) GetMany(context.Context, []string) ([]*claudesessions.SessionInsight, error) {
+	return f, nil
+}
+
+type fakeJobs []*storage.JobHistory
+
+func (f fakeJobs) ListAllJobHistory(_ context.Context, limit, offset int) ([]*storage.JobHistory, error) {
+	if offset >= len(f) {
+		return nil, nil
+	}
+	return f[offset:min(offset+limit, len(f))], nil
+}
+
+func TestExporter_SessionsPagesThroughTheFilteredList(t *testing.T) {
+	src := &pagedSessions{}
+	for i := range 5 {
+		src.items = append(src.items, claudesessions.ClaudeSessionSummary{
+			SessionID: "s" + strconv.Itoa(i),
+			CostByModel: map[string]claudesessions.SessionCost{
+				"opus": {TotalUSD: 1}, "haiku": {TotalUSD: 0.5},
+			},
+		})
+	}
+	e := NewExporter(Sources{Sessions: src})
+
+	q := claudesessions.SessionQuery{Project: "/a", Limit: 10, Cursor: "stale"}
+	table, err := e.Table(context.Background(), DatasetSessions, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 5 || table.Rows[4][0] != "s4" {
+		t.Errorf("rows = %v, want all five sessions", table.Rows)
+	}
+	if len(src.queries) != 3 {
+		t.Fatalf("ListPage called %d times, want 3", len(src.queries))
+	}
+	first := src.queries[0]
+	if first.Project != "/a" || first.Cursor != "" || first.Limit != claudesessions.MaxPageSize {
+		t.Errorf("first query = %+v, want the filter kept, the cursor reset and the largest page", first)
+	}
+
+	models, err := e.Table(context.Background(), DatasetSessionModels, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models.Rows) != 10 || models.Rows[0][3] != "haiku" || models.Rows[1][8] != 1.0 {
+		t.Errorf("session_models rows = %v, want two per session, models sorted", models.Rows)
+	}
+}
+
+func TestExporter_InsightsFollowTheSessionOrder(t *testing.T) {
+	src := &pagedSessions{items: []claudesessions.ClaudeSessionSummary{
+		{SessionID: "b", ProjectPath: "/b"}, {SessionID: "none"}, {SessionID: "a", ProjectPath: "/a"},
+	}}
+	insights := fakeInsights{
+		{SessionID: "a", TurnCount: 3, ToolBreakdown: map[string]int{"Bash": 2}},
+		{SessionID: "b", TurnCount: 7},
+	}
+	table, err := NewExporter(Sources{Sessions: src, Insights: insights}).
+		Table(context.Background(), DatasetInsights, claudesessions.SessionQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 2 || table.Rows[0][0] != "b" || table.Rows[1][1] != "/a" {
+		t.Fatalf("rows = %v, want b then a, without the unprocessed session", table.Rows)
+	}
+	if got := table.Rows[1][24]; got != `{"Bash":2}` {
+		t.Errorf("tool_breakdown = %v", got)
+	}
+}
+
+func TestExporter_JobsHonorTheWindow(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 8, d, 12, 0, 0, 0, time.UTC) }
+	var jobs fakeJobs
+	for i := range jobPageSize + 10 {
+		jobs = append(jobs, &storage.JobHistory{ID: "j" + strconv.Itoa(i), StartedAt: day(20)})
+	}
+	finished := day(15)
+	jobs = append(jobs,
+		&storage.JobHistory{ID: "spans", StartedAt: day(9), FinishedAt: &finished},
+		&storage.JobHistory{ID: "before", StartedAt: day(1)},
+	)
+	from, to := day(10), day(25)
+
+	table, err := NewExporter(Sources{Jobs: jobs}).
+		Table(context.Background(), DatasetJobs, claudesessions.SessionQuery{From: &from, To: &to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != jobPageSize+11 {
+		t.Fatalf("rows = %d, want every job overlapping the window", len(table.Rows))
+	}
+	if last := table.Rows[len(table.Rows)-1]; last[0] != "spans" || last[6] != finished {
+		t.Errorf("last row = %v, want the job that started before the window and ran into it", last)
+	}
+}
+
+func TestExporter_MissingSource(t *testing.T) {
+	if _, err := NewExporter(Sources{}).
+		Table(context.Background(), DatasetChats, claudesessions.SessionQuery{}); err == nil {
+		t.Error("expected an error for a dataset with no source")
+	}
+}
@@ -0,0 +1,260 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// writeParquet writes t as a Parquet file: one row group, one uncompressed
+// PLAIN-encoded data page per column, and a footer describing them.
+//
+// That is the simplest file the format allows, and every reader — pandas,
+// Polars, DuckDB, Spark, BigQuery — reads it. Compression and dictionary
+// pages would shrink it, but an export is read once into a tool that
+// re-encodes it, and the sizes here are a few megabytes at most: the table is
+// built in memory already.
+//
+// Columns map to physical types as string → BYTE_ARRAY (UTF8), int →
+// INT64, float → DOUBLE, bool → BOOLEAN and time → INT64 (TIMESTAMP_MILLIS,
+// UTC). Nullable columns are OPTIONAL, the rest REQUIRED.
+func writeParquet(w io.Writer, t Table) error {
+	var out bytes.Buffer
+	out.WriteString(parquetMagic)
+
+	var chunks []parquetChunk
+	if len(t.Rows) > 0 {
+		chunks = make([]parquetChunk, len(t.Columns))
+		for j, c := range t.Columns {
+			page := encodeParquetPage(t, j)
+			header := parquetPageHeader(len(page), len(t.Rows))
+			chunks[j] = parquetChunk{
+				offset: int64(out.Len()),
+				size:   int64(len(header) + len(page)),
+				column: c,
+			}
+			out.Write(header)
+			out.Write(page)
+		}
+	}
+
+	footer := parquetFooter(t, chunks)
+	out.Write(footer)
+	out.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	out.WriteString(parquetMagic)
+
+	if _, err := w.Write(out.Bytes()); err != nil {
+		return fmt.Errorf("writing parquet: %w", err)
+	}
+	return nil
+}
+
+const parquetMagic = "PAR1"
+
+// Parquet enum values, from parquet.thrift.
+const (
+	pqBoolean   = 0
+	pqInt64     = 2
+	pqDouble    = 5
+	pqByteArray = 6
+
+	pqRequired = 0
+	pqOptional = 1
+
+	pqUTF8            = 0
+	pqTimestampMillis = 9
+
+	pqPlain = 0
+	pqRLE   = 3
+
+	pqDataPage     = 0
+	pqUncompressed = 0
+)
+
+// parquetChunk locates one column's data page in the file.
+type parquetChunk struct {
+	offset int64
+	size   int64
+	column Column
+}
+
+func parquetPhysicalType(ct ColumnType) int32 {
+	switch ct {
+	case TypeInt, TypeTime:
+		return pqInt64
+	case TypeFloat:
+		return pqDouble
+	case TypeBool:
+		return pqBoolean
+	default:
+		return pqByteArray
+	}
+}
+
+// encodeParquetPage encodes column j's definition levels, when it has them,
+// followed by its non-null values.
+func encodeParquetPage(t Table, j int) []byte {
+	col := t.Columns[j]
+	var page []byte
+	if col.Nullable {
+		levels := make([]bool, len(t.Rows))
+		for i, row := range t.Rows {
+			levels[i] = row[j] != nil
+		}
+		rle := encodeDefinitionLevels(levels)
+		page = binary.LittleEndian.AppendUint32(page, uint32(len(rle)))
+		page = append(page, rle...)
+	}
+
+	if col.Type == TypeBool {
+		// PLAIN booleans are bit-packed, least significant bit first.
+		var bits []byte
+		n := 0
+		for _, row := range t.Rows {
+			if row[j] == nil {
+				continue
+			}
+			if n%8 == 0 {
+				bits = append(bits, 0)
+			}
+			if row[j].(bool) {
+				bits[n/8] |= 1 << (n % 8)
+			}
+			n++
+		}
+		return append(page, bits...)
+	}
+
+	for _, row := range t.Rows {
+		switch v := row[j].(type) {
+		case string:
+			page = binary.LittleEndian.AppendUint32(page, uint32(len(v)))
+			page = append(page, v...)
+		case int64:
+			page = binary.LittleEndian.AppendUint64(page, uint64(v))
+		case float64:
+			page = binary.LittleEndian.AppendUint64(page, math.Float64bits(v))
+		case time.Time:
+			page = binary.LittleEndian.AppendUint64(page, uint64(v.UnixMilli()))
+		}
+	}
+	return page
+}
+
+// encodeDefinitionLevels run-length encodes a flat column's definition
+// levels (1 = present, 0 = null) in the RLE/bit-packing hybrid at bit width
+// one: each run is a varint of its length shifted left once, then the level
+// in one byte.
+func encodeDefinitionLevels(present []bool) []byte {
+	var out []byte
+	for i := 0; i < len(present); {
+		j := i
+		for j < len(present) && present[j] == present[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		if present[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i = j
+	}
+	return out
+}
+
+func parquetPageHeader(size, numValues int) []byte {
+	var w compactWriter
+	w.beginStruct()
+	w.i32(1, pqDataPage)
+	w.i32(2, int32(size))
+	w.i32(3, int32(size))
+	w.structField(5)
+	w.i32(1, int32(numValues))
+	w.i32(2, pqPlain)
+	w.i32(3, pqRLE)
+	w.i32(4, pqRLE)
+	w.endStruct()
+	w.endStruct()
+	return w.buf
+}
+
+// parquetFooter encodes the FileMetaData struct: the schema, then a row group
+// over chunks, or none for an empty table.
+func parquetFooter(t Table, chunks []parquetChunk) []byte {
+	var w compactWriter
+	w.beginStruct()
+	w.i32(1, 1) // format version
+
+	w.listField(2, ctStruct, len(t.Columns)+1)
+	w.beginStruct()
+	w.binary(4, "schema")
+	w.i32(5, int32(len(t.Columns)))
+	w.endStruct()
+	for _, c := range t.Columns {
+		w.beginStruct()
+		w.i32(1, parquetPhysicalType(c.Type))
+		if c.Nullable {
+			w.i32(3, pqOptional)
+		} else {
+			w.i32(3, pqRequired)
+		}
+		w.binary(4, c.Name)
+		switch c.Type {
+		case TypeString:
+			w.i32(6, pqUTF8)
+		case TypeTime:
+			w.i32(6, pqTimestampMillis)
+		}
+		w.endStruct()
+	}
+
+	w.i64(3, int64(len(t.Rows)))
+
+	if len(chunks) == 0 {
+		w.listField(4, ctStruct, 0)
+	} else {
+		w.listField(4, ctStruct, 1)
+		w.beginStruct()
+		var total int64
+		w.listField(1, ctStruct, len(chunks))
+		for _, ch := range chunks {
+			total += ch.size
+			writeParquetColumnChunk(&w, ch, len(t.Rows))
+		}
+		w.i64(2, total)
+		w.i64(3, int64(len(t.Rows)))
+		w.endStruct()
+	}
+
+	w.binary(6, "agento")
+	w.endStruct()
+	return w.buf
+}
+
+func writeParquetColumnChunk(w *compactWriter, ch parquetChunk, numRows int) {
+	w.beginStruct()
+	w.i64(2, ch.offset)
+	w.structField(3)
+	w.i32(1, parquetPhysicalType(ch.column.Type))
+	if ch.column.Nullable {
+		w.listField(2, ctI32, 2)
+		w.listI32(pqPlain)
+		w.listI32(pqRLE)
+	} else {
+		w.listField(2, ctI32, 1)
+		w.listI32(pqPlain)
+	}
+	w.listField(3, ctBinary, 1)
+	w.listBinary(ch.column.Name)
+	w.i32(4, pqUncompressed)
+	w.i64(5, int64(numRows))
+	w.i64(6, ch.size)
+	w.i64(7, ch.size)
+	w.i64(9, ch.offset)
+	w.endStruct()
+	w.endStruct()
+}
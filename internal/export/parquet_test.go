@@ -0,0 +1,215 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// compactReader decodes the Thrift compact structs writeParquet emits, into
+// maps keyed by field id. It exists so the test reads the file the way a
+// Parquet reader would — from the footer — rather than trusting the writer's
+// own bookkeeping.
+type compactReader struct {
+	buf []byte
+	pos int
+	t   *testing.T
+}
+
+func (r *compactReader) varint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		r.t.Fatalf("bad varint at %d", r.pos)
+	}
+	r.pos += n
+	return v
+}
+
+func (r *compactReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *compactReader) value(typ byte) any {
+	switch typ {
+	case ctI32, ctI64:
+		return r.zigzag()
+	case ctBinary:
+		n := int(r.varint())
+		s := string(r.buf[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case ctList:
+		head := r.buf[r.pos]
+		r.pos++
+		n, elem := int(head>>4), head&0x0f
+		if n == 15 {
+			n = int(r.varint())
+		}
+		out := make([]any, n)
+		for i := range out {
+			out[i] = r.value(elem)
+		}
+		return out
+	case ctStruct:
+		return r.structValue()
+	}
+	r.t.Fatalf("unexpected compact type %d at %d", typ, r.pos)
+	return nil
+}
+
+func (r *compactReader) structValue() map[int16]any {
+	out := map[int16]any{}
+	var last int16
+	for {
+		head := r.buf[r.pos]
+		r.pos++
+		if head == 0 {
+			return out
+		}
+		typ := head & 0x0f
+		if delta := int16(head >> 4); delta != 0 {
+			last += delta
+		} else {
+			last = int16(r.zigzag())
+		}
+		out[last] = r.value(typ)
+	}
+}
+
+func readParquetFooter(t *testing.T, file []byte) map[int16]any {
+	t.Helper()
+	if string(file[:4]) != parquetMagic || string(file[len(file)-4:]) != parquetMagic {
+		t.Fatalf("missing PAR1 magic")
+	}
+	n := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := file[len(file)-8-n : len(file)-8]
+	return (&compactReader{buf: footer, t: t}).structValue()
+}
+
+func TestWriteParquet(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatParquet, sampleTable()); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+	meta := readParquetFooter(t, file)
+
+	if meta[3] != int64(2) {
+		t.Errorf("num_rows = %v, want 2", meta[3])
+	}
+
+	schema := meta[2].([]any)
+	wantSchema := []struct {
+		name      string
+		typ       int64
+		repeat    int64
+		converted int64
+	}{
+		{"id", pqByteArray, pqRequired, pqUTF8},
+		{"tokens", pqInt64, pqRequired, -1},
+		{"cost_usd", pqDouble, pqRequired, -1},
+		{"favorite", pqBoolean, pqRequired, -1},
+		{"finished_at", pqInt64, pqOptional, pqTimestampMillis},
+	}
+	if len(schema) != len(wantSchema)+1 || schema[0].(map[int16]any)[5] != int64(len(wantSchema)) {
+		t.Fatalf("schema = %v, want a root with %d children", schema, len(wantSchema))
+	}
+	for i, want := range wantSchema {
+		el := schema[i+1].(map[int16]any)
+		converted, ok := el[6]
+		if !ok {
+			converted = int64(-1)
+		}
+		if el[4] != want.name || el[1] != want.typ || el[3] != want.repeat || converted != want.converted {
+			t.Errorf("schema[%d] = %v, want %+v", i+1, el, want)
+		}
+	}
+
+	rowGroups := meta[4].([]any)
+	if len(rowGroups) != 1 {
+		t.Fatalf("row groups = %d, want 1", len(rowGroups))
+	}
+	columns := rowGroups[0].(map[int16]any)[1].([]any)
+	page := func(i int) (header map[int16]any, data []byte) {
+		cm := columns[i].(map[int16]any)[3].(map[int16]any)
+		r := &compactReader{buf: file, pos: int(cm[9].(int64)), t: t}
+		header = r.structValue()
+		size := int(header[3].(int64))
+		return header, file[r.pos : r.pos+size]
+	}
+
+	// tokens: two PLAIN int64s.
+	h, data := page(1)
+	if dp := h[5].(map[int16]any); dp[1] != int64(2) || dp[2] != int64(pqPlain) {
+		t.Errorf("tokens page header = %v", h)
+	}
+	if got := []uint64{binary.LittleEndian.Uint64(data), binary.LittleEndian.Uint64(data[8:])}; got[0] != 1200 || got[1] != 0 {
+		t.Errorf("tokens = %v, want [1200 0]", got)
+	}
+
+	// cost_usd: PLAIN doubles.
+	if _, data = page(2); math.Float64frombits(binary.LittleEndian.Uint64(data)) != 0.25 {
+		t.Errorf("cost_usd[0] = %v, want 0.25", math.Float64frombits(binary.LittleEndian.Uint64(data)))
+	}
+
+	// favorite: bit-packed, true then false.
+	if _, data = page(3); len(data) != 1 || data[0] != 0b01 {
+		t.Errorf("favorite = %08b, want 00000001", data)
+	}
+
+	// finished_at: definition levels [1 0] as two runs, then one timestamp.
+	_, data = page(4)
+	levelLen := int(binary.LittleEndian.Uint32(data))
+	if levels := data[4 : 4+levelLen]; !bytes.Equal(levels, []byte{2, 1, 2, 0}) {
+		t.Errorf("definition levels = %v, want runs [1×1, 1×0]", levels)
+	}
+	want := time.Date(2026, 8, 15, 7, 30, 0, 0, time.UTC).UnixMilli()
+	if got := int64(binary.LittleEndian.Uint64(data[4+levelLen:])); got != want || len(data) != 4+levelLen+8 {
+		t.Errorf("finished_at = %d (page %d bytes), want one value %d", got, len(data), want)
+	}
+
+	// id: length-prefixed strings.
+	if _, data = page(0); string(data[4:5]) != "a" || binary.LittleEndian.Uint32(data[5:]) != 11 {
+		t.Errorf("id page = %q", data)
+	}
+}
+
+// TestWriteParquet_Empty writes a schema and no row groups, which readers
+// load as an empty frame with the right columns.
+func TestWriteParquet_Empty(t *testing.T) {
+	table := sampleTable()
+	table.Rows = nil
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatParquet, table); err != nil {
+		t.Fatal(err)
+	}
+	meta := readParquetFooter(t, buf.Bytes())
+	if meta[3] != int64(0) || len(meta[4].([]any)) != 0 || len(meta[2].([]any)) != 6 {
+		t.Errorf("footer = %v, want 0 rows, no row groups and the full schema", meta)
+	}
+}
+
+// TestCompactWriter_LongFormHeaders covers the two escapes a small footer
+// never needs but a wide table does: lists of 15 or more and field-id jumps
+// over 15.
+func TestCompactWriter_LongFormHeaders(t *testing.T) {
+	var w compactWriter
+	w.beginStruct()
+	w.listField(1, ctI32, 20)
+	for i := range 20 {
+		w.listI32(int32(i))
+	}
+	w.i64(40, -3)
+	w.endStruct()
+
+	got := (&compactReader{buf: w.buf, t: t}).structValue()
+	if list := got[1].([]any); len(list) != 20 || list[19] != int64(19) {
+		t.Errorf("list = %v", got[1])
+	}
+	if got[40] != int64(-3) {
+		t.Errorf("field 40 = %v, want -3", got[40])
+	}
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// writeCSV writes a header row and then one record per row. Times are RFC
+// 3339 in UTC, which every spreadsheet parses, and nulls are empty fields.
+func writeCSV(w io.Writer, t Table) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = c.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("writing csv header: %w", err)
+	}
+
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, v := range row {
+			record[i] = formatText(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("writing csv row: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("flushing csv: %w", err)
+	}
+	return nil
+}
+
+func formatText(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		return x.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+// writeJSONL writes one JSON object per row. Keys follow column order rather
+// than the alphabetical order a map would marshal in, so a line reads like
+// the CSV header above it.
+func writeJSONL(w io.Writer, t Table) error {
+	bw := bufio.NewWriter(w)
+	keys := make([][]byte, len(t.Columns))
+	for i, c := range t.Columns {
+		k, err := json.Marshal(c.Name)
+		if err != nil {
+			return fmt.Errorf("encoding column name: %w", err)
+		}
+		keys[i] = k
+	}
+
+	for _, row := range t.Rows {
+		bw.WriteByte('{') //nolint:errcheck // bufio errors surface on Flush
+		for i, v := range row {
+			if i > 0 {
+				bw.WriteByte(',') //nolint:errcheck
+			}
+			if ts, ok := v.(time.Time); ok {
+				v = ts.UTC().Format(time.RFC3339)
+			}
+			val, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("encoding %q: %w", t.Columns[i].Name, err)
+			}
+			bw.Write(keys[i]) //nolint:errcheck
+			bw.WriteByte(':') //nolint:errcheck
+			bw.Write(val)     //nolint:errcheck
+		}
+		bw.WriteString("}\n") //nolint:errcheck
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("writing jsonl: %w", err)
+	}
+	return nil
+}
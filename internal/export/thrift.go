@@ -0,0 +1,88 @@
+package export
+
+import "encoding/binary"
+
+// compactWriter encodes the subset of the Thrift compact protocol Parquet
+// metadata needs: structs of i32, i64, binary, struct and list fields.
+//
+// Parquet's footer and page headers are Thrift structs, and pulling in a
+// Thrift runtime — or a Parquet library built on one — for the handful of
+// structs a flat, uncompressed file uses was more weight than this.
+type compactWriter struct {
+	buf []byte
+	// lastField is the id of the previous field in each open struct; compact
+	// field headers carry the delta from it.
+	lastField []int16
+}
+
+// Compact protocol type codes.
+const (
+	ctI32    = 5
+	ctI64    = 6
+	ctBinary = 8
+	ctList   = 9
+	ctStruct = 12
+)
+
+func (w *compactWriter) beginStruct() { w.lastField = append(w.lastField, 0) }
+
+func (w *compactWriter) endStruct() {
+	w.buf = append(w.buf, 0)
+	w.lastField = w.lastField[:len(w.lastField)-1]
+}
+
+func (w *compactWriter) fieldHeader(id int16, typ byte) {
+	top := len(w.lastField) - 1
+	if delta := id - w.lastField[top]; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.buf = binary.AppendUvarint(w.buf, zigzag(int64(id)))
+	}
+	w.lastField[top] = id
+}
+
+func (w *compactWriter) i32(id int16, v int32) {
+	w.fieldHeader(id, ctI32)
+	w.buf = binary.AppendUvarint(w.buf, zigzag(int64(v)))
+}
+
+func (w *compactWriter) i64(id int16, v int64) {
+	w.fieldHeader(id, ctI64)
+	w.buf = binary.AppendUvarint(w.buf, zigzag(v))
+}
+
+func (w *compactWriter) binary(id int16, v string) {
+	w.fieldHeader(id, ctBinary)
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// structField opens a nested struct field; close it with endStruct.
+func (w *compactWriter) structField(id int16) {
+	w.fieldHeader(id, ctStruct)
+	w.beginStruct()
+}
+
+// listField writes a list header for n elements of typ. The elements follow:
+// bare values for scalars, beginStruct…endStruct for structs.
+func (w *compactWriter) listField(id int16, typ byte, n int) {
+	w.fieldHeader(id, ctList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|typ)
+		return
+	}
+	w.buf = append(w.buf, 0xf0|typ)
+	w.buf = binary.AppendUvarint(w.buf, uint64(n))
+}
+
+func (w *compactWriter) listI32(v int32) {
+	w.buf = binary.AppendUvarint(w.buf, zigzag(int64(v)))
+}
+
+func (w *compactWriter) listBinary(v string) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+func zigzag(v int64) uint64 { return uint64(v<<1) ^ uint64(v>>63) }
@@ -18,6 +18,7 @@ func registerPullsTools(server *mcp.Server, token string, allowed map[string]boo
 	registerCreatePull(server, c, allowed)
 	registerGetPullDiff(server, c, allowed)
 	registerListPullComments(server, c, allowed)
+	registerCreatePullComment(server, c, allowed)
 }
 
 func registerListPulls(server *mcp.Server, c *client, allowed map[string]bool) {
@@ -197,3 +198,33 @@ func registerListPullComments(
 		return textResult(fmt.Sprintf("Comments: %s", string(result)))
 	})
 }
+
+func registerCreatePullComment(
+	server *mcp.Server, c *client, allowed map[string]bool,
+) {
+	if len(allowed) > 0 && !allowed["create_pull_comment"] {
+		return
+	}
+	type params struct {
+		Owner  string `json:"owner" jsonschema:"required,Repository owner"`
+		Repo   string `json:"repo" jsonschema:"required,Repository name"`
+		Number int    `json:"number" jsonschema:"required,Pull request number"`
+		Body   string `json:"body" jsonschema:"required,Comment body in Markdown"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "create_pull_comment",
+		Description: "Posts a comment on a pull request's conversation.",
+	}, func(
+		ctx context.Context, _ *mcp.CallToolRequest, p *params,
+	) (*mcp.CallToolResult, any, error) {
+		// Conversation comments on a pull request are issue comments; the
+		// pulls comments endpoint is for line-anchored review comments.
+		path := fmt.Sprintf("/repos/%s/%s/issues/%d/comments",
+			url.PathEscape(p.Owner), url.PathEscape(p.Repo), p.Number)
+		result, err := c.call(ctx, http.MethodPost, path, map[string]any{"body": p.Body})
+		if err != nil {
+			return nil, nil, err
+		}
+		return textResult(fmt.Sprintf("Comment created: %s", string(result)))
+	})
+}
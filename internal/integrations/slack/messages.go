@@ -0,0 +1,77 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+// Message is a Slack message as returned by conversations.replies.
+type Message struct {
+	User  string `json:"user"`
+	BotID string `json:"bot_id,omitempty"`
+	Text  string `json:"text"`
+	TS    string `json:"ts"`
+}
+
+// PostMessage posts text to a channel as the integration's bot, in the
+// thread of threadTS when it is set, and returns the new message's timestamp.
+func PostMessage(ctx context.Context, cfg *config.IntegrationConfig, channel, text, threadTS string) (string, error) {
+	token, err := resolveToken(cfg)
+	if err != nil {
+		return "", err
+	}
+	payload := map[string]any{
+		"channel": channel,
+		"text":    text,
+	}
+	if threadTS != "" {
+		payload["thread_ts"] = threadTS
+	}
+
+	body, err := callSlackJSON(ctx, token, "chat.postMessage", payload)
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		TS string `json:"ts"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("parsing response: %w", err)
+	}
+	return resp.TS, nil
+}
+
+// ThreadReplies returns the replies in the thread of the message at ts,
+// oldest first, without the message itself.
+func ThreadReplies(ctx context.Context, cfg *config.IntegrationConfig, channel, ts string) ([]Message, error) {
+	token, err := resolveToken(cfg)
+	if err != nil {
+		return nil, err
+	}
+	v := url.Values{}
+	v.Set("channel", channel)
+	v.Set("ts", ts)
+
+	body, err := callSlack(ctx, token, "conversations.replies", v)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Messages []Message `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	replies := make([]Message, 0, len(resp.Messages))
+	for _, m := range resp.Messages {
+		if m.TS != ts {
+			replies = append(replies, m)
+		}
+	}
+	return replies, nil
+}
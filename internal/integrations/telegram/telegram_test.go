@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 // setupTestServer creates an httptest.Server and points apiBaseURL at it.
@@ -226,6 +227,59 @@ func TestHandleReadMessages_LimitClamping(t *testing.T) {
 	}
 }
 
+func TestGetUpdates(t *testing.T) {
+	var method string
+	var payload map[string]any
+	_, cleanup := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		method = r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		writeTestJSON(w, telegramResponse{OK: true, Result: json.RawMessage(`[{"update_id":7}]`)})
+	})
+	defer cleanup()
+
+	got, err := GetUpdates(context.Background(), "test-token", 7, 30*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != `[{"update_id":7}]` {
+		t.Errorf("result = %s", got)
+	}
+	if method != "getUpdates" {
+		t.Errorf("method = %q, want getUpdates", method)
+	}
+	if payload["offset"] != float64(7) || payload["timeout"] != float64(30) {
+		t.Errorf("payload = %v, want offset 7 and timeout 30", payload)
+	}
+}
+
+func TestSendWithButtons(t *testing.T) {
+	var payload struct {
+		ChatID      int64 `json:"chat_id"`
+		ReplyMarkup struct {
+			InlineKeyboard [][]InlineButton `json:"inline_keyboard"`
+		} `json:"reply_markup"`
+	}
+	_, cleanup := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		writeTestJSON(w, telegramResponse{OK: true, Result: json.RawMessage(`{"message_id":42}`)})
+	})
+	defer cleanup()
+
+	buttons := []InlineButton{{Text: "Approve", Data: "approve:1"}, {Text: "Deny", Data: "deny:1"}}
+	id, err := SendWithButtons(context.Background(), "test-token", -100, "Run Bash?", buttons)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 42 {
+		t.Errorf("message id = %d, want 42", id)
+	}
+	if payload.ChatID != -100 || len(payload.ReplyMarkup.InlineKeyboard) != 1 ||
+		len(payload.ReplyMarkup.InlineKeyboard[0]) != 2 ||
+		payload.ReplyMarkup.InlineKeyboard[0][1].Data != "deny:1" {
+		t.Errorf("payload = %+v, want both buttons in one row", payload)
+	}
+}
+
 func TestHandleCreatePoll_OptionsValidation(t *testing.T) {
 	tests := []struct {
 		name    string
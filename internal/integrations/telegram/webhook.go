@@ -4,17 +4,23 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"time"
 	"unicode/utf8"
 )
 
+// allowedUpdates are the update types Agento receives: messages for trigger
+// rules, and callback queries for the buttons on approval requests.
+var allowedUpdates = []string{"message", "callback_query"}
+
 // RegisterWebhook calls Telegram's setWebhook API to register a webhook URL
 // with the given secret token for request verification.
 func RegisterWebhook(ctx context.Context, botToken, webhookURL, secretToken string) error {
 	payload := map[string]any{
 		"url":                  webhookURL,
 		"secret_token":         secretToken,
-		"allowed_updates":      []string{"message"},
+		"allowed_updates":      allowedUpdates,
 		"drop_pending_updates": false,
 	}
 
@@ -34,6 +40,24 @@ func DeleteWebhook(ctx context.Context, botToken string) error {
 	return nil
 }
 
+// GetUpdates long-polls Telegram's getUpdates API for updates from
+// offset on, waiting up to timeout for one to arrive. It returns the raw
+// array of Update objects, which is empty when none arrived in time.
+// Telegram refuses getUpdates while a webhook is registered.
+func GetUpdates(ctx context.Context, botToken string, offset int64, timeout time.Duration) (json.RawMessage, error) {
+	payload := map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": allowedUpdates,
+	}
+
+	resp, err := callTelegram(ctx, botToken, "getUpdates", payload)
+	if err != nil {
+		return nil, fmt.Errorf("getting updates: %w", err)
+	}
+	return resp.Result, nil
+}
+
 // GenerateSecretToken generates a cryptographically random secret token
 // for Telegram webhook verification (64 hex chars = 32 bytes).
 func GenerateSecretToken() (string, error) {
@@ -76,6 +100,68 @@ func SendReply(ctx context.Context, botToken string, chatID int64, replyToMsgID
 	return nil
 }
 
+// InlineButton is a button shown under a message. Pressing it sends Data
+// back in a callback query.
+type InlineButton struct {
+	Text string `json:"text"`
+	Data string `json:"callback_data"`
+}
+
+// SendWithButtons sends text to a chat with one row of inline buttons and
+// returns the ID of the sent message. Telegram caps callback data at 64 bytes.
+func SendWithButtons(
+	ctx context.Context, botToken string, chatID int64, text string, buttons []InlineButton,
+) (int, error) {
+	payload := map[string]any{
+		"chat_id":      chatID,
+		"text":         truncateMessage(text),
+		"reply_markup": map[string]any{"inline_keyboard": [][]InlineButton{buttons}},
+	}
+	resp, err := callTelegram(ctx, botToken, "sendMessage", payload)
+	if err != nil {
+		return 0, fmt.Errorf("sending message: %w", err)
+	}
+	var sent struct {
+		MessageID int `json:"message_id"`
+	}
+	if err := json.Unmarshal(resp.Result, &sent); err != nil {
+		return 0, fmt.Errorf("parsing sent message: %w", err)
+	}
+	return sent.MessageID, nil
+}
+
+// EditMessageText replaces the text of a sent message, removing its buttons.
+func EditMessageText(ctx context.Context, botToken string, chatID int64, messageID int, text string) error {
+	payload := map[string]any{
+		"chat_id":    chatID,
+		"message_id": messageID,
+		"text":       truncateMessage(text),
+	}
+	if _, err := callTelegram(ctx, botToken, "editMessageText", payload); err != nil {
+		return fmt.Errorf("editing message: %w", err)
+	}
+	return nil
+}
+
+// AnswerCallbackQuery acknowledges a button press, showing text to the user
+// who pressed it. Telegram keeps the button spinning until it is answered.
+func AnswerCallbackQuery(ctx context.Context, botToken, callbackQueryID, text string) error {
+	payload := map[string]any{
+		"callback_query_id": callbackQueryID,
+		"text":              text,
+	}
+	if _, err := callTelegram(ctx, botToken, "answerCallbackQuery", payload); err != nil {
+		return fmt.Errorf("answering callback query: %w", err)
+	}
+	return nil
+}
+
+// truncateMessage cuts text to the length of one Telegram message.
+func truncateMessage(text string) string {
+	const maxLen = 4096
+	return splitMessage(text, maxLen)[0]
+}
+
 // splitMessage splits text into chunks of at most maxLen bytes,
 // ensuring splits occur on valid UTF-8 rune boundaries.
 func splitMessage(text string, maxLen int) []string {
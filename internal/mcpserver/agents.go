@@ -0,0 +1,128 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/approval"
+	"github.com/shaharia-lab/agento/internal/audit"
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+type runAgentParams struct {
+	Agent     string            `json:"agent" jsonschema:"required,Slug of the agent to run, as list_agents shows it"`
+	Prompt    string            `json:"prompt" jsonschema:"required,The message to send the agent"`
+	Variables map[string]string `json:"variables,omitempty" jsonschema:"Values for the system prompt's {{variables}}"`
+}
+
+// agentSummary is what list_agents shows of an agent.
+type agentSummary struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Model       string `json:"model,omitempty"`
+}
+
+// runAgentResult is what run_agent returns.
+type runAgentResult struct {
+	Answer            string   `json:"answer"`
+	SessionID         string   `json:"session_id"`
+	CostUSD           float64  `json:"cost_usd"`
+	InputTokens       int      `json:"input_tokens"`
+	OutputTokens      int      `json:"output_tokens"`
+	PermissionDenials []string `json:"permission_denials,omitempty"`
+}
+
+func (t *toolset) registerAgentTools(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_agents",
+		Description: "Lists the agents saved in Agento, with their slugs for run_agent.",
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, _ *struct{}) (*mcp.CallToolResult, any, error) {
+		agents, err := t.cfg.Agents.List(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("listing agents: %w", err)
+		}
+		out := make([]agentSummary, 0, len(agents))
+		for _, a := range agents {
+			out = append(out, agentSummary{Slug: a.Slug, Name: a.Name, Description: a.Description, Model: a.Model})
+		}
+		return jsonResult(out)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "run_agent",
+		Description: "Runs a saved Agento agent on a prompt, with its own system prompt, model and tools, " +
+			"and returns its answer. Each call starts a new session.",
+	}, t.runAgent)
+}
+
+func (t *toolset) runAgent(
+	ctx context.Context, _ *mcp.CallToolRequest, params *runAgentParams,
+) (*mcp.CallToolResult, any, error) {
+	agentCfg, err := t.cfg.Agents.Get(ctx, params.Agent)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading agent %q: %w", params.Agent, err)
+	}
+	if agentCfg == nil {
+		return nil, nil, fmt.Errorf("agent %q not found", params.Agent)
+	}
+
+	opts, err := t.runOptions(ctx, agentCfg, params.Variables)
+	if err != nil {
+		return nil, nil, err
+	}
+	t.cfg.Logger.Info("running agent for MCP client", "agent", agentCfg.Slug)
+	result, err := agent.RunAgent(ctx, agentCfg, params.Prompt, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("running agent %q: %w", params.Agent, err)
+	}
+	return jsonResult(runAgentResult{
+		Answer:            result.Answer,
+		SessionID:         result.SessionID,
+		CostUSD:           result.CostUSD,
+		InputTokens:       result.Usage.InputTokens,
+		OutputTokens:      result.Usage.OutputTokens,
+		PermissionDenials: result.PermissionDenials,
+	})
+}
+
+// runOptions builds the options of an MCP-initiated run. An agent that gates
+// tools is refused when there is no approval broker: nobody would be asked,
+// and the gated calls must not run unasked.
+func (t *toolset) runOptions(
+	ctx context.Context, agentCfg *config.AgentConfig, variables map[string]string,
+) (agent.RunOptions, error) {
+	opts := agent.RunOptions{
+		Variables:           variables,
+		LocalToolsMCP:       t.cfg.LocalToolsMCP,
+		MCPRegistry:         t.cfg.MCPRegistry,
+		IntegrationRegistry: t.cfg.IntegrationRegistry,
+	}
+	if agentCfg.Capabilities.MayRequireApproval() {
+		if t.cfg.Approvals == nil {
+			return opts, errNeedsApprovals(agentCfg.Slug)
+		}
+		opts.PermissionHandler = t.cfg.Approvals.PermissionHandler(ctx, agentCfg, approval.Run{
+			Source:    Source,
+			AgentSlug: agentCfg.Slug,
+			Title:     "MCP run of " + agentCfg.Name,
+		})
+	}
+	if t.cfg.Budget != nil {
+		opts.Budget = t.cfg.Budget.ForRun(agentCfg.Slug, "", Source)
+	}
+	if t.cfg.Audit != nil {
+		opts.Audit = t.cfg.Audit.ForRun(audit.Run{Source: audit.SourceMCP, AgentSlug: agentCfg.Slug})
+	}
+	return opts, nil
+}
+
+// errNeedsApprovals is the error for a run refused for want of an approval
+// broker.
+func errNeedsApprovals(agentSlug string) error {
+	return fmt.Errorf("agent %q gates tools on approval, which needs `agento web`; "+
+		"connect to its /mcp endpoint instead", agentSlug)
+}
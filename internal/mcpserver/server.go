@@ -0,0 +1,130 @@
+// Package mcpserver exposes Agento to MCP clients such as Claude Code: run a
+// saved agent as a tool, list and trigger scheduled tasks, and query the cost
+// and usage analytics of Claude Code sessions. `agento mcp` serves it over
+// stdio and `agento web` over streamable HTTP at /mcp.
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/approval"
+	"github.com/shaharia-lab/agento/internal/audit"
+	"github.com/shaharia-lab/agento/internal/build"
+	"github.com/shaharia-lab/agento/internal/claudesessions"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/integrations"
+	"github.com/shaharia-lab/agento/internal/service"
+	"github.com/shaharia-lab/agento/internal/storage"
+	"github.com/shaharia-lab/agento/internal/tools"
+)
+
+// Source names MCP-initiated runs on the budget ledger and approval requests.
+const Source = "mcp"
+
+// SessionIndex is the Claude Code session index the analytics are computed
+// from. *claudesessions.Cache satisfies it.
+type SessionIndex interface {
+	EnsureScan() <-chan struct{}
+	Analytics(p claudesessions.AnalyticsParams) claudesessions.AnalyticsReport
+}
+
+// BudgetEnforcer caps the spend of agent runs.
+type BudgetEnforcer interface {
+	ForRun(agentSlug, taskID, source string) agent.BudgetGuard
+}
+
+// ApprovalBroker gates the tool calls of agent runs on a person's approval.
+type ApprovalBroker interface {
+	PermissionHandler(ctx context.Context, agentCfg *config.AgentConfig, run approval.Run) claude.PermissionHandler
+}
+
+// ToolAuditLog hands out the auditor that logs the tool calls of each run.
+type ToolAuditLog interface {
+	ForRun(run audit.Run) agent.ToolAuditor
+}
+
+// Config holds the dependencies of the MCP server.
+type Config struct {
+	Agents   storage.AgentStore
+	Tasks    service.TaskService
+	Sessions SessionIndex
+
+	LocalToolsMCP       *tools.LocalMCPConfig
+	MCPRegistry         *config.MCPRegistry
+	IntegrationRegistry *integrations.IntegrationRegistry
+
+	// Budget is optional. When set, agent runs are checked against the
+	// budget caps.
+	Budget BudgetEnforcer
+	// Approvals is optional. When set, the gated tool calls of an agent run
+	// wait for a person's answer; without it, agents and tasks that gate
+	// tools are refused.
+	Approvals ApprovalBroker
+	// Audit is optional. When set, the tool calls of agent runs are logged.
+	Audit ToolAuditLog
+
+	Logger *slog.Logger
+}
+
+// New returns the MCP server with every Agento tool registered.
+func New(cfg Config) *mcp.Server {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	server := mcp.NewServer(&mcp.Implementation{
+		Name:    "agento",
+		Version: build.Version,
+	}, nil)
+
+	t := &toolset{cfg: cfg}
+	t.registerAgentTools(server)
+	t.registerTaskTools(server)
+	t.registerUsageTools(server)
+	return server
+}
+
+// toolset implements the tool handlers.
+type toolset struct {
+	cfg Config
+}
+
+// textResult is a helper that wraps a string in an MCP CallToolResult.
+func textResult(text string) (*mcp.CallToolResult, any, error) {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, nil, nil
+}
+
+// jsonResult wraps v, indented, in an MCP CallToolResult.
+func jsonResult(v any) (*mcp.CallToolResult, any, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding result: %w", err)
+	}
+	return textResult(string(b))
+}
+
+// NewHTTPHandler serves server over the streamable HTTP transport. Requests a
+// browser sends cross-origin are refused, so a web page cannot drive the
+// tools of a local Agento.
+//
+// The SDK's own Host check is off: it would refuse a reverse proxy on
+// loopback that forwards the public URL's Host. The router guards the
+// endpoint with the Host check /api has, which knows that URL.
+func NewHTTPHandler(server *mcp.Server) http.Handler {
+	return mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server },
+		&mcp.StreamableHTTPOptions{
+			CrossOriginProtection:      http.NewCrossOriginProtection(),
+			DisableLocalhostProtection: true,
+		})
+}
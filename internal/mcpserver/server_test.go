@@ -0,0 +1,174 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/claudesessions"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/service/mocks"
+	"github.com/shaharia-lab/agento/internal/storage"
+	storagemocks "github.com/shaharia-lab/agento/internal/storage/mocks"
+)
+
+type stubSessionIndex struct {
+	params []claudesessions.AnalyticsParams
+}
+
+func (s *stubSessionIndex) EnsureScan() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+func (s *stubSessionIndex) Analytics(p claudesessions.AnalyticsParams) claudesessions.AnalyticsReport {
+	s.params = append(s.params, p)
+	return claudesessions.AnalyticsReport{
+		Projects:    []string{"/home/me/agento", "/home/me/blog", "/srv/blog"},
+		CostSummary: claudesessions.CostSummary{TotalCostUSD: 12.5},
+	}
+}
+
+// connect returns a client session on a server built from cfg.
+func connect(t *testing.T, cfg Config) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := New(cfg).Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { serverSession.Close() }) //nolint:errcheck
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() }) //nolint:errcheck
+	return session
+}
+
+func callTool(t *testing.T, session *mcp.ClientSession, name string, args map[string]any) (string, bool) {
+	t.Helper()
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	require.NoError(t, err)
+	require.Len(t, res.Content, 1)
+	text, ok := res.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	return text.Text, res.IsError
+}
+
+func TestServer_ListsTools(t *testing.T) {
+	session := connect(t, Config{})
+	res, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range res.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"list_agents", "run_agent", "list_tasks", "run_task", "list_job_history", "usage_analytics",
+	}, names)
+}
+
+func TestServer_Agents(t *testing.T) {
+	agents := storagemocks.NewMockAgentStore(t)
+	agents.On("List", mock.Anything).Return([]*config.AgentConfig{
+		{Slug: "reviewer", Name: "Reviewer", Model: "sonnet", SystemPrompt: "secret"},
+	}, nil)
+	agents.On("Get", mock.Anything, "missing").Return(nil, nil)
+	agents.On("Get", mock.Anything, "deployer").Return(&config.AgentConfig{
+		Slug:         "deployer",
+		Capabilities: config.AgentCapabilities{RequireApproval: []string{"Bash"}},
+	}, nil)
+	session := connect(t, Config{Agents: agents})
+
+	text, isErr := callTool(t, session, "list_agents", nil)
+	assert.False(t, isErr)
+	assert.Contains(t, text, `"slug": "reviewer"`)
+	assert.NotContains(t, text, "secret", "system prompts are not listed")
+
+	text, isErr = callTool(t, session, "run_agent", map[string]any{"agent": "missing", "prompt": "hi"})
+	assert.True(t, isErr)
+	assert.Contains(t, text, `agent "missing" not found`)
+
+	text, isErr = callTool(t, session, "run_agent", map[string]any{"agent": "deployer", "prompt": "ship it"})
+	assert.True(t, isErr, "a gated agent is refused without an approval broker")
+	assert.Contains(t, text, "/mcp")
+}
+
+func TestServer_Tasks(t *testing.T) {
+	now := time.Now()
+	tasks := mocks.NewMockTaskService(t)
+	tasks.On("ListTasks", mock.Anything).Return([]*storage.ScheduledTask{
+		{ID: "t1", Name: "Nightly report", Prompt: "long prompt", Status: storage.TaskStatusActive, NextRunAt: &now},
+	}, nil)
+	tasks.On("GetTask", mock.Anything, "t1").Return(&storage.ScheduledTask{ID: "t1", AgentSlug: "reporter"}, nil)
+	tasks.On("RunTask", mock.Anything, "t1").Return(&storage.ScheduledTask{ID: "t1", Name: "Nightly report"}, nil)
+	tasks.On("GetTask", mock.Anything, "t2").Return(&storage.ScheduledTask{
+		ID:    "t2",
+		Steps: []storage.PipelineStep{{AgentSlug: "reporter"}, {AgentSlug: "deployer"}},
+	}, nil)
+	tasks.On("ListAllJobHistory", mock.Anything, 100, 0).Return([]*storage.JobHistory{{ID: "j1"}}, nil)
+	tasks.On("ListJobHistory", mock.Anything, "t1", 10).Return(nil, errors.New("db error"))
+
+	agents := storagemocks.NewMockAgentStore(t)
+	agents.On("Get", mock.Anything, "reporter").Return(&config.AgentConfig{Slug: "reporter"}, nil)
+	agents.On("Get", mock.Anything, "deployer").Return(&config.AgentConfig{
+		Slug:         "deployer",
+		Capabilities: config.AgentCapabilities{RequireApproval: []string{"Bash"}},
+	}, nil)
+	session := connect(t, Config{Agents: agents, Tasks: tasks})
+
+	text, isErr := callTool(t, session, "list_tasks", nil)
+	assert.False(t, isErr)
+	assert.Contains(t, text, `"name": "Nightly report"`)
+	assert.NotContains(t, text, "long prompt")
+
+	text, isErr = callTool(t, session, "run_task", map[string]any{"id": "t1"})
+	assert.False(t, isErr)
+	assert.Contains(t, text, "Nightly report")
+
+	_, isErr = callTool(t, session, "run_task", map[string]any{"id": "t2"})
+	assert.True(t, isErr, "a pipeline with a gated step is refused without an approval broker")
+	tasks.AssertNotCalled(t, "RunTask", mock.Anything, "t2")
+
+	text, isErr = callTool(t, session, "list_job_history", map[string]any{"limit": 500})
+	assert.False(t, isErr)
+	assert.Contains(t, text, `"id": "j1"`)
+
+	_, isErr = callTool(t, session, "list_job_history", map[string]any{"task_id": "t1"})
+	assert.True(t, isErr)
+}
+
+func TestServer_UsageAnalytics(t *testing.T) {
+	sessions := &stubSessionIndex{}
+	session := connect(t, Config{Sessions: sessions})
+
+	text, isErr := callTool(t, session, "usage_analytics", map[string]any{
+		"from": "2026-05-04", "to": "2026-05-10", "project": "agento", "tz": "Europe/Berlin",
+	})
+	require.False(t, isErr, text)
+	assert.Contains(t, text, `"total_cost_usd": 12.5`)
+	assert.Contains(t, text, `"project": "/home/me/agento"`)
+
+	require.Len(t, sessions.params, 2, "a directory name is resolved to its path and asked again")
+	p := sessions.params[1]
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	assert.Equal(t, "/home/me/agento", p.Project)
+	assert.Equal(t, time.Date(2026, 5, 4, 0, 0, 0, 0, berlin), p.From)
+	assert.Equal(t, time.Date(2026, 5, 11, 0, 0, 0, 0, berlin).Add(-time.Nanosecond), p.To)
+
+	text, isErr = callTool(t, session, "usage_analytics", map[string]any{"project": "blog"})
+	assert.True(t, isErr)
+	assert.Contains(t, text, "/srv/blog", "an ambiguous name lists the candidates")
+
+	_, isErr = callTool(t, session, "usage_analytics", map[string]any{"from": "last week"})
+	assert.True(t, isErr)
+}
@@ -0,0 +1,139 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// maxJobHistoryLimit caps list_job_history's limit.
+const maxJobHistoryLimit = 100
+
+type runTaskParams struct {
+	ID string `json:"id" jsonschema:"required,ID of the scheduled task, as list_tasks shows it"`
+}
+
+type listJobHistoryParams struct {
+	TaskID string `json:"task_id,omitempty" jsonschema:"Only runs of this task (default: all tasks)"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"Maximum number of runs, newest first (default 10, max 100)"`
+}
+
+// taskSummary is what list_tasks shows of a task: enough to pick one, without
+// its prompt or pipeline.
+type taskSummary struct {
+	ID            string             `json:"id"`
+	Name          string             `json:"name"`
+	Description   string             `json:"description,omitempty"`
+	AgentSlug     string             `json:"agent_slug,omitempty"`
+	Status        storage.TaskStatus `json:"status"`
+	ScheduleType  string             `json:"schedule_type"`
+	RunCount      int                `json:"run_count"`
+	LastRunAt     *time.Time         `json:"last_run_at,omitempty"`
+	LastRunStatus string             `json:"last_run_status,omitempty"`
+	NextRunAt     *time.Time         `json:"next_run_at,omitempty"`
+}
+
+func (t *toolset) registerTaskTools(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_tasks",
+		Description: "Lists Agento's scheduled tasks with their status and last and next run.",
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, _ *struct{}) (*mcp.CallToolResult, any, error) {
+		tasks, err := t.cfg.Tasks.ListTasks(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		out := make([]taskSummary, 0, len(tasks))
+		for _, task := range tasks {
+			out = append(out, taskSummary{
+				ID:            task.ID,
+				Name:          task.Name,
+				Description:   task.Description,
+				AgentSlug:     task.AgentSlug,
+				Status:        task.Status,
+				ScheduleType:  string(task.ScheduleType),
+				RunCount:      task.RunCount,
+				LastRunAt:     task.LastRunAt,
+				LastRunStatus: task.LastRunStatus,
+				NextRunAt:     task.NextRunAt,
+			})
+		}
+		return jsonResult(out)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "run_task",
+		Description: "Starts a run of an active scheduled task now, outside its schedule. " +
+			"It returns at once; the run's outcome appears in list_job_history.",
+	}, t.runTask)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_job_history",
+		Description: "Lists recent runs of scheduled tasks, newest first, with their status, usage and response.",
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, params *listJobHistoryParams) (*mcp.CallToolResult, any, error) {
+		limit := params.Limit
+		if limit <= 0 {
+			limit = 10
+		}
+		limit = min(limit, maxJobHistoryLimit)
+
+		var history []*storage.JobHistory
+		var err error
+		if params.TaskID != "" {
+			history, err = t.cfg.Tasks.ListJobHistory(ctx, params.TaskID, limit)
+		} else {
+			history, err = t.cfg.Tasks.ListAllJobHistory(ctx, limit, 0)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if history == nil {
+			history = []*storage.JobHistory{}
+		}
+		return jsonResult(history)
+	})
+}
+
+func (t *toolset) runTask(
+	ctx context.Context, _ *mcp.CallToolRequest, params *runTaskParams,
+) (*mcp.CallToolResult, any, error) {
+	if t.cfg.Approvals == nil {
+		if err := t.refuseGated(ctx, params.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+	task, err := t.cfg.Tasks.RunTask(ctx, params.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return textResult(fmt.Sprintf("Started a run of task %q (%s).", task.Name, task.ID))
+}
+
+// refuseGated returns an error when an agent the task runs gates tools, for
+// the reason runOptions refuses to run such an agent.
+func (t *toolset) refuseGated(ctx context.Context, taskID string) error {
+	task, err := t.cfg.Tasks.GetTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	slugs := []string{task.AgentSlug}
+	for _, step := range task.Steps {
+		slugs = append(slugs, step.AgentSlug)
+	}
+	for _, slug := range slugs {
+		if slug == "" {
+			continue
+		}
+		agentCfg, err := t.cfg.Agents.Get(ctx, slug)
+		if err != nil {
+			return fmt.Errorf("loading agent %q: %w", slug, err)
+		}
+		if agentCfg != nil && agentCfg.Capabilities.MayRequireApproval() {
+			return errNeedsApprovals(slug)
+		}
+	}
+	return nil
+}
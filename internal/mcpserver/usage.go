@@ -0,0 +1,173 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/shaharia-lab/agento/internal/claudesessions"
+)
+
+// usageTopN caps each breakdown of usage_analytics, which is read by a model
+// and costs context per row.
+const usageTopN = 10
+
+type usageParams struct {
+	From    string `json:"from,omitempty" jsonschema:"Start of the window, YYYY-MM-DD or RFC3339 (default: 30 days ago)"`
+	To      string `json:"to,omitempty" jsonschema:"End of the window, YYYY-MM-DD (inclusive) or RFC3339 (default: now)"`
+	Project string `json:"project,omitempty" jsonschema:"Only this project: its path, or a unique directory name"`
+	TZ      string `json:"tz,omitempty" jsonschema:"IANA timezone bare dates are read in (default: UTC)"`
+}
+
+// usageReport is what usage_analytics returns: the figures `agento stats`
+// prints, not the dashboard's chart series.
+type usageReport struct {
+	From        time.Time                       `json:"from"`
+	To          time.Time                       `json:"to"`
+	Project     string                          `json:"project,omitempty"`
+	Summary     claudesessions.AnalyticsSummary `json:"summary"`
+	CostSummary claudesessions.CostSummary      `json:"cost_summary"`
+	Forecast    claudesessions.SpendForecast    `json:"forecast"`
+	ByModel     []claudesessions.ModelCostStat  `json:"by_model"`
+	ByProject   []claudesessions.ProjectStat    `json:"by_project"`
+	TopSessions []claudesessions.SessionRanking `json:"top_sessions"`
+}
+
+func (t *toolset) registerUsageTools(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "usage_analytics",
+		Description: "Reports Claude Code cost and usage for a window: totals, the month's spend forecast, " +
+			"and breakdowns by model and by project with the most expensive sessions. " +
+			"Answers questions like \"how much did project X cost this week\".",
+	}, t.usageAnalytics)
+}
+
+func (t *toolset) usageAnalytics(
+	ctx context.Context, _ *mcp.CallToolRequest, params *usageParams,
+) (*mcp.CallToolResult, any, error) {
+	p, err := params.analyticsParams(time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The scan is incremental, so waiting costs little once the index is warm,
+	// and an answer about "today" includes today.
+	select {
+	case <-t.cfg.Sessions.EnsureScan():
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+
+	report := t.cfg.Sessions.Analytics(p)
+	if p.Project != "" && !slices.Contains(report.Projects, p.Project) {
+		project, err := resolveProject(p.Project, report.Projects)
+		if err != nil {
+			return nil, nil, err
+		}
+		p.Project = project
+		report = t.cfg.Sessions.Analytics(p)
+	}
+
+	return jsonResult(usageReport{
+		From:        p.From,
+		To:          p.To,
+		Project:     p.Project,
+		Summary:     report.Summary,
+		CostSummary: report.CostSummary,
+		Forecast:    report.Forecast,
+		ByModel:     firstN(report.CostByModel, usageTopN),
+		ByProject:   firstN(report.ProjectBreakdown, usageTopN),
+		TopSessions: firstN(report.TopSessions.ByCost, usageTopN),
+	})
+}
+
+// analyticsParams resolves the window the way the analytics endpoint does:
+// the last 30 days by default, with bare dates read in the chosen timezone
+// and a bare end date covering that whole day.
+func (u *usageParams) analyticsParams(now time.Time) (claudesessions.AnalyticsParams, error) {
+	loc := time.UTC
+	if u.TZ != "" {
+		l, err := time.LoadLocation(u.TZ)
+		if err != nil {
+			return claudesessions.AnalyticsParams{}, fmt.Errorf("invalid tz: %w", err)
+		}
+		loc = l
+	}
+
+	p := claudesessions.AnalyticsParams{
+		From:    now.In(loc).AddDate(0, 0, -30),
+		To:      now.In(loc),
+		Project: u.Project,
+		Loc:     loc,
+	}
+	if u.From != "" {
+		from, err := parseDate(u.From, false, loc)
+		if err != nil {
+			return p, fmt.Errorf("invalid from: %w", err)
+		}
+		p.From = from
+	}
+	if u.To != "" {
+		to, err := parseDate(u.To, true, loc)
+		if err != nil {
+			return p, fmt.Errorf("invalid to: %w", err)
+		}
+		p.To = to
+	}
+	if !p.From.Before(p.To) {
+		return p, fmt.Errorf("from must be before to")
+	}
+	return p, nil
+}
+
+// parseDate reads RFC 3339 or a bare date in loc. A date used as an end
+// bound means the end of that day.
+func parseDate(raw string, endOfDay bool, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", raw, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither YYYY-MM-DD nor RFC3339", raw)
+	}
+	if endOfDay {
+		return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return day, nil
+}
+
+// resolveProject finds the one known project path that name refers to: a
+// client asking about "project X" rarely knows its full path.
+func resolveProject(name string, projects []string) (string, error) {
+	name = strings.TrimSuffix(name, "/")
+	var matches []string
+	for _, p := range projects {
+		if filepath.Base(p) == name || strings.HasSuffix(p, "/"+name) {
+			matches = append(matches, p)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return "", fmt.Errorf("no project matches %q", name)
+	default:
+		return "", fmt.Errorf("%q matches several projects, pass one of: %s", name, strings.Join(matches, ", "))
+	}
+}
+
+// firstN returns at most n leading items, never nil, so the JSON shows [].
+func firstN[T any](items []T, n int) []T {
+	if len(items) > n {
+		items = items[:n]
+	}
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -1,5 +1,7 @@
 package notification
 
+import "time"
+
 // SMTPConfig holds connection parameters for the SMTP provider.
 type SMTPConfig struct {
 	Host       string `json:"host"`
@@ -30,12 +32,84 @@ func (p ScheduledTasksPreferences) IsOnFailedEnabled() bool {
 	return p.OnFailed == nil || *p.OnFailed
 }
 
+// BudgetPreferences controls notifications for budget guardrail events.
+// A nil pointer means "use the default", which is enabled (true).
+type BudgetPreferences struct {
+	// OnExceeded, when nil or true, enables notifications when a run is
+	// refused or aborted by a budget cap.
+	OnExceeded *bool `json:"on_exceeded,omitempty"`
+}
+
+// IsOnExceededEnabled returns true unless OnExceeded is explicitly set to false.
+func (p BudgetPreferences) IsOnExceededEnabled() bool {
+	return p.OnExceeded == nil || *p.OnExceeded
+}
+
+// UsagePreferences controls notifications for Claude Code usage events.
+// A nil pointer means "use the default", which is enabled (true).
+type UsagePreferences struct {
+	// OnAnomaly, when nil or true, enables notifications when a day or a
+	// session costs far more than its recent baseline.
+	OnAnomaly *bool `json:"on_anomaly,omitempty"`
+	// OnLimitWarning, when nil or true, enables notifications when a Claude
+	// subscription usage window nears or reaches its limit.
+	OnLimitWarning *bool `json:"on_limit_warning,omitempty"`
+}
+
+// IsOnAnomalyEnabled returns true unless OnAnomaly is explicitly set to false.
+func (p UsagePreferences) IsOnAnomalyEnabled() bool {
+	return p.OnAnomaly == nil || *p.OnAnomaly
+}
+
+// IsOnLimitWarningEnabled returns true unless OnLimitWarning is explicitly set
+// to false.
+func (p UsagePreferences) IsOnLimitWarningEnabled() bool {
+	return p.OnLimitWarning == nil || *p.OnLimitWarning
+}
+
 // NotificationPreferences holds per-event-category notification preferences.
 // The name is intentional: it provides clarity when referenced as notification.NotificationPreferences.
 //
 //nolint:revive
 type NotificationPreferences struct {
 	ScheduledTasks ScheduledTasksPreferences `json:"scheduled_tasks"`
+	Budgets        BudgetPreferences         `json:"budgets"`
+	Usage          UsagePreferences          `json:"usage"`
+}
+
+// Approval channels.
+const (
+	ApprovalChannelTelegram = "telegram"
+	ApprovalChannelSlack    = "slack"
+	ApprovalChannelEmail    = "email"
+)
+
+// DefaultApprovalTimeoutMinutes is how long an approval request waits for an
+// answer when ApprovalSettings.TimeoutMinutes is unset.
+const DefaultApprovalTimeoutMinutes = 30
+
+// ApprovalSettings controls where approval requests from unattended runs go.
+type ApprovalSettings struct {
+	// Channel is "telegram", "slack", "email", or empty to only list requests
+	// on the Approvals page.
+	Channel string `json:"channel,omitempty"`
+	// IntegrationID is the Telegram or Slack integration to send through.
+	IntegrationID string `json:"integration_id,omitempty"`
+	// Target is the Telegram chat ID or Slack channel ID requests are posted
+	// to. Email goes to the provider's recipients.
+	Target string `json:"target,omitempty"`
+	// TimeoutMinutes is how long a run waits for an answer before the call is
+	// denied; zero means DefaultApprovalTimeoutMinutes.
+	TimeoutMinutes int `json:"timeout_minutes,omitempty"`
+}
+
+// Timeout returns how long a request waits for an answer.
+func (a ApprovalSettings) Timeout() time.Duration {
+	minutes := a.TimeoutMinutes
+	if minutes <= 0 {
+		minutes = DefaultApprovalTimeoutMinutes
+	}
+	return time.Duration(minutes) * time.Minute
 }
 
 // NotificationSettings represents the persisted notification configuration.
@@ -46,4 +120,5 @@ type NotificationSettings struct {
 	Enabled     bool                    `json:"enabled"`
 	Provider    SMTPConfig              `json:"provider"`
 	Preferences NotificationPreferences `json:"preferences"`
+	Approvals   ApprovalSettings        `json:"approvals"`
 }
@@ -42,6 +42,12 @@ func humanSubject(eventType string) string {
 		return "Scheduled Task Completed Successfully"
 	case "tasks_scheduler.task_execution.failed":
 		return "Scheduled Task Execution Failed"
+	case "budget.limit.exceeded":
+		return "Budget Limit Reached"
+	case "claude.usage.anomaly_detected":
+		return "Unusual Claude Code Usage Detected"
+	case "claude.usage.limit_approaching":
+		return "Claude Usage Limit Approaching"
 	}
 	return eventType
 }
@@ -55,6 +61,12 @@ func shouldSendForEvent(eventType string, settings *NotificationSettings) bool {
 		return prefs.IsOnFinishedEnabled()
 	case "tasks_scheduler.task_execution.failed":
 		return prefs.IsOnFailedEnabled()
+	case "budget.limit.exceeded":
+		return settings.Preferences.Budgets.IsOnExceededEnabled()
+	case "claude.usage.anomaly_detected":
+		return settings.Preferences.Usage.IsOnAnomalyEnabled()
+	case "claude.usage.limit_approaching":
+		return settings.Preferences.Usage.IsOnLimitWarningEnabled()
 	}
 	return true
 }
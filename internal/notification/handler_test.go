@@ -281,6 +281,81 @@ func TestHandle_UnknownEvent_SubjectFallsBackToEventType(t *testing.T) {
 	assert.Contains(t, store.entries[0].Subject, "some.custom.event")
 }
 
+func TestHandle_BudgetExceeded_SubjectIsReadable(t *testing.T) {
+	store := &stubStore{}
+	loader := func() (*notification.NotificationSettings, error) {
+		return &notification.NotificationSettings{
+			Enabled: true,
+			Provider: notification.SMTPConfig{
+				Host: "localhost", Port: 9999,
+				FromAddr: "from@example.com", ToAddrs: "to@example.com",
+			},
+		}, nil
+	}
+	h := notification.NewNotificationHandler(loader, store, slog.Default())
+	h.Handle("budget.limit.exceeded", map[string]string{"Budget": "global", "Limit": "$5.00"})
+
+	require.Len(t, store.entries, 1)
+	assert.Contains(t, store.entries[0].Subject, "Budget Limit Reached")
+}
+
+func TestHandle_BudgetExceeded_ExplicitlyDisabled(t *testing.T) {
+	store := &stubStore{}
+	loader := func() (*notification.NotificationSettings, error) {
+		return &notification.NotificationSettings{
+			Enabled: true,
+			Provider: notification.SMTPConfig{
+				Host: "localhost", Port: 9999,
+				FromAddr: "from@example.com", ToAddrs: "to@example.com",
+			},
+			Preferences: notification.NotificationPreferences{
+				Budgets: notification.BudgetPreferences{OnExceeded: boolPtr(false)},
+			},
+		}, nil
+	}
+	h := notification.NewNotificationHandler(loader, store, slog.Default())
+	h.Handle("budget.limit.exceeded", map[string]string{"Budget": "global"})
+	assert.Empty(t, store.entries)
+}
+
+func TestHandle_UsageAnomaly_ExplicitlyDisabled(t *testing.T) {
+	store := &stubStore{}
+	loader := func() (*notification.NotificationSettings, error) {
+		return &notification.NotificationSettings{
+			Enabled: true,
+			Provider: notification.SMTPConfig{
+				Host: "localhost", Port: 9999,
+				FromAddr: "from@example.com", ToAddrs: "to@example.com",
+			},
+			Preferences: notification.NotificationPreferences{
+				Usage: notification.UsagePreferences{OnAnomaly: boolPtr(false)},
+			},
+		}, nil
+	}
+	h := notification.NewNotificationHandler(loader, store, slog.Default())
+	h.Handle("claude.usage.anomaly_detected", map[string]string{"scope": "day"})
+	assert.Empty(t, store.entries)
+}
+
+func TestHandle_UsageLimitWarning_ExplicitlyDisabled(t *testing.T) {
+	store := &stubStore{}
+	loader := func() (*notification.NotificationSettings, error) {
+		return &notification.NotificationSettings{
+			Enabled: true,
+			Provider: notification.SMTPConfig{
+				Host: "localhost", Port: 9999,
+				FromAddr: "from@example.com", ToAddrs: "to@example.com",
+			},
+			Preferences: notification.NotificationPreferences{
+				Usage: notification.UsagePreferences{OnLimitWarning: boolPtr(false)},
+			},
+		}, nil
+	}
+	h := notification.NewNotificationHandler(loader, store, slog.Default())
+	h.Handle("claude.usage.limit_approaching", map[string]string{"window": "five_hour"})
+	assert.Empty(t, store.entries)
+}
+
 // --- preference helper tests ---
 
 func TestScheduledTasksPreferences_Defaults(t *testing.T) {
@@ -0,0 +1,298 @@
+// Package pipeline plans and gates the steps of a multi-step scheduled task.
+// It holds no state: the scheduler runs the steps Plan returns, asking
+// ShouldRun before each one and building its prompt from Variables.
+package pipeline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// MaxTimeoutMinutes bounds a step's timeout, like a task's.
+const MaxTimeoutMinutes = 240
+
+// PreviousVariable names the answer of a step's last dependency in prompts.
+const PreviousVariable = "previous"
+
+// stepVariablePrefix prefixes the answer of any earlier step in prompts, as
+// in {{steps.collect}}.
+const stepVariablePrefix = "steps."
+
+var stepIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidationError describes an invalid pipeline definition. Field is the JSON
+// path of the offending value, e.g. "steps[1].depends_on".
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Result is what a finished or skipped step leaves for the steps after it.
+type Result struct {
+	Status storage.JobStatus
+	Answer string
+}
+
+// Plan validates steps and returns them in the order they run: every step
+// after the steps it depends on, and otherwise in the order listed. In the
+// returned copies DependsOn is explicit — a pipeline with no dependencies
+// declared becomes a chain — and RunIf is set on every step that has
+// dependencies.
+func Plan(steps []storage.PipelineStep) ([]storage.PipelineStep, error) {
+	if len(steps) == 0 {
+		return nil, nil
+	}
+
+	resolved, err := resolveSteps(steps)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered, err := topoSort(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkReferences(steps, ordered); err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+// resolveSteps checks each step on its own and fills in its dependencies.
+func resolveSteps(steps []storage.PipelineStep) ([]storage.PipelineStep, error) {
+	chain := true
+	for _, st := range steps {
+		if len(st.DependsOn) > 0 {
+			chain = false
+			break
+		}
+	}
+
+	index := make(map[string]int, len(steps))
+	for i, st := range steps {
+		field := fmt.Sprintf("steps[%d]", i)
+		if !stepIDPattern.MatchString(st.ID) {
+			return nil, &ValidationError{
+				Field:   field + ".id",
+				Message: "id is required and may only contain letters, digits, '-' and '_'",
+			}
+		}
+		if _, dup := index[st.ID]; dup {
+			return nil, &ValidationError{Field: field + ".id", Message: fmt.Sprintf("duplicate step id %q", st.ID)}
+		}
+		index[st.ID] = i
+
+		if strings.TrimSpace(st.Prompt) == "" {
+			return nil, &ValidationError{Field: field + ".prompt", Message: "prompt is required"}
+		}
+		if st.TimeoutMinutes < 0 || st.TimeoutMinutes > MaxTimeoutMinutes {
+			return nil, &ValidationError{
+				Field:   field + ".timeout_minutes",
+				Message: fmt.Sprintf("timeout must be between 1 and %d minutes", MaxTimeoutMinutes),
+			}
+		}
+		switch st.RunIf {
+		case "", storage.StepRunIfSuccess, storage.StepRunIfFailure, storage.StepRunIfAlways:
+		default:
+			return nil, &ValidationError{Field: field + ".run_if", Message: "must be success, failure, or always"}
+		}
+	}
+
+	out := make([]storage.PipelineStep, len(steps))
+	for i, st := range steps {
+		field := fmt.Sprintf("steps[%d]", i)
+		var deps []string
+		if chain && i > 0 {
+			deps = []string{steps[i-1].ID}
+		}
+		seen := make(map[string]bool, len(st.DependsOn))
+		for _, dep := range st.DependsOn {
+			if _, ok := index[dep]; !ok {
+				return nil, &ValidationError{Field: field + ".depends_on", Message: fmt.Sprintf("unknown step %q", dep)}
+			}
+			if dep == st.ID {
+				return nil, &ValidationError{Field: field + ".depends_on", Message: "a step cannot depend on itself"}
+			}
+			if !seen[dep] {
+				seen[dep] = true
+				deps = append(deps, dep)
+			}
+		}
+
+		if len(deps) == 0 && (st.RunIf != "" || st.IfContains != "") {
+			return nil, &ValidationError{
+				Field:   field + ".run_if",
+				Message: "a step without dependencies always runs, so it cannot have a condition",
+			}
+		}
+		if len(deps) > 0 && st.RunIf == "" {
+			st.RunIf = storage.StepRunIfSuccess
+		}
+		st.DependsOn = deps
+		out[i] = st
+	}
+	return out, nil
+}
+
+// topoSort orders steps so that each comes after its dependencies, keeping
+// the listed order among steps that are ready at the same time.
+func topoSort(steps []storage.PipelineStep) ([]storage.PipelineStep, error) {
+	placed := make(map[string]bool, len(steps))
+	ordered := make([]storage.PipelineStep, 0, len(steps))
+	for len(ordered) < len(steps) {
+		progress := false
+		for _, st := range steps {
+			if placed[st.ID] || !allPlaced(st.DependsOn, placed) {
+				continue
+			}
+			placed[st.ID] = true
+			ordered = append(ordered, st)
+			progress = true
+			break
+		}
+		if !progress {
+			var stuck []string
+			for _, st := range steps {
+				if !placed[st.ID] {
+					stuck = append(stuck, st.ID)
+				}
+			}
+			return nil, &ValidationError{
+				Field:   "steps",
+				Message: "dependency cycle between steps " + strings.Join(stuck, ", "),
+			}
+		}
+	}
+	return ordered, nil
+}
+
+func allPlaced(ids []string, placed map[string]bool) bool {
+	for _, id := range ids {
+		if !placed[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkReferences makes sure a prompt only uses the answers of steps that
+// are guaranteed to have finished before it: its transitive dependencies.
+// Errors point at the step's position in the original list.
+func checkReferences(original, ordered []storage.PipelineStep) error {
+	position := make(map[string]int, len(original))
+	for i, st := range original {
+		position[st.ID] = i
+	}
+
+	ancestors := make(map[string]map[string]bool, len(ordered))
+	for _, st := range ordered {
+		set := make(map[string]bool)
+		for _, dep := range st.DependsOn {
+			set[dep] = true
+			for a := range ancestors[dep] {
+				set[a] = true
+			}
+		}
+		ancestors[st.ID] = set
+
+		field := fmt.Sprintf("steps[%d].prompt", position[st.ID])
+		for _, name := range placeholders(st.Prompt) {
+			if name == PreviousVariable && len(st.DependsOn) == 0 {
+				return &ValidationError{
+					Field:   field,
+					Message: "{{previous}} needs a step to depend on",
+				}
+			}
+			ref, ok := strings.CutPrefix(name, stepVariablePrefix)
+			if ok && !set[ref] {
+				return &ValidationError{
+					Field:   field,
+					Message: fmt.Sprintf("{{%s}} does not refer to a step this one depends on", name),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// placeholders returns the variable names used in template, scanned the way
+// agent.Interpolate does.
+func placeholders(template string) []string {
+	var names []string
+	rest := template
+	for {
+		start := strings.Index(rest, "{{")
+		if start == -1 {
+			return names
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end == -1 {
+			return names
+		}
+		names = append(names, strings.TrimSpace(rest[start+2:start+end]))
+		rest = rest[start+end+2:]
+	}
+}
+
+// ShouldRun reports whether step may run given the results of the steps
+// before it. When it may not, reason says why, for the skipped step's record.
+func ShouldRun(step storage.PipelineStep, results map[string]Result) (ok bool, reason string) {
+	if len(step.DependsOn) == 0 {
+		return true, ""
+	}
+
+	switch step.RunIf {
+	case storage.StepRunIfAlways:
+	case storage.StepRunIfFailure:
+		failed := false
+		for _, dep := range step.DependsOn {
+			if s := results[dep].Status; s == storage.JobStatusFailed || s == storage.JobStatusBudgetBlocked {
+				failed = true
+				break
+			}
+		}
+		if !failed {
+			return false, "no dependency failed"
+		}
+	default:
+		for _, dep := range step.DependsOn {
+			if results[dep].Status != storage.JobStatusSuccess {
+				return false, fmt.Sprintf("dependency %q did not succeed", dep)
+			}
+		}
+	}
+
+	if step.IfContains != "" {
+		want := strings.ToLower(step.IfContains)
+		for _, dep := range step.DependsOn {
+			if strings.Contains(strings.ToLower(results[dep].Answer), want) {
+				return true, ""
+			}
+		}
+		return false, fmt.Sprintf("no dependency answer contains %q", step.IfContains)
+	}
+	return true, ""
+}
+
+// Variables returns the template variables for step's prompt: the answer of
+// every step that has finished as steps.<id>, and that of its last
+// dependency as previous. Skipped and failed steps contribute an empty
+// answer.
+func Variables(step storage.PipelineStep, results map[string]Result) map[string]string {
+	vars := make(map[string]string, len(results)+1)
+	for id, r := range results {
+		vars[stepVariablePrefix+id] = r.Answer
+	}
+	if n := len(step.DependsOn); n > 0 {
+		vars[PreviousVariable] = results[step.DependsOn[n-1]].Answer
+	}
+	return vars
+}
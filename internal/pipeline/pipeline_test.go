@@ -0,0 +1,187 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func stepIDs(steps []storage.PipelineStep) []string {
+	ids := make([]string, len(steps))
+	for i, st := range steps {
+		ids[i] = st.ID
+	}
+	return ids
+}
+
+func TestPlan_Chain(t *testing.T) {
+	steps, err := Plan([]storage.PipelineStep{
+		{ID: "fetch", Prompt: "Fetch the report"},
+		{ID: "summarize", Prompt: "Summarize: {{previous}}"},
+		{ID: "post", Prompt: "Post {{steps.summarize}} (raw: {{steps.fetch}})"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"fetch", "summarize", "post"}, stepIDs(steps))
+	assert.Empty(t, steps[0].DependsOn)
+	assert.Equal(t, []string{"fetch"}, steps[1].DependsOn)
+	assert.Equal(t, []string{"summarize"}, steps[2].DependsOn)
+	assert.Equal(t, storage.StepRunIfSuccess, steps[2].RunIf)
+}
+
+func TestPlan_DAG(t *testing.T) {
+	steps, err := Plan([]storage.PipelineStep{
+		{ID: "report", Prompt: "Combine {{steps.logs}} and {{steps.metrics}}", DependsOn: []string{"logs", "metrics"}},
+		{ID: "logs", Prompt: "Read the logs"},
+		{ID: "metrics", Prompt: "Read the metrics"},
+		{ID: "alert", Prompt: "Alert: {{previous}}", DependsOn: []string{"report"}, IfContains: "outage"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"logs", "metrics", "report", "alert"}, stepIDs(steps))
+	assert.Empty(t, steps[0].DependsOn, "explicit dependencies elsewhere leave roots alone")
+}
+
+func TestPlan_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		steps     []storage.PipelineStep
+		wantField string
+	}{
+		{
+			name:      "missing id",
+			steps:     []storage.PipelineStep{{Prompt: "p"}},
+			wantField: "steps[0].id",
+		},
+		{
+			name:      "id unusable in a variable",
+			steps:     []storage.PipelineStep{{ID: "a b", Prompt: "p"}},
+			wantField: "steps[0].id",
+		},
+		{
+			name:      "duplicate id",
+			steps:     []storage.PipelineStep{{ID: "a", Prompt: "p"}, {ID: "a", Prompt: "p"}},
+			wantField: "steps[1].id",
+		},
+		{
+			name:      "missing prompt",
+			steps:     []storage.PipelineStep{{ID: "a", Prompt: " "}},
+			wantField: "steps[0].prompt",
+		},
+		{
+			name:      "timeout out of range",
+			steps:     []storage.PipelineStep{{ID: "a", Prompt: "p", TimeoutMinutes: 300}},
+			wantField: "steps[0].timeout_minutes",
+		},
+		{
+			name:      "unknown condition",
+			steps:     []storage.PipelineStep{{ID: "a", Prompt: "p"}, {ID: "b", Prompt: "p", RunIf: "sometimes"}},
+			wantField: "steps[1].run_if",
+		},
+		{
+			name:      "condition on a root step",
+			steps:     []storage.PipelineStep{{ID: "a", Prompt: "p", IfContains: "x"}},
+			wantField: "steps[0].run_if",
+		},
+		{
+			name:      "unknown dependency",
+			steps:     []storage.PipelineStep{{ID: "a", Prompt: "p", DependsOn: []string{"b"}}},
+			wantField: "steps[0].depends_on",
+		},
+		{
+			name:      "self dependency",
+			steps:     []storage.PipelineStep{{ID: "a", Prompt: "p", DependsOn: []string{"a"}}},
+			wantField: "steps[0].depends_on",
+		},
+		{
+			name: "cycle",
+			steps: []storage.PipelineStep{
+				{ID: "a", Prompt: "p", DependsOn: []string{"b"}},
+				{ID: "b", Prompt: "p", DependsOn: []string{"a"}},
+			},
+			wantField: "steps",
+		},
+		{
+			name: "reference to a step that may not have run",
+			steps: []storage.PipelineStep{
+				{ID: "a", Prompt: "p"},
+				{ID: "b", Prompt: "p"},
+				{ID: "c", Prompt: "{{steps.b}}", DependsOn: []string{"a"}},
+			},
+			wantField: "steps[2].prompt",
+		},
+		{
+			name:      "previous without a dependency",
+			steps:     []storage.PipelineStep{{ID: "a", Prompt: "{{previous}}"}},
+			wantField: "steps[0].prompt",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Plan(tc.steps)
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			assert.Equal(t, tc.wantField, verr.Field, verr.Message)
+		})
+	}
+}
+
+func TestShouldRun(t *testing.T) {
+	results := map[string]Result{
+		"ok":      {Status: storage.JobStatusSuccess, Answer: "Found an OUTAGE in eu-west"},
+		"broken":  {Status: storage.JobStatusFailed},
+		"blocked": {Status: storage.JobStatusBudgetBlocked},
+		"skipped": {Status: storage.JobStatusSkipped},
+	}
+
+	tests := []struct {
+		name string
+		step storage.PipelineStep
+		want bool
+	}{
+		{name: "root", step: storage.PipelineStep{}, want: true},
+		{name: "success after success", step: storage.PipelineStep{DependsOn: []string{"ok"}, RunIf: "success"}, want: true},
+		{name: "success after failure", step: storage.PipelineStep{DependsOn: []string{"ok", "broken"}}, want: false},
+		{name: "success after skip", step: storage.PipelineStep{DependsOn: []string{"skipped"}}, want: false},
+		{name: "failure after failure", step: storage.PipelineStep{DependsOn: []string{"ok", "broken"}, RunIf: "failure"}, want: true},
+		{name: "failure after budget block", step: storage.PipelineStep{DependsOn: []string{"blocked"}, RunIf: "failure"}, want: true},
+		{name: "failure after success", step: storage.PipelineStep{DependsOn: []string{"ok"}, RunIf: "failure"}, want: false},
+		{name: "always", step: storage.PipelineStep{DependsOn: []string{"broken", "skipped"}, RunIf: "always"}, want: true},
+		{
+			name: "contains, case-insensitive",
+			step: storage.PipelineStep{DependsOn: []string{"ok"}, RunIf: "success", IfContains: "outage"},
+			want: true,
+		},
+		{
+			name: "does not contain",
+			step: storage.PipelineStep{DependsOn: []string{"ok"}, RunIf: "success", IfContains: "all clear"},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, reason := ShouldRun(tc.step, results)
+			assert.Equal(t, tc.want, got)
+			if !got {
+				assert.NotEmpty(t, reason)
+			}
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	results := map[string]Result{
+		"logs":    {Status: storage.JobStatusSuccess, Answer: "log answer"},
+		"metrics": {Status: storage.JobStatusFailed},
+	}
+	vars := Variables(storage.PipelineStep{DependsOn: []string{"metrics", "logs"}}, results)
+	assert.Equal(t, map[string]string{
+		"steps.logs":    "log answer",
+		"steps.metrics": "",
+		"previous":      "log answer",
+	}, vars)
+}
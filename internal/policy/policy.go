@@ -0,0 +1,258 @@
+// Package policy evaluates an agent's tool policy — the allow, deny and ask
+// rules on tool arguments declared under capabilities.policy — against
+// individual tool calls. The runner consults it before every call; the
+// approval broker consults it to tell which calls of an unattended run must
+// wait for a person.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+// pathFields are the input fields, in order of preference, that name the
+// file or directory a tool works on: file_path for Read, Write and Edit,
+// notebook_path for NotebookEdit, path for Glob and Grep.
+var pathFields = []string{"file_path", "notebook_path", "path"}
+
+// Decision is the outcome of evaluating a call.
+type Decision struct {
+	// Action is "allow", "deny" or "ask", or empty when no rule matched.
+	Action string
+	// Rule is the index of the matching rule, or -1.
+	Rule int
+	// Reason is the matching rule's reason.
+	Reason string
+}
+
+// Matched reports whether a rule matched the call.
+func (d Decision) Matched() bool {
+	return d.Rule >= 0
+}
+
+// Policy is a compiled config.ToolPolicy. A nil *Policy matches nothing.
+type Policy struct {
+	dryRun bool
+	rules  []rule
+}
+
+type rule struct {
+	tool    string
+	action  string
+	reason  string
+	command *regexp.Regexp
+	paths   []*regexp.Regexp
+	hosts   []string
+	params  map[string]*regexp.Regexp
+}
+
+// Compile validates cfg and compiles its patterns. It returns nil for a nil
+// or empty policy.
+func Compile(cfg *config.ToolPolicy) (*Policy, error) {
+	if cfg == nil || len(cfg.Rules) == 0 {
+		return nil, nil
+	}
+	p := &Policy{dryRun: cfg.DryRun, rules: make([]rule, 0, len(cfg.Rules))}
+	for i := range cfg.Rules {
+		r, err := compileRule(&cfg.Rules[i])
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p, nil
+}
+
+func compileRule(cfg *config.PolicyRule) (rule, error) {
+	r := rule{tool: strings.TrimSpace(cfg.Tool), action: cfg.Action, reason: cfg.Reason, hosts: cfg.Hosts}
+	if r.tool == "" {
+		return rule{}, fmt.Errorf("missing tool")
+	}
+	switch r.action {
+	case config.PolicyAllow, config.PolicyDeny, config.PolicyAsk:
+	default:
+		return rule{}, fmt.Errorf("invalid action %q: must be allow, deny or ask", r.action)
+	}
+
+	var err error
+	if cfg.Command != "" {
+		if r.command, err = regexp.Compile(cfg.Command); err != nil {
+			return rule{}, fmt.Errorf("invalid command pattern: %w", err)
+		}
+	}
+	for _, glob := range cfg.Paths {
+		re, err := compileGlob(glob)
+		if err != nil {
+			return rule{}, fmt.Errorf("invalid path glob %q: %w", glob, err)
+		}
+		r.paths = append(r.paths, re)
+	}
+	if r.params, err = compileParams(cfg.Params); err != nil {
+		return rule{}, err
+	}
+	return r, nil
+}
+
+func compileParams(params map[string]string) (map[string]*regexp.Regexp, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	compiled := make(map[string]*regexp.Regexp, len(params))
+	for name, pattern := range params {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for parameter %q: %w", name, err)
+		}
+		compiled[name] = re
+	}
+	return compiled, nil
+}
+
+// compileGlob turns a path glob into an anchored regular expression: "**"
+// matches across directories, "*" and "?" within one.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	if rest, ok := strings.CutPrefix(glob, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("resolving home directory: %w", err)
+		}
+		glob = filepath.Join(home, rest)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// DryRun reports whether decisions are only to be logged, not enforced.
+func (p *Policy) DryRun() bool {
+	return p != nil && p.dryRun
+}
+
+// Asks reports whether an enforced rule asks for approval of this call.
+func (p *Policy) Asks(toolName string, input json.RawMessage) bool {
+	return !p.DryRun() && p.Evaluate(toolName, input).Action == config.PolicyAsk
+}
+
+// Evaluate returns the decision of the first rule matching the call.
+func (p *Policy) Evaluate(toolName string, input json.RawMessage) Decision {
+	if p == nil {
+		return Decision{Rule: -1}
+	}
+	var args map[string]any
+	if err := json.Unmarshal(input, &args); err != nil {
+		// Input that is not a JSON object matches only rules without
+		// conditions.
+		args = nil
+	}
+	for i := range p.rules {
+		r := &p.rules[i]
+		if config.MatchToolName(r.tool, toolName) && r.matches(args) {
+			return Decision{Action: r.action, Rule: i, Reason: r.reason}
+		}
+	}
+	return Decision{Rule: -1}
+}
+
+// matches reports whether args meet every condition of r.
+func (r *rule) matches(args map[string]any) bool {
+	if r.command != nil {
+		command, ok := args["command"].(string)
+		if !ok || !r.command.MatchString(command) {
+			return false
+		}
+	}
+	if len(r.paths) > 0 && !r.matchesPath(args) {
+		return false
+	}
+	if len(r.hosts) > 0 && !r.matchesHost(args) {
+		return false
+	}
+	for name, re := range r.params {
+		value, ok := paramString(args, name)
+		if !ok || !re.MatchString(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *rule) matchesPath(args map[string]any) bool {
+	for _, field := range pathFields {
+		path, ok := args[field].(string)
+		if !ok || path == "" {
+			continue
+		}
+		path = filepath.Clean(path)
+		for _, re := range r.paths {
+			if re.MatchString(path) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func (r *rule) matchesHost(args map[string]any) bool {
+	raw, ok := args["url"].(string)
+	if !ok {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, pattern := range r.hosts {
+		pattern = strings.ToLower(pattern)
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// paramString returns the named argument as a string: strings as they are,
+// anything else as JSON.
+func paramString(args map[string]any, name string) (string, bool) {
+	v, ok := args[name]
+	if !ok {
+		return "", false
+	}
+	if s, ok := v.(string); ok {
+		return s, true
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
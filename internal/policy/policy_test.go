@@ -0,0 +1,110 @@
+package policy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+func compile(t *testing.T, rules ...config.PolicyRule) *Policy {
+	t.Helper()
+	p, err := Compile(&config.ToolPolicy{Rules: rules})
+	require.NoError(t, err)
+	return p
+}
+
+func TestCompile(t *testing.T) {
+	p, err := Compile(nil)
+	require.NoError(t, err)
+	assert.Nil(t, p)
+
+	tests := []struct {
+		name    string
+		rule    config.PolicyRule
+		wantErr string
+	}{
+		{name: "missing tool", rule: config.PolicyRule{Action: "deny"}, wantErr: "rule 1: missing tool"},
+		{name: "unknown action", rule: config.PolicyRule{Tool: "Bash", Action: "block"}, wantErr: "invalid action"},
+		{
+			name:    "bad command pattern",
+			rule:    config.PolicyRule{Tool: "Bash", Action: "deny", Command: "("},
+			wantErr: "invalid command pattern",
+		},
+		{
+			name:    "bad parameter pattern",
+			rule:    config.PolicyRule{Tool: "mcp__x__y", Action: "ask", Params: map[string]string{"repo": "["}},
+			wantErr: `invalid pattern for parameter "repo"`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Compile(&config.ToolPolicy{Rules: []config.PolicyRule{tc.rule}})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	p := compile(t,
+		config.PolicyRule{Tool: "Bash", Action: "deny", Command: `\bsudo\b`},
+		config.PolicyRule{Tool: "Bash", Action: "allow", Command: `^(ls|cat|git status)\b`},
+		config.PolicyRule{Tool: "Bash", Action: "ask"},
+		config.PolicyRule{Tool: "Write", Action: "allow", Paths: []string{"/srv/app/**"}},
+		config.PolicyRule{Tool: "Read", Action: "deny", Paths: []string{"~/.ssh/*", "**/.env"}},
+		config.PolicyRule{Tool: "WebFetch", Action: "allow", Hosts: []string{"docs.python.org", "*.github.com"}},
+		config.PolicyRule{Tool: "mcp__github__*", Action: "ask", Params: map[string]string{"repo": `^acme/prod-`}},
+		config.PolicyRule{Tool: "mcp__jira__create_issue", Action: "deny", Params: map[string]string{"priority": `^1$`}},
+	)
+
+	tests := []struct {
+		tool       string
+		input      string
+		wantAction string
+		wantRule   int
+	}{
+		{tool: "Bash", input: `{"command":"sudo rm x"}`, wantAction: "deny", wantRule: 0},
+		{tool: "Bash", input: `{"command":"git status"}`, wantAction: "allow", wantRule: 1},
+		{tool: "Bash", input: `{"command":"make deploy"}`, wantAction: "ask", wantRule: 2},
+		{tool: "Write", input: `{"file_path":"/srv/app/cmd/main.go"}`, wantAction: "allow", wantRule: 3},
+		{tool: "Write", input: `{"file_path":"/srv/app/../../etc/passwd"}`, wantRule: -1},
+		{tool: "Read", input: `{"file_path":"` + filepath.Join(home, ".ssh", "id_ed25519") + `"}`, wantAction: "deny", wantRule: 4},
+		{tool: "Read", input: `{"file_path":"/srv/app/.env"}`, wantAction: "deny", wantRule: 4},
+		{tool: "Read", input: `{"file_path":"/srv/app/main.go"}`, wantRule: -1},
+		{tool: "WebFetch", input: `{"url":"https://api.github.com/x"}`, wantAction: "allow", wantRule: 5},
+		{tool: "WebFetch", input: `{"url":"https://github.com.evil.io/x"}`, wantRule: -1},
+		{tool: "WebFetch", input: `{"url":"https://DOCS.python.org/3/"}`, wantAction: "allow", wantRule: 5},
+		{tool: "mcp__github__merge_pr", input: `{"repo":"acme/prod-api"}`, wantAction: "ask", wantRule: 6},
+		{tool: "mcp__github__merge_pr", input: `{"repo":"acme/sandbox"}`, wantRule: -1},
+		{tool: "mcp__jira__create_issue", input: `{"priority":1}`, wantAction: "deny", wantRule: 7},
+		{tool: "Glob", input: `{"pattern":"*"}`, wantRule: -1},
+	}
+	for _, tc := range tests {
+		d := p.Evaluate(tc.tool, json.RawMessage(tc.input))
+		assert.Equal(t, tc.wantAction, d.Action, "%s %s", tc.tool, tc.input)
+		assert.Equal(t, tc.wantRule, d.Rule, "%s %s", tc.tool, tc.input)
+	}
+}
+
+func TestAsks(t *testing.T) {
+	rule := config.PolicyRule{Tool: "Bash", Action: "ask"}
+	assert.True(t, compile(t, rule).Asks("Bash", json.RawMessage(`{"command":"ls"}`)))
+	assert.False(t, compile(t, rule).Asks("Read", nil))
+
+	dryRun, err := Compile(&config.ToolPolicy{DryRun: true, Rules: []config.PolicyRule{rule}})
+	require.NoError(t, err)
+	assert.False(t, dryRun.Asks("Bash", nil), "a dry run never asks")
+
+	var none *Policy
+	assert.False(t, none.Asks("Bash", nil))
+	assert.False(t, none.Evaluate("Bash", nil).Matched())
+}
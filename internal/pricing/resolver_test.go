@@ -203,6 +203,15 @@ func TestBuiltinCatalog_Parses(t *testing.T) {
 			t.Errorf("missing seed row for %q", p)
 		}
 	}
+	// The models of the coding agents whose sessions can be imported.
+	for _, p := range []string{
+		"gpt-5", "gpt-5-mini", "gpt-5-nano", "gpt-4.1", "o3", "o4-mini", "codex-mini",
+		"gemini-2.5-pro", "gemini-2.5-flash", "gemini-2.5-flash-lite",
+	} {
+		if seen[p] == 0 {
+			t.Errorf("missing seed row for %q", p)
+		}
+	}
 }
 
 // TestBuiltinCatalog_BillableMatchesZeroRates enforces the invariant that keeps
@@ -465,11 +474,11 @@ func allBuiltinRates(t *testing.T) []Rate {
 	return all
 }
 
-// TestBuiltinCatalog_QwenContextTiers pins the seeded bands to what Alibaba
-// publishes (#218). These are the numbers that decide whether a long-context
-// session is priced right, and nothing else in the catalog contradicts them,
-// so an edit slip would be silent.
-func TestBuiltinCatalog_QwenContextTiers(t *testing.T) {
+// TestBuiltinCatalog_ContextTiers pins the seeded bands to what Alibaba
+// (#218) and Google publish. These are the numbers that decide whether a
+// long-context session is priced right, and nothing else in the catalog
+// contradicts them, so an edit slip would be silent.
+func TestBuiltinCatalog_ContextTiers(t *testing.T) {
 	want := map[string][]TierRate{
 		"qwen3.7-plus": {
 			{MaxInputTokens: 256_000, InputPerMTok: 0.4, OutputPerMTok: 1.6},
@@ -488,6 +497,10 @@ func TestBuiltinCatalog_QwenContextTiers(t *testing.T) {
 			{MaxInputTokens: 128_000, InputPerMTok: 2.4, OutputPerMTok: 12.0},
 			{MaxInputTokens: 256_000, InputPerMTok: 3.0, OutputPerMTok: 15.0},
 		},
+		"gemini-2.5-pro": {
+			{MaxInputTokens: 200_000, InputPerMTok: 1.25, OutputPerMTok: 10.0},
+			{MaxInputTokens: 1_048_576, InputPerMTok: 2.5, OutputPerMTok: 15.0},
+		},
 	}
 
 	seen := map[string]bool{}
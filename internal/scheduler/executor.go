@@ -2,22 +2,36 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
+	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/approval"
+	"github.com/shaharia-lab/agento/internal/audit"
 	"github.com/shaharia-lab/agento/internal/config"
 	"github.com/shaharia-lab/agento/internal/storage"
 )
 
-// executeTask runs a single task execution with concurrency limiting.
+// executeTask runs a scheduled execution of a task. It supersedes any retry
+// still pending from the task's previous run.
 func (s *Scheduler) executeTask(taskID string) {
+	s.mu.Lock()
+	s.cancelRetry(taskID)
+	s.mu.Unlock()
+
+	s.execute(taskID, firstAttempt)
+}
+
+// execute runs one attempt of a task with concurrency limiting.
+func (s *Scheduler) execute(taskID string, attempt runAttempt) {
 	// Acquire semaphore.
 	s.semaphore <- struct{}{}
 	defer func() { <-s.semaphore }()
@@ -26,7 +40,10 @@ func (s *Scheduler) executeTask(taskID string) {
 	// scheduled tasks are not triggered by an HTTP request — they start a new
 	// trace rooted here.
 	ctx, span := otel.Tracer("agento").Start(context.Background(), "scheduler.task.execute")
-	span.SetAttributes(attribute.String("scheduler.task_id", taskID))
+	span.SetAttributes(
+		attribute.String("scheduler.task_id", taskID),
+		attribute.Int("scheduler.attempt", attempt.number),
+	)
 	defer span.End()
 
 	task, err := s.cfg.TaskStore.GetTask(ctx, taskID)
@@ -47,15 +64,16 @@ func (s *Scheduler) executeTask(taskID string) {
 		attribute.String("scheduler.trigger", "scheduled"),
 	)
 
-	if s.shouldAutoPause(ctx, task) {
+	// A retry belongs to a run that already passed the stop conditions.
+	if attempt.number == 1 && s.shouldAutoPause(ctx, task) {
 		return
 	}
 
 	s.logger.Info("executing task",
 		"task_id", task.ID, "task_name", task.Name,
-		"run_count", task.RunCount+1)
+		"run_count", task.RunCount+1, "attempt", attempt.number)
 
-	s.runTask(ctx, task, span)
+	s.runTask(ctx, task, span, attempt)
 }
 
 // shouldAutoPause checks stop conditions and pauses the task if met.
@@ -75,14 +93,14 @@ func (s *Scheduler) shouldAutoPause(ctx context.Context, task *storage.Scheduled
 // initial job history record. On any failure it records the failed run,
 // publishes the failed event, and returns a non-nil error.
 func (s *Scheduler) prepareTaskRun(
-	ctx context.Context, task *storage.ScheduledTask, startedAt time.Time,
+	ctx context.Context, task *storage.ScheduledTask, startedAt time.Time, attempt runAttempt,
 ) (prompt string, chatSession *storage.ChatSession, jh *storage.JobHistory, err error) {
 	prompt, err = agent.Interpolate(task.Prompt, nil)
 	if err != nil {
 		errMsg := fmt.Sprintf("prompt interpolation: %v", err)
 		s.logger.Error("failed to interpolate prompt", "task_id", task.ID, "error", err)
-		s.recordFailedRun(ctx, task, startedAt, "", errMsg)
-		s.publishTaskFailed(task, errMsg)
+		s.recordFailedRun(ctx, task, startedAt, attempt, "", errMsg)
+		s.publishTaskFailed(task, errMsg, attempt.number)
 		return "", nil, nil, err
 	}
 
@@ -90,22 +108,29 @@ func (s *Scheduler) prepareTaskRun(
 	if err != nil {
 		errMsg := fmt.Sprintf("create session: %v", err)
 		s.logger.Error("failed to create chat session", "task_id", task.ID, "error", err)
-		s.recordFailedRun(ctx, task, startedAt, "", errMsg)
-		s.publishTaskFailed(task, errMsg)
+		s.recordFailedRun(ctx, task, startedAt, attempt, "", errMsg)
+		s.publishTaskFailed(task, errMsg, attempt.number)
 		return "", nil, nil, err
 	}
 
-	jh = s.createInitialJobHistory(ctx, task, startedAt, chatSession.ID, prompt)
+	jh = s.createInitialJobHistory(ctx, task, startedAt, attempt, chatSession.ID, prompt)
 	return prompt, chatSession, jh, nil
 }
 
 // runTask performs the core task execution: prompt interpolation, session
 // creation, agent invocation, and result recording.
 // parentCtx carries the root trace span from executeTask.
-func (s *Scheduler) runTask(parentCtx context.Context, task *storage.ScheduledTask, parentSpan trace.Span) {
+func (s *Scheduler) runTask(
+	parentCtx context.Context, task *storage.ScheduledTask, parentSpan trace.Span, attempt runAttempt,
+) {
+	if len(task.Steps) > 0 {
+		s.runPipeline(parentCtx, task, parentSpan, attempt)
+		return
+	}
+
 	startedAt := time.Now().UTC()
 
-	prompt, chatSession, jh, err := s.prepareTaskRun(parentCtx, task, startedAt)
+	prompt, chatSession, jh, err := s.prepareTaskRun(parentCtx, task, startedAt, attempt)
 	if err != nil {
 		parentSpan.RecordError(err)
 		parentSpan.SetStatus(codes.Error, err.Error())
@@ -117,29 +142,39 @@ func (s *Scheduler) runTask(parentCtx context.Context, task *storage.ScheduledTa
 		errMsg := fmt.Sprintf("resolve agent: %v", err)
 		s.logger.Error("failed to resolve agent config",
 			"task_id", task.ID, "error", err)
-		s.finishJobHistory(parentCtx, jh, startedAt, storage.JobStatusFailed,
-			errMsg, agent.UsageStats{}, "")
-		s.updateTaskAfterRun(parentCtx, task, startedAt, "failed")
-		s.publishTaskFailed(task, errMsg)
+		// A missing or broken agent fails every attempt the same way, so
+		// this is never retried.
+		s.failRun(parentCtx, task, jh, startedAt, attempt, errMsg, "", agent.UsageStats{})
 		parentSpan.RecordError(err)
 		parentSpan.SetStatus(codes.Error, errMsg)
 		return
 	}
 
-	opts := s.buildRunOptions(task)
-
 	timeout := time.Duration(task.TimeoutMinutes) * time.Minute
 	ctx, cancel := context.WithTimeout(parentCtx, timeout)
 	defer cancel()
 
+	opts := s.buildRunOptions(ctx, task, agentCfg, jh.ID, chatSession.ID)
+
 	result, err := agent.RunAgent(ctx, agentCfg, prompt, opts)
+	var budgetErr *agent.BudgetExceededError
+	if errors.As(err, &budgetErr) {
+		// The enforcer has already published the budget event, so the
+		// task-failed one is not sent on top of it.
+		s.logger.Warn("task run blocked by budget",
+			"task_id", task.ID, "policy_id", budgetErr.PolicyID, "error", err)
+		s.finishJobHistory(parentCtx, jh, startedAt, storage.JobStatusBudgetBlocked,
+			err.Error(), agent.UsageStats{}, "")
+		s.updateTaskAfterRun(parentCtx, task, startedAt, string(storage.JobStatusBudgetBlocked))
+		parentSpan.RecordError(err)
+		parentSpan.SetStatus(codes.Error, err.Error())
+		return
+	}
 	if err != nil {
 		s.logger.Error("task execution failed",
-			"task_id", task.ID, "error", err)
-		s.finishJobHistory(parentCtx, jh, startedAt, storage.JobStatusFailed,
-			err.Error(), agent.UsageStats{}, "")
-		s.updateTaskAfterRun(parentCtx, task, startedAt, "failed")
-		s.publishTaskFailed(task, err.Error())
+			"task_id", task.ID, "attempt", attempt.number, "error", err)
+		s.failRun(parentCtx, task, jh, startedAt, attempt,
+			err.Error(), classifyRunError(ctx, err), agent.UsageStats{})
 		parentSpan.RecordError(err)
 		parentSpan.SetStatus(codes.Error, err.Error())
 		return
@@ -184,7 +219,7 @@ func (s *Scheduler) createTaskSession(
 // createInitialJobHistory creates and persists an initial job history record.
 func (s *Scheduler) createInitialJobHistory(
 	ctx context.Context, task *storage.ScheduledTask, startedAt time.Time,
-	chatSessionID, prompt string,
+	attempt runAttempt, chatSessionID, prompt string,
 ) *storage.JobHistory {
 	promptPreview := prompt
 	if len(promptPreview) > 200 {
@@ -200,6 +235,7 @@ func (s *Scheduler) createInitialJobHistory(
 		Model:         task.Model,
 		PromptPreview: promptPreview,
 	}
+	attempt.apply(jh)
 	if err := s.cfg.TaskStore.CreateJobHistory(ctx, jh); err != nil {
 		s.logger.Error("failed to create job history",
 			"task_id", task.ID, "error", err)
@@ -207,9 +243,41 @@ func (s *Scheduler) createInitialJobHistory(
 	return jh
 }
 
-// buildRunOptions constructs the agent RunOptions for a task.
-func (s *Scheduler) buildRunOptions(task *storage.ScheduledTask) agent.RunOptions {
+// buildRunOptions constructs the agent RunOptions for a task run recorded
+// as job jobID in chat session chatSessionID. ctx bounds the run; gated tool
+// calls stop waiting for approval when it ends.
+func (s *Scheduler) buildRunOptions(
+	ctx context.Context, task *storage.ScheduledTask, agentCfg *config.AgentConfig, jobID, chatSessionID string,
+) agent.RunOptions {
+	var guard agent.BudgetGuard
+	if s.cfg.Budget != nil {
+		guard = s.cfg.Budget.ForRun(task.AgentSlug, task.ID, "scheduled_task")
+	}
+	var permissions claude.PermissionHandler
+	if s.cfg.Approvals != nil {
+		permissions = s.cfg.Approvals.PermissionHandler(ctx, agentCfg, approval.Run{
+			Source:        "scheduled_task",
+			AgentSlug:     task.AgentSlug,
+			TaskID:        task.ID,
+			JobID:         jobID,
+			ChatSessionID: chatSessionID,
+			Title:         task.Name,
+		})
+	}
+	var auditor agent.ToolAuditor
+	if s.cfg.Audit != nil {
+		auditor = s.cfg.Audit.ForRun(audit.Run{
+			Source:        audit.SourceScheduledTask,
+			AgentSlug:     task.AgentSlug,
+			TaskID:        task.ID,
+			JobID:         jobID,
+			ChatSessionID: chatSessionID,
+		})
+	}
 	return agent.RunOptions{
+		PermissionHandler:   permissions,
+		Budget:              guard,
+		Audit:               auditor,
 		LocalToolsMCP:       s.cfg.LocalMCP,
 		MCPRegistry:         s.cfg.MCPRegistry,
 		IntegrationRegistry: s.cfg.IntegrationRegistry,
@@ -326,7 +394,8 @@ func (s *Scheduler) updateTaskAfterRun(
 }
 
 func (s *Scheduler) recordFailedRun(
-	ctx context.Context, task *storage.ScheduledTask, startedAt time.Time, chatSessionID, errMsg string,
+	ctx context.Context, task *storage.ScheduledTask, startedAt time.Time, attempt runAttempt,
+	chatSessionID, errMsg string,
 ) {
 	jh := &storage.JobHistory{
 		TaskID:        task.ID,
@@ -337,6 +406,7 @@ func (s *Scheduler) recordFailedRun(
 		ChatSessionID: chatSessionID,
 		ErrorMessage:  errMsg,
 	}
+	attempt.apply(jh)
 	now := time.Now().UTC()
 	jh.FinishedAt = &now
 	jh.DurationMS = now.Sub(startedAt).Milliseconds()
@@ -377,7 +447,8 @@ func (s *Scheduler) publishTaskFinished(
 }
 
 // publishTaskFailed publishes a task-failed event with the error details.
-func (s *Scheduler) publishTaskFailed(task *storage.ScheduledTask, errMsg string) {
+// attempts is how many times the run was tried.
+func (s *Scheduler) publishTaskFailed(task *storage.ScheduledTask, errMsg string, attempts int) {
 	if s.cfg.EventPublisher == nil {
 		return
 	}
@@ -389,5 +460,6 @@ func (s *Scheduler) publishTaskFailed(task *storage.ScheduledTask, errMsg string
 		"Status":           "Failed",
 		"Error":            errMsg,
 		"Run Count":        strconv.Itoa(task.RunCount),
+		"Attempts":         strconv.Itoa(attempts),
 	})
 }
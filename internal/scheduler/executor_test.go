@@ -3,6 +3,7 @@ package scheduler_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"sync"
@@ -12,6 +13,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/pricing"
 	"github.com/shaharia-lab/agento/internal/scheduler"
 	"github.com/shaharia-lab/agento/internal/storage"
 )
@@ -28,6 +31,7 @@ type stubTaskStore struct {
 	mu      sync.Mutex
 	tasks   map[string]*storage.ScheduledTask
 	history []*storage.JobHistory
+	steps   []*storage.JobStep
 }
 
 func newStubTaskStore(tasks ...*storage.ScheduledTask) *stubTaskStore {
@@ -68,6 +72,9 @@ func (s *stubTaskStore) DeleteTask(_ context.Context, _ string) error { return n
 func (s *stubTaskStore) CreateJobHistory(_ context.Context, jh *storage.JobHistory) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if jh.ID == "" {
+		jh.ID = fmt.Sprintf("job-%d", len(s.history)+1)
+	}
 	s.history = append(s.history, jh)
 	return nil
 }
@@ -76,7 +83,7 @@ func (s *stubTaskStore) UpdateJobHistory(_ context.Context, jh *storage.JobHisto
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for i, h := range s.history {
-		if h.TaskID == jh.TaskID {
+		if h.ID == jh.ID {
 			s.history[i] = jh
 			return nil
 		}
@@ -97,6 +104,15 @@ func (s *stubTaskStore) ListAllJobHistory(_ context.Context, _, _ int) ([]*stora
 func (s *stubTaskStore) DeleteJobHistory(_ context.Context, _ string) error       { return nil }
 func (s *stubTaskStore) BulkDeleteJobHistory(_ context.Context, _ []string) error { return nil }
 
+func (s *stubTaskStore) CreateJobStep(_ context.Context, step *storage.JobStep) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps = append(s.steps, step)
+	return nil
+}
+
+func (s *stubTaskStore) UpdateJobStep(_ context.Context, _ *storage.JobStep) error { return nil }
+
 // --- ChatStore stub ---
 
 type stubChatStore struct {
@@ -365,3 +381,252 @@ func TestScheduler_NoPublisherOnPausedTask(t *testing.T) {
 		s.ExportedExecuteTask(task.ID)
 	})
 }
+
+// --- BudgetEnforcer stub ---
+
+type refusingBudget struct {
+	settled bool
+}
+
+func (b *refusingBudget) ForRun(_, _, _ string) agent.BudgetGuard { return b }
+
+func (b *refusingBudget) Admit(_ context.Context) error {
+	return &agent.BudgetExceededError{PolicyID: "p1", Scope: "global", Period: "daily", LimitUSD: 1, SpentUSD: 1}
+}
+
+func (b *refusingBudget) Observe(_, _ string, _ pricing.Usage) error { return nil }
+
+func (b *refusingBudget) Settle(_ context.Context, _ float64) { b.settled = true }
+
+// TestRunTask_BudgetBlocked verifies that a run refused by the budget guard is
+// recorded with the budget_blocked status and does not publish task-failed.
+func TestRunTask_BudgetBlocked(t *testing.T) {
+	task := buildTask("b1", "Budgeted Task")
+	// No agent slug: the scheduler synthesizes a config, so the run reaches
+	// the runner without an AgentStore, and Admit refuses it before the SDK.
+	task.AgentSlug = ""
+	ts := newStubTaskStore(task)
+	pub := &stubEventPublisher{}
+	budget := &refusingBudget{}
+
+	s, err := scheduler.New(scheduler.Config{
+		TaskStore:      ts,
+		ChatStore:      &stubChatStore{},
+		Logger:         newTestLogger(),
+		MaxConcurrency: 1,
+		EventPublisher: pub,
+		Budget:         budget,
+	})
+	require.NoError(t, err)
+
+	s.ExportedExecuteTask(task.ID)
+
+	ts.mu.Lock()
+	require.Len(t, ts.history, 1)
+	assert.Equal(t, storage.JobStatusBudgetBlocked, ts.history[0].Status)
+	assert.Contains(t, ts.history[0].ErrorMessage, "budget exceeded")
+	ts.mu.Unlock()
+
+	stored, _ := ts.GetTask(context.Background(), task.ID)
+	assert.Equal(t, string(storage.JobStatusBudgetBlocked), stored.LastRunStatus)
+	assert.False(t, budget.settled, "a refused run never started, so nothing is charged")
+	assert.Empty(t, pub.waitForEvents(1, 50*time.Millisecond), "task-failed must not fire for a budget block")
+}
+
+// TestRunTask_Pipeline verifies that a pipeline run is recorded as one job
+// with a step record per step, and that step conditions are applied. Every
+// step that runs is refused by the budget guard, so the SDK is never reached.
+func TestRunTask_Pipeline(t *testing.T) {
+	task := buildTask("p1", "Pipeline Task")
+	task.AgentSlug = ""
+	task.Steps = []storage.PipelineStep{
+		{ID: "collect", Prompt: "Collect"},
+		{ID: "report", Prompt: "Report on {{previous}}", DependsOn: []string{"collect"}},
+		{ID: "recover", Prompt: "Recover", DependsOn: []string{"collect"}, RunIf: storage.StepRunIfFailure},
+	}
+	ts := newStubTaskStore(task)
+
+	s, err := scheduler.New(scheduler.Config{
+		TaskStore:      ts,
+		ChatStore:      &stubChatStore{},
+		Logger:         newTestLogger(),
+		MaxConcurrency: 1,
+		Budget:         &refusingBudget{},
+	})
+	require.NoError(t, err)
+
+	s.ExportedExecuteTask(task.ID)
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	require.Len(t, ts.history, 1)
+	assert.Equal(t, storage.JobStatusBudgetBlocked, ts.history[0].Status)
+	assert.Contains(t, ts.history[0].ErrorMessage, `step "collect"`)
+
+	require.Len(t, ts.steps, 3)
+	for i, want := range []struct {
+		id     string
+		status storage.JobStatus
+	}{
+		{"collect", storage.JobStatusBudgetBlocked},
+		{"report", storage.JobStatusSkipped},
+		{"recover", storage.JobStatusBudgetBlocked},
+	} {
+		assert.Equal(t, want.id, ts.steps[i].StepID)
+		assert.Equal(t, i, ts.steps[i].Position)
+		assert.Equal(t, ts.history[0].ID, ts.steps[i].JobID)
+		assert.Equal(t, want.status, ts.steps[i].Status, want.id)
+	}
+}
+
+// --- retries ---
+
+// failingBudget admits nothing, failing every run with err before the SDK is
+// reached.
+type failingBudget struct {
+	err error
+}
+
+func (b *failingBudget) ForRun(_, _, _ string) agent.BudgetGuard { return b }
+
+func (b *failingBudget) Admit(_ context.Context) error { return b.err }
+
+func (b *failingBudget) Observe(_, _ string, _ pricing.Usage) error { return nil }
+
+func (b *failingBudget) Settle(_ context.Context, _ float64) {}
+
+// TestRunTask_RetriesThenFails verifies that a retryable failure is retried,
+// every attempt is recorded and linked to the first, and task-failed is only
+// published once the attempts are exhausted.
+func TestRunTask_RetriesThenFails(t *testing.T) {
+	task := buildTask("r1", "Retried Task")
+	task.AgentSlug = ""
+	task.Retry = storage.RetryPolicy{MaxAttempts: 2, BackoffSeconds: 1}
+	ts := newStubTaskStore(task)
+	pub := &stubEventPublisher{}
+
+	s, err := scheduler.New(scheduler.Config{
+		TaskStore:      ts,
+		ChatStore:      &stubChatStore{},
+		Logger:         newTestLogger(),
+		MaxConcurrency: 1,
+		EventPublisher: pub,
+		Budget:         &failingBudget{err: errors.New("API Error: 429 rate limit exceeded")},
+	})
+	require.NoError(t, err)
+
+	s.ExportedExecuteTask(task.ID)
+	assert.Empty(t, pub.waitForEvents(1, 100*time.Millisecond), "no task-failed while a retry is pending")
+
+	events := pub.waitForEvents(1, 3*time.Second)
+	require.Len(t, events, 1)
+	assert.Equal(t, scheduler.EventTaskFailed, events[0].eventType)
+	assert.Equal(t, "2", events[0].payload["Attempts"])
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	require.Len(t, ts.history, 2)
+	assert.Equal(t, storage.JobStatusRetrying, ts.history[0].Status)
+	assert.Equal(t, 1, ts.history[0].Attempt)
+	assert.Empty(t, ts.history[0].RetryOf)
+	assert.Equal(t, storage.JobStatusFailed, ts.history[1].Status)
+	assert.Equal(t, 2, ts.history[1].Attempt)
+	assert.Equal(t, ts.history[0].ID, ts.history[1].RetryOf)
+	assert.Equal(t, 1, ts.tasks[task.ID].RunCount, "the attempts make up one run")
+}
+
+// TestRunTask_NotRetriedForOtherErrorClasses verifies that a failure outside
+// the policy's retry_on list fails the run at once.
+func TestRunTask_NotRetriedForOtherErrorClasses(t *testing.T) {
+	task := buildTask("r2", "Not Retried Task")
+	task.AgentSlug = ""
+	task.Retry = storage.RetryPolicy{MaxAttempts: 3, RetryOn: []storage.ErrorClass{storage.ErrorClassTimeout}}
+	ts := newStubTaskStore(task)
+	pub := &stubEventPublisher{}
+
+	s, err := scheduler.New(scheduler.Config{
+		TaskStore:      ts,
+		ChatStore:      &stubChatStore{},
+		Logger:         newTestLogger(),
+		MaxConcurrency: 1,
+		EventPublisher: pub,
+		Budget:         &failingBudget{err: errors.New("API Error: 429 rate limit exceeded")},
+	})
+	require.NoError(t, err)
+
+	s.ExportedExecuteTask(task.ID)
+
+	events := pub.waitForEvents(1, 500*time.Millisecond)
+	require.Len(t, events, 1)
+	assert.Equal(t, "1", events[0].payload["Attempts"])
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	require.Len(t, ts.history, 1)
+	assert.Equal(t, storage.JobStatusFailed, ts.history[0].Status)
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := storage.RetryPolicy{MaxAttempts: 5, BackoffSeconds: 10, MaxBackoffSeconds: 60}
+
+	tests := []struct {
+		name   string
+		policy storage.RetryPolicy
+		failed int
+		class  storage.ErrorClass
+		want   time.Duration
+		ok     bool
+	}{
+		{name: "no policy", failed: 1, class: storage.ErrorClassCrash},
+		{name: "first retry", policy: policy, failed: 1, class: storage.ErrorClassTimeout, want: 10 * time.Second, ok: true},
+		{name: "doubles", policy: policy, failed: 3, class: storage.ErrorClassRateLimit, want: 40 * time.Second, ok: true},
+		{name: "capped", policy: policy, failed: 4, class: storage.ErrorClassCrash, want: time.Minute, ok: true},
+		{name: "attempts exhausted", policy: policy, failed: 5, class: storage.ErrorClassCrash},
+		{name: "agent errors are not retried by default", policy: policy, failed: 1, class: storage.ErrorClassAgentError},
+		{name: "unclassified failure", policy: policy, failed: 1},
+		{
+			name:   "defaults",
+			policy: storage.RetryPolicy{MaxAttempts: 3, RetryOn: []storage.ErrorClass{storage.ErrorClassAgentError}},
+			failed: 2, class: storage.ErrorClassAgentError, want: 2 * time.Minute, ok: true,
+		},
+		{
+			name:   "custom multiplier",
+			policy: storage.RetryPolicy{MaxAttempts: 3, BackoffSeconds: 10, BackoffMultiplier: 1.5},
+			failed: 2, class: storage.ErrorClassCrash, want: 15 * time.Second, ok: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := scheduler.RetryDelay(tc.policy, tc.failed, tc.class)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestClassifyRunError(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-expired.Done()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want storage.ErrorClass
+	}{
+		{name: "timeout", ctx: expired, err: errors.New("signal: killed"), want: storage.ErrorClassTimeout},
+		{name: "rate limit", ctx: context.Background(), err: errors.New("agent error: API Error: 429"), want: storage.ErrorClassRateLimit},
+		{name: "overloaded", ctx: context.Background(), err: errors.New("agent error: Overloaded"), want: storage.ErrorClassRateLimit},
+		{name: "overloaded status", ctx: context.Background(), err: errors.New("starting agent: request failed with status code 529"), want: storage.ErrorClassRateLimit},
+		// The digits alone are not a status: here they are part of a line number.
+		{name: "digits in an ordinary error", ctx: context.Background(), err: errors.New("agent error: parse error at line 1429"), want: storage.ErrorClassAgentError},
+		{name: "digits in a crash", ctx: context.Background(), err: errors.New("starting agent: read 429 bytes: unexpected EOF"), want: storage.ErrorClassCrash},
+		{name: "agent error", ctx: context.Background(), err: errors.New("agent error: subtype=error_max_turns"), want: storage.ErrorClassAgentError},
+		{name: "crash", ctx: context.Background(), err: errors.New("starting agent: exit status 1"), want: storage.ErrorClassCrash},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, scheduler.ClassifyRunError(tc.ctx, tc.err))
+		})
+	}
+}
@@ -4,3 +4,9 @@ package scheduler
 func (s *Scheduler) ExportedExecuteTask(taskID string) {
 	s.executeTask(taskID)
 }
+
+// RetryDelay exposes retryDelay for external tests.
+var RetryDelay = retryDelay
+
+// ClassifyRunError exposes classifyRunError for external tests.
+var ClassifyRunError = classifyRunError
@@ -0,0 +1,242 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/pipeline"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// stepOutcome is the result of running one pipeline step.
+type stepOutcome struct {
+	status    storage.JobStatus
+	errMsg    string
+	class     storage.ErrorClass // for a failed run; empty when not retryable
+	answer    string
+	sessionID string
+	usage     agent.UsageStats
+}
+
+// runPipeline executes a task's steps in dependency order and records the
+// run as one job history entry with a job step per step. Steps whose
+// condition is not met are recorded as skipped. The run fails if any step
+// fails; its response is the answer of the last step that succeeded. A
+// failed run is retried as a whole, classed by its first failed step.
+func (s *Scheduler) runPipeline(
+	ctx context.Context, task *storage.ScheduledTask, parentSpan trace.Span, attempt runAttempt,
+) {
+	startedAt := time.Now().UTC()
+
+	steps, err := pipeline.Plan(task.Steps)
+	if err != nil {
+		errMsg := fmt.Sprintf("invalid pipeline: %v", err)
+		s.logger.Error("failed to plan pipeline", "task_id", task.ID, "error", err)
+		s.recordFailedRun(ctx, task, startedAt, attempt, "", errMsg)
+		s.publishTaskFailed(task, errMsg, attempt.number)
+		parentSpan.RecordError(err)
+		parentSpan.SetStatus(codes.Error, errMsg)
+		return
+	}
+
+	ids := make([]string, len(steps))
+	for i, st := range steps {
+		ids[i] = st.ID
+	}
+	jh := s.createInitialJobHistory(ctx, task, startedAt, attempt, "", "Pipeline: "+strings.Join(ids, " → "))
+
+	results := make(map[string]pipeline.Result, len(steps))
+	var usage agent.UsageStats
+	var failure, blocked, answer, sessionID string
+	var failureClass storage.ErrorClass
+	for i, step := range steps {
+		rec := &storage.JobStep{
+			JobID:     jh.ID,
+			StepID:    step.ID,
+			Position:  i,
+			AgentSlug: step.AgentSlug,
+			StartedAt: time.Now().UTC(),
+		}
+
+		if ok, reason := pipeline.ShouldRun(step, results); !ok {
+			rec.Status = storage.JobStatusSkipped
+			rec.ErrorMessage = reason
+			rec.FinishedAt = &rec.StartedAt
+			if err := s.cfg.TaskStore.CreateJobStep(ctx, rec); err != nil {
+				s.logger.Error("failed to create job step", "job_id", jh.ID, "step_id", step.ID, "error", err)
+			}
+			results[step.ID] = pipeline.Result{Status: storage.JobStatusSkipped}
+			continue
+		}
+
+		out := s.runStep(ctx, task, step, rec, results)
+		results[step.ID] = pipeline.Result{Status: out.status, Answer: out.answer}
+		usage = addUsage(usage, out.usage)
+
+		switch out.status {
+		case storage.JobStatusSuccess:
+			answer, sessionID = out.answer, out.sessionID
+		case storage.JobStatusBudgetBlocked:
+			if blocked == "" {
+				blocked = fmt.Sprintf("step %q: %s", step.ID, out.errMsg)
+			}
+		default:
+			if failure == "" {
+				failure = fmt.Sprintf("step %q: %s", step.ID, out.errMsg)
+				failureClass = out.class
+			}
+		}
+	}
+
+	jh.ChatSessionID = sessionID
+	if failure != "" {
+		s.failRun(ctx, task, jh, startedAt, attempt, failure, failureClass, usage)
+		parentSpan.SetStatus(codes.Error, failure)
+		return
+	}
+
+	status, errMsg := storage.JobStatusSuccess, ""
+	if blocked != "" {
+		status, errMsg = storage.JobStatusBudgetBlocked, blocked
+	}
+
+	responseText := ""
+	if task.SaveOutput {
+		responseText = answer
+	}
+	s.finishJobHistory(ctx, jh, startedAt, status, errMsg, usage, responseText)
+	s.updateTaskAfterRun(ctx, task, startedAt, string(status))
+
+	if status == storage.JobStatusSuccess {
+		s.publishTaskFinished(task, jh, sessionID)
+		s.logger.Info("pipeline execution completed",
+			"task_id", task.ID, "task_name", task.Name,
+			"steps", len(steps), "run_count", task.RunCount)
+		return
+	}
+	// The enforcer has already published the budget event.
+	parentSpan.SetStatus(codes.Error, errMsg)
+}
+
+// runStep runs a single pipeline step and records it in rec.
+func (s *Scheduler) runStep(
+	parentCtx context.Context, task *storage.ScheduledTask, step storage.PipelineStep,
+	rec *storage.JobStep, results map[string]pipeline.Result,
+) stepOutcome {
+	ctx, span := otel.Tracer("agento").Start(parentCtx, "scheduler.pipeline.step")
+	span.SetAttributes(
+		attribute.String("scheduler.step_id", step.ID),
+		attribute.String("scheduler.agent_slug", step.AgentSlug),
+	)
+	defer span.End()
+
+	rec.Status = storage.JobStatusRunning
+	if err := s.cfg.TaskStore.CreateJobStep(ctx, rec); err != nil {
+		s.logger.Error("failed to create job step", "job_id", rec.JobID, "step_id", step.ID, "error", err)
+	}
+
+	fail := func(status storage.JobStatus, errMsg string) stepOutcome {
+		span.SetStatus(codes.Error, errMsg)
+		s.finishJobStep(ctx, rec, status, errMsg, agent.UsageStats{}, "")
+		return stepOutcome{status: status, errMsg: errMsg, sessionID: rec.ChatSessionID}
+	}
+
+	prompt, err := agent.Interpolate(step.Prompt, pipeline.Variables(step, results))
+	if err != nil {
+		return fail(storage.JobStatusFailed, fmt.Sprintf("prompt interpolation: %v", err))
+	}
+	rec.PromptPreview = prompt
+	if len(rec.PromptPreview) > 200 {
+		rec.PromptPreview = rec.PromptPreview[:200] + "..."
+	}
+
+	// The step runs as a variant of its task, so sessions, agent resolution
+	// and budget scoping work exactly as for a single-prompt run.
+	stepTask := *task
+	stepTask.Name = task.Name + " › " + step.ID
+	stepTask.AgentSlug = step.AgentSlug
+	if step.TimeoutMinutes > 0 {
+		stepTask.TimeoutMinutes = step.TimeoutMinutes
+	}
+
+	chatSession, err := s.createTaskSession(ctx, &stepTask)
+	if err != nil {
+		return fail(storage.JobStatusFailed, fmt.Sprintf("create session: %v", err))
+	}
+	rec.ChatSessionID = chatSession.ID
+
+	agentCfg, err := s.resolveAgentConfig(ctx, &stepTask)
+	if err != nil {
+		return fail(storage.JobStatusFailed, fmt.Sprintf("resolve agent: %v", err))
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, time.Duration(stepTask.TimeoutMinutes)*time.Minute)
+	defer cancel()
+
+	opts := s.buildRunOptions(runCtx, &stepTask, agentCfg, rec.JobID, chatSession.ID)
+	result, err := agent.RunAgent(runCtx, agentCfg, prompt, opts)
+	var budgetErr *agent.BudgetExceededError
+	if errors.As(err, &budgetErr) {
+		s.logger.Warn("pipeline step blocked by budget",
+			"task_id", task.ID, "step_id", step.ID, "policy_id", budgetErr.PolicyID)
+		return fail(storage.JobStatusBudgetBlocked, err.Error())
+	}
+	if err != nil {
+		s.logger.Error("pipeline step failed", "task_id", task.ID, "step_id", step.ID, "error", err)
+		out := fail(storage.JobStatusFailed, err.Error())
+		out.class = classifyRunError(runCtx, err)
+		return out
+	}
+
+	s.saveSessionResults(ctx, chatSession, result, prompt, rec.StartedAt)
+	responseText := ""
+	if task.SaveOutput {
+		responseText = result.Answer
+	}
+	s.finishJobStep(ctx, rec, storage.JobStatusSuccess, "", result.Usage, responseText)
+	return stepOutcome{
+		status:    storage.JobStatusSuccess,
+		answer:    result.Answer,
+		sessionID: chatSession.ID,
+		usage:     result.Usage,
+	}
+}
+
+func (s *Scheduler) finishJobStep(
+	ctx context.Context, rec *storage.JobStep, status storage.JobStatus,
+	errMsg string, usage agent.UsageStats, responseText string,
+) {
+	now := time.Now().UTC()
+	rec.Status = status
+	rec.FinishedAt = &now
+	rec.DurationMS = now.Sub(rec.StartedAt).Milliseconds()
+	rec.ErrorMessage = errMsg
+	rec.ResponseText = responseText
+	rec.TotalInputTokens = usage.InputTokens
+	rec.TotalOutputTokens = usage.OutputTokens
+	rec.TotalCacheCreationTokens = usage.CacheCreationInputTokens
+	rec.TotalCacheReadTokens = usage.CacheReadInputTokens
+
+	if err := s.cfg.TaskStore.UpdateJobStep(ctx, rec); err != nil {
+		s.logger.Error("failed to update job step", "step_id", rec.ID, "error", err)
+	}
+}
+
+func addUsage(a, b agent.UsageStats) agent.UsageStats {
+	return agent.UsageStats{
+		InputTokens:              a.InputTokens + b.InputTokens,
+		OutputTokens:             a.OutputTokens + b.OutputTokens,
+		CacheReadInputTokens:     a.CacheReadInputTokens + b.CacheReadInputTokens,
+		CacheCreationInputTokens: a.CacheCreationInputTokens + b.CacheCreationInputTokens,
+		WebSearchRequests:        a.WebSearchRequests + b.WebSearchRequests,
+	}
+}
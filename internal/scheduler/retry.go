@@ -0,0 +1,172 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// Retry policy defaults, used when a policy leaves the field at zero.
+const (
+	defaultRetryBackoff    = time.Minute
+	defaultRetryMultiplier = 2.0
+	defaultRetryMaxBackoff = time.Hour
+)
+
+// runAttempt identifies one attempt of a task run. Retries carry the job
+// history ID of the first attempt so their records link back to it.
+type runAttempt struct {
+	number  int // 1 for the scheduled run
+	firstID string
+}
+
+// firstAttempt is the attempt a scheduled run starts with.
+var firstAttempt = runAttempt{number: 1}
+
+// apply stamps the attempt onto a job history record.
+func (a runAttempt) apply(jh *storage.JobHistory) {
+	jh.Attempt = a.number
+	if a.number > 1 {
+		jh.RetryOf = a.firstID
+	}
+}
+
+// next returns the attempt that retries a, whose record is jh.
+func (a runAttempt) next(jh *storage.JobHistory) runAttempt {
+	firstID := a.firstID
+	if a.number == 1 {
+		firstID = jh.ID
+	}
+	return runAttempt{number: a.number + 1, firstID: firstID}
+}
+
+// rateLimitMarkers are lower-cased fragments of the errors Claude Code and
+// the API report when a request is throttled or the service is overloaded.
+var rateLimitMarkers = []string{
+	"rate limit", "rate_limit", "ratelimit", "too many requests",
+	"overloaded", "usage limit",
+}
+
+// rateLimitStatus matches a throttled or overloaded HTTP status as errors
+// report it ("API Error: 429", "status code 529", "HTTP/1.1 429"). The code
+// alone is not enough: the digits turn up in line numbers, IDs and counts.
+var rateLimitStatus = regexp.MustCompile(`(?:api error|status(?: code)?|http(?:/[\d.]+)?)\W*(?:429|529)\b`)
+
+// classifyRunError sorts a failed agent run into an error class. runCtx is
+// the context the run was given, whose deadline is the task timeout.
+func classifyRunError(runCtx context.Context, err error) storage.ErrorClass {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return storage.ErrorClassTimeout
+	}
+	msg := strings.ToLower(err.Error())
+	if rateLimitStatus.MatchString(msg) {
+		return storage.ErrorClassRateLimit
+	}
+	for _, marker := range rateLimitMarkers {
+		if strings.Contains(msg, marker) {
+			return storage.ErrorClassRateLimit
+		}
+	}
+	// The runner reports an error result from the agent as "agent error: …";
+	// anything else means the process never produced a result.
+	if strings.Contains(msg, "agent error:") {
+		return storage.ErrorClassAgentError
+	}
+	return storage.ErrorClassCrash
+}
+
+// retryDelay reports whether a run whose attempt number failed with class
+// should be retried under policy, and how long to wait first. An empty class
+// marks a failure no retry can fix, such as a missing agent.
+func retryDelay(policy storage.RetryPolicy, failed int, class storage.ErrorClass) (time.Duration, bool) {
+	if class == "" || failed >= policy.MaxAttempts {
+		return 0, false
+	}
+	retryOn := policy.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = storage.DefaultRetryOn
+	}
+	if !slices.Contains(retryOn, class) {
+		return 0, false
+	}
+
+	backoff := defaultRetryBackoff
+	if policy.BackoffSeconds > 0 {
+		backoff = time.Duration(policy.BackoffSeconds) * time.Second
+	}
+	multiplier := defaultRetryMultiplier
+	if policy.BackoffMultiplier > 0 {
+		multiplier = policy.BackoffMultiplier
+	}
+	maxBackoff := defaultRetryMaxBackoff
+	if policy.MaxBackoffSeconds > 0 {
+		maxBackoff = time.Duration(policy.MaxBackoffSeconds) * time.Second
+	}
+
+	delay := float64(backoff) * math.Pow(multiplier, float64(failed-1))
+	if delay > float64(maxBackoff) {
+		return maxBackoff, true
+	}
+	return time.Duration(delay), true
+}
+
+// failRun finishes a failed attempt. When the task's retry policy allows
+// another attempt, the record is marked retrying and the retry scheduled;
+// otherwise the run is recorded as failed and the task-failed event sent.
+func (s *Scheduler) failRun(
+	ctx context.Context, task *storage.ScheduledTask, jh *storage.JobHistory,
+	startedAt time.Time, attempt runAttempt, errMsg string, class storage.ErrorClass, usage agent.UsageStats,
+) {
+	if delay, ok := retryDelay(task.Retry, attempt.number, class); ok {
+		s.finishJobHistory(ctx, jh, startedAt, storage.JobStatusRetrying, errMsg, usage, "")
+		s.scheduleRetry(task.ID, attempt.next(jh), delay)
+		s.logger.Warn("task run failed, retrying",
+			"task_id", task.ID, "attempt", attempt.number, "error_class", class,
+			"retry_in", delay.String(), "error", errMsg)
+		return
+	}
+
+	s.finishJobHistory(ctx, jh, startedAt, storage.JobStatusFailed, errMsg, usage, "")
+	s.updateTaskAfterRun(ctx, task, startedAt, string(storage.JobStatusFailed))
+	s.publishTaskFailed(task, errMsg, attempt.number)
+}
+
+// scheduleRetry runs attempt of the task after delay. A task has at most one
+// pending retry; a new scheduled run or unscheduling the task cancels it.
+func (s *Scheduler) scheduleRetry(taskID string, attempt runAttempt, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.retries[taskID]; ok {
+		t.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		s.mu.Lock()
+		if s.retries[taskID] != timer {
+			s.mu.Unlock()
+			return
+		}
+		delete(s.retries, taskID)
+		s.mu.Unlock()
+
+		s.execute(taskID, attempt)
+	})
+	s.retries[taskID] = timer
+}
+
+// cancelRetry drops the task's pending retry, if any. The caller must hold s.mu.
+func (s *Scheduler) cancelRetry(taskID string) {
+	if t, ok := s.retries[taskID]; ok {
+		t.Stop()
+		delete(s.retries, taskID)
+		s.logger.Info("pending retry canceled", "task_id", taskID)
+	}
+}
@@ -11,7 +11,11 @@ import (
 
 	"github.com/go-co-op/gocron/v2"
 	"github.com/google/uuid"
+	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
 
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/approval"
+	"github.com/shaharia-lab/agento/internal/audit"
 	"github.com/shaharia-lab/agento/internal/config"
 	"github.com/shaharia-lab/agento/internal/integrations"
 	"github.com/shaharia-lab/agento/internal/storage"
@@ -30,6 +34,22 @@ const (
 	EventTaskFailed   = "tasks_scheduler.task_execution.failed"
 )
 
+// BudgetEnforcer hands out a budget guard for each task run.
+type BudgetEnforcer interface {
+	ForRun(agentSlug, taskID, source string) agent.BudgetGuard
+}
+
+// ApprovalBroker gates the tool calls of task runs on a person's approval.
+type ApprovalBroker interface {
+	PermissionHandler(ctx context.Context, agentCfg *config.AgentConfig, run approval.Run) claude.PermissionHandler
+}
+
+// ToolAuditLog hands out the auditor that logs the tool calls of each task
+// run.
+type ToolAuditLog interface {
+	ForRun(run audit.Run) agent.ToolAuditor
+}
+
 // Config holds the scheduler configuration.
 type Config struct {
 	TaskStore           storage.TaskStore
@@ -43,13 +63,22 @@ type Config struct {
 	MaxConcurrency      int
 	// EventPublisher is optional. When set, task lifecycle events are published.
 	EventPublisher EventPublisher
+	// Budget is optional. When set, every run is checked against the
+	// configured spending caps.
+	Budget BudgetEnforcer
+	// Approvals is optional. When set, the tools an agent gates wait on a
+	// person's approval during its task runs.
+	Approvals ApprovalBroker
+	// Audit is optional. When set, the tool calls of every run are logged.
+	Audit ToolAuditLog
 }
 
 // Scheduler manages scheduled task execution using gocron.
 type Scheduler struct {
 	cron      gocron.Scheduler
 	cfg       Config
-	jobs      map[string]uuid.UUID // taskID → gocron job UUID
+	jobs      map[string]uuid.UUID   // taskID → gocron job UUID
+	retries   map[string]*time.Timer // taskID → pending retry
 	mu        sync.Mutex
 	semaphore chan struct{}
 	logger    *slog.Logger
@@ -71,6 +100,7 @@ func New(cfg Config) (*Scheduler, error) {
 		cron:      cron,
 		cfg:       cfg,
 		jobs:      make(map[string]uuid.UUID),
+		retries:   make(map[string]*time.Timer),
 		semaphore: make(chan struct{}, maxConc),
 		logger:    cfg.Logger,
 	}, nil
@@ -98,8 +128,13 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop shuts down the gocron scheduler.
+// Stop shuts down the gocron scheduler and drops pending retries.
 func (s *Scheduler) Stop() error {
+	s.mu.Lock()
+	for taskID := range s.retries {
+		s.cancelRetry(taskID)
+	}
+	s.mu.Unlock()
 	return s.cron.Shutdown()
 }
 
@@ -135,11 +170,14 @@ func (s *Scheduler) ScheduleTask(task *storage.ScheduledTask) error {
 	return nil
 }
 
-// UnscheduleTask removes a task from the gocron scheduler.
+// UnscheduleTask removes a task from the gocron scheduler and cancels its
+// pending retry.
 func (s *Scheduler) UnscheduleTask(taskID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.cancelRetry(taskID)
+
 	if jobID, ok := s.jobs[taskID]; ok {
 		if err := s.cron.RemoveJob(jobID); err != nil {
 			s.logger.Warn("failed to remove job", "task_id", taskID, "error", err)
@@ -149,6 +187,13 @@ func (s *Scheduler) UnscheduleTask(taskID string) {
 	}
 }
 
+// RunNow starts a run of a task outside its schedule and returns at once. The
+// run waits for a free slot like a scheduled one, supersedes any pending
+// retry, and is recorded in the job history.
+func (s *Scheduler) RunNow(taskID string) {
+	go s.executeTask(taskID)
+}
+
 // buildJobDefinition converts a ScheduledTask's schedule config into a gocron JobDefinition.
 func (s *Scheduler) buildJobDefinition(task *storage.ScheduledTask) (gocron.JobDefinition, error) {
 	cfg := task.ScheduleConfig
@@ -0,0 +1,183 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// sealedPrefix starts every sealed value. Credentials are JSON, which never
+// starts with it, so a value without it is plaintext from before encryption.
+const sealedPrefix = "enc:v1:"
+
+// scrypt parameters for deriving the key-encryption key. They make each
+// guess at a passphrase cost tens of milliseconds.
+const (
+	scryptN   = 1 << 15
+	scryptR   = 8
+	scryptP   = 1
+	keyLength = 32
+)
+
+// ErrKeyMismatch is returned when a value was sealed with a different key.
+var ErrKeyMismatch = errors.New("value was sealed with a different key")
+
+// Cipher seals values with envelope encryption: each value gets its own
+// random data key, which is wrapped with a key-encryption key derived from
+// the master key. Rotating the master key rewraps the data keys and leaves
+// the encrypted values alone.
+//
+// A sealed value reads enc:v1:<key id>:<wrapped data key>:<ciphertext>.
+type Cipher struct {
+	keyID string
+	kek   cipher.AEAD
+}
+
+// NewCipher derives the key-encryption key from material and salt. keyID is
+// recorded in every value the cipher seals.
+func NewCipher(keyID string, material, salt []byte) (*Cipher, error) {
+	if keyID == "" || strings.Contains(keyID, ":") {
+		return nil, fmt.Errorf("invalid key id %q", keyID)
+	}
+	if len(material) == 0 {
+		return nil, fmt.Errorf("key material is empty")
+	}
+	kek, err := scrypt.Key(material, salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("deriving key: %w", err)
+	}
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{keyID: keyID, kek: aead}, nil
+}
+
+// KeyID identifies the master key the cipher seals with.
+func (c *Cipher) KeyID() string {
+	return c.keyID
+}
+
+// IsSealed reports whether stored is a sealed value rather than plaintext.
+func IsSealed(stored string) bool {
+	return strings.HasPrefix(stored, sealedPrefix)
+}
+
+// Seal encrypts plaintext under a fresh data key.
+func (c *Cipher) Seal(plaintext []byte) (string, error) {
+	dek := make([]byte, keyLength)
+	if _, err := rand.Read(dek); err != nil {
+		return "", fmt.Errorf("generating data key: %w", err)
+	}
+	data, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := sealWith(data, plaintext, nil)
+	if err != nil {
+		return "", err
+	}
+	return c.wrap(dek, ciphertext)
+}
+
+// Open decrypts a value Seal returned. Plaintext is returned as it is.
+func (c *Cipher) Open(stored string) ([]byte, error) {
+	if !IsSealed(stored) {
+		return []byte(stored), nil
+	}
+	dek, ciphertext, err := c.unwrap(stored)
+	if err != nil {
+		return nil, err
+	}
+	data, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+	return openWith(data, ciphertext, nil)
+}
+
+// Rewrap moves a value sealed by c to next by rewrapping its data key. The
+// ciphertext is kept. Plaintext is sealed by next.
+func (c *Cipher) Rewrap(stored string, next *Cipher) (string, error) {
+	if !IsSealed(stored) {
+		return next.Seal([]byte(stored))
+	}
+	dek, ciphertext, err := c.unwrap(stored)
+	if err != nil {
+		return "", err
+	}
+	return next.wrap(dek, ciphertext)
+}
+
+// wrap seals dek with the key-encryption key and formats the stored value.
+func (c *Cipher) wrap(dek, ciphertext []byte) (string, error) {
+	wrapped, err := sealWith(c.kek, dek, []byte(c.keyID))
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawStdEncoding
+	return sealedPrefix + c.keyID + ":" + enc.EncodeToString(wrapped) + ":" + enc.EncodeToString(ciphertext), nil
+}
+
+// unwrap parses a stored value and returns its data key and ciphertext.
+func (c *Cipher) unwrap(stored string) (dek, ciphertext []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(stored, sealedPrefix), ":")
+	if len(parts) != 3 {
+		return nil, nil, fmt.Errorf("malformed sealed value")
+	}
+	if parts[0] != c.keyID {
+		return nil, nil, fmt.Errorf("%w: sealed with %q, have %q", ErrKeyMismatch, parts[0], c.keyID)
+	}
+	enc := base64.RawStdEncoding
+	wrapped, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("decoding data key: %w", err)
+	}
+	if ciphertext, err = enc.DecodeString(parts[2]); err != nil {
+		return nil, nil, fmt.Errorf("decoding ciphertext: %w", err)
+	}
+	if dek, err = openWith(c.kek, wrapped, []byte(c.keyID)); err != nil {
+		return nil, nil, fmt.Errorf("unwrapping data key: %w", err)
+	}
+	return dek, ciphertext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	return aead, nil
+}
+
+// sealWith encrypts plaintext under a random nonce, which it prepends.
+func sealWith(aead cipher.AEAD, plaintext, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+// openWith reverses sealWith.
+func openWith(aead cipher.AEAD, sealed, additional []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("sealed value is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additional)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCipher(t *testing.T, keyID, material string) *Cipher {
+	t.Helper()
+	c, err := NewCipher(keyID, []byte(material), []byte("0123456789abcdef"))
+	require.NoError(t, err)
+	return c
+}
+
+func TestCipher_SealOpen(t *testing.T) {
+	c := newTestCipher(t, "k1", "correct horse battery")
+	plaintext := []byte(`{"token":"ghp_secret"}`)
+
+	sealed, err := c.Seal(plaintext)
+	require.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, sealed, "ghp_secret")
+	assert.True(t, strings.HasPrefix(sealed, "enc:v1:k1:"))
+
+	again, err := c.Seal(plaintext)
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "every value gets its own data key and nonce")
+
+	got, err := c.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+
+	got, err = c.Open(`{"legacy":true}`)
+	require.NoError(t, err)
+	assert.Equal(t, `{"legacy":true}`, string(got), "plaintext from before encryption passes through")
+}
+
+func TestCipher_WrongKey(t *testing.T) {
+	c := newTestCipher(t, "k1", "correct horse battery")
+	sealed, err := c.Seal([]byte("secret"))
+	require.NoError(t, err)
+
+	_, err = newTestCipher(t, "k2", "correct horse battery").Open(sealed)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	_, err = newTestCipher(t, "k1", "wrong horse battery").Open(sealed)
+	assert.Error(t, err)
+
+	tampered := sealed[:len(sealed)-2] + "AA"
+	_, err = c.Open(tampered)
+	assert.Error(t, err)
+}
+
+func TestCipher_Rewrap(t *testing.T) {
+	oldKey := newTestCipher(t, "k1", "correct horse battery")
+	newKey := newTestCipher(t, "k2", "battery staple horse")
+
+	sealed, err := oldKey.Seal([]byte("secret"))
+	require.NoError(t, err)
+	moved, err := oldKey.Rewrap(sealed, newKey)
+	require.NoError(t, err)
+
+	assert.Equal(t, sealed[strings.LastIndex(sealed, ":"):], moved[strings.LastIndex(moved, ":"):],
+		"the ciphertext is kept; only the data key is rewrapped")
+	got, err := newKey.Open(moved)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(got))
+	_, err = oldKey.Open(moved)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	moved, err = oldKey.Rewrap("plain", newKey)
+	require.NoError(t, err)
+	got, err = newKey.Open(moved)
+	require.NoError(t, err)
+	assert.Equal(t, "plain", string(got))
+}
@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keyringService is the service name keys are filed under in the OS keyring.
+// The account is the key id.
+const keyringService = "agento"
+
+// keyring stores master keys in the operating system's secret store.
+type keyring interface {
+	// Available reports whether this host has a keyring agento can use.
+	Available() bool
+	Get(ctx context.Context, account string) (string, error)
+	Set(ctx context.Context, account, secret string) error
+	Delete(ctx context.Context, account string) error
+}
+
+// osKeyring uses the macOS Keychain through security(1), and the Secret
+// Service (GNOME Keyring, KWallet) on Linux through secret-tool(1). The
+// secret never appears on a command line.
+type osKeyring struct{}
+
+func (osKeyring) tool() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "security"
+	case "linux":
+		return "secret-tool"
+	}
+	return ""
+}
+
+func (k osKeyring) Available() bool {
+	tool := k.tool()
+	if tool == "" {
+		return false
+	}
+	_, err := exec.LookPath(tool)
+	return err == nil
+}
+
+func (k osKeyring) Get(ctx context.Context, account string) (string, error) {
+	var secret string
+	var err error
+	if k.tool() == "security" {
+		secret, err = runKeyring(ctx, "", "security", "find-generic-password", "-s", keyringService, "-a", account, "-w")
+	} else {
+		secret, err = runKeyring(ctx, "", "secret-tool", "lookup", "service", keyringService, "account", account)
+	}
+	if err == nil && secret == "" {
+		err = fmt.Errorf("no key %q in the keyring", account)
+	}
+	return secret, err
+}
+
+func (k osKeyring) Set(ctx context.Context, account, secret string) error {
+	var err error
+	if k.tool() == "security" {
+		// Interactive mode reads the command from stdin, keeping the secret
+		// out of the process list. Both values are base64 or hex.
+		cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", keyringService, account, secret)
+		_, err = runKeyring(ctx, cmd, "security", "-i")
+	} else {
+		_, err = runKeyring(ctx, secret, "secret-tool", "store", "--label", "Agento credential encryption key",
+			"service", keyringService, "account", account)
+	}
+	return err
+}
+
+func (k osKeyring) Delete(ctx context.Context, account string) error {
+	var err error
+	if k.tool() == "security" {
+		_, err = runKeyring(ctx, "", "security", "delete-generic-password", "-s", keyringService, "-a", account)
+	} else {
+		_, err = runKeyring(ctx, "", "secret-tool", "clear", "service", keyringService, "account", account)
+	}
+	return err
+}
+
+// runKeyring runs a keyring tool with stdin and returns its trimmed stdout.
+// The nolint is for gosec G204: name is always security or secret-tool.
+func runKeyring(ctx context.Context, stdin, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...) //nolint:gosec
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		detail := strings.TrimSpace(stderr.String())
+		if detail == "" {
+			detail = err.Error()
+		}
+		return "", fmt.Errorf("%s %s: %s", name, args[0], detail)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
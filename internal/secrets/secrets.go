@@ -0,0 +1,322 @@
+// Package secrets encrypts integration credentials and OAuth tokens at rest.
+//
+// The master key is kept outside the database: in the OS keyring, in a file
+// or passphrase the user supplies, or, on hosts without a keyring, in a key
+// file under the data directory. The database records only which key sealed
+// the credentials and where that key is kept.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// Where a master key is kept. They are recorded in storage.SecretKey.Source.
+const (
+	// SourceKeyring is a random key in the OS keyring.
+	SourceKeyring = "keyring"
+	// SourceKeyFile is a random key in a file under the data directory.
+	SourceKeyFile = "keyfile"
+	// SourceFile is the content of the file AGENTO_SECRET_KEY_FILE names.
+	SourceFile = "file"
+	// SourcePassphrase is the AGENTO_SECRET_PASSPHRASE passphrase.
+	SourcePassphrase = "passphrase"
+)
+
+// minMaterialLength is the shortest passphrase or key file accepted.
+const minMaterialLength = 12
+
+// checkPlaintext is sealed into SecretKey.Check to tell a wrong key from a
+// right one before any credential is touched.
+const checkPlaintext = "agento-secret-key-check"
+
+var (
+	// ErrKeyUnavailable is returned when the master key cannot be found.
+	ErrKeyUnavailable = errors.New("credential encryption key unavailable")
+	// ErrWrongKey is returned when the master key found is not the one the
+	// credentials were sealed with.
+	ErrWrongKey = errors.New("credential encryption key does not match")
+)
+
+// Options says where to find a master key the user supplies. Both fields are
+// optional; without them a random key is kept in the OS keyring.
+type Options struct {
+	// KeyFile is a file holding a passphrase or key (AGENTO_SECRET_KEY_FILE).
+	KeyFile string
+	// Passphrase is a passphrase (AGENTO_SECRET_PASSPHRASE).
+	Passphrase string
+	// KeysDir holds random keys when there is no OS keyring.
+	KeysDir string
+}
+
+// Manager loads and rotates the master key.
+type Manager struct {
+	store   storage.SecretKeyStore
+	opts    Options
+	keyring keyring
+	logger  *slog.Logger
+}
+
+// NewManager returns a Manager that records keys in store.
+func NewManager(store storage.SecretKeyStore, opts Options, logger *slog.Logger) *Manager {
+	return &Manager{store: store, opts: opts, keyring: osKeyring{}, logger: logger}
+}
+
+// Load returns the cipher for the current master key, creating the key the
+// first time. Any credential still stored as plaintext is sealed, which is
+// how rows written before encryption existed are migrated.
+func (m *Manager) Load(ctx context.Context) (*Cipher, error) {
+	rec, err := m.store.GetSecretKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		return m.create(ctx, m.opts, sealPlaintext)
+	}
+
+	c, err := m.open(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	n, err := m.store.RewriteCredentials(ctx, nil, func(stored string) (string, error) {
+		return sealPlaintext(c, stored)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encrypting credentials: %w", err)
+	}
+	if n > 0 {
+		m.logger.Info("encrypted plaintext integration credentials", "count", n)
+	}
+	return c, nil
+}
+
+// Rotate replaces the master key. Every credential's data key is rewrapped
+// under the new key in one transaction, and the old key is then removed from
+// the keyring or key file. next says where the new key comes from, as
+// Options does for the first key; when it names neither a file nor a
+// passphrase a random key is generated.
+func (m *Manager) Rotate(ctx context.Context, next Options) (*Cipher, error) {
+	current, err := m.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := m.store.GetSecretKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := m.create(ctx, next, func(c *Cipher, stored string) (string, error) {
+		return current.Rewrap(stored, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.forget(ctx, rec)
+	return c, nil
+}
+
+// create makes a new master key, stores it where opts says, and records it
+// while passing every credential through rewrite.
+func (m *Manager) create(
+	ctx context.Context, opts Options, rewrite func(c *Cipher, stored string) (string, error),
+) (*Cipher, error) {
+	keyID, err := newKeyID()
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+	source, material, err := m.newMaterial(ctx, keyID, opts)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewCipher(keyID, material, salt)
+	if err != nil {
+		return nil, err
+	}
+	check, err := c.Seal([]byte(checkPlaintext))
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &storage.SecretKey{
+		KeyID:     keyID,
+		Source:    source,
+		Salt:      base64.StdEncoding.EncodeToString(salt),
+		Check:     check,
+		CreatedAt: time.Now().UTC(),
+	}
+	n, err := m.store.RewriteCredentials(ctx, rec, func(stored string) (string, error) {
+		return rewrite(c, stored)
+	})
+	if err != nil {
+		m.forget(ctx, rec)
+		return nil, fmt.Errorf("encrypting credentials: %w", err)
+	}
+	m.logger.Info("credential encryption key created", "key_id", keyID, "source", source, "credentials", n)
+	return c, nil
+}
+
+// newMaterial returns the material for a new key, storing generated keys in
+// the keyring, or in a key file when there is no keyring.
+func (m *Manager) newMaterial(ctx context.Context, keyID string, opts Options) (string, []byte, error) {
+	switch {
+	case opts.KeyFile != "":
+		material, err := readKeyFile(opts.KeyFile)
+		return SourceFile, material, err
+	case opts.Passphrase != "":
+		if len(opts.Passphrase) < minMaterialLength {
+			return "", nil, fmt.Errorf("passphrase must be at least %d characters", minMaterialLength)
+		}
+		return SourcePassphrase, []byte(opts.Passphrase), nil
+	}
+
+	raw := make([]byte, keyLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("generating key: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(raw)
+	if m.keyring.Available() {
+		err := m.keyring.Set(ctx, keyID, encoded)
+		if err == nil {
+			return SourceKeyring, []byte(encoded), nil
+		}
+		m.logger.Warn("could not store the credential encryption key in the OS keyring", "error", err)
+	}
+
+	path := m.keyFilePath(keyID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", nil, fmt.Errorf("creating keys directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(encoded), 0o600); err != nil {
+		return "", nil, fmt.Errorf("writing key file: %w", err)
+	}
+	m.logger.Warn("no OS keyring available; the credential encryption key is in a file beside the database. "+
+		"Set AGENTO_SECRET_KEY_FILE or AGENTO_SECRET_PASSPHRASE and run `agento secrets rotate` to keep it elsewhere",
+		"path", path)
+	return SourceKeyFile, []byte(encoded), nil
+}
+
+// open finds the key rec names and checks it is the right one.
+func (m *Manager) open(ctx context.Context, rec *storage.SecretKey) (*Cipher, error) {
+	if rec.Source != SourceFile && rec.Source != SourcePassphrase &&
+		(m.opts.KeyFile != "" || m.opts.Passphrase != "") {
+		m.logger.Warn("AGENTO_SECRET_KEY_FILE or AGENTO_SECRET_PASSPHRASE is set but credentials are sealed "+
+			"with a generated key; run `agento secrets rotate` to switch to it", "source", rec.Source)
+	}
+	material, err := m.material(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := base64.StdEncoding.DecodeString(rec.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("decoding key salt: %w", err)
+	}
+	c, err := NewCipher(rec.KeyID, material, salt)
+	if err != nil {
+		return nil, err
+	}
+	if got, err := c.Open(rec.Check); err != nil || string(got) != checkPlaintext {
+		return nil, fmt.Errorf("%w: the %s key is not the one credentials were encrypted with", ErrWrongKey, rec.Source)
+	}
+	return c, nil
+}
+
+// material fetches the material of the key rec names.
+func (m *Manager) material(ctx context.Context, rec *storage.SecretKey) ([]byte, error) {
+	switch rec.Source {
+	case SourceKeyring:
+		secret, err := m.keyring.Get(ctx, rec.KeyID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: reading key %q from the OS keyring: %v", ErrKeyUnavailable, rec.KeyID, err)
+		}
+		return []byte(secret), nil
+	case SourceKeyFile:
+		material, err := os.ReadFile(m.keyFilePath(rec.KeyID))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrKeyUnavailable, err)
+		}
+		return material, nil
+	case SourceFile:
+		if m.opts.KeyFile == "" {
+			return nil, fmt.Errorf("%w: credentials were encrypted with a key file; set AGENTO_SECRET_KEY_FILE",
+				ErrKeyUnavailable)
+		}
+		material, err := readKeyFile(m.opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrKeyUnavailable, err)
+		}
+		return material, nil
+	case SourcePassphrase:
+		if m.opts.Passphrase == "" {
+			return nil, fmt.Errorf("%w: credentials were encrypted with a passphrase; set AGENTO_SECRET_PASSPHRASE",
+				ErrKeyUnavailable)
+		}
+		return []byte(m.opts.Passphrase), nil
+	}
+	return nil, fmt.Errorf("unknown credential key source %q", rec.Source)
+}
+
+// forget removes a generated key that is no longer used. Failures are
+// logged; a stale key left behind decrypts nothing new.
+func (m *Manager) forget(ctx context.Context, rec *storage.SecretKey) {
+	var err error
+	switch rec.Source {
+	case SourceKeyring:
+		err = m.keyring.Delete(ctx, rec.KeyID)
+	case SourceKeyFile:
+		err = os.Remove(m.keyFilePath(rec.KeyID))
+	default:
+		return
+	}
+	if err != nil {
+		m.logger.Warn("could not remove the old credential encryption key", "key_id", rec.KeyID, "error", err)
+	}
+}
+
+func (m *Manager) keyFilePath(keyID string) string {
+	return filepath.Join(m.opts.KeysDir, keyID+".key")
+}
+
+// sealPlaintext seals stored unless it is sealed already.
+func sealPlaintext(c *Cipher, stored string) (string, error) {
+	if IsSealed(stored) {
+		return stored, nil
+	}
+	return c.Seal([]byte(stored))
+}
+
+// readKeyFile reads a user-supplied key file. Surrounding whitespace, such
+// as a trailing newline, is not part of the key.
+func readKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // the path is the user's own configuration
+	if err != nil {
+		return nil, fmt.Errorf("reading key file: %w", err)
+	}
+	material := bytes.TrimSpace(data)
+	if len(material) < minMaterialLength {
+		return nil, fmt.Errorf("key file %s must hold at least %d characters", path, minMaterialLength)
+	}
+	return material, nil
+}
+
+func newKeyID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating key id: %w", err)
+	}
+	return "k" + hex.EncodeToString(b), nil
+}
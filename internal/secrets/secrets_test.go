@@ -0,0 +1,184 @@
+package secrets
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// fakeKeyring keeps keys in memory.
+type fakeKeyring struct {
+	available bool
+	keys      map[string]string
+}
+
+func (k *fakeKeyring) Available() bool { return k.available }
+
+func (k *fakeKeyring) Get(_ context.Context, account string) (string, error) {
+	secret, ok := k.keys[account]
+	if !ok {
+		return "", fmt.Errorf("no key %q in the keyring", account)
+	}
+	return secret, nil
+}
+
+func (k *fakeKeyring) Set(_ context.Context, account, secret string) error {
+	k.keys[account] = secret
+	return nil
+}
+
+func (k *fakeKeyring) Delete(_ context.Context, account string) error {
+	delete(k.keys, account)
+	return nil
+}
+
+type fixture struct {
+	db      *sql.DB
+	keyring *fakeKeyring
+	keysDir string
+}
+
+func newFixture(t *testing.T, keyringAvailable bool) *fixture {
+	t.Helper()
+	db, _, err := storage.NewSQLiteDB(":memory:", slog.Default())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return &fixture{
+		db:      db,
+		keyring: &fakeKeyring{available: keyringAvailable, keys: map[string]string{}},
+		keysDir: t.TempDir(),
+	}
+}
+
+func (f *fixture) manager(opts Options) *Manager {
+	opts.KeysDir = f.keysDir
+	m := NewManager(storage.NewSQLiteSecretKeyStore(f.db), opts, slog.Default())
+	m.keyring = f.keyring
+	return m
+}
+
+func (f *fixture) saveIntegration(t *testing.T, c storage.CredentialCipher) {
+	t.Helper()
+	now := time.Now().UTC()
+	require.NoError(t, storage.NewSQLiteIntegrationStore(f.db, c).Save(context.Background(), &config.IntegrationConfig{
+		ID: "github-1", Name: "GitHub", Type: "github", Enabled: true,
+		Credentials: json.RawMessage(`{"token":"ghp_secret"}`),
+		Auth:        json.RawMessage(`{"access_token":"oauth_secret"}`),
+		Services:    map[string]config.ServiceConfig{},
+		CreatedAt:   now, UpdatedAt: now,
+	}))
+}
+
+func (f *fixture) rawCredentials(t *testing.T) (credentials, auth string) {
+	t.Helper()
+	require.NoError(t, f.db.QueryRow(`SELECT credentials, auth FROM integrations WHERE id = 'github-1'`).
+		Scan(&credentials, &auth))
+	return credentials, auth
+}
+
+func TestManager_LoadEncryptsExistingCredentials(t *testing.T) {
+	f := newFixture(t, true)
+	ctx := context.Background()
+	f.saveIntegration(t, nil)
+
+	c, err := f.manager(Options{}).Load(ctx)
+	require.NoError(t, err)
+	assert.Len(t, f.keyring.keys, 1, "a generated key is kept in the keyring")
+
+	credentials, auth := f.rawCredentials(t)
+	assert.True(t, IsSealed(credentials))
+	assert.True(t, IsSealed(auth))
+	assert.NotContains(t, credentials+auth, "secret")
+
+	got, err := storage.NewSQLiteIntegrationStore(f.db, c).Get(ctx, "github-1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"token":"ghp_secret"}`, string(got.Credentials))
+	assert.JSONEq(t, `{"access_token":"oauth_secret"}`, string(got.Auth))
+
+	// The next start finds the same key.
+	again, err := f.manager(Options{}).Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, c.KeyID(), again.KeyID())
+}
+
+func TestManager_FallsBackToKeyFile(t *testing.T) {
+	f := newFixture(t, false)
+
+	c, err := f.manager(Options{}).Load(context.Background())
+	require.NoError(t, err)
+	info, err := os.Stat(filepath.Join(f.keysDir, c.KeyID()+".key"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestManager_KeyUnavailable(t *testing.T) {
+	f := newFixture(t, true)
+	ctx := context.Background()
+	_, err := f.manager(Options{}).Load(ctx)
+	require.NoError(t, err)
+
+	f.keyring.keys = map[string]string{}
+	_, err = f.manager(Options{}).Load(ctx)
+	assert.ErrorIs(t, err, ErrKeyUnavailable)
+}
+
+func TestManager_Passphrase(t *testing.T) {
+	f := newFixture(t, true)
+	ctx := context.Background()
+	f.saveIntegration(t, nil)
+
+	_, err := f.manager(Options{Passphrase: "short"}).Load(ctx)
+	assert.Error(t, err, "a short passphrase is refused")
+
+	_, err = f.manager(Options{Passphrase: "correct horse battery"}).Load(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, f.keyring.keys)
+
+	_, err = f.manager(Options{}).Load(ctx)
+	assert.ErrorIs(t, err, ErrKeyUnavailable)
+	_, err = f.manager(Options{Passphrase: "wrong horse battery"}).Load(ctx)
+	assert.ErrorIs(t, err, ErrWrongKey)
+}
+
+func TestManager_Rotate(t *testing.T) {
+	f := newFixture(t, true)
+	ctx := context.Background()
+	f.saveIntegration(t, nil)
+
+	old, err := f.manager(Options{}).Load(ctx)
+	require.NoError(t, err)
+	oldCredentials, _ := f.rawCredentials(t)
+
+	next, err := f.manager(Options{}).Rotate(ctx, Options{Passphrase: "correct horse battery"})
+	require.NoError(t, err)
+	assert.NotEqual(t, old.KeyID(), next.KeyID())
+	assert.Empty(t, f.keyring.keys, "the old key is removed from the keyring")
+
+	credentials, _ := f.rawCredentials(t)
+	got, err := next.Open(credentials)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"token":"ghp_secret"}`, string(got))
+	_, err = old.Open(credentials)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+	assert.NotEqual(t, oldCredentials, credentials)
+
+	// A process still holding the old key must not write with it.
+	assert.Error(t, storage.NewSQLiteIntegrationStore(f.db, old).Save(ctx, &config.IntegrationConfig{
+		ID: "github-1", Credentials: json.RawMessage(`{}`),
+	}))
+
+	_, err = f.manager(Options{Passphrase: "correct horse battery"}).Load(ctx)
+	require.NoError(t, err)
+}
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/auth"
+)
+
+// Authenticator checks the credentials a request carries. *auth.Manager
+// satisfies it.
+type Authenticator interface {
+	Enabled(ctx context.Context) (bool, error)
+	Authenticate(r *http.Request) (*auth.Principal, error)
+	Login(ctx context.Context, password string) (string, time.Time, error)
+}
+
+const loginPath = "/login"
+
+// requireAuth refuses a request that does not carry a valid session or API
+// token, or whose token's scope does not cover it, while authentication is on.
+//
+// It is applied to the whole router rather than to /api alone: the SPA, the
+// OpenAI-compatible API, /mcp and /metrics all expose the same machine.
+// Three things are exempt. /health carries nothing. /login is how a browser
+// gets a session in the first place. And the webhooks under /webhooks/ are
+// called by other servers that cannot sign in; each verifies its own secret
+// before acting, which is the same reason the guards leave them alone.
+//
+// If the auth state cannot be read the request is refused rather than let
+// through: a database error must not be a way in.
+func (s *Server) requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.auth == nil || authExempt(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		enabled, err := s.auth.Enabled(r.Context())
+		if err != nil {
+			s.logger.Error("checking authentication failed", "error", err)
+			writeGuardError(w, http.StatusServiceUnavailable, "authentication is unavailable")
+			return
+		}
+		if !enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		p, err := s.auth.Authenticate(r)
+		if err != nil {
+			s.logger.Error("authenticating request failed", "error", err)
+			writeGuardError(w, http.StatusServiceUnavailable, "authentication is unavailable")
+			return
+		}
+		if p == nil {
+			s.refuseUnauthenticated(w, r)
+			return
+		}
+		if !p.Allows(r.Method, r.URL.Path) {
+			writeGuardError(w, http.StatusForbidden, "this API token's scope does not allow this request")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), p)))
+	})
+}
+
+// refuseUnauthenticated sends a browser opening a page to the sign-in form,
+// and answers anything else with 401.
+func (s *Server) refuseUnauthenticated(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && !isAPIPath(r.URL.Path) {
+		http.Redirect(w, r, loginPath+"?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+		return
+	}
+	w.Header().Set("WWW-Authenticate", `Bearer realm="agento"`)
+	writeGuardError(w, http.StatusUnauthorized, "authentication required")
+}
+
+// authExempt reports whether path is served without authentication.
+func authExempt(path string) bool {
+	return path == "/health" || path == loginPath || strings.HasPrefix(path, "/webhooks/")
+}
+
+// isAPIPath reports whether path is one a program rather than a browser
+// page requests.
+func isAPIPath(path string) bool {
+	for _, prefix := range []string{"/api", "/v1", "/mcp", "/metrics"} {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Failed sign-ins are limited per client address, so the password cannot be
+// guessed at the speed of the network. Behind a reverse proxy every client
+// shares the proxy's address, which makes the limit stricter, not looser.
+const (
+	maxLoginFailures   = 5
+	loginFailureWindow = 15 * time.Minute
+)
+
+// loginLimiter counts recent failed sign-ins by client address.
+type loginLimiter struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+}
+
+func newLoginLimiter() *loginLimiter {
+	return &loginLimiter{failures: make(map[string][]time.Time)}
+}
+
+// blocked reports whether addr has failed too often recently.
+func (l *loginLimiter) blocked(addr string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.recent(addr, now)) >= maxLoginFailures
+}
+
+// fail records a failed sign-in from addr.
+func (l *loginLimiter) fail(addr string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures[addr] = append(l.recent(addr, now), now)
+}
+
+// reset forgets addr's failures after it signs in.
+func (l *loginLimiter) reset(addr string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, addr)
+}
+
+// recent drops addr's failures older than the window and returns the rest.
+// The caller holds l.mu.
+func (l *loginLimiter) recent(addr string, now time.Time) []time.Time {
+	kept := l.failures[addr][:0]
+	for _, t := range l.failures[addr] {
+		if now.Sub(t) < loginFailureWindow {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		delete(l.failures, addr)
+		return nil
+	}
+	l.failures[addr] = kept
+	return kept
+}
+
+// clientAddr is the address failed sign-ins are counted against.
+func clientAddr(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// loginPage is the sign-in form. It is rendered by the server rather than the
+// SPA so that signing in needs nothing that itself requires a session.
+var loginPage = template.Must( //nolint:gochecknoglobals
+	template.New("login").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Sign in · Agento</title>
+<style>
+body{font-family:system-ui,sans-serif;background:#fafafa;color:#18181b;display:flex;
+align-items:center;justify-content:center;min-height:100vh;margin:0}
+form{background:#fff;border:1px solid #e4e4e7;border-radius:8px;padding:24px;width:300px}
+h1{font-size:18px;margin:0 0 16px}
+label{display:block;font-size:13px;margin-bottom:6px}
+input{box-sizing:border-box;width:100%;padding:8px;border:1px solid #d4d4d8;border-radius:6px;font-size:14px}
+button{margin-top:16px;width:100%;padding:8px;border:0;border-radius:6px;background:#18181b;color:#fff;
+font-size:14px;cursor:pointer}
+p{color:#b91c1c;font-size:13px;margin:12px 0 0}
+</style>
+</head>
+<body>
+<form method="post" action="/login">
+<h1>Sign in to Agento</h1>
+<label for="password">Admin password</label>
+<input id="password" name="password" type="password" autocomplete="current-password" autofocus required>
+<input type="hidden" name="next" value="{{.Next}}">
+<button type="submit">Sign in</button>
+{{if .Error}}<p>{{.Error}}</p>{{end}}
+</form>
+</body>
+</html>
+`))
+
+type loginPageData struct {
+	Next  string
+	Error string
+}
+
+// handleLoginPage serves the sign-in form. With authentication off there is
+// nothing to sign in to, so it goes straight to the app.
+func (s *Server) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	next := safeNext(r.URL.Query().Get("next"))
+	if enabled, err := s.auth.Enabled(r.Context()); err == nil && !enabled {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+	s.renderLogin(w, http.StatusOK, loginPageData{Next: next})
+}
+
+// handleLogin checks the submitted password and, when it is right, sets the
+// session cookie and returns to the page the browser was sent here from.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	addr := clientAddr(r)
+	now := time.Now()
+	next := safeNext(r.PostFormValue("next"))
+	if s.loginLimiter.blocked(addr, now) {
+		s.renderLogin(w, http.StatusTooManyRequests,
+			loginPageData{Next: next, Error: "Too many failed attempts. Try again later."})
+		return
+	}
+
+	sessionID, expires, err := s.auth.Login(r.Context(), r.PostFormValue("password"))
+	switch {
+	case errors.Is(err, auth.ErrDisabled):
+		http.Redirect(w, r, next, http.StatusSeeOther)
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		s.loginLimiter.fail(addr, now)
+		s.logger.Warn("failed sign-in", "addr", addr)
+		s.renderLogin(w, http.StatusUnauthorized, loginPageData{Next: next, Error: "Wrong password."})
+	case err != nil:
+		s.logger.Error("sign-in failed", "error", err)
+		s.renderLogin(w, http.StatusInternalServerError,
+			loginPageData{Next: next, Error: "Signing in failed. See the server log."})
+	default:
+		s.loginLimiter.reset(addr)
+		http.SetCookie(w, auth.SessionCookieFor(r, sessionID, expires))
+		http.Redirect(w, r, next, http.StatusSeeOther)
+	}
+}
+
+func (s *Server) renderLogin(w http.ResponseWriter, status int, data loginPageData) {
+	w.Header().Set(headerContentType, "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := loginPage.Execute(w, data); err != nil {
+		s.logger.Error("rendering sign-in page failed", "error", err)
+	}
+}
+
+// safeNext returns next if it is a path on this server, else "/". Following
+// any other value would make the sign-in form an open redirect.
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	if strings.HasPrefix(next, loginPath) {
+		return "/"
+	}
+	return next
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/auth"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// stubAuth signs in the bearer token "admin" or "read", and the password
+// "hunter22".
+type stubAuth struct {
+	enabled bool
+	err     error
+}
+
+func (a stubAuth) Enabled(context.Context) (bool, error) { return a.enabled, a.err }
+
+func (a stubAuth) Authenticate(r *http.Request) (*auth.Principal, error) {
+	switch r.Header.Get("Authorization") {
+	case "Bearer admin":
+		return &auth.Principal{Kind: auth.KindToken, Scope: storage.APITokenScopeAdmin}, nil
+	case "Bearer read":
+		return &auth.Principal{Kind: auth.KindToken, Scope: storage.APITokenScopeRead}, nil
+	}
+	return nil, nil
+}
+
+func (a stubAuth) Login(_ context.Context, password string) (string, time.Time, error) {
+	if !a.enabled {
+		return "", time.Time{}, auth.ErrDisabled
+	}
+	if password != "hunter22" {
+		return "", time.Time{}, auth.ErrInvalidCredentials
+	}
+	return "sess", time.Now().Add(time.Hour), nil
+}
+
+func serveAuth(h http.Handler, method, path, bearer string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Host = "localhost:8990"
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRouter_AuthOffLetsEverythingThrough(t *testing.T) {
+	var hookReached bool
+	h := newTestRouter(t, Options{Auth: stubAuth{enabled: false}}, &hookReached)
+
+	// The content-type guard answers, so the auth middleware let it by.
+	rec := serveAuth(h, http.MethodPost, "/api/agents", "")
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("POST /api/agents with auth off = %d, want 415 from the guard", rec.Code)
+	}
+}
+
+func TestRouter_AuthRequiresCredentials(t *testing.T) {
+	var hookReached bool
+	h := newTestRouter(t, Options{Auth: stubAuth{enabled: true}}, &hookReached)
+
+	rec := serveAuth(h, http.MethodPost, "/api/agents", "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST /api/agents without credentials = %d, want 401", rec.Code)
+	}
+	if rec.Header().Get("WWW-Authenticate") == "" {
+		t.Error("a 401 must name the scheme to use")
+	}
+
+	rec = serveAuth(h, http.MethodPost, "/v1/chat/completions", "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST /v1/chat/completions without credentials = %d, want 401", rec.Code)
+	}
+
+	rec = serveAuth(h, http.MethodGet, "/chats/abc", "")
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("GET /chats/abc without credentials = %d, want a redirect to sign in", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/login?next="+url.QueryEscape("/chats/abc") {
+		t.Errorf("Location = %q", loc)
+	}
+
+	rec = serveAuth(h, http.MethodGet, "/health", "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /health = %d, want 200 — it must stay open for probes", rec.Code)
+	}
+}
+
+// The webhooks are called by other servers, which cannot sign in. Each checks
+// its own secret.
+func TestRouter_AuthLeavesWebhooksAlone(t *testing.T) {
+	var hookReached bool
+	h := newTestRouter(t, Options{Auth: stubAuth{enabled: true}}, &hookReached)
+
+	serveAuth(h, http.MethodPost, "/webhooks/telegram/abc", "")
+	if !hookReached {
+		t.Error("the webhook was not reached with authentication on")
+	}
+}
+
+func TestRouter_AuthEnforcesScope(t *testing.T) {
+	var hookReached bool
+	h := newTestRouter(t, Options{Auth: stubAuth{enabled: true}}, &hookReached)
+
+	rec := serveAuth(h, http.MethodPost, "/api/agents", "read")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("POST /api/agents with a read token = %d, want 403", rec.Code)
+	}
+
+	rec = serveAuth(h, http.MethodPost, "/api/agents", "admin")
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("POST /api/agents with an admin token = %d, want 415 from the guard", rec.Code)
+	}
+}
+
+// A database error must not be a way in.
+func TestRouter_AuthFailsClosed(t *testing.T) {
+	var hookReached bool
+	h := newTestRouter(t, Options{Auth: stubAuth{err: errors.New("database is locked")}}, &hookReached)
+
+	rec := serveAuth(h, http.MethodGet, "/api/agents", "")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /api/agents when auth state is unreadable = %d, want 503", rec.Code)
+	}
+}
+
+func postLogin(h http.Handler, password, next string) *httptest.ResponseRecorder {
+	form := url.Values{"password": {password}, "next": {next}}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Host = "localhost:8990"
+	req.RemoteAddr = "192.0.2.1:50000"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLogin(t *testing.T) {
+	var hookReached bool
+	h := newTestRouter(t, Options{Auth: stubAuth{enabled: true}}, &hookReached)
+
+	rec := postLogin(h, "hunter22", "/tasks")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/tasks" {
+		t.Fatalf("sign-in = %d to %q, want 303 to /tasks", rec.Code, rec.Header().Get("Location"))
+	}
+	cookie := rec.Result().Cookies()
+	if len(cookie) != 1 || cookie[0].Name != auth.SessionCookie || !cookie[0].HttpOnly {
+		t.Errorf("sign-in cookies = %+v, want one HttpOnly session cookie", cookie)
+	}
+
+	rec = postLogin(h, "hunter22", "https://evil.example/")
+	if loc := rec.Header().Get("Location"); loc != "/" {
+		t.Errorf("sign-in with an off-site next redirected to %q, want /", loc)
+	}
+}
+
+func TestLogin_LimitsFailures(t *testing.T) {
+	var hookReached bool
+	h := newTestRouter(t, Options{Auth: stubAuth{enabled: true}}, &hookReached)
+
+	for i := 0; i < maxLoginFailures; i++ {
+		if rec := postLogin(h, "guess", "/"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong password #%d = %d, want 401", i+1, rec.Code)
+		}
+	}
+	if rec := postLogin(h, "hunter22", "/"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("sign-in after %d failures = %d, want 429", maxLoginFailures, rec.Code)
+	}
+}
+
+func TestSafeNext(t *testing.T) {
+	tests := map[string]string{
+		"":                     "/",
+		"/chats/1?x=y":         "/chats/1?x=y",
+		"//evil.example":       "/",
+		"/\\evil.example":      "/",
+		"https://evil.example": "/",
+		"/login?next=/":        "/",
+	}
+	for in, want := range tests {
+		if got := safeNext(in); got != want {
+			t.Errorf("safeNext(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
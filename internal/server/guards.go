@@ -25,11 +25,20 @@ type Options struct {
 	// process restarted — with nothing saying why. triggerService.publicURL
 	// re-reads it per call for the same reason. Optional; nil is treated as "".
 	PublicURLFunc func() string
+
+	// MCP is optional. When set, it is served at /mcp: the Model Context
+	// Protocol endpoint MCP clients such as Claude Code connect to.
+	MCP http.Handler
+
+	// Auth is optional. When set, every route but /health, /login and the
+	// webhooks requires a session or an API token whenever an admin password
+	// is set. See requireAuth.
+	Auth Authenticator
 }
 
-// defaultBindAddress is loopback because Agento ships without authentication
-// by design. Listening on every interface put an API that can run arbitrary
-// Bash on every network the machine joins.
+// defaultBindAddress is loopback because Agento ships without authentication:
+// it is off until an admin password is set. Listening on every interface put
+// an API that can run arbitrary Bash on every network the machine joins.
 const defaultBindAddress = "127.0.0.1"
 
 // listenHost resolves the interface to bind.
@@ -115,3 +115,55 @@ func TestRouter_PublicURLHostIsAdmitted(t *testing.T) {
 		t.Error("a configured PublicURL host must be admitted")
 	}
 }
+
+// MCP clients end a session with a body-less DELETE, so /mcp takes the Host
+// guard but not the content-type one.
+func TestRouter_MCPTakesOnlyTheHostGuard(t *testing.T) {
+	var hookReached, mcpReached bool
+	mcpHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mcpReached = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	h := newTestRouter(t, Options{MCP: mcpHandler}, &hookReached)
+
+	req := httptest.NewRequest(http.MethodDelete, "/mcp", nil)
+	req.Host = "localhost:8990"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent || !mcpReached {
+		t.Errorf("DELETE /mcp: status %d reached=%v, want the handler to be reached", rec.Code, mcpReached)
+	}
+
+	mcpReached = false
+	req = httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Host = "rebind.evil.example"
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden || mcpReached {
+		t.Errorf("POST /mcp with a foreign Host = %d, want 403", rec.Code)
+	}
+}
+
+// /v1 runs agents as /api does, so it needs the same two guards.
+func TestRouter_GuardsAreMountedOnOpenAIAPI(t *testing.T) {
+	var hookReached bool
+	h := newTestRouter(t, Options{}, &hookReached)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"x"}`))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Host = "localhost:8990"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("POST /v1/chat/completions with text/plain = %d, want 415", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	req.Host = "rebind.evil.example"
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("GET /v1/models with a foreign Host = %d, want 403", rec.Code)
+	}
+}
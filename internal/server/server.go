@@ -46,6 +46,8 @@ type Server struct {
 	httpServer     *http.Server
 	monitoringMgr  *telemetry.MonitoringManager
 	webhookHandler WebhookMounter
+	auth           Authenticator
+	loginLimiter   *loginLimiter
 }
 
 // New creates a new Server. Pass frontendFS=nil to proxy to Vite dev server on port 5173.
@@ -66,6 +68,8 @@ func New(
 		logger:         logger,
 		monitoringMgr:  monitoringMgr,
 		webhookHandler: webhookHandler,
+		auth:           opts.Auth,
+		loginLimiter:   newLoginLimiter(),
 	}
 
 	r := chi.NewRouter()
@@ -73,6 +77,7 @@ func New(
 	r.Use(middleware.RequestID)
 	r.Use(s.corsMiddleware())
 	r.Use(s.requestLogger)
+	r.Use(s.requireAuth)
 
 	// Health check
 	r.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
@@ -83,15 +88,23 @@ func New(
 		}
 	})
 
+	// The sign-in form. It posts a form rather than JSON, so it takes the Host
+	// guard only; the session cookie it sets is SameSite=Lax.
+	if s.auth != nil {
+		r.With(s.validateHost).Get(loginPath, s.handleLoginPage)
+		r.With(s.validateHost).Post(loginPath, s.handleLogin)
+	}
+
 	// Metrics endpoint: serves Prometheus metrics when enabled, 503 otherwise.
 	r.Get("/metrics", s.metricsHandler())
 
 	// API routes.
 	//
 	// The two guards below are scoped here rather than applied globally, and
-	// deliberately: POST /webhooks/telegram/{id} is mounted at the root, arrives
-	// from Telegram's servers with a foreign Host, and would be broken by
-	// either. It is not a hole — it authenticates with its own secret token.
+	// deliberately: POST /webhooks/{telegram,generic,github}/{id} are
+	// mounted at the root, arrive from other servers with a foreign Host,
+	// and would be broken by either. They are not a hole — each authenticates
+	// with its own secret.
 	// /health, /metrics and the SPA are likewise left alone; the attack this
 	// closes needs /api.
 	r.Route("/api", func(r chi.Router) {
@@ -100,6 +113,21 @@ func New(
 		apiSrv.Mount(r)
 	})
 
+	// The OpenAI-compatible API runs agents just as /api does, so it takes
+	// both guards. Its clients post JSON, which the content-type one admits.
+	r.Route("/v1", func(r chi.Router) {
+		r.Use(s.validateHost)
+		r.Use(requireJSONContentType)
+		apiSrv.MountOpenAI(r)
+	})
+
+	// The MCP endpoint takes the Host guard but not the content-type one: MCP
+	// clients end a session with a body-less DELETE. The handler refuses
+	// cross-origin browser requests itself.
+	if opts.MCP != nil {
+		r.With(s.validateHost).Handle("/mcp", opts.MCP)
+	}
+
 	// Webhook routes (mounted at root level, outside /api)
 	if s.webhookHandler != nil {
 		s.webhookHandler.Mount(r)
@@ -140,8 +168,8 @@ func (s *Server) Run(ctx context.Context) error {
 		s.logger.Info("listening on loopback only; set AGENTO_BIND=0.0.0.0 to allow other devices",
 			"addr", s.httpServer.Addr)
 	} else {
-		s.logger.Warn("listening on a non-loopback address; Agento has no authentication, "+
-			"so anyone who can reach this address can run agents on this machine",
+		s.logger.Warn("listening on a non-loopback address; unless an admin password is set "+
+			"(agento auth set-password), anyone who can reach this address can run agents on this machine",
 			"addr", s.httpServer.Addr)
 	}
 
@@ -14,6 +14,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 
 	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/policy"
 	"github.com/shaharia-lab/agento/internal/storage"
 )
 
@@ -96,23 +97,8 @@ func (s *agentService) Create(ctx context.Context, agent *config.AgentConfig) (*
 	if agent.Thinking == "" {
 		agent.Thinking = "adaptive"
 	}
-	switch agent.PermissionMode {
-	case "", "bypass", "default":
-		// valid
-	default:
-		return nil, &ValidationError{Field: "permission_mode", Message: "must be bypass or default"}
-	}
-	// The global equivalent is validated in SettingsManager; an agent override
-	// reaches the same filesystem resolution and must not be the one path that
-	// accepts anything. A relative value is the dangerous shape: the runner
-	// stats it against the server's working directory while the subprocess
-	// resolves --settings against its own, so the file checked and the file
-	// loaded would be different ones.
-	if agent.ClaudeConfigDir != "" {
-		if err := config.ValidateClaudeConfigDir(agent.ClaudeConfigDir); err != nil {
-			return nil, &ValidationError{Field: "claude_config_dir", Message: err.Error()}
-		}
-		agent.ClaudeConfigDir = config.NormalizeClaudeConfigDir(agent.ClaudeConfigDir)
+	if err := validateAgentSettings(agent); err != nil {
+		return nil, err
 	}
 
 	existing, err := s.repo.Get(ctx, agent.Slug)
@@ -160,11 +146,34 @@ func (s *agentService) Update(
 	if agent.Model == "" {
 		agent.Model = "claude-sonnet-4-6"
 	}
+	if err := validateAgentSettings(agent); err != nil {
+		return nil, err
+	}
+	if agent.Thinking == "" {
+		agent.Thinking = "adaptive"
+	}
+
+	if err := s.repo.Save(ctx, agent); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("saving agent: %w", err)
+	}
+
+	s.logger.Info("agent updated", "slug", slug)
+	return agent, nil
+}
+
+// validateAgentSettings checks the fields Create and Update validate alike,
+// and normalizes the Claude config dir.
+func validateAgentSettings(agent *config.AgentConfig) error {
 	switch agent.PermissionMode {
 	case "", "bypass", "default":
 		// valid
 	default:
-		return nil, &ValidationError{Field: "permission_mode", Message: "must be bypass or default"}
+		return &ValidationError{Field: "permission_mode", Message: "must be bypass or default"}
+	}
+	if _, err := policy.Compile(agent.Capabilities.Policy); err != nil {
+		return &ValidationError{Field: "capabilities.policy", Message: err.Error()}
 	}
 	// The global equivalent is validated in SettingsManager; an agent override
 	// reaches the same filesystem resolution and must not be the one path that
@@ -174,22 +183,11 @@ func (s *agentService) Update(
 	// loaded would be different ones.
 	if agent.ClaudeConfigDir != "" {
 		if err := config.ValidateClaudeConfigDir(agent.ClaudeConfigDir); err != nil {
-			return nil, &ValidationError{Field: "claude_config_dir", Message: err.Error()}
+			return &ValidationError{Field: "claude_config_dir", Message: err.Error()}
 		}
 		agent.ClaudeConfigDir = config.NormalizeClaudeConfigDir(agent.ClaudeConfigDir)
 	}
-	if agent.Thinking == "" {
-		agent.Thinking = "adaptive"
-	}
-
-	if err := s.repo.Save(ctx, agent); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("saving agent: %w", err)
-	}
-
-	s.logger.Info("agent updated", "slug", slug)
-	return agent, nil
+	return nil
 }
 
 func (s *agentService) Delete(ctx context.Context, slug string) error {
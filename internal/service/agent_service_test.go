@@ -373,6 +373,21 @@ func TestAgentService_Create(t *testing.T) {
 			wantErr: true,
 			errType: &ValidationError{},
 		},
+		{
+			name: "fails with an invalid tool policy",
+			input: &config.AgentConfig{
+				Name: "Agent",
+				Capabilities: config.AgentCapabilities{Policy: &config.ToolPolicy{
+					Rules: []config.PolicyRule{{Tool: "Bash", Action: "deny", Command: "(unclosed"}},
+				}},
+			},
+			setupMock: func(m *mocks.MockAgentStore) {
+				// no repo calls expected
+			},
+			wantErr:     true,
+			errType:     &ValidationError{},
+			errContains: "invalid command pattern",
+		},
 		{
 			name: "fails when slug already exists",
 			input: &config.AgentConfig{
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// BudgetStatus is a budget policy together with what has been spent against
+// it in the current window.
+type BudgetStatus struct {
+	Policy       *storage.BudgetPolicy `json:"policy"`
+	WindowStart  time.Time             `json:"window_start"`
+	SpentUSD     float64               `json:"spent_usd"`
+	RemainingUSD float64               `json:"remaining_usd"`
+	Exceeded     bool                  `json:"exceeded"`
+}
+
+// BudgetService defines the business logic interface for managing budget policies.
+type BudgetService interface {
+	// ListPolicies returns all budget policies.
+	ListPolicies(ctx context.Context) ([]*storage.BudgetPolicy, error)
+
+	// GetPolicy returns a single budget policy by ID.
+	GetPolicy(ctx context.Context, id string) (*storage.BudgetPolicy, error)
+
+	// CreatePolicy validates and creates a new budget policy.
+	CreatePolicy(ctx context.Context, p *storage.BudgetPolicy) (*storage.BudgetPolicy, error)
+
+	// UpdatePolicy validates and updates an existing budget policy.
+	UpdatePolicy(ctx context.Context, id string, p *storage.BudgetPolicy) (*storage.BudgetPolicy, error)
+
+	// DeletePolicy removes a budget policy by ID.
+	DeletePolicy(ctx context.Context, id string) error
+
+	// Status returns every policy with its spend in the current window.
+	Status(ctx context.Context) ([]BudgetStatus, error)
+}
+
+type budgetService struct {
+	store  storage.BudgetStore
+	logger *slog.Logger
+}
+
+// NewBudgetService returns a new BudgetService.
+func NewBudgetService(store storage.BudgetStore, logger *slog.Logger) BudgetService {
+	return &budgetService{store: store, logger: logger}
+}
+
+func (s *budgetService) ListPolicies(ctx context.Context) ([]*storage.BudgetPolicy, error) {
+	ctx, span := otel.Tracer("agento").Start(ctx, "budget.list")
+	defer span.End()
+
+	policies, err := s.store.ListPolicies(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("listing budget policies: %w", err)
+	}
+	return policies, nil
+}
+
+func (s *budgetService) GetPolicy(ctx context.Context, id string) (*storage.BudgetPolicy, error) {
+	ctx, span := otel.Tracer("agento").Start(ctx, "budget.get")
+	defer span.End()
+
+	p, err := s.store.GetPolicy(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("getting budget policy: %w", err)
+	}
+	if p == nil {
+		return nil, &NotFoundError{Resource: "budget_policy", ID: id}
+	}
+	return p, nil
+}
+
+func (s *budgetService) CreatePolicy(
+	ctx context.Context, p *storage.BudgetPolicy,
+) (*storage.BudgetPolicy, error) {
+	ctx, span := otel.Tracer("agento").Start(ctx, "budget.create")
+	defer span.End()
+
+	if err := validateBudgetPolicy(p); err != nil {
+		return nil, err
+	}
+	if err := s.store.CreatePolicy(ctx, p); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("creating budget policy: %w", err)
+	}
+
+	s.logger.Info("budget policy created", "id", p.ID, "scope", p.Scope, "period", p.Period)
+	return p, nil
+}
+
+func (s *budgetService) UpdatePolicy(
+	ctx context.Context, id string, p *storage.BudgetPolicy,
+) (*storage.BudgetPolicy, error) {
+	ctx, span := otel.Tracer("agento").Start(ctx, "budget.update")
+	defer span.End()
+
+	existing, err := s.store.GetPolicy(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("looking up budget policy: %w", err)
+	}
+	if existing == nil {
+		return nil, &NotFoundError{Resource: "budget_policy", ID: id}
+	}
+
+	p.ID = id
+	p.CreatedAt = existing.CreatedAt
+	if err := validateBudgetPolicy(p); err != nil {
+		return nil, err
+	}
+	if err := s.store.UpdatePolicy(ctx, p); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("updating budget policy: %w", err)
+	}
+
+	s.logger.Info("budget policy updated", "id", id)
+	return p, nil
+}
+
+func (s *budgetService) DeletePolicy(ctx context.Context, id string) error {
+	ctx, span := otel.Tracer("agento").Start(ctx, "budget.delete")
+	defer span.End()
+
+	existing, err := s.store.GetPolicy(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("looking up budget policy: %w", err)
+	}
+	if existing == nil {
+		return &NotFoundError{Resource: "budget_policy", ID: id}
+	}
+
+	if err := s.store.DeletePolicy(ctx, id); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("deleting budget policy: %w", err)
+	}
+
+	s.logger.Info("budget policy deleted", "id", id)
+	return nil
+}
+
+func (s *budgetService) Status(ctx context.Context) ([]BudgetStatus, error) {
+	ctx, span := otel.Tracer("agento").Start(ctx, "budget.status")
+	defer span.End()
+
+	policies, err := s.store.ListPolicies(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("listing budget policies: %w", err)
+	}
+
+	now := time.Now()
+	out := make([]BudgetStatus, 0, len(policies))
+	for _, p := range policies {
+		start := p.Period.WindowStart(now)
+		spent, sumErr := s.store.SumSpend(ctx, p.Scope, p.ScopeID, start)
+		if sumErr != nil {
+			span.RecordError(sumErr)
+			span.SetStatus(codes.Error, sumErr.Error())
+			return nil, fmt.Errorf("summing spend for budget %q: %w", p.ID, sumErr)
+		}
+		remaining := p.LimitUSD - spent
+		if remaining < 0 {
+			remaining = 0
+		}
+		out = append(out, BudgetStatus{
+			Policy:       p,
+			WindowStart:  start,
+			SpentUSD:     spent,
+			RemainingUSD: remaining,
+			Exceeded:     spent >= p.LimitUSD,
+		})
+	}
+	return out, nil
+}
+
+func validateBudgetPolicy(p *storage.BudgetPolicy) error {
+	switch p.Scope {
+	case storage.BudgetScopeGlobal:
+		// A global policy has no target; drop any stray value so it cannot
+		// be mistaken for one later.
+		p.ScopeID = ""
+	case storage.BudgetScopeAgent, storage.BudgetScopeTask:
+		if p.ScopeID == "" {
+			return &ValidationError{Field: "scope_id", Message: "scope_id is required for agent and task budgets"}
+		}
+	default:
+		return &ValidationError{Field: "scope", Message: "scope must be one of global, agent, task"}
+	}
+	switch p.Period {
+	case storage.BudgetPeriodDaily, storage.BudgetPeriodWeekly, storage.BudgetPeriodMonthly:
+	default:
+		return &ValidationError{Field: "period", Message: "period must be one of daily, weekly, monthly"}
+	}
+	if p.LimitUSD <= 0 {
+		return &ValidationError{Field: "limit_usd", Message: "limit_usd must be greater than zero"}
+	}
+	return nil
+}
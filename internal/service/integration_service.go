@@ -103,6 +103,10 @@ func validateIntegrationCredentials(cfg *config.IntegrationConfig) error {
 		return validateSlackCredentials(cfg)
 	case "whatsapp":
 		return validateWhatsAppCredentials(cfg)
+	case "webhook":
+		// A generic webhook integration holds no credentials: each of its
+		// trigger rules carries its own signing secret.
+		return nil
 	default:
 		if len(cfg.Credentials) == 0 {
 			return &ValidationError{Field: "credentials", Message: "credentials are required"}
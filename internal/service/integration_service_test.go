@@ -235,6 +235,16 @@ func TestIntegrationService_Create(t *testing.T) {
 				assert.True(t, got.Services["calendar"].Enabled)
 			},
 		},
+		{
+			name:  "success_webhook_without_credentials",
+			input: &config.IntegrationConfig{Name: "CI", Type: "webhook", Enabled: true},
+			setup: func(m *mocks.MockIntegrationStore) {
+				m.On("Save", mock.Anything, mock.AnythingOfType("*config.IntegrationConfig")).Return(nil)
+			},
+			checkFunc: func(t *testing.T, got *config.IntegrationConfig) {
+				assert.Equal(t, "webhook", got.Type)
+			},
+		},
 		{
 			name: "validation_error_missing_name",
 			input: func() *config.IntegrationConfig {
@@ -637,6 +637,65 @@ func (_c *MockTaskService_ResumeTask_Call) RunAndReturn(run func(context.Context
 	return _c
 }
 
+// RunTask provides a mock function with given fields: ctx, id
+func (_m *MockTaskService) RunTask(ctx context.Context, id string) (*storage.ScheduledTask, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RunTask")
+	}
+
+	var r0 *storage.ScheduledTask
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*storage.ScheduledTask, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *storage.ScheduledTask); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.ScheduledTask)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTaskService_RunTask_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RunTask'
+type MockTaskService_RunTask_Call struct {
+	*mock.Call
+}
+
+// RunTask is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockTaskService_Expecter) RunTask(ctx interface{}, id interface{}) *MockTaskService_RunTask_Call {
+	return &MockTaskService_RunTask_Call{Call: _e.mock.On("RunTask", ctx, id)}
+}
+
+func (_c *MockTaskService_RunTask_Call) Run(run func(ctx context.Context, id string)) *MockTaskService_RunTask_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockTaskService_RunTask_Call) Return(_a0 *storage.ScheduledTask, _a1 error) *MockTaskService_RunTask_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTaskService_RunTask_Call) RunAndReturn(run func(context.Context, string) (*storage.ScheduledTask, error)) *MockTaskService_RunTask_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateTask provides a mock function with given fields: ctx, id, task
 func (_m *MockTaskService) UpdateTask(ctx context.Context, id string, task *storage.ScheduledTask) (*storage.ScheduledTask, error) {
 	ret := _m.Called(ctx, id, task)
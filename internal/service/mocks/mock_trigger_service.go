@@ -355,6 +355,65 @@ func (_c *MockTriggerService_ListRules_Call) RunAndReturn(run func(context.Conte
 	return _c
 }
 
+// RegenerateRuleSecret provides a mock function with given fields: ctx, id
+func (_m *MockTriggerService) RegenerateRuleSecret(ctx context.Context, id string) (*config.TriggerRule, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegenerateRuleSecret")
+	}
+
+	var r0 *config.TriggerRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*config.TriggerRule, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *config.TriggerRule); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*config.TriggerRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTriggerService_RegenerateRuleSecret_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegenerateRuleSecret'
+type MockTriggerService_RegenerateRuleSecret_Call struct {
+	*mock.Call
+}
+
+// RegenerateRuleSecret is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockTriggerService_Expecter) RegenerateRuleSecret(ctx interface{}, id interface{}) *MockTriggerService_RegenerateRuleSecret_Call {
+	return &MockTriggerService_RegenerateRuleSecret_Call{Call: _e.mock.On("RegenerateRuleSecret", ctx, id)}
+}
+
+func (_c *MockTriggerService_RegenerateRuleSecret_Call) Run(run func(ctx context.Context, id string)) *MockTriggerService_RegenerateRuleSecret_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockTriggerService_RegenerateRuleSecret_Call) Return(_a0 *config.TriggerRule, _a1 error) *MockTriggerService_RegenerateRuleSecret_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTriggerService_RegenerateRuleSecret_Call) RunAndReturn(run func(context.Context, string) (*config.TriggerRule, error)) *MockTriggerService_RegenerateRuleSecret_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RegenerateSecret provides a mock function with given fields: ctx, integrationID
 func (_m *MockTriggerService) RegenerateSecret(ctx context.Context, integrationID string) error {
 	ret := _m.Called(ctx, integrationID)
@@ -2,12 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 
+	"github.com/shaharia-lab/agento/internal/pipeline"
 	"github.com/shaharia-lab/agento/internal/storage"
 )
 
@@ -16,6 +18,7 @@ import (
 type TaskScheduler interface {
 	ScheduleTask(task *storage.ScheduledTask) error
 	UnscheduleTask(taskID string)
+	RunNow(taskID string)
 }
 
 // TaskService defines the business logic interface for managing scheduled tasks.
@@ -27,6 +30,7 @@ type TaskService interface {
 	DeleteTask(ctx context.Context, id string) error
 	PauseTask(ctx context.Context, id string) (*storage.ScheduledTask, error)
 	ResumeTask(ctx context.Context, id string) (*storage.ScheduledTask, error)
+	RunTask(ctx context.Context, id string) (*storage.ScheduledTask, error)
 	ListJobHistory(ctx context.Context, taskID string, limit int) ([]*storage.JobHistory, error)
 	ListAllJobHistory(ctx context.Context, limit, offset int) ([]*storage.JobHistory, error)
 	GetJobHistory(ctx context.Context, id string) (*storage.JobHistory, error)
@@ -36,6 +40,9 @@ type TaskService interface {
 
 const errFmtLookingUpTask = "looking up task: %w"
 
+// ErrNoScheduler is returned by RunTask when no scheduler is configured.
+var ErrNoScheduler = errors.New("task scheduler is not running")
+
 type taskService struct {
 	repo      storage.TaskStore
 	scheduler TaskScheduler // optional; nil if no scheduler is configured
@@ -256,6 +263,33 @@ func (s *taskService) ResumeTask(ctx context.Context, id string) (*storage.Sched
 	return task, nil
 }
 
+// RunTask starts a run of an active task now, outside its schedule. The run
+// happens in the background; its outcome lands in the job history.
+func (s *taskService) RunTask(ctx context.Context, id string) (*storage.ScheduledTask, error) {
+	ctx, span := otel.Tracer("agento").Start(ctx, "task.run")
+	defer span.End()
+
+	if s.scheduler == nil {
+		return nil, ErrNoScheduler
+	}
+	task, err := s.repo.GetTask(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf(errFmtLookingUpTask, err)
+	}
+	if task == nil {
+		return nil, &NotFoundError{Resource: "task", ID: id}
+	}
+	if task.Status != storage.TaskStatusActive {
+		return nil, &ValidationError{Field: "status", Message: fmt.Sprintf("task is %s; resume it first", task.Status)}
+	}
+
+	s.scheduler.RunNow(id)
+	s.logger.Info("task run started", "id", id)
+	return task, nil
+}
+
 func (s *taskService) ListJobHistory(ctx context.Context, taskID string, limit int) ([]*storage.JobHistory, error) {
 	ctx, span := otel.Tracer("agento").Start(ctx, "task.list_job_history")
 	defer span.End()
@@ -346,12 +380,19 @@ func validateTask(task *storage.ScheduledTask) error {
 	if task.Name == "" {
 		return &ValidationError{Field: "name", Message: "name is required"}
 	}
-	if task.Prompt == "" {
+	if len(task.Steps) > 0 {
+		if err := validateSteps(task.Steps); err != nil {
+			return err
+		}
+	} else if task.Prompt == "" {
 		return &ValidationError{Field: "prompt", Message: "prompt is required"}
 	}
 	if task.TimeoutMinutes < 0 || task.TimeoutMinutes > 240 {
 		return &ValidationError{Field: "timeout_minutes", Message: "timeout must be between 1 and 240 minutes"}
 	}
+	if err := validateRetryPolicy(task.Retry); err != nil {
+		return err
+	}
 
 	switch task.ScheduleType {
 	case storage.ScheduleRunImmediately, storage.ScheduleOneOff, storage.ScheduleInterval, storage.ScheduleCron:
@@ -365,6 +406,49 @@ func validateTask(task *storage.ScheduledTask) error {
 	return validateScheduleConfig(task)
 }
 
+// validateSteps checks a pipeline definition, reporting the offending step
+// field.
+func validateSteps(steps []storage.PipelineStep) error {
+	_, err := pipeline.Plan(steps)
+	var planErr *pipeline.ValidationError
+	if errors.As(err, &planErr) {
+		return &ValidationError{Field: planErr.Field, Message: planErr.Message}
+	}
+	return err
+}
+
+// maxRetryAttempts bounds a retry policy's max_attempts.
+const maxRetryAttempts = 10
+
+func validateRetryPolicy(p storage.RetryPolicy) error {
+	if p.MaxAttempts < 0 || p.MaxAttempts > maxRetryAttempts {
+		return &ValidationError{
+			Field:   "retry.max_attempts",
+			Message: fmt.Sprintf("max_attempts must be between 0 and %d", maxRetryAttempts),
+		}
+	}
+	if p.BackoffSeconds < 0 || p.BackoffSeconds > 86400 {
+		return &ValidationError{Field: "retry.backoff_seconds", Message: "backoff must be between 1 second and 1 day"}
+	}
+	if p.MaxBackoffSeconds < 0 || p.MaxBackoffSeconds > 86400 {
+		return &ValidationError{Field: "retry.max_backoff_seconds", Message: "backoff must be between 1 second and 1 day"}
+	}
+	if p.BackoffMultiplier != 0 && (p.BackoffMultiplier < 1 || p.BackoffMultiplier > 10) {
+		return &ValidationError{Field: "retry.backoff_multiplier", Message: "multiplier must be between 1 and 10"}
+	}
+	for _, class := range p.RetryOn {
+		switch class {
+		case storage.ErrorClassTimeout, storage.ErrorClassRateLimit, storage.ErrorClassCrash, storage.ErrorClassAgentError:
+		default:
+			return &ValidationError{
+				Field:   "retry.retry_on",
+				Message: "must contain only timeout, rate_limit, crash, or agent_error",
+			}
+		}
+	}
+	return nil
+}
+
 func validateScheduleConfig(task *storage.ScheduledTask) error {
 	cfg := task.ScheduleConfig
 	switch task.ScheduleType {
@@ -110,6 +110,25 @@ func TestCreateTask(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestCreateTask_Pipeline(t *testing.T) {
+	repo := new(mocks.MockTaskStore)
+	repo.On("CreateTask", mock.Anything, mock.AnythingOfType("*storage.ScheduledTask")).Return(nil)
+
+	svc := newTestTaskService(repo)
+	task := &storage.ScheduledTask{
+		Name:         "Nightly digest",
+		ScheduleType: storage.ScheduleRunImmediately,
+		Steps: []storage.PipelineStep{
+			{ID: "collect", AgentSlug: "reader", Prompt: "Collect today's incidents"},
+			{ID: "summarize", AgentSlug: "writer", Prompt: "Summarize: {{previous}}"},
+		},
+	}
+	_, err := svc.CreateTask(context.Background(), task)
+
+	require.NoError(t, err, "a pipeline needs no task-level prompt")
+	repo.AssertExpectations(t)
+}
+
 func TestCreateTask_ValidationErrors(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -151,6 +170,34 @@ func TestCreateTask_ValidationErrors(t *testing.T) {
 			task:    &storage.ScheduledTask{Name: "n", Prompt: "p", ScheduleType: storage.ScheduleOneOff, ScheduleConfig: storage.ScheduleConfig{RunAt: "t"}, TimeoutMinutes: 300},
 			wantErr: "timeout",
 		},
+		{
+			name: "pipeline step dependency cycle",
+			task: &storage.ScheduledTask{Name: "n", ScheduleType: storage.ScheduleRunImmediately, Steps: []storage.PipelineStep{
+				{ID: "a", Prompt: "p", DependsOn: []string{"b"}},
+				{ID: "b", Prompt: "p", DependsOn: []string{"a"}},
+			}},
+			wantErr: "steps",
+		},
+		{
+			name: "pipeline step missing prompt",
+			task: &storage.ScheduledTask{Name: "n", ScheduleType: storage.ScheduleRunImmediately, Steps: []storage.PipelineStep{
+				{ID: "a", Prompt: "p"},
+				{ID: "b"},
+			}},
+			wantErr: "steps[1].prompt",
+		},
+		{
+			name: "too many retry attempts",
+			task: &storage.ScheduledTask{Name: "n", Prompt: "p", ScheduleType: storage.ScheduleRunImmediately,
+				Retry: storage.RetryPolicy{MaxAttempts: 50}},
+			wantErr: "retry.max_attempts",
+		},
+		{
+			name: "unknown retry error class",
+			task: &storage.ScheduledTask{Name: "n", Prompt: "p", ScheduleType: storage.ScheduleRunImmediately,
+				Retry: storage.RetryPolicy{MaxAttempts: 3, RetryOn: []storage.ErrorClass{"network"}}},
+			wantErr: "retry.retry_on",
+		},
 	}
 
 	for _, tt := range tests {
@@ -346,6 +393,47 @@ func TestResumeTask_StoreError(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+// ---------------------------------------------------------------------------
+// RunTask
+// ---------------------------------------------------------------------------
+
+type stubTaskScheduler struct {
+	ran []string
+}
+
+func (s *stubTaskScheduler) ScheduleTask(*storage.ScheduledTask) error { return nil }
+func (s *stubTaskScheduler) UnscheduleTask(string)                     {}
+func (s *stubTaskScheduler) RunNow(taskID string)                      { s.ran = append(s.ran, taskID) }
+
+func TestRunTask(t *testing.T) {
+	repo := new(mocks.MockTaskStore)
+	repo.On("GetTask", mock.Anything, "t1").Return(&storage.ScheduledTask{ID: "t1", Status: storage.TaskStatusActive}, nil)
+	repo.On("GetTask", mock.Anything, "t2").Return(&storage.ScheduledTask{ID: "t2", Status: storage.TaskStatusPaused}, nil)
+	repo.On("GetTask", mock.Anything, "missing").Return(nil, nil)
+	sched := &stubTaskScheduler{}
+	svc := NewTaskService(repo, sched, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	result, err := svc.RunTask(context.Background(), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, "t1", result.ID)
+	assert.Equal(t, []string{"t1"}, sched.ran)
+
+	_, err = svc.RunTask(context.Background(), "t2")
+	var invalid *ValidationError
+	assert.True(t, errors.As(err, &invalid), "a paused task is not run")
+
+	_, err = svc.RunTask(context.Background(), "missing")
+	var notFound *NotFoundError
+	assert.True(t, errors.As(err, &notFound))
+	assert.Equal(t, []string{"t1"}, sched.ran)
+}
+
+func TestRunTask_NoScheduler(t *testing.T) {
+	svc := newTestTaskService(new(mocks.MockTaskStore))
+	_, err := svc.RunTask(context.Background(), "t1")
+	assert.ErrorIs(t, err, ErrNoScheduler)
+}
+
 // ---------------------------------------------------------------------------
 // ListJobHistory
 // ---------------------------------------------------------------------------
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"strings"
 
 	"go.opentelemetry.io/otel"
@@ -12,6 +13,7 @@ import (
 	"github.com/shaharia-lab/agento/internal/config"
 	"github.com/shaharia-lab/agento/internal/integrations/telegram"
 	"github.com/shaharia-lab/agento/internal/storage"
+	"github.com/shaharia-lab/agento/internal/trigger"
 )
 
 // TriggerService defines the business logic interface for managing trigger rules and webhooks.
@@ -31,10 +33,11 @@ type TriggerService interface {
 	// DeleteRule removes a trigger rule by ID.
 	DeleteRule(ctx context.Context, id string) error
 
-	// RegisterWebhook registers a Telegram webhook for the given integration.
+	// RegisterWebhook registers a Telegram webhook, or enables inbound GitHub
+	// deliveries, for the given integration.
 	RegisterWebhook(ctx context.Context, integrationID string) error
 
-	// DeleteWebhook removes the Telegram webhook for the given integration.
+	// DeleteWebhook removes the webhook for the given integration.
 	DeleteWebhook(ctx context.Context, integrationID string) error
 
 	// GetWebhookStatus returns the webhook status for an integration.
@@ -42,14 +45,20 @@ type TriggerService interface {
 
 	// RegenerateSecret generates a new webhook secret and re-registers the webhook.
 	RegenerateSecret(ctx context.Context, integrationID string) error
+
+	// RegenerateRuleSecret replaces the signing secret of a generic webhook rule.
+	RegenerateRuleSecret(ctx context.Context, id string) (*config.TriggerRule, error)
 }
 
 // WebhookStatus holds the current webhook state for an integration.
 type WebhookStatus struct {
-	Status    string `json:"status"`     // "active", "inactive", "error"
+	Status    string `json:"status"`     // "active", "inactive", "error", "polling"
 	URL       string `json:"url"`        // The registered webhook URL
-	HasSecret bool   `json:"has_secret"` // Whether a secret is configured (never expose the secret itself)
+	HasSecret bool   `json:"has_secret"` // Whether a secret is configured
 	Error     string `json:"error"`      // Last error message, if any
+	// Secret is set only for GitHub integrations, whose secret the user must
+	// enter on GitHub. Telegram's is never exposed.
+	Secret string `json:"secret,omitempty"`
 }
 
 type triggerService struct {
@@ -87,6 +96,9 @@ func (s *triggerService) ListRules(ctx context.Context, integrationID string) ([
 		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("listing trigger rules: %w", err)
 	}
+	for _, r := range rules {
+		s.setWebhookURL(r)
+	}
 	return rules, nil
 }
 
@@ -103,6 +115,7 @@ func (s *triggerService) GetRule(ctx context.Context, id string) (*config.Trigge
 	if rule == nil {
 		return nil, &NotFoundError{Resource: "trigger_rule", ID: id}
 	}
+	s.setWebhookURL(rule)
 	return rule, nil
 }
 
@@ -125,6 +138,16 @@ func (s *triggerService) CreateRule(ctx context.Context, rule *config.TriggerRul
 		return nil, &NotFoundError{Resource: "integration", ID: rule.IntegrationID}
 	}
 
+	rule.WebhookSecret = ""
+	if err := prepareRuleForIntegration(rule, integration.Type); err != nil {
+		return nil, err
+	}
+	if integration.Type == "webhook" {
+		if rule.WebhookSecret, err = trigger.GenerateWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.triggerStore.CreateRule(ctx, rule); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -132,6 +155,7 @@ func (s *triggerService) CreateRule(ctx context.Context, rule *config.TriggerRul
 	}
 
 	s.logger.Info("trigger rule created", "id", rule.ID, "integration_id", rule.IntegrationID)
+	s.setWebhookURL(rule)
 	return rule, nil
 }
 
@@ -159,6 +183,23 @@ func (s *triggerService) UpdateRule(
 		return nil, err
 	}
 
+	integration, err := s.integrationStore.Get(ctx, existing.IntegrationID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("looking up integration: %w", err)
+	}
+	if integration == nil {
+		return nil, &NotFoundError{Resource: "integration", ID: existing.IntegrationID}
+	}
+
+	if err := prepareRuleForIntegration(rule, integration.Type); err != nil {
+		return nil, err
+	}
+	// The secret is only changed through RegenerateRuleSecret, so a rule
+	// edited in the UI keeps working for its callers.
+	rule.WebhookSecret = existing.WebhookSecret
+
 	if err := s.triggerStore.UpdateRule(ctx, rule); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -166,6 +207,7 @@ func (s *triggerService) UpdateRule(
 	}
 
 	s.logger.Info("trigger rule updated", "id", id)
+	s.setWebhookURL(rule)
 	return rule, nil
 }
 
@@ -220,8 +262,15 @@ func (s *triggerService) RegisterWebhook(ctx context.Context, integrationID stri
 	if integration == nil {
 		return &NotFoundError{Resource: "integration", ID: integrationID}
 	}
-	if integration.Type != "telegram" {
-		return &ValidationError{Field: "type", Message: "webhooks are only supported for telegram integrations"}
+	switch integration.Type {
+	case "telegram":
+	case "github":
+		return s.registerGitHubWebhook(ctx, integrationID)
+	default:
+		return &ValidationError{
+			Field:   "type",
+			Message: "webhooks are only supported for telegram and github integrations",
+		}
 	}
 
 	var creds config.TelegramCredentials
@@ -257,6 +306,27 @@ func (s *triggerService) RegisterWebhook(ctx context.Context, integrationID stri
 	return nil
 }
 
+// registerGitHubWebhook enables inbound GitHub deliveries. GitHub webhooks are
+// created by the user in the repository or organization settings, so this
+// only mints the secret they paste there; GetWebhookStatus shows it.
+func (s *triggerService) registerGitHubWebhook(ctx context.Context, integrationID string) error {
+	secret, _, _, err := s.triggerStore.GetWebhookInfo(ctx, integrationID)
+	if err != nil {
+		return fmt.Errorf("getting webhook info: %w", err)
+	}
+	if secret == "" {
+		if secret, err = trigger.GenerateWebhookSecret(); err != nil {
+			return err
+		}
+	}
+	if err := s.triggerStore.SetWebhookInfo(ctx, integrationID, secret, "active", ""); err != nil {
+		return fmt.Errorf("saving webhook info: %w", err)
+	}
+
+	s.logger.Info("github webhook enabled", "integration_id", integrationID)
+	return nil
+}
+
 func (s *triggerService) DeleteWebhook(ctx context.Context, integrationID string) error {
 	ctx, span := otel.Tracer("agento").Start(ctx, "trigger.delete_webhook")
 	defer span.End()
@@ -269,6 +339,16 @@ func (s *triggerService) DeleteWebhook(ctx context.Context, integrationID string
 		return &NotFoundError{Resource: "integration", ID: integrationID}
 	}
 
+	if integration.Type == "github" {
+		// Nothing to unregister: the webhook on GitHub's side is the user's,
+		// and deliveries to it are refused once the secret is cleared.
+		if err := s.triggerStore.SetWebhookInfo(ctx, integrationID, "", "inactive", ""); err != nil {
+			return fmt.Errorf("clearing webhook info: %w", err)
+		}
+		s.logger.Info("github webhook disabled", "integration_id", integrationID)
+		return nil
+	}
+
 	var creds config.TelegramCredentials
 	if parseErr := integration.ParseCredentials(&creds); parseErr != nil {
 		return fmt.Errorf("parsing telegram credentials: %w", parseErr)
@@ -307,10 +387,26 @@ func (s *triggerService) GetWebhookStatus(ctx context.Context, integrationID str
 		Error:     webhookErr,
 	}
 
+	integration, err := s.integrationStore.Get(ctx, integrationID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("looking up integration: %w", err)
+	}
+	kind := "telegram"
+	if integration != nil && integration.Type == "github" {
+		kind = "github"
+		// Unlike Telegram's, which Agento hands to the Bot API itself, a
+		// GitHub secret has to be pasted into GitHub by the user.
+		if status == "active" {
+			ws.Secret = secret
+		}
+	}
+
 	if status == "active" {
 		baseURL := s.publicURL()
 		if baseURL != "" {
-			ws.URL = fmt.Sprintf("%s/webhooks/telegram/%s", baseURL, integrationID)
+			ws.URL = fmt.Sprintf("%s/webhooks/%s/%s", baseURL, kind, integrationID)
 		}
 	}
 
@@ -335,6 +431,46 @@ func (s *triggerService) RegenerateSecret(ctx context.Context, integrationID str
 	return s.RegisterWebhook(ctx, integrationID)
 }
 
+func (s *triggerService) RegenerateRuleSecret(ctx context.Context, id string) (*config.TriggerRule, error) {
+	ctx, span := otel.Tracer("agento").Start(ctx, "trigger.regenerate_rule_secret")
+	defer span.End()
+
+	rule, err := s.triggerStore.GetRule(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("looking up trigger rule: %w", err)
+	}
+	if rule == nil {
+		return nil, &NotFoundError{Resource: "trigger_rule", ID: id}
+	}
+	if rule.WebhookSecret == "" {
+		return nil, &ValidationError{Field: "id", Message: "only generic webhook rules have a secret"}
+	}
+
+	if rule.WebhookSecret, err = trigger.GenerateWebhookSecret(); err != nil {
+		return nil, err
+	}
+	if err := s.triggerStore.UpdateRule(ctx, rule); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("updating trigger rule: %w", err)
+	}
+
+	s.logger.Info("trigger rule secret regenerated", "id", id)
+	s.setWebhookURL(rule)
+	return rule, nil
+}
+
+// setWebhookURL fills in the URL a generic webhook rule is reached at. It is
+// relative when no public URL is configured.
+func (s *triggerService) setWebhookURL(rule *config.TriggerRule) {
+	if rule.WebhookSecret == "" {
+		return
+	}
+	rule.WebhookURL = fmt.Sprintf("%s/webhooks/generic/%s", s.publicURL(), rule.ID)
+}
+
 func validateTriggerRule(rule *config.TriggerRule) error {
 	if rule.AgentSlug == "" {
 		return &ValidationError{Field: "agent_slug", Message: "agent_slug is required"}
@@ -344,3 +480,108 @@ func validateTriggerRule(rule *config.TriggerRule) error {
 	}
 	return nil
 }
+
+// prepareRuleForIntegration validates the fields that apply to rules on an
+// integration of the given type and clears those that do not, so a rule
+// never carries settings its dispatcher would silently ignore.
+func prepareRuleForIntegration(rule *config.TriggerRule, integrationType string) error {
+	switch integrationType {
+	case "webhook":
+		clearChatFilters(rule)
+		clearGitHubFilters(rule)
+		return validateWebhookRule(rule)
+	case "github":
+		clearChatFilters(rule)
+		rule.ResponseMode = ""
+		rule.CallbackURL = ""
+		return validateRuleVariables(rule.Variables, trigger.IsGitHubVariable)
+	default:
+		clearGitHubFilters(rule)
+		rule.PromptTemplate = ""
+		rule.Variables = nil
+		rule.ResponseMode = ""
+		rule.CallbackURL = ""
+		return validateConversation(rule)
+	}
+}
+
+// validateConversation checks a Telegram rule's conversation settings. The
+// idle timeout means nothing without a conversation, so it is cleared then.
+func validateConversation(rule *config.TriggerRule) error {
+	switch rule.Conversation {
+	case "":
+		rule.ConversationIdleMinutes = 0
+		return nil
+	case config.TriggerConversationChat, config.TriggerConversationThread:
+	default:
+		return &ValidationError{Field: "conversation", Message: "conversation must be one of chat, thread"}
+	}
+	if rule.ConversationIdleMinutes < 0 {
+		return &ValidationError{
+			Field:   "conversation_idle_minutes",
+			Message: "conversation_idle_minutes must not be negative",
+		}
+	}
+	return nil
+}
+
+// validateWebhookRule checks the fields of a rule on a generic webhook
+// integration. An empty response mode defaults to sync.
+func validateWebhookRule(rule *config.TriggerRule) error {
+	switch rule.ResponseMode {
+	case "":
+		rule.ResponseMode = config.WebhookResponseSync
+	case config.WebhookResponseSync, config.WebhookResponseCallback:
+	default:
+		return &ValidationError{Field: "response_mode", Message: "response_mode must be one of sync, callback"}
+	}
+
+	if rule.ResponseMode == config.WebhookResponseCallback {
+		u, err := url.Parse(rule.CallbackURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &ValidationError{
+				Field:   "callback_url",
+				Message: "callback_url must be an http or https URL when response_mode is callback",
+			}
+		}
+	}
+
+	return validateRuleVariables(rule.Variables, trigger.IsWebhookVariable)
+}
+
+// validateRuleVariables checks that every variable has a usable name and a
+// JSONPath the extractor supports. A name builtin reports is refused: the
+// variable would silently replace the built-in one in the prompt.
+func validateRuleVariables(vars map[string]string, builtin func(string) bool) error {
+	for name, path := range vars {
+		if name == "" || strings.ContainsAny(name, "{} ") {
+			return &ValidationError{Field: "variables", Message: fmt.Sprintf("invalid variable name %q", name)}
+		}
+		if builtin(name) {
+			return &ValidationError{
+				Field:   "variables",
+				Message: fmt.Sprintf("variable name %q is reserved for a built-in variable", name),
+			}
+		}
+		if err := trigger.ValidateJSONPath(path); err != nil {
+			return &ValidationError{Field: "variables", Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+func clearChatFilters(rule *config.TriggerRule) {
+	rule.FilterPrefix = ""
+	rule.FilterKeywords = nil
+	rule.FilterChatIDs = nil
+	rule.Conversation = ""
+	rule.ConversationIdleMinutes = 0
+}
+
+func clearGitHubFilters(rule *config.TriggerRule) {
+	rule.FilterEvents = nil
+	rule.FilterRepos = nil
+	rule.FilterLabels = nil
+	rule.FilterAuthors = nil
+	rule.FilterBranches = nil
+}
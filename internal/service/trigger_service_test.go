@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+func TestPrepareRuleForIntegration_Variables(t *testing.T) {
+	tests := []struct {
+		name            string
+		integrationType string
+		variables       map[string]string
+		wantErr         string
+	}{
+		{
+			name:            "webhook variable",
+			integrationType: "webhook",
+			variables:       map[string]string{"status": "$.alerts[0].status"},
+		},
+		{
+			// {{body}} is the raw payload; a variable must not replace it.
+			name:            "webhook variable named body",
+			integrationType: "webhook",
+			variables:       map[string]string{"body": "$.message"},
+			wantErr:         `"body" is reserved`,
+		},
+		{
+			name:            "github variable",
+			integrationType: "github",
+			variables:       map[string]string{"milestone": "$.issue.milestone.title"},
+		},
+		{
+			name:            "github variable named after a built-in",
+			integrationType: "github",
+			variables:       map[string]string{"title": "$.issue.title"},
+			wantErr:         `"title" is reserved`,
+		},
+		{
+			name:            "invalid name",
+			integrationType: "github",
+			variables:       map[string]string{"{x}": "$.a"},
+			wantErr:         "invalid variable name",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := &config.TriggerRule{AgentSlug: "ops", IntegrationID: "int-1", Variables: tc.variables}
+			err := prepareRuleForIntegration(rule, tc.integrationType)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var ve *ValidationError
+			require.ErrorAs(t, err, &ve)
+			assert.Equal(t, "variables", ve.Field)
+			assert.Contains(t, ve.Message, tc.wantErr)
+		})
+	}
+}
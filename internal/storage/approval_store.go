@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// ApprovalStatus is where an approval request stands.
+type ApprovalStatus string
+
+// Approval status constants.
+const (
+	ApprovalPending  ApprovalStatus = "pending"
+	ApprovalApproved ApprovalStatus = "approved"
+	ApprovalDenied   ApprovalStatus = "denied"
+	// ApprovalExpired means nobody answered before the timeout, or the run
+	// waiting on the answer ended first.
+	ApprovalExpired ApprovalStatus = "expired"
+)
+
+// ApprovalRequest is one gated tool call of an unattended run, and the answer
+// it got.
+type ApprovalRequest struct {
+	ID string `json:"id"`
+	// Source is the kind of run: "chat", "scheduled_task", "trigger" or "mcp".
+	Source        string `json:"source"`
+	AgentSlug     string `json:"agent_slug"`
+	TaskID        string `json:"task_id,omitempty"`
+	JobID         string `json:"job_id,omitempty"`
+	TriggerRuleID string `json:"trigger_rule_id,omitempty"`
+	ChatSessionID string `json:"chat_session_id,omitempty"`
+	// Title names the run for the approver: the task or the trigger rule.
+	Title     string `json:"title"`
+	ToolName  string `json:"tool_name"`
+	ToolInput string `json:"tool_input"`
+	// Channel is where the request was sent: "telegram", "slack", "email",
+	// or empty when it was only listed in the web UI.
+	Channel string `json:"channel"`
+	// ChannelRef locates the message the request was sent as, so the answer
+	// can be reported back to it: "integration/chat/message" on Telegram,
+	// "integration/channel/ts" on Slack.
+	ChannelRef string         `json:"-"`
+	Status     ApprovalStatus `json:"status"`
+	// DecidedBy names who answered, prefixed with where they did so, e.g.
+	// "telegram:@alice". Empty for pending and expired requests.
+	DecidedBy string     `json:"decided_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+// ApprovalFilter narrows ListApprovals. Zero fields do not filter.
+type ApprovalFilter struct {
+	Status ApprovalStatus
+	JobID  string
+	Limit  int
+}
+
+// ApprovalStore defines the persistence interface for approval requests.
+type ApprovalStore interface {
+	// CreateApproval inserts a new pending approval request.
+	CreateApproval(ctx context.Context, req *ApprovalRequest) error
+
+	// GetApproval returns an approval request by ID, or nil if not found.
+	GetApproval(ctx context.Context, id string) (*ApprovalRequest, error)
+
+	// ListApprovals returns approval requests matching filter, newest first.
+	ListApprovals(ctx context.Context, filter ApprovalFilter) ([]*ApprovalRequest, error)
+
+	// SetApprovalChannelRef records where a request was sent.
+	SetApprovalChannelRef(ctx context.Context, id, ref string) error
+
+	// DecideApproval moves a pending request to status. It reports false,
+	// changing nothing, when the request is no longer pending: the first
+	// answer wins.
+	DecideApproval(ctx context.Context, id string, status ApprovalStatus, decidedBy string, at time.Time) (bool, error)
+
+	// ExpirePendingApprovals expires every pending request and returns how
+	// many there were.
+	ExpirePendingApprovals(ctx context.Context, at time.Time) (int, error)
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// APITokenScope is what an API token may do.
+type APITokenScope string
+
+// API token scope constants.
+const (
+	// APITokenScopeRead may read analytics, sessions, chats, tasks and their
+	// records, and change nothing.
+	APITokenScopeRead APITokenScope = "read"
+	// APITokenScopeRun may also run agents: chats, chat completions and MCP.
+	APITokenScopeRun APITokenScope = "run"
+	// APITokenScopeAdmin may do anything the signed-in admin can.
+	APITokenScopeAdmin APITokenScope = "admin"
+	// APITokenScopeIngest may only deliver Claude Code hook events, and read
+	// nothing. It is the scope `agento hook install` gives the hook command.
+	APITokenScopeIngest APITokenScope = "ingest"
+)
+
+// APIToken is a personal API token. Only a hash of the secret is stored; the
+// secret itself is shown once, when the token is created.
+type APIToken struct {
+	ID    string        `json:"id"`
+	Name  string        `json:"name"`
+	Scope APITokenScope `json:"scope"`
+	// Prefix is the start of the secret, enough to tell tokens apart.
+	Prefix     string     `json:"prefix"`
+	TokenHash  string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// AuthSession is a signed-in browser session. Like a token it is stored as a
+// hash of the cookie's value.
+type AuthSession struct {
+	IDHash    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// AuthStore defines the persistence interface for the admin password, browser
+// sessions and API tokens.
+type AuthStore interface {
+	// GetPasswordHash returns the admin password's hash, or "" when none is
+	// set, which means authentication is off.
+	GetPasswordHash(ctx context.Context) (string, error)
+
+	// SetPasswordHash sets the admin password's hash.
+	SetPasswordHash(ctx context.Context, hash string) error
+
+	// ClearPassword removes the admin password, turning authentication off.
+	ClearPassword(ctx context.Context) error
+
+	// CreateAuthSession inserts a browser session.
+	CreateAuthSession(ctx context.Context, s *AuthSession) error
+
+	// GetAuthSession returns a session by the hash of its ID, or nil if not
+	// found.
+	GetAuthSession(ctx context.Context, idHash string) (*AuthSession, error)
+
+	// DeleteAuthSession removes a session.
+	DeleteAuthSession(ctx context.Context, idHash string) error
+
+	// DeleteAuthSessions removes every session, signing every browser out.
+	DeleteAuthSessions(ctx context.Context) error
+
+	// DeleteExpiredAuthSessions removes sessions that expired before now and
+	// returns how many there were.
+	DeleteExpiredAuthSessions(ctx context.Context, now time.Time) (int, error)
+
+	// CreateAPIToken inserts a token.
+	CreateAPIToken(ctx context.Context, t *APIToken) error
+
+	// ListAPITokens returns every token, newest first.
+	ListAPITokens(ctx context.Context) ([]*APIToken, error)
+
+	// GetAPITokenByHash returns a token by the hash of its secret, or nil if
+	// not found.
+	GetAPITokenByHash(ctx context.Context, hash string) (*APIToken, error)
+
+	// DeleteAPIToken revokes a token. It reports false when there was none.
+	DeleteAPIToken(ctx context.Context, id string) (bool, error)
+
+	// TouchAPIToken records that a token was used at.
+	TouchAPIToken(ctx context.Context, id string, at time.Time) error
+}
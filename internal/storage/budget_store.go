@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// BudgetScope names what a budget policy caps.
+type BudgetScope string
+
+// Budget scope constants.
+const (
+	// BudgetScopeGlobal caps every guarded run together. ScopeID is empty.
+	BudgetScopeGlobal BudgetScope = "global"
+	// BudgetScopeAgent caps runs of one agent. ScopeID is the agent slug.
+	BudgetScopeAgent BudgetScope = "agent"
+	// BudgetScopeTask caps runs of one scheduled task. ScopeID is the task ID.
+	BudgetScopeTask BudgetScope = "task"
+)
+
+// BudgetPeriod is the calendar window a budget policy resets on.
+type BudgetPeriod string
+
+// Budget period constants.
+const (
+	BudgetPeriodDaily   BudgetPeriod = "daily"
+	BudgetPeriodWeekly  BudgetPeriod = "weekly"
+	BudgetPeriodMonthly BudgetPeriod = "monthly"
+)
+
+// WindowStart returns the start of the window containing now, in now's
+// location. Weeks start on Monday. Windows are calendar-aligned rather than
+// rolling so that "$20 a day" means what a user reading their invoice means by
+// it: spend resets at midnight, not 24 hours after some earlier run.
+// An unknown period returns the zero time, which makes every recorded spend
+// count — the conservative reading of a policy the code does not understand.
+func (p BudgetPeriod) WindowStart(now time.Time) time.Time {
+	y, m, d := now.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	switch p {
+	case BudgetPeriodDaily:
+		return day
+	case BudgetPeriodWeekly:
+		// time.Weekday has Sunday as 0; shift so Monday is 0.
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case BudgetPeriodMonthly:
+		return time.Date(y, m, 1, 0, 0, 0, 0, now.Location())
+	}
+	return time.Time{}
+}
+
+// BudgetPolicy is a USD cap on guarded agent runs over a calendar period.
+type BudgetPolicy struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Scope     BudgetScope  `json:"scope"`
+	ScopeID   string       `json:"scope_id"`
+	Period    BudgetPeriod `json:"period"`
+	LimitUSD  float64      `json:"limit_usd"`
+	Enabled   bool         `json:"enabled"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// BudgetSpend is one settled run charged against the budget ledger.
+type BudgetSpend struct {
+	ID        string    `json:"id"`
+	AgentSlug string    `json:"agent_slug"`
+	TaskID    string    `json:"task_id"`
+	Source    string    `json:"source"`
+	CostUSD   float64   `json:"cost_usd"`
+	SpentAt   time.Time `json:"spent_at"`
+}
+
+// BudgetStore defines the persistence interface for budget policies and the
+// spend ledger they are checked against.
+type BudgetStore interface {
+	// ListPolicies returns all budget policies, ordered by creation time.
+	ListPolicies(ctx context.Context) ([]*BudgetPolicy, error)
+
+	// GetPolicy returns a single budget policy by ID, or nil if not found.
+	GetPolicy(ctx context.Context, id string) (*BudgetPolicy, error)
+
+	// CreatePolicy inserts a new budget policy.
+	CreatePolicy(ctx context.Context, p *BudgetPolicy) error
+
+	// UpdatePolicy persists changes to an existing budget policy.
+	UpdatePolicy(ctx context.Context, p *BudgetPolicy) error
+
+	// DeletePolicy removes a budget policy by ID.
+	DeletePolicy(ctx context.Context, id string) error
+
+	// RecordSpend appends a settled run to the spend ledger.
+	RecordSpend(ctx context.Context, s *BudgetSpend) error
+
+	// SumSpend returns the total USD recorded at or after since for the given
+	// scope. The global scope ignores scopeID and sums every entry.
+	SumSpend(ctx context.Context, scope BudgetScope, scopeID string, since time.Time) (float64, error)
+}
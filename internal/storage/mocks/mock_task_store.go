@@ -116,6 +116,53 @@ func (_c *MockTaskStore_CreateJobHistory_Call) RunAndReturn(run func(context.Con
 	return _c
 }
 
+// CreateJobStep provides a mock function with given fields: ctx, step
+func (_m *MockTaskStore) CreateJobStep(ctx context.Context, step *storage.JobStep) error {
+	ret := _m.Called(ctx, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateJobStep")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.JobStep) error); ok {
+		r0 = rf(ctx, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockTaskStore_CreateJobStep_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateJobStep'
+type MockTaskStore_CreateJobStep_Call struct {
+	*mock.Call
+}
+
+// CreateJobStep is a helper method to define mock.On call
+//   - ctx context.Context
+//   - step *storage.JobStep
+func (_e *MockTaskStore_Expecter) CreateJobStep(ctx interface{}, step interface{}) *MockTaskStore_CreateJobStep_Call {
+	return &MockTaskStore_CreateJobStep_Call{Call: _e.mock.On("CreateJobStep", ctx, step)}
+}
+
+func (_c *MockTaskStore_CreateJobStep_Call) Run(run func(ctx context.Context, step *storage.JobStep)) *MockTaskStore_CreateJobStep_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*storage.JobStep))
+	})
+	return _c
+}
+
+func (_c *MockTaskStore_CreateJobStep_Call) Return(_a0 error) *MockTaskStore_CreateJobStep_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockTaskStore_CreateJobStep_Call) RunAndReturn(run func(context.Context, *storage.JobStep) error) *MockTaskStore_CreateJobStep_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CreateTask provides a mock function with given fields: ctx, task
 func (_m *MockTaskStore) CreateTask(ctx context.Context, task *storage.ScheduledTask) error {
 	ret := _m.Called(ctx, task)
@@ -600,6 +647,53 @@ func (_c *MockTaskStore_UpdateJobHistory_Call) RunAndReturn(run func(context.Con
 	return _c
 }
 
+// UpdateJobStep provides a mock function with given fields: ctx, step
+func (_m *MockTaskStore) UpdateJobStep(ctx context.Context, step *storage.JobStep) error {
+	ret := _m.Called(ctx, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateJobStep")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.JobStep) error); ok {
+		r0 = rf(ctx, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockTaskStore_UpdateJobStep_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateJobStep'
+type MockTaskStore_UpdateJobStep_Call struct {
+	*mock.Call
+}
+
+// UpdateJobStep is a helper method to define mock.On call
+//   - ctx context.Context
+//   - step *storage.JobStep
+func (_e *MockTaskStore_Expecter) UpdateJobStep(ctx interface{}, step interface{}) *MockTaskStore_UpdateJobStep_Call {
+	return &MockTaskStore_UpdateJobStep_Call{Call: _e.mock.On("UpdateJobStep", ctx, step)}
+}
+
+func (_c *MockTaskStore_UpdateJobStep_Call) Run(run func(ctx context.Context, step *storage.JobStep)) *MockTaskStore_UpdateJobStep_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*storage.JobStep))
+	})
+	return _c
+}
+
+func (_c *MockTaskStore_UpdateJobStep_Call) Return(_a0 error) *MockTaskStore_UpdateJobStep_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockTaskStore_UpdateJobStep_Call) RunAndReturn(run func(context.Context, *storage.JobStep) error) *MockTaskStore_UpdateJobStep_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateTask provides a mock function with given fields: ctx, task
 func (_m *MockTaskStore) UpdateTask(ctx context.Context, task *storage.ScheduledTask) error {
 	ret := _m.Called(ctx, task)
@@ -8,6 +8,8 @@ import (
 	config "github.com/shaharia-lab/agento/internal/config"
 
 	mock "github.com/stretchr/testify/mock"
+
+	storage "github.com/shaharia-lab/agento/internal/storage"
 )
 
 // MockTriggerStore is an autogenerated mock type for the TriggerStore type
@@ -70,6 +72,54 @@ func (_c *MockTriggerStore_CreateRule_Call) RunAndReturn(run func(context.Contex
 	return _c
 }
 
+// DeleteConversation provides a mock function with given fields: ctx, ruleID, key
+func (_m *MockTriggerStore) DeleteConversation(ctx context.Context, ruleID string, key string) error {
+	ret := _m.Called(ctx, ruleID, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteConversation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, ruleID, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockTriggerStore_DeleteConversation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteConversation'
+type MockTriggerStore_DeleteConversation_Call struct {
+	*mock.Call
+}
+
+// DeleteConversation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ruleID string
+//   - key string
+func (_e *MockTriggerStore_Expecter) DeleteConversation(ctx interface{}, ruleID interface{}, key interface{}) *MockTriggerStore_DeleteConversation_Call {
+	return &MockTriggerStore_DeleteConversation_Call{Call: _e.mock.On("DeleteConversation", ctx, ruleID, key)}
+}
+
+func (_c *MockTriggerStore_DeleteConversation_Call) Run(run func(ctx context.Context, ruleID string, key string)) *MockTriggerStore_DeleteConversation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockTriggerStore_DeleteConversation_Call) Return(_a0 error) *MockTriggerStore_DeleteConversation_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockTriggerStore_DeleteConversation_Call) RunAndReturn(run func(context.Context, string, string) error) *MockTriggerStore_DeleteConversation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DeleteRule provides a mock function with given fields: ctx, id
 func (_m *MockTriggerStore) DeleteRule(ctx context.Context, id string) error {
 	ret := _m.Called(ctx, id)
@@ -164,6 +214,66 @@ func (_c *MockTriggerStore_DeleteRulesByIntegration_Call) RunAndReturn(run func(
 	return _c
 }
 
+// GetConversation provides a mock function with given fields: ctx, ruleID, key
+func (_m *MockTriggerStore) GetConversation(ctx context.Context, ruleID string, key string) (*storage.TriggerConversation, error) {
+	ret := _m.Called(ctx, ruleID, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetConversation")
+	}
+
+	var r0 *storage.TriggerConversation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*storage.TriggerConversation, error)); ok {
+		return rf(ctx, ruleID, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *storage.TriggerConversation); ok {
+		r0 = rf(ctx, ruleID, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*storage.TriggerConversation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ruleID, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTriggerStore_GetConversation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetConversation'
+type MockTriggerStore_GetConversation_Call struct {
+	*mock.Call
+}
+
+// GetConversation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ruleID string
+//   - key string
+func (_e *MockTriggerStore_Expecter) GetConversation(ctx interface{}, ruleID interface{}, key interface{}) *MockTriggerStore_GetConversation_Call {
+	return &MockTriggerStore_GetConversation_Call{Call: _e.mock.On("GetConversation", ctx, ruleID, key)}
+}
+
+func (_c *MockTriggerStore_GetConversation_Call) Run(run func(ctx context.Context, ruleID string, key string)) *MockTriggerStore_GetConversation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockTriggerStore_GetConversation_Call) Return(_a0 *storage.TriggerConversation, _a1 error) *MockTriggerStore_GetConversation_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTriggerStore_GetConversation_Call) RunAndReturn(run func(context.Context, string, string) (*storage.TriggerConversation, error)) *MockTriggerStore_GetConversation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetRule provides a mock function with given fields: ctx, id
 func (_m *MockTriggerStore) GetRule(ctx context.Context, id string) (*config.TriggerRule, error) {
 	ret := _m.Called(ctx, id)
@@ -223,6 +333,63 @@ func (_c *MockTriggerStore_GetRule_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// GetUpdateOffset provides a mock function with given fields: ctx, integrationID
+func (_m *MockTriggerStore) GetUpdateOffset(ctx context.Context, integrationID string) (int64, error) {
+	ret := _m.Called(ctx, integrationID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUpdateOffset")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, integrationID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, integrationID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, integrationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTriggerStore_GetUpdateOffset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUpdateOffset'
+type MockTriggerStore_GetUpdateOffset_Call struct {
+	*mock.Call
+}
+
+// GetUpdateOffset is a helper method to define mock.On call
+//   - ctx context.Context
+//   - integrationID string
+func (_e *MockTriggerStore_Expecter) GetUpdateOffset(ctx interface{}, integrationID interface{}) *MockTriggerStore_GetUpdateOffset_Call {
+	return &MockTriggerStore_GetUpdateOffset_Call{Call: _e.mock.On("GetUpdateOffset", ctx, integrationID)}
+}
+
+func (_c *MockTriggerStore_GetUpdateOffset_Call) Run(run func(ctx context.Context, integrationID string)) *MockTriggerStore_GetUpdateOffset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockTriggerStore_GetUpdateOffset_Call) Return(_a0 int64, _a1 error) *MockTriggerStore_GetUpdateOffset_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTriggerStore_GetUpdateOffset_Call) RunAndReturn(run func(context.Context, string) (int64, error)) *MockTriggerStore_GetUpdateOffset_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetWebhookInfo provides a mock function with given fields: ctx, integrationID
 func (_m *MockTriggerStore) GetWebhookInfo(ctx context.Context, integrationID string) (string, string, string, error) {
 	ret := _m.Called(ctx, integrationID)
@@ -459,6 +626,101 @@ func (_c *MockTriggerStore_MarkUpdateProcessed_Call) RunAndReturn(run func(conte
 	return _c
 }
 
+// SaveConversation provides a mock function with given fields: ctx, c
+func (_m *MockTriggerStore) SaveConversation(ctx context.Context, c *storage.TriggerConversation) error {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveConversation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *storage.TriggerConversation) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockTriggerStore_SaveConversation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveConversation'
+type MockTriggerStore_SaveConversation_Call struct {
+	*mock.Call
+}
+
+// SaveConversation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - c *storage.TriggerConversation
+func (_e *MockTriggerStore_Expecter) SaveConversation(ctx interface{}, c interface{}) *MockTriggerStore_SaveConversation_Call {
+	return &MockTriggerStore_SaveConversation_Call{Call: _e.mock.On("SaveConversation", ctx, c)}
+}
+
+func (_c *MockTriggerStore_SaveConversation_Call) Run(run func(ctx context.Context, c *storage.TriggerConversation)) *MockTriggerStore_SaveConversation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*storage.TriggerConversation))
+	})
+	return _c
+}
+
+func (_c *MockTriggerStore_SaveConversation_Call) Return(_a0 error) *MockTriggerStore_SaveConversation_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockTriggerStore_SaveConversation_Call) RunAndReturn(run func(context.Context, *storage.TriggerConversation) error) *MockTriggerStore_SaveConversation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveUpdateOffset provides a mock function with given fields: ctx, integrationID, offset
+func (_m *MockTriggerStore) SaveUpdateOffset(ctx context.Context, integrationID string, offset int64) error {
+	ret := _m.Called(ctx, integrationID, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveUpdateOffset")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) error); ok {
+		r0 = rf(ctx, integrationID, offset)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockTriggerStore_SaveUpdateOffset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveUpdateOffset'
+type MockTriggerStore_SaveUpdateOffset_Call struct {
+	*mock.Call
+}
+
+// SaveUpdateOffset is a helper method to define mock.On call
+//   - ctx context.Context
+//   - integrationID string
+//   - offset int64
+func (_e *MockTriggerStore_Expecter) SaveUpdateOffset(ctx interface{}, integrationID interface{}, offset interface{}) *MockTriggerStore_SaveUpdateOffset_Call {
+	return &MockTriggerStore_SaveUpdateOffset_Call{Call: _e.mock.On("SaveUpdateOffset", ctx, integrationID, offset)}
+}
+
+func (_c *MockTriggerStore_SaveUpdateOffset_Call) Run(run func(ctx context.Context, integrationID string, offset int64)) *MockTriggerStore_SaveUpdateOffset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockTriggerStore_SaveUpdateOffset_Call) Return(_a0 error) *MockTriggerStore_SaveUpdateOffset_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockTriggerStore_SaveUpdateOffset_Call) RunAndReturn(run func(context.Context, string, int64) error) *MockTriggerStore_SaveUpdateOffset_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetWebhookInfo provides a mock function with given fields: ctx, integrationID, secret, status, webhookErr
 func (_m *MockTriggerStore) SetWebhookInfo(ctx context.Context, integrationID string, secret string, status string, webhookErr string) error {
 	ret := _m.Called(ctx, integrationID, secret, status, webhookErr)
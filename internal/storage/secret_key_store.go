@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// CredentialCipher encrypts integration credentials and OAuth tokens at rest.
+// *secrets.Cipher satisfies it.
+type CredentialCipher interface {
+	// KeyID identifies the master key the cipher seals with.
+	KeyID() string
+	// Seal encrypts plaintext into the form stored in the database.
+	Seal(plaintext []byte) (string, error)
+	// Open decrypts a value Seal returned. A value stored before encryption
+	// was turned on is returned as it is.
+	Open(stored string) ([]byte, error)
+}
+
+// SecretKey records the master key integration credentials are encrypted
+// with. The key itself is never stored in the database.
+type SecretKey struct {
+	// KeyID names the key. Every sealed value carries it, and the OS keyring
+	// holds the key under it.
+	KeyID string
+	// Source is where the key is kept: "keyring", "keyfile", "file" or
+	// "passphrase".
+	Source string
+	// Salt is the salt the key-encryption key is derived with.
+	Salt string
+	// Check is a known value sealed with the key, which tells a wrong key
+	// from a missing one.
+	Check     string
+	CreatedAt time.Time
+}
+
+// SecretKeyStore keeps the SecretKey record and rewrites the stored
+// credentials when the key is created or rotated.
+type SecretKeyStore interface {
+	// GetSecretKey returns the current key record, or nil when credentials
+	// have never been encrypted.
+	GetSecretKey(ctx context.Context) (*SecretKey, error)
+
+	// RewriteCredentials passes every stored integration credential and auth
+	// value through rewrite in one transaction, and replaces the key record
+	// with key when it is non-nil. It returns how many values changed.
+	RewriteCredentials(ctx context.Context, key *SecretKey, rewrite func(stored string) (string, error)) (int, error)
+}
@@ -657,6 +657,426 @@ ALTER TABLE user_settings ADD COLUMN claude_config_dirs TEXT NOT NULL DEFAULT '[
 -- A per-agent override, so a work agent and a personal agent can be live in one
 -- Agento instance. Empty means the global default.
 ALTER TABLE agents ADD COLUMN claude_config_dir TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 28,
+		sql: `
+-- Budget guardrails.
+--
+-- A policy caps guarded agent runs (scheduled tasks and triggers) at a USD
+-- amount per calendar day, week or month, either globally, per agent slug or
+-- per scheduled task. scope_id is empty for the global scope.
+CREATE TABLE budget_policies (
+    id          TEXT PRIMARY KEY,
+    name        TEXT NOT NULL DEFAULT '',
+    scope       TEXT NOT NULL,
+    scope_id    TEXT NOT NULL DEFAULT '',
+    period      TEXT NOT NULL,
+    limit_usd   REAL NOT NULL,
+    enabled     INTEGER NOT NULL DEFAULT 1,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The ledger policies are checked against. It is Agento's own record of what
+-- its guarded runs cost rather than a query over claude_session_cache, for two
+-- reasons: the session cache is filled by a background scan that can lag a
+-- run by minutes, which is exactly the window in which a runaway schedule
+-- overspends; and the cache holds every Claude Code session on the machine,
+-- while a cap configured here is a cap on what Agento itself launches.
+--
+-- Rows are never updated. Deleting a policy does not touch the ledger, so a
+-- policy re-created mid-month sees the month's spend to date.
+CREATE TABLE budget_spend (
+    id          TEXT PRIMARY KEY,
+    agent_slug  TEXT NOT NULL DEFAULT '',
+    task_id     TEXT NOT NULL DEFAULT '',
+    source      TEXT NOT NULL DEFAULT '',
+    cost_usd    REAL NOT NULL,
+    spent_at    DATETIME NOT NULL
+);
+CREATE INDEX idx_budget_spend_spent_at ON budget_spend(spent_at);
+CREATE INDEX idx_budget_spend_agent    ON budget_spend(agent_slug, spent_at);
+CREATE INDEX idx_budget_spend_task     ON budget_spend(task_id, spent_at);
+`,
+	},
+	{
+		version: 29,
+		sql: `
+-- Generic webhook triggers.
+--
+-- A rule on a "webhook" integration is reached at its own URL,
+-- /webhooks/generic/{rule id}, so the secret lives on the rule rather than on
+-- the integration the way Telegram's does: each caller (a CI job, a monitor, a
+-- form) gets a secret that can be rotated without breaking the others.
+--
+-- prompt_template is interpolated with variables, a JSON object mapping a
+-- variable name to a JSONPath into the request body. response_mode is 'sync'
+-- (the answer is the HTTP response) or 'callback' (202 now, the answer POSTed
+-- to callback_url later). All are empty for Telegram rules.
+ALTER TABLE trigger_rules ADD COLUMN webhook_secret  TEXT NOT NULL DEFAULT '';
+ALTER TABLE trigger_rules ADD COLUMN prompt_template TEXT NOT NULL DEFAULT '';
+ALTER TABLE trigger_rules ADD COLUMN variables       TEXT NOT NULL DEFAULT '{}';
+ALTER TABLE trigger_rules ADD COLUMN response_mode   TEXT NOT NULL DEFAULT '';
+ALTER TABLE trigger_rules ADD COLUMN callback_url    TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 30,
+		sql: `
+-- GitHub webhook triggers.
+--
+-- GitHub delivers every event a repository or organization webhook is
+-- subscribed to to a single URL per integration, /webhooks/github/{id}, signed
+-- with the secret already kept in integrations.webhook_secret. Rules on the
+-- integration then pick the deliveries they care about with these filters,
+-- stored as JSON arrays like filter_keywords and filter_chat_ids. An empty
+-- array matches everything.
+ALTER TABLE trigger_rules ADD COLUMN filter_events   TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE trigger_rules ADD COLUMN filter_repos    TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE trigger_rules ADD COLUMN filter_labels   TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE trigger_rules ADD COLUMN filter_authors  TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE trigger_rules ADD COLUMN filter_branches TEXT NOT NULL DEFAULT '[]';
+`,
+	},
+	{
+		version: 31,
+		sql: `
+-- Multi-step pipelines.
+--
+-- A scheduled task with a non-empty pipeline_steps array (JSON, one object
+-- per step: id, agent_slug, prompt, depends_on, run_if, if_contains,
+-- timeout_minutes) runs each step as its own agent instead of the task's
+-- single prompt. A run is still one job_history row, carrying the summed
+-- usage and the final answer; job_steps holds a row per step, including the
+-- ones skipped because their condition was not met, and goes with the run.
+ALTER TABLE scheduled_tasks ADD COLUMN pipeline_steps TEXT NOT NULL DEFAULT '[]';
+
+CREATE TABLE job_steps (
+    id                          TEXT PRIMARY KEY,
+    job_id                      TEXT NOT NULL REFERENCES job_history(id) ON DELETE CASCADE,
+    step_id                     TEXT NOT NULL,
+    position                    INTEGER NOT NULL DEFAULT 0,
+    agent_slug                  TEXT NOT NULL DEFAULT '',
+    status                      TEXT NOT NULL DEFAULT 'running',
+    started_at                  DATETIME NOT NULL,
+    finished_at                 DATETIME,
+    duration_ms                 INTEGER NOT NULL DEFAULT 0,
+    chat_session_id             TEXT NOT NULL DEFAULT '',
+    prompt_preview              TEXT NOT NULL DEFAULT '',
+    error_message               TEXT NOT NULL DEFAULT '',
+    total_input_tokens          INTEGER NOT NULL DEFAULT 0,
+    total_output_tokens         INTEGER NOT NULL DEFAULT 0,
+    total_cache_creation_tokens INTEGER NOT NULL DEFAULT 0,
+    total_cache_read_tokens     INTEGER NOT NULL DEFAULT 0,
+    response_text               TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_job_steps_job ON job_steps(job_id, position);
+`,
+	},
+	{
+		version: 32,
+		sql: `
+-- Retry policies for scheduled tasks.
+--
+-- retry_policy is a JSON object (max_attempts, backoff_seconds,
+-- backoff_multiplier, max_backoff_seconds, retry_on); '{}' disables retries.
+-- Every attempt of a run gets its own job_history row: attempt counts from 1,
+-- and retry_of holds the id of the first attempt's row so the attempts of one
+-- run can be listed together. Existing rows are all first attempts.
+ALTER TABLE scheduled_tasks ADD COLUMN retry_policy TEXT NOT NULL DEFAULT '{}';
+ALTER TABLE job_history ADD COLUMN attempt  INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE job_history ADD COLUMN retry_of TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 33,
+		sql: `
+-- Full-text search over transcript content.
+--
+-- One row per journey step that carries text — a prompt, a reply, a thinking
+-- block, a tool call's input or a tool's output — addressed by the turn and
+-- step index the journey view renders, so a hit can link straight to it. A
+-- sub-agent's steps are indexed under the Task call that spawned them.
+--
+-- The scanner replaces a session's rows in the same transaction as its cache
+-- row, so the index is exactly as fresh as the sessions list: a transcript
+-- whose rows failed to commit still looks changed to the next scan's diff.
+-- Rows are never updated in place, only deleted and re-inserted.
+CREATE TABLE claude_transcript_step (
+    id         INTEGER PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    turn       INTEGER NOT NULL,
+    step       INTEGER NOT NULL,
+    kind       TEXT NOT NULL,
+    body       TEXT NOT NULL
+);
+CREATE INDEX idx_claude_transcript_step_session ON claude_transcript_step(session_id);
+
+-- The index is external-content: it stores only the inverted index and reads
+-- body back from claude_transcript_step for snippets, so transcript text is
+-- kept once. The triggers are what keep the two in step; FTS5 cannot follow
+-- its content table on its own.
+CREATE VIRTUAL TABLE claude_transcript_fts USING fts5(
+    body,
+    content = 'claude_transcript_step',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER claude_transcript_step_ai AFTER INSERT ON claude_transcript_step BEGIN
+    INSERT INTO claude_transcript_fts(rowid, body) VALUES (new.id, new.body);
+END;
+
+CREATE TRIGGER claude_transcript_step_ad AFTER DELETE ON claude_transcript_step BEGIN
+    INSERT INTO claude_transcript_fts(claude_transcript_fts, rowid, body) VALUES ('delete', old.id, old.body);
+END;
+`,
+	},
+	{
+		version: 34,
+		sql: `
+-- Conversation continuity for Telegram triggers.
+--
+-- A rule with a conversation mode ('chat' or 'thread') resumes one Agento chat
+-- session, and through its sdk_session_id one Claude session, across the
+-- messages of a Telegram chat or reply thread instead of starting fresh for
+-- each. conversation_key is the chat ID, or "chat:thread" in thread mode.
+-- A conversation idle for longer than conversation_idle_minutes (0 = the
+-- default) is replaced by a fresh one on the next message, as is one reset by
+-- the /new command; the chat session itself is kept as history.
+ALTER TABLE trigger_rules ADD COLUMN conversation              TEXT NOT NULL DEFAULT '';
+ALTER TABLE trigger_rules ADD COLUMN conversation_idle_minutes INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE trigger_conversations (
+    rule_id          TEXT NOT NULL REFERENCES trigger_rules(id) ON DELETE CASCADE,
+    conversation_key TEXT NOT NULL,
+    chat_session_id  TEXT NOT NULL,
+    last_message_at  DATETIME NOT NULL,
+    PRIMARY KEY (rule_id, conversation_key)
+);
+`,
+	},
+	{
+		version: 35,
+		sql: `
+-- Where long polling resumes for each Telegram integration: the update_id
+-- after the last one handed to the dispatcher. Telegram drops the updates
+-- before the offset passed to getUpdates, so a restart neither replays nor
+-- loses messages; telegram_processed_updates still catches any that a crash
+-- between dispatch and saving the offset would replay.
+CREATE TABLE telegram_update_offsets (
+    integration_id TEXT PRIMARY KEY REFERENCES integrations(id) ON DELETE CASCADE,
+    next_offset    INTEGER NOT NULL,
+    updated_at     DATETIME NOT NULL
+);
+`,
+	},
+	{
+		version: 36,
+		sql: `
+-- Human-in-the-loop approvals. Each row is one tool call an unattended run
+-- (scheduled task or trigger) was paused at because its agent gates the tool
+-- behind approval, and the answer it got. Rows are kept after the run as the
+-- record of who approved what; they are not tied to job_history by a foreign
+-- key so that deleting job history does not erase that record.
+CREATE TABLE approval_requests (
+    id              TEXT PRIMARY KEY,
+    source          TEXT NOT NULL,
+    agent_slug      TEXT NOT NULL DEFAULT '',
+    task_id         TEXT NOT NULL DEFAULT '',
+    job_id          TEXT NOT NULL DEFAULT '',
+    trigger_rule_id TEXT NOT NULL DEFAULT '',
+    chat_session_id TEXT NOT NULL DEFAULT '',
+    title           TEXT NOT NULL DEFAULT '',
+    tool_name       TEXT NOT NULL,
+    tool_input      TEXT NOT NULL DEFAULT '',
+    channel         TEXT NOT NULL DEFAULT '',
+    channel_ref     TEXT NOT NULL DEFAULT '',
+    status          TEXT NOT NULL,
+    decided_by      TEXT NOT NULL DEFAULT '',
+    created_at      DATETIME NOT NULL,
+    expires_at      DATETIME NOT NULL,
+    decided_at      DATETIME
+);
+CREATE INDEX idx_approval_requests_status ON approval_requests(status, created_at);
+CREATE INDEX idx_approval_requests_job ON approval_requests(job_id);
+`,
+	},
+	{
+		version: 37,
+		sql: `
+-- Tool call audit log: one row per tool call made by a chat, scheduled task
+-- or triggered run, with its full input, how it ended and the permission
+-- decision it got. The log is append-only; the triggers below refuse any
+-- change to a row once written. Like approval_requests it is not tied to
+-- chat sessions or job history by foreign keys, so deleting those keeps the
+-- record of what their runs did.
+CREATE TABLE tool_calls (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    tool_use_id     TEXT NOT NULL DEFAULT '',
+    source          TEXT NOT NULL,
+    agent_slug      TEXT NOT NULL DEFAULT '',
+    task_id         TEXT NOT NULL DEFAULT '',
+    job_id          TEXT NOT NULL DEFAULT '',
+    trigger_rule_id TEXT NOT NULL DEFAULT '',
+    chat_session_id TEXT NOT NULL DEFAULT '',
+    tool_name       TEXT NOT NULL,
+    input           TEXT NOT NULL DEFAULT '',
+    status          TEXT NOT NULL,
+    decision        TEXT NOT NULL,
+    decision_reason TEXT NOT NULL DEFAULT '',
+    started_at      DATETIME NOT NULL,
+    duration_ms     INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_tool_calls_started ON tool_calls(started_at);
+CREATE INDEX idx_tool_calls_agent ON tool_calls(agent_slug, started_at);
+CREATE INDEX idx_tool_calls_tool ON tool_calls(tool_name, started_at);
+CREATE INDEX idx_tool_calls_job ON tool_calls(job_id);
+CREATE INDEX idx_tool_calls_chat ON tool_calls(chat_session_id);
+
+CREATE TRIGGER tool_calls_no_update BEFORE UPDATE ON tool_calls
+BEGIN
+    SELECT RAISE(ABORT, 'tool_calls is append-only');
+END;
+CREATE TRIGGER tool_calls_no_delete BEFORE DELETE ON tool_calls
+BEGIN
+    SELECT RAISE(ABORT, 'tool_calls is append-only');
+END;
+`,
+	},
+	{
+		version: 38,
+		sql: `
+-- Optional authentication. auth_admin holds the admin password as a bcrypt
+-- hash; while it has no row, authentication is off. Browser sessions and API
+-- tokens are stored as SHA-256 hashes of their secrets, so a copy of the
+-- database does not sign anyone in.
+CREATE TABLE auth_admin (
+    id            INTEGER PRIMARY KEY CHECK (id = 1),
+    password_hash TEXT NOT NULL,
+    updated_at    DATETIME NOT NULL
+);
+
+CREATE TABLE auth_sessions (
+    id_hash    TEXT PRIMARY KEY,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL
+);
+CREATE INDEX idx_auth_sessions_expires ON auth_sessions(expires_at);
+
+CREATE TABLE api_tokens (
+    id           TEXT PRIMARY KEY,
+    name         TEXT NOT NULL,
+    scope        TEXT NOT NULL,
+    prefix       TEXT NOT NULL,
+    token_hash   TEXT NOT NULL UNIQUE,
+    created_at   DATETIME NOT NULL,
+    last_used_at DATETIME,
+    expires_at   DATETIME
+);
+`,
+	},
+	{
+		version: 39,
+		sql: `
+-- Encryption at rest for integration credentials. secret_key records which
+-- master key sealed them and where it is kept; the key itself never enters
+-- the database. While it has no row, credentials are stored as plaintext.
+CREATE TABLE secret_key (
+    id          INTEGER PRIMARY KEY CHECK (id = 1),
+    key_id      TEXT NOT NULL,
+    source      TEXT NOT NULL,
+    salt        TEXT NOT NULL,
+    check_value TEXT NOT NULL,
+    created_at  DATETIME NOT NULL
+);
+`,
+	},
+	{
+		version: 40,
+		sql: `
+-- Automatic backups: how often agento web writes one, how many are kept,
+-- where they go, and whether they carry integration credentials. An interval
+-- of 0 leaves automatic backups off.
+ALTER TABLE user_settings ADD COLUMN backup_interval_hours      INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_settings ADD COLUMN backup_keep                INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_settings ADD COLUMN backup_dir                 TEXT    NOT NULL DEFAULT '';
+ALTER TABLE user_settings ADD COLUMN backup_include_credentials INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		version: 41,
+		sql: `
+-- Transcript archive: one row per Claude Code transcript Agento holds a
+-- compressed copy of, so history outlives Claude Code's own cleanup. Copies
+-- are content-addressed by sha256 under the transcripts directory, and rows
+-- with the same content share one object. agent_id is blank for a session's
+-- own transcript and names the sub-agent otherwise; meta holds a sub-agent's
+-- .meta.json sidecar. original_missing_since is set once the transcript has
+-- gone from Claude's config dir, and only such sessions are ever pruned.
+CREATE TABLE IF NOT EXISTS claude_transcript_archive (
+    file_path              TEXT     PRIMARY KEY,
+    session_id             TEXT     NOT NULL,
+    project_path           TEXT     NOT NULL DEFAULT '',
+    config_dir             TEXT     NOT NULL DEFAULT '',
+    agent_id               TEXT     NOT NULL DEFAULT '',
+    sha256                 TEXT     NOT NULL,
+    size                   INTEGER  NOT NULL DEFAULT 0,
+    stored_size            INTEGER  NOT NULL DEFAULT 0,
+    file_mtime             DATETIME NOT NULL,
+    meta                   TEXT     NOT NULL DEFAULT '',
+    archived_at            DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    original_missing_since DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_claude_transcript_archive_session ON claude_transcript_archive(session_id);
+CREATE INDEX IF NOT EXISTS idx_claude_transcript_archive_sha256 ON claude_transcript_archive(sha256);
+
+ALTER TABLE user_settings ADD COLUMN transcript_archive_disabled       INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_settings ADD COLUMN transcript_archive_retention_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_settings ADD COLUMN transcript_archive_max_mb         INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		version: 42,
+		sql: `
+-- Sessions from coding agents other than Claude Code. coding_agent names the
+-- agent a cached session was read from; every row before this one is Claude
+-- Code's. imported_agents is the JSON list of agents the user chose to index.
+ALTER TABLE claude_session_cache ADD COLUMN coding_agent TEXT NOT NULL DEFAULT 'claude-code';
+CREATE INDEX IF NOT EXISTS idx_claude_session_cache_coding_agent ON claude_session_cache(coding_agent);
+
+ALTER TABLE user_settings ADD COLUMN imported_agents TEXT NOT NULL DEFAULT '[]';
+`,
+	},
+	{
+		version: 43,
+		sql: `
+-- Claude Code usage per transcript per minute, from which the subscription's
+-- 5-hour and weekly usage windows are reconstructed. minute is the unix time
+-- of the minute's start; cost_usd is the API-equivalent cost of the minute's
+-- messages. Rows are keyed by transcript, like the cache rows they are written
+-- with, so a re-read replaces exactly its own rows.
+CREATE TABLE IF NOT EXISTS claude_usage_bucket (
+    file_path                TEXT    NOT NULL,
+    config_dir               TEXT    NOT NULL DEFAULT '',
+    minute                   INTEGER NOT NULL,
+    messages                 INTEGER NOT NULL DEFAULT 0,
+    input_tokens             INTEGER NOT NULL DEFAULT 0,
+    output_tokens            INTEGER NOT NULL DEFAULT 0,
+    cache_creation_tokens    INTEGER NOT NULL DEFAULT 0,
+    cache_creation_5m_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_creation_1h_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_read_tokens        INTEGER NOT NULL DEFAULT 0,
+    cost_usd                 REAL    NOT NULL DEFAULT 0,
+    PRIMARY KEY (file_path, minute)
+);
+CREATE INDEX IF NOT EXISTS idx_claude_usage_bucket_dir_minute ON claude_usage_bucket(config_dir, minute);
+
+ALTER TABLE user_settings ADD COLUMN usage_limit_five_hour_usd REAL    NOT NULL DEFAULT 0;
+ALTER TABLE user_settings ADD COLUMN usage_limit_weekly_usd    REAL    NOT NULL DEFAULT 0;
+ALTER TABLE user_settings ADD COLUMN usage_warn_percent        INTEGER NOT NULL DEFAULT 0;
 `,
 	},
 }
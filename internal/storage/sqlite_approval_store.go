@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SQLiteApprovalStore implements ApprovalStore backed by a SQLite database.
+type SQLiteApprovalStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteApprovalStore returns a new SQLiteApprovalStore.
+func NewSQLiteApprovalStore(db *sql.DB) *SQLiteApprovalStore {
+	return &SQLiteApprovalStore{db: db}
+}
+
+const approvalColumns = `id, source, agent_slug, task_id, job_id, trigger_rule_id, chat_session_id,
+	title, tool_name, tool_input, channel, channel_ref, status, decided_by,
+	created_at, expires_at, decided_at`
+
+// CreateApproval inserts a new pending approval request.
+func (s *SQLiteApprovalStore) CreateApproval(ctx context.Context, req *ApprovalRequest) error {
+	if req.ID == "" {
+		req.ID = uuid.New().String()
+	}
+	if req.CreatedAt.IsZero() {
+		req.CreatedAt = time.Now().UTC()
+	}
+	req.Status = ApprovalPending
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO approval_requests (`+approvalColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		req.ID, req.Source, req.AgentSlug, req.TaskID, req.JobID, req.TriggerRuleID, req.ChatSessionID,
+		req.Title, req.ToolName, req.ToolInput, req.Channel, req.ChannelRef, string(req.Status), req.DecidedBy,
+		req.CreatedAt.UTC(), req.ExpiresAt.UTC(), nil,
+	)
+	if err != nil {
+		return fmt.Errorf("creating approval request: %w", err)
+	}
+	return nil
+}
+
+// GetApproval returns an approval request by ID, or nil if not found.
+func (s *SQLiteApprovalStore) GetApproval(ctx context.Context, id string) (*ApprovalRequest, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+approvalColumns+` FROM approval_requests WHERE id = ?`, id)
+	req, err := scanApproval(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting approval request %q: %w", id, err)
+	}
+	return req, nil
+}
+
+// ListApprovals returns approval requests matching filter, newest first.
+func (s *SQLiteApprovalStore) ListApprovals(ctx context.Context, filter ApprovalFilter) ([]*ApprovalRequest, error) {
+	var where []string
+	var args []any
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, string(filter.Status))
+	}
+	if filter.JobID != "" {
+		where = append(where, "job_id = ?")
+		args = append(args, filter.JobID)
+	}
+
+	query := `SELECT ` + approvalColumns + ` FROM approval_requests`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY created_at DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing approval requests: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	reqs := make([]*ApprovalRequest, 0)
+	for rows.Next() {
+		req, scanErr := scanApproval(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("scanning approval request: %w", scanErr)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, rows.Err()
+}
+
+// SetApprovalChannelRef records where a request was sent.
+func (s *SQLiteApprovalStore) SetApprovalChannelRef(ctx context.Context, id, ref string) error {
+	if _, err := s.db.ExecContext(ctx,
+		`UPDATE approval_requests SET channel_ref = ? WHERE id = ?`, ref, id); err != nil {
+		return fmt.Errorf("setting approval channel ref: %w", err)
+	}
+	return nil
+}
+
+// DecideApproval moves a pending request to status, reporting false when it
+// was no longer pending.
+func (s *SQLiteApprovalStore) DecideApproval(
+	ctx context.Context, id string, status ApprovalStatus, decidedBy string, at time.Time,
+) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE approval_requests SET status = ?, decided_by = ?, decided_at = ?
+		WHERE id = ? AND status = ?`,
+		string(status), decidedBy, at.UTC(), id, string(ApprovalPending),
+	)
+	if err != nil {
+		return false, fmt.Errorf("deciding approval request: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("deciding approval request: %w", err)
+	}
+	return n == 1, nil
+}
+
+// ExpirePendingApprovals expires every pending request.
+func (s *SQLiteApprovalStore) ExpirePendingApprovals(ctx context.Context, at time.Time) (int, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE approval_requests SET status = ?, decided_at = ? WHERE status = ?`,
+		string(ApprovalExpired), at.UTC(), string(ApprovalPending),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("expiring approval requests: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("expiring approval requests: %w", err)
+	}
+	return int(n), nil
+}
+
+func scanApproval(row interface{ Scan(...any) error }) (*ApprovalRequest, error) {
+	var req ApprovalRequest
+	var status string
+	var decidedAt sql.NullTime
+	if err := row.Scan(
+		&req.ID, &req.Source, &req.AgentSlug, &req.TaskID, &req.JobID, &req.TriggerRuleID, &req.ChatSessionID,
+		&req.Title, &req.ToolName, &req.ToolInput, &req.Channel, &req.ChannelRef, &status, &req.DecidedBy,
+		&req.CreatedAt, &req.ExpiresAt, &decidedAt,
+	); err != nil {
+		return nil, err
+	}
+	req.Status = ApprovalStatus(status)
+	if decidedAt.Valid {
+		req.DecidedAt = &decidedAt.Time
+	}
+	return &req, nil
+}
@@ -0,0 +1,80 @@
+package storage_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func TestSQLiteApprovalStore(t *testing.T) {
+	db, _, err := storage.NewSQLiteDB(":memory:", slog.Default())
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := storage.NewSQLiteApprovalStore(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	newRequest := func(jobID string) *storage.ApprovalRequest {
+		req := &storage.ApprovalRequest{
+			Source: "scheduled_task", AgentSlug: "ops", JobID: jobID, Title: "nightly deploy",
+			ToolName: "Bash", ToolInput: `{"command":"kubectl apply -f prod.yaml"}`,
+			Channel: "telegram", ExpiresAt: now.Add(30 * time.Minute),
+		}
+		require.NoError(t, store.CreateApproval(ctx, req))
+		return req
+	}
+
+	t.Run("the first answer wins", func(t *testing.T) {
+		req := newRequest("job-1")
+		require.NotEmpty(t, req.ID)
+		require.NoError(t, store.SetApprovalChannelRef(ctx, req.ID, "int-1/-100123/42"))
+
+		ok, err := store.DecideApproval(ctx, req.ID, storage.ApprovalApproved, "telegram:@alice", now)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = store.DecideApproval(ctx, req.ID, storage.ApprovalDenied, "web", now)
+		require.NoError(t, err)
+		assert.False(t, ok, "an answered request cannot be answered again")
+
+		got, err := store.GetApproval(ctx, req.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, storage.ApprovalApproved, got.Status)
+		assert.Equal(t, "telegram:@alice", got.DecidedBy)
+		assert.Equal(t, "int-1/-100123/42", got.ChannelRef)
+		require.NotNil(t, got.DecidedAt)
+	})
+
+	t.Run("list filters and expiry", func(t *testing.T) {
+		newRequest("job-2")
+		newRequest("job-2")
+
+		pending, err := store.ListApprovals(ctx, storage.ApprovalFilter{Status: storage.ApprovalPending})
+		require.NoError(t, err)
+		assert.Len(t, pending, 2)
+
+		forJob, err := store.ListApprovals(ctx, storage.ApprovalFilter{JobID: "job-2", Limit: 1})
+		require.NoError(t, err)
+		assert.Len(t, forJob, 1)
+
+		n, err := store.ExpirePendingApprovals(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		pending, err = store.ListApprovals(ctx, storage.ApprovalFilter{Status: storage.ApprovalPending})
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("missing request", func(t *testing.T) {
+		got, err := store.GetApproval(ctx, "nope")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+}
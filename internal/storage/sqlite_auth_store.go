@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SQLiteAuthStore implements AuthStore backed by a SQLite database.
+type SQLiteAuthStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteAuthStore returns a new SQLiteAuthStore.
+func NewSQLiteAuthStore(db *sql.DB) *SQLiteAuthStore {
+	return &SQLiteAuthStore{db: db}
+}
+
+const apiTokenColumns = `id, name, scope, prefix, token_hash, created_at, last_used_at, expires_at`
+
+// GetPasswordHash returns the admin password's hash, or "" when none is set.
+func (s *SQLiteAuthStore) GetPasswordHash(ctx context.Context) (string, error) {
+	var hash string
+	err := s.db.QueryRowContext(ctx, `SELECT password_hash FROM auth_admin WHERE id = 1`).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting admin password: %w", err)
+	}
+	return hash, nil
+}
+
+// SetPasswordHash sets the admin password's hash.
+func (s *SQLiteAuthStore) SetPasswordHash(ctx context.Context, hash string) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO auth_admin (id, password_hash, updated_at) VALUES (1, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET password_hash = excluded.password_hash, updated_at = excluded.updated_at`,
+		hash, time.Now().UTC(),
+	); err != nil {
+		return fmt.Errorf("setting admin password: %w", err)
+	}
+	return nil
+}
+
+// ClearPassword removes the admin password.
+func (s *SQLiteAuthStore) ClearPassword(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM auth_admin`); err != nil {
+		return fmt.Errorf("clearing admin password: %w", err)
+	}
+	return nil
+}
+
+// CreateAuthSession inserts a browser session.
+func (s *SQLiteAuthStore) CreateAuthSession(ctx context.Context, sess *AuthSession) error {
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO auth_sessions (id_hash, created_at, expires_at) VALUES (?, ?, ?)`,
+		sess.IDHash, sess.CreatedAt.UTC(), sess.ExpiresAt.UTC(),
+	); err != nil {
+		return fmt.Errorf("creating auth session: %w", err)
+	}
+	return nil
+}
+
+// GetAuthSession returns a session by the hash of its ID, or nil if not found.
+func (s *SQLiteAuthStore) GetAuthSession(ctx context.Context, idHash string) (*AuthSession, error) {
+	sess := AuthSession{IDHash: idHash}
+	err := s.db.QueryRowContext(ctx,
+		`SELECT created_at, expires_at FROM auth_sessions WHERE id_hash = ?`, idHash,
+	).Scan(&sess.CreatedAt, &sess.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting auth session: %w", err)
+	}
+	return &sess, nil
+}
+
+// DeleteAuthSession removes a session.
+func (s *SQLiteAuthStore) DeleteAuthSession(ctx context.Context, idHash string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM auth_sessions WHERE id_hash = ?`, idHash); err != nil {
+		return fmt.Errorf("deleting auth session: %w", err)
+	}
+	return nil
+}
+
+// DeleteAuthSessions removes every session.
+func (s *SQLiteAuthStore) DeleteAuthSessions(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM auth_sessions`); err != nil {
+		return fmt.Errorf("deleting auth sessions: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredAuthSessions removes sessions that expired before now.
+func (s *SQLiteAuthStore) DeleteExpiredAuthSessions(ctx context.Context, now time.Time) (int, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM auth_sessions WHERE expires_at < ?`, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("deleting expired auth sessions: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("deleting expired auth sessions: %w", err)
+	}
+	return int(n), nil
+}
+
+// CreateAPIToken inserts a token.
+func (s *SQLiteAuthStore) CreateAPIToken(ctx context.Context, t *APIToken) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO api_tokens (`+apiTokenColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.ID, t.Name, string(t.Scope), t.Prefix, t.TokenHash,
+		t.CreatedAt.UTC(), t.LastUsedAt, t.ExpiresAt,
+	); err != nil {
+		return fmt.Errorf("creating API token: %w", err)
+	}
+	return nil
+}
+
+// ListAPITokens returns every token, newest first.
+func (s *SQLiteAuthStore) ListAPITokens(ctx context.Context) ([]*APIToken, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+apiTokenColumns+` FROM api_tokens ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("listing API tokens: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	tokens := make([]*APIToken, 0)
+	for rows.Next() {
+		t, scanErr := scanAPIToken(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("scanning API token: %w", scanErr)
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// GetAPITokenByHash returns a token by the hash of its secret, or nil if not
+// found.
+func (s *SQLiteAuthStore) GetAPITokenByHash(ctx context.Context, hash string) (*APIToken, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+apiTokenColumns+` FROM api_tokens WHERE token_hash = ?`, hash)
+	t, err := scanAPIToken(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting API token: %w", err)
+	}
+	return t, nil
+}
+
+// DeleteAPIToken revokes a token, reporting false when there was none.
+func (s *SQLiteAuthStore) DeleteAPIToken(ctx context.Context, id string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM api_tokens WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("deleting API token: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("deleting API token: %w", err)
+	}
+	return n == 1, nil
+}
+
+// TouchAPIToken records that a token was used at.
+func (s *SQLiteAuthStore) TouchAPIToken(ctx context.Context, id string, at time.Time) error {
+	if _, err := s.db.ExecContext(ctx,
+		`UPDATE api_tokens SET last_used_at = ? WHERE id = ?`, at.UTC(), id); err != nil {
+		return fmt.Errorf("touching API token: %w", err)
+	}
+	return nil
+}
+
+func scanAPIToken(row interface{ Scan(...any) error }) (*APIToken, error) {
+	var t APIToken
+	var scope string
+	var lastUsedAt, expiresAt sql.NullTime
+	if err := row.Scan(
+		&t.ID, &t.Name, &scope, &t.Prefix, &t.TokenHash, &t.CreatedAt, &lastUsedAt, &expiresAt,
+	); err != nil {
+		return nil, err
+	}
+	t.Scope = APITokenScope(scope)
+	if lastUsedAt.Valid {
+		t.LastUsedAt = &lastUsedAt.Time
+	}
+	if expiresAt.Valid {
+		t.ExpiresAt = &expiresAt.Time
+	}
+	return &t, nil
+}
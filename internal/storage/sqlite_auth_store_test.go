@@ -0,0 +1,103 @@
+package storage_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func TestSQLiteAuthStore(t *testing.T) {
+	db, _, err := storage.NewSQLiteDB(":memory:", slog.Default())
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := storage.NewSQLiteAuthStore(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("password set, replaced and cleared", func(t *testing.T) {
+		hash, err := store.GetPasswordHash(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, hash, "no password means authentication is off")
+
+		require.NoError(t, store.SetPasswordHash(ctx, "first"))
+		require.NoError(t, store.SetPasswordHash(ctx, "second"))
+		hash, err = store.GetPasswordHash(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "second", hash)
+
+		require.NoError(t, store.ClearPassword(ctx))
+		hash, err = store.GetPasswordHash(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, hash)
+	})
+
+	t.Run("sessions expire and are pruned", func(t *testing.T) {
+		require.NoError(t, store.CreateAuthSession(ctx, &storage.AuthSession{
+			IDHash: "live", CreatedAt: now, ExpiresAt: now.Add(time.Hour),
+		}))
+		require.NoError(t, store.CreateAuthSession(ctx, &storage.AuthSession{
+			IDHash: "stale", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour),
+		}))
+
+		n, err := store.DeleteExpiredAuthSessions(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		got, err := store.GetAuthSession(ctx, "live")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.True(t, got.ExpiresAt.Equal(now.Add(time.Hour)))
+		got, err = store.GetAuthSession(ctx, "stale")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+
+		require.NoError(t, store.DeleteAuthSessions(ctx))
+		got, err = store.GetAuthSession(ctx, "live")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("tokens are found by hash, touched and revoked", func(t *testing.T) {
+		expires := now.Add(24 * time.Hour)
+		tok := &storage.APIToken{
+			Name: "ci", Scope: storage.APITokenScopeRead, Prefix: "agento_abc123",
+			TokenHash: "hash-1", ExpiresAt: &expires,
+		}
+		require.NoError(t, store.CreateAPIToken(ctx, tok))
+		require.NotEmpty(t, tok.ID)
+
+		got, err := store.GetAPITokenByHash(ctx, "hash-1")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, "ci", got.Name)
+		assert.Equal(t, storage.APITokenScopeRead, got.Scope)
+		assert.Nil(t, got.LastUsedAt)
+		require.NotNil(t, got.ExpiresAt)
+		assert.True(t, got.ExpiresAt.Equal(expires))
+
+		require.NoError(t, store.TouchAPIToken(ctx, tok.ID, now))
+		list, err := store.ListAPITokens(ctx)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		require.NotNil(t, list[0].LastUsedAt)
+		assert.True(t, list[0].LastUsedAt.Equal(now))
+
+		ok, err := store.DeleteAPIToken(ctx, tok.ID)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = store.DeleteAPIToken(ctx, tok.ID)
+		require.NoError(t, err)
+		assert.False(t, ok, "a revoked token cannot be revoked again")
+
+		got, err = store.GetAPITokenByHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+}
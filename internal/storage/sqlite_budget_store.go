@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SQLiteBudgetStore implements BudgetStore backed by a SQLite database.
+type SQLiteBudgetStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteBudgetStore returns a new SQLiteBudgetStore.
+func NewSQLiteBudgetStore(db *sql.DB) *SQLiteBudgetStore {
+	return &SQLiteBudgetStore{db: db}
+}
+
+const budgetPolicyColumns = `id, name, scope, scope_id, period, limit_usd, enabled, created_at, updated_at`
+
+// ListPolicies returns all budget policies, ordered by creation time.
+func (s *SQLiteBudgetStore) ListPolicies(ctx context.Context) ([]*BudgetPolicy, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+budgetPolicyColumns+` FROM budget_policies ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("listing budget policies: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	policies := make([]*BudgetPolicy, 0)
+	for rows.Next() {
+		p, scanErr := scanBudgetPolicy(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("scanning budget policy: %w", scanErr)
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// GetPolicy returns a single budget policy by ID, or nil if not found.
+func (s *SQLiteBudgetStore) GetPolicy(ctx context.Context, id string) (*BudgetPolicy, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+budgetPolicyColumns+` FROM budget_policies WHERE id = ?`, id)
+	p, err := scanBudgetPolicy(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting budget policy %q: %w", id, err)
+	}
+	return p, nil
+}
+
+// CreatePolicy inserts a new budget policy.
+func (s *SQLiteBudgetStore) CreatePolicy(ctx context.Context, p *BudgetPolicy) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+
+	enabled := 0
+	if p.Enabled {
+		enabled = 1
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO budget_policies
+			(id, name, scope, scope_id, period, limit_usd, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.ID, p.Name, string(p.Scope), p.ScopeID, string(p.Period), p.LimitUSD,
+		enabled, p.CreatedAt, p.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("creating budget policy: %w", err)
+	}
+	return nil
+}
+
+// UpdatePolicy persists changes to an existing budget policy.
+func (s *SQLiteBudgetStore) UpdatePolicy(ctx context.Context, p *BudgetPolicy) error {
+	p.UpdatedAt = time.Now().UTC()
+
+	enabled := 0
+	if p.Enabled {
+		enabled = 1
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE budget_policies SET
+			name = ?, scope = ?, scope_id = ?, period = ?, limit_usd = ?, enabled = ?,
+			updated_at = ?
+		WHERE id = ?`,
+		p.Name, string(p.Scope), p.ScopeID, string(p.Period), p.LimitUSD,
+		enabled, p.UpdatedAt, p.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating budget policy: %w", err)
+	}
+	return nil
+}
+
+// DeletePolicy removes a budget policy by ID.
+func (s *SQLiteBudgetStore) DeletePolicy(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM budget_policies WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting budget policy %q: %w", id, err)
+	}
+	return nil
+}
+
+// RecordSpend appends a settled run to the spend ledger.
+func (s *SQLiteBudgetStore) RecordSpend(ctx context.Context, sp *BudgetSpend) error {
+	if sp.ID == "" {
+		sp.ID = uuid.New().String()
+	}
+	if sp.SpentAt.IsZero() {
+		sp.SpentAt = time.Now()
+	}
+	sp.SpentAt = sp.SpentAt.UTC()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO budget_spend (id, agent_slug, task_id, source, cost_usd, spent_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		sp.ID, sp.AgentSlug, sp.TaskID, sp.Source, sp.CostUSD, sp.SpentAt,
+	)
+	if err != nil {
+		return fmt.Errorf("recording budget spend: %w", err)
+	}
+	return nil
+}
+
+// SumSpend returns the total USD recorded at or after since for the given
+// scope. The global scope ignores scopeID and sums every entry.
+func (s *SQLiteBudgetStore) SumSpend(
+	ctx context.Context, scope BudgetScope, scopeID string, since time.Time,
+) (float64, error) {
+	query := `SELECT COALESCE(SUM(cost_usd), 0) FROM budget_spend WHERE spent_at >= ?`
+	args := []any{since.UTC()}
+	switch scope {
+	case BudgetScopeGlobal:
+	case BudgetScopeAgent:
+		query += ` AND agent_slug = ?`
+		args = append(args, scopeID)
+	case BudgetScopeTask:
+		query += ` AND task_id = ?`
+		args = append(args, scopeID)
+	default:
+		return 0, fmt.Errorf("summing budget spend: unknown scope %q", scope)
+	}
+
+	var total float64
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("summing budget spend: %w", err)
+	}
+	return total, nil
+}
+
+func scanBudgetPolicy(row interface{ Scan(...any) error }) (*BudgetPolicy, error) {
+	var p BudgetPolicy
+	var scope, period string
+	var enabled int
+	if err := row.Scan(
+		&p.ID, &p.Name, &scope, &p.ScopeID, &period, &p.LimitUSD, &enabled,
+		&p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	p.Scope = BudgetScope(scope)
+	p.Period = BudgetPeriod(period)
+	p.Enabled = enabled != 0
+	return &p, nil
+}
@@ -0,0 +1,104 @@
+package storage_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func TestSQLiteBudgetStore(t *testing.T) {
+	db, _, err := storage.NewSQLiteDB(":memory:", slog.Default())
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := storage.NewSQLiteBudgetStore(db)
+	ctx := context.Background()
+
+	t.Run("policy CRUD", func(t *testing.T) {
+		p := &storage.BudgetPolicy{
+			Name:     "writer daily",
+			Scope:    storage.BudgetScopeAgent,
+			ScopeID:  "writer",
+			Period:   storage.BudgetPeriodDaily,
+			LimitUSD: 5,
+			Enabled:  true,
+		}
+		require.NoError(t, store.CreatePolicy(ctx, p))
+		require.NotEmpty(t, p.ID)
+
+		got, err := store.GetPolicy(ctx, p.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, storage.BudgetScopeAgent, got.Scope)
+		assert.Equal(t, "writer", got.ScopeID)
+		assert.Equal(t, storage.BudgetPeriodDaily, got.Period)
+		assert.InDelta(t, 5.0, got.LimitUSD, 1e-9)
+		assert.True(t, got.Enabled)
+
+		got.LimitUSD = 7.5
+		got.Enabled = false
+		require.NoError(t, store.UpdatePolicy(ctx, got))
+
+		list, err := store.ListPolicies(ctx)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.InDelta(t, 7.5, list[0].LimitUSD, 1e-9)
+		assert.False(t, list[0].Enabled)
+
+		require.NoError(t, store.DeletePolicy(ctx, p.ID))
+		got, err = store.GetPolicy(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("sum spend by scope and window", func(t *testing.T) {
+		now := time.Now().UTC()
+		entries := []*storage.BudgetSpend{
+			{AgentSlug: "a", TaskID: "t1", CostUSD: 1, SpentAt: now},
+			{AgentSlug: "a", CostUSD: 2, SpentAt: now},
+			{AgentSlug: "b", TaskID: "t1", CostUSD: 4, SpentAt: now},
+			{AgentSlug: "a", TaskID: "t1", CostUSD: 8, SpentAt: now.Add(-48 * time.Hour)},
+		}
+		for _, e := range entries {
+			require.NoError(t, store.RecordSpend(ctx, e))
+		}
+		since := now.Add(-time.Hour)
+
+		global, err := store.SumSpend(ctx, storage.BudgetScopeGlobal, "", since)
+		require.NoError(t, err)
+		assert.InDelta(t, 7.0, global, 1e-9)
+
+		agentA, err := store.SumSpend(ctx, storage.BudgetScopeAgent, "a", since)
+		require.NoError(t, err)
+		assert.InDelta(t, 3.0, agentA, 1e-9)
+
+		task, err := store.SumSpend(ctx, storage.BudgetScopeTask, "t1", since)
+		require.NoError(t, err)
+		assert.InDelta(t, 5.0, task, 1e-9)
+
+		_, err = store.SumSpend(ctx, storage.BudgetScope("bogus"), "", since)
+		assert.Error(t, err)
+	})
+}
+
+func TestBudgetPeriod_WindowStart(t *testing.T) {
+	// Thursday afternoon.
+	now := time.Date(2026, 5, 14, 15, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 5, 14, 0, 0, 0, 0, time.UTC), storage.BudgetPeriodDaily.WindowStart(now))
+	assert.Equal(t, time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC), storage.BudgetPeriodWeekly.WindowStart(now),
+		"weeks start on Monday")
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), storage.BudgetPeriodMonthly.WindowStart(now))
+
+	sunday := time.Date(2026, 5, 17, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC), storage.BudgetPeriodWeekly.WindowStart(sunday),
+		"Sunday belongs to the week that started the previous Monday")
+
+	assert.True(t, storage.BudgetPeriod("yearly").WindowStart(now).IsZero())
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/shaharia-lab/agento/internal/config"
@@ -11,12 +12,17 @@ import (
 
 // SQLiteIntegrationStore implements IntegrationStore backed by a SQLite database.
 type SQLiteIntegrationStore struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher CredentialCipher
 }
 
 // NewSQLiteIntegrationStore returns a new SQLiteIntegrationStore.
-func NewSQLiteIntegrationStore(db *sql.DB) *SQLiteIntegrationStore {
-	return &SQLiteIntegrationStore{db: db}
+//
+// cipher is optional. When set, credentials and OAuth tokens are sealed
+// before they are written and opened when read. Without it they are stored
+// as plaintext JSON.
+func NewSQLiteIntegrationStore(db *sql.DB, cipher CredentialCipher) *SQLiteIntegrationStore {
+	return &SQLiteIntegrationStore{db: db, cipher: cipher}
 }
 
 // List returns all integration configs.
@@ -32,7 +38,7 @@ func (s *SQLiteIntegrationStore) List(ctx context.Context) ([]*config.Integratio
 
 	integrations := make([]*config.IntegrationConfig, 0)
 	for rows.Next() {
-		cfg, err := scanIntegration(rows)
+		cfg, err := s.scanIntegration(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -47,30 +53,13 @@ func (s *SQLiteIntegrationStore) Get(ctx context.Context, id string) (*config.In
 		SELECT id, name, type, enabled, credentials, auth, services, created_at, updated_at
 		FROM integrations WHERE id = ?`, id)
 
-	cfg := &config.IntegrationConfig{}
-	var credJSON, servJSON string
-	var authJSON sql.NullString
-	var enabled int
-	err := row.Scan(
-		&cfg.ID, &cfg.Name, &cfg.Type, &enabled,
-		&credJSON, &authJSON, &servJSON,
-		&cfg.CreatedAt, &cfg.UpdatedAt,
-	)
-	if err == sql.ErrNoRows {
+	cfg, err := s.scanIntegration(row)
+	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("getting integration %q: %w", id, err)
 	}
-
-	cfg.Enabled = enabled != 0
-	cfg.Credentials = json.RawMessage(credJSON)
-	if authJSON.Valid && authJSON.String != "" {
-		cfg.Auth = json.RawMessage(authJSON.String)
-	}
-	if err := json.Unmarshal([]byte(servJSON), &cfg.Services); err != nil {
-		return nil, fmt.Errorf("parsing services for integration %q: %w", id, err)
-	}
 	return cfg, nil
 }
 
@@ -80,9 +69,16 @@ func (s *SQLiteIntegrationStore) Save(ctx context.Context, cfg *config.Integrati
 		return fmt.Errorf("integration id is required")
 	}
 
+	credentials, err := s.seal(cfg.Credentials)
+	if err != nil {
+		return fmt.Errorf("encrypting credentials for integration %q: %w", cfg.ID, err)
+	}
 	var authJSON *string
 	if cfg.IsAuthenticated() {
-		authStr := string(cfg.Auth)
+		authStr, sealErr := s.seal(cfg.Auth)
+		if sealErr != nil {
+			return fmt.Errorf("encrypting auth for integration %q: %w", cfg.ID, sealErr)
+		}
 		authJSON = &authStr
 	}
 
@@ -96,7 +92,16 @@ func (s *SQLiteIntegrationStore) Save(ctx context.Context, cfg *config.Integrati
 		enabled = 1
 	}
 
-	_, err = s.db.ExecContext(ctx, `
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := s.checkKey(ctx, tx); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
 		INSERT INTO integrations (id, name, type, enabled, credentials, auth, services, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
@@ -108,12 +113,52 @@ func (s *SQLiteIntegrationStore) Save(ctx context.Context, cfg *config.Integrati
 			services = excluded.services,
 			updated_at = excluded.updated_at`,
 		cfg.ID, cfg.Name, cfg.Type, enabled,
-		string(cfg.Credentials), authJSON, string(servJSON),
+		credentials, authJSON, string(servJSON),
 		cfg.CreatedAt, cfg.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("saving integration %q: %w", cfg.ID, err)
 	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("saving integration %q: %w", cfg.ID, err)
+	}
+	return nil
+}
+
+// seal encrypts a credential value when a cipher is set.
+func (s *SQLiteIntegrationStore) seal(value json.RawMessage) (string, error) {
+	if s.cipher == nil {
+		return string(value), nil
+	}
+	return s.cipher.Seal(value)
+}
+
+// open decrypts a stored credential value when a cipher is set.
+func (s *SQLiteIntegrationStore) open(stored string) (json.RawMessage, error) {
+	if s.cipher == nil {
+		return json.RawMessage(stored), nil
+	}
+	plain, err := s.cipher.Open(stored)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(plain), nil
+}
+
+// checkKey refuses a write sealed with a key other than the current one,
+// which happens when `agento secrets rotate` ran while this process was up.
+func (s *SQLiteIntegrationStore) checkKey(ctx context.Context, tx *sql.Tx) error {
+	if s.cipher == nil {
+		return nil
+	}
+	var keyID string
+	err := tx.QueryRowContext(ctx, `SELECT key_id FROM secret_key WHERE id = 1`).Scan(&keyID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("checking secret key: %w", err)
+	}
+	if keyID != s.cipher.KeyID() {
+		return fmt.Errorf("the secret key was rotated since agento started; restart agento web to save integrations")
+	}
 	return nil
 }
 
@@ -133,27 +178,34 @@ func (s *SQLiteIntegrationStore) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func scanIntegration(rows *sql.Rows) (*config.IntegrationConfig, error) {
+func (s *SQLiteIntegrationStore) scanIntegration(row rowScanner) (*config.IntegrationConfig, error) {
 	cfg := &config.IntegrationConfig{}
 	var credJSON, servJSON string
 	var authJSON sql.NullString
 	var enabled int
-	err := rows.Scan(
+	err := row.Scan(
 		&cfg.ID, &cfg.Name, &cfg.Type, &enabled,
 		&credJSON, &authJSON, &servJSON,
 		&cfg.CreatedAt, &cfg.UpdatedAt,
 	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
 	if err != nil {
 		return nil, fmt.Errorf("scanning integration: %w", err)
 	}
 
 	cfg.Enabled = enabled != 0
-	cfg.Credentials = json.RawMessage(credJSON)
+	if cfg.Credentials, err = s.open(credJSON); err != nil {
+		return nil, fmt.Errorf("decrypting credentials for integration %q: %w", cfg.ID, err)
+	}
 	if authJSON.Valid && authJSON.String != "" {
-		cfg.Auth = json.RawMessage(authJSON.String)
+		if cfg.Auth, err = s.open(authJSON.String); err != nil {
+			return nil, fmt.Errorf("decrypting auth for integration %q: %w", cfg.ID, err)
+		}
 	}
 	if err := json.Unmarshal([]byte(servJSON), &cfg.Services); err != nil {
-		return nil, fmt.Errorf("parsing services: %w", err)
+		return nil, fmt.Errorf("parsing services for integration %q: %w", cfg.ID, err)
 	}
 	return cfg, nil
 }
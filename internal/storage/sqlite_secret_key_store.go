@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SQLiteSecretKeyStore implements SecretKeyStore backed by a SQLite database.
+type SQLiteSecretKeyStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteSecretKeyStore returns a new SQLiteSecretKeyStore.
+func NewSQLiteSecretKeyStore(db *sql.DB) *SQLiteSecretKeyStore {
+	return &SQLiteSecretKeyStore{db: db}
+}
+
+// GetSecretKey returns the current key record, or nil when credentials have
+// never been encrypted.
+func (s *SQLiteSecretKeyStore) GetSecretKey(ctx context.Context) (*SecretKey, error) {
+	var k SecretKey
+	err := s.db.QueryRowContext(ctx,
+		`SELECT key_id, source, salt, check_value, created_at FROM secret_key WHERE id = 1`,
+	).Scan(&k.KeyID, &k.Source, &k.Salt, &k.Check, &k.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting secret key record: %w", err)
+	}
+	return &k, nil
+}
+
+// RewriteCredentials passes every stored integration credential and auth
+// value through rewrite in one transaction, and replaces the key record with
+// key when it is non-nil. It returns how many values changed.
+func (s *SQLiteSecretKeyStore) RewriteCredentials(
+	ctx context.Context, key *SecretKey, rewrite func(stored string) (string, error),
+) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, credentials, auth FROM integrations`)
+	if err != nil {
+		return 0, fmt.Errorf("listing integration credentials: %w", err)
+	}
+	type stored struct {
+		id, credentials string
+		auth            sql.NullString
+	}
+	var all []stored
+	for rows.Next() {
+		var v stored
+		if err := rows.Scan(&v.id, &v.credentials, &v.auth); err != nil {
+			rows.Close() //nolint:errcheck,gosec
+			return 0, fmt.Errorf("scanning integration credentials: %w", err)
+		}
+		all = append(all, v)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, fmt.Errorf("listing integration credentials: %w", err)
+	}
+
+	changed := 0
+	for _, v := range all {
+		n, err := rewriteIntegrationCredentials(ctx, tx, v.id, v.credentials, v.auth, rewrite)
+		if err != nil {
+			return 0, err
+		}
+		changed += n
+	}
+
+	if key != nil {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO secret_key (id, key_id, source, salt, check_value, created_at) VALUES (1, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET key_id = excluded.key_id, source = excluded.source,
+				salt = excluded.salt, check_value = excluded.check_value, created_at = excluded.created_at`,
+			key.KeyID, key.Source, key.Salt, key.Check, key.CreatedAt.UTC(),
+		); err != nil {
+			return 0, fmt.Errorf("saving secret key record: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing credentials: %w", err)
+	}
+	return changed, nil
+}
+
+// rewriteIntegrationCredentials rewrites one integration's credentials and
+// auth, and reports how many of the two changed.
+func rewriteIntegrationCredentials(
+	ctx context.Context, tx *sql.Tx, id, credentials string, auth sql.NullString,
+	rewrite func(string) (string, error),
+) (int, error) {
+	newCredentials, err := rewrite(credentials)
+	if err != nil {
+		return 0, fmt.Errorf("rewriting credentials of integration %q: %w", id, err)
+	}
+	newAuth := auth
+	if auth.Valid && auth.String != "" {
+		if newAuth.String, err = rewrite(auth.String); err != nil {
+			return 0, fmt.Errorf("rewriting auth of integration %q: %w", id, err)
+		}
+	}
+
+	changed := 0
+	if newCredentials != credentials {
+		changed++
+	}
+	if newAuth.String != auth.String {
+		changed++
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE integrations SET credentials = ?, auth = ? WHERE id = ?`,
+		newCredentials, newAuth, id); err != nil {
+		return 0, fmt.Errorf("updating credentials of integration %q: %w", id, err)
+	}
+	return changed, nil
+}
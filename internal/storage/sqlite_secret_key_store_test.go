@@ -0,0 +1,63 @@
+package storage_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func TestSQLiteSecretKeyStore(t *testing.T) {
+	db, _, err := storage.NewSQLiteDB(":memory:", slog.Default())
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := storage.NewSQLiteSecretKeyStore(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	rec, err := store.GetSecretKey(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, rec, "no record means credentials were never encrypted")
+
+	require.NoError(t, storage.NewSQLiteIntegrationStore(db, nil).Save(ctx, &config.IntegrationConfig{
+		ID: "slack-1", Name: "Slack", Type: "slack",
+		Credentials: json.RawMessage(`{"token":"xoxb"}`),
+		CreatedAt:   now, UpdatedAt: now,
+	}))
+
+	upper := func(stored string) (string, error) { return strings.ToUpper(stored), nil }
+	n, err := store.RewriteCredentials(ctx, &storage.SecretKey{
+		KeyID: "k1", Source: "keyring", Salt: "salt", Check: "check", CreatedAt: now,
+	}, upper)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "an integration without auth has only its credentials rewritten")
+
+	got, err := storage.NewSQLiteIntegrationStore(db, nil).Get(ctx, "slack-1")
+	require.NoError(t, err)
+	assert.Equal(t, `{"TOKEN":"XOXB"}`, string(got.Credentials))
+	assert.Nil(t, got.Auth)
+
+	rec, err = store.GetSecretKey(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+	assert.Equal(t, "k1", rec.KeyID)
+	assert.True(t, rec.CreatedAt.Equal(now))
+
+	// Without a key the record is left as it is, and unchanged values are
+	// not counted.
+	n, err = store.RewriteCredentials(ctx, nil, upper)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	rec, err = store.GetSecretKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "k1", rec.KeyID)
+}
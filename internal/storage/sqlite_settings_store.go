@@ -25,7 +25,8 @@ func (s *SQLiteSettingsStore) Load() (config.UserSettings, error) {
 	var us config.UserSettings
 	var darkMode, onboarding int
 	var hiddenProjects string
-	var claudeConfigDirs string
+	var claudeConfigDirs, importedAgents string
+	var backupIncludeCredentials, transcriptArchiveDisabled int
 
 	ctx := context.Background()
 	err := s.db.QueryRowContext(ctx, `
@@ -33,13 +34,21 @@ func (s *SQLiteSettingsStore) Load() (config.UserSettings, error) {
 		       appearance_dark_mode, appearance_font_size, appearance_font_family,
 		       notification_settings, event_bus_worker_pool_size, public_url,
 		       hidden_projects, idle_gap_threshold_minutes,
-		       claude_config_dir, claude_config_dirs
+		       claude_config_dir, claude_config_dirs,
+		       backup_interval_hours, backup_keep, backup_dir, backup_include_credentials,
+		       transcript_archive_disabled, transcript_archive_retention_days, transcript_archive_max_mb,
+		       imported_agents,
+		       usage_limit_five_hour_usd, usage_limit_weekly_usd, usage_warn_percent
 		FROM user_settings WHERE id = 1`).Scan(
 		&us.DefaultWorkingDir, &us.DefaultModel, &onboarding,
 		&darkMode, &us.AppearanceFontSize, &us.AppearanceFontFamily,
 		&us.NotificationSettings, &us.EventBusWorkerPoolSize,
 		&us.PublicURL, &hiddenProjects, &us.IdleGapThresholdMinutes,
 		&us.ClaudeConfigDir, &claudeConfigDirs,
+		&us.BackupIntervalHours, &us.BackupKeep, &us.BackupDir, &backupIncludeCredentials,
+		&transcriptArchiveDisabled, &us.TranscriptArchiveRetentionDays, &us.TranscriptArchiveMaxMB,
+		&importedAgents,
+		&us.UsageLimitFiveHourUSD, &us.UsageLimitWeeklyUSD, &us.UsageWarnPercent,
 	)
 	if err == sql.ErrNoRows {
 		// Return zero-value settings; SettingsManager fills defaults.
@@ -52,6 +61,9 @@ func (s *SQLiteSettingsStore) Load() (config.UserSettings, error) {
 	us.AppearanceDarkMode = darkMode != 0
 	us.HiddenProjects = decodeStringList(hiddenProjects)
 	us.ClaudeConfigDirs = decodeStringList(claudeConfigDirs)
+	us.BackupIncludeCredentials = backupIncludeCredentials != 0
+	us.TranscriptArchiveDisabled = transcriptArchiveDisabled != 0
+	us.ImportedAgents = decodeStringList(importedAgents)
 	return us, nil
 }
 
@@ -86,15 +98,6 @@ func encodeStringList(values []string) string {
 
 // Save persists the user settings (single row, id=1).
 func (s *SQLiteSettingsStore) Save(settings config.UserSettings) error {
-	onboarding := 0
-	if settings.OnboardingComplete {
-		onboarding = 1
-	}
-	darkMode := 0
-	if settings.AppearanceDarkMode {
-		darkMode = 1
-	}
-
 	notificationSettings := settings.NotificationSettings
 	if notificationSettings == "" {
 		notificationSettings = "{}"
@@ -107,8 +110,12 @@ func (s *SQLiteSettingsStore) Save(settings config.UserSettings) error {
 			 appearance_dark_mode, appearance_font_size, appearance_font_family,
 			 notification_settings, event_bus_worker_pool_size, public_url,
 			 hidden_projects, idle_gap_threshold_minutes,
-			 claude_config_dir, claude_config_dirs)
-		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			 claude_config_dir, claude_config_dirs,
+			 backup_interval_hours, backup_keep, backup_dir, backup_include_credentials,
+			 transcript_archive_disabled, transcript_archive_retention_days, transcript_archive_max_mb,
+			 imported_agents,
+			 usage_limit_five_hour_usd, usage_limit_weekly_usd, usage_warn_percent)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			default_working_dir = excluded.default_working_dir,
 			default_model = excluded.default_model,
@@ -122,16 +129,40 @@ func (s *SQLiteSettingsStore) Save(settings config.UserSettings) error {
 			hidden_projects = excluded.hidden_projects,
 			idle_gap_threshold_minutes = excluded.idle_gap_threshold_minutes,
 			claude_config_dir = excluded.claude_config_dir,
-			claude_config_dirs = excluded.claude_config_dirs`,
-		settings.DefaultWorkingDir, settings.DefaultModel, onboarding,
-		darkMode, settings.AppearanceFontSize, settings.AppearanceFontFamily,
+			claude_config_dirs = excluded.claude_config_dirs,
+			backup_interval_hours = excluded.backup_interval_hours,
+			backup_keep = excluded.backup_keep,
+			backup_dir = excluded.backup_dir,
+			backup_include_credentials = excluded.backup_include_credentials,
+			transcript_archive_disabled = excluded.transcript_archive_disabled,
+			transcript_archive_retention_days = excluded.transcript_archive_retention_days,
+			transcript_archive_max_mb = excluded.transcript_archive_max_mb,
+			imported_agents = excluded.imported_agents,
+			usage_limit_five_hour_usd = excluded.usage_limit_five_hour_usd,
+			usage_limit_weekly_usd = excluded.usage_limit_weekly_usd,
+			usage_warn_percent = excluded.usage_warn_percent`,
+		settings.DefaultWorkingDir, settings.DefaultModel, boolToInt(settings.OnboardingComplete),
+		boolToInt(settings.AppearanceDarkMode), settings.AppearanceFontSize, settings.AppearanceFontFamily,
 		notificationSettings, settings.EventBusWorkerPoolSize,
 		settings.PublicURL, encodeStringList(settings.HiddenProjects),
 		settings.IdleGapThresholdMinutes,
 		settings.ClaudeConfigDir, encodeStringList(settings.ClaudeConfigDirs),
+		settings.BackupIntervalHours, settings.BackupKeep, settings.BackupDir,
+		boolToInt(settings.BackupIncludeCredentials),
+		boolToInt(settings.TranscriptArchiveDisabled), settings.TranscriptArchiveRetentionDays,
+		settings.TranscriptArchiveMaxMB, encodeStringList(settings.ImportedAgents),
+		settings.UsageLimitFiveHourUSD, settings.UsageLimitWeeklyUSD, settings.UsageWarnPercent,
 	)
 	if err != nil {
 		return fmt.Errorf("saving settings: %w", err)
 	}
 	return nil
 }
+
+// boolToInt stores a bool in an INTEGER column.
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+)
+
+// LatestSchemaVersion returns the schema version this build migrates a
+// database to. A database at a higher version was written by a newer agento.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// SnapshotSQLiteDB writes a consistent copy of db to dest with VACUUM INTO,
+// while db stays in use. dest must not exist. Without includeCredentials the
+// copy's integration credentials and OAuth tokens are cleared, along with
+// the record of the key that sealed them. It returns the copy's schema
+// version.
+func SnapshotSQLiteDB(ctx context.Context, db *sql.DB, dest string, includeCredentials bool) (int, error) {
+	if _, err := db.ExecContext(ctx, `VACUUM INTO ?`, dest); err != nil {
+		return 0, fmt.Errorf("snapshotting database: %w", err)
+	}
+
+	snap, err := openSQLiteFile(dest)
+	if err != nil {
+		return 0, err
+	}
+	defer snap.Close() //nolint:errcheck
+
+	if !includeCredentials {
+		if _, err := snap.ExecContext(ctx, `
+			UPDATE integrations SET credentials = '{}', auth = NULL;
+			DELETE FROM secret_key;`); err != nil {
+			return 0, fmt.Errorf("clearing credentials from snapshot: %w", err)
+		}
+	}
+	return currentVersion(ctx, snap)
+}
+
+// CheckSQLiteDB checks the integrity of the database file at path without
+// migrating it, and returns its schema version.
+func CheckSQLiteDB(ctx context.Context, path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, fmt.Errorf("checking database: %w", err)
+	}
+	db, err := openSQLiteFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close() //nolint:errcheck
+
+	var result string
+	if err := db.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&result); err != nil {
+		return 0, fmt.Errorf("checking database integrity: %w", err)
+	}
+	if result != "ok" {
+		return 0, fmt.Errorf("database is damaged: %s", result)
+	}
+	return currentVersion(ctx, db)
+}
+
+// openSQLiteFile opens a database file as it is: unlike openSQLiteDB it sets
+// no pragmas, so a snapshot keeps the rollback journal VACUUM INTO gave it
+// and stays a single self-contained file.
+func openSQLiteFile(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -27,7 +28,8 @@ func (s *SQLiteTaskStore) ListTasks(ctx context.Context) ([]*ScheduledTask, erro
 		SELECT id, name, description, prompt, agent_slug, working_directory, model,
 		       settings_profile_id, timeout_minutes, schedule_type, schedule_config,
 		       stop_after_count, stop_after_time, save_output, status, run_count, last_run_at,
-		       last_run_status, next_run_at, created_at, updated_at
+		       last_run_status, next_run_at, created_at, updated_at, pipeline_steps,
+		       retry_policy
 		FROM scheduled_tasks
 		ORDER BY created_at DESC`)
 	if err != nil {
@@ -52,11 +54,12 @@ func (s *SQLiteTaskStore) GetTask(ctx context.Context, id string) (*ScheduledTas
 		SELECT id, name, description, prompt, agent_slug, working_directory, model,
 		       settings_profile_id, timeout_minutes, schedule_type, schedule_config,
 		       stop_after_count, stop_after_time, save_output, status, run_count, last_run_at,
-		       last_run_status, next_run_at, created_at, updated_at
+		       last_run_status, next_run_at, created_at, updated_at, pipeline_steps,
+		       retry_policy
 		FROM scheduled_tasks WHERE id = ?`, id)
 
 	t := &ScheduledTask{}
-	var configJSON string
+	var configJSON, stepsJSON, retryJSON string
 	var stopAfterTime sql.NullTime
 	var lastRunAt sql.NullTime
 	var nextRunAt sql.NullTime
@@ -66,7 +69,7 @@ func (s *SQLiteTaskStore) GetTask(ctx context.Context, id string) (*ScheduledTas
 		&t.WorkingDirectory, &t.Model, &t.SettingsProfileID, &t.TimeoutMinutes,
 		&t.ScheduleType, &configJSON, &t.StopAfterCount, &stopAfterTime, &t.SaveOutput,
 		&t.Status, &t.RunCount, &lastRunAt, &t.LastRunStatus, &nextRunAt,
-		&t.CreatedAt, &t.UpdatedAt,
+		&t.CreatedAt, &t.UpdatedAt, &stepsJSON, &retryJSON,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -78,6 +81,12 @@ func (s *SQLiteTaskStore) GetTask(ctx context.Context, id string) (*ScheduledTas
 	if err := json.Unmarshal([]byte(configJSON), &t.ScheduleConfig); err != nil {
 		return nil, fmt.Errorf("unmarshaling schedule config for task %q: %w", id, err)
 	}
+	if err := json.Unmarshal([]byte(stepsJSON), &t.Steps); err != nil {
+		return nil, fmt.Errorf("unmarshaling pipeline steps for task %q: %w", id, err)
+	}
+	if err := json.Unmarshal([]byte(retryJSON), &t.Retry); err != nil {
+		return nil, fmt.Errorf("unmarshaling retry policy for task %q: %w", id, err)
+	}
 	if stopAfterTime.Valid {
 		t.StopAfterTime = &stopAfterTime.Time
 	}
@@ -103,19 +112,27 @@ func (s *SQLiteTaskStore) CreateTask(ctx context.Context, task *ScheduledTask) e
 	if err != nil {
 		return fmt.Errorf("marshaling schedule config: %w", err)
 	}
+	stepsJSON, err := task.MarshalSteps()
+	if err != nil {
+		return fmt.Errorf("marshaling pipeline steps: %w", err)
+	}
+	retryJSON, err := task.MarshalRetryPolicy()
+	if err != nil {
+		return fmt.Errorf("marshaling retry policy: %w", err)
+	}
 
 	_, err = s.db.ExecContext(ctx, `
 		INSERT INTO scheduled_tasks
 			(id, name, description, prompt, agent_slug, working_directory, model,
 			 settings_profile_id, timeout_minutes, schedule_type, schedule_config,
 			 stop_after_count, stop_after_time, save_output, status, run_count, last_run_at,
-			 last_run_status, next_run_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			 last_run_status, next_run_at, created_at, updated_at, pipeline_steps, retry_policy)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		task.ID, task.Name, task.Description, task.Prompt, task.AgentSlug,
 		task.WorkingDirectory, task.Model, task.SettingsProfileID, task.TimeoutMinutes,
 		task.ScheduleType, configJSON, task.StopAfterCount, task.StopAfterTime, task.SaveOutput,
 		task.Status, task.RunCount, task.LastRunAt, task.LastRunStatus, task.NextRunAt,
-		task.CreatedAt, task.UpdatedAt,
+		task.CreatedAt, task.UpdatedAt, stepsJSON, retryJSON,
 	)
 	if err != nil {
 		return fmt.Errorf("creating task: %w", err)
@@ -131,6 +148,14 @@ func (s *SQLiteTaskStore) UpdateTask(ctx context.Context, task *ScheduledTask) e
 	if err != nil {
 		return fmt.Errorf("marshaling schedule config: %w", err)
 	}
+	stepsJSON, err := task.MarshalSteps()
+	if err != nil {
+		return fmt.Errorf("marshaling pipeline steps: %w", err)
+	}
+	retryJSON, err := task.MarshalRetryPolicy()
+	if err != nil {
+		return fmt.Errorf("marshaling retry policy: %w", err)
+	}
 
 	res, err := s.db.ExecContext(ctx, `
 		UPDATE scheduled_tasks SET
@@ -139,14 +164,14 @@ func (s *SQLiteTaskStore) UpdateTask(ctx context.Context, task *ScheduledTask) e
 			timeout_minutes = ?, schedule_type = ?, schedule_config = ?,
 			stop_after_count = ?, stop_after_time = ?, save_output = ?, status = ?,
 			run_count = ?, last_run_at = ?, last_run_status = ?,
-			next_run_at = ?, updated_at = ?
+			next_run_at = ?, updated_at = ?, pipeline_steps = ?, retry_policy = ?
 		WHERE id = ?`,
 		task.Name, task.Description, task.Prompt, task.AgentSlug,
 		task.WorkingDirectory, task.Model, task.SettingsProfileID,
 		task.TimeoutMinutes, task.ScheduleType, configJSON,
 		task.StopAfterCount, task.StopAfterTime, task.SaveOutput, task.Status,
 		task.RunCount, task.LastRunAt, task.LastRunStatus,
-		task.NextRunAt, task.UpdatedAt, task.ID,
+		task.NextRunAt, task.UpdatedAt, stepsJSON, retryJSON, task.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("updating task %q: %w", task.ID, err)
@@ -183,7 +208,8 @@ func (s *SQLiteTaskStore) ListJobHistory(ctx context.Context, taskID string, lim
 		SELECT id, task_id, task_name, agent_slug, status, started_at, finished_at,
 		       duration_ms, chat_session_id, model, prompt_preview, error_message,
 		       total_input_tokens, total_output_tokens,
-		       total_cache_creation_tokens, total_cache_read_tokens, response_text
+		       total_cache_creation_tokens, total_cache_read_tokens, response_text,
+		       attempt, retry_of
 		FROM job_history
 		WHERE task_id = ?
 		ORDER BY started_at DESC
@@ -201,7 +227,8 @@ func (s *SQLiteTaskStore) ListAllJobHistory(ctx context.Context, limit, offset i
 		SELECT id, task_id, task_name, agent_slug, status, started_at, finished_at,
 		       duration_ms, chat_session_id, model, prompt_preview, error_message,
 		       total_input_tokens, total_output_tokens,
-		       total_cache_creation_tokens, total_cache_read_tokens, response_text
+		       total_cache_creation_tokens, total_cache_read_tokens, response_text,
+		       attempt, retry_of
 		FROM job_history
 		ORDER BY started_at DESC
 		LIMIT ? OFFSET ?`, limit, offset)
@@ -218,7 +245,8 @@ func (s *SQLiteTaskStore) GetJobHistory(ctx context.Context, id string) (*JobHis
 		SELECT id, task_id, task_name, agent_slug, status, started_at, finished_at,
 		       duration_ms, chat_session_id, model, prompt_preview, error_message,
 		       total_input_tokens, total_output_tokens,
-		       total_cache_creation_tokens, total_cache_read_tokens, response_text
+		       total_cache_creation_tokens, total_cache_read_tokens, response_text,
+		       attempt, retry_of
 		FROM job_history WHERE id = ?`, id)
 
 	jh, err := scanJobHistoryRow(row)
@@ -228,27 +256,88 @@ func (s *SQLiteTaskStore) GetJobHistory(ctx context.Context, id string) (*JobHis
 	if err != nil {
 		return nil, fmt.Errorf("getting job history %q: %w", id, err)
 	}
+
+	steps, err := s.listJobSteps(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) > 0 {
+		jh.Steps = steps
+	}
+
+	approvals, err := NewSQLiteApprovalStore(s.db).ListApprovals(ctx, ApprovalFilter{JobID: id})
+	if err != nil {
+		return nil, err
+	}
+	if len(approvals) > 0 {
+		// Oldest first, in the order the run asked.
+		slices.Reverse(approvals)
+		jh.Approvals = approvals
+	}
 	return jh, nil
 }
 
+// listJobSteps returns the pipeline step records of a job in step order.
+func (s *SQLiteTaskStore) listJobSteps(ctx context.Context, jobID string) ([]*JobStep, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, job_id, step_id, position, agent_slug, status, started_at, finished_at,
+		       duration_ms, chat_session_id, prompt_preview, error_message,
+		       total_input_tokens, total_output_tokens,
+		       total_cache_creation_tokens, total_cache_read_tokens, response_text
+		FROM job_steps
+		WHERE job_id = ?
+		ORDER BY position`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("listing steps for job %q: %w", jobID, err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	steps := make([]*JobStep, 0)
+	for rows.Next() {
+		st := &JobStep{}
+		var finishedAt sql.NullTime
+		err := rows.Scan(
+			&st.ID, &st.JobID, &st.StepID, &st.Position, &st.AgentSlug, &st.Status,
+			&st.StartedAt, &finishedAt, &st.DurationMS, &st.ChatSessionID,
+			&st.PromptPreview, &st.ErrorMessage,
+			&st.TotalInputTokens, &st.TotalOutputTokens,
+			&st.TotalCacheCreationTokens, &st.TotalCacheReadTokens,
+			&st.ResponseText,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning job step: %w", err)
+		}
+		if finishedAt.Valid {
+			st.FinishedAt = &finishedAt.Time
+		}
+		steps = append(steps, st)
+	}
+	return steps, rows.Err()
+}
+
 // CreateJobHistory inserts a new job history record.
 func (s *SQLiteTaskStore) CreateJobHistory(ctx context.Context, jh *JobHistory) error {
 	if jh.ID == "" {
 		jh.ID = uuid.New().String()
 	}
+	if jh.Attempt == 0 {
+		jh.Attempt = 1
+	}
 
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO job_history
 			(id, task_id, task_name, agent_slug, status, started_at, finished_at,
 			 duration_ms, chat_session_id, model, prompt_preview, error_message,
 			 total_input_tokens, total_output_tokens,
-			 total_cache_creation_tokens, total_cache_read_tokens, response_text)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			 total_cache_creation_tokens, total_cache_read_tokens, response_text,
+			 attempt, retry_of)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		jh.ID, jh.TaskID, jh.TaskName, jh.AgentSlug, jh.Status,
 		jh.StartedAt, jh.FinishedAt, jh.DurationMS, jh.ChatSessionID,
 		jh.Model, jh.PromptPreview, jh.ErrorMessage,
 		jh.TotalInputTokens, jh.TotalOutputTokens,
 		jh.TotalCacheCreationTokens, jh.TotalCacheReadTokens, jh.ResponseText,
+		jh.Attempt, jh.RetryOf,
 	)
 	if err != nil {
 		return fmt.Errorf("creating job history: %w", err)
@@ -312,10 +401,55 @@ func (s *SQLiteTaskStore) UpdateJobHistory(ctx context.Context, jh *JobHistory)
 	return nil
 }
 
+// CreateJobStep inserts a new pipeline step record.
+func (s *SQLiteTaskStore) CreateJobStep(ctx context.Context, step *JobStep) error {
+	if step.ID == "" {
+		step.ID = uuid.New().String()
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO job_steps
+			(id, job_id, step_id, position, agent_slug, status, started_at, finished_at,
+			 duration_ms, chat_session_id, prompt_preview, error_message,
+			 total_input_tokens, total_output_tokens,
+			 total_cache_creation_tokens, total_cache_read_tokens, response_text)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		step.ID, step.JobID, step.StepID, step.Position, step.AgentSlug, step.Status,
+		step.StartedAt, step.FinishedAt, step.DurationMS, step.ChatSessionID,
+		step.PromptPreview, step.ErrorMessage,
+		step.TotalInputTokens, step.TotalOutputTokens,
+		step.TotalCacheCreationTokens, step.TotalCacheReadTokens, step.ResponseText,
+	)
+	if err != nil {
+		return fmt.Errorf("creating job step: %w", err)
+	}
+	return nil
+}
+
+// UpdateJobStep updates an existing pipeline step record.
+func (s *SQLiteTaskStore) UpdateJobStep(ctx context.Context, step *JobStep) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE job_steps SET
+			status = ?, finished_at = ?, duration_ms = ?, chat_session_id = ?,
+			prompt_preview = ?, error_message = ?, total_input_tokens = ?, total_output_tokens = ?,
+			total_cache_creation_tokens = ?, total_cache_read_tokens = ?,
+			response_text = ?
+		WHERE id = ?`,
+		step.Status, step.FinishedAt, step.DurationMS, step.ChatSessionID,
+		step.PromptPreview, step.ErrorMessage, step.TotalInputTokens, step.TotalOutputTokens,
+		step.TotalCacheCreationTokens, step.TotalCacheReadTokens,
+		step.ResponseText, step.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating job step %q: %w", step.ID, err)
+	}
+	return nil
+}
+
 // scanScheduledTask scans a scheduled task from a row set.
 func scanScheduledTask(rows *sql.Rows) (*ScheduledTask, error) {
 	t := &ScheduledTask{}
-	var configJSON string
+	var configJSON, stepsJSON, retryJSON string
 	var stopAfterTime sql.NullTime
 	var lastRunAt sql.NullTime
 	var nextRunAt sql.NullTime
@@ -325,7 +459,7 @@ func scanScheduledTask(rows *sql.Rows) (*ScheduledTask, error) {
 		&t.WorkingDirectory, &t.Model, &t.SettingsProfileID, &t.TimeoutMinutes,
 		&t.ScheduleType, &configJSON, &t.StopAfterCount, &stopAfterTime, &t.SaveOutput,
 		&t.Status, &t.RunCount, &lastRunAt, &t.LastRunStatus, &nextRunAt,
-		&t.CreatedAt, &t.UpdatedAt,
+		&t.CreatedAt, &t.UpdatedAt, &stepsJSON, &retryJSON,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("scanning task: %w", err)
@@ -334,6 +468,12 @@ func scanScheduledTask(rows *sql.Rows) (*ScheduledTask, error) {
 	if err := json.Unmarshal([]byte(configJSON), &t.ScheduleConfig); err != nil {
 		return nil, fmt.Errorf("unmarshaling schedule config: %w", err)
 	}
+	if err := json.Unmarshal([]byte(stepsJSON), &t.Steps); err != nil {
+		return nil, fmt.Errorf("unmarshaling pipeline steps: %w", err)
+	}
+	if err := json.Unmarshal([]byte(retryJSON), &t.Retry); err != nil {
+		return nil, fmt.Errorf("unmarshaling retry policy: %w", err)
+	}
 	if stopAfterTime.Valid {
 		t.StopAfterTime = &stopAfterTime.Time
 	}
@@ -358,7 +498,7 @@ func scanJobHistoryRows(rows *sql.Rows) ([]*JobHistory, error) {
 			&jh.Model, &jh.PromptPreview, &jh.ErrorMessage,
 			&jh.TotalInputTokens, &jh.TotalOutputTokens,
 			&jh.TotalCacheCreationTokens, &jh.TotalCacheReadTokens,
-			&jh.ResponseText,
+			&jh.ResponseText, &jh.Attempt, &jh.RetryOf,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning job history: %w", err)
@@ -381,7 +521,7 @@ func scanJobHistoryRow(row *sql.Row) (*JobHistory, error) {
 		&jh.Model, &jh.PromptPreview, &jh.ErrorMessage,
 		&jh.TotalInputTokens, &jh.TotalOutputTokens,
 		&jh.TotalCacheCreationTokens, &jh.TotalCacheReadTokens,
-		&jh.ResponseText,
+		&jh.ResponseText, &jh.Attempt, &jh.RetryOf,
 	)
 	if err != nil {
 		return nil, err
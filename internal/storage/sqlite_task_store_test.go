@@ -0,0 +1,96 @@
+package storage_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func TestSQLiteTaskStore_Pipeline(t *testing.T) {
+	db, _, err := storage.NewSQLiteDB(":memory:", slog.Default())
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := storage.NewSQLiteTaskStore(db)
+	ctx := context.Background()
+
+	task := &storage.ScheduledTask{
+		Name:         "triage",
+		ScheduleType: storage.ScheduleRunImmediately,
+		Status:       storage.TaskStatusActive,
+		Steps: []storage.PipelineStep{
+			{ID: "collect", AgentSlug: "reader", Prompt: "Summarize the inbox"},
+			{
+				ID: "escalate", AgentSlug: "pager", Prompt: "Page on-call: {{previous}}",
+				DependsOn: []string{"collect"}, IfContains: "urgent", TimeoutMinutes: 5,
+			},
+		},
+	}
+	require.NoError(t, store.CreateTask(ctx, task))
+
+	got, err := store.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, task.Steps, got.Steps)
+
+	list, err := store.ListTasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, task.Steps, list[0].Steps)
+
+	got.Steps = nil
+	require.NoError(t, store.UpdateTask(ctx, got))
+	got, err = store.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Steps)
+
+	started := time.Now().UTC().Truncate(time.Second)
+	jh := &storage.JobHistory{
+		TaskID: task.ID, TaskName: task.Name, Status: storage.JobStatusRunning, StartedAt: started,
+	}
+	require.NoError(t, store.CreateJobHistory(ctx, jh))
+
+	first := &storage.JobStep{
+		JobID: jh.ID, StepID: "collect", AgentSlug: "reader",
+		Status: storage.JobStatusRunning, StartedAt: started,
+	}
+	require.NoError(t, store.CreateJobStep(ctx, first))
+	second := &storage.JobStep{
+		JobID: jh.ID, StepID: "escalate", Position: 1, AgentSlug: "pager",
+		Status: storage.JobStatusSkipped, StartedAt: started,
+	}
+	require.NoError(t, store.CreateJobStep(ctx, second))
+
+	finished := started.Add(time.Minute)
+	first.Status = storage.JobStatusSuccess
+	first.FinishedAt = &finished
+	first.TotalOutputTokens = 42
+	first.ResponseText = "all quiet"
+	require.NoError(t, store.UpdateJobStep(ctx, first))
+
+	gotJob, err := store.GetJobHistory(ctx, jh.ID)
+	require.NoError(t, err)
+	require.Len(t, gotJob.Steps, 2)
+	assert.Equal(t, "collect", gotJob.Steps[0].StepID)
+	assert.Equal(t, storage.JobStatusSuccess, gotJob.Steps[0].Status)
+	assert.Equal(t, 42, gotJob.Steps[0].TotalOutputTokens)
+	assert.Equal(t, "all quiet", gotJob.Steps[0].ResponseText)
+	assert.Equal(t, storage.JobStatusSkipped, gotJob.Steps[1].Status)
+
+	// Steps are not loaded for listings, and go with their run.
+	runs, err := store.ListJobHistory(ctx, task.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Empty(t, runs[0].Steps)
+
+	require.NoError(t, store.DeleteJobHistory(ctx, jh.ID))
+	var n int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM job_steps").Scan(&n))
+	assert.Zero(t, n)
+}
@@ -26,7 +26,7 @@ func newTestDB(t *testing.T) *sql.DB {
 func TestNewSQLiteDB_CreatesTables(t *testing.T) {
 	db := newTestDB(t)
 
-	tables := []string{"agents", "chat_sessions", "chat_messages", "integrations", "user_settings", "schema_migrations", "claude_session_cache", "claude_subagent_cache", "claude_cache_metadata", "notification_log", "scheduled_tasks", "job_history", "trigger_rules", "telegram_processed_updates", "model_pricing", "model_pricing_tier"}
+	tables := []string{"agents", "chat_sessions", "chat_messages", "integrations", "user_settings", "schema_migrations", "claude_session_cache", "claude_subagent_cache", "claude_cache_metadata", "notification_log", "scheduled_tasks", "job_history", "job_steps", "trigger_rules", "telegram_processed_updates", "model_pricing", "model_pricing_tier", "budget_policies", "budget_spend"}
 	for _, table := range tables {
 		var name string
 		err := db.QueryRowContext(context.Background(), "SELECT name FROM sqlite_master WHERE type='table' AND name=?", table).Scan(&name)
@@ -44,8 +44,8 @@ func TestNewSQLiteDB_MigrationVersion(t *testing.T) {
 	if err != nil {
 		t.Fatalf("querying version: %v", err)
 	}
-	if version != 27 {
-		t.Errorf("expected version 27, got %d", version)
+	if version != 43 {
+		t.Errorf("expected version 43, got %d", version)
 	}
 }
 
@@ -329,7 +329,7 @@ func TestSQLiteChatStore_GetSessionWithMessages_NotFound(t *testing.T) {
 func TestSQLiteIntegrationStore_CRUD(t *testing.T) {
 	ctx := context.Background()
 	db := newTestDB(t)
-	store := NewSQLiteIntegrationStore(db)
+	store := NewSQLiteIntegrationStore(db, nil)
 
 	// List empty
 	integrations, err := store.List(ctx)
@@ -421,7 +421,7 @@ func TestSQLiteIntegrationStore_CRUD(t *testing.T) {
 func TestSQLiteIntegrationStore_SaveRequiresID(t *testing.T) {
 	ctx := context.Background()
 	db := newTestDB(t)
-	store := NewSQLiteIntegrationStore(db)
+	store := NewSQLiteIntegrationStore(db, nil)
 
 	err := store.Save(ctx, &config.IntegrationConfig{Name: "No ID"})
 	if err == nil {
@@ -452,6 +452,13 @@ func TestSQLiteSettingsStore_LoadSave(t *testing.T) {
 	settings.AppearanceDarkMode = true
 	settings.AppearanceFontSize = 14
 	settings.AppearanceFontFamily = "monospace"
+	settings.BackupIntervalHours = 24
+	settings.BackupIncludeCredentials = true
+	settings.TranscriptArchiveDisabled = true
+	settings.TranscriptArchiveMaxMB = 512
+	settings.ImportedAgents = []string{"codex", "gemini"}
+	settings.UsageLimitWeeklyUSD = 850.5
+	settings.UsageWarnPercent = 90
 	if saveErr := store.Save(settings); saveErr != nil {
 		t.Fatalf("save: %v", saveErr)
 	}
@@ -476,6 +483,21 @@ func TestSQLiteSettingsStore_LoadSave(t *testing.T) {
 	if got.AppearanceFontFamily != "monospace" {
 		t.Errorf("expected font family 'monospace', got %q", got.AppearanceFontFamily)
 	}
+	if got.BackupIntervalHours != 24 || !got.BackupIncludeCredentials {
+		t.Errorf("expected a daily backup with credentials, got %d hours, credentials %v",
+			got.BackupIntervalHours, got.BackupIncludeCredentials)
+	}
+	if !got.TranscriptArchiveDisabled || got.TranscriptArchiveMaxMB != 512 {
+		t.Errorf("expected a disabled 512 MB transcript archive, got disabled %v, %d MB",
+			got.TranscriptArchiveDisabled, got.TranscriptArchiveMaxMB)
+	}
+	if len(got.ImportedAgents) != 2 || got.ImportedAgents[0] != "codex" || got.ImportedAgents[1] != "gemini" {
+		t.Errorf("expected codex and gemini imported, got %v", got.ImportedAgents)
+	}
+	if got.UsageLimitWeeklyUSD != 850.5 || got.UsageWarnPercent != 90 {
+		t.Errorf("expected a $850.50 weekly limit warned at 90%%, got %v, %d%%",
+			got.UsageLimitWeeklyUSD, got.UsageWarnPercent)
+	}
 }
 
 // TestSQLiteSettingsStore_DataAnalytics covers the Data & Analytics fields,
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SQLiteToolCallStore implements ToolCallStore backed by a SQLite database.
+type SQLiteToolCallStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteToolCallStore returns a new SQLiteToolCallStore.
+func NewSQLiteToolCallStore(db *sql.DB) *SQLiteToolCallStore {
+	return &SQLiteToolCallStore{db: db}
+}
+
+const toolCallColumns = `id, tool_use_id, source, agent_slug, task_id, job_id, trigger_rule_id,
+	chat_session_id, tool_name, input, status, decision, decision_reason, started_at, duration_ms`
+
+// RecordToolCall appends a call to the log and sets its ID.
+func (s *SQLiteToolCallStore) RecordToolCall(ctx context.Context, call *ToolCall) error {
+	if call.StartedAt.IsZero() {
+		call.StartedAt = time.Now().UTC()
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO tool_calls (tool_use_id, source, agent_slug, task_id, job_id, trigger_rule_id,
+			chat_session_id, tool_name, input, status, decision, decision_reason, started_at, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		call.ToolUseID, call.Source, call.AgentSlug, call.TaskID, call.JobID, call.TriggerRuleID,
+		call.ChatSessionID, call.ToolName, call.Input, string(call.Status), call.Decision, call.DecisionReason,
+		call.StartedAt.UTC(), call.DurationMS,
+	)
+	if err != nil {
+		return fmt.Errorf("recording tool call: %w", err)
+	}
+	if call.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("recording tool call: %w", err)
+	}
+	return nil
+}
+
+// ListToolCalls returns the calls matching filter, newest first.
+func (s *SQLiteToolCallStore) ListToolCalls(ctx context.Context, filter ToolCallFilter) ([]*ToolCall, error) {
+	where, args := toolCallConditions(filter)
+
+	query := `SELECT ` + toolCallColumns + ` FROM tool_calls`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY started_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing tool calls: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	calls := make([]*ToolCall, 0)
+	for rows.Next() {
+		call, scanErr := scanToolCall(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("scanning tool call: %w", scanErr)
+		}
+		calls = append(calls, call)
+	}
+	return calls, rows.Err()
+}
+
+// toolCallConditions returns the WHERE conditions and arguments for filter.
+func toolCallConditions(filter ToolCallFilter) (where []string, args []any) {
+	equals := []struct {
+		column, value string
+	}{
+		{"agent_slug", filter.AgentSlug},
+		{"source", filter.Source},
+		{"tool_name", filter.ToolName},
+		{"job_id", filter.JobID},
+		{"chat_session_id", filter.ChatSessionID},
+		{"status", string(filter.Status)},
+	}
+	for _, eq := range equals {
+		if eq.value != "" {
+			where = append(where, eq.column+" = ?")
+			args = append(args, eq.value)
+		}
+	}
+	if filter.Query != "" {
+		// instr rather than LIKE, so that % and _ in a path match literally.
+		where = append(where, "instr(input, ?) > 0")
+		args = append(args, filter.Query)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "started_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "started_at < ?")
+		args = append(args, filter.Until.UTC())
+	}
+	return where, args
+}
+
+func scanToolCall(row interface{ Scan(...any) error }) (*ToolCall, error) {
+	var call ToolCall
+	var status string
+	if err := row.Scan(
+		&call.ID, &call.ToolUseID, &call.Source, &call.AgentSlug, &call.TaskID, &call.JobID, &call.TriggerRuleID,
+		&call.ChatSessionID, &call.ToolName, &call.Input, &status, &call.Decision, &call.DecisionReason,
+		&call.StartedAt, &call.DurationMS,
+	); err != nil {
+		return nil, err
+	}
+	call.Status = ToolCallStatus(status)
+	return &call, nil
+}
@@ -0,0 +1,90 @@
+package storage_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+func TestSQLiteToolCallStore(t *testing.T) {
+	db, _, err := storage.NewSQLiteDB(":memory:", slog.Default())
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := storage.NewSQLiteToolCallStore(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	record := func(call storage.ToolCall) *storage.ToolCall {
+		require.NoError(t, store.RecordToolCall(ctx, &call))
+		require.NotZero(t, call.ID)
+		return &call
+	}
+	record(storage.ToolCall{
+		Source: "scheduled_task", AgentSlug: "ops", JobID: "job-1", ToolName: "Edit",
+		Input: `{"file_path":"/srv/deploy/prod.yaml"}`, Status: storage.ToolCallSucceeded,
+		Decision: storage.ToolDecisionAuto, StartedAt: now.Add(-48 * time.Hour), DurationMS: 12,
+	})
+	record(storage.ToolCall{
+		Source: "chat", AgentSlug: "ops", ChatSessionID: "chat-1", ToolName: "Bash",
+		Input: `{"command":"kubectl apply -f prod.yaml"}`, Status: storage.ToolCallDenied,
+		Decision: storage.ToolDecisionDeny, DecisionReason: "not in prod", StartedAt: now.Add(-time.Hour),
+	})
+	last := record(storage.ToolCall{
+		Source: "trigger", AgentSlug: "docs", ToolName: "Read",
+		Input: `{"file_path":"/srv/docs/100%_done.md"}`, Status: storage.ToolCallSucceeded,
+		Decision: storage.ToolDecisionAllow, StartedAt: now,
+	})
+
+	t.Run("newest first", func(t *testing.T) {
+		calls, err := store.ListToolCalls(ctx, storage.ToolCallFilter{})
+		require.NoError(t, err)
+		require.Len(t, calls, 3)
+		assert.Equal(t, last.ID, calls[0].ID)
+		assert.Equal(t, last.Input, calls[0].Input)
+		assert.Equal(t, storage.ToolCallSucceeded, calls[0].Status)
+		assert.Equal(t, "not in prod", calls[1].DecisionReason)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			filter storage.ToolCallFilter
+			want   int
+		}{
+			{name: "input contains", filter: storage.ToolCallFilter{AgentSlug: "ops", Query: "prod.yaml"}, want: 2},
+			{name: "since", filter: storage.ToolCallFilter{Query: "prod.yaml", Since: now.Add(-7 * 24 * time.Hour)}, want: 2},
+			{name: "until", filter: storage.ToolCallFilter{Until: now.Add(-24 * time.Hour)}, want: 1},
+			{name: "literal percent", filter: storage.ToolCallFilter{Query: "100%_"}, want: 1},
+			{name: "wildcards do not match", filter: storage.ToolCallFilter{Query: "%prod%"}, want: 0},
+			{name: "tool and source", filter: storage.ToolCallFilter{ToolName: "Bash", Source: "chat"}, want: 1},
+			{name: "status", filter: storage.ToolCallFilter{Status: storage.ToolCallDenied}, want: 1},
+			{name: "job", filter: storage.ToolCallFilter{JobID: "job-1"}, want: 1},
+			{name: "limit", filter: storage.ToolCallFilter{Limit: 2}, want: 2},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				calls, err := store.ListToolCalls(ctx, tc.filter)
+				require.NoError(t, err)
+				assert.Len(t, calls, tc.want)
+			})
+		}
+	})
+
+	t.Run("entries cannot be changed or deleted", func(t *testing.T) {
+		_, err := db.ExecContext(ctx, `UPDATE tool_calls SET status = 'success' WHERE id = ?`, last.ID)
+		assert.ErrorContains(t, err, "append-only")
+		_, err = db.ExecContext(ctx, `DELETE FROM tool_calls`)
+		assert.ErrorContains(t, err, "append-only")
+
+		calls, err := store.ListToolCalls(ctx, storage.ToolCallFilter{})
+		require.NoError(t, err)
+		assert.Len(t, calls, 3)
+	})
+}
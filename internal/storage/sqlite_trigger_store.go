@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,6 +13,14 @@ import (
 	"github.com/shaharia-lab/agento/internal/config"
 )
 
+// triggerRuleColumns is the column list scanTriggerRule expects, in order.
+const triggerRuleColumns = `id, integration_id, name, agent_slug, enabled,
+		       filter_prefix, filter_keywords, filter_chat_ids,
+		       webhook_secret, prompt_template, variables, response_mode, callback_url,
+		       filter_events, filter_repos, filter_labels, filter_authors, filter_branches,
+		       conversation, conversation_idle_minutes,
+		       created_at, updated_at`
+
 // SQLiteTriggerStore implements TriggerStore backed by a SQLite database.
 type SQLiteTriggerStore struct {
 	db *sql.DB
@@ -25,9 +34,7 @@ func NewSQLiteTriggerStore(db *sql.DB) *SQLiteTriggerStore {
 // ListRules returns all trigger rules for the given integration, ordered by creation time.
 func (s *SQLiteTriggerStore) ListRules(ctx context.Context, integrationID string) ([]*config.TriggerRule, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, integration_id, name, agent_slug, enabled,
-		       filter_prefix, filter_keywords, filter_chat_ids,
-		       created_at, updated_at
+		SELECT `+triggerRuleColumns+`
 		FROM trigger_rules
 		WHERE integration_id = ?
 		ORDER BY created_at ASC`, integrationID)
@@ -50,35 +57,17 @@ func (s *SQLiteTriggerStore) ListRules(ctx context.Context, integrationID string
 // GetRule returns a single trigger rule by ID, or nil if not found.
 func (s *SQLiteTriggerStore) GetRule(ctx context.Context, id string) (*config.TriggerRule, error) {
 	row := s.db.QueryRowContext(ctx, `
-		SELECT id, integration_id, name, agent_slug, enabled,
-		       filter_prefix, filter_keywords, filter_chat_ids,
-		       created_at, updated_at
+		SELECT `+triggerRuleColumns+`
 		FROM trigger_rules WHERE id = ?`, id)
 
-	var r config.TriggerRule
-	var enabled int
-	var keywordsJSON, chatIDsJSON string
-
-	err := row.Scan(
-		&r.ID, &r.IntegrationID, &r.Name, &r.AgentSlug, &enabled,
-		&r.FilterPrefix, &keywordsJSON, &chatIDsJSON,
-		&r.CreatedAt, &r.UpdatedAt,
-	)
-	if err == sql.ErrNoRows {
+	r, err := scanTriggerRule(row)
+	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("getting trigger rule %q: %w", id, err)
 	}
-
-	r.Enabled = enabled != 0
-	if err := json.Unmarshal([]byte(keywordsJSON), &r.FilterKeywords); err != nil {
-		r.FilterKeywords = nil
-	}
-	if err := json.Unmarshal([]byte(chatIDsJSON), &r.FilterChatIDs); err != nil {
-		r.FilterChatIDs = nil
-	}
-	return &r, nil
+	return r, nil
 }
 
 // CreateRule inserts a new trigger rule.
@@ -98,6 +87,10 @@ func (s *SQLiteTriggerStore) CreateRule(ctx context.Context, rule *config.Trigge
 	if err != nil {
 		return fmt.Errorf("marshaling filter_chat_ids: %w", err)
 	}
+	variablesJSON, err := marshalTriggerVariables(rule.Variables)
+	if err != nil {
+		return err
+	}
 
 	enabled := 0
 	if rule.Enabled {
@@ -108,10 +101,18 @@ func (s *SQLiteTriggerStore) CreateRule(ctx context.Context, rule *config.Trigge
 		INSERT INTO trigger_rules
 			(id, integration_id, name, agent_slug, enabled,
 			 filter_prefix, filter_keywords, filter_chat_ids,
+			 webhook_secret, prompt_template, variables, response_mode, callback_url,
+			 filter_events, filter_repos, filter_labels, filter_authors, filter_branches,
+			 conversation, conversation_idle_minutes,
 			 created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		rule.ID, rule.IntegrationID, rule.Name, rule.AgentSlug, enabled,
 		rule.FilterPrefix, string(keywordsJSON), string(chatIDsJSON),
+		rule.WebhookSecret, rule.PromptTemplate, variablesJSON, rule.ResponseMode, rule.CallbackURL,
+		marshalStringList(rule.FilterEvents), marshalStringList(rule.FilterRepos),
+		marshalStringList(rule.FilterLabels), marshalStringList(rule.FilterAuthors),
+		marshalStringList(rule.FilterBranches),
+		rule.Conversation, rule.ConversationIdleMinutes,
 		rule.CreatedAt, rule.UpdatedAt,
 	)
 	if err != nil {
@@ -132,6 +133,10 @@ func (s *SQLiteTriggerStore) UpdateRule(ctx context.Context, rule *config.Trigge
 	if err != nil {
 		return fmt.Errorf("marshaling filter_chat_ids: %w", err)
 	}
+	variablesJSON, err := marshalTriggerVariables(rule.Variables)
+	if err != nil {
+		return err
+	}
 
 	enabled := 0
 	if rule.Enabled {
@@ -142,10 +147,18 @@ func (s *SQLiteTriggerStore) UpdateRule(ctx context.Context, rule *config.Trigge
 		UPDATE trigger_rules SET
 			name = ?, agent_slug = ?, enabled = ?,
 			filter_prefix = ?, filter_keywords = ?, filter_chat_ids = ?,
+			webhook_secret = ?, prompt_template = ?, variables = ?, response_mode = ?, callback_url = ?,
+			filter_events = ?, filter_repos = ?, filter_labels = ?, filter_authors = ?, filter_branches = ?,
+			conversation = ?, conversation_idle_minutes = ?,
 			updated_at = ?
 		WHERE id = ?`,
 		rule.Name, rule.AgentSlug, enabled,
 		rule.FilterPrefix, string(keywordsJSON), string(chatIDsJSON),
+		rule.WebhookSecret, rule.PromptTemplate, variablesJSON, rule.ResponseMode, rule.CallbackURL,
+		marshalStringList(rule.FilterEvents), marshalStringList(rule.FilterRepos),
+		marshalStringList(rule.FilterLabels), marshalStringList(rule.FilterAuthors),
+		marshalStringList(rule.FilterBranches),
+		rule.Conversation, rule.ConversationIdleMinutes,
 		rule.UpdatedAt, rule.ID,
 	)
 	if err != nil {
@@ -210,6 +223,90 @@ func (s *SQLiteTriggerStore) MarkUpdateProcessed(ctx context.Context, integratio
 	return nil
 }
 
+// GetUpdateOffset returns the offset long polling resumes from for an
+// integration, or 0 if none is saved.
+func (s *SQLiteTriggerStore) GetUpdateOffset(ctx context.Context, integrationID string) (int64, error) {
+	var offset int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT next_offset FROM telegram_update_offsets WHERE integration_id = ?`,
+		integrationID,
+	).Scan(&offset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("getting update offset: %w", err)
+	}
+	return offset, nil
+}
+
+// SaveUpdateOffset records the offset long polling resumes from for an
+// integration.
+func (s *SQLiteTriggerStore) SaveUpdateOffset(ctx context.Context, integrationID string, offset int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO telegram_update_offsets (integration_id, next_offset, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (integration_id) DO UPDATE SET
+			next_offset = excluded.next_offset,
+			updated_at  = excluded.updated_at`,
+		integrationID, offset, time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("saving update offset: %w", err)
+	}
+	return nil
+}
+
+// GetConversation returns the conversation a rule keeps under key, or nil if
+// there is none.
+func (s *SQLiteTriggerStore) GetConversation(
+	ctx context.Context, ruleID, key string,
+) (*TriggerConversation, error) {
+	c := TriggerConversation{RuleID: ruleID, Key: key}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT chat_session_id, last_message_at
+		FROM trigger_conversations
+		WHERE rule_id = ? AND conversation_key = ?`,
+		ruleID, key,
+	).Scan(&c.ChatSessionID, &c.LastMessageAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting trigger conversation: %w", err)
+	}
+	return &c, nil
+}
+
+// SaveConversation creates or replaces a rule's conversation under its key.
+func (s *SQLiteTriggerStore) SaveConversation(ctx context.Context, c *TriggerConversation) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO trigger_conversations (rule_id, conversation_key, chat_session_id, last_message_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (rule_id, conversation_key) DO UPDATE SET
+			chat_session_id = excluded.chat_session_id,
+			last_message_at = excluded.last_message_at`,
+		c.RuleID, c.Key, c.ChatSessionID, c.LastMessageAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("saving trigger conversation: %w", err)
+	}
+	return nil
+}
+
+// DeleteConversation forgets a rule's conversation under key, so the next
+// message starts a fresh one. The chat session is kept.
+func (s *SQLiteTriggerStore) DeleteConversation(ctx context.Context, ruleID, key string) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM trigger_conversations WHERE rule_id = ? AND conversation_key = ?`,
+		ruleID, key,
+	)
+	if err != nil {
+		return fmt.Errorf("deleting trigger conversation: %w", err)
+	}
+	return nil
+}
+
 // GetWebhookInfo returns the webhook secret, status, and error for an integration.
 func (s *SQLiteTriggerStore) GetWebhookInfo(
 	ctx context.Context, integrationID string,
@@ -250,11 +347,15 @@ type triggerRowScanner interface {
 func scanTriggerRule(rows triggerRowScanner) (*config.TriggerRule, error) {
 	var r config.TriggerRule
 	var enabled int
-	var keywordsJSON, chatIDsJSON string
+	var keywordsJSON, chatIDsJSON, variablesJSON string
+	var eventsJSON, reposJSON, labelsJSON, authorsJSON, branchesJSON string
 
 	err := rows.Scan(
 		&r.ID, &r.IntegrationID, &r.Name, &r.AgentSlug, &enabled,
 		&r.FilterPrefix, &keywordsJSON, &chatIDsJSON,
+		&r.WebhookSecret, &r.PromptTemplate, &variablesJSON, &r.ResponseMode, &r.CallbackURL,
+		&eventsJSON, &reposJSON, &labelsJSON, &authorsJSON, &branchesJSON,
+		&r.Conversation, &r.ConversationIdleMinutes,
 		&r.CreatedAt, &r.UpdatedAt,
 	)
 	if err != nil {
@@ -268,5 +369,44 @@ func scanTriggerRule(rows triggerRowScanner) (*config.TriggerRule, error) {
 	if err := json.Unmarshal([]byte(chatIDsJSON), &r.FilterChatIDs); err != nil {
 		r.FilterChatIDs = nil
 	}
+	if err := json.Unmarshal([]byte(variablesJSON), &r.Variables); err != nil || len(r.Variables) == 0 {
+		r.Variables = nil
+	}
+	r.FilterEvents = unmarshalStringList(eventsJSON)
+	r.FilterRepos = unmarshalStringList(reposJSON)
+	r.FilterLabels = unmarshalStringList(labelsJSON)
+	r.FilterAuthors = unmarshalStringList(authorsJSON)
+	r.FilterBranches = unmarshalStringList(branchesJSON)
 	return &r, nil
 }
+
+// marshalStringList encodes a filter list, storing nil as "[]".
+func marshalStringList(list []string) string {
+	if len(list) == 0 {
+		return "[]"
+	}
+	b, _ := json.Marshal(list) //nolint:errcheck // a []string always marshals
+	return string(b)
+}
+
+// unmarshalStringList decodes a filter list, returning nil for an empty or
+// unreadable one.
+func unmarshalStringList(s string) []string {
+	var list []string
+	if err := json.Unmarshal([]byte(s), &list); err != nil || len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// marshalTriggerVariables encodes a rule's variable map, storing nil as "{}".
+func marshalTriggerVariables(vars map[string]string) (string, error) {
+	if vars == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(vars)
+	if err != nil {
+		return "", fmt.Errorf("marshaling variables: %w", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,96 @@
+package storage_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// newTriggerStore returns a trigger store over a fresh database holding one
+// Telegram integration, which the trigger tables reference.
+func newTriggerStore(t *testing.T) *storage.SQLiteTriggerStore {
+	t.Helper()
+	db, _, err := storage.NewSQLiteDB(":memory:", slog.Default())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.ExecContext(context.Background(), `
+		INSERT INTO integrations (id, name, type, enabled, created_at, updated_at)
+		VALUES ('int-1', 'bot', 'telegram', 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`)
+	require.NoError(t, err)
+	return storage.NewSQLiteTriggerStore(db)
+}
+
+func TestSQLiteTriggerStore_Conversations(t *testing.T) {
+	store := newTriggerStore(t)
+	ctx := context.Background()
+	rule := &config.TriggerRule{
+		IntegrationID: "int-1", Name: "assistant", Enabled: true,
+		Conversation: config.TriggerConversationChat,
+	}
+	require.NoError(t, store.CreateRule(ctx, rule))
+
+	got, err := store.GetConversation(ctx, rule.ID, "-100123")
+	require.NoError(t, err)
+	assert.Nil(t, got, "no conversation yet")
+
+	first := time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, store.SaveConversation(ctx, &storage.TriggerConversation{
+		RuleID: rule.ID, Key: "-100123", ChatSessionID: "sess-1", LastMessageAt: first,
+	}))
+	// The next message moves the same conversation on rather than adding one.
+	require.NoError(t, store.SaveConversation(ctx, &storage.TriggerConversation{
+		RuleID: rule.ID, Key: "-100123", ChatSessionID: "sess-2", LastMessageAt: first.Add(time.Minute),
+	}))
+	require.NoError(t, store.SaveConversation(ctx, &storage.TriggerConversation{
+		RuleID: rule.ID, Key: "-100123:42", ChatSessionID: "sess-3", LastMessageAt: first,
+	}))
+
+	got, err = store.GetConversation(ctx, rule.ID, "-100123")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "sess-2", got.ChatSessionID)
+	assert.True(t, got.LastMessageAt.Equal(first.Add(time.Minute)), "last message at %v", got.LastMessageAt)
+
+	require.NoError(t, store.DeleteConversation(ctx, rule.ID, "-100123"))
+	got, err = store.GetConversation(ctx, rule.ID, "-100123")
+	require.NoError(t, err)
+	assert.Nil(t, got, "deleted")
+
+	got, err = store.GetConversation(ctx, rule.ID, "-100123:42")
+	require.NoError(t, err)
+	require.NotNil(t, got, "another thread's conversation is kept")
+	assert.Equal(t, "sess-3", got.ChatSessionID)
+
+	// A deleted rule takes its conversations with it.
+	require.NoError(t, store.DeleteRule(ctx, rule.ID))
+	got, err = store.GetConversation(ctx, rule.ID, "-100123:42")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestSQLiteTriggerStore_UpdateOffset(t *testing.T) {
+	store := newTriggerStore(t)
+	ctx := context.Background()
+
+	offset, err := store.GetUpdateOffset(ctx, "int-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), offset, "polling starts from the beginning")
+
+	require.NoError(t, store.SaveUpdateOffset(ctx, "int-1", 1001))
+	require.NoError(t, store.SaveUpdateOffset(ctx, "int-1", 1042))
+	offset, err = store.GetUpdateOffset(ctx, "int-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1042), offset)
+
+	offset, err = store.GetUpdateOffset(ctx, "int-2")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), offset, "offsets are per integration")
+}
@@ -34,6 +34,57 @@ const (
 	JobStatusRunning JobStatus = "running"
 	JobStatusSuccess JobStatus = "success"
 	JobStatusFailed  JobStatus = "failed"
+	// JobStatusBudgetBlocked marks a run refused or aborted by a budget cap.
+	// It is distinct from failed so a spent budget does not read as a broken
+	// task, and does not trigger the task-failed notification.
+	JobStatusBudgetBlocked JobStatus = "budget_blocked"
+	// JobStatusSkipped marks a pipeline step whose run condition was not met.
+	JobStatusSkipped JobStatus = "skipped"
+	// JobStatusRetrying marks a failed attempt that the task's retry policy
+	// will run again. Only the last attempt of a run ends as failed.
+	JobStatusRetrying JobStatus = "retrying"
+)
+
+// ErrorClass groups run failures so a retry policy can pick the ones worth
+// another attempt.
+type ErrorClass string
+
+// Error classes for retry policies.
+const (
+	ErrorClassTimeout   ErrorClass = "timeout"    // the run hit its timeout
+	ErrorClassRateLimit ErrorClass = "rate_limit" // rate or usage limit reached, or the API overloaded
+	ErrorClassCrash     ErrorClass = "crash"      // the claude process failed or exited without a result
+	// ErrorClassAgentError is an error result reported by the agent itself,
+	// such as hitting its turn limit. It usually fails the same way again.
+	ErrorClassAgentError ErrorClass = "agent_error"
+)
+
+// DefaultRetryOn is the set of error classes retried when a policy names none.
+var DefaultRetryOn = []ErrorClass{ErrorClassTimeout, ErrorClassRateLimit, ErrorClassCrash}
+
+// RetryPolicy controls how a failed run is retried. The zero value disables
+// retries.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt, so 3 means up to two retries.
+	MaxAttempts int `json:"max_attempts,omitempty"`
+	// BackoffSeconds is the wait before the first retry (default 60). Each
+	// later wait is BackoffMultiplier (default 2) times the one before, up to
+	// MaxBackoffSeconds (default 3600).
+	BackoffSeconds    int          `json:"backoff_seconds,omitempty"`
+	BackoffMultiplier float64      `json:"backoff_multiplier,omitempty"`
+	MaxBackoffSeconds int          `json:"max_backoff_seconds,omitempty"`
+	RetryOn           []ErrorClass `json:"retry_on,omitempty"` // default DefaultRetryOn
+}
+
+// StepRunIf names the outcome of a pipeline step's dependencies that lets the
+// step run.
+type StepRunIf string
+
+// Step run conditions for pipeline steps.
+const (
+	StepRunIfSuccess StepRunIf = "success" // every dependency succeeded (default)
+	StepRunIfFailure StepRunIf = "failure" // at least one dependency failed
+	StepRunIfAlways  StepRunIf = "always"
 )
 
 // ScheduleConfig holds the schedule-type-specific configuration as JSON.
@@ -72,6 +123,11 @@ type ScheduledTask struct {
 	NextRunAt         *time.Time     `json:"next_run_at,omitempty"`
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
+
+	// Steps turns the task into a pipeline. When set, Prompt and AgentSlug
+	// are unused and each step runs its own agent.
+	Steps []PipelineStep `json:"steps,omitempty"`
+	Retry RetryPolicy    `json:"retry"`
 }
 
 // MarshalScheduleConfig returns the JSON encoding of the schedule config.
@@ -83,6 +139,46 @@ func (t *ScheduledTask) MarshalScheduleConfig() (string, error) {
 	return string(b), nil
 }
 
+// MarshalRetryPolicy returns the JSON encoding of the retry policy.
+func (t *ScheduledTask) MarshalRetryPolicy() (string, error) {
+	b, err := json.Marshal(t.Retry)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// MarshalSteps returns the JSON encoding of the pipeline steps.
+func (t *ScheduledTask) MarshalSteps() (string, error) {
+	if len(t.Steps) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(t.Steps)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// PipelineStep is one agent run in a pipeline task. A step's prompt can use
+// the answers of the steps it depends on as {{steps.<id>}}, and the answer of
+// its last dependency as {{previous}}.
+type PipelineStep struct {
+	ID        string `json:"id"`
+	AgentSlug string `json:"agent_slug"`
+	Prompt    string `json:"prompt"`
+	// DependsOn lists the IDs of the steps that must finish first. When no
+	// step in a pipeline sets it, the steps run in order, each depending on
+	// the one before.
+	DependsOn []string `json:"depends_on,omitempty"`
+	// RunIf and IfContains gate the step on its dependencies' outcomes;
+	// IfContains requires at least one dependency's answer to contain the
+	// text (case-insensitive).
+	RunIf          StepRunIf `json:"run_if,omitempty"`
+	IfContains     string    `json:"if_contains,omitempty"`
+	TimeoutMinutes int       `json:"timeout_minutes,omitempty"` // 0 uses the task's timeout
+}
+
 // JobHistory records the result of a single task execution.
 type JobHistory struct {
 	ID                       string     `json:"id"`
@@ -102,6 +198,37 @@ type JobHistory struct {
 	TotalCacheCreationTokens int        `json:"total_cache_creation_tokens"`
 	TotalCacheReadTokens     int        `json:"total_cache_read_tokens"`
 	ResponseText             string     `json:"response_text"`
+	// Attempt numbers the tries of one run, from 1. Retries point at the
+	// first attempt's entry through RetryOf.
+	Attempt int    `json:"attempt"`
+	RetryOf string `json:"retry_of,omitempty"`
+	// Steps holds one record per pipeline step. It is only loaded by
+	// GetJobHistory, and is empty for single-prompt tasks.
+	Steps []*JobStep `json:"steps,omitempty"`
+	// Approvals holds the gated tool calls the run paused at and who
+	// answered them. It is only loaded by GetJobHistory.
+	Approvals []*ApprovalRequest `json:"approvals,omitempty"`
+}
+
+// JobStep records one step of a pipeline run.
+type JobStep struct {
+	ID                       string     `json:"id"`
+	JobID                    string     `json:"job_id"`
+	StepID                   string     `json:"step_id"`
+	Position                 int        `json:"position"`
+	AgentSlug                string     `json:"agent_slug"`
+	Status                   JobStatus  `json:"status"`
+	StartedAt                time.Time  `json:"started_at"`
+	FinishedAt               *time.Time `json:"finished_at,omitempty"`
+	DurationMS               int64      `json:"duration_ms"`
+	ChatSessionID            string     `json:"chat_session_id"`
+	PromptPreview            string     `json:"prompt_preview"`
+	ErrorMessage             string     `json:"error_message"`
+	TotalInputTokens         int        `json:"total_input_tokens"`
+	TotalOutputTokens        int        `json:"total_output_tokens"`
+	TotalCacheCreationTokens int        `json:"total_cache_creation_tokens"`
+	TotalCacheReadTokens     int        `json:"total_cache_read_tokens"`
+	ResponseText             string     `json:"response_text"`
 }
 
 // TaskStore defines the persistence interface for scheduled tasks and job history.
@@ -119,4 +246,7 @@ type TaskStore interface {
 	UpdateJobHistory(ctx context.Context, jh *JobHistory) error
 	DeleteJobHistory(ctx context.Context, id string) error
 	BulkDeleteJobHistory(ctx context.Context, ids []string) error
+
+	CreateJobStep(ctx context.Context, step *JobStep) error
+	UpdateJobStep(ctx context.Context, step *JobStep) error
 }
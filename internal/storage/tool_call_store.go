@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// ToolCallStatus is how an audited tool call ended.
+type ToolCallStatus string
+
+// Tool call status constants.
+const (
+	ToolCallSucceeded ToolCallStatus = "success"
+	ToolCallFailed    ToolCallStatus = "error"
+	// ToolCallDenied means the call was refused permission and never ran.
+	ToolCallDenied ToolCallStatus = "denied"
+	// ToolCallInterrupted means the run ended before the call returned.
+	ToolCallInterrupted ToolCallStatus = "interrupted"
+)
+
+// Permission decision constants for ToolCall.Decision.
+const (
+	// ToolDecisionAuto means claude ran the call without asking: the tool
+	// was pre-approved, or the agent runs in bypass mode.
+	ToolDecisionAuto  = "auto"
+	ToolDecisionAllow = "allow"
+	ToolDecisionDeny  = "deny"
+)
+
+// ToolCall is one entry of the tool call audit log. Entries are written once
+// and never changed.
+type ToolCall struct {
+	ID        int64  `json:"id"`
+	ToolUseID string `json:"tool_use_id"`
+	// Source is the kind of run: "chat", "scheduled_task", "trigger" or "mcp".
+	Source        string `json:"source"`
+	AgentSlug     string `json:"agent_slug"`
+	TaskID        string `json:"task_id,omitempty"`
+	JobID         string `json:"job_id,omitempty"`
+	TriggerRuleID string `json:"trigger_rule_id,omitempty"`
+	ChatSessionID string `json:"chat_session_id,omitempty"`
+	ToolName      string `json:"tool_name"`
+	// Input is the call's full JSON input.
+	Input  string         `json:"input"`
+	Status ToolCallStatus `json:"status"`
+	// Decision is the permission decision: ToolDecisionAuto, ToolDecisionAllow
+	// or ToolDecisionDeny.
+	Decision string `json:"decision"`
+	// DecisionReason is the message a denied call was refused with.
+	DecisionReason string    `json:"decision_reason,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+	DurationMS     int64     `json:"duration_ms"`
+}
+
+// ToolCallFilter narrows ListToolCalls. Zero fields do not filter.
+type ToolCallFilter struct {
+	AgentSlug     string
+	Source        string
+	ToolName      string
+	JobID         string
+	ChatSessionID string
+	Status        ToolCallStatus
+	// Query matches calls whose input contains it, e.g. a file name.
+	Query string
+	// Since and Until bound StartedAt, inclusive and exclusive.
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+// ToolCallStore defines the persistence interface for the tool call audit
+// log. The log is append-only: there is no way to change or delete an entry.
+type ToolCallStore interface {
+	// RecordToolCall appends a call to the log.
+	RecordToolCall(ctx context.Context, call *ToolCall) error
+
+	// ListToolCalls returns the calls matching filter, newest first.
+	ListToolCalls(ctx context.Context, filter ToolCallFilter) ([]*ToolCall, error)
+}
@@ -2,11 +2,23 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/shaharia-lab/agento/internal/config"
 )
 
-// TriggerStore defines the persistence interface for trigger rules and Telegram update deduplication.
+// TriggerConversation links a Telegram chat or reply thread to the chat
+// session a conversation-mode trigger rule resumes for it.
+type TriggerConversation struct {
+	RuleID string
+	// Key is the Telegram chat ID, or "chat:thread" for a thread.
+	Key           string
+	ChatSessionID string
+	LastMessageAt time.Time
+}
+
+// TriggerStore defines the persistence interface for trigger rules, Telegram update
+// deduplication and polling offsets, and Telegram conversations.
 type TriggerStore interface {
 	// ListRules returns all trigger rules for the given integration, ordered by creation time.
 	ListRules(ctx context.Context, integrationID string) ([]*config.TriggerRule, error)
@@ -32,6 +44,21 @@ type TriggerStore interface {
 	// MarkUpdateProcessed records a Telegram update_id as processed.
 	MarkUpdateProcessed(ctx context.Context, integrationID string, updateID int64) error
 
+	// GetUpdateOffset returns the offset long polling resumes from, or 0 if none is saved.
+	GetUpdateOffset(ctx context.Context, integrationID string) (int64, error)
+
+	// SaveUpdateOffset records the offset long polling resumes from.
+	SaveUpdateOffset(ctx context.Context, integrationID string, offset int64) error
+
+	// GetConversation returns the conversation a rule keeps under key, or nil if there is none.
+	GetConversation(ctx context.Context, ruleID, key string) (*TriggerConversation, error)
+
+	// SaveConversation creates or replaces a rule's conversation under its key.
+	SaveConversation(ctx context.Context, c *TriggerConversation) error
+
+	// DeleteConversation forgets a rule's conversation under key; the chat session is kept.
+	DeleteConversation(ctx context.Context, ruleID, key string) error
+
 	// GetWebhookInfo returns the webhook secret, status, and error for an integration.
 	GetWebhookInfo(ctx context.Context, integrationID string) (secret, status, webhookErr string, err error)
 
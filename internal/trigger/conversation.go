@@ -0,0 +1,170 @@
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// Telegram conversations.
+//
+// A rule without a conversation mode runs every message in a fresh session, so
+// the agent forgets everything between messages. A rule with one keeps a
+// trigger_conversations row per Telegram chat (or reply thread) pointing at
+// the Agento chat session it last ran in, and resumes that session's Claude
+// session for the next message. The conversation ends after the rule's idle
+// timeout or on /new; either way the old chat session stays, as history.
+
+// newCommand starts a fresh conversation.
+const newCommand = "/new"
+
+// parseNewCommand reports whether text is the /new command, addressed to any
+// bot ("/new@my_bot" in a group), and returns what follows it.
+func parseNewCommand(text string) (rest string, ok bool) {
+	cmd, rest, _ := strings.Cut(strings.TrimSpace(text), " ")
+	if name, _, _ := strings.Cut(cmd, "@"); name != newCommand {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// conversationKey identifies the conversation msg belongs to under rule: the
+// chat, or in thread mode the chat's thread when the message is in one.
+func conversationKey(rule *config.TriggerRule, msg *TelegramMsg) string {
+	if rule.Conversation == config.TriggerConversationThread && msg.MessageThreadID != 0 {
+		return fmt.Sprintf("%d:%d", msg.Chat.ID, msg.MessageThreadID)
+	}
+	return fmt.Sprintf("%d", msg.Chat.ID)
+}
+
+// conversationIdle is how long rule's conversations wait for a next message.
+func conversationIdle(rule *config.TriggerRule) time.Duration {
+	minutes := rule.ConversationIdleMinutes
+	if minutes <= 0 {
+		minutes = config.DefaultConversationIdleMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// conversationLive reports whether conv can still be resumed at now.
+func conversationLive(rule *config.TriggerRule, conv *storage.TriggerConversation, now time.Time) bool {
+	return conv != nil && now.Sub(conv.LastMessageAt) <= conversationIdle(rule)
+}
+
+// runTelegramRule runs rule on a Telegram message, continuing the
+// conversation the rule keeps for the message's chat or thread when it keeps
+// one.
+func (d *Dispatcher) runTelegramRule(
+	ctx context.Context, rule *config.TriggerRule, msg *TelegramMsg, prompt string,
+) (*agent.AgentResult, error) {
+	title := fmt.Sprintf("[Telegram] %s", rule.Name)
+	if rule.Conversation == "" {
+		_, result, err := d.runRule(ctx, rule, prompt, title)
+		return result, err
+	}
+
+	key := conversationKey(rule, msg)
+	unlock := d.lockConversation(rule.ID + "/" + key)
+	defer unlock()
+
+	agentCfg, err := d.resolveAgent(ctx, rule.AgentSlug)
+	if err != nil {
+		return nil, fmt.Errorf("resolving agent: %w", err)
+	}
+
+	session := d.resumableSession(ctx, rule, key)
+	if session == nil {
+		if session, err = d.createSession(ctx, rule, title); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := d.runInSession(ctx, rule, agentCfg, session, prompt)
+	if err != nil {
+		// A session that failed once — most often one whose Claude session can
+		// no longer be resumed — would fail every message after it too, so
+		// the conversation ends here and the next message starts afresh.
+		d.endConversation(ctx, rule.ID, key)
+		return nil, err
+	}
+
+	if err := d.triggerStore.SaveConversation(ctx, &storage.TriggerConversation{
+		RuleID:        rule.ID,
+		Key:           key,
+		ChatSessionID: session.ID,
+		LastMessageAt: time.Now().UTC(),
+	}); err != nil {
+		d.logger.Warn("failed to save telegram conversation", "rule_id", rule.ID, "key", key, "error", err)
+	}
+	return result, nil
+}
+
+// resumableSession returns the chat session rule's conversation under key
+// continues, or nil when there is none to continue: no conversation yet, one
+// idle past the timeout, or one whose session was deleted or never got as far
+// as a Claude session.
+func (d *Dispatcher) resumableSession(
+	ctx context.Context, rule *config.TriggerRule, key string,
+) *storage.ChatSession {
+	conv, err := d.triggerStore.GetConversation(ctx, rule.ID, key)
+	if err != nil {
+		d.logger.Warn("failed to load telegram conversation", "rule_id", rule.ID, "key", key, "error", err)
+		return nil
+	}
+	if !conversationLive(rule, conv, time.Now()) {
+		return nil
+	}
+	session, err := d.chatStore.GetSession(ctx, conv.ChatSessionID)
+	if err != nil {
+		d.logger.Warn("failed to load conversation session",
+			"rule_id", rule.ID, "session_id", conv.ChatSessionID, "error", err)
+		return nil
+	}
+	if session == nil || session.SDKSession == "" {
+		return nil
+	}
+	return session
+}
+
+// resetConversations handles /new: it ends the conversation of every enabled
+// conversation rule that would hear from msg's chat. It reports whether any
+// such rule exists; when none does, /new is an ordinary message.
+func (d *Dispatcher) resetConversations(ctx context.Context, integrationID string, msg *TelegramMsg) bool {
+	rules, err := d.triggerStore.ListRules(ctx, integrationID)
+	if err != nil {
+		d.logger.Error("failed to load trigger rules", "integration_id", integrationID, "error", err)
+		return false
+	}
+
+	chatID := fmt.Sprintf("%d", msg.Chat.ID)
+	reset := false
+	for _, rule := range rules {
+		if !rule.Enabled || rule.Conversation == "" || !matchesChatIDs(rule.FilterChatIDs, chatID) {
+			continue
+		}
+		d.endConversation(ctx, rule.ID, conversationKey(rule, msg))
+		reset = true
+	}
+	return reset
+}
+
+func (d *Dispatcher) endConversation(ctx context.Context, ruleID, key string) {
+	if err := d.triggerStore.DeleteConversation(ctx, ruleID, key); err != nil {
+		d.logger.Warn("failed to end telegram conversation", "rule_id", ruleID, "key", key, "error", err)
+	}
+}
+
+// lockConversation holds the conversation's mutex until the returned func
+// is called.
+func (d *Dispatcher) lockConversation(id string) func() {
+	mu, _ := d.conversations.LoadOrStore(id, &sync.Mutex{})
+	m := mu.(*sync.Mutex)
+	m.Lock()
+	return m.Unlock
+}
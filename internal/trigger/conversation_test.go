@@ -0,0 +1,147 @@
+package trigger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage"
+	"github.com/shaharia-lab/agento/internal/storage/mocks"
+)
+
+func TestParseNewCommand(t *testing.T) {
+	tests := []struct {
+		text   string
+		rest   string
+		wantOK bool
+	}{
+		{text: "/new", wantOK: true},
+		{text: "  /new  ", wantOK: true},
+		{text: "/new@agento_bot", wantOK: true},
+		{text: "/new let's plan the release", rest: "let's plan the release", wantOK: true},
+		{text: "/new@agento_bot  start over", rest: "start over", wantOK: true},
+		{text: "/newer"},
+		{text: "/NEW"},
+		{text: "what's /new here"},
+		{text: ""},
+	}
+	for _, tc := range tests {
+		rest, ok := parseNewCommand(tc.text)
+		assert.Equal(t, tc.wantOK, ok, tc.text)
+		assert.Equal(t, tc.rest, rest, tc.text)
+	}
+}
+
+func TestConversationKey(t *testing.T) {
+	inThread := &TelegramMsg{Chat: TelegramChat{ID: -100123}, MessageThreadID: 42}
+	plain := &TelegramMsg{Chat: TelegramChat{ID: -100123}}
+
+	chat := &config.TriggerRule{Conversation: config.TriggerConversationChat}
+	assert.Equal(t, "-100123", conversationKey(chat, inThread), "chat mode ignores threads")
+
+	thread := &config.TriggerRule{Conversation: config.TriggerConversationThread}
+	assert.Equal(t, "-100123:42", conversationKey(thread, inThread))
+	assert.Equal(t, "-100123", conversationKey(thread, plain), "outside a thread the chat is the conversation")
+}
+
+func TestConversationLive(t *testing.T) {
+	now := time.Date(2026, 8, 1, 12, 0, 0, 0, time.UTC)
+	conv := func(ago time.Duration) *storage.TriggerConversation {
+		return &storage.TriggerConversation{LastMessageAt: now.Add(-ago)}
+	}
+
+	byDefault := &config.TriggerRule{Conversation: config.TriggerConversationChat}
+	assert.False(t, conversationLive(byDefault, nil, now))
+	assert.True(t, conversationLive(byDefault, conv(59*time.Minute), now))
+	assert.False(t, conversationLive(byDefault, conv(61*time.Minute), now))
+
+	short := &config.TriggerRule{Conversation: config.TriggerConversationChat, ConversationIdleMinutes: 5}
+	assert.True(t, conversationLive(short, conv(5*time.Minute), now))
+	assert.False(t, conversationLive(short, conv(6*time.Minute), now))
+}
+
+func newConversationDispatcher(triggers *mocks.MockTriggerStore, chats *mocks.MockChatStore) *Dispatcher {
+	return NewDispatcher(DispatcherConfig{
+		TriggerStore: triggers,
+		ChatStore:    chats,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+}
+
+func TestDispatcher_ResumableSession(t *testing.T) {
+	ctx := context.Background()
+	rule := &config.TriggerRule{ID: "rule-1", Conversation: config.TriggerConversationChat}
+	conv := func(ago time.Duration, sessionID string) *storage.TriggerConversation {
+		return &storage.TriggerConversation{
+			RuleID: rule.ID, Key: "-100123", ChatSessionID: sessionID, LastMessageAt: time.Now().Add(-ago),
+		}
+	}
+
+	t.Run("a live conversation resumes its session", func(t *testing.T) {
+		triggers, chats := &mocks.MockTriggerStore{}, &mocks.MockChatStore{}
+		triggers.On("GetConversation", mock.Anything, "rule-1", "-100123").Return(conv(time.Minute, "sess-1"), nil)
+		chats.On("GetSession", mock.Anything, "sess-1").Return(&storage.ChatSession{ID: "sess-1", SDKSession: "sdk-1"}, nil)
+
+		session := newConversationDispatcher(triggers, chats).resumableSession(ctx, rule, "-100123")
+		if assert.NotNil(t, session) {
+			assert.Equal(t, "sess-1", session.ID)
+		}
+		chats.AssertExpectations(t)
+	})
+
+	t.Run("an idle conversation starts afresh", func(t *testing.T) {
+		triggers, chats := &mocks.MockTriggerStore{}, &mocks.MockChatStore{}
+		triggers.On("GetConversation", mock.Anything, "rule-1", "-100123").Return(conv(2*time.Hour, "sess-1"), nil)
+
+		assert.Nil(t, newConversationDispatcher(triggers, chats).resumableSession(ctx, rule, "-100123"))
+		chats.AssertNotCalled(t, "GetSession", mock.Anything, mock.Anything)
+	})
+
+	t.Run("a session that never reached Claude starts afresh", func(t *testing.T) {
+		triggers, chats := &mocks.MockTriggerStore{}, &mocks.MockChatStore{}
+		triggers.On("GetConversation", mock.Anything, "rule-1", "-100123").Return(conv(time.Minute, "sess-1"), nil)
+		chats.On("GetSession", mock.Anything, "sess-1").Return(&storage.ChatSession{ID: "sess-1"}, nil)
+
+		assert.Nil(t, newConversationDispatcher(triggers, chats).resumableSession(ctx, rule, "-100123"))
+	})
+
+	t.Run("no conversation yet", func(t *testing.T) {
+		triggers, chats := &mocks.MockTriggerStore{}, &mocks.MockChatStore{}
+		triggers.On("GetConversation", mock.Anything, "rule-1", "-100123").Return(nil, nil)
+
+		assert.Nil(t, newConversationDispatcher(triggers, chats).resumableSession(ctx, rule, "-100123"))
+	})
+}
+
+func TestDispatcher_ResetConversations(t *testing.T) {
+	ctx := context.Background()
+	msg := &TelegramMsg{Chat: TelegramChat{ID: -100123}, MessageThreadID: 42}
+
+	triggers := &mocks.MockTriggerStore{}
+	triggers.On("ListRules", mock.Anything, "int-1").Return([]*config.TriggerRule{
+		{ID: "chat", Enabled: true, Conversation: config.TriggerConversationChat},
+		{ID: "thread", Enabled: true, Conversation: config.TriggerConversationThread},
+		{ID: "stateless", Enabled: true},
+		{ID: "disabled", Conversation: config.TriggerConversationChat},
+		{ID: "elsewhere", Enabled: true, Conversation: config.TriggerConversationChat, FilterChatIDs: []string{"777"}},
+	}, nil)
+	triggers.On("DeleteConversation", mock.Anything, "chat", "-100123").Return(nil)
+	triggers.On("DeleteConversation", mock.Anything, "thread", "-100123:42").Return(nil)
+
+	d := newConversationDispatcher(triggers, &mocks.MockChatStore{})
+	assert.True(t, d.resetConversations(ctx, "int-1", msg))
+	triggers.AssertExpectations(t)
+	triggers.AssertNumberOfCalls(t, "DeleteConversation", 2)
+
+	// Without a conversation rule to hear it, /new is an ordinary message.
+	none := &mocks.MockTriggerStore{}
+	none.On("ListRules", mock.Anything, "int-2").Return([]*config.TriggerRule{{ID: "stateless", Enabled: true}}, nil)
+	assert.False(t, newConversationDispatcher(none, &mocks.MockChatStore{}).resetConversations(ctx, "int-2", msg))
+	none.AssertNotCalled(t, "DeleteConversation", mock.Anything, mock.Anything, mock.Anything)
+}
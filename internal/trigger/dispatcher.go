@@ -2,12 +2,18 @@ package trigger
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 	"time"
 
+	claude "github.com/shaharia-lab/claude-agent-sdk-go/claude"
+
 	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/approval"
+	"github.com/shaharia-lab/agento/internal/audit"
 	"github.com/shaharia-lab/agento/internal/config"
 	"github.com/shaharia-lab/agento/internal/integrations"
 	telegramintegration "github.com/shaharia-lab/agento/internal/integrations/telegram"
@@ -16,11 +22,37 @@ import (
 )
 
 // maxConcurrentExecutions limits the number of concurrent agent executions
-// dispatched from incoming Telegram messages.
+// dispatched from incoming Telegram messages and webhooks.
 const maxConcurrentExecutions = 10
 
+// runTimeout bounds a triggered run. A run of an agent that gates tools gets
+// one approval timeout on top, so that waiting on an answer does not use up
+// its time.
+const runTimeout = 5 * time.Minute
+
+// BudgetEnforcer hands out a budget guard for each triggered run.
+type BudgetEnforcer interface {
+	ForRun(agentSlug, taskID, source string) agent.BudgetGuard
+}
+
+// ApprovalBroker gates the tool calls of triggered runs on a person's
+// approval, and takes the answers given with Telegram buttons.
+type ApprovalBroker interface {
+	PermissionHandler(ctx context.Context, agentCfg *config.AgentConfig, run approval.Run) claude.PermissionHandler
+	HandleTelegramCallback(ctx context.Context, integrationID, data, from string) string
+	// Timeout is how long a request waits for an answer.
+	Timeout() time.Duration
+}
+
+// ToolAuditLog hands out the auditor that logs the tool calls of each
+// triggered run.
+type ToolAuditLog interface {
+	ForRun(run audit.Run) agent.ToolAuditor
+}
+
 // Dispatcher matches incoming messages against trigger rules, runs the
-// appropriate agent, and sends the reply back to Telegram.
+// appropriate agent, and delivers the reply: back to Telegram, or to the
+// caller of a generic webhook.
 type Dispatcher struct {
 	triggerStore        storage.TriggerStore
 	agentStore          storage.AgentStore
@@ -30,9 +62,18 @@ type Dispatcher struct {
 	localToolsMCP       *tools.LocalMCPConfig
 	integrationRegistry *integrations.IntegrationRegistry
 	settingsMgr         *config.SettingsManager
+	budget              BudgetEnforcer
+	approvals           ApprovalBroker
+	audit               ToolAuditLog
 	logger              *slog.Logger
 	sem                 chan struct{}
 	ctx                 context.Context
+
+	// conversations serializes the messages of one Telegram conversation, so
+	// two sent in quick succession resume the session one after the other
+	// rather than both resuming the same point. Keyed by rule ID and
+	// conversation key; one mutex per conversation ever seen.
+	conversations sync.Map
 }
 
 // DispatcherConfig holds all dependencies for the Dispatcher.
@@ -47,6 +88,15 @@ type DispatcherConfig struct {
 	SettingsMgr         *config.SettingsManager
 	Logger              *slog.Logger
 	Ctx                 context.Context
+	// Budget is optional. When set, triggered runs are checked against the
+	// configured spending caps.
+	Budget BudgetEnforcer
+	// Approvals is optional. When set, the tools an agent gates wait on a
+	// person's approval, and Telegram approval buttons are answered.
+	Approvals ApprovalBroker
+	// Audit is optional. When set, the tool calls of triggered runs are
+	// logged.
+	Audit ToolAuditLog
 }
 
 // NewDispatcher creates a new Dispatcher.
@@ -64,6 +114,9 @@ func NewDispatcher(cfg DispatcherConfig) *Dispatcher {
 		localToolsMCP:       cfg.LocalToolsMCP,
 		integrationRegistry: cfg.IntegrationRegistry,
 		settingsMgr:         cfg.SettingsMgr,
+		budget:              cfg.Budget,
+		approvals:           cfg.Approvals,
+		audit:               cfg.Audit,
 		logger:              cfg.Logger,
 		sem:                 make(chan struct{}, maxConcurrentExecutions),
 		ctx:                 ctx,
@@ -72,8 +125,18 @@ func NewDispatcher(cfg DispatcherConfig) *Dispatcher {
 
 // TelegramUpdate represents the relevant fields from a Telegram Update object.
 type TelegramUpdate struct {
-	UpdateID int64        `json:"update_id"`
-	Message  *TelegramMsg `json:"message,omitempty"`
+	UpdateID      int64                  `json:"update_id"`
+	Message       *TelegramMsg           `json:"message,omitempty"`
+	CallbackQuery *TelegramCallbackQuery `json:"callback_query,omitempty"`
+}
+
+// TelegramCallbackQuery represents a press of an inline button under one of
+// the bot's messages.
+type TelegramCallbackQuery struct {
+	ID      string        `json:"id"`
+	From    *TelegramUser `json:"from,omitempty"`
+	Message *TelegramMsg  `json:"message,omitempty"`
+	Data    string        `json:"data"`
 }
 
 // TelegramMsg represents the relevant fields from a Telegram Message object.
@@ -82,6 +145,9 @@ type TelegramMsg struct {
 	Chat      TelegramChat  `json:"chat"`
 	Text      string        `json:"text"`
 	From      *TelegramUser `json:"from,omitempty"`
+	// MessageThreadID is set for messages in a forum topic or, in a
+	// supergroup, a reply thread.
+	MessageThreadID int `json:"message_thread_id,omitempty"`
 }
 
 // TelegramChat represents a Telegram chat.
@@ -103,6 +169,12 @@ func (d *Dispatcher) HandleTelegramUpdate(
 	integrationID, botToken string,
 	update TelegramUpdate,
 ) {
+	if update.CallbackQuery != nil {
+		// Button presses answer approvals that running agents wait on, so
+		// they must not queue behind those runs for the semaphore.
+		go d.processCallbackQuery(integrationID, botToken, update)
+		return
+	}
 	go func() {
 		select {
 		case d.sem <- struct{}{}:
@@ -129,7 +201,22 @@ func (d *Dispatcher) processTelegramUpdate(
 		return
 	}
 
-	matchedRule, prompt := d.findMatchingRule(ctx, integrationID, update.Message)
+	msg := update.Message
+	if rest, ok := parseNewCommand(msg.Text); ok && d.resetConversations(ctx, integrationID, msg) {
+		if rest == "" {
+			if err := telegramintegration.SendReply(ctx, botToken, msg.Chat.ID, msg.MessageID,
+				"Started a new conversation."); err != nil {
+				d.logger.Error("failed to send telegram reply", "chat_id", msg.Chat.ID, "error", err)
+			}
+			return
+		}
+		// "/new <text>" starts the new conversation with text.
+		fresh := *msg
+		fresh.Text = rest
+		msg = &fresh
+	}
+
+	matchedRule, prompt := d.findMatchingRule(ctx, integrationID, msg)
 	if matchedRule == nil {
 		return
 	}
@@ -137,9 +224,40 @@ func (d *Dispatcher) processTelegramUpdate(
 	d.logger.Info("trigger rule matched",
 		"rule_id", matchedRule.ID, "rule_name", matchedRule.Name,
 		"agent_slug", matchedRule.AgentSlug,
-		"chat_id", update.Message.Chat.ID)
+		"chat_id", msg.Chat.ID)
+
+	d.executeAndReply(ctx, botToken, msg, matchedRule, prompt)
+}
 
-	d.executeAndReply(ctx, botToken, update.Message, matchedRule, prompt)
+// processCallbackQuery hands an inline button press to the approval broker
+// and shows its answer to the person who pressed it.
+func (d *Dispatcher) processCallbackQuery(integrationID, botToken string, update TelegramUpdate) {
+	ctx := d.ctx
+	query := update.CallbackQuery
+	if !d.deduplicateUpdate(ctx, integrationID, update.UpdateID) {
+		return
+	}
+
+	answer := "This button is no longer in use."
+	if d.approvals != nil {
+		answer = d.approvals.HandleTelegramCallback(ctx, integrationID, query.Data, telegramUserName(query.From))
+	}
+	if err := telegramintegration.AnswerCallbackQuery(ctx, botToken, query.ID, answer); err != nil {
+		d.logger.Warn("failed to answer telegram callback query", "integration_id", integrationID, "error", err)
+	}
+}
+
+// telegramUserName names a Telegram user: @username, or their first name
+// and ID for users without one.
+func telegramUserName(u *TelegramUser) string {
+	switch {
+	case u == nil:
+		return "unknown"
+	case u.Username != "":
+		return "@" + u.Username
+	default:
+		return fmt.Sprintf("%s (%d)", u.FirstName, u.ID)
+	}
 }
 
 // deduplicateUpdate checks and marks the update as processed. Returns true if processing should continue.
@@ -190,62 +308,134 @@ func (d *Dispatcher) executeAndReply(
 ) {
 	telegramintegration.SendChatAction(ctx, botToken, msg.Chat.ID)
 
-	agentCfg, err := d.resolveAgent(ctx, rule.AgentSlug)
+	result, err := d.runTelegramRule(ctx, rule, msg, prompt)
+	var budgetErr *agent.BudgetExceededError
+	if errors.As(err, &budgetErr) {
+		d.logger.Warn("trigger run blocked by budget", "rule_id", rule.ID, "policy_id", budgetErr.PolicyID)
+		if replyErr := telegramintegration.SendReply(
+			ctx, botToken, msg.Chat.ID, msg.MessageID,
+			"This agent has reached its spending limit. Please try again later.",
+		); replyErr != nil {
+			d.logger.Error("failed to send budget reply", "chat_id", msg.Chat.ID, "error", replyErr)
+		}
+		return
+	}
 	if err != nil {
-		d.logger.Error("failed to resolve agent for trigger", "agent_slug", rule.AgentSlug, "error", err)
+		d.logger.Error("agent execution failed for trigger", "rule_id", rule.ID, "error", err)
 		d.sendErrorReply(ctx, botToken, msg.Chat.ID, msg.MessageID)
 		return
 	}
 
+	reply := result.Answer
+	if reply == "" {
+		reply = "No response generated."
+	}
+	if replyErr := telegramintegration.SendReply(ctx, botToken, msg.Chat.ID, msg.MessageID, reply); replyErr != nil {
+		d.logger.Error("failed to send telegram reply", "chat_id", msg.Chat.ID, "error", replyErr)
+	}
+}
+
+// runRule resolves the rule's agent and runs it on prompt, recording the
+// exchange as a chat session titled title. The session is returned whenever
+// one was created, including when the run itself fails.
+func (d *Dispatcher) runRule(
+	ctx context.Context, rule *config.TriggerRule, prompt, title string,
+) (*storage.ChatSession, *agent.AgentResult, error) {
+	agentCfg, err := d.resolveAgent(ctx, rule.AgentSlug)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolving agent: %w", err)
+	}
+
+	chatSession, err := d.createSession(ctx, rule, title)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := d.runInSession(ctx, rule, agentCfg, chatSession, prompt)
+	return chatSession, result, err
+}
+
+// createSession starts the chat session a triggered run is recorded in.
+func (d *Dispatcher) createSession(
+	ctx context.Context, rule *config.TriggerRule, title string,
+) (*storage.ChatSession, error) {
 	chatSession, err := d.chatStore.CreateSession(ctx, rule.AgentSlug, "", "", "")
 	if err != nil {
-		d.logger.Error("failed to create chat session for trigger", "error", err)
-		d.sendErrorReply(ctx, botToken, msg.Chat.ID, msg.MessageID)
-		return
+		return nil, fmt.Errorf("creating chat session: %w", err)
 	}
 
-	chatSession.Title = fmt.Sprintf("[Telegram] %s", rule.Name)
+	chatSession.Title = title
 	if updateErr := d.chatStore.UpdateSession(ctx, chatSession); updateErr != nil {
 		d.logger.Warn("failed to update session title", "error", updateErr)
 	}
+	return chatSession, nil
+}
 
+// runInSession runs the agent on prompt and records the exchange in session,
+// resuming its Claude session when it already has one.
+func (d *Dispatcher) runInSession(
+	ctx context.Context, rule *config.TriggerRule, agentCfg *config.AgentConfig,
+	session *storage.ChatSession, prompt string,
+) (*agent.AgentResult, error) {
 	opts := agent.RunOptions{
 		LocalToolsMCP:       d.localToolsMCP,
 		MCPRegistry:         d.mcpRegistry,
 		IntegrationRegistry: d.integrationRegistry,
+		ResumeSessionID:     session.SDKSession,
+	}
+	if d.budget != nil {
+		opts.Budget = d.budget.ForRun(rule.AgentSlug, "", "trigger")
+	}
+	if d.audit != nil {
+		opts.Audit = d.audit.ForRun(audit.Run{
+			Source:        audit.SourceTrigger,
+			AgentSlug:     rule.AgentSlug,
+			TriggerRuleID: rule.ID,
+			ChatSessionID: session.ID,
+		})
 	}
 
-	runCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	timeout := runTimeout
+	gated := d.approvals != nil && agentCfg.Capabilities.MayRequireApproval()
+	if gated {
+		timeout += d.approvals.Timeout()
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	if gated {
+		opts.PermissionHandler = d.approvals.PermissionHandler(runCtx, agentCfg, approval.Run{
+			Source:        "trigger",
+			AgentSlug:     rule.AgentSlug,
+			TriggerRuleID: rule.ID,
+			ChatSessionID: session.ID,
+			Title:         session.Title,
+		})
+	}
+
 	result, err := agent.RunAgent(runCtx, agentCfg, prompt, opts)
 	if err != nil {
-		d.logger.Error("agent execution failed for trigger", "rule_id", rule.ID, "error", err)
-		d.sendErrorReply(ctx, botToken, msg.Chat.ID, msg.MessageID)
-		d.saveSessionMessages(ctx, chatSession, prompt, "")
-		return
+		d.saveSessionMessages(ctx, session, prompt, "")
+		return nil, err
 	}
 
-	d.saveSessionMessages(ctx, chatSession, prompt, result.Answer)
-	d.updateSessionUsage(ctx, chatSession, result)
-
-	reply := result.Answer
-	if reply == "" {
-		reply = "No response generated."
-	}
-	if replyErr := telegramintegration.SendReply(ctx, botToken, msg.Chat.ID, msg.MessageID, reply); replyErr != nil {
-		d.logger.Error("failed to send telegram reply", "chat_id", msg.Chat.ID, "error", replyErr)
-	}
+	d.saveSessionMessages(ctx, session, prompt, result.Answer)
+	d.updateSessionUsage(ctx, session, result)
+	return result, nil
 }
 
+// updateSessionUsage records a run's Claude session and adds its usage to the
+// session's running totals: a conversation runs many times in one session.
 func (d *Dispatcher) updateSessionUsage(
 	ctx context.Context, session *storage.ChatSession, result *agent.AgentResult,
 ) {
-	session.SDKSession = result.SessionID
-	session.TotalInputTokens = result.Usage.InputTokens
-	session.TotalOutputTokens = result.Usage.OutputTokens
-	session.TotalCacheCreationTokens = result.Usage.CacheCreationInputTokens
-	session.TotalCacheReadTokens = result.Usage.CacheReadInputTokens
+	if result.SessionID != "" {
+		session.SDKSession = result.SessionID
+	}
+	session.TotalInputTokens += result.Usage.InputTokens
+	session.TotalOutputTokens += result.Usage.OutputTokens
+	session.TotalCacheCreationTokens += result.Usage.CacheCreationInputTokens
+	session.TotalCacheReadTokens += result.Usage.CacheReadInputTokens
 	session.UpdatedAt = time.Now().UTC()
 	if updateErr := d.chatStore.UpdateSession(ctx, session); updateErr != nil {
 		d.logger.Warn("failed to update session after execution", "error", updateErr)
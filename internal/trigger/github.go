@@ -0,0 +1,385 @@
+package trigger
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+// GitHubSignatureHeader carries GitHub's HMAC-SHA256 of a delivery body as
+// "sha256=<hex>", the same format VerifyWebhookSignature checks.
+const GitHubSignatureHeader = "X-Hub-Signature-256"
+
+// GitHubEvent is the part of a GitHub webhook delivery that rules filter on
+// and prompts are built from. Fields that do not apply to an event type are
+// empty.
+type GitHubEvent struct {
+	Name       string // X-GitHub-Event, e.g. "pull_request"
+	Action     string // payload action, e.g. "opened"
+	Repo       string // "owner/name"
+	Sender     string
+	Author     string // who opened the issue or PR, wrote the comment, or pushed
+	Number     int
+	Title      string
+	Body       string // issue or pull request description
+	Comment    string // comment or review text
+	URL        string
+	Branch     string // base branch of a PR; pushed or built branch otherwise
+	HeadBranch string
+	SHA        string
+	Labels     []string
+	State      string // PR/issue state, review state, or workflow conclusion
+
+	// Payload is the raw delivery body.
+	Payload []byte
+}
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubIssue struct {
+	Number  int         `json:"number"`
+	Title   string      `json:"title"`
+	Body    string      `json:"body"`
+	HTMLURL string      `json:"html_url"`
+	State   string      `json:"state"`
+	User    *githubUser `json:"user"`
+	Labels  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Head *struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base *struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+type githubComment struct {
+	Body    string      `json:"body"`
+	HTMLURL string      `json:"html_url"`
+	State   string      `json:"state"`
+	User    *githubUser `json:"user"`
+}
+
+type githubPayload struct {
+	Action     string `json:"action"`
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Compare    string `json:"compare"`
+	Repository *struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender      *githubUser    `json:"sender"`
+	PullRequest *githubIssue   `json:"pull_request"`
+	Issue       *githubIssue   `json:"issue"`
+	Comment     *githubComment `json:"comment"`
+	Review      *githubComment `json:"review"`
+	HeadCommit  *struct {
+		Message string `json:"message"`
+	} `json:"head_commit"`
+	WorkflowRun *struct {
+		Name       string      `json:"name"`
+		HeadBranch string      `json:"head_branch"`
+		HeadSHA    string      `json:"head_sha"`
+		Conclusion string      `json:"conclusion"`
+		HTMLURL    string      `json:"html_url"`
+		RunNumber  int         `json:"run_number"`
+		Actor      *githubUser `json:"actor"`
+	} `json:"workflow_run"`
+	Release *struct {
+		TagName string      `json:"tag_name"`
+		Name    string      `json:"name"`
+		Body    string      `json:"body"`
+		HTMLURL string      `json:"html_url"`
+		Author  *githubUser `json:"author"`
+	} `json:"release"`
+}
+
+// ParseGitHubEvent extracts a GitHubEvent from a delivery. name is the value
+// of the X-GitHub-Event header.
+func ParseGitHubEvent(name string, body []byte) (*GitHubEvent, error) {
+	var p githubPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("decoding github payload: %w", err)
+	}
+
+	ev := &GitHubEvent{Name: name, Action: p.Action, Payload: body}
+	if p.Repository != nil {
+		ev.Repo = p.Repository.FullName
+	}
+	if p.Sender != nil {
+		ev.Sender = p.Sender.Login
+	}
+	ev.Author = ev.Sender
+
+	// A pull request takes precedence over its issue: pull_request_review
+	// and friends carry only the former, issue_comment on a PR only the latter.
+	if item := firstIssue(p.PullRequest, p.Issue); item != nil {
+		ev.Number = item.Number
+		ev.Title = item.Title
+		ev.Body = item.Body
+		ev.URL = item.HTMLURL
+		ev.State = item.State
+		if item.User != nil {
+			ev.Author = item.User.Login
+		}
+		for _, l := range item.Labels {
+			ev.Labels = append(ev.Labels, l.Name)
+		}
+		if item.Base != nil {
+			ev.Branch = item.Base.Ref
+		}
+		if item.Head != nil {
+			ev.HeadBranch = item.Head.Ref
+			ev.SHA = item.Head.SHA
+		}
+	}
+	if c := firstComment(p.Comment, p.Review); c != nil {
+		ev.Comment = c.Body
+		if c.HTMLURL != "" {
+			ev.URL = c.HTMLURL
+		}
+		if c.State != "" {
+			ev.State = c.State
+		}
+		if c.User != nil {
+			ev.Author = c.User.Login
+		}
+	}
+
+	switch {
+	case name == "push":
+		ev.Branch = strings.TrimPrefix(p.Ref, "refs/heads/")
+		ev.HeadBranch = ev.Branch
+		ev.SHA = p.After
+		ev.URL = p.Compare
+		if p.HeadCommit != nil {
+			ev.Title = firstLine(p.HeadCommit.Message)
+			ev.Body = p.HeadCommit.Message
+		}
+	case p.WorkflowRun != nil:
+		run := p.WorkflowRun
+		ev.Title = run.Name
+		ev.Number = run.RunNumber
+		ev.URL = run.HTMLURL
+		ev.Branch = run.HeadBranch
+		ev.HeadBranch = run.HeadBranch
+		ev.SHA = run.HeadSHA
+		ev.State = run.Conclusion
+		if run.Actor != nil {
+			ev.Author = run.Actor.Login
+		}
+	case p.Release != nil:
+		rel := p.Release
+		ev.Title = rel.Name
+		if ev.Title == "" {
+			ev.Title = rel.TagName
+		}
+		ev.Body = rel.Body
+		ev.URL = rel.HTMLURL
+		if rel.Author != nil {
+			ev.Author = rel.Author.Login
+		}
+	}
+	return ev, nil
+}
+
+func firstIssue(items ...*githubIssue) *githubIssue {
+	for _, it := range items {
+		if it != nil {
+			return it
+		}
+	}
+	return nil
+}
+
+func firstComment(items ...*githubComment) *githubComment {
+	for _, it := range items {
+		if it != nil {
+			return it
+		}
+	}
+	return nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// FullName returns the event name with its action, e.g. "pull_request.opened".
+func (e *GitHubEvent) FullName() string {
+	if e.Action == "" {
+		return e.Name
+	}
+	return e.Name + "." + e.Action
+}
+
+// Variables returns the template variables a GitHub event exposes.
+func (e *GitHubEvent) Variables() map[string]string {
+	number := ""
+	if e.Number != 0 {
+		number = strconv.Itoa(e.Number)
+	}
+	return map[string]string{
+		"event":       e.Name,
+		"action":      e.Action,
+		"repository":  e.Repo,
+		"sender":      e.Sender,
+		"author":      e.Author,
+		"number":      number,
+		"title":       e.Title,
+		"body":        e.Body,
+		"comment":     e.Comment,
+		"url":         e.URL,
+		"branch":      e.Branch,
+		"head_branch": e.HeadBranch,
+		"sha":         e.SHA,
+		"labels":      strings.Join(e.Labels, ", "),
+		"state":       e.State,
+		"payload":     string(e.Payload),
+	}
+}
+
+// IsGitHubVariable reports whether name is one of the event's own
+// variables, which a rule variable of the same name would replace.
+func IsGitHubVariable(name string) bool {
+	_, ok := (&GitHubEvent{}).Variables()[name]
+	return ok
+}
+
+// MatchGitHubRule reports whether event passes every filter configured on
+// rule. Within a filter, any one entry matching is enough.
+func MatchGitHubRule(rule *config.TriggerRule, event *GitHubEvent) bool {
+	return matchesAny(rule.FilterEvents, func(f string) bool {
+		return strings.EqualFold(f, event.Name) || strings.EqualFold(f, event.FullName())
+	}) &&
+		matchesAny(rule.FilterRepos, func(f string) bool { return strings.EqualFold(f, event.Repo) }) &&
+		matchesAny(rule.FilterAuthors, func(f string) bool {
+			return strings.EqualFold(strings.TrimPrefix(f, "@"), event.Author)
+		}) &&
+		matchesAny(rule.FilterBranches, func(f string) bool {
+			ok, err := path.Match(f, event.Branch)
+			return err == nil && ok && event.Branch != ""
+		}) &&
+		matchesAny(rule.FilterLabels, func(f string) bool {
+			for _, l := range event.Labels {
+				if strings.EqualFold(f, l) {
+					return true
+				}
+			}
+			return false
+		})
+}
+
+// matchesAny returns true if filters is empty or match accepts any entry.
+func matchesAny(filters []string, match func(string) bool) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		if match(strings.TrimSpace(f)) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildGitHubPrompt renders the rule's prompt template for event. The
+// template sees the event's Variables plus the rule's own JSONPath variables
+// resolved against the payload. A rule without a template gets a summary of
+// the event.
+func BuildGitHubPrompt(rule *config.TriggerRule, event *GitHubEvent) (string, error) {
+	if rule.PromptTemplate == "" {
+		return summarizeGitHubEvent(event), nil
+	}
+	vars := event.Variables()
+	resolveVariables(vars, rule.Variables, event.Payload)
+	return agent.Interpolate(rule.PromptTemplate, vars)
+}
+
+func summarizeGitHubEvent(e *GitHubEvent) string {
+	var b strings.Builder
+	line := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", label, value)
+		}
+	}
+
+	line("GitHub event", e.FullName())
+	line("Repository", e.Repo)
+	if e.Number != 0 {
+		line("Number", "#"+strconv.Itoa(e.Number))
+	}
+	line("Title", e.Title)
+	line("Author", e.Author)
+	if e.HeadBranch != "" && e.HeadBranch != e.Branch {
+		line("Branch", e.HeadBranch+" → "+e.Branch)
+	} else {
+		line("Branch", e.Branch)
+	}
+	line("Commit", e.SHA)
+	line("Labels", strings.Join(e.Labels, ", "))
+	line("State", e.State)
+	line("URL", e.URL)
+	if e.Body != "" {
+		b.WriteString("\n" + e.Body + "\n")
+	}
+	if e.Comment != "" {
+		b.WriteString("\nComment:\n" + e.Comment + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// HandleGitHubEvent runs every enabled rule of the integration that matches
+// event. Unlike a Telegram message, which has one reply and so goes to the
+// first matching rule, a repository event can reasonably start several agents
+// (a reviewer and a labeler on the same pull request). It returns
+// immediately; runs share the dispatcher's concurrency limit.
+func (d *Dispatcher) HandleGitHubEvent(integrationID string, event *GitHubEvent) {
+	go func() {
+		rules, err := d.triggerStore.ListRules(d.ctx, integrationID)
+		if err != nil {
+			d.logger.Error("failed to load trigger rules", "integration_id", integrationID, "error", err)
+			return
+		}
+		for _, rule := range rules {
+			if !rule.Enabled || !MatchGitHubRule(rule, event) {
+				continue
+			}
+			prompt, promptErr := BuildGitHubPrompt(rule, event)
+			if promptErr != nil {
+				d.logger.Warn("failed to build prompt for github trigger", "rule_id", rule.ID, "error", promptErr)
+				continue
+			}
+			d.logger.Info("trigger rule matched",
+				"rule_id", rule.ID, "rule_name", rule.Name, "agent_slug", rule.AgentSlug,
+				"event", event.FullName(), "repository", event.Repo)
+			go d.runGitHubRule(rule, prompt)
+		}
+	}()
+}
+
+func (d *Dispatcher) runGitHubRule(rule *config.TriggerRule, prompt string) {
+	select {
+	case d.sem <- struct{}{}:
+		defer func() { <-d.sem }()
+	case <-d.ctx.Done():
+		d.logger.Warn("dispatcher context canceled, dropping github event", "rule_id", rule.ID)
+		return
+	}
+
+	if _, _, err := d.runRule(d.ctx, rule, prompt, fmt.Sprintf("[GitHub] %s", rule.Name)); err != nil {
+		d.logger.Error("agent execution failed for github trigger", "rule_id", rule.ID, "error", err)
+	}
+}
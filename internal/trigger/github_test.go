@@ -0,0 +1,169 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+const pullRequestOpened = `{
+	"action": "opened",
+	"number": 42,
+	"pull_request": {
+		"number": 42,
+		"title": "Add retries",
+		"body": "Retries failed runs.",
+		"html_url": "https://github.com/acme/api/pull/42",
+		"state": "open",
+		"user": {"login": "alice"},
+		"labels": [{"name": "needs-review"}, {"name": "backend"}],
+		"head": {"ref": "feature/retries", "sha": "abc123"},
+		"base": {"ref": "main"}
+	},
+	"repository": {"full_name": "acme/api"},
+	"sender": {"login": "alice"}
+}`
+
+func TestParseGitHubEvent(t *testing.T) {
+	t.Run("pull_request", func(t *testing.T) {
+		ev, err := ParseGitHubEvent("pull_request", []byte(pullRequestOpened))
+		require.NoError(t, err)
+		assert.Equal(t, "pull_request.opened", ev.FullName())
+		assert.Equal(t, "acme/api", ev.Repo)
+		assert.Equal(t, "alice", ev.Author)
+		assert.Equal(t, 42, ev.Number)
+		assert.Equal(t, "Add retries", ev.Title)
+		assert.Equal(t, "main", ev.Branch, "a pull request matches on its base branch")
+		assert.Equal(t, "feature/retries", ev.HeadBranch)
+		assert.Equal(t, "abc123", ev.SHA)
+		assert.Equal(t, []string{"needs-review", "backend"}, ev.Labels)
+	})
+
+	t.Run("issue_comment takes the commenter as author", func(t *testing.T) {
+		body := `{
+			"action": "created",
+			"issue": {"number": 7, "title": "Crash", "user": {"login": "bob"}, "labels": [{"name": "bug"}]},
+			"comment": {"body": "Still happening", "user": {"login": "carol"},
+				"html_url": "https://github.com/acme/api/issues/7#c1"},
+			"repository": {"full_name": "acme/api"},
+			"sender": {"login": "carol"}
+		}`
+		ev, err := ParseGitHubEvent("issue_comment", []byte(body))
+		require.NoError(t, err)
+		assert.Equal(t, "carol", ev.Author)
+		assert.Equal(t, "Still happening", ev.Comment)
+		assert.Equal(t, 7, ev.Number)
+		assert.Equal(t, []string{"bug"}, ev.Labels)
+		assert.Equal(t, "https://github.com/acme/api/issues/7#c1", ev.URL)
+	})
+
+	t.Run("push", func(t *testing.T) {
+		body := `{
+			"ref": "refs/heads/release/1.2",
+			"after": "def456",
+			"compare": "https://github.com/acme/api/compare/a...b",
+			"head_commit": {"message": "Bump version\n\nDetails"},
+			"repository": {"full_name": "acme/api"},
+			"sender": {"login": "dave"}
+		}`
+		ev, err := ParseGitHubEvent("push", []byte(body))
+		require.NoError(t, err)
+		assert.Equal(t, "push", ev.FullName())
+		assert.Equal(t, "release/1.2", ev.Branch)
+		assert.Equal(t, "def456", ev.SHA)
+		assert.Equal(t, "Bump version", ev.Title)
+		assert.Equal(t, "dave", ev.Author)
+	})
+
+	t.Run("workflow_run", func(t *testing.T) {
+		body := `{
+			"action": "completed",
+			"workflow_run": {"name": "CI", "head_branch": "main", "head_sha": "f00",
+				"conclusion": "failure", "run_number": 311, "actor": {"login": "erin"},
+				"html_url": "https://github.com/acme/api/actions/runs/1"},
+			"repository": {"full_name": "acme/api"},
+			"sender": {"login": "erin"}
+		}`
+		ev, err := ParseGitHubEvent("workflow_run", []byte(body))
+		require.NoError(t, err)
+		assert.Equal(t, "workflow_run.completed", ev.FullName())
+		assert.Equal(t, "CI", ev.Title)
+		assert.Equal(t, "failure", ev.State)
+		assert.Equal(t, "main", ev.Branch)
+		assert.Equal(t, 311, ev.Number)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := ParseGitHubEvent("push", []byte("not json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestMatchGitHubRule(t *testing.T) {
+	ev, err := ParseGitHubEvent("pull_request", []byte(pullRequestOpened))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		rule config.TriggerRule
+		want bool
+	}{
+		{name: "no filters", want: true},
+		{name: "event name", rule: config.TriggerRule{FilterEvents: []string{"pull_request"}}, want: true},
+		{name: "event and action", rule: config.TriggerRule{FilterEvents: []string{"pull_request.opened"}}, want: true},
+		{name: "other action", rule: config.TriggerRule{FilterEvents: []string{"pull_request.closed"}}, want: false},
+		{name: "other event", rule: config.TriggerRule{FilterEvents: []string{"issues", "push"}}, want: false},
+		{name: "repository", rule: config.TriggerRule{FilterRepos: []string{"ACME/api"}}, want: true},
+		{name: "other repository", rule: config.TriggerRule{FilterRepos: []string{"acme/web"}}, want: false},
+		{name: "any label", rule: config.TriggerRule{FilterLabels: []string{"docs", "Backend"}}, want: true},
+		{name: "missing label", rule: config.TriggerRule{FilterLabels: []string{"docs"}}, want: false},
+		{name: "author", rule: config.TriggerRule{FilterAuthors: []string{"@alice"}}, want: true},
+		{name: "other author", rule: config.TriggerRule{FilterAuthors: []string{"bob"}}, want: false},
+		{name: "branch", rule: config.TriggerRule{FilterBranches: []string{"main"}}, want: true},
+		{name: "branch glob", rule: config.TriggerRule{FilterBranches: []string{"ma*"}}, want: true},
+		{name: "head branch is not the base", rule: config.TriggerRule{FilterBranches: []string{"feature/*"}}, want: false},
+		{
+			name: "all filters must pass",
+			rule: config.TriggerRule{
+				FilterEvents: []string{"pull_request.opened"},
+				FilterRepos:  []string{"acme/api"},
+				FilterLabels: []string{"docs"},
+			},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, MatchGitHubRule(&tc.rule, ev))
+		})
+	}
+}
+
+func TestBuildGitHubPrompt(t *testing.T) {
+	ev, err := ParseGitHubEvent("pull_request", []byte(pullRequestOpened))
+	require.NoError(t, err)
+
+	t.Run("summary without a template", func(t *testing.T) {
+		got, err := BuildGitHubPrompt(&config.TriggerRule{}, ev)
+		require.NoError(t, err)
+		assert.Contains(t, got, "GitHub event: pull_request.opened\n")
+		assert.Contains(t, got, "Repository: acme/api\n")
+		assert.Contains(t, got, "Number: #42\n")
+		assert.Contains(t, got, "Branch: feature/retries → main\n")
+		assert.Contains(t, got, "Labels: needs-review, backend\n")
+		assert.Contains(t, got, "\n\nRetries failed runs.")
+	})
+
+	t.Run("template with event and JSONPath variables", func(t *testing.T) {
+		rule := &config.TriggerRule{
+			PromptTemplate: "Review {{repository}}#{{number}} by {{author}} ({{head_sha}})",
+			Variables:      map[string]string{"head_sha": "$.pull_request.head.sha"},
+		}
+		got, err := BuildGitHubPrompt(rule, ev)
+		require.NoError(t, err)
+		assert.Equal(t, "Review acme/api#42 by alice (abc123)", got)
+	})
+}
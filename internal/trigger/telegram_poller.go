@@ -0,0 +1,346 @@
+package trigger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	telegramintegration "github.com/shaharia-lab/agento/internal/integrations/telegram"
+	"github.com/shaharia-lab/agento/internal/storage"
+)
+
+// Telegram long polling.
+//
+// A Telegram webhook needs a public URL that Telegram can reach, which a
+// laptop or an internal host does not have. While no public URL is
+// configured, the poller asks Telegram for updates itself with getUpdates, for
+// every enabled Telegram integration with an enabled trigger rule or that
+// approval requests are sent through, and hands
+// them to the same Dispatcher.HandleTelegramUpdate a webhook delivery goes
+// to. Once a public URL is configured it stops polling and registers the
+// webhook of each integration it was polling for; clearing the URL again
+// switches back. The integration's webhook status reads "polling" meanwhile.
+
+const (
+	// pollerReconcileInterval is how often the poller re-reads the public
+	// URL, integrations and rules, and so how long a switch takes.
+	pollerReconcileInterval = 30 * time.Second
+
+	// pollTimeout is how long Telegram holds a getUpdates request open
+	// waiting for a message. It must stay below the Telegram client's
+	// request timeout.
+	pollTimeout = 30 * time.Second
+
+	// pollRetryMin and pollRetryMax bound the backoff after a failed poll.
+	pollRetryMin = 5 * time.Second
+	pollRetryMax = 5 * time.Minute
+
+	// webhookStatusPolling is the webhook status of an integration whose
+	// updates are being polled for.
+	webhookStatusPolling = "polling"
+)
+
+// WebhookRegistrar registers the Telegram webhook of an integration.
+type WebhookRegistrar interface {
+	RegisterWebhook(ctx context.Context, integrationID string) error
+}
+
+// TelegramPollerConfig holds all dependencies for the TelegramPoller.
+type TelegramPollerConfig struct {
+	TriggerStore     storage.TriggerStore
+	IntegrationStore storage.IntegrationStore
+	Dispatcher       *Dispatcher
+	Webhooks         WebhookRegistrar
+	// PublicURL returns the configured public URL; polling runs while it is empty.
+	PublicURL func() string
+	// ApprovalIntegrationID is optional. It returns the integration approval
+	// requests are sent through, whose button presses must be received
+	// even when it has no trigger rules.
+	ApprovalIntegrationID func() string
+	Logger                *slog.Logger
+}
+
+// TelegramPoller receives Telegram updates by long polling when Agento has no
+// public URL for a webhook.
+type TelegramPoller struct {
+	triggerStore     storage.TriggerStore
+	integrationStore storage.IntegrationStore
+	dispatcher       *Dispatcher
+	webhooks         WebhookRegistrar
+	publicURL        func() string
+	approvalID       func() string
+	logger           *slog.Logger
+
+	// loops holds the running poll loop of each integration, by ID. It is
+	// only touched by the reconcile goroutine.
+	loops map[string]*pollLoop
+}
+
+// pollLoop is one integration's running getUpdates loop.
+type pollLoop struct {
+	botToken string
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewTelegramPoller creates a new TelegramPoller.
+func NewTelegramPoller(cfg TelegramPollerConfig) *TelegramPoller {
+	return &TelegramPoller{
+		triggerStore:     cfg.TriggerStore,
+		integrationStore: cfg.IntegrationStore,
+		dispatcher:       cfg.Dispatcher,
+		webhooks:         cfg.Webhooks,
+		publicURL:        cfg.PublicURL,
+		approvalID:       cfg.ApprovalIntegrationID,
+		logger:           cfg.Logger,
+		loops:            make(map[string]*pollLoop),
+	}
+}
+
+// Start launches the poller and returns immediately. Cancel ctx to stop it;
+// the webhook status of polled integrations is left as is, so polling
+// resumes where it stopped on the next start.
+func (p *TelegramPoller) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pollerReconcileInterval)
+		defer ticker.Stop()
+		for {
+			p.reconcile(ctx)
+			select {
+			case <-ctx.Done():
+				for id := range p.loops {
+					p.stopLoop(id)
+				}
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// reconcile brings the running poll loops in line with the public URL,
+// integrations and rules.
+func (p *TelegramPoller) reconcile(ctx context.Context) {
+	wanted, err := p.pollableIntegrations(ctx)
+	if err != nil {
+		p.logger.Error("telegram poller: failed to list integrations", "error", err)
+		return
+	}
+	if p.publicURL() != "" {
+		p.useWebhooks(ctx, wanted)
+		return
+	}
+	p.usePolling(ctx, wanted)
+}
+
+// useWebhooks stops all polling and registers the webhooks of the wanted
+// integrations that need one.
+func (p *TelegramPoller) useWebhooks(ctx context.Context, wanted map[string]string) {
+	for id := range p.loops {
+		p.stopLoop(id)
+	}
+	for id := range wanted {
+		p.switchToWebhook(ctx, id)
+	}
+}
+
+// usePolling runs exactly one poll loop per wanted integration, restarting
+// any whose bot token changed.
+func (p *TelegramPoller) usePolling(ctx context.Context, wanted map[string]string) {
+	for id, loop := range p.loops {
+		token, ok := wanted[id]
+		if ok && token == loop.botToken {
+			continue
+		}
+		p.stopLoop(id)
+		if !ok {
+			p.setStatus(ctx, id, "inactive", "")
+		}
+	}
+	for id, token := range wanted {
+		if _, running := p.loops[id]; !running {
+			p.startLoop(ctx, id, token)
+		}
+	}
+}
+
+// pollableIntegrations returns the bot token of every enabled Telegram
+// integration with at least one enabled trigger rule, or that approval
+// requests go through, by integration ID. Other integrations are left alone,
+// so their bot's updates stay readable by the read_messages tool.
+func (p *TelegramPoller) pollableIntegrations(ctx context.Context) (map[string]string, error) {
+	integrations, err := p.integrationStore.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing integrations: %w", err)
+	}
+	approvalID := p.approvalIntegrationID()
+
+	wanted := make(map[string]string)
+	for _, integration := range integrations {
+		if integration.Type != "telegram" || !integration.Enabled {
+			continue
+		}
+		var creds config.TelegramCredentials
+		if err := integration.ParseCredentials(&creds); err != nil || creds.BotToken == "" {
+			continue
+		}
+		if integration.ID == approvalID {
+			wanted[integration.ID] = creds.BotToken
+			continue
+		}
+		rules, err := p.triggerStore.ListRules(ctx, integration.ID)
+		if err != nil {
+			return nil, fmt.Errorf("listing trigger rules: %w", err)
+		}
+		if hasEnabledRule(rules) {
+			wanted[integration.ID] = creds.BotToken
+		}
+	}
+	return wanted, nil
+}
+
+func (p *TelegramPoller) approvalIntegrationID() string {
+	if p.approvalID == nil {
+		return ""
+	}
+	return p.approvalID()
+}
+
+func hasEnabledRule(rules []*config.TriggerRule) bool {
+	for _, rule := range rules {
+		if rule.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// switchToWebhook registers the webhook of an integration that was being
+// polled for, or that has never had one. An integration whose webhook the
+// user removed, or whose registration failed, is left for the user.
+func (p *TelegramPoller) switchToWebhook(ctx context.Context, integrationID string) {
+	_, status, _, err := p.triggerStore.GetWebhookInfo(ctx, integrationID)
+	if err != nil {
+		p.logger.Warn("telegram poller: failed to get webhook info", "integration_id", integrationID, "error", err)
+		return
+	}
+	if !needsWebhook(status) {
+		return
+	}
+	if err := p.webhooks.RegisterWebhook(ctx, integrationID); err != nil {
+		p.logger.Warn("telegram poller: failed to register webhook", "integration_id", integrationID, "error", err)
+		return
+	}
+	p.logger.Info("telegram integration switched to webhook", "integration_id", integrationID)
+}
+
+// needsWebhook reports whether an integration with the given webhook status
+// should get a webhook once a public URL is configured.
+func needsWebhook(status string) bool {
+	return status == "" || status == webhookStatusPolling
+}
+
+func (p *TelegramPoller) startLoop(ctx context.Context, integrationID, botToken string) {
+	loopCtx, cancel := context.WithCancel(ctx)
+	loop := &pollLoop{botToken: botToken, cancel: cancel, done: make(chan struct{})}
+	p.loops[integrationID] = loop
+
+	go func() {
+		defer close(loop.done)
+		p.poll(loopCtx, integrationID, botToken)
+	}()
+	p.logger.Info("telegram integration switched to long polling", "integration_id", integrationID)
+}
+
+// stopLoop stops an integration's poll loop and waits for it to exit, so a
+// following webhook registration cannot race its last getUpdates.
+func (p *TelegramPoller) stopLoop(integrationID string) {
+	loop := p.loops[integrationID]
+	loop.cancel()
+	<-loop.done
+	delete(p.loops, integrationID)
+}
+
+// poll runs getUpdates for one integration until ctx is canceled.
+func (p *TelegramPoller) poll(ctx context.Context, integrationID, botToken string) {
+	// Telegram refuses getUpdates while a webhook is set. Pending updates
+	// are kept, so nothing sent in between is lost.
+	if err := telegramintegration.DeleteWebhook(ctx, botToken); err != nil {
+		p.logger.Warn("telegram poller: failed to delete webhook", "integration_id", integrationID, "error", err)
+	}
+	p.setStatus(ctx, integrationID, webhookStatusPolling, "")
+
+	offset, err := p.triggerStore.GetUpdateOffset(ctx, integrationID)
+	if err != nil {
+		p.logger.Warn("telegram poller: failed to load offset", "integration_id", integrationID, "error", err)
+	}
+
+	retry := pollRetryMin
+	for ctx.Err() == nil {
+		next, err := p.pollOnce(ctx, integrationID, botToken, offset)
+		if err == nil {
+			if retry > pollRetryMin {
+				p.setStatus(ctx, integrationID, webhookStatusPolling, "")
+				retry = pollRetryMin
+			}
+			offset = next
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		p.logger.Warn("telegram poller: getUpdates failed",
+			"integration_id", integrationID, "retry_in", retry, "error", err)
+		p.setStatus(ctx, integrationID, webhookStatusPolling, err.Error())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(retry*2, pollRetryMax)
+	}
+}
+
+// pollOnce fetches one batch of updates from offset on, dispatches them, and
+// returns the offset of the next batch.
+func (p *TelegramPoller) pollOnce(ctx context.Context, integrationID, botToken string, offset int64) (int64, error) {
+	raw, err := telegramintegration.GetUpdates(ctx, botToken, offset, pollTimeout)
+	if err != nil {
+		return offset, err
+	}
+	var updates []TelegramUpdate
+	if err := json.Unmarshal(raw, &updates); err != nil {
+		return offset, fmt.Errorf("decoding updates: %w", err)
+	}
+	if len(updates) == 0 {
+		return offset, nil
+	}
+
+	for _, update := range updates {
+		p.dispatcher.HandleTelegramUpdate(integrationID, botToken, update)
+	}
+	next := nextUpdateOffset(offset, updates)
+	if err := p.triggerStore.SaveUpdateOffset(ctx, integrationID, next); err != nil {
+		p.logger.Warn("telegram poller: failed to save offset", "integration_id", integrationID, "error", err)
+	}
+	return next, nil
+}
+
+// nextUpdateOffset returns the getUpdates offset that confirms updates: one
+// past the highest update_id seen.
+func nextUpdateOffset(offset int64, updates []TelegramUpdate) int64 {
+	for _, u := range updates {
+		offset = max(offset, u.UpdateID+1)
+	}
+	return offset
+}
+
+func (p *TelegramPoller) setStatus(ctx context.Context, integrationID, status, pollErr string) {
+	if err := p.triggerStore.SetWebhookInfo(ctx, integrationID, "", status, pollErr); err != nil {
+		p.logger.Warn("telegram poller: failed to save status", "integration_id", integrationID, "error", err)
+	}
+}
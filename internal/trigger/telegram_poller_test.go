@@ -0,0 +1,94 @@
+package trigger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/config"
+	"github.com/shaharia-lab/agento/internal/storage/mocks"
+)
+
+func TestNextUpdateOffset(t *testing.T) {
+	assert.Equal(t, int64(0), nextUpdateOffset(0, nil))
+	assert.Equal(t, int64(12), nextUpdateOffset(0, []TelegramUpdate{{UpdateID: 10}, {UpdateID: 11}}))
+	assert.Equal(t, int64(12), nextUpdateOffset(12, []TelegramUpdate{{UpdateID: 9}}), "never moves backwards")
+}
+
+func TestNeedsWebhook(t *testing.T) {
+	assert.True(t, needsWebhook(""), "never set up")
+	assert.True(t, needsWebhook(webhookStatusPolling))
+	assert.False(t, needsWebhook("active"))
+	assert.False(t, needsWebhook("inactive"), "removed by the user")
+	assert.False(t, needsWebhook("error"), "left for the user to retry")
+}
+
+type recordingRegistrar struct {
+	registered []string
+}
+
+func (r *recordingRegistrar) RegisterWebhook(_ context.Context, integrationID string) error {
+	r.registered = append(r.registered, integrationID)
+	return nil
+}
+
+func telegramIntegration(id, botToken string, enabled bool) *config.IntegrationConfig {
+	creds, _ := json.Marshal(config.TelegramCredentials{BotToken: botToken})
+	return &config.IntegrationConfig{ID: id, Type: "telegram", Enabled: enabled, Credentials: creds}
+}
+
+// TestTelegramPoller_Reconcile switches an integration from polling to its
+// webhook as a public URL is configured. The context is canceled up front, so
+// a poll loop sets its status and loads its offset, then exits before its
+// first getUpdates.
+func TestTelegramPoller_Reconcile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	integrations := &mocks.MockIntegrationStore{}
+	integrations.On("List", mock.Anything).Return([]*config.IntegrationConfig{
+		telegramIntegration("int-1", "token-1", true),
+		telegramIntegration("no-rules", "token-2", true),
+		telegramIntegration("disabled", "token-3", false),
+		{ID: "gmail", Type: "google", Enabled: true},
+	}, nil)
+	triggers := &mocks.MockTriggerStore{}
+	triggers.On("ListRules", mock.Anything, "int-1").Return([]*config.TriggerRule{{ID: "r1", Enabled: true}}, nil)
+	triggers.On("ListRules", mock.Anything, "no-rules").Return([]*config.TriggerRule{{ID: "r2"}}, nil)
+	triggers.On("SetWebhookInfo", mock.Anything, "int-1", "", webhookStatusPolling, "").Return(nil)
+	triggers.On("GetUpdateOffset", mock.Anything, "int-1").Return(int64(1042), nil)
+	triggers.On("GetWebhookInfo", mock.Anything, "int-1").Return("", webhookStatusPolling, "", nil)
+
+	publicURL := ""
+	webhooks := &recordingRegistrar{}
+	p := NewTelegramPoller(TelegramPollerConfig{
+		TriggerStore:     triggers,
+		IntegrationStore: integrations,
+		Webhooks:         webhooks,
+		PublicURL:        func() string { return publicURL },
+		Logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	// Without a public URL, only the integration with an enabled rule is polled.
+	p.reconcile(ctx)
+	require.Len(t, p.loops, 1)
+	loop := p.loops["int-1"]
+	require.NotNil(t, loop)
+	assert.Equal(t, "token-1", loop.botToken)
+	<-loop.done
+	triggers.AssertCalled(t, "SetWebhookInfo", mock.Anything, "int-1", "", webhookStatusPolling, "")
+	triggers.AssertCalled(t, "GetUpdateOffset", mock.Anything, "int-1")
+	assert.Empty(t, webhooks.registered)
+
+	// With one, polling stops and the polled integration gets its webhook.
+	publicURL = "https://agento.example.com"
+	p.reconcile(ctx)
+	assert.Empty(t, p.loops)
+	assert.Equal(t, []string{"int-1"}, webhooks.registered)
+}
@@ -0,0 +1,301 @@
+package trigger
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 of a generic webhook request
+// body, and of the callbacks Agento sends, as "sha256=<hex>".
+const WebhookSignatureHeader = "X-Agento-Signature-256"
+
+// callbackTimeout bounds a single callback delivery.
+const callbackTimeout = 30 * time.Second
+
+// WebhookResult is the response to a synchronous webhook and the body of a
+// callback.
+type WebhookResult struct {
+	RuleID    string  `json:"rule_id"`
+	SessionID string  `json:"session_id,omitempty"`
+	Answer    string  `json:"answer"`
+	CostUSD   float64 `json:"cost_usd,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// GenerateWebhookSecret returns a random 32-byte hex-encoded secret.
+func GenerateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SignWebhookPayload returns the signature header value for body.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body) //nolint:errcheck // hash.Hash.Write never returns an error
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature is a valid "sha256=<hex>"
+// HMAC of body under secret. An empty secret never verifies.
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	if secret == "" {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(SignWebhookPayload(secret, body)))
+}
+
+// BuildWebhookPrompt renders the rule's prompt template for a request body.
+// Each of rule.Variables is resolved against the body as JSON and {{body}} is
+// the raw body. A rule without a template sends the body itself.
+func BuildWebhookPrompt(rule *config.TriggerRule, body []byte) (string, error) {
+	if rule.PromptTemplate == "" {
+		return string(body), nil
+	}
+
+	vars := map[string]string{"body": string(body)}
+	resolveVariables(vars, rule.Variables, body)
+	return agent.Interpolate(rule.PromptTemplate, vars)
+}
+
+// IsWebhookVariable reports whether name is a variable BuildWebhookPrompt
+// sets itself, which a rule variable of the same name would replace.
+func IsWebhookVariable(name string) bool {
+	return name == "body"
+}
+
+// resolveVariables adds each of paths (variable name → JSONPath), resolved
+// against body, to vars. A body that is not JSON leaves every path
+// unresolved rather than failing the request, since the template may not
+// need them; unresolved paths become empty strings.
+func resolveVariables(vars, paths map[string]string, body []byte) {
+	if len(paths) == 0 {
+		return
+	}
+	var doc any
+	if json.Unmarshal(body, &doc) != nil {
+		doc = nil
+	}
+	for name, path := range paths {
+		v, ok := ExtractJSONPath(doc, path)
+		if !ok {
+			vars[name] = ""
+			continue
+		}
+		vars[name] = formatJSONValue(v)
+	}
+}
+
+// formatJSONValue renders a decoded JSON value for a prompt: strings as-is,
+// null as empty, and everything else as compact JSON.
+func formatJSONValue(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// ExtractJSONPath evaluates a JSONPath against a document decoded with
+// encoding/json. The supported subset is what webhook payloads need: a
+// leading "$", dotted keys (.a.b), quoted keys (['a b']) and array indexes
+// ([0], with negative indexes counting from the end). It reports false when
+// the path does not resolve or cannot be parsed.
+func ExtractJSONPath(doc any, path string) (any, bool) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false
+	}
+	cur := doc
+	for _, st := range steps {
+		if st.key != nil {
+			obj, ok := cur.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if cur, ok = obj[*st.key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+		arr, ok := cur.([]any)
+		if !ok {
+			return nil, false
+		}
+		i := st.index
+		if i < 0 {
+			i += len(arr)
+		}
+		if i < 0 || i >= len(arr) {
+			return nil, false
+		}
+		cur = arr[i]
+	}
+	return cur, true
+}
+
+// ValidateJSONPath returns an error when path is not in the subset
+// ExtractJSONPath supports.
+func ValidateJSONPath(path string) error {
+	_, err := parseJSONPath(path)
+	return err
+}
+
+// pathStep is one key or index of a parsed JSONPath.
+type pathStep struct {
+	key   *string
+	index int
+}
+
+func parseJSONPath(path string) ([]pathStep, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimPrefix(p, "$")
+
+	var steps []pathStep
+	for p != "" {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end == -1 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty key in path %q", path)
+			}
+			key := p[:end]
+			steps = append(steps, pathStep{key: &key})
+			p = p[end:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed bracket in path %q", path)
+			}
+			inner := strings.TrimSpace(p[1:end])
+			p = p[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				key := inner[1 : len(inner)-1]
+				steps = append(steps, pathStep{key: &key})
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q in path %q", inner, path)
+			}
+			steps = append(steps, pathStep{index: idx})
+		default:
+			// Tolerate a bare leading key ("a.b" for "$.a.b").
+			if len(steps) > 0 {
+				return nil, fmt.Errorf("unexpected %q in path %q", p[0], path)
+			}
+			p = "." + p
+		}
+	}
+	return steps, nil
+}
+
+// ErrDispatcherBusy is returned by RunWebhook when the caller gave up before
+// an execution slot became free.
+var ErrDispatcherBusy = errors.New("too many concurrent trigger executions")
+
+// RunWebhook runs rule synchronously on prompt and returns its result. It
+// waits for an execution slot for as long as ctx allows.
+func (d *Dispatcher) RunWebhook(ctx context.Context, rule *config.TriggerRule, prompt string) (*WebhookResult, error) {
+	select {
+	case d.sem <- struct{}{}:
+		defer func() { <-d.sem }()
+	case <-ctx.Done():
+		return nil, ErrDispatcherBusy
+	}
+	return d.runWebhook(ctx, rule, prompt)
+}
+
+// DispatchWebhook runs rule in the background and POSTs the result to the
+// rule's callback URL, signed with the rule's secret.
+func (d *Dispatcher) DispatchWebhook(rule *config.TriggerRule, prompt string) {
+	go func() {
+		select {
+		case d.sem <- struct{}{}:
+			defer func() { <-d.sem }()
+		case <-d.ctx.Done():
+			d.logger.Warn("dispatcher context canceled, dropping webhook", "rule_id", rule.ID)
+			return
+		}
+
+		res, err := d.runWebhook(d.ctx, rule, prompt)
+		if err != nil {
+			res.Error = err.Error()
+		}
+		if cbErr := d.postCallback(d.ctx, rule, res); cbErr != nil {
+			d.logger.Error("failed to deliver webhook callback",
+				"rule_id", rule.ID, "callback_url", rule.CallbackURL, "error", cbErr)
+		}
+	}()
+}
+
+// runWebhook runs the rule and fills a WebhookResult. The result is non-nil
+// even when err is set, so a callback can report the failure.
+func (d *Dispatcher) runWebhook(ctx context.Context, rule *config.TriggerRule, prompt string) (*WebhookResult, error) {
+	res := &WebhookResult{RuleID: rule.ID}
+	session, result, err := d.runRule(ctx, rule, prompt, fmt.Sprintf("[Webhook] %s", rule.Name))
+	if session != nil {
+		res.SessionID = session.ID
+	}
+	if err != nil {
+		d.logger.Error("agent execution failed for webhook", "rule_id", rule.ID, "error", err)
+		return res, err
+	}
+	res.Answer = result.Answer
+	res.CostUSD = result.CostUSD
+	return res, nil
+}
+
+func (d *Dispatcher) postCallback(ctx context.Context, rule *config.TriggerRule, res *WebhookResult) error {
+	body, err := json.Marshal(res)
+	if err != nil {
+		return fmt.Errorf("marshaling callback: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, callbackTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rule.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building callback request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(rule.WebhookSecret, body))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting callback: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package trigger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaharia-lab/agento/internal/agent"
+	"github.com/shaharia-lab/agento/internal/config"
+)
+
+const alertBody = `{
+	"status": "firing",
+	"alerts": [
+		{"labels": {"alertname": "HighLatency", "service name": "api"}, "value": 1.5},
+		{"labels": {"alertname": "DiskFull"}, "value": null}
+	],
+	"count": 2
+}`
+
+func TestExtractJSONPath(t *testing.T) {
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(alertBody), &doc))
+
+	tests := []struct {
+		path   string
+		want   any
+		wantOK bool
+	}{
+		{path: "$.status", want: "firing", wantOK: true},
+		{path: "status", want: "firing", wantOK: true},
+		{path: "$.alerts[0].labels.alertname", want: "HighLatency", wantOK: true},
+		{path: "$.alerts[-1].labels.alertname", want: "DiskFull", wantOK: true},
+		{path: "$.alerts[0].labels['service name']", want: "api", wantOK: true},
+		{path: `$["count"]`, want: float64(2), wantOK: true},
+		{path: "$.alerts[1].value", want: nil, wantOK: true},
+		{path: "$.alerts[2]", wantOK: false},
+		{path: "$.missing.key", wantOK: false},
+		{path: "$.status[0]", wantOK: false},
+		{path: "$.alerts[x]", wantOK: false},
+		{path: "$.alerts[0", wantOK: false},
+		{path: "$..status", wantOK: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			got, ok := ExtractJSONPath(doc, tc.path)
+			assert.Equal(t, tc.wantOK, ok)
+			if tc.wantOK {
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+
+	whole, ok := ExtractJSONPath(doc, "$")
+	assert.True(t, ok)
+	assert.Equal(t, doc, whole)
+}
+
+func TestBuildWebhookPrompt(t *testing.T) {
+	t.Run("no template sends the body", func(t *testing.T) {
+		got, err := BuildWebhookPrompt(&config.TriggerRule{}, []byte(alertBody))
+		require.NoError(t, err)
+		assert.Equal(t, alertBody, got)
+	})
+
+	t.Run("variables are extracted from the body", func(t *testing.T) {
+		rule := &config.TriggerRule{
+			PromptTemplate: "{{name}} is {{status}} ({{count}} alerts): {{labels}} {{gone}}",
+			Variables: map[string]string{
+				"name":   "$.alerts[0].labels.alertname",
+				"status": "$.status",
+				"count":  "$.count",
+				"labels": "$.alerts[1].labels",
+				"gone":   "$.nope",
+			},
+		}
+		got, err := BuildWebhookPrompt(rule, []byte(alertBody))
+		require.NoError(t, err)
+		assert.Equal(t, `HighLatency is firing (2 alerts): {"alertname":"DiskFull"} `, got)
+	})
+
+	t.Run("body variable and non-JSON payloads", func(t *testing.T) {
+		rule := &config.TriggerRule{
+			PromptTemplate: "Summarize: {{body}}{{status}}",
+			Variables:      map[string]string{"status": "$.status"},
+		}
+		got, err := BuildWebhookPrompt(rule, []byte("plain text"))
+		require.NoError(t, err)
+		assert.Equal(t, "Summarize: plain text", got)
+	})
+
+	t.Run("unknown template variable", func(t *testing.T) {
+		rule := &config.TriggerRule{PromptTemplate: "{{undefined}}"}
+		_, err := BuildWebhookPrompt(rule, []byte(alertBody))
+		var mve *agent.MissingVariableError
+		assert.ErrorAs(t, err, &mve)
+	})
+}
+
+func TestWebhookSignature(t *testing.T) {
+	body := []byte(`{"hello":"world"}`)
+	sig := SignWebhookPayload("s3cret", body)
+
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, sig)
+	assert.True(t, VerifyWebhookSignature("s3cret", body, sig))
+	assert.False(t, VerifyWebhookSignature("other", body, sig))
+	assert.False(t, VerifyWebhookSignature("s3cret", []byte(`{"hello":"there"}`), sig))
+	assert.False(t, VerifyWebhookSignature("s3cret", body, ""))
+	assert.False(t, VerifyWebhookSignature("", body, SignWebhookPayload("", body)),
+		"a rule without a secret must never verify")
+}
+
+func TestValidateJSONPath(t *testing.T) {
+	assert.NoError(t, ValidateJSONPath("$.a.b[0]['c d']"))
+	assert.Error(t, ValidateJSONPath("$.a["))
+	assert.Error(t, ValidateJSONPath("$.a[one]"))
+}